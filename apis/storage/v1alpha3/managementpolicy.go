@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A BlobType is a type of blob that a lifecycle management rule applies to.
+// +kubebuilder:validation:Enum=blockBlob;appendBlob
+type BlobType string
+
+// Blob types.
+const (
+	BlobTypeBlockBlob  BlobType = "blockBlob"
+	BlobTypeAppendBlob BlobType = "appendBlob"
+)
+
+// ManagementPolicyParameters define the desired state of the lifecycle
+// management policy of an Azure Blob Storage Account.
+type ManagementPolicyParameters struct {
+	// ResourceGroupName - Name of the storage account's resource group.
+	// +optional
+	ResourceGroupName string `json:"resourceGroupName,omitempty"`
+
+	// ResourceGroupNameRef - A reference to a ResourceGroup object to
+	// retrieve its name
+	// +optional
+	ResourceGroupNameRef *xpv1.Reference `json:"resourceGroupNameRef,omitempty"`
+
+	// ResourceGroupNameSelector - Selects a ResourceGroup to reference.
+	// +optional
+	ResourceGroupNameSelector *xpv1.Selector `json:"resourceGroupNameSelector,omitempty"`
+
+	// AccountName - Name of the storage account the policy applies to.
+	// +optional
+	AccountName string `json:"accountName,omitempty"`
+
+	// AccountNameRef - A reference to the Account the policy applies to.
+	// +optional
+	AccountNameRef *xpv1.Reference `json:"accountNameRef,omitempty"`
+
+	// AccountNameSelector - Selects an Account to reference.
+	// +optional
+	AccountNameSelector *xpv1.Selector `json:"accountNameSelector,omitempty"`
+
+	// Rules of the policy. Rules that Azure reports but are not declared
+	// here are removed.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=100
+	Rules []ManagementPolicyRule `json:"rules"`
+}
+
+// A ManagementPolicyRule is a lifecycle management rule, which acts on the
+// blobs its filters match once they reach a certain age.
+type ManagementPolicyRule struct {
+	// Name of the rule, which must be unique within the policy.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9]+$`
+	// +kubebuilder:validation:MaxLength=256
+	Name string `json:"name"`
+
+	// Enabled rules are applied. Defaults to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Filters limit the rule to a subset of the account's blobs. The rule
+	// applies to every block blob when it is unset.
+	// +optional
+	Filters *ManagementPolicyFilters `json:"filters,omitempty"`
+
+	// Actions the rule takes.
+	Actions ManagementPolicyActions `json:"actions"`
+}
+
+// ManagementPolicyFilters limit a lifecycle management rule to the blobs
+// that match all of them.
+type ManagementPolicyFilters struct {
+	// PrefixMatch limits the rule to blobs whose names, including their
+	// container's name, start with one of these prefixes, for example
+	// logs/2022.
+	// +optional
+	PrefixMatch []string `json:"prefixMatch,omitempty"`
+
+	// BlobTypes the rule applies to. Only delete actions apply to append
+	// blobs. Defaults to blockBlob.
+	// +optional
+	BlobTypes []BlobType `json:"blobTypes,omitempty"`
+}
+
+// ManagementPolicyActions are the actions of a lifecycle management rule. At
+// least one action must be set.
+// +kubebuilder:validation:MinProperties=1
+type ManagementPolicyActions struct {
+	// BaseBlob actions apply to the current version of each blob.
+	// +optional
+	BaseBlob *ManagementPolicyBaseBlobActions `json:"baseBlob,omitempty"`
+
+	// Snapshot actions apply to blob snapshots.
+	// +optional
+	Snapshot *ManagementPolicyVersionActions `json:"snapshot,omitempty"`
+
+	// Version actions apply to previous blob versions.
+	// +optional
+	Version *ManagementPolicyVersionActions `json:"version,omitempty"`
+}
+
+// ManagementPolicyBaseBlobActions act on blobs a number of days after they
+// were last modified.
+// +kubebuilder:validation:MinProperties=1
+type ManagementPolicyBaseBlobActions struct {
+	// TierToCoolAfterDays moves blobs to the cool tier this many days after
+	// they were last modified.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TierToCoolAfterDays *int32 `json:"tierToCoolAfterDays,omitempty"`
+
+	// TierToArchiveAfterDays moves blobs to the archive tier this many days
+	// after they were last modified.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TierToArchiveAfterDays *int32 `json:"tierToArchiveAfterDays,omitempty"`
+
+	// DeleteAfterDays deletes blobs this many days after they were last
+	// modified.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	DeleteAfterDays *int32 `json:"deleteAfterDays,omitempty"`
+}
+
+// ManagementPolicyVersionActions act on blob snapshots or previous versions
+// a number of days after they were created.
+// +kubebuilder:validation:MinProperties=1
+type ManagementPolicyVersionActions struct {
+	// TierToCoolAfterDays moves snapshots or versions to the cool tier this
+	// many days after they were created.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TierToCoolAfterDays *int32 `json:"tierToCoolAfterDays,omitempty"`
+
+	// TierToArchiveAfterDays moves snapshots or versions to the archive
+	// tier this many days after they were created.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TierToArchiveAfterDays *int32 `json:"tierToArchiveAfterDays,omitempty"`
+
+	// DeleteAfterDays deletes snapshots or versions this many days after
+	// they were created.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	DeleteAfterDays *int32 `json:"deleteAfterDays,omitempty"`
+}
+
+// A ManagementPolicySpec defines the desired state of a ManagementPolicy.
+type ManagementPolicySpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ManagementPolicyParameters `json:"forProvider"`
+}
+
+// A ManagementPolicyObservation represents the observed state of a
+// ManagementPolicy.
+type ManagementPolicyObservation struct {
+	// ID - Resource ID
+	ID string `json:"id,omitempty"`
+
+	// LastModifiedTime is when the policy was last modified.
+	LastModifiedTime *metav1.Time `json:"lastModifiedTime,omitempty"`
+}
+
+// A ManagementPolicyStatus represents the observed state of a
+// ManagementPolicy.
+type ManagementPolicyStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ManagementPolicyObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ManagementPolicy is a managed resource that represents the lifecycle
+// management policy of an Azure Blob Storage Account, which moves blobs to
+// cooler tiers and deletes them as they age. A storage account has at most
+// one policy.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="STORAGE_ACCOUNT",type="string",JSONPath=".spec.forProvider.accountName"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,azure}
+type ManagementPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManagementPolicySpec   `json:"spec"`
+	Status ManagementPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ManagementPolicyList contains a list of ManagementPolicy.
+type ManagementPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ManagementPolicy `json:"items"`
+}
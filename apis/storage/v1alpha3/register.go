@@ -53,7 +53,16 @@ var (
 	ContainerGroupVersionKind = SchemeGroupVersion.WithKind(ContainerKind)
 )
 
+// ManagementPolicy type metadata.
+var (
+	ManagementPolicyKind             = reflect.TypeOf(ManagementPolicy{}).Name()
+	ManagementPolicyGroupKind        = schema.GroupKind{Group: Group, Kind: ManagementPolicyKind}.String()
+	ManagementPolicyKindAPIVersion   = ManagementPolicyKind + "." + SchemeGroupVersion.String()
+	ManagementPolicyGroupVersionKind = SchemeGroupVersion.WithKind(ManagementPolicyKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&Account{}, &AccountList{})
 	SchemeBuilder.Register(&Container{}, &ContainerList{})
+	SchemeBuilder.Register(&ManagementPolicy{}, &ManagementPolicyList{})
 }
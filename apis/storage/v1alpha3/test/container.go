@@ -81,6 +81,17 @@ func (tc *MockContainer) WithFinalizer(f string) *MockContainer {
 	return tc
 }
 
+// WithExternalName sets the external name annotation, or removes it if the
+// supplied name is empty
+func (tc *MockContainer) WithExternalName(name string) *MockContainer {
+	if name == "" {
+		delete(tc.Container.ObjectMeta.Annotations, meta.AnnotationKeyExternalName)
+		return tc
+	}
+	meta.SetExternalName(tc, name)
+	return tc
+}
+
 // WithFinalizers sets finalizers list
 func (tc *MockContainer) WithFinalizers(f []string) *MockContainer {
 	tc.Container.ObjectMeta.Finalizers = f
@@ -117,8 +128,51 @@ func (tc *MockContainer) WithSpecMetadata(meta map[string]string) *MockContainer
 	return tc
 }
 
+// WithSpecEncryptionScope sets spec default encryption scope values
+func (tc *MockContainer) WithSpecEncryptionScope(scope string, preventOverride bool) *MockContainer {
+	tc.Container.Spec.DefaultEncryptionScope = scope
+	tc.Container.Spec.PreventEncryptionScopeOverride = preventOverride
+	return tc
+}
+
+// WithSpecAdoptionPolicy sets spec adoption policy value
+func (tc *MockContainer) WithSpecAdoptionPolicy(p storagev1alpha3.AdoptionPolicy) *MockContainer {
+	tc.Container.Spec.AdoptionPolicy = p
+	return tc
+}
+
+// WithSpecMetadataFrom sets spec metadata ConfigMap reference value
+func (tc *MockContainer) WithSpecMetadataFrom(namespace, name string) *MockContainer {
+	tc.Container.Spec.MetadataFrom = &storagev1alpha3.ConfigMapReference{Namespace: namespace, Name: name}
+	return tc
+}
+
+// WithSpecDefaultContentSettings sets spec default content settings value
+func (tc *MockContainer) WithSpecDefaultContentSettings(cacheControl, contentDisposition string) *MockContainer {
+	tc.Container.Spec.DefaultContentSettings = &storagev1alpha3.ContentSettings{CacheControl: cacheControl, ContentDisposition: contentDisposition}
+	return tc
+}
+
 // WithStatusConditions sets the conditioned status.
 func (tc *MockContainer) WithStatusConditions(c ...xpv1.Condition) *MockContainer {
 	tc.Status.SetConditions(c...)
 	return tc
 }
+
+// WithStatusAtProvider sets status observation value
+func (tc *MockContainer) WithStatusAtProvider(o storagev1alpha3.ContainerObservation) *MockContainer {
+	tc.Container.Status.AtProvider = o
+	return tc
+}
+
+// WithStatusDrift sets status drift value
+func (tc *MockContainer) WithStatusDrift(d *storagev1alpha3.ContainerDrift) *MockContainer {
+	tc.Container.Status.Drift = d
+	return tc
+}
+
+// WithStatusOperations sets status operations value
+func (tc *MockContainer) WithStatusOperations(ops ...storagev1alpha3.ContainerOperationRecord) *MockContainer {
+	tc.Container.Status.Operations = ops
+	return tc
+}
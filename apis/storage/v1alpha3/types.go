@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha3
 
 import (
+	"time"
+
 	"github.com/Azure/azure-storage-blob-go/azblob"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -30,6 +32,175 @@ type AccountParameters struct {
 
 	// StorageAccountSpec specifies the desired state of this Account.
 	StorageAccountSpec *StorageAccountSpec `json:"storageAccountSpec"`
+
+	// BlobService specifies the desired state of the blob service of this
+	// Account. Blob service properties are not managed when it is unset.
+	// +optional
+	BlobService *BlobServiceParameters `json:"blobService,omitempty"`
+
+	// DefaultToOAuthAuthentication sets whether this Account defaults to
+	// OAuth rather than shared key authentication, so that its containers
+	// can be used without account keys. The setting is left as it is when
+	// unset.
+	// +optional
+	DefaultToOAuthAuthentication *bool `json:"defaultToOAuthAuthentication,omitempty"`
+
+	// AllowSharedKeyAccess sets whether this Account permits requests
+	// authorized with its account keys, including shared access signatures.
+	// The account key stops working once shared key access is disallowed, so
+	// the blob service and Containers of this Account are then managed with an
+	// Azure AD token issued to the provider's service principal instead. The
+	// setting is left as it is when unset.
+	// +optional
+	AllowSharedKeyAccess *bool `json:"allowSharedKeyAccess,omitempty"`
+
+	// MinimumTLSVersion sets the minimum TLS version that this Account
+	// permits requests to use. The setting is restored if it is changed, for
+	// example lowered, outside of Crossplane, and is left as it is when unset.
+	// +optional
+	// +kubebuilder:validation:Enum=TLS1_0;TLS1_1;TLS1_2
+	MinimumTLSVersion *string `json:"minimumTLSVersion,omitempty"`
+
+	// SharedAccessSignatures are account SAS tokens for the blob service of
+	// this Account that are written to its connection secret, so that
+	// consumers need not use the account key. Each token is written under
+	// its name, and reissued before it expires. Containers of this Account
+	// that are managed with a SAS read a token named sas, which must grant
+	// every permission they need.
+	// +optional
+	SharedAccessSignatures []SharedAccessSignature `json:"sharedAccessSignatures,omitempty"`
+}
+
+// A SharedAccessSignature configures a SAS token that is written to a
+// connection secret. Tokens are signed with the storage account's first key,
+// and are reissued when the key or their configuration changes.
+type SharedAccessSignature struct {
+	// Name of the connection secret key the token is written to.
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	Name string `json:"name"`
+
+	// Permissions granted by the token. Container tokens may grant any
+	// combination of r (read), a (add), c (create), w (write), d (delete)
+	// and l (list). Account tokens may also grant u (update) and p
+	// (process).
+	// +kubebuilder:validation:Pattern=`^[racwdlup]+$`
+	Permissions string `json:"permissions"`
+
+	// Validity is how long each token is valid for once it is issued.
+	// +optional
+	// +kubebuilder:default="24h"
+	Validity *metav1.Duration `json:"validity,omitempty"`
+
+	// RenewBefore is how long before it expires a token is reissued.
+	// Defaults to a third of its validity. Tokens are reissued when they are
+	// next reconciled within that time.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// IPRange restricts the token to requests from an IPv4 address, such as
+	// 203.0.113.7, or an inclusive range of them, such as
+	// 203.0.113.0-203.0.113.255. The token is not restricted when it is
+	// unset.
+	// +optional
+	IPRange string `json:"ipRange,omitempty"`
+
+	// AllowHTTP permits the token to be used over HTTP as well as HTTPS.
+	// +optional
+	AllowHTTP bool `json:"allowHTTP,omitempty"`
+}
+
+// DefaultSASValidity is how long a SharedAccessSignature's tokens are valid
+// for when its validity is unset.
+const DefaultSASValidity = 24 * time.Hour
+
+// Lifetime returns how long the tokens of this SharedAccessSignature are valid
+// for, and how long before they expire they are reissued.
+func (s SharedAccessSignature) Lifetime() (validity, renewBefore time.Duration) {
+	validity = DefaultSASValidity
+	if s.Validity != nil && s.Validity.Duration > 0 {
+		validity = s.Validity.Duration
+	}
+	renewBefore = validity / 3
+	if s.RenewBefore != nil && s.RenewBefore.Duration > 0 && s.RenewBefore.Duration < validity {
+		renewBefore = s.RenewBefore.Duration
+	}
+	return validity, renewBefore
+}
+
+// BlobServiceParameters define the desired state of the blob service of an
+// Azure Blob Storage Account.
+type BlobServiceParameters struct {
+	// HourMetrics configures Storage Analytics metrics aggregated by hour.
+	// +optional
+	HourMetrics *MetricsParameters `json:"hourMetrics,omitempty"`
+
+	// MinuteMetrics configures Storage Analytics metrics aggregated by
+	// minute.
+	// +optional
+	MinuteMetrics *MetricsParameters `json:"minuteMetrics,omitempty"`
+
+	// Logging configures Storage Analytics logging.
+	// +optional
+	Logging *LoggingParameters `json:"logging,omitempty"`
+
+	// SoftDelete configures the retention of deleted blobs. Restoring
+	// deleted blobs requires it to be enabled.
+	// +optional
+	SoftDelete *SoftDeleteParameters `json:"softDelete,omitempty"`
+}
+
+// SoftDeleteParameters configure the retention of deleted blobs.
+type SoftDeleteParameters struct {
+	// Enabled causes deleted blobs to be retained.
+	Enabled bool `json:"enabled"`
+
+	// Days for which deleted blobs are retained. It has no effect unless soft
+	// delete is enabled.
+	// +optional
+	// +kubebuilder:default=7
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=365
+	Days int32 `json:"days,omitempty"`
+}
+
+// LoggingParameters configure Storage Analytics logging. Logging is disabled
+// when no kind of request is logged.
+type LoggingParameters struct {
+	// Read requests are logged.
+	// +optional
+	Read bool `json:"read,omitempty"`
+
+	// Write requests are logged.
+	// +optional
+	Write bool `json:"write,omitempty"`
+
+	// Delete requests are logged.
+	// +optional
+	Delete bool `json:"delete,omitempty"`
+
+	// RetentionDays is the number of days for which logs are kept. Logs are
+	// kept until they are deleted when it is unset.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=365
+	RetentionDays *int32 `json:"retentionDays,omitempty"`
+}
+
+// MetricsParameters configure Storage Analytics metrics.
+type MetricsParameters struct {
+	// Enabled metrics are collected.
+	Enabled bool `json:"enabled"`
+
+	// IncludeAPIs summarises metrics by API operation.
+	// +optional
+	IncludeAPIs bool `json:"includeAPIs,omitempty"`
+
+	// RetentionDays is the number of days for which metrics are kept.
+	// Metrics are kept until they are deleted when it is unset.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=365
+	RetentionDays *int32 `json:"retentionDays,omitempty"`
 }
 
 // An AccountSpec defines the desired state of an Account.
@@ -38,11 +209,47 @@ type AccountSpec struct {
 	AccountParameters `json:",inline"`
 }
 
+// AnnotationKeyInitiateFailover is the annotation that, when set to "true",
+// initiates a customer initiated failover of an Account to its secondary
+// location. The annotation is removed once the failover was initiated, or
+// was rejected because the Account cannot currently fail over.
+const AnnotationKeyInitiateFailover = "storage.azure.crossplane.io/initiate-failover"
+
 // An AccountStatus represents the observed state of an Account.
 type AccountStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
 
 	*StorageAccountStatus `json:",inline"`
+
+	// Failover is the state of the most recent failover initiated through
+	// this Account.
+	// +optional
+	Failover *FailoverStatus `json:"failover,omitempty"`
+}
+
+// A FailoverStatus is the observed failover state of an Account.
+type FailoverStatus struct {
+	// InProgress is true while a failover is in progress.
+	// +optional
+	InProgress bool `json:"inProgress,omitempty"`
+
+	// InitiatedTime is when the failover was initiated.
+	// +optional
+	InitiatedTime *metav1.Time `json:"initiatedTime,omitempty"`
+
+	// PrimaryLocation of the Account when it was last observed.
+	// +optional
+	PrimaryLocation string `json:"primaryLocation,omitempty"`
+
+	// SecondaryLocation of the Account when it was last observed.
+	// +optional
+	SecondaryLocation string `json:"secondaryLocation,omitempty"`
+
+	// LastSyncTime is the time before which every write to the primary
+	// location could be read from the secondary one when the Account was last
+	// observed.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -73,24 +280,441 @@ type AccountList struct {
 // ContainerParameters define the desired state of an Azure Blob Storage
 // Container.
 type ContainerParameters struct {
-	// Metadata for this Container.
+	// Metadata for this Container. Values may refer to the variables
+	// {{.ControllerName}}, {{.ControllerVersion}}, {{.Name}}, {{.Namespace}}
+	// and {{.Timestamp}}, which is the time this Container was created. Write
+	// a literal {{ as \{{.
 	// +optional
 	Metadata azblob.Metadata `json:"metadata,omitempty"`
 
+	// MetadataKeyPolicy determines the case in which metadata keys are
+	// written, and compared with the keys Azure reports when detecting
+	// drift. Defaults to Preserve.
+	// +optional
+	// +kubebuilder:validation:Enum=Preserve;Lowercase;AsReturned
+	MetadataKeyPolicy MetadataKeyPolicy `json:"metadataKeyPolicy,omitempty"`
+
+	// DefaultEncryptionScope applied to blobs written to this Container. Blobs
+	// are encrypted using the storage account's encryption settings when it
+	// is unset.
+	// +optional
+	DefaultEncryptionScope string `json:"defaultEncryptionScope,omitempty"`
+
+	// PreventEncryptionScopeOverride prevents blobs from being written to this
+	// Container with any encryption scope other than its default one. The
+	// default encryption scope cannot be removed while it is set.
+	// +optional
+	PreventEncryptionScopeOverride bool `json:"preventEncryptionScopeOverride,omitempty"`
+
+	// CreateEncryptionScope creates the DefaultEncryptionScope in the storage
+	// account if it does not exist, rather than failing to use it.
+	// +optional
+	CreateEncryptionScope bool `json:"createEncryptionScope,omitempty"`
+
+	// EncryptionScopeKeyURI is the URI of the Key Vault key that encrypts a
+	// DefaultEncryptionScope created for this Container. Microsoft managed
+	// keys are used when it is unset. The key of an existing encryption scope
+	// is never changed.
+	// +optional
+	EncryptionScopeKeyURI string `json:"encryptionScopeKeyURI,omitempty"`
+
+	// MetadataFrom references a ConfigMap whose data is merged into the
+	// metadata of this Container. Keys set in Metadata take precedence over
+	// keys of the same name in the ConfigMap.
+	// +optional
+	MetadataFrom *ConfigMapReference `json:"metadataFrom,omitempty"`
+
+	// DefaultContentSettings are the content headers that blobs served from
+	// this Container, for example as a CDN origin, should use by default.
+	// Azure has no such container setting, so they are kept in reserved
+	// metadata keys for the tools that write blobs to read.
+	// +optional
+	DefaultContentSettings *ContentSettings `json:"defaultContentSettings,omitempty"`
+
 	// PublicAccessType for this container; either "blob" or "container".
 	// +optional
 	PublicAccessType azblob.PublicAccessType `json:"publicAccessType,omitempty"`
+
+	// AccessPolicies are the stored access policies of this container, which
+	// shared access signatures may refer to so that they can be revoked by
+	// changing or removing the policy. Policies are written when the
+	// container is created and whenever they drift, replacing any others.
+	// The container's stored access policies are removed when it is empty,
+	// and are not managed when it is unset.
+	// +optional
+	// +kubebuilder:validation:MaxItems=5
+	AccessPolicies *[]StoredAccessPolicy `json:"accessPolicies,omitempty"`
+
+	// ImmutabilityPolicy is the time-based retention policy of this
+	// container, which prevents its blobs from being modified or deleted
+	// until they are older than its retention period. The container's
+	// immutability policy is not managed when it is unset.
+	// +optional
+	ImmutabilityPolicy *ContainerImmutabilityPolicy `json:"immutabilityPolicy,omitempty"`
+
+	// LegalHold of this container, which prevents its blobs from being
+	// modified or deleted while it has any tags, regardless of their age.
+	// The container's legal hold is not managed when it is unset.
+	// +optional
+	LegalHold *ContainerLegalHold `json:"legalHold,omitempty"`
+
+	// AdoptionPolicy determines whether this Container may manage a container
+	// that already existed in Azure before Crossplane created it. Defaults to
+	// AdoptIfExists.
+	// +optional
+	// +kubebuilder:validation:Enum=AdoptIfExists;FailIfExists;ManageExclusively;Import
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// AdoptionGracePeriod is how long an existing container must go
+	// unmodified before this Container adopts or imports it, so that it does
+	// not race another controller that still manages the container. Adoption
+	// is deferred until the container has been stable for this long. Existing
+	// containers are adopted immediately when it is unset.
+	// +optional
+	AdoptionGracePeriod *metav1.Duration `json:"adoptionGracePeriod,omitempty"`
+
+	// DeletionProtection prevents this Container from being deleted. While
+	// it is set, deleting this Container neither deletes the container in
+	// Azure nor removes this Container's finalizer, regardless of its
+	// deletion policy.
+	// +optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// DeleteSnapshots deletes blobs that have snapshots, along with their
+	// snapshots, when they prevent this Container from being deleted.
+	// Otherwise such a Container is not deleted until its snapshots are.
+	// +optional
+	DeleteSnapshots bool `json:"deleteSnapshots,omitempty"`
+
+	// PurgeVersionsOnDelete deletes the blobs of this Container and all of
+	// their previous versions before this Container is deleted, when its
+	// storage account has blob versioning enabled. Blob snapshots are deleted
+	// with their blobs if DeleteSnapshots is also set.
+	// +optional
+	PurgeVersionsOnDelete bool `json:"purgeVersionsOnDelete,omitempty"`
+
+	// ArchiveContainer is a container in the same storage account that the
+	// blobs of this Container are copied to, under a prefix of this
+	// Container's name, before this Container is deleted. The archive
+	// container must already exist. It only applies when the deletion policy
+	// is Delete; nothing is archived when the container is orphaned.
+	// +optional
+	ArchiveContainer string `json:"archiveContainer,omitempty"`
+
+	// RetainConnectionSecret keeps the Secret this Container writes its
+	// connection details to when this Container is deleted. Otherwise the
+	// Secret is deleted along with this Container, unless another resource
+	// controls it.
+	// +optional
+	RetainConnectionSecret bool `json:"retainConnectionSecret,omitempty"`
+
+	// SharedAccessSignatures are container SAS tokens for this Container
+	// that are written to its connection secret, along with its URL, so that
+	// consumers need not use the account key. Each token is written under
+	// its name, and reissued before it expires. Tokens are signed with the
+	// account key in the storage account's connection secret, and cannot be
+	// issued without it.
+	// +optional
+	SharedAccessSignatures []SharedAccessSignature `json:"sharedAccessSignatures,omitempty"`
+}
+
+// An AdoptionPolicy determines how a Container treats a container that
+// already existed in Azure before Crossplane created it.
+type AdoptionPolicy string
+
+// Adoption policies.
+const (
+	// AdoptIfExists manages an existing container as if Crossplane had
+	// created it.
+	AdoptIfExists AdoptionPolicy = "AdoptIfExists"
+
+	// FailIfExists refuses to manage an existing container.
+	FailIfExists AdoptionPolicy = "FailIfExists"
+
+	// ManageExclusively refuses to manage an existing container, and reports
+	// changes made to the container outside of Crossplane as errors.
+	ManageExclusively AdoptionPolicy = "ManageExclusively"
+
+	// Import manages an existing container after backfilling any unset
+	// fields of this Container's spec from it. The existing container is not
+	// changed when it is imported. A container that does not exist is
+	// created.
+	Import AdoptionPolicy = "Import"
+)
+
+// A MetadataKeyPolicy determines the case in which a Container's metadata
+// keys are written to Azure. Azure does not distinguish keys that differ only
+// in case, and does not report keys in the case they were written in, so keys
+// are always compared ignoring case.
+type MetadataKeyPolicy string
+
+// Metadata key policies.
+const (
+	// MetadataKeyPreserve writes metadata keys as they are specified.
+	MetadataKeyPreserve MetadataKeyPolicy = "Preserve"
+
+	// MetadataKeyLowercase writes metadata keys in lower case.
+	MetadataKeyLowercase MetadataKeyPolicy = "Lowercase"
+
+	// MetadataKeyAsReturned writes metadata keys in the canonical form in
+	// which the blob service's HTTP headers return them, for example
+	// Costcenter for costCenter.
+	MetadataKeyAsReturned MetadataKeyPolicy = "AsReturned"
+)
+
+// A ConfigMapReference is a reference to a ConfigMap in an arbitrary
+// namespace.
+type ConfigMapReference struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+
+	// Namespace of the ConfigMap.
+	Namespace string `json:"namespace"`
+}
+
+// ContentSettings are default content headers of blobs.
+type ContentSettings struct {
+	// CacheControl is the default Cache-Control header of blobs.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	CacheControl string `json:"cacheControl,omitempty"`
+
+	// ContentDisposition is the default Content-Disposition header of blobs.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	ContentDisposition string `json:"contentDisposition,omitempty"`
+}
+
+// A StoredAccessPolicy is a stored access policy, or signed identifier, of a
+// container.
+type StoredAccessPolicy struct {
+	// ID of the policy, which shared access signatures refer to it by.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=64
+	ID string `json:"id"`
+
+	// Start is when shared access signatures that refer to the policy
+	// become valid.
+	Start metav1.Time `json:"start"`
+
+	// Expiry is when shared access signatures that refer to the policy stop
+	// being valid.
+	Expiry metav1.Time `json:"expiry"`
+
+	// Permission that shared access signatures that refer to the policy
+	// grant, as any combination of r (read), a (add), c (create), w (write),
+	// d (delete) and l (list).
+	// +kubebuilder:validation:Pattern=`^[racwdl]+$`
+	Permission string `json:"permission"`
+}
+
+// A ContainerImmutabilityPolicy is a time-based retention policy of a
+// container.
+type ContainerImmutabilityPolicy struct {
+	// PeriodSinceCreationInDays is how many days after they are created
+	// blobs may not be modified or deleted.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=146000
+	PeriodSinceCreationInDays int32 `json:"periodSinceCreationInDays"`
+
+	// AllowProtectedAppendWrites allows new blocks to be appended to append
+	// blobs while they are retained. It cannot change once the policy is
+	// locked.
+	// +optional
+	AllowProtectedAppendWrites bool `json:"allowProtectedAppendWrites,omitempty"`
+
+	// Locked policies can no longer be removed, and their retention period
+	// can only be extended. Locking a policy cannot be undone.
+	// +optional
+	Locked bool `json:"locked,omitempty"`
+}
+
+// A ContainerLegalHold is the legal hold of a container.
+type ContainerLegalHold struct {
+	// Tags of the legal hold. Tags the container has but that are not
+	// listed are cleared, and the hold is released when none are listed.
+	// Azure stores tags in lower case.
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	Tags []LegalHoldTag `json:"tags,omitempty"`
 }
 
+// A LegalHoldTag identifies a legal hold, for example a case number.
+// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9]{3,23}$`
+type LegalHoldTag string
+
 // A ContainerSpec defines the desired state of a Container.
 type ContainerSpec struct {
 	xpv1.ResourceSpec   `json:",inline"`
 	ContainerParameters `json:",inline"`
 }
 
+// AnnotationKeyReconcileNow is the annotation that, when present, reconciles
+// a Container immediately rather than at its next poll, for example to
+// correct drift after rotating the account's keys. The annotation is removed
+// before the Container is reconciled.
+const AnnotationKeyReconcileNow = "crossplane.io/reconcile-now"
+
+// AnnotationKeyForceRecreate is the annotation that, when set to the UID of a
+// Container, deletes the Container in Azure and creates it again. This is a
+// last resort for repairing a Container that is in a bad state; every blob in
+// it is lost, unless the Container has an archive container to copy its blobs
+// to before it is deleted. The UID confirms that the Container is meant to be
+// recreated, so that the annotation cannot be applied to the wrong Container
+// by accident. The annotation is removed once the Container's deletion was
+// verified and it is being created again.
+const AnnotationKeyForceRecreate = "storage.azure.crossplane.io/force-recreate"
+
+// ContainerObservation represents the observed state of a Container.
+type ContainerObservation struct {
+	// DefaultEncryptionScope applied to blobs written to this Container.
+	// +optional
+	DefaultEncryptionScope string `json:"defaultEncryptionScope,omitempty"`
+
+	// EncryptionKeyType indicates whether blobs written to this Container are
+	// encrypted with a customer-managed or a Microsoft-managed key.
+	// +optional
+	EncryptionKeyType string `json:"encryptionKeyType,omitempty"`
+
+	// ETag of this Container when it was last observed.
+	// +optional
+	ETag string `json:"etag,omitempty"`
+
+	// DeletionProtected indicates that this Container is protected from
+	// deletion.
+	// +optional
+	DeletionProtected bool `json:"deletionProtected,omitempty"`
+
+	// AuditedPublicAccess is the anonymous public access level of this
+	// Container that was last reported in an audit event. It is cleared when
+	// the Container no longer permits anonymous access.
+	// +optional
+	AuditedPublicAccess string `json:"auditedPublicAccess,omitempty"`
+
+	// PublicAccessDriftSince is when this Container's public access type was
+	// first observed to have drifted from its desired state, if correcting it
+	// is being delayed. It is cleared once the drift is corrected or goes
+	// away.
+	// +optional
+	PublicAccessDriftSince *metav1.Time `json:"publicAccessDriftSince,omitempty"`
+
+	// MetadataRejectedGeneration is the generation of this Container's spec
+	// whose metadata Azure rejected as invalid. Metadata that is rejected is
+	// not written again until the spec changes.
+	// +optional
+	MetadataRejectedGeneration int64 `json:"metadataRejectedGeneration,omitempty"`
+
+	// MetadataKeysSanitized indicates that the metadata Azure rejected was
+	// written with sanitized keys instead.
+	// +optional
+	MetadataKeysSanitized bool `json:"metadataKeysSanitized,omitempty"`
+
+	// CorrelationID is the client request ID of every blob service request
+	// made by the most recent reconcile of this Container, which identifies
+	// them in Azure diagnostic logs. It is derived from this Container's UID
+	// and generation, and is only set while requests are correlated.
+	// +optional
+	CorrelationID string `json:"correlationID,omitempty"`
+}
+
 // A ContainerStatus represents the observed status of a Container.
 type ContainerStatus struct {
 	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ContainerObservation `json:"atProvider,omitempty"`
+
+	// ObservedGeneration is the generation of this Container's spec that was
+	// most recently applied to Azure.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Drift of this Container from its desired state, as observed when it was
+	// last reconciled. It is unset when the Container had not drifted.
+	// +optional
+	Drift *ContainerDrift `json:"drift,omitempty"`
+
+	// Operations are the most recent attempts to create, update, or delete
+	// this Container in Azure, oldest first. Only a bounded number of them
+	// are kept.
+	// +optional
+	Operations []ContainerOperationRecord `json:"operations,omitempty"`
+}
+
+// A ContainerOperation is an operation on a Container in Azure.
+type ContainerOperation string
+
+// Operations on a Container in Azure.
+const (
+	ContainerOperationCreate ContainerOperation = "Create"
+	ContainerOperationUpdate ContainerOperation = "Update"
+	ContainerOperationDelete ContainerOperation = "Delete"
+)
+
+// An OperationOutcome is the outcome of an operation.
+type OperationOutcome string
+
+// Outcomes of operations.
+const (
+	OperationSucceeded OperationOutcome = "Succeeded"
+	OperationFailed    OperationOutcome = "Failed"
+)
+
+// A ContainerOperationRecord records an attempt at an operation on a
+// Container in Azure. Consecutive failed attempts at the same operation are
+// recorded once, as the first of them.
+type ContainerOperationRecord struct {
+	// Operation that was attempted.
+	Operation ContainerOperation `json:"operation"`
+
+	// Outcome of the attempt.
+	Outcome OperationOutcome `json:"outcome"`
+
+	// Time of the attempt.
+	Time metav1.Time `json:"time"`
+
+	// RequestID is the Azure request ID of a failed attempt, which Azure
+	// support can use to trace it. It is unset when Azure did not return
+	// one.
+	// +optional
+	RequestID string `json:"requestID,omitempty"`
+}
+
+// ContainerDrift describes how the observed public access type and metadata
+// of a Container differ from its desired state.
+type ContainerDrift struct {
+	// PublicAccessType of the Container, if it drifted.
+	// +optional
+	PublicAccessType *PublicAccessTypeDrift `json:"publicAccessType,omitempty"`
+
+	// MetadataAdded are the metadata keys the Container has, but should not.
+	// +optional
+	MetadataAdded []string `json:"metadataAdded,omitempty"`
+
+	// MetadataRemoved are the metadata keys the Container should have, but
+	// does not.
+	// +optional
+	MetadataRemoved []string `json:"metadataRemoved,omitempty"`
+
+	// MetadataChanged are the metadata keys whose values differ from their
+	// desired values. The values are not reported, since they may be
+	// sensitive.
+	// +optional
+	MetadataChanged []string `json:"metadataChanged,omitempty"`
+
+	// PlatformMetadata are the metadata keys managed by the controller,
+	// rather than by this Container's spec, that were added, removed, or
+	// changed. They are corrected like any other drift, but are reported
+	// separately from the drift of the keys the spec manages.
+	// +optional
+	PlatformMetadata []string `json:"platformMetadata,omitempty"`
+}
+
+// PublicAccessTypeDrift describes a public access type that drifted.
+type PublicAccessTypeDrift struct {
+	// Desired public access type of the Container.
+	Desired string `json:"desired"`
+
+	// Observed public access type of the Container.
+	Observed string `json:"observed"`
 }
 
 // +kubebuilder:object:root=true
@@ -23,6 +23,8 @@ package v1alpha3
 
 import (
 	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/crossplane/crossplane-runtime/apis/common/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -93,6 +95,33 @@ func (in *AccountParameters) DeepCopyInto(out *AccountParameters) {
 		*out = new(StorageAccountSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BlobService != nil {
+		in, out := &in.BlobService, &out.BlobService
+		*out = new(BlobServiceParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultToOAuthAuthentication != nil {
+		in, out := &in.DefaultToOAuthAuthentication, &out.DefaultToOAuthAuthentication
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowSharedKeyAccess != nil {
+		in, out := &in.AllowSharedKeyAccess, &out.AllowSharedKeyAccess
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinimumTLSVersion != nil {
+		in, out := &in.MinimumTLSVersion, &out.MinimumTLSVersion
+		*out = new(string)
+		**out = **in
+	}
+	if in.SharedAccessSignatures != nil {
+		in, out := &in.SharedAccessSignatures, &out.SharedAccessSignatures
+		*out = make([]SharedAccessSignature, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountParameters.
@@ -131,6 +160,11 @@ func (in *AccountStatus) DeepCopyInto(out *AccountStatus) {
 		*out = new(StorageAccountStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = new(FailoverStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccountStatus.
@@ -143,6 +177,56 @@ func (in *AccountStatus) DeepCopy() *AccountStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlobServiceParameters) DeepCopyInto(out *BlobServiceParameters) {
+	*out = *in
+	if in.HourMetrics != nil {
+		in, out := &in.HourMetrics, &out.HourMetrics
+		*out = new(MetricsParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinuteMetrics != nil {
+		in, out := &in.MinuteMetrics, &out.MinuteMetrics
+		*out = new(MetricsParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(LoggingParameters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SoftDelete != nil {
+		in, out := &in.SoftDelete, &out.SoftDelete
+		*out = new(SoftDeleteParameters)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlobServiceParameters.
+func (in *BlobServiceParameters) DeepCopy() *BlobServiceParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(BlobServiceParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapReference) DeepCopyInto(out *ConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapReference.
+func (in *ConfigMapReference) DeepCopy() *ConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Container) DeepCopyInto(out *Container) {
 	*out = *in
@@ -170,6 +254,81 @@ func (in *Container) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerDrift) DeepCopyInto(out *ContainerDrift) {
+	*out = *in
+	if in.PublicAccessType != nil {
+		in, out := &in.PublicAccessType, &out.PublicAccessType
+		*out = new(PublicAccessTypeDrift)
+		**out = **in
+	}
+	if in.MetadataAdded != nil {
+		in, out := &in.MetadataAdded, &out.MetadataAdded
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MetadataRemoved != nil {
+		in, out := &in.MetadataRemoved, &out.MetadataRemoved
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MetadataChanged != nil {
+		in, out := &in.MetadataChanged, &out.MetadataChanged
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PlatformMetadata != nil {
+		in, out := &in.PlatformMetadata, &out.PlatformMetadata
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerDrift.
+func (in *ContainerDrift) DeepCopy() *ContainerDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerImmutabilityPolicy) DeepCopyInto(out *ContainerImmutabilityPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerImmutabilityPolicy.
+func (in *ContainerImmutabilityPolicy) DeepCopy() *ContainerImmutabilityPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerImmutabilityPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerLegalHold) DeepCopyInto(out *ContainerLegalHold) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]LegalHoldTag, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerLegalHold.
+func (in *ContainerLegalHold) DeepCopy() *ContainerLegalHold {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerLegalHold)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerList) DeepCopyInto(out *ContainerList) {
 	*out = *in
@@ -202,6 +361,41 @@ func (in *ContainerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerObservation) DeepCopyInto(out *ContainerObservation) {
+	*out = *in
+	if in.PublicAccessDriftSince != nil {
+		in, out := &in.PublicAccessDriftSince, &out.PublicAccessDriftSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerObservation.
+func (in *ContainerObservation) DeepCopy() *ContainerObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerOperationRecord) DeepCopyInto(out *ContainerOperationRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerOperationRecord.
+func (in *ContainerOperationRecord) DeepCopy() *ContainerOperationRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerOperationRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerParameters) DeepCopyInto(out *ContainerParameters) {
 	*out = *in
@@ -212,6 +406,49 @@ func (in *ContainerParameters) DeepCopyInto(out *ContainerParameters) {
 			(*out)[key] = val
 		}
 	}
+	if in.MetadataFrom != nil {
+		in, out := &in.MetadataFrom, &out.MetadataFrom
+		*out = new(ConfigMapReference)
+		**out = **in
+	}
+	if in.DefaultContentSettings != nil {
+		in, out := &in.DefaultContentSettings, &out.DefaultContentSettings
+		*out = new(ContentSettings)
+		**out = **in
+	}
+	if in.AccessPolicies != nil {
+		in, out := &in.AccessPolicies, &out.AccessPolicies
+		*out = new([]StoredAccessPolicy)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make([]StoredAccessPolicy, len(*in))
+			for i := range *in {
+				(*in)[i].DeepCopyInto(&(*out)[i])
+			}
+		}
+	}
+	if in.ImmutabilityPolicy != nil {
+		in, out := &in.ImmutabilityPolicy, &out.ImmutabilityPolicy
+		*out = new(ContainerImmutabilityPolicy)
+		**out = **in
+	}
+	if in.LegalHold != nil {
+		in, out := &in.LegalHold, &out.LegalHold
+		*out = new(ContainerLegalHold)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdoptionGracePeriod != nil {
+		in, out := &in.AdoptionGracePeriod, &out.AdoptionGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.SharedAccessSignatures != nil {
+		in, out := &in.SharedAccessSignatures, &out.SharedAccessSignatures
+		*out = make([]SharedAccessSignature, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerParameters.
@@ -245,6 +482,19 @@ func (in *ContainerSpec) DeepCopy() *ContainerSpec {
 func (in *ContainerStatus) DeepCopyInto(out *ContainerStatus) {
 	*out = *in
 	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(ContainerDrift)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]ContainerOperationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerStatus.
@@ -257,6 +507,21 @@ func (in *ContainerStatus) DeepCopy() *ContainerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContentSettings) DeepCopyInto(out *ContentSettings) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContentSettings.
+func (in *ContentSettings) DeepCopy() *ContentSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ContentSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomDomain) DeepCopyInto(out *CustomDomain) {
 	*out = *in
@@ -327,6 +592,29 @@ func (in *Endpoints) DeepCopy() *Endpoints {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailoverStatus) DeepCopyInto(out *FailoverStatus) {
+	*out = *in
+	if in.InitiatedTime != nil {
+		in, out := &in.InitiatedTime, &out.InitiatedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailoverStatus.
+func (in *FailoverStatus) DeepCopy() *FailoverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FailoverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPRule) DeepCopyInto(out *IPRule) {
 	*out = *in
@@ -372,6 +660,341 @@ func (in *KeyVaultProperties) DeepCopy() *KeyVaultProperties {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingParameters) DeepCopyInto(out *LoggingParameters) {
+	*out = *in
+	if in.RetentionDays != nil {
+		in, out := &in.RetentionDays, &out.RetentionDays
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingParameters.
+func (in *LoggingParameters) DeepCopy() *LoggingParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicy) DeepCopyInto(out *ManagementPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicy.
+func (in *ManagementPolicy) DeepCopy() *ManagementPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagementPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicyActions) DeepCopyInto(out *ManagementPolicyActions) {
+	*out = *in
+	if in.BaseBlob != nil {
+		in, out := &in.BaseBlob, &out.BaseBlob
+		*out = new(ManagementPolicyBaseBlobActions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(ManagementPolicyVersionActions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(ManagementPolicyVersionActions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicyActions.
+func (in *ManagementPolicyActions) DeepCopy() *ManagementPolicyActions {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicyActions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicyBaseBlobActions) DeepCopyInto(out *ManagementPolicyBaseBlobActions) {
+	*out = *in
+	if in.TierToCoolAfterDays != nil {
+		in, out := &in.TierToCoolAfterDays, &out.TierToCoolAfterDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TierToArchiveAfterDays != nil {
+		in, out := &in.TierToArchiveAfterDays, &out.TierToArchiveAfterDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DeleteAfterDays != nil {
+		in, out := &in.DeleteAfterDays, &out.DeleteAfterDays
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicyBaseBlobActions.
+func (in *ManagementPolicyBaseBlobActions) DeepCopy() *ManagementPolicyBaseBlobActions {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicyBaseBlobActions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicyFilters) DeepCopyInto(out *ManagementPolicyFilters) {
+	*out = *in
+	if in.PrefixMatch != nil {
+		in, out := &in.PrefixMatch, &out.PrefixMatch
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BlobTypes != nil {
+		in, out := &in.BlobTypes, &out.BlobTypes
+		*out = make([]BlobType, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicyFilters.
+func (in *ManagementPolicyFilters) DeepCopy() *ManagementPolicyFilters {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicyFilters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicyList) DeepCopyInto(out *ManagementPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ManagementPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicyList.
+func (in *ManagementPolicyList) DeepCopy() *ManagementPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagementPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicyObservation) DeepCopyInto(out *ManagementPolicyObservation) {
+	*out = *in
+	if in.LastModifiedTime != nil {
+		in, out := &in.LastModifiedTime, &out.LastModifiedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicyObservation.
+func (in *ManagementPolicyObservation) DeepCopy() *ManagementPolicyObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicyObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicyParameters) DeepCopyInto(out *ManagementPolicyParameters) {
+	*out = *in
+	if in.ResourceGroupNameRef != nil {
+		in, out := &in.ResourceGroupNameRef, &out.ResourceGroupNameRef
+		*out = new(v1.Reference)
+		**out = **in
+	}
+	if in.ResourceGroupNameSelector != nil {
+		in, out := &in.ResourceGroupNameSelector, &out.ResourceGroupNameSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AccountNameRef != nil {
+		in, out := &in.AccountNameRef, &out.AccountNameRef
+		*out = new(v1.Reference)
+		**out = **in
+	}
+	if in.AccountNameSelector != nil {
+		in, out := &in.AccountNameSelector, &out.AccountNameSelector
+		*out = new(v1.Selector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]ManagementPolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicyParameters.
+func (in *ManagementPolicyParameters) DeepCopy() *ManagementPolicyParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicyParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicyRule) DeepCopyInto(out *ManagementPolicyRule) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = new(ManagementPolicyFilters)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Actions.DeepCopyInto(&out.Actions)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicyRule.
+func (in *ManagementPolicyRule) DeepCopy() *ManagementPolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicySpec) DeepCopyInto(out *ManagementPolicySpec) {
+	*out = *in
+	in.ResourceSpec.DeepCopyInto(&out.ResourceSpec)
+	in.ForProvider.DeepCopyInto(&out.ForProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicySpec.
+func (in *ManagementPolicySpec) DeepCopy() *ManagementPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicyStatus) DeepCopyInto(out *ManagementPolicyStatus) {
+	*out = *in
+	in.ResourceStatus.DeepCopyInto(&out.ResourceStatus)
+	in.AtProvider.DeepCopyInto(&out.AtProvider)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicyStatus.
+func (in *ManagementPolicyStatus) DeepCopy() *ManagementPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementPolicyVersionActions) DeepCopyInto(out *ManagementPolicyVersionActions) {
+	*out = *in
+	if in.TierToCoolAfterDays != nil {
+		in, out := &in.TierToCoolAfterDays, &out.TierToCoolAfterDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TierToArchiveAfterDays != nil {
+		in, out := &in.TierToArchiveAfterDays, &out.TierToArchiveAfterDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DeleteAfterDays != nil {
+		in, out := &in.DeleteAfterDays, &out.DeleteAfterDays
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementPolicyVersionActions.
+func (in *ManagementPolicyVersionActions) DeepCopy() *ManagementPolicyVersionActions {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementPolicyVersionActions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsParameters) DeepCopyInto(out *MetricsParameters) {
+	*out = *in
+	if in.RetentionDays != nil {
+		in, out := &in.RetentionDays, &out.RetentionDays
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsParameters.
+func (in *MetricsParameters) DeepCopy() *MetricsParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkRuleSet) DeepCopyInto(out *NetworkRuleSet) {
 	*out = *in
@@ -397,6 +1020,46 @@ func (in *NetworkRuleSet) DeepCopy() *NetworkRuleSet {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublicAccessTypeDrift) DeepCopyInto(out *PublicAccessTypeDrift) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublicAccessTypeDrift.
+func (in *PublicAccessTypeDrift) DeepCopy() *PublicAccessTypeDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(PublicAccessTypeDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedAccessSignature) DeepCopyInto(out *SharedAccessSignature) {
+	*out = *in
+	if in.Validity != nil {
+		in, out := &in.Validity, &out.Validity
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedAccessSignature.
+func (in *SharedAccessSignature) DeepCopy() *SharedAccessSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedAccessSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Sku) DeepCopyInto(out *Sku) {
 	*out = *in
@@ -422,6 +1085,21 @@ func (in *Sku) DeepCopy() *Sku {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoftDeleteParameters) DeepCopyInto(out *SoftDeleteParameters) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoftDeleteParameters.
+func (in *SoftDeleteParameters) DeepCopy() *SoftDeleteParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(SoftDeleteParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageAccountSpec) DeepCopyInto(out *StorageAccountSpec) {
 	*out = *in
@@ -542,6 +1220,23 @@ func (in *StorageAccountStatusProperties) DeepCopy() *StorageAccountStatusProper
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoredAccessPolicy) DeepCopyInto(out *StoredAccessPolicy) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	in.Expiry.DeepCopyInto(&out.Expiry)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoredAccessPolicy.
+func (in *StoredAccessPolicy) DeepCopy() *StoredAccessPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StoredAccessPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualNetworkRule) DeepCopyInto(out *VirtualNetworkRule) {
 	*out = *in
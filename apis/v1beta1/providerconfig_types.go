@@ -22,18 +22,198 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 )
 
+// An Environment tags a ProviderConfig with the kind of environment it
+// manages resources in.
+type Environment string
+
+// Environments.
+const (
+	// EnvironmentProduction forbids public storage containers.
+	EnvironmentProduction Environment = "Production"
+
+	// EnvironmentDevelopment permits public storage containers.
+	EnvironmentDevelopment Environment = "Development"
+)
+
+// A Cloud is an Azure cloud, which determines the endpoints of the Azure
+// Resource Manager, Azure Active Directory and storage services.
+type Cloud string
+
+// Clouds.
+const (
+	CloudPublic       Cloud = "AzurePublicCloud"
+	CloudUSGovernment Cloud = "AzureUSGovernmentCloud"
+	CloudChina        Cloud = "AzureChinaCloud"
+	CloudGerman       Cloud = "AzureGermanCloud"
+)
+
 // A ProviderConfigSpec defines the desired state of a ProviderConfig.
 type ProviderConfigSpec struct {
 	// Credentials required to authenticate to this provider.
 	Credentials ProviderCredentials `json:"credentials"`
+
+	// Environment this provider manages resources in. Environment specific
+	// guardrails are applied to resources that use this provider. No
+	// guardrails are applied when it is unset.
+	// +optional
+	// +kubebuilder:validation:Enum=Production;Development
+	Environment Environment `json:"environment,omitempty"`
+
+	// Cloud this provider manages resources in. It switches the Azure
+	// Resource Manager, Azure Active Directory, Graph and storage endpoints
+	// of every client to those of the cloud, overriding any endpoints the
+	// credentials set. The public cloud, or the endpoints the credentials
+	// set, are used when it is unset.
+	// +optional
+	// +kubebuilder:validation:Enum=AzurePublicCloud;AzureUSGovernmentCloud;AzureChinaCloud;AzureGermanCloud
+	Cloud Cloud `json:"cloud,omitempty"`
+
+	// ContainerDefaultMetadata is merged into the metadata of every storage
+	// Container that uses this provider. Keys set by a Container, including
+	// keys from its MetadataFrom ConfigMap, take precedence over these. Values
+	// may refer to the same variables as the Container's metadata.
+	// +optional
+	ContainerDefaultMetadata map[string]string `json:"containerDefaultMetadata,omitempty"`
+
+	// ContainerAllowedMetadataKeys are the only metadata keys that storage
+	// Containers that use this provider may set, including keys merged in
+	// from their MetadataFrom ConfigMap and the ContainerDefaultMetadata.
+	// Keys are matched ignoring case. Containers with any other key are not
+	// created or updated. Any key is allowed when it is empty.
+	// +optional
+	ContainerAllowedMetadataKeys []string `json:"containerAllowedMetadataKeys,omitempty"`
+
+	// ContainerAllowedLocations are the only Azure locations, such as
+	// westeurope, whose storage accounts may hold storage Containers that use
+	// this provider. Containers in accounts elsewhere are neither created nor
+	// updated. Locations are matched ignoring case and spaces. Containers may
+	// be in accounts in any location when it is empty.
+	// +optional
+	ContainerAllowedLocations []string `json:"containerAllowedLocations,omitempty"`
+
+	// MaxConcurrentStorageRequests limits the number of requests that storage
+	// Containers that use this provider may make concurrently against each
+	// storage account. Requests beyond the limit wait for earlier ones to
+	// finish. Requests are not limited when it is unset or zero.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxConcurrentStorageRequests int `json:"maxConcurrentStorageRequests,omitempty"`
+
+	// BlobServiceAPIVersion pins the blob service API version that storage
+	// Containers that use this provider are managed with, such as
+	// 2019-02-02, for clouds that lag the versions the provider targets.
+	// Requests for features that need a newer version, such as encryption
+	// scopes, are still made with that version when it is older. The
+	// provider's own versions are used when it is unset.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`
+	BlobServiceAPIVersion string `json:"blobServiceAPIVersion,omitempty"`
+
+	// BlobFallbackEndpoint is the URL of a blob service endpoint that storage
+	// Containers that use this provider read from again when reading them
+	// from their storage account's primary endpoint fails with a network
+	// error, for example because it does not resolve during a regional
+	// outage. Any %s in it is replaced with the name of the storage account,
+	// such as https://%s-secondary.blob.core.windows.net for the secondary
+	// endpoint of read-access geo-redundant accounts. Containers are never
+	// written through it. Reads do not fall back when it is unset.
+	// +optional
+	BlobFallbackEndpoint string `json:"blobFallbackEndpoint,omitempty"`
+
+	// MaxReconcileBackoff is the longest that storage Containers that use
+	// this provider wait to be retried while reconciling them keeps failing.
+	// The wait grows exponentially with each consecutive failure up to this
+	// ceiling, so that failing Containers recover soon after the cause of
+	// the failure, such as invalid credentials, is fixed. Failing Containers
+	// are retried with the controller's default rate limiting when it is
+	// unset.
+	// +optional
+	MaxReconcileBackoff *metav1.Duration `json:"maxReconcileBackoff,omitempty"`
+
+	// StorageRetry configures how storage Containers that use this provider
+	// retry failed blob service requests. Requests are retried with the
+	// provider's exponential defaults when it is unset.
+	// +optional
+	StorageRetry *StorageRetryOptions `json:"storageRetry,omitempty"`
 }
 
+// A StorageRetryPolicy determines how long failed storage requests wait
+// before they are retried.
+type StorageRetryPolicy string
+
+// Storage retry policies.
+const (
+	// StorageRetryExponential waits exponentially longer after each failed
+	// try, up to the maximum retry delay.
+	StorageRetryExponential StorageRetryPolicy = "Exponential"
+
+	// StorageRetryFixed waits the retry delay after every failed try.
+	StorageRetryFixed StorageRetryPolicy = "Fixed"
+)
+
+// StorageRetryOptions configure how failed storage requests are retried.
+// Unset fields take the defaults of the Azure storage SDK.
+type StorageRetryOptions struct {
+	// Policy determines how long failed requests wait before they are
+	// retried.
+	// +optional
+	// +kubebuilder:validation:Enum=Exponential;Fixed
+	// +kubebuilder:default=Exponential
+	Policy StorageRetryPolicy `json:"policy,omitempty"`
+
+	// MaxTries is the most times a request is tried, including the first
+	// try.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxTries int32 `json:"maxTries,omitempty"`
+
+	// TryTimeout is the longest a single try may take.
+	// +optional
+	TryTimeout *metav1.Duration `json:"tryTimeout,omitempty"`
+
+	// RetryDelay is how long a failed request waits before it is retried
+	// under the Fixed policy, and the base of the delay under the
+	// Exponential policy.
+	// +optional
+	RetryDelay *metav1.Duration `json:"retryDelay,omitempty"`
+
+	// MaxRetryDelay is the longest a failed request waits before it is
+	// retried.
+	// +optional
+	MaxRetryDelay *metav1.Duration `json:"maxRetryDelay,omitempty"`
+}
+
+// CredentialsSourceManagedIdentity authenticates as the managed identity of
+// the node or pod the provider runs on, using the Azure Instance Metadata
+// Service.
+const CredentialsSourceManagedIdentity xpv1.CredentialsSource = "ManagedIdentity"
+
 // ProviderCredentials required to authenticate.
 type ProviderCredentials struct {
-	// Source of the provider credentials.
-	// +kubebuilder:validation:Enum=None;Secret;Environment;Filesystem
+	// Source of the provider credentials. InjectedIdentity authenticates as
+	// the Azure AD application that Azure Workload Identity federates with
+	// the provider's service account, using the client ID, tenant ID and
+	// federated token file it injects into the provider's pod.
+	// ManagedIdentity authenticates as the system-assigned managed identity
+	// of the node the provider runs on, or the user-assigned identity named
+	// by ClientID.
+	// +kubebuilder:validation:Enum=None;Secret;InjectedIdentity;ManagedIdentity;Environment;Filesystem
 	Source xpv1.CredentialsSource `json:"source"`
 
+	// SubscriptionID is the Azure subscription that InjectedIdentity and
+	// ManagedIdentity credentials manage. It defaults to the
+	// AZURE_SUBSCRIPTION_ID environment variable of the provider's pod. It
+	// is ignored by other sources, whose credentials include their
+	// subscription.
+	// +optional
+	SubscriptionID string `json:"subscriptionId,omitempty"`
+
+	// ClientID of the user-assigned managed identity that ManagedIdentity
+	// credentials authenticate as. The system-assigned identity is used if
+	// it is omitted. It is ignored by other sources.
+	// +optional
+	ClientID string `json:"clientId,omitempty"`
+
 	xpv1.CommonCredentialSelectors `json:",inline"`
 }
 
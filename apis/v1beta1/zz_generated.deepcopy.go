@@ -22,6 +22,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -88,6 +89,33 @@ func (in *ProviderConfigList) DeepCopyObject() runtime.Object {
 func (in *ProviderConfigSpec) DeepCopyInto(out *ProviderConfigSpec) {
 	*out = *in
 	in.Credentials.DeepCopyInto(&out.Credentials)
+	if in.ContainerDefaultMetadata != nil {
+		in, out := &in.ContainerDefaultMetadata, &out.ContainerDefaultMetadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ContainerAllowedMetadataKeys != nil {
+		in, out := &in.ContainerAllowedMetadataKeys, &out.ContainerAllowedMetadataKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContainerAllowedLocations != nil {
+		in, out := &in.ContainerAllowedLocations, &out.ContainerAllowedLocations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxReconcileBackoff != nil {
+		in, out := &in.MaxReconcileBackoff, &out.MaxReconcileBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.StorageRetry != nil {
+		in, out := &in.StorageRetry, &out.StorageRetry
+		*out = new(StorageRetryOptions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigSpec.
@@ -189,3 +217,33 @@ func (in *ProviderCredentials) DeepCopy() *ProviderCredentials {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageRetryOptions) DeepCopyInto(out *StorageRetryOptions) {
+	*out = *in
+	if in.TryTimeout != nil {
+		in, out := &in.TryTimeout, &out.TryTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RetryDelay != nil {
+		in, out := &in.RetryDelay, &out.RetryDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxRetryDelay != nil {
+		in, out := &in.MaxRetryDelay, &out.MaxRetryDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageRetryOptions.
+func (in *StorageRetryOptions) DeepCopy() *StorageRetryOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageRetryOptions)
+	in.DeepCopyInto(out)
+	return out
+}
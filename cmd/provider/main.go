@@ -39,6 +39,8 @@ import (
 	"github.com/crossplane-contrib/provider-azure/apis"
 	"github.com/crossplane-contrib/provider-azure/apis/v1alpha1"
 	"github.com/crossplane-contrib/provider-azure/pkg/controller"
+	"github.com/crossplane-contrib/provider-azure/pkg/controller/storage/account"
+	"github.com/crossplane-contrib/provider-azure/pkg/controller/storage/container"
 	"github.com/crossplane-contrib/provider-azure/pkg/features"
 )
 
@@ -53,6 +55,10 @@ func main() {
 
 		namespace                  = app.Flag("namespace", "Namespace used to set as default scope in default secret store config.").Default("crossplane-system").Envar("POD_NAMESPACE").String()
 		enableExternalSecretStores = app.Flag("enable-external-secret-stores", "Enable support for ExternalSecretStores.").Default("false").Envar("ENABLE_EXTERNAL_SECRET_STORES").Bool()
+		observeOnly                = app.Flag("observe-only", "Report drift of storage containers without ever mutating them.").Default("false").Envar("OBSERVE_ONLY").Bool()
+		containerOptions           = containerFlags(app)
+		accountKeyTTL              = app.Flag("account-key-ttl", "How long storage account keys are cached before they are listed again. Keys are not cached if it is zero.").Default("5m").Envar("ACCOUNT_KEY_TTL").Duration()
+		ensureResourceGroups       = app.Flag("ensure-resource-groups", "Create the resource group of a storage account, unless it already exists, before creating the account.").Default("false").Envar("ENSURE_RESOURCE_GROUPS").Bool()
 	)
 	kingpin.MustParse(app.Parse(os.Args[1:]))
 
@@ -116,6 +122,66 @@ func main() {
 		})), "cannot create default store config")
 	}
 
-	kingpin.FatalIfError(controller.Setup(mgr, o), "Cannot setup Azure controllers")
+	if *observeOnly {
+		o.Features.Enable(features.ObserveOnly)
+		log.Info("Feature enabled", "flag", features.ObserveOnly)
+	}
+
+	if *ensureResourceGroups {
+		o.Features.Enable(features.EnsureResourceGroups)
+		log.Info("Feature enabled", "flag", features.EnsureResourceGroups)
+	}
+
+	kingpin.FatalIfError(controller.Setup(mgr, o, account.Options{KeyTTL: *accountKeyTTL}, containerOptions()), "Cannot setup Azure controllers")
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
+
+// containerFlags registers the flags that configure the storage container
+// controller, and returns a function that builds its options once they have
+// been parsed. Options that have no flag keep their defaults.
+func containerFlags(app *kingpin.Application) func() container.Options {
+	var (
+		reconcileJitter          = app.Flag("reconcile-jitter", "Fraction of the poll interval by which storage container requeues are randomized, to avoid reconciling many containers at once.").Default("0.1").Envar("RECONCILE_JITTER").Float64()
+		auditPublicAccess        = app.Flag("audit-public-access", "Record an audit event when a storage container is observed to permit anonymous public access.").Default("true").Envar("AUDIT_PUBLIC_ACCESS").Bool()
+		maxMetadataValueLength   = app.Flag("max-metadata-value-length", "Longest, in bytes, that each metadata value of a storage container may be. Values are not limited if it is zero.").Default("4096").Envar("MAX_METADATA_VALUE_LENGTH").Int()
+		publicAccessRemediation  = app.Flag("public-access-remediation-delay", "How long the public access type of a storage container may drift before it is corrected. Drift is corrected immediately if it is zero.").Default("0").Envar("PUBLIC_ACCESS_REMEDIATION_DELAY").Duration()
+		snapshotBeforeDelete     = app.Flag("snapshot-container-before-delete", "Record the public access type and metadata of a storage container in an event before it is deleted.").Default("false").Envar("SNAPSHOT_CONTAINER_BEFORE_DELETE").Bool()
+		maxContainerOperations   = app.Flag("max-container-operations-per-reconcile", "Maximum number of blob service requests each reconcile of a storage container may send. Requests are not limited if it is zero.").Default("0").Envar("MAX_CONTAINER_OPERATIONS_PER_RECONCILE").Int()
+		orphanedSecretSweep      = app.Flag("orphaned-secret-sweep-interval", "How often connection secrets of storage containers that no longer exist are deleted. Orphaned secrets are not swept if it is zero.").Default("0").Envar("ORPHANED_SECRET_SWEEP_INTERVAL").Duration()
+		sanitizeMetadataKeys     = app.Flag("sanitize-metadata-keys", "Retry writing storage container metadata that Azure rejected as invalid once, with its keys made valid C# identifiers.").Default("false").Envar("SANITIZE_METADATA_KEYS").Bool()
+		managedMetadataKeys      = app.Flag("managed-metadata-key", "A storage container metadata key managed by the platform rather than by container specs, whose drift is reported separately. May be repeated.").Envar("MANAGED_METADATA_KEYS").Strings()
+		ownerMetadataKey         = app.Flag("owner-metadata-key", "A storage container metadata key through which other tools claim containers. Containers it claims for another owner are never changed. Ownership is not checked if it is empty.").Default("").Envar("OWNER_METADATA_KEY").String()
+		ownerIdentity            = app.Flag("owner-identity", "The value of the owner metadata key that claims a storage container for this provider.").Default("crossplane").Envar("OWNER_IDENTITY").String()
+		suppressUnchangedStatus  = app.Flag("suppress-unchanged-status-writes", "Skip writing the status of a storage container when a reconcile left it unchanged.").Default("false").Envar("SUPPRESS_UNCHANGED_STATUS_WRITES").Bool()
+		credentialPreference     = app.Flag("credential-preference", "A kind of credential to manage storage containers with: token, sas or sharedKey. May be repeated, most preferred first; the first available kind is used.").Envar("CREDENTIAL_PREFERENCE").Enums(string(container.CredentialToken), string(container.CredentialSAS), string(container.CredentialSharedKey))
+		checkAccountPublicAccess = app.Flag("check-account-public-access", "Fail storage containers that ask for public access before writing them if their storage account disallows blob public access.").Default("false").Envar("CHECK_ACCOUNT_PUBLIC_ACCESS").Bool()
+		accountPublicAccessTTL   = app.Flag("account-public-access-ttl", "How long whether a storage account allows blob public access is cached. It is checked before every write when zero.").Default("1m").Envar("ACCOUNT_PUBLIC_ACCESS_TTL").Duration()
+		recordTransitionEvents   = app.Flag("record-transition-events", "Record an event each time a storage container is created, updated or deleted, or its drift is detected or corrected.").Default("false").Envar("RECORD_TRANSITION_EVENTS").Bool()
+		correlateRequests        = app.Flag("correlate-requests", "Make every Azure blob service request of a storage container reconcile with the same client request ID, and record it in the container's status.").Default("false").Envar("CORRELATE_REQUESTS").Bool()
+		restoreSoftDeleted       = app.Flag("restore-soft-deleted-accounts", "Restore the storage account of a storage container when it is found to be soft-deleted, rather than only reporting that it must be restored.").Default("false").Envar("RESTORE_SOFT_DELETED_ACCOUNTS").Bool()
+	)
+	return func() container.Options {
+		o := container.DefaultOptions()
+		o.Jitter = *reconcileJitter
+		o.AuditPublicAccess = *auditPublicAccess
+		o.MaxMetadataValueLength = *maxMetadataValueLength
+		o.PublicAccessRemediationDelay = *publicAccessRemediation
+		o.SnapshotBeforeDelete = *snapshotBeforeDelete
+		o.MaxOperationsPerReconcile = *maxContainerOperations
+		o.OrphanedSecretSweepInterval = *orphanedSecretSweep
+		o.SanitizeMetadataKeys = *sanitizeMetadataKeys
+		o.ManagedMetadataKeys = *managedMetadataKeys
+		o.OwnerMetadataKey = *ownerMetadataKey
+		o.OwnerIdentity = *ownerIdentity
+		o.SuppressUnchangedStatus = *suppressUnchangedStatus
+		o.CheckAccountPublicAccess = *checkAccountPublicAccess
+		o.AccountPublicAccessTTL = *accountPublicAccessTTL
+		o.RecordTransitionEvents = *recordTransitionEvents
+		o.CorrelateRequests = *correlateRequests
+		o.RestoreSoftDeletedAccounts = *restoreSoftDeleted
+		for _, k := range *credentialPreference {
+			o.CredentialPreference = append(o.CredentialPreference, container.CredentialKind(k))
+		}
+		return o
+	}
+}
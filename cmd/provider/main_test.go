@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/crossplane-contrib/provider-azure/pkg/controller/storage/container"
+)
+
+// flagDefaults returns the container controller's default options, with the
+// options whose flags default to something else set to those defaults.
+func flagDefaults() container.Options {
+	o := container.DefaultOptions()
+	o.OwnerIdentity = "crossplane"
+	o.AccountPublicAccessTTL = time.Minute
+	return o
+}
+
+func TestContainerFlags(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		args   []string
+		want   func() container.Options
+	}{
+		"Defaults": {
+			reason: "Options should be the controller's defaults when no flags are set.",
+			want:   flagDefaults,
+		},
+		"Flags": {
+			reason: "Flags should override only the options they configure.",
+			args: []string{
+				"--reconcile-jitter=0.2",
+				"--no-audit-public-access",
+				"--snapshot-container-before-delete",
+				"--managed-metadata-key=team",
+				"--credential-preference=token",
+				"--credential-preference=sharedKey",
+				"--restore-soft-deleted-accounts",
+			},
+			want: func() container.Options {
+				o := flagDefaults()
+				o.Jitter = 0.2
+				o.AuditPublicAccess = false
+				o.SnapshotBeforeDelete = true
+				o.ManagedMetadataKeys = []string{"team"}
+				o.CredentialPreference = []container.CredentialKind{container.CredentialToken, container.CredentialSharedKey}
+				o.RestoreSoftDeletedAccounts = true
+				return o
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			app := kingpin.New("provider", "")
+			opts := containerFlags(app)
+			if _, err := app.Parse(tc.args); err != nil {
+				t.Fatalf("\n%s\napp.Parse(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want(), opts()); diff != "" {
+				t.Errorf("\n%s\ncontainerFlags(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -133,6 +133,24 @@ func UseProviderConfig(ctx context.Context, c client.Client, mg resource.Managed
 		return nil, nil, errors.Wrap(err, errGetProviderConfig)
 	}
 
+	if m, ok, err := identityCredentials(pc.Spec.Credentials); ok {
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := useCloud(m, pc.Spec.Cloud); err != nil {
+			return nil, nil, err
+		}
+		res := m[CredentialsKeyResourceManagerEndpointURL]
+		if res == "" {
+			res = azure.PublicCloud.ResourceManagerEndpoint
+		}
+		spt, err := NewServicePrincipalToken(m, res)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errGetAuthorizer)
+		}
+		return m, autorest.NewBearerAuthorizer(spt), nil
+	}
+
 	data, err := resource.CommonCredentialExtractor(ctx, pc.Spec.Credentials.Source, c, pc.Spec.Credentials.CommonCredentialSelectors)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "cannot get credentials")
@@ -141,6 +159,9 @@ func UseProviderConfig(ctx context.Context, c client.Client, mg resource.Managed
 	if err := json.Unmarshal(data, &m); err != nil {
 		return nil, nil, errors.Wrap(err, errUnmarshalCredentialSecret)
 	}
+	if err := useCloud(m, pc.Spec.Cloud); err != nil {
+		return nil, nil, err
+	}
 	cfg := auth.NewClientCredentialsConfig(m[CredentialsKeyClientID], m[CredentialsKeyClientSecret], m[CredentialsKeyTenantID])
 	cfg.AADEndpoint = m[CredentialsKeyActiveDirectoryEndpointURL]
 	cfg.Resource = m[CredentialsKeyResourceManagerEndpointURL]
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-azure/apis/v1beta1"
+)
+
+// CredentialsKeyStorageEndpointSuffix is the key of the DNS suffix of the
+// storage service endpoints of the cloud that credentials authenticate to,
+// such as core.windows.net.
+const CredentialsKeyStorageEndpointSuffix = "storageEndpointSuffix"
+
+const errUnknownCloud = "unknown cloud %q"
+
+// CloudEnvironment returns the environment, and thus the service endpoints, of
+// the supplied cloud. The public cloud is returned when it is empty.
+func CloudEnvironment(c v1beta1.Cloud) (azure.Environment, error) {
+	if c == "" {
+		return azure.PublicCloud, nil
+	}
+	env, err := azure.EnvironmentFromName(string(c))
+	return env, errors.Wrapf(err, errUnknownCloud, c)
+}
+
+// useCloud sets the endpoints of the supplied cloud in the supplied
+// credentials, overriding any endpoints they set. Credentials are left as is
+// when the cloud is empty.
+func useCloud(creds map[string]string, c v1beta1.Cloud) error {
+	if c == "" {
+		return nil
+	}
+	env, err := CloudEnvironment(c)
+	if err != nil {
+		return err
+	}
+	creds[CredentialsKeyActiveDirectoryEndpointURL] = env.ActiveDirectoryEndpoint
+	creds[CredentialsKeyResourceManagerEndpointURL] = env.ResourceManagerEndpoint
+	creds[CredentialsKeyActiveDirectoryGraphResourceID] = env.GraphEndpoint
+	creds[CredentialsKeyStorageEndpointSuffix] = env.StorageEndpointSuffix
+	return nil
+}
+
+// ResourceManagerEndpoint returns the base URI of the Azure Resource Manager
+// endpoint of the supplied credentials, or that of the public cloud if they
+// do not set one.
+func ResourceManagerEndpoint(creds map[string]string) string {
+	return baseURI(creds[CredentialsKeyResourceManagerEndpointURL], azure.PublicCloud.ResourceManagerEndpoint)
+}
+
+// GraphEndpoint returns the base URI of the Azure Active Directory Graph
+// endpoint of the supplied credentials, or that of the public cloud if they
+// do not set one.
+func GraphEndpoint(creds map[string]string) string {
+	return baseURI(creds[CredentialsKeyActiveDirectoryGraphResourceID], azure.PublicCloud.GraphEndpoint)
+}
+
+// StorageEndpointSuffix returns the DNS suffix of the storage service
+// endpoints of the supplied credentials, or that of the public cloud if they
+// do not set one.
+func StorageEndpointSuffix(creds map[string]string) string {
+	if s := creds[CredentialsKeyStorageEndpointSuffix]; s != "" {
+		return s
+	}
+	return azure.PublicCloud.StorageEndpointSuffix
+}
+
+// baseURI returns the supplied endpoint, or the supplied default if it is
+// empty, without the trailing slash that Azure SDK base URIs omit.
+func baseURI(endpoint, def string) string {
+	if endpoint == "" {
+		endpoint = def
+	}
+	return strings.TrimSuffix(endpoint, "/")
+}
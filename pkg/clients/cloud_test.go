@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane-contrib/provider-azure/apis/v1beta1"
+)
+
+func TestUseCloud(t *testing.T) {
+	secret := map[string]string{
+		CredentialsKeyClientID:                   "client",
+		CredentialsKeyActiveDirectoryEndpointURL: "https://login.example.org/",
+		CredentialsKeyResourceManagerEndpointURL: "https://management.example.org/",
+	}
+
+	cases := map[string]struct {
+		reason string
+		cloud  v1beta1.Cloud
+		want   map[string]string
+		err    bool
+	}{
+		"Unset": {
+			reason: "Credentials should keep their own endpoints when no cloud is set.",
+			want:   secret,
+		},
+		"China": {
+			reason: "The cloud's endpoints should override those of the credentials.",
+			cloud:  v1beta1.CloudChina,
+			want: map[string]string{
+				CredentialsKeyClientID:                       "client",
+				CredentialsKeyActiveDirectoryEndpointURL:     "https://login.chinacloudapi.cn/",
+				CredentialsKeyResourceManagerEndpointURL:     "https://management.chinacloudapi.cn/",
+				CredentialsKeyActiveDirectoryGraphResourceID: "https://graph.chinacloudapi.cn/",
+				CredentialsKeyStorageEndpointSuffix:          "core.chinacloudapi.cn",
+			},
+		},
+		"Unknown": {
+			reason: "Unknown clouds should be rejected.",
+			cloud:  v1beta1.Cloud("AzureMoonCloud"),
+			err:    true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := map[string]string{}
+			for k, v := range secret {
+				got[k] = v
+			}
+			err := useCloud(got, tc.cloud)
+			if (err != nil) != tc.err {
+				t.Fatalf("\n%s\nuseCloud(...): want error %t, got %v", tc.reason, tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nuseCloud(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	public := map[string]string{}
+	gov := map[string]string{}
+	if err := useCloud(gov, v1beta1.CloudUSGovernment); err != nil {
+		t.Fatalf("useCloud(...): %v", err)
+	}
+
+	if got, want := ResourceManagerEndpoint(public), "https://management.azure.com"; got != want {
+		t.Errorf("ResourceManagerEndpoint(public): want %s, got %s", want, got)
+	}
+	if got, want := ResourceManagerEndpoint(gov), "https://management.usgovcloudapi.net"; got != want {
+		t.Errorf("ResourceManagerEndpoint(gov): want %s, got %s", want, got)
+	}
+	if got, want := GraphEndpoint(public), "https://graph.windows.net"; got != want {
+		t.Errorf("GraphEndpoint(public): want %s, got %s", want, got)
+	}
+	if got, want := StorageEndpointSuffix(public), "core.windows.net"; got != want {
+		t.Errorf("StorageEndpointSuffix(public): want %s, got %s", want, got)
+	}
+	if got, want := StorageEndpointSuffix(gov), "core.usgovcloudapi.net"; got != want {
+		t.Errorf("StorageEndpointSuffix(gov): want %s, got %s", want, got)
+	}
+}
@@ -70,11 +70,11 @@ type AggregateClient struct {
 
 // NewAggregateClient produces the various clients used by the AKS controller.
 func NewAggregateClient(creds map[string]string, auth autorest.Authorizer) (AKSClient, error) {
-	mcc := containerservice.NewManagedClustersClient(creds[azure.CredentialsKeySubscriptionID])
+	mcc := containerservice.NewManagedClustersClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
 	mcc.Authorizer = auth
 	_ = mcc.AddToUserAgent(azure.UserAgent)
 
-	rac := authorization.NewRoleAssignmentsClient(creds[azure.CredentialsKeySubscriptionID])
+	rac := authorization.NewRoleAssignmentsClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
 	rac.Authorizer = auth
 	_ = rac.AddToUserAgent(azure.UserAgent)
 
@@ -96,11 +96,11 @@ func NewAggregateClient(creds map[string]string, auth autorest.Authorizer) (AKSC
 
 	ta := autorest.NewBearerAuthorizer(token)
 
-	ac := graphrbac.NewApplicationsClient(creds[azure.CredentialsKeyTenantID])
+	ac := graphrbac.NewApplicationsClientWithBaseURI(azure.GraphEndpoint(creds), creds[azure.CredentialsKeyTenantID])
 	ac.Authorizer = ta
 	_ = ac.AddToUserAgent(azure.UserAgent)
 
-	spc := graphrbac.NewServicePrincipalsClient(creds[azure.CredentialsKeyTenantID])
+	spc := graphrbac.NewServicePrincipalsClientWithBaseURI(azure.GraphEndpoint(creds), creds[azure.CredentialsKeyTenantID])
 	spc.Authorizer = ta
 	_ = spc.AddToUserAgent(azure.UserAgent)
 
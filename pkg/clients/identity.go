@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-azure/apis/v1beta1"
+)
+
+// Environment variables that Azure Workload Identity injects into the pods of
+// service accounts that are federated with an Azure AD application.
+const (
+	EnvAzureClientID           = "AZURE_CLIENT_ID"
+	EnvAzureTenantID           = "AZURE_TENANT_ID"
+	EnvAzureFederatedTokenFile = "AZURE_FEDERATED_TOKEN_FILE"
+	EnvAzureAuthorityHost      = "AZURE_AUTHORITY_HOST"
+
+	// EnvAzureSubscriptionID is not injected by Azure Workload Identity, but
+	// may be set on the provider's pod to supply the subscription of a
+	// ProviderConfig that does not set one.
+	EnvAzureSubscriptionID = "AZURE_SUBSCRIPTION_ID"
+)
+
+// CredentialsKeyFederatedTokenFile is the key of credentials that authenticate
+// with a federated token, such as a Kubernetes service account token, rather
+// than a client secret. Its value is the path of the file that holds the
+// token, which is read again each time an Azure AD token is requested, so
+// that the token can be rotated.
+const CredentialsKeyFederatedTokenFile = "federatedTokenFile"
+
+// CredentialsKeyManagedIdentity is the key of credentials that authenticate
+// as the managed identity of the node or pod the provider runs on. Its value
+// is "true". The client ID of a user-assigned identity, if any, is that of
+// the credentials.
+const CredentialsKeyManagedIdentity = "managedIdentity"
+
+const (
+	clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	errNoInjectedIdentity  = "cannot use injected identity: environment variable %s is not set; is Azure Workload Identity enabled for the provider's service account?"
+	errNoSubscriptionID    = "cannot use pod identity: neither the ProviderConfig's credentials nor environment variable " + EnvAzureSubscriptionID + " set a subscription ID"
+	errReadFederatedToken  = "cannot read federated token"
+	errGetOAuthConfig      = "cannot get OAuth configuration"
+	errGetPrincipalToken   = "cannot get service principal token"
+	errGetManagedIdentity  = "cannot get managed identity token"
+	errEmptyFederatedToken = "federated token file %s is empty"
+)
+
+// identityCredentials returns the credentials of the identity of the
+// provider's pod that the supplied credentials use, in the form of the content
+// of a credentials secret. It returns false if the credentials are not those
+// of the provider's pod.
+func identityCredentials(pc v1beta1.ProviderCredentials) (map[string]string, bool, error) {
+	switch pc.Source { //nolint:exhaustive
+	case xpv1.CredentialsSourceInjectedIdentity:
+		m, err := injectedIdentityCredentials(pc)
+		return m, true, err
+	case v1beta1.CredentialsSourceManagedIdentity:
+		m, err := managedIdentityCredentials(pc)
+		return m, true, err
+	}
+	return nil, false, nil
+}
+
+// injectedIdentityCredentials returns the credentials of the Azure Workload
+// Identity injected into the provider's pod, in the form of the content of a
+// credentials secret. The subscription is that of the supplied credentials,
+// or else the one the environment sets.
+func injectedIdentityCredentials(pc v1beta1.ProviderCredentials) (map[string]string, error) {
+	m := map[string]string{}
+	for _, kv := range [][2]string{
+		{CredentialsKeyClientID, EnvAzureClientID},
+		{CredentialsKeyTenantID, EnvAzureTenantID},
+		{CredentialsKeyFederatedTokenFile, EnvAzureFederatedTokenFile},
+	} {
+		v := os.Getenv(kv[1])
+		if v == "" {
+			return nil, errors.Errorf(errNoInjectedIdentity, kv[1])
+		}
+		m[kv[0]] = v
+	}
+	if h := os.Getenv(EnvAzureAuthorityHost); h != "" {
+		m[CredentialsKeyActiveDirectoryEndpointURL] = h
+	}
+	if err := setSubscriptionID(m, pc); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// managedIdentityCredentials returns the credentials of the managed identity
+// of the node or pod the provider runs on, in the form of the content of a
+// credentials secret. The identity is the user-assigned identity of the
+// supplied credentials' client ID, or else the system-assigned identity.
+func managedIdentityCredentials(pc v1beta1.ProviderCredentials) (map[string]string, error) {
+	m := map[string]string{CredentialsKeyManagedIdentity: "true"}
+	if pc.ClientID != "" {
+		m[CredentialsKeyClientID] = pc.ClientID
+	}
+	if err := setSubscriptionID(m, pc); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// setSubscriptionID sets the subscription of the supplied credentials, or else
+// the one the environment sets, in the supplied credentials secret content.
+func setSubscriptionID(m map[string]string, pc v1beta1.ProviderCredentials) error {
+	m[CredentialsKeySubscriptionID] = pc.SubscriptionID
+	if m[CredentialsKeySubscriptionID] == "" {
+		m[CredentialsKeySubscriptionID] = os.Getenv(EnvAzureSubscriptionID)
+	}
+	if m[CredentialsKeySubscriptionID] == "" {
+		return errors.New(errNoSubscriptionID)
+	}
+	return nil
+}
+
+// NewServicePrincipalToken returns a token of the service principal of the
+// supplied credentials, which are the content of a credentials secret, for the
+// supplied resource. Managed identity credentials authenticate using the Azure
+// Instance Metadata Service, credentials with a federated token file with the
+// federated token it holds, and all others with their client secret.
+func NewServicePrincipalToken(creds map[string]string, resource string) (*adal.ServicePrincipalToken, error) {
+	if creds[CredentialsKeyManagedIdentity] == "true" {
+		spt, err := adal.NewServicePrincipalTokenFromManagedIdentity(resource, &adal.ManagedIdentityOptions{ClientID: creds[CredentialsKeyClientID]})
+		return spt, errors.Wrap(err, errGetManagedIdentity)
+	}
+
+	aad := creds[CredentialsKeyActiveDirectoryEndpointURL]
+	if aad == "" {
+		aad = azure.PublicCloud.ActiveDirectoryEndpoint
+	}
+	oc, err := adal.NewOAuthConfig(aad, creds[CredentialsKeyTenantID])
+	if err != nil {
+		return nil, errors.Wrap(err, errGetOAuthConfig)
+	}
+
+	var secret adal.ServicePrincipalSecret = &adal.ServicePrincipalTokenSecret{ClientSecret: creds[CredentialsKeyClientSecret]}
+	if f := creds[CredentialsKeyFederatedTokenFile]; f != "" {
+		secret = &federatedTokenSecret{file: f}
+	}
+	spt, err := adal.NewServicePrincipalTokenWithSecret(*oc, creds[CredentialsKeyClientID], resource, secret)
+	return spt, errors.Wrap(err, errGetPrincipalToken)
+}
+
+// A federatedTokenSecret authenticates a service principal with a federated
+// token, such as a projected Kubernetes service account token, which it reads
+// from a file each time a token is requested.
+type federatedTokenSecret struct {
+	file string
+}
+
+// SetAuthenticationValues sets the federated token as the client assertion of
+// the supplied token request.
+func (s *federatedTokenSecret) SetAuthenticationValues(_ *adal.ServicePrincipalToken, v *url.Values) error {
+	b, err := ioutil.ReadFile(s.file)
+	if err != nil {
+		return errors.Wrap(err, errReadFederatedToken)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return errors.Errorf(errEmptyFederatedToken, s.file)
+	}
+	v.Set("client_assertion_type", clientAssertionType)
+	v.Set("client_assertion", token)
+	return nil
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/v1beta1"
+)
+
+func TestInjectedIdentityCredentials(t *testing.T) {
+	env := map[string]string{
+		EnvAzureClientID:           "client",
+		EnvAzureTenantID:           "tenant",
+		EnvAzureFederatedTokenFile: "/var/run/secrets/azure/tokens/azure-identity-token",
+	}
+
+	cases := map[string]struct {
+		reason string
+		env    map[string]string
+		pc     v1beta1.ProviderCredentials
+		want   map[string]string
+		err    error
+	}{
+		"MissingClientID": {
+			reason: "Credentials require the client ID Azure Workload Identity injects.",
+			env:    map[string]string{EnvAzureTenantID: "tenant", EnvAzureFederatedTokenFile: "/token"},
+			pc:     v1beta1.ProviderCredentials{SubscriptionID: "sub"},
+			err:    errors.Errorf(errNoInjectedIdentity, EnvAzureClientID),
+		},
+		"MissingSubscriptionID": {
+			reason: "Credentials require a subscription.",
+			env:    env,
+			err:    errors.New(errNoSubscriptionID),
+		},
+		"ProviderConfigSubscription": {
+			reason: "The ProviderConfig's subscription takes precedence over the environment's.",
+			env: map[string]string{
+				EnvAzureClientID:           "client",
+				EnvAzureTenantID:           "tenant",
+				EnvAzureFederatedTokenFile: "/token",
+				EnvAzureAuthorityHost:      "https://login.microsoftonline.us/",
+				EnvAzureSubscriptionID:     "env-sub",
+			},
+			pc: v1beta1.ProviderCredentials{SubscriptionID: "sub"},
+			want: map[string]string{
+				CredentialsKeyClientID:                   "client",
+				CredentialsKeyTenantID:                   "tenant",
+				CredentialsKeyFederatedTokenFile:         "/token",
+				CredentialsKeyActiveDirectoryEndpointURL: "https://login.microsoftonline.us/",
+				CredentialsKeySubscriptionID:             "sub",
+			},
+		},
+		"EnvironmentSubscription": {
+			reason: "The environment's subscription is used if the ProviderConfig does not set one.",
+			env: map[string]string{
+				EnvAzureClientID:           "client",
+				EnvAzureTenantID:           "tenant",
+				EnvAzureFederatedTokenFile: "/token",
+				EnvAzureSubscriptionID:     "env-sub",
+			},
+			want: map[string]string{
+				CredentialsKeyClientID:           "client",
+				CredentialsKeyTenantID:           "tenant",
+				CredentialsKeyFederatedTokenFile: "/token",
+				CredentialsKeySubscriptionID:     "env-sub",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, k := range []string{EnvAzureClientID, EnvAzureTenantID, EnvAzureFederatedTokenFile, EnvAzureAuthorityHost, EnvAzureSubscriptionID} {
+				t.Setenv(k, tc.env[k])
+			}
+			got, err := injectedIdentityCredentials(tc.pc)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ninjectedIdentityCredentials(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ninjectedIdentityCredentials(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIdentityCredentials(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		pc     v1beta1.ProviderCredentials
+		want   map[string]string
+		ok     bool
+		err    error
+	}{
+		"Secret": {
+			reason: "Secret credentials are not those of the provider's pod.",
+			pc:     v1beta1.ProviderCredentials{Source: xpv1.CredentialsSourceSecret},
+		},
+		"SystemAssignedManagedIdentity": {
+			reason: "ManagedIdentity credentials without a client ID use the system-assigned identity.",
+			pc:     v1beta1.ProviderCredentials{Source: v1beta1.CredentialsSourceManagedIdentity, SubscriptionID: "sub"},
+			want: map[string]string{
+				CredentialsKeyManagedIdentity: "true",
+				CredentialsKeySubscriptionID:  "sub",
+			},
+			ok: true,
+		},
+		"UserAssignedManagedIdentity": {
+			reason: "ManagedIdentity credentials with a client ID use that user-assigned identity.",
+			pc:     v1beta1.ProviderCredentials{Source: v1beta1.CredentialsSourceManagedIdentity, SubscriptionID: "sub", ClientID: "client"},
+			want: map[string]string{
+				CredentialsKeyManagedIdentity: "true",
+				CredentialsKeyClientID:        "client",
+				CredentialsKeySubscriptionID:  "sub",
+			},
+			ok: true,
+		},
+		"ManagedIdentityMissingSubscriptionID": {
+			reason: "ManagedIdentity credentials require a subscription.",
+			pc:     v1beta1.ProviderCredentials{Source: v1beta1.CredentialsSourceManagedIdentity},
+			ok:     true,
+			err:    errors.New(errNoSubscriptionID),
+		},
+		"InjectedIdentity": {
+			reason: "InjectedIdentity credentials are those of the provider's pod.",
+			pc:     v1beta1.ProviderCredentials{Source: xpv1.CredentialsSourceInjectedIdentity},
+			ok:     true,
+			err:    errors.Errorf(errNoInjectedIdentity, EnvAzureClientID),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(EnvAzureClientID, "")
+			t.Setenv(EnvAzureSubscriptionID, "")
+			got, ok, err := identityCredentials(tc.pc)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nidentityCredentials(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if ok != tc.ok {
+				t.Errorf("\n%s\nidentityCredentials(...): want %t, got %t", tc.reason, tc.ok, ok)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nidentityCredentials(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewServicePrincipalTokenFederated(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("client_assertion_type") != clientAssertionType || r.PostForm.Get("client_secret") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		got = append(got, r.PostForm.Get("client_assertion"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"aad","token_type":"Bearer","expires_in":"3600","expires_on":"0","resource":"r"}`))
+	}))
+	defer srv.Close()
+
+	creds := map[string]string{
+		CredentialsKeyClientID:                   "client",
+		CredentialsKeyTenantID:                   "tenant",
+		CredentialsKeyActiveDirectoryEndpointURL: srv.URL,
+		CredentialsKeyFederatedTokenFile:         file,
+	}
+	spt, err := NewServicePrincipalToken(creds, "r")
+	if err != nil {
+		t.Fatalf("NewServicePrincipalToken(...): %v", err)
+	}
+
+	// The token file is read again on every refresh, so that a rotated
+	// service account token is presented to Azure AD.
+	for _, token := range []string{"first", "second\n"} {
+		if err := ioutil.WriteFile(file, []byte(token), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := spt.Refresh(); err != nil {
+			t.Fatalf("Refresh(): %v", err)
+		}
+	}
+	if diff := cmp.Diff([]string{"first", "second"}, got); diff != "" {
+		t.Errorf("Refresh(): -want assertions, +got assertions:\n%s", diff)
+	}
+	if spt.OAuthToken() != "aad" {
+		t.Errorf("OAuthToken(): want aad, got %q", spt.OAuthToken())
+	}
+}
+
+func TestNewServicePrincipalTokenEmptyFederatedToken(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	spt, err := NewServicePrincipalToken(map[string]string{CredentialsKeyClientID: "client", CredentialsKeyTenantID: "tenant", CredentialsKeyFederatedTokenFile: file}, "r")
+	if err != nil {
+		t.Fatalf("NewServicePrincipalToken(...): %v", err)
+	}
+	if err := spt.Refresh(); err == nil {
+		t.Errorf("Refresh(): want error for empty federated token, got nil")
+	}
+}
+
+func TestNewServicePrincipalTokenManagedIdentity(t *testing.T) {
+	var clientID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("secret") != "msi-secret" || r.URL.Query().Get("resource") != "r" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		clientID = r.URL.Query().Get("clientid")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"msi","token_type":"Bearer","expires_on":"09/14/2030 00:00:00 +00:00","resource":"r"}`))
+	}))
+	defer srv.Close()
+
+	// MSI_ENDPOINT and MSI_SECRET direct adal to the App Service flavour of
+	// the managed identity endpoint, which lets the endpoint be faked.
+	t.Setenv("MSI_ENDPOINT", srv.URL)
+	t.Setenv("MSI_SECRET", "msi-secret")
+
+	spt, err := NewServicePrincipalToken(map[string]string{CredentialsKeyManagedIdentity: "true", CredentialsKeyClientID: "user-assigned"}, "r")
+	if err != nil {
+		t.Fatalf("NewServicePrincipalToken(...): %v", err)
+	}
+	if err := spt.Refresh(); err != nil {
+		t.Fatalf("Refresh(): %v", err)
+	}
+	if clientID != "user-assigned" {
+		t.Errorf("Refresh(): want client ID user-assigned, got %q", clientID)
+	}
+	if spt.OAuthToken() != "msi" {
+		t.Errorf("OAuthToken(): want msi, got %q", spt.OAuthToken())
+	}
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package resourcegroup
 
 import (
+	"context"
 	"encoding/json"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources/resourcesapi"
@@ -33,6 +35,8 @@ import (
 // A GroupsClient handles CRUD operations for Azure Resource Group resources.
 type GroupsClient resourcesapi.GroupsClientAPI
 
+const errLocationMismatch = "resource group %s exists in location %s, not %s"
+
 // NewClient returns a new Azure Resource Groups client. Credentials must be
 // passed as JSON encoded data.
 func NewClient(credentials []byte) (GroupsClient, error) {
@@ -69,3 +73,40 @@ func NewParameters(r *v1alpha3.ResourceGroup) resources.Group {
 		Location: azure.ToStringPtr(r.Spec.Location),
 	}
 }
+
+// A Handle manages the resource groups that other resources are created in.
+type Handle struct {
+	client GroupsClient
+}
+
+// NewHandle returns a Handle that manages resource groups using the supplied
+// client.
+func NewHandle(c GroupsClient) *Handle {
+	return &Handle{client: c}
+}
+
+// EnsureResourceGroup creates the named resource group in the supplied
+// location unless it already exists. An existing group is left as it is,
+// unless it is in a different location, in which case an error is returned.
+func (h *Handle) EnsureResourceGroup(ctx context.Context, name, location string) error {
+	g, err := h.client.Get(ctx, name)
+	if err == nil {
+		if got := azure.ToString(g.Location); !sameLocation(got, location) {
+			return errors.Errorf(errLocationMismatch, name, got, location)
+		}
+		return nil
+	}
+	if !azure.IsNotFound(err) {
+		return errors.Wrapf(err, "cannot get resource group %s", name)
+	}
+	_, err = h.client.CreateOrUpdate(ctx, name, resources.Group{Location: azure.ToStringPtr(location)})
+	return errors.Wrapf(err, "cannot create resource group %s", name)
+}
+
+// sameLocation returns true if the supplied locations name the same Azure
+// region. Azure reports regions by their programmatic name, such as westus,
+// while they may be specified by their display name, such as West US.
+func sameLocation(a, b string) bool {
+	normalize := func(l string) string { return strings.ToLower(strings.ReplaceAll(l, " ", "")) }
+	return normalize(a) == normalize(b)
+}
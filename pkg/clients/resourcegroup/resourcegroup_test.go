@@ -17,15 +17,21 @@ limitations under the License.
 package resourcegroup
 
 import (
+	"context"
+	"net/http"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
 
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane-contrib/provider-azure/apis/v1alpha3"
 	azure "github.com/crossplane-contrib/provider-azure/pkg/clients"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/resourcegroup/fake"
 )
 
 const (
@@ -66,3 +72,78 @@ func TestNewParameters(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureResourceGroup(t *testing.T) {
+	errBoom := errors.New("boom")
+	errNotFound := autorest.DetailedError{StatusCode: http.StatusNotFound}
+
+	type args struct {
+		existing  *resources.Group
+		getErr    error
+		createErr error
+		location  string
+	}
+	type want struct {
+		err     error
+		created *resources.Group
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CreateNew": {
+			reason: "A resource group that does not exist should be created in the supplied location.",
+			args:   args{getErr: errNotFound, location: location},
+			want:   want{created: &resources.Group{Location: azure.ToStringPtr(location)}},
+		},
+		"AlreadyExists": {
+			reason: "A resource group that already exists in the supplied location should be left as it is.",
+			args:   args{existing: &resources.Group{Location: azure.ToStringPtr("westus")}, location: "West US"},
+		},
+		"LocationMismatch": {
+			reason: "A resource group that already exists in another location should be reported rather than changed.",
+			args:   args{existing: &resources.Group{Location: azure.ToStringPtr("eastus")}, location: "westus"},
+			want:   want{err: errors.Errorf(errLocationMismatch, name, "eastus", "westus")},
+		},
+		"GetFailed": {
+			reason: "Errors getting the resource group should be returned.",
+			args:   args{getErr: errBoom, location: location},
+			want:   want{err: errors.Wrapf(errBoom, "cannot get resource group %s", name)},
+		},
+		"CreateFailed": {
+			reason: "Errors creating the resource group should be returned.",
+			args:   args{getErr: errNotFound, createErr: errBoom, location: location},
+			want: want{
+				err:     errors.Wrapf(errBoom, "cannot create resource group %s", name),
+				created: &resources.Group{Location: azure.ToStringPtr(location)},
+			},
+		},
+	}
+
+	for n, tc := range cases {
+		t.Run(n, func(t *testing.T) {
+			var created *resources.Group
+			c := &fake.MockClient{
+				MockGet: func(_ context.Context, _ string) (resources.Group, error) {
+					if tc.args.existing != nil {
+						return *tc.args.existing, nil
+					}
+					return resources.Group{}, tc.args.getErr
+				},
+				MockCreateOrUpdate: func(_ context.Context, _ string, g resources.Group) (resources.Group, error) {
+					created = &g
+					return g, tc.args.createErr
+				},
+			}
+
+			err := NewHandle(c).EnsureResourceGroup(context.Background(), name, tc.args.location)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nEnsureResourceGroup(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.created, created); diff != "" {
+				t.Errorf("\n%s\nEnsureResourceGroup(...): -want created, +got created:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+// MaxStoredAccessPolicies is the most stored access policies a container may
+// have.
+const MaxStoredAccessPolicies = 5
+
+// maxStoredAccessPolicyID is the longest a stored access policy's ID may be.
+const maxStoredAccessPolicyID = 64
+
+// ValidateStoredAccessPolicies returns an error if the blob service would
+// reject the supplied stored access policies: if there are too many of them,
+// or any has an empty, overlong or duplicate ID, or invalid permissions.
+func ValidateStoredAccessPolicies(policies []azblob.SignedIdentifier) error {
+	if len(policies) > MaxStoredAccessPolicies {
+		return errors.Errorf("a container may have at most %d stored access policies, not %d", MaxStoredAccessPolicies, len(policies))
+	}
+	seen := make(map[string]bool, len(policies))
+	for _, p := range policies {
+		if p.ID == "" || len(p.ID) > maxStoredAccessPolicyID {
+			return errors.Errorf("stored access policy ID %q must be between 1 and %d characters long", p.ID, maxStoredAccessPolicyID)
+		}
+		if seen[p.ID] {
+			return errors.Errorf("duplicate stored access policy ID %q", p.ID)
+		}
+		seen[p.ID] = true
+		if err := (&azblob.AccessPolicyPermission{}).Parse(p.AccessPolicy.Permission); err != nil {
+			return errors.Wrapf(err, "stored access policy %q", p.ID)
+		}
+	}
+	return nil
+}
+
+// GetStoredAccessPolicies returns the container's stored access policies.
+func (a *ContainerHandle) GetStoredAccessPolicies(ctx context.Context) ([]azblob.SignedIdentifier, error) {
+	p, err := a.GetAccessPolicy(ctx, azblob.LeaseAccessConditions{})
+	if err != nil {
+		return nil, err
+	}
+	return p.Items, nil
+}
+
+// SetStoredAccessPolicies replaces the container's stored access policies with
+// the supplied ones. The blob service sets a container's public access type
+// and stored access policies together, so the supplied public access type is
+// written too.
+func (a *ContainerHandle) SetStoredAccessPolicies(ctx context.Context, publicAccessType azblob.PublicAccessType, policies []azblob.SignedIdentifier) error {
+	if err := ValidateStoredAccessPolicies(policies); err != nil {
+		return err
+	}
+	_, err := a.SetAccessPolicy(ctx, publicAccessType, policies, azblob.ContainerAccessConditions{})
+	return err
+}
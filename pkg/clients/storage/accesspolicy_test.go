@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStoredAccessPolicies(t *testing.T) {
+	identifiers := []azblob.SignedIdentifier{{
+		ID:           "read-only",
+		AccessPolicy: azblob.AccessPolicy{Start: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Expiry: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), Permission: "rl"},
+	}}
+
+	var stored []azblob.SignedIdentifier
+	var access string
+	h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("comp") != "acl" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			b, _ := xml.Marshal(struct {
+				XMLName xml.Name                  `xml:"SignedIdentifiers"`
+				Items   []azblob.SignedIdentifier `xml:"SignedIdentifier"`
+			}{Items: stored})
+			_, _ = w.Write(b)
+		case http.MethodPut:
+			access = r.Header.Get(headerBlobPublicAccess)
+			b, _ := ioutil.ReadAll(r.Body)
+			body := struct {
+				Items []azblob.SignedIdentifier `xml:"SignedIdentifier"`
+			}{}
+			_ = xml.Unmarshal(b, &body)
+			stored = body.Items
+		}
+	}))
+
+	ctx := context.Background()
+	if err := h.SetStoredAccessPolicies(ctx, azblob.PublicAccessBlob, identifiers); err != nil {
+		t.Fatalf("SetStoredAccessPolicies(...): %v", err)
+	}
+	if access != string(azblob.PublicAccessBlob) {
+		t.Errorf("SetStoredAccessPolicies(...): want public access %q written, got %q", azblob.PublicAccessBlob, access)
+	}
+	got, err := h.GetStoredAccessPolicies(ctx)
+	if err != nil {
+		t.Fatalf("GetStoredAccessPolicies(...): %v", err)
+	}
+	if diff := cmp.Diff(identifiers, got); diff != "" {
+		t.Errorf("GetStoredAccessPolicies(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestValidateStoredAccessPolicies(t *testing.T) {
+	policy := func(id, permission string) azblob.SignedIdentifier {
+		return azblob.SignedIdentifier{ID: id, AccessPolicy: azblob.AccessPolicy{Permission: permission}}
+	}
+	tooMany := []azblob.SignedIdentifier{}
+	for i := 0; i <= MaxStoredAccessPolicies; i++ {
+		tooMany = append(tooMany, policy(fmt.Sprintf("p%d", i), "r"))
+	}
+
+	cases := map[string]struct {
+		reason   string
+		policies []azblob.SignedIdentifier
+		err      bool
+	}{
+		"Valid": {
+			reason:   "Policies with unique IDs and known permissions are valid.",
+			policies: []azblob.SignedIdentifier{policy("read", "rl"), policy("write", "racwdl")},
+		},
+		"TooMany": {
+			reason:   "A container may have only so many policies.",
+			policies: tooMany,
+			err:      true,
+		},
+		"EmptyID": {
+			reason:   "Every policy needs an ID.",
+			policies: []azblob.SignedIdentifier{policy("", "r")},
+			err:      true,
+		},
+		"LongID": {
+			reason:   "Policy IDs may be only so long.",
+			policies: []azblob.SignedIdentifier{policy(strings.Repeat("p", maxStoredAccessPolicyID+1), "r")},
+			err:      true,
+		},
+		"DuplicateID": {
+			reason:   "Policy IDs must be unique.",
+			policies: []azblob.SignedIdentifier{policy("read", "r"), policy("read", "l")},
+			err:      true,
+		},
+		"UnknownPermission": {
+			reason:   "Permissions the blob service does not know are invalid.",
+			policies: []azblob.SignedIdentifier{policy("read", "rz")},
+			err:      true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateStoredAccessPolicies(tc.policies)
+			if (err != nil) != tc.err {
+				t.Errorf("\n%s\nValidateStoredAccessPolicies(...): want error %t, got %v", tc.reason, tc.err, err)
+			}
+		})
+	}
+}
+
+func TestCreateMetadata(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		scope  string
+	}{
+		"NoEncryptionScope": {
+			reason: "Metadata should be applied when the container is created.",
+		},
+		"EncryptionScope": {
+			reason: "Metadata should be applied when a container with a default encryption scope is created.",
+			scope:  "scope",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got azblob.Metadata
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = azblob.Metadata{}
+				for k, v := range r.Header {
+					if strings.HasPrefix(strings.ToLower(k), headerMetaPrefix) {
+						got[strings.ToLower(k[len(headerMetaPrefix):])] = v[0]
+					}
+				}
+				w.WriteHeader(http.StatusCreated)
+			}))
+			h.DefaultEncryptionScope = tc.scope
+
+			if err := h.Create(context.Background(), azblob.PublicAccessNone, azblob.Metadata{"owner": "crossplane"}); err != nil {
+				t.Fatalf("\n%s\nCreate(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(azblob.Metadata{"owner": "crossplane"}, got); diff != "" {
+				t.Errorf("\n%s\nCreate(...): -want metadata, +got metadata:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
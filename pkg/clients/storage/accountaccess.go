@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultAccountPublicAccessTTL is how long whether storage accounts allow
+// blob public access is cached by default.
+const DefaultAccountPublicAccessTTL = time.Minute
+
+// An AccountPublicAccessCache caches whether each storage account allows blob
+// public access for a time to live, so that every reconcile of the account's
+// containers does not ask the management plane, while a changed account
+// setting is still noticed soon after it changes.
+type AccountPublicAccessCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	allowed map[string]cachedPublicAccess
+}
+
+type cachedPublicAccess struct {
+	allowed bool
+	expires time.Time
+}
+
+// NewAccountPublicAccessCache returns an AccountPublicAccessCache that caches
+// for the supplied time to live. Nothing is cached if it is not positive.
+func NewAccountPublicAccessCache(ttl time.Duration) *AccountPublicAccessCache {
+	return &AccountPublicAccessCache{ttl: ttl, now: time.Now}
+}
+
+// Allowed returns whether the named storage account allows blob public
+// access. A cached answer is returned until it expires, after which the
+// supplied management operations are asked again. Failures are not cached.
+func (c *AccountPublicAccessCache) Allowed(ctx context.Context, account string, m ManagementOperations) (bool, error) {
+	if c.ttl <= 0 {
+		return m.GetAllowBlobPublicAccess(ctx)
+	}
+
+	c.mu.Lock()
+	if a, ok := c.allowed[account]; ok && c.now().Before(a.expires) {
+		c.mu.Unlock()
+		return a.allowed, nil
+	}
+	c.mu.Unlock()
+
+	allowed, err := m.GetAllowBlobPublicAccess(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.allowed == nil {
+		c.allowed = map[string]cachedPublicAccess{}
+	}
+	c.allowed[account] = cachedPublicAccess{allowed: allowed, expires: c.now().Add(c.ttl)}
+	return allowed, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// publicAccessManagement reports whether an account allows blob public
+// access, and counts how many times it was asked.
+type publicAccessManagement struct {
+	ManagementOperations
+	allowed bool
+	err     error
+	calls   int
+}
+
+func (m *publicAccessManagement) GetAllowBlobPublicAccess(context.Context) (bool, error) {
+	m.calls++
+	return m.allowed, m.err
+}
+
+func TestAccountPublicAccessCache(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := time.Now()
+
+	type call struct {
+		after   time.Duration
+		allowed bool
+		err     error
+	}
+	type want struct {
+		allowed []bool
+		calls   int
+	}
+	cases := map[string]struct {
+		reason string
+		ttl    time.Duration
+		calls  []call
+		want   want
+	}{
+		"CacheHit": {
+			reason: "A cached answer should be returned without asking the management plane again, even if the account setting changed.",
+			ttl:    time.Minute,
+			calls:  []call{{allowed: false}, {after: 30 * time.Second, allowed: true}},
+			want:   want{allowed: []bool{false, false}, calls: 1},
+		},
+		"Refreshed": {
+			reason: "An expired answer should be refreshed, picking up a changed account setting.",
+			ttl:    time.Minute,
+			calls:  []call{{allowed: false}, {after: 2 * time.Minute, allowed: true}},
+			want:   want{allowed: []bool{false, true}, calls: 2},
+		},
+		"FailureNotCached": {
+			reason: "Failures to ask the management plane should not be cached.",
+			ttl:    time.Minute,
+			calls:  []call{{err: errBoom}, {allowed: true}},
+			want:   want{allowed: []bool{false, true}, calls: 2},
+		},
+		"CachingDisabled": {
+			reason: "The management plane should be asked every time when the time to live is not positive.",
+			calls:  []call{{allowed: false}, {allowed: true}},
+			want:   want{allowed: []bool{false, true}, calls: 2},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewAccountPublicAccessCache(tc.ttl)
+			m := &publicAccessManagement{}
+			got := make([]bool, 0, len(tc.calls))
+			for _, call := range tc.calls {
+				c.now = func() time.Time { return now.Add(call.after) }
+				m.allowed, m.err = call.allowed, call.err
+				allowed, err := c.Allowed(context.Background(), testAccount, m)
+				if !errors.Is(err, call.err) {
+					t.Fatalf("\n%s\nAllowed(...): want error %v, got %v", tc.reason, call.err, err)
+				}
+				got = append(got, allowed)
+			}
+			for i := range got {
+				if got[i] != tc.want.allowed[i] {
+					t.Errorf("\n%s\nAllowed(...): call %d: want %t, got %t", tc.reason, i, tc.want.allowed[i], got[i])
+				}
+			}
+			if m.calls != tc.want.calls {
+				t.Errorf("\n%s\nAllowed(...): want %d management plane calls, got %d", tc.reason, tc.want.calls, m.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"math"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// copyBackoff paces polling for pending blob copies. Its steps are bounded by
+// the context rather than counted.
+var copyBackoff = wait.Backoff{Duration: 100 * time.Millisecond, Factor: 2, Cap: 5 * time.Second, Steps: math.MaxInt32}
+
+// ArchiveBlobs copies every current blob in the container to the named archive
+// container in the same storage account, which must already exist. Blobs are
+// archived under a prefix of the container's name, so that one archive
+// container can hold the blobs of many containers, and archiving a container
+// again overwrites its earlier copies. It returns once every copy has
+// completed. Errors copying individual blobs are aggregated and returned
+// together.
+func (a *ContainerHandle) ArchiveBlobs(ctx context.Context, archive string) error {
+	src := a.URL()
+	name := path.Base(src.Path)
+	dst := src
+	dst.Path = path.Join(path.Dir(src.Path), archive)
+	ac := azblob.NewContainerURL(dst, a.pipeline)
+
+	if _, err := ac.GetProperties(ctx, azblob.LeaseAccessConditions{}); err != nil {
+		if IsNotFoundError(err) {
+			return errors.Errorf("archive container %s does not exist", archive)
+		}
+		return errors.Wrapf(err, "cannot get archive container %s", archive)
+	}
+
+	var failed []string
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		page, err := a.ListBlobsFlatSegment(ctx, marker, azblob.ListBlobsSegmentOptions{})
+		if err != nil {
+			return errors.Wrap(err, "cannot list blobs")
+		}
+		for _, b := range page.Segment.BlobItems {
+			err := copyBlob(ctx, a.NewBlobURL(b.Name), ac.NewBlobURL(name+"/"+b.Name))
+			if errors.Is(err, ErrOperationBudgetExceeded) {
+				return err
+			}
+			if err != nil {
+				failed = append(failed, b.Name+": "+err.Error())
+			}
+		}
+		marker = page.NextMarker
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("cannot archive %d blob(s) to container %s: %s", len(failed), archive, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// copyBlob copies the source blob to the destination blob, and waits for the
+// copy to complete if Azure completes it asynchronously.
+func copyBlob(ctx context.Context, src, dst azblob.BlobURL) error {
+	resp, err := dst.StartCopyFromURL(ctx, src.URL(), nil, azblob.ModifiedAccessConditions{}, azblob.BlobAccessConditions{})
+	if err != nil {
+		return err
+	}
+	status, desc := resp.CopyStatus(), ""
+	if status == azblob.CopyStatusPending {
+		err = wait.ExponentialBackoffWithContext(ctx, copyBackoff, func() (bool, error) {
+			p, err := dst.GetProperties(ctx, azblob.BlobAccessConditions{})
+			if err != nil {
+				return false, err
+			}
+			status, desc = p.CopyStatus(), p.CopyStatusDescription()
+			return status != azblob.CopyStatusPending, nil
+		})
+		if err != nil {
+			return errors.Wrap(err, "cannot wait for copy to complete")
+		}
+	}
+	if status != azblob.CopyStatusSuccess {
+		return errors.Errorf("copy %s: %s", status, desc)
+	}
+	return nil
+}
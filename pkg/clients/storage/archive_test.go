@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestArchiveBlobs(t *testing.T) {
+	type want struct {
+		err    bool
+		copied []string
+	}
+	cases := map[string]struct {
+		reason     string
+		archive    bool
+		copyStatus map[string]string
+		want       want
+	}{
+		"Archived": {
+			reason:     "Every blob should be copied under the container's name in the archive container.",
+			archive:    true,
+			copyStatus: map[string]string{"a": "success", "b/c": "success"},
+			want: want{
+				copied: []string{"/archive/testcontainer/a", "/archive/testcontainer/b/c"},
+			},
+		},
+		"CopyPending": {
+			reason:     "Copies that complete asynchronously should be waited for.",
+			archive:    true,
+			copyStatus: map[string]string{"a": "pending", "b/c": "success"},
+			want: want{
+				copied: []string{"/archive/testcontainer/a", "/archive/testcontainer/b/c"},
+			},
+		},
+		"CopyFailed": {
+			reason:     "Blobs that could not be copied should be reported after the others are copied.",
+			archive:    true,
+			copyStatus: map[string]string{"a": "failed", "b/c": "success"},
+			want: want{
+				err:    true,
+				copied: []string{"/archive/testcontainer/a", "/archive/testcontainer/b/c"},
+			},
+		},
+		"NoArchiveContainer": {
+			reason: "Nothing should be copied when the archive container does not exist.",
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var copied []string
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/archive":
+					if !tc.archive {
+						w.WriteHeader(http.StatusNotFound)
+					}
+				case r.URL.Path == "/"+testContainer:
+					_, _ = w.Write([]byte(blobListing("", Blob{Name: "a"}, Blob{Name: "b/c"})))
+				case r.Method == http.MethodPut:
+					copied = append(copied, r.URL.Path)
+					w.Header().Set("x-ms-copy-status", tc.copyStatus[r.URL.Path[len("/archive/testcontainer/"):]])
+					w.WriteHeader(http.StatusAccepted)
+				case r.Method == http.MethodHead:
+					w.Header().Set("x-ms-copy-status", "success")
+				}
+			}))
+
+			err := h.ArchiveBlobs(context.Background(), "archive")
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nArchiveBlobs(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.copied, copied); diff != "" {
+				t.Errorf("\n%s\nArchiveBlobs(...): -want copied, +got copied:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+const (
+	headerVersion                     = "x-ms-version"
+	headerBlobPublicAccess            = "x-ms-blob-public-access"
+	headerDefaultEncryptionScope      = "x-ms-default-encryption-scope"
+	headerDenyEncryptionScopeOverride = "x-ms-deny-encryption-scope-override"
+	headerDeleteSnapshots             = "x-ms-delete-snapshots"
+
+	// extendedServiceVersion is the first blob service version that knows
+	// about blob versions and encryption scopes. The azblob SDK we use pins an
+	// older version, so requests that need either are built by hand.
+	extendedServiceVersion = "2019-12-12"
+)
+
+// DeleteWithVersions deletes every blob in the container, along with its
+// previous versions, before deleting the container itself. It is meant for
+// storage accounts with blob versioning enabled, which keep the current
+// version of a deleted blob as a previous one, so callers should check that
+// it is first. Snapshots are listed, and deleted along with their blobs, when
+// the handle's DeleteSnapshots is true; otherwise blobs that have snapshots
+// cannot be deleted. Errors deleting individual blobs and versions are
+// aggregated and returned together, in which case the container is not
+// deleted.
+func (a *ContainerHandle) DeleteWithVersions(ctx context.Context) error {
+	blobs, err := a.ListBlobsWithOptions(ctx, ListOptions{Versions: true, Snapshots: a.DeleteSnapshots})
+	if err != nil {
+		return errors.Wrap(err, "cannot list blob versions")
+	}
+	snapshotted := map[string]bool{}
+	for _, b := range blobs {
+		if b.Snapshot != "" {
+			snapshotted[b.Name] = true
+		}
+	}
+
+	// The current version of a blob cannot be deleted by its version ID, so
+	// current blobs are deleted first, along with any snapshots. That makes
+	// their current versions previous ones, which are deleted with the rest.
+	var failed []string
+	kept := map[string]bool{}
+	for _, b := range blobs {
+		if b.Previous || b.Snapshot != "" {
+			continue
+		}
+		err := a.deleteBlob(ctx, b.Name, "", snapshotted[b.Name])
+		if errors.Is(err, ErrOperationBudgetExceeded) {
+			return err
+		}
+		if err != nil && !IsNotFoundError(err) {
+			failed = append(failed, b.Name+": "+err.Error())
+			kept[b.Name] = true
+		}
+	}
+	for _, b := range blobs {
+		if b.VersionID == "" || b.Snapshot != "" || (!b.Previous && kept[b.Name]) {
+			continue
+		}
+		err := a.deleteBlob(ctx, b.Name, b.VersionID, false)
+		if errors.Is(err, ErrOperationBudgetExceeded) {
+			return err
+		}
+		if err != nil && !IsNotFoundError(err) {
+			failed = append(failed, b.Name+" version "+b.VersionID+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("cannot delete %d blob(s) or blob version(s): %s", len(failed), strings.Join(failed, "; "))
+	}
+
+	return a.Delete(ctx)
+}
+
+// deleteBlob deletes the named blob, or the supplied version of it. The
+// blob's snapshots are deleted with it if the supplied snapshots is true; the
+// blob service refuses to delete a blob that has snapshots otherwise.
+func (a *ContainerHandle) deleteBlob(ctx context.Context, name, versionID string, snapshots bool) error {
+	u := a.NewBlobURL(name).URL()
+	if versionID != "" {
+		q := u.Query()
+		q.Set("versionid", versionID)
+		u.RawQuery = q.Encode()
+	}
+	h := http.Header{}
+	if snapshots {
+		h.Set(headerDeleteSnapshots, string(azblob.DeleteSnapshotsOptionInclude))
+	}
+	_, _, err := a.send(ctx, http.MethodDelete, u, h, http.StatusAccepted)
+	return err
+}
+
+// send issues a request with the supplied headers that the azblob SDK cannot
+// express through the handle's pipeline and returns the response headers and
+// body when the status code is one of the supplied success codes.
+func (a *ContainerHandle) send(ctx context.Context, method string, u url.URL, h http.Header, success ...int) (http.Header, []byte, error) {
+	return send(ctx, a.pipeline, method, u, h, nil, success...)
+}
+
+// send issues a request with the supplied headers and body through the
+// supplied pipeline.
+func send(ctx context.Context, p pipeline.Pipeline, method string, u url.URL, h http.Header, body []byte, success ...int) (http.Header, []byte, error) {
+	var b io.ReadSeeker
+	if body != nil {
+		b = bytes.NewReader(body)
+	}
+	req, err := pipeline.NewRequest(method, u, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range h {
+		req.Header[k] = v
+	}
+	req.Header.Set(headerVersion, extendedServiceVersion)
+
+	resp, err := p.Do(withMinVersion(ctx, extendedServiceVersion), nil, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := resp.Response()
+	defer r.Body.Close() // nolint:errcheck
+	rb, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range success {
+		if r.StatusCode == c {
+			return r.Header, rb, nil
+		}
+	}
+	return nil, nil, azblob.NewResponseError(nil, r, r.Status)
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	testAccount   = "testaccount"
+	testKey       = "dGVzdC1rZXkK"
+	testContainer = "testcontainer"
+)
+
+// newTestContainerHandle returns a ContainerHandle whose requests are served
+// by the supplied handler rather than Azure.
+func newTestContainerHandle(t *testing.T, h http.Handler) *ContainerHandle {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	c, err := azblob.NewSharedKeyCredential(testAccount, testKey)
+	if err != nil {
+		t.Fatalf("azblob.NewSharedKeyCredential(...): %v", err)
+	}
+	p := azblob.NewPipeline(c, azblob.PipelineOptions{Retry: azblob.RetryOptions{MaxTries: 1}})
+	u, _ := url.Parse(srv.URL + "/" + testContainer)
+	return &ContainerHandle{
+		ContainerURL: azblob.NewContainerURL(*u, p),
+		pipeline:     p,
+	}
+}
+
+// newTestContainerHandleWithCredentials returns a ContainerHandle whose
+// requests are served by the supplied handler rather than Azure, and that
+// reads and writes as the supplied storage accounts, so that the handler can
+// tell which credential signed each request.
+func newTestContainerHandleWithCredentials(t *testing.T, h http.Handler, reader, writer string) *ContainerHandle {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	u, _ := url.Parse(srv.URL + "/" + testContainer)
+	handle := func(account string) *ContainerHandle {
+		c, err := azblob.NewSharedKeyCredential(account, testKey)
+		if err != nil {
+			t.Fatalf("azblob.NewSharedKeyCredential(...): %v", err)
+		}
+		p := azblob.NewPipeline(c, azblob.PipelineOptions{Retry: azblob.RetryOptions{MaxTries: 1}})
+		return &ContainerHandle{ContainerURL: azblob.NewContainerURL(*u, p), pipeline: p}
+	}
+	w := handle(writer)
+	w.reader = handle(reader)
+	return w
+}
+
+// recorder records the requests a fake blob endpoint receives.
+type recorder struct {
+	mu   sync.Mutex
+	reqs []string
+}
+
+func (r *recorder) record(req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reqs = append(r.reqs, req.Method+" "+req.URL.Path+queryString(req.URL))
+}
+
+func (r *recorder) requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.reqs...)
+}
+
+// queryString renders the query parameters that identify blob versions and
+// snapshots, ignoring the ones every request carries.
+func queryString(u *url.URL) string {
+	keys := []string{}
+	for k := range u.Query() {
+		switch k {
+		case "versionid", "snapshot", "include", "marker":
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + u.Query().Get(k)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "?" + strings.Join(parts, "&")
+}
+
+func blobListing(next string, blobs ...Blob) string {
+	b := &strings.Builder{}
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>`)
+	for _, v := range blobs {
+		fmt.Fprintf(b, "<Blob><Name>%s</Name>", v.Name)
+		if v.VersionID != "" {
+			fmt.Fprintf(b, "<VersionId>%s</VersionId><IsCurrentVersion>%t</IsCurrentVersion>", v.VersionID, !v.Previous)
+		}
+		if v.Snapshot != "" {
+			fmt.Fprintf(b, "<Snapshot>%s</Snapshot>", v.Snapshot)
+		}
+		b.WriteString("</Blob>")
+	}
+	fmt.Fprintf(b, "</Blobs><NextMarker>%s</NextMarker></EnumerationResults>", next)
+	return b.String()
+}
+
+func TestDeleteWithVersions(t *testing.T) {
+	type want struct {
+		err  bool
+		reqs []string
+	}
+	cases := map[string]struct {
+		reason    string
+		snapshots bool
+		pages     map[string]string
+		failBlob  string
+		want      want
+	}{
+		"Versions": {
+			reason: "Current blobs should be deleted before every version, including the one that was current, and then the container.",
+			pages: map[string]string{
+				"": blobListing("", Blob{Name: "a", VersionID: "v1", Previous: true}, Blob{Name: "a", VersionID: "v2"}),
+			},
+			want: want{
+				reqs: []string{
+					"GET /testcontainer?include=versions",
+					"DELETE /testcontainer/a",
+					"DELETE /testcontainer/a?versionid=v1",
+					"DELETE /testcontainer/a?versionid=v2",
+					"DELETE /testcontainer",
+				},
+			},
+		},
+		"IncludeSnapshots": {
+			reason:    "Snapshots should be listed when requested, and deleted along with their blobs.",
+			snapshots: true,
+			pages: map[string]string{
+				"":   blobListing("m1", Blob{Name: "a", VersionID: "v1"}, Blob{Name: "b", VersionID: "v2"}),
+				"m1": blobListing("", Blob{Name: "b", Snapshot: "s1"}),
+			},
+			want: want{
+				reqs: []string{
+					"GET /testcontainer?include=snapshots,versions",
+					"GET /testcontainer?include=snapshots,versions&marker=m1",
+					"DELETE /testcontainer/a",
+					"DELETE /testcontainer/b delete-snapshots=include",
+					"DELETE /testcontainer/a?versionid=v1",
+					"DELETE /testcontainer/b?versionid=v2",
+					"DELETE /testcontainer",
+				},
+			},
+		},
+		"DeleteFailed": {
+			reason: "Deletion errors should be aggregated, the current version of a blob that was not deleted should be kept, and the container should not be deleted.",
+			pages: map[string]string{
+				"": blobListing("", Blob{Name: "a", VersionID: "v1"}, Blob{Name: "a", VersionID: "v0", Previous: true}, Blob{Name: "b", VersionID: "v1"}),
+			},
+			failBlob: "/testcontainer/a",
+			want: want{
+				err: true,
+				reqs: []string{
+					"GET /testcontainer?include=versions",
+					"DELETE /testcontainer/a",
+					"DELETE /testcontainer/b",
+					"DELETE /testcontainer/a?versionid=v0",
+					"DELETE /testcontainer/b?versionid=v1",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var reqs []string
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req := r.Method + " " + r.URL.Path + queryString(r.URL)
+				if d := r.Header.Get("x-ms-delete-snapshots"); d != "" {
+					req += " delete-snapshots=" + d
+				}
+				mu.Lock()
+				reqs = append(reqs, req)
+				mu.Unlock()
+				switch {
+				case r.Method == http.MethodGet:
+					fmt.Fprint(w, tc.pages[r.URL.Query().Get("marker")])
+				case r.URL.Path == tc.failBlob:
+					w.WriteHeader(http.StatusConflict)
+				default:
+					w.WriteHeader(http.StatusAccepted)
+				}
+			}))
+			h.DeleteSnapshots = tc.snapshots
+
+			err := h.DeleteWithVersions(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nDeleteWithVersions(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.reqs, reqs); diff != "" {
+				t.Errorf("\n%s\nDeleteWithVersions(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetContainerProperties(t *testing.T) {
+	type want struct {
+		props *ContainerProperties
+		err   bool
+	}
+	cases := map[string]struct {
+		reason  string
+		headers map[string]string
+		status  int
+		want    want
+	}{
+		"EncryptionScope": {
+			reason: "The container's default encryption scope and metadata should be parsed from its properties.",
+			headers: map[string]string{
+				"x-ms-blob-public-access":             "container",
+				"x-ms-meta-Owner":                     "crossplane",
+				"x-ms-default-encryption-scope":       "cmk",
+				"x-ms-deny-encryption-scope-override": "true",
+				"ETag":                                `"0x1"`,
+			},
+			status: http.StatusOK,
+			want: want{
+				props: &ContainerProperties{
+					PublicAccessType:               azblob.PublicAccessContainer,
+					Metadata:                       azblob.Metadata{"owner": "crossplane"},
+					ETag:                           azblob.ETag(`"0x1"`),
+					DefaultEncryptionScope:         "cmk",
+					PreventEncryptionScopeOverride: true,
+				},
+			},
+		},
+		"NoEncryptionScope": {
+			reason: "A container without encryption scope headers should report none.",
+			status: http.StatusOK,
+			want: want{
+				props: &ContainerProperties{},
+			},
+		},
+		"NotFound": {
+			reason: "Errors getting the container's properties should be returned.",
+			status: http.StatusNotFound,
+			want: want{
+				err: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tc.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tc.status)
+			}))
+
+			got, err := h.GetContainerProperties(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nGetContainerProperties(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.props, got); diff != "" {
+				t.Errorf("\n%s\nGetContainerProperties(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateWithEncryptionScope(t *testing.T) {
+	var got http.Header
+	h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		w.WriteHeader(http.StatusCreated)
+	}))
+	h.DefaultEncryptionScope = "cmk"
+	h.PreventEncryptionScopeOverride = true
+
+	if err := h.Create(context.Background(), azblob.PublicAccessBlob, nil); err != nil {
+		t.Fatalf("Create(...): %v", err)
+	}
+	want := map[string]string{
+		headerDefaultEncryptionScope:      "cmk",
+		headerDenyEncryptionScopeOverride: "true",
+		headerBlobPublicAccess:            "blob",
+		headerVersion:                     extendedServiceVersion,
+	}
+	for k, v := range want {
+		if got.Get(k) != v {
+			t.Errorf("Create(...): want header %s: %q, got %q", k, v, got.Get(k))
+		}
+	}
+}
@@ -0,0 +1,374 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+
+	azure "github.com/crossplane-contrib/provider-azure/pkg/clients"
+)
+
+const (
+	// analyticsVersion is the only version of Storage Analytics.
+	analyticsVersion = "1.0"
+
+	minRetentionDays = 1
+	maxRetentionDays = 365
+
+	// metadataConcurrency bounds how many containers' metadata is read at
+	// once.
+	metadataConcurrency = 8
+)
+
+// BlobServiceOperations are operations on the blob service of a storage
+// account.
+type BlobServiceOperations interface {
+	GetMetricsConfig(ctx context.Context) (hour, minute MetricsProperties, err error)
+	SetMetricsConfig(ctx context.Context, hour, minute MetricsProperties) error
+	GetLoggingConfig(ctx context.Context) (LoggingProperties, error)
+	SetLoggingConfig(ctx context.Context, p LoggingProperties) error
+	GetBlobSoftDelete(ctx context.Context) (enabled bool, days int32, err error)
+	SetBlobSoftDelete(ctx context.Context, enabled bool, days int32) error
+	GetMetadataForContainers(ctx context.Context, names []string) (map[string]azblob.Metadata, map[string]error)
+}
+
+// MetricsProperties configure Storage Analytics metrics.
+type MetricsProperties struct {
+	// Enabled metrics are collected.
+	Enabled bool
+
+	// IncludeAPIs causes metrics to be summarised per API operation. It has
+	// no effect unless metrics are enabled.
+	IncludeAPIs bool
+
+	// RetentionDays is the number of days for which metrics are kept. Metrics
+	// are kept until they are deleted when it is nil.
+	RetentionDays *int32
+}
+
+// LoggingProperties configure Storage Analytics logging. Logging is disabled
+// when no kind of request is logged.
+type LoggingProperties struct {
+	// Read requests are logged.
+	Read bool
+
+	// Write requests are logged.
+	Write bool
+
+	// Delete requests are logged.
+	Delete bool
+
+	// RetentionDays is the number of days for which logs are kept. Logs are
+	// kept until they are deleted when it is nil.
+	RetentionDays *int32
+}
+
+// ValidateMetricsProperties returns an error if the supplied metrics
+// properties would be rejected by Azure.
+func ValidateMetricsProperties(p MetricsProperties) error {
+	return validateRetentionDays(p.RetentionDays)
+}
+
+// ValidateLoggingProperties returns an error if the supplied logging
+// properties would be rejected by Azure.
+func ValidateLoggingProperties(p LoggingProperties) error {
+	return validateRetentionDays(p.RetentionDays)
+}
+
+// ValidateBlobSoftDelete returns an error if the supplied blob soft delete
+// settings would be rejected by Azure. Days are ignored when soft delete is
+// disabled.
+func ValidateBlobSoftDelete(enabled bool, days int32) error {
+	if !enabled {
+		return nil
+	}
+	return validateRetentionDays(&days)
+}
+
+func validateRetentionDays(days *int32) error {
+	if days != nil && (*days < minRetentionDays || *days > maxRetentionDays) {
+		return errors.Errorf("retention days must be between %d and %d, not %d", minRetentionDays, maxRetentionDays, *days)
+	}
+	return nil
+}
+
+// BlobServiceHandle implements BlobServiceOperations.
+type BlobServiceHandle struct {
+	azblob.ServiceURL
+
+	pipeline pipeline.Pipeline
+}
+
+var _ BlobServiceOperations = &BlobServiceHandle{}
+
+// NewBlobServiceHandle creates a new instance of BlobServiceHandle for the
+// given storage account.
+func NewBlobServiceHandle(accountName, accountKey string) (*BlobServiceHandle, error) {
+	return NewBlobServiceHandleWithEndpointSuffix(accountName, accountKey, DefaultEndpointSuffix)
+}
+
+// NewBlobServiceHandleWithEndpointSuffix creates a new instance of
+// BlobServiceHandle for the given storage account, whose blob service
+// endpoint has the supplied DNS suffix, such as that of a sovereign cloud. The
+// public cloud's suffix is used when it is empty.
+func NewBlobServiceHandleWithEndpointSuffix(accountName, accountKey, suffix string) (*BlobServiceHandle, error) {
+	if suffix == "" {
+		suffix = DefaultEndpointSuffix
+	}
+	c, err := azblob.NewSharedKeyCredential(accountName, accountKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewBlobServiceHandleWithCredential(accountName, c, suffix)
+}
+
+// NewBlobServiceHandleWithCredential creates a new instance of
+// BlobServiceHandle for the given storage account that authorizes requests
+// with the supplied credential, such as a token credential of an account that
+// disallows shared key access. The public cloud's endpoint suffix is used when
+// the supplied one is empty.
+func NewBlobServiceHandleWithCredential(accountName string, c azblob.Credential, suffix string) (*BlobServiceHandle, error) {
+	if suffix == "" {
+		suffix = DefaultEndpointSuffix
+	}
+	p := newPipeline(c, azblob.PipelineOptions{
+		Telemetry: azblob.TelemetryOptions{Value: azure.UserAgent},
+	}, nil, "")
+
+	u, _ := url.Parse(fmt.Sprintf(blobFormatString, accountName, suffix))
+	return &BlobServiceHandle{ServiceURL: azblob.NewServiceURL(*u, p), pipeline: p}, nil
+}
+
+// GetMetricsConfig returns the hour and minute metrics configuration of the
+// blob service.
+func (h *BlobServiceHandle) GetMetricsConfig(ctx context.Context) (hour, minute MetricsProperties, err error) {
+	p, err := h.GetProperties(ctx)
+	if err != nil {
+		return MetricsProperties{}, MetricsProperties{}, err
+	}
+	return metricsProperties(p.HourMetrics), metricsProperties(p.MinuteMetrics), nil
+}
+
+// SetMetricsConfig sets the hour and minute metrics configuration of the blob
+// service. Other blob service properties are left unchanged.
+func (h *BlobServiceHandle) SetMetricsConfig(ctx context.Context, hour, minute MetricsProperties) error {
+	for _, p := range []MetricsProperties{hour, minute} {
+		if err := ValidateMetricsProperties(p); err != nil {
+			return err
+		}
+	}
+	return h.setProperties(ctx, serviceProperties{
+		HourMetrics:   toMetrics(hour),
+		MinuteMetrics: toMetrics(minute),
+	})
+}
+
+// GetLoggingConfig returns the logging configuration of the blob service.
+func (h *BlobServiceHandle) GetLoggingConfig(ctx context.Context) (LoggingProperties, error) {
+	p, err := h.GetProperties(ctx)
+	if err != nil {
+		return LoggingProperties{}, err
+	}
+	return loggingProperties(p.Logging), nil
+}
+
+// SetLoggingConfig sets the logging configuration of the blob service. Other
+// blob service properties are left unchanged.
+func (h *BlobServiceHandle) SetLoggingConfig(ctx context.Context, p LoggingProperties) error {
+	if err := ValidateLoggingProperties(p); err != nil {
+		return err
+	}
+	return h.setProperties(ctx, serviceProperties{Logging: toLogging(p)})
+}
+
+// GetBlobSoftDelete returns whether deleted blobs are retained, and for how
+// many days. Days are zero when soft delete is disabled.
+func (h *BlobServiceHandle) GetBlobSoftDelete(ctx context.Context) (bool, int32, error) {
+	p, err := h.GetProperties(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	if p.DeleteRetentionPolicy == nil || !p.DeleteRetentionPolicy.Enabled {
+		return false, 0, nil
+	}
+	return true, to.Int32(p.DeleteRetentionPolicy.Days), nil
+}
+
+// RequireBlobSoftDelete returns a SoftDeleteDisabledError unless blob soft
+// delete is enabled on the named storage account. Restoring deleted blobs
+// requires it.
+func RequireBlobSoftDelete(ctx context.Context, bs BlobServiceOperations, account string) error {
+	enabled, _, err := bs.GetBlobSoftDelete(ctx)
+	if err != nil {
+		return errors.Wrap(err, "cannot get blob soft delete")
+	}
+	if !enabled {
+		return &SoftDeleteDisabledError{Account: account}
+	}
+	return nil
+}
+
+// SetBlobSoftDelete sets whether deleted blobs are retained, and for how many
+// days. Other blob service properties are left unchanged.
+func (h *BlobServiceHandle) SetBlobSoftDelete(ctx context.Context, enabled bool, days int32) error {
+	if err := ValidateBlobSoftDelete(enabled, days); err != nil {
+		return err
+	}
+	rp := &azblob.RetentionPolicy{Enabled: enabled}
+	if enabled {
+		rp.Days = to.Int32Ptr(days)
+	}
+	return h.setProperties(ctx, serviceProperties{DeleteRetentionPolicy: rp})
+}
+
+// serviceProperties are the subset of blob service properties we manage.
+// Properties that are omitted from a request are left unchanged by Azure.
+type serviceProperties struct {
+	XMLName       xml.Name        `xml:"StorageServiceProperties"`
+	Logging       *azblob.Logging `xml:"Logging,omitempty"`
+	HourMetrics   *azblob.Metrics `xml:"HourMetrics,omitempty"`
+	MinuteMetrics *azblob.Metrics `xml:"MinuteMetrics,omitempty"`
+
+	DeleteRetentionPolicy *azblob.RetentionPolicy `xml:"DeleteRetentionPolicy,omitempty"`
+}
+
+// setProperties sets the supplied blob service properties. The azblob SDK
+// always sends an empty Cors element, which would delete the service's CORS
+// rules, and its client side validation rejects every Logging element, so the
+// request is built by hand.
+func (h *BlobServiceHandle) setProperties(ctx context.Context, p serviceProperties) error {
+	body, err := xml.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "cannot encode blob service properties")
+	}
+
+	u := h.URL()
+	q := u.Query()
+	q.Set("restype", "service")
+	q.Set("comp", "properties")
+	u.RawQuery = q.Encode()
+
+	_, _, err = send(ctx, h.pipeline, http.MethodPut, u, http.Header{"Content-Type": {"application/xml"}}, body, http.StatusAccepted)
+	return err
+}
+
+func loggingProperties(l *azblob.Logging) LoggingProperties {
+	if l == nil {
+		return LoggingProperties{}
+	}
+	p := LoggingProperties{Read: l.Read, Write: l.Write, Delete: l.Delete}
+	if l.RetentionPolicy.Enabled {
+		p.RetentionDays = l.RetentionPolicy.Days
+	}
+	return p
+}
+
+func toLogging(p LoggingProperties) *azblob.Logging {
+	return &azblob.Logging{
+		Version:         analyticsVersion,
+		Read:            p.Read,
+		Write:           p.Write,
+		Delete:          p.Delete,
+		RetentionPolicy: azblob.RetentionPolicy{Enabled: p.RetentionDays != nil, Days: p.RetentionDays},
+	}
+}
+
+func metricsProperties(m *azblob.Metrics) MetricsProperties {
+	if m == nil {
+		return MetricsProperties{}
+	}
+	p := MetricsProperties{
+		Enabled:     m.Enabled,
+		IncludeAPIs: m.Enabled && to.Bool(m.IncludeAPIs),
+	}
+	if m.RetentionPolicy != nil && m.RetentionPolicy.Enabled {
+		p.RetentionDays = m.RetentionPolicy.Days
+	}
+	return p
+}
+
+func toMetrics(p MetricsProperties) *azblob.Metrics {
+	m := &azblob.Metrics{
+		Version:         to.StringPtr(analyticsVersion),
+		Enabled:         p.Enabled,
+		RetentionPolicy: &azblob.RetentionPolicy{Enabled: p.RetentionDays != nil, Days: p.RetentionDays},
+	}
+	// Azure rejects IncludeAPIs for disabled metrics.
+	if p.Enabled {
+		m.IncludeAPIs = to.BoolPtr(p.IncludeAPIs)
+	}
+	return m
+}
+
+// GetMetadataForContainers reads the metadata of each named container, a few
+// containers at a time. It returns the metadata of every container it could
+// read, and the error reading each container it could not. A container that
+// does not exist has a not found error. Containers that were not yet read when
+// the supplied context was cancelled have the context's error.
+func (h *BlobServiceHandle) GetMetadataForContainers(ctx context.Context, names []string) (map[string]azblob.Metadata, map[string]error) {
+	mds := make(map[string]azblob.Metadata, len(names))
+	errs := make(map[string]error)
+
+	mu := &sync.Mutex{}
+	record := func(name string, md azblob.Metadata, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[name] = err
+			return
+		}
+		mds[name] = md
+	}
+
+	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, metadataConcurrency)
+	for _, name := range names {
+		if ctx.Err() != nil {
+			record(name, nil, ctx.Err())
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			record(name, nil, ctx.Err())
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer func() { <-sem; wg.Done() }()
+			resp, err := h.NewContainerURL(name).GetProperties(ctx, azblob.LeaseAccessConditions{})
+			if err != nil {
+				record(name, nil, err)
+				return
+			}
+			record(name, resp.NewMetadata(), nil)
+		}(name)
+	}
+	wg.Wait()
+
+	return mds, errs
+}
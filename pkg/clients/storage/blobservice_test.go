@@ -0,0 +1,464 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+// newTestBlobServiceHandle returns a BlobServiceHandle whose requests are
+// served by the supplied handler rather than Azure.
+func newTestBlobServiceHandle(t *testing.T, h http.Handler) *BlobServiceHandle {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	c, err := azblob.NewSharedKeyCredential(testAccount, testKey)
+	if err != nil {
+		t.Fatalf("azblob.NewSharedKeyCredential(...): %v", err)
+	}
+	p := azblob.NewPipeline(c, azblob.PipelineOptions{Retry: azblob.RetryOptions{MaxTries: 1}})
+	u, _ := url.Parse(srv.URL)
+	return &BlobServiceHandle{ServiceURL: azblob.NewServiceURL(*u, p), pipeline: p}
+}
+
+func TestGetMetricsConfig(t *testing.T) {
+	body := `<?xml version="1.0" encoding="utf-8"?><StorageServiceProperties>` +
+		`<HourMetrics><Version>1.0</Version><Enabled>true</Enabled><IncludeAPIs>true</IncludeAPIs><RetentionPolicy><Enabled>true</Enabled><Days>7</Days></RetentionPolicy></HourMetrics>` +
+		`<MinuteMetrics><Version>1.0</Version><Enabled>false</Enabled><RetentionPolicy><Enabled>false</Enabled></RetentionPolicy></MinuteMetrics>` +
+		`</StorageServiceProperties>`
+	h := newTestBlobServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+
+	hour, minute, err := h.GetMetricsConfig(context.Background())
+	if err != nil {
+		t.Fatalf("GetMetricsConfig(...): %v", err)
+	}
+	if diff := cmp.Diff(MetricsProperties{Enabled: true, IncludeAPIs: true, RetentionDays: to.Int32Ptr(7)}, hour); diff != "" {
+		t.Errorf("GetMetricsConfig(...): -want hour, +got hour:\n%s", diff)
+	}
+	if diff := cmp.Diff(MetricsProperties{}, minute); diff != "" {
+		t.Errorf("GetMetricsConfig(...): -want minute, +got minute:\n%s", diff)
+	}
+}
+
+func TestSetMetricsConfig(t *testing.T) {
+	type want struct {
+		props *azblob.StorageServiceProperties
+		err   bool
+	}
+	cases := map[string]struct {
+		reason string
+		hour   MetricsProperties
+		minute MetricsProperties
+		want   want
+	}{
+		"EnableHour": {
+			reason: "Hour metrics should be enabled, and disabled minute metrics should not include APIs.",
+			hour:   MetricsProperties{Enabled: true, IncludeAPIs: true, RetentionDays: to.Int32Ptr(7)},
+			minute: MetricsProperties{IncludeAPIs: true},
+			want: want{
+				props: &azblob.StorageServiceProperties{
+					HourMetrics: &azblob.Metrics{
+						Version:         to.StringPtr(analyticsVersion),
+						Enabled:         true,
+						IncludeAPIs:     to.BoolPtr(true),
+						RetentionPolicy: &azblob.RetentionPolicy{Enabled: true, Days: to.Int32Ptr(7)},
+					},
+					MinuteMetrics: &azblob.Metrics{
+						Version:         to.StringPtr(analyticsVersion),
+						RetentionPolicy: &azblob.RetentionPolicy{},
+					},
+				},
+			},
+		},
+		"EnableMinute": {
+			reason: "Minute metrics should be enabled without a retention policy.",
+			minute: MetricsProperties{Enabled: true},
+			want: want{
+				props: &azblob.StorageServiceProperties{
+					HourMetrics: &azblob.Metrics{
+						Version:         to.StringPtr(analyticsVersion),
+						RetentionPolicy: &azblob.RetentionPolicy{},
+					},
+					MinuteMetrics: &azblob.Metrics{
+						Version:         to.StringPtr(analyticsVersion),
+						Enabled:         true,
+						IncludeAPIs:     to.BoolPtr(false),
+						RetentionPolicy: &azblob.RetentionPolicy{},
+					},
+				},
+			},
+		},
+		"RetentionTooShort": {
+			reason: "Retention of less than a day should be rejected.",
+			hour:   MetricsProperties{Enabled: true, RetentionDays: to.Int32Ptr(0)},
+			want:   want{err: true},
+		},
+		"RetentionTooLong": {
+			reason: "Retention of more than a year should be rejected.",
+			minute: MetricsProperties{Enabled: true, RetentionDays: to.Int32Ptr(366)},
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got *azblob.StorageServiceProperties
+			h := newTestBlobServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				if strings.Contains(string(b), "<Cors") {
+					t.Errorf("request body should not contain CORS rules: %s", b)
+				}
+				got = &azblob.StorageServiceProperties{}
+				if err := xml.Unmarshal(b, got); err != nil {
+					t.Errorf("xml.Unmarshal(...): %v", err)
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+
+			err := h.SetMetricsConfig(context.Background(), tc.hour, tc.minute)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nSetMetricsConfig(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.props, got, cmp.AllowUnexported(azblob.StorageServiceProperties{})); diff != "" {
+				t.Errorf("\n%s\nSetMetricsConfig(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetLoggingConfig(t *testing.T) {
+	body := `<?xml version="1.0" encoding="utf-8"?><StorageServiceProperties>` +
+		`<Logging><Version>1.0</Version><Read>true</Read><Write>false</Write><Delete>true</Delete><RetentionPolicy><Enabled>true</Enabled><Days>7</Days></RetentionPolicy></Logging>` +
+		`</StorageServiceProperties>`
+	h := newTestBlobServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+
+	got, err := h.GetLoggingConfig(context.Background())
+	if err != nil {
+		t.Fatalf("GetLoggingConfig(...): %v", err)
+	}
+	if diff := cmp.Diff(LoggingProperties{Read: true, Delete: true, RetentionDays: to.Int32Ptr(7)}, got); diff != "" {
+		t.Errorf("GetLoggingConfig(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestSetLoggingConfig(t *testing.T) {
+	type want struct {
+		props *azblob.StorageServiceProperties
+		err   bool
+	}
+	cases := map[string]struct {
+		reason  string
+		logging LoggingProperties
+		want    want
+	}{
+		"EnableAll": {
+			reason:  "Every kind of request should be logged and retained.",
+			logging: LoggingProperties{Read: true, Write: true, Delete: true, RetentionDays: to.Int32Ptr(30)},
+			want: want{
+				props: &azblob.StorageServiceProperties{
+					Logging: &azblob.Logging{
+						Version:         analyticsVersion,
+						Read:            true,
+						Write:           true,
+						Delete:          true,
+						RetentionPolicy: azblob.RetentionPolicy{Enabled: true, Days: to.Int32Ptr(30)},
+					},
+				},
+			},
+		},
+		"Partial": {
+			reason:  "Only the requested kinds of request should be logged.",
+			logging: LoggingProperties{Write: true},
+			want: want{
+				props: &azblob.StorageServiceProperties{
+					Logging: &azblob.Logging{
+						Version: analyticsVersion,
+						Write:   true,
+					},
+				},
+			},
+		},
+		"Disable": {
+			reason: "Disabling logging should clear every flag and the retention policy.",
+			want: want{
+				props: &azblob.StorageServiceProperties{
+					Logging: &azblob.Logging{Version: analyticsVersion},
+				},
+			},
+		},
+		"RetentionTooLong": {
+			reason:  "Retention of more than a year should be rejected.",
+			logging: LoggingProperties{Read: true, RetentionDays: to.Int32Ptr(366)},
+			want:    want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got *azblob.StorageServiceProperties
+			h := newTestBlobServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				if strings.Contains(string(b), "<Cors") {
+					t.Errorf("request body should not contain CORS rules: %s", b)
+				}
+				got = &azblob.StorageServiceProperties{}
+				if err := xml.Unmarshal(b, got); err != nil {
+					t.Errorf("xml.Unmarshal(...): %v", err)
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+
+			err := h.SetLoggingConfig(context.Background(), tc.logging)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nSetLoggingConfig(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.props, got, cmp.AllowUnexported(azblob.StorageServiceProperties{})); diff != "" {
+				t.Errorf("\n%s\nSetLoggingConfig(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetBlobSoftDelete(t *testing.T) {
+	type want struct {
+		enabled bool
+		days    int32
+	}
+	cases := map[string]struct {
+		reason string
+		body   string
+		want   want
+	}{
+		"Enabled": {
+			reason: "Enabled soft delete should be reported along with its retention.",
+			body:   `<DeleteRetentionPolicy><Enabled>true</Enabled><Days>14</Days></DeleteRetentionPolicy>`,
+			want:   want{enabled: true, days: 14},
+		},
+		"Disabled": {
+			reason: "Disabled soft delete should be reported without retention.",
+			body:   `<DeleteRetentionPolicy><Enabled>false</Enabled></DeleteRetentionPolicy>`,
+		},
+		"Unreported": {
+			reason: "Soft delete should be reported as disabled when the service does not report it.",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestBlobServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><StorageServiceProperties>` + tc.body + `</StorageServiceProperties>`))
+			}))
+			enabled, days, err := h.GetBlobSoftDelete(context.Background())
+			if err != nil {
+				t.Fatalf("\n%s\nGetBlobSoftDelete(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, want{enabled: enabled, days: days}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nGetBlobSoftDelete(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSetBlobSoftDelete(t *testing.T) {
+	type want struct {
+		props *azblob.StorageServiceProperties
+		err   bool
+	}
+	cases := map[string]struct {
+		reason  string
+		enabled bool
+		days    int32
+		want    want
+	}{
+		"Enable": {
+			reason:  "Enabling soft delete should set its retention.",
+			enabled: true,
+			days:    7,
+			want: want{
+				props: &azblob.StorageServiceProperties{
+					DeleteRetentionPolicy: &azblob.RetentionPolicy{Enabled: true, Days: to.Int32Ptr(7)},
+				},
+			},
+		},
+		"Disable": {
+			reason: "Disabling soft delete should not send a retention.",
+			days:   7,
+			want: want{
+				props: &azblob.StorageServiceProperties{
+					DeleteRetentionPolicy: &azblob.RetentionPolicy{},
+				},
+			},
+		},
+		"DaysTooFew": {
+			reason:  "Retention of less than a day should be rejected.",
+			enabled: true,
+			want:    want{err: true},
+		},
+		"DaysTooMany": {
+			reason:  "Retention of more than a year should be rejected.",
+			enabled: true,
+			days:    366,
+			want:    want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got *azblob.StorageServiceProperties
+			h := newTestBlobServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				got = &azblob.StorageServiceProperties{}
+				if err := xml.Unmarshal(b, got); err != nil {
+					t.Errorf("xml.Unmarshal(...): %v", err)
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+
+			err := h.SetBlobSoftDelete(context.Background(), tc.enabled, tc.days)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nSetBlobSoftDelete(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.props, got, cmp.AllowUnexported(azblob.StorageServiceProperties{})); diff != "" {
+				t.Errorf("\n%s\nSetBlobSoftDelete(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRequireBlobSoftDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		body    string
+		wantErr bool
+	}{
+		"Enabled": {
+			reason: "No error should be returned when soft delete is enabled.",
+			body:   `<DeleteRetentionPolicy><Enabled>true</Enabled><Days>7</Days></DeleteRetentionPolicy>`,
+		},
+		"Disabled": {
+			reason:  "A SoftDeleteDisabledError should be returned when soft delete is disabled.",
+			body:    `<DeleteRetentionPolicy><Enabled>false</Enabled></DeleteRetentionPolicy>`,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestBlobServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><StorageServiceProperties>` + tc.body + `</StorageServiceProperties>`))
+			}))
+			err := RequireBlobSoftDelete(context.Background(), h, testAccount)
+			if IsSoftDeleteDisabled(err) != tc.wantErr {
+				t.Errorf("\n%s\nRequireBlobSoftDelete(...): want soft delete disabled error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetMetadataForContainers(t *testing.T) {
+	names := []string{"a", "missing", "b", "forbidden"}
+	for i := 0; i < 2*metadataConcurrency; i++ {
+		names = append(names, fmt.Sprintf("c%d", i))
+	}
+
+	mu := &sync.Mutex{}
+	current, peak := 0, 0
+	h := newTestBlobServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		current++
+		if current > peak {
+			peak = current
+		}
+		mu.Unlock()
+		defer func() { mu.Lock(); current--; mu.Unlock() }()
+		time.Sleep(10 * time.Millisecond)
+
+		switch r.URL.Path {
+		case "/missing":
+			w.Header().Set("x-ms-error-code", string(azblob.ServiceCodeContainerNotFound))
+			w.WriteHeader(http.StatusNotFound)
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.Header().Set("x-ms-meta-name", strings.TrimPrefix(r.URL.Path, "/"))
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	mds, errs := h.GetMetadataForContainers(context.Background(), names)
+
+	if diff := cmp.Diff(azblob.Metadata{"name": "a"}, mds["a"]); diff != "" {
+		t.Errorf("GetMetadataForContainers(...): -want a, +got a:\n%s", diff)
+	}
+	if len(mds) != len(names)-2 {
+		t.Errorf("GetMetadataForContainers(...): want metadata of %d containers, got %d", len(names)-2, len(mds))
+	}
+	if len(errs) != 2 {
+		t.Errorf("GetMetadataForContainers(...): want 2 errors, got %v", errs)
+	}
+	if kind, ok := NotFoundKind(errs["missing"]); !ok || kind != KindContainer {
+		t.Errorf("GetMetadataForContainers(...): want container not found error for missing, got %v", errs["missing"])
+	}
+	if errs["forbidden"] == nil || IsNotFoundError(errs["forbidden"]) {
+		t.Errorf("GetMetadataForContainers(...): want forbidden error for forbidden, got %v", errs["forbidden"])
+	}
+	if peak > metadataConcurrency {
+		t.Errorf("GetMetadataForContainers(...): want at most %d concurrent requests, got %d", metadataConcurrency, peak)
+	}
+}
+
+func TestGetMetadataForContainersCancelled(t *testing.T) {
+	requests := 0
+	h := newTestBlobServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mds, errs := h.GetMetadataForContainers(ctx, []string{"a", "b"})
+	if len(mds) != 0 {
+		t.Errorf("GetMetadataForContainers(...): want no metadata, got %v", mds)
+	}
+	for _, name := range []string{"a", "b"} {
+		if !errors.Is(errs[name], context.Canceled) {
+			t.Errorf("GetMetadataForContainers(...): want %s cancelled, got %v", name, errs[name])
+		}
+	}
+	if requests != 0 {
+		t.Errorf("GetMetadataForContainers(...): want no requests, got %d", requests)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/pkg/errors"
+)
+
+// ErrBudgetExceeded is returned by container operations that would run past
+// the time budget of their context.
+var ErrBudgetExceeded = errors.New("container operations exceeded their time budget")
+
+// ErrOperationBudgetExceeded is returned by container operations that would
+// send more requests than the operation budget of their context permits.
+var ErrOperationBudgetExceeded = errors.New("container operations exceeded their operation budget")
+
+// budgetResolution is the resolution with which the azblob retry policy
+// times tries out. It rounds the time left until an operation's deadline down
+// to whole seconds, so an operation that starts with less than a second of its
+// budget left fails without being tried.
+const budgetResolution = time.Second
+
+type budgetKey struct{}
+
+type operationBudgetKey struct{}
+
+// WithOperationBudget returns a context that limits the number of requests
+// that every container operation made with it may send to the blob service,
+// not counting retries, to the supplied maximum. Operations that would send
+// more fail with ErrOperationBudgetExceeded, without sending the request.
+// Operations that work through many blobs stop at the first such failure, so
+// that they can be resumed with a fresh budget.
+func WithOperationBudget(ctx context.Context, max int) context.Context {
+	left := int64(max)
+	return context.WithValue(ctx, operationBudgetKey{}, &left)
+}
+
+// spendOperation spends one request of the supplied context's operation
+// budget. It returns false if the budget is spent.
+func spendOperation(ctx context.Context) bool {
+	left, ok := ctx.Value(operationBudgetKey{}).(*int64)
+	return !ok || atomic.AddInt64(left, -1) >= 0
+}
+
+// WithBudget returns a context that limits the total time spent by every
+// container operation made with it, including each operation's retries, to
+// the supplied duration. Each operation is bounded by what remains of the
+// budget when it starts, and returns ErrBudgetExceeded if the budget runs
+// out before or while it runs. A parent deadline that is earlier than the
+// budget still applies.
+func WithBudget(ctx context.Context, total time.Duration) (context.Context, context.CancelFunc) {
+	deadline := time.Now().Add(total)
+	return context.WithDeadline(context.WithValue(ctx, budgetKey{}, deadline), deadline)
+}
+
+// budgetLeft returns how much of the supplied context's time budget is left,
+// and whether it has a budget.
+func budgetLeft(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Value(budgetKey{}).(time.Time)
+	return time.Until(deadline), ok
+}
+
+// newBudgetPolicyFactory returns a factory of policies that fail operations
+// whose context's operation budget is spent with ErrOperationBudgetExceeded,
+// and operations whose context's time budget is spent with ErrBudgetExceeded,
+// rather than a less helpful context deadline error. Operations that fail
+// with less than the budgetResolution left are considered to have spent it.
+func newBudgetPolicyFactory() pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, _ *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, req pipeline.Request) (pipeline.Response, error) {
+			if !spendOperation(ctx) {
+				return nil, ErrOperationBudgetExceeded
+			}
+			left, ok := budgetLeft(ctx)
+			if !ok {
+				return next.Do(ctx, req)
+			}
+			if left < budgetResolution {
+				return nil, ErrBudgetExceeded
+			}
+			resp, err := next.Do(ctx, req)
+			if left, _ := budgetLeft(ctx); err == nil || left >= budgetResolution {
+				return resp, err
+			}
+			if resp != nil && resp.Response() != nil && resp.Response().Body != nil {
+				_ = resp.Response().Body.Close()
+			}
+			return nil, ErrBudgetExceeded
+		}
+	})
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+// newTestBudgetContainerHandle returns a ContainerHandle whose requests are
+// served by the supplied handler and whose pipeline enforces time budgets.
+func newTestBudgetContainerHandle(t *testing.T, h http.Handler) *ContainerHandle {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	c, err := azblob.NewSharedKeyCredential(testAccount, testKey)
+	if err != nil {
+		t.Fatalf("azblob.NewSharedKeyCredential(...): %v", err)
+	}
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		newBudgetPolicyFactory(),
+		azblob.NewRetryPolicyFactory(azblob.RetryOptions{MaxTries: 1}),
+		c,
+		pipeline.MethodFactoryMarker(),
+	}, pipeline.Options{})
+	u, _ := url.Parse(srv.URL + "/" + testContainer)
+	return &ContainerHandle{ContainerURL: azblob.NewContainerURL(*u, p), pipeline: p}
+}
+
+func TestWithBudget(t *testing.T) {
+	type want struct {
+		err  error
+		reqs []string
+	}
+	cases := map[string]struct {
+		reason string
+		budget time.Duration
+		delay  time.Duration
+		want   want
+	}{
+		"Fits": {
+			reason: "Operations that together take less than the budget should succeed.",
+			budget: 5 * time.Second,
+			delay:  10 * time.Millisecond,
+			want: want{
+				reqs: []string{
+					"GET /testcontainer",
+					"PUT /testcontainer",
+					"PUT /testcontainer",
+					"GET /testcontainer",
+				},
+			},
+		},
+		"Exceeded": {
+			reason: "Operations that together take longer than the budget should fail once it is spent, and no further requests should be sent.",
+			budget: 1500 * time.Millisecond,
+			delay:  400 * time.Millisecond,
+			want: want{
+				err: ErrBudgetExceeded,
+				reqs: []string{
+					"GET /testcontainer",
+					"PUT /testcontainer",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := &recorder{}
+			h := newTestBudgetContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rec.record(r)
+				select {
+				case <-time.After(tc.delay):
+				case <-r.Context().Done():
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			ctx, cancel := WithBudget(context.Background(), tc.budget)
+			defer cancel()
+
+			// A reconcile gets the container, updates it, then gets it again.
+			_, _, err := h.Get(ctx)
+			if err == nil {
+				err = h.Update(ctx, azblob.PublicAccessNone, nil)
+			}
+			if err == nil {
+				_, _, err = h.Get(ctx)
+			}
+			if !errorIs(err, tc.want.err) {
+				t.Errorf("\n%s\nWithBudget(...): want error %v, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.reqs, rec.requests()); diff != "" {
+				t.Errorf("\n%s\nWithBudget(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithBudgetParentDeadline(t *testing.T) {
+	h := newTestBudgetContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+
+	parent, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ctx, cancel := WithBudget(parent, time.Minute)
+	defer cancel()
+
+	// The budget is not spent, so the parent's deadline should be reported.
+	if _, _, err := h.Get(ctx); err == nil || errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Get(...): want an error other than ErrBudgetExceeded, got %v", err)
+	}
+}
+
+func TestWithOperationBudget(t *testing.T) {
+	type want struct {
+		err  error
+		reqs []string
+	}
+	cases := map[string]struct {
+		reason string
+		budget int
+		want   want
+	}{
+		"Fits": {
+			reason: "Operations that together send no more requests than the budget should succeed.",
+			budget: 4,
+			want: want{
+				reqs: []string{
+					"GET /testcontainer",
+					"PUT /testcontainer",
+					"PUT /testcontainer",
+					"GET /testcontainer",
+				},
+			},
+		},
+		"Exceeded": {
+			reason: "Operations should fail once the budget is spent, and no further requests should be sent.",
+			budget: 2,
+			want: want{
+				err: ErrOperationBudgetExceeded,
+				reqs: []string{
+					"GET /testcontainer",
+					"PUT /testcontainer",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := &recorder{}
+			h := newTestBudgetContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rec.record(r)
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			ctx := WithOperationBudget(context.Background(), tc.budget)
+
+			// A reconcile gets the container, updates it, then gets it again.
+			_, _, err := h.Get(ctx)
+			if err == nil {
+				err = h.Update(ctx, azblob.PublicAccessNone, nil)
+			}
+			if err == nil {
+				_, _, err = h.Get(ctx)
+			}
+			if !errorIs(err, tc.want.err) {
+				t.Errorf("\n%s\nWithOperationBudget(...): want error %v, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.reqs, rec.requests()); diff != "" {
+				t.Errorf("\n%s\nWithOperationBudget(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithOperationBudgetResume(t *testing.T) {
+	mu := sync.Mutex{}
+	blobs := map[string]bool{"a": true, "b": true, "c": true}
+	h := newTestBudgetContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodGet {
+			names := make([]string, 0, len(blobs))
+			for b := range blobs {
+				names = append(names, b)
+			}
+			sort.Strings(names)
+			versions := make([]Blob, len(names))
+			for i, b := range names {
+				versions[i] = Blob{Name: b, Snapshot: "s1"}
+			}
+			fmt.Fprint(w, blobListing("", versions...))
+			return
+		}
+		delete(blobs, strings.TrimPrefix(r.URL.Path, "/"+testContainer+"/"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	// Each reconcile lists the remaining snapshots, then deletes the blobs
+	// they were taken of, until its budget of three requests is spent.
+	reconcile := func() error {
+		ctx := WithOperationBudget(context.Background(), 3)
+		snaps, err := h.ListSnapshots(ctx, "")
+		if err != nil {
+			return err
+		}
+		return h.DeleteBlobsWithSnapshots(ctx, snaps)
+	}
+	remaining := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(blobs)
+	}
+
+	if err := reconcile(); !errors.Is(err, ErrOperationBudgetExceeded) {
+		t.Fatalf("DeleteBlobsWithSnapshots(...): want error %v, got %v", ErrOperationBudgetExceeded, err)
+	}
+	if got := remaining(); got != 1 {
+		t.Fatalf("DeleteBlobsWithSnapshots(...): want 1 blob left once the budget is spent, got %d", got)
+	}
+	if err := reconcile(); err != nil {
+		t.Fatalf("DeleteBlobsWithSnapshots(...): want the next reconcile to resume and succeed, got %v", err)
+	}
+	if got := remaining(); got != 0 {
+		t.Errorf("DeleteBlobsWithSnapshots(...): want no blobs left after resuming, got %d", got)
+	}
+}
+
+// errorIs returns true if the got error is or wraps the wanted one, or if
+// neither is set.
+func errorIs(got, want error) bool {
+	if want == nil {
+		return got == nil
+	}
+	return errors.Is(got, want)
+}
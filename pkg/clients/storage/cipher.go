@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+// A MetadataCipher encrypts and decrypts sensitive container metadata values,
+// so that they are not stored in Azure in plaintext. Ciphers are supplied the
+// key of each value, for example to use it as associated data.
+type MetadataCipher interface {
+	Encrypt(key, plaintext string) (string, error)
+	Decrypt(key, ciphertext string) (string, error)
+}
+
+// MetadataEncryptingContainerOperations decorates ContainerOperations so that
+// metadata values of keys with a sensitive prefix are encrypted before they
+// are written and decrypted after they are read. Callers, including drift
+// detection, only ever see plaintext values.
+type MetadataEncryptingContainerOperations struct {
+	ContainerOperations
+
+	cipher MetadataCipher
+	prefix string
+}
+
+var _ ContainerOperations = &MetadataEncryptingContainerOperations{}
+
+// NewMetadataEncryptingContainerOperations returns ContainerOperations that
+// encrypt the values of metadata keys that start with the supplied prefix,
+// ignoring case, with the supplied cipher. The supplied operations are
+// returned unchanged when no cipher or prefix is configured.
+func NewMetadataEncryptingContainerOperations(o ContainerOperations, c MetadataCipher, prefix string) ContainerOperations {
+	if c == nil || prefix == "" {
+		return o
+	}
+	return &MetadataEncryptingContainerOperations{ContainerOperations: o, cipher: c, prefix: strings.ToLower(prefix)}
+}
+
+// sensitive returns true if the value of the supplied metadata key must be
+// encrypted. The blob service returns metadata keys in lower case.
+func (e *MetadataEncryptingContainerOperations) sensitive(key string) bool {
+	return strings.HasPrefix(strings.ToLower(key), e.prefix)
+}
+
+// transform returns a copy of the supplied metadata with each sensitive value
+// replaced by the result of the supplied function.
+func (e *MetadataEncryptingContainerOperations) transform(md azblob.Metadata, fn func(key, value string) (string, error), action string) (azblob.Metadata, error) {
+	if md == nil {
+		return nil, nil
+	}
+	out := make(azblob.Metadata, len(md))
+	for k, v := range md {
+		if !e.sensitive(k) {
+			out[k] = v
+			continue
+		}
+		t, err := fn(k, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot %s value of metadata key %s", action, k)
+		}
+		out[k] = t
+	}
+	return out, nil
+}
+
+func (e *MetadataEncryptingContainerOperations) encrypt(md azblob.Metadata) (azblob.Metadata, error) {
+	return e.transform(md, e.cipher.Encrypt, "encrypt")
+}
+
+func (e *MetadataEncryptingContainerOperations) decrypt(md azblob.Metadata) (azblob.Metadata, error) {
+	return e.transform(md, e.cipher.Decrypt, "decrypt")
+}
+
+// Create creates the container with its sensitive metadata values encrypted.
+func (e *MetadataEncryptingContainerOperations) Create(ctx context.Context, publicAccessType azblob.PublicAccessType, metadata azblob.Metadata) error {
+	md, err := e.encrypt(metadata)
+	if err != nil {
+		return err
+	}
+	return e.ContainerOperations.Create(ctx, publicAccessType, md)
+}
+
+// Update updates the container with its sensitive metadata values encrypted.
+func (e *MetadataEncryptingContainerOperations) Update(ctx context.Context, publicAccessType azblob.PublicAccessType, metadata azblob.Metadata) error {
+	md, err := e.encrypt(metadata)
+	if err != nil {
+		return err
+	}
+	return e.ContainerOperations.Update(ctx, publicAccessType, md)
+}
+
+// UpdatePartial partially updates the container with its sensitive metadata
+// values encrypted.
+func (e *MetadataEncryptingContainerOperations) UpdatePartial(ctx context.Context, publicAccessType *azblob.PublicAccessType, metadata *azblob.Metadata) UpdateResult {
+	if metadata == nil {
+		return e.ContainerOperations.UpdatePartial(ctx, publicAccessType, nil)
+	}
+	md, err := e.encrypt(*metadata)
+	if err != nil {
+		return UpdateResult{Err: err}
+	}
+	return e.ContainerOperations.UpdatePartial(ctx, publicAccessType, &md)
+}
+
+// Get gets the container's access type and metadata, with its sensitive
+// metadata values decrypted.
+func (e *MetadataEncryptingContainerOperations) Get(ctx context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+	access, md, err := e.ContainerOperations.Get(ctx)
+	if err != nil {
+		return access, md, err
+	}
+	md, err = e.decrypt(md)
+	return access, md, err
+}
+
+// GetContainerProperties gets the container's properties, with its sensitive
+// metadata values decrypted.
+func (e *MetadataEncryptingContainerOperations) GetContainerProperties(ctx context.Context) (*ContainerProperties, error) {
+	p, err := e.ContainerOperations.GetContainerProperties(ctx)
+	if err != nil || p == nil {
+		return p, err
+	}
+	md, err := e.decrypt(p.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	out := *p
+	out.Metadata = md
+	return &out, nil
+}
+
+// Import imports the container, with its sensitive metadata values decrypted.
+func (e *MetadataEncryptingContainerOperations) Import(ctx context.Context) (ContainerSnapshot, error) {
+	s, err := e.ContainerOperations.Import(ctx)
+	if err != nil {
+		return s, err
+	}
+	md, err := e.decrypt(s.Metadata)
+	if err != nil {
+		return ContainerSnapshot{}, err
+	}
+	s.Metadata = md
+	return s, nil
+}
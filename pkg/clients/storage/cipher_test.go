@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+// testCipher "encrypts" values by base64 encoding them with their key.
+type testCipher struct{}
+
+func (testCipher) Encrypt(key, plaintext string) (string, error) {
+	return "enc:" + base64.StdEncoding.EncodeToString([]byte(key+"="+plaintext)), nil
+}
+
+func (testCipher) Decrypt(key, ciphertext string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, "enc:"))
+	if err != nil || !strings.HasPrefix(ciphertext, "enc:") || !strings.HasPrefix(string(b), key+"=") {
+		return "", errors.New("not encrypted with this key")
+	}
+	return strings.TrimPrefix(string(b), key+"="), nil
+}
+
+// memContainerOperations stores the metadata it is written, and returns it
+// when it is read.
+type memContainerOperations struct {
+	stubContainerOperations
+	md azblob.Metadata
+}
+
+func (m *memContainerOperations) Create(_ context.Context, _ azblob.PublicAccessType, md azblob.Metadata) error {
+	m.md = md
+	return nil
+}
+
+func (m *memContainerOperations) Update(_ context.Context, _ azblob.PublicAccessType, md azblob.Metadata) error {
+	m.md = md
+	return nil
+}
+
+func (m *memContainerOperations) UpdatePartial(_ context.Context, _ *azblob.PublicAccessType, md *azblob.Metadata) UpdateResult {
+	if md != nil {
+		m.md = *md
+	}
+	return UpdateResult{MetadataApplied: md != nil}
+}
+
+func (m *memContainerOperations) Get(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+	a := azblob.PublicAccessNone
+	return &a, m.md, nil
+}
+
+func (m *memContainerOperations) GetContainerProperties(context.Context) (*ContainerProperties, error) {
+	return &ContainerProperties{Metadata: m.md}, nil
+}
+
+func (m *memContainerOperations) Import(context.Context) (ContainerSnapshot, error) {
+	return ContainerSnapshot{ContainerProperties: ContainerProperties{Metadata: m.md}}, nil
+}
+
+func TestMetadataEncryptingRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	plain := azblob.Metadata{"Secret-Token": "hunter2", "owner": "crossplane"}
+
+	mem := &memContainerOperations{}
+	o := NewMetadataEncryptingContainerOperations(mem, testCipher{}, "secret-")
+	if err := o.Create(ctx, azblob.PublicAccessNone, plain); err != nil {
+		t.Fatalf("Create(...): %v", err)
+	}
+
+	if mem.md["owner"] != "crossplane" {
+		t.Errorf("Create(...): want insensitive value stored in plaintext, got %q", mem.md["owner"])
+	}
+	if v := mem.md["Secret-Token"]; v == "hunter2" || !strings.HasPrefix(v, "enc:") {
+		t.Errorf("Create(...): want sensitive value stored encrypted, got %q", v)
+	}
+
+	_, got, err := o.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get(...): %v", err)
+	}
+	if diff := cmp.Diff(plain, got); diff != "" {
+		t.Errorf("Get(...): -want, +got:\n%s", diff)
+	}
+	p, err := o.GetContainerProperties(ctx)
+	if err != nil {
+		t.Fatalf("GetContainerProperties(...): %v", err)
+	}
+	if diff := cmp.Diff(plain, p.Metadata); diff != "" {
+		t.Errorf("GetContainerProperties(...): -want, +got:\n%s", diff)
+	}
+	s, err := o.Import(ctx)
+	if err != nil {
+		t.Fatalf("Import(...): %v", err)
+	}
+	if diff := cmp.Diff(plain, s.Metadata); diff != "" {
+		t.Errorf("Import(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestMetadataEncryptingDecryptFailed(t *testing.T) {
+	mem := &memContainerOperations{md: azblob.Metadata{"secret-token": "hunter2"}}
+	o := NewMetadataEncryptingContainerOperations(mem, testCipher{}, "secret-")
+	if _, _, err := o.Get(context.Background()); err == nil {
+		t.Error("Get(...): want error decrypting a plaintext sensitive value, got nil")
+	}
+}
+
+func TestNewMetadataEncryptingContainerOperations(t *testing.T) {
+	o := stubContainerOperations{}
+	if got := NewMetadataEncryptingContainerOperations(o, nil, "secret-"); got != ContainerOperations(o) {
+		t.Errorf("NewMetadataEncryptingContainerOperations(...): want the undecorated operations without a cipher, got %T", got)
+	}
+}
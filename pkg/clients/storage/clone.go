@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"path"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+// CopyAccessPolicyFrom replaces the container's public access type and signed
+// identifiers with those of the supplied source container. The container's
+// metadata is left unchanged.
+func (a *ContainerHandle) CopyAccessPolicyFrom(ctx context.Context, source *ContainerHandle) error {
+	name := path.Base(source.URL().Path)
+	p, err := source.GetAccessPolicy(ctx, azblob.LeaseAccessConditions{})
+	if IsNotFoundError(err) {
+		return errors.Errorf("cannot copy access policy: source container %s does not exist", name)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot get access policy of source container %s", name)
+	}
+	_, err = a.SetAccessPolicy(ctx, p.BlobPublicAccess(), p.Items, azblob.ContainerAccessConditions{})
+	return errors.Wrap(err, "cannot set access policy")
+}
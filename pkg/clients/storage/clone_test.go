@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCopyAccessPolicyFrom(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiry := start.Add(24 * time.Hour)
+	identifiers := []azblob.SignedIdentifier{{
+		ID:           "read-only",
+		AccessPolicy: azblob.AccessPolicy{Start: start, Expiry: expiry, Permission: "r"},
+	}}
+
+	type want struct {
+		err         bool
+		access      string
+		identifiers []azblob.SignedIdentifier
+		reqs        []string
+	}
+	cases := map[string]struct {
+		reason       string
+		sourceStatus int
+		want         want
+	}{
+		"Copied": {
+			reason:       "The source's public access type and signed identifiers should be applied to the target.",
+			sourceStatus: http.StatusOK,
+			want: want{
+				access:      "blob",
+				identifiers: identifiers,
+				reqs:        []string{"PUT /testcontainer?comp=acl"},
+			},
+		},
+		"SourceNotFound": {
+			reason:       "A clear error should be returned if the source does not exist.",
+			sourceStatus: http.StatusNotFound,
+			want:         want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			source := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.sourceStatus != http.StatusOK {
+					w.WriteHeader(tc.sourceStatus)
+					return
+				}
+				w.Header().Set(headerBlobPublicAccess, "blob")
+				b, _ := xml.Marshal(struct {
+					XMLName xml.Name                  `xml:"SignedIdentifiers"`
+					Items   []azblob.SignedIdentifier `xml:"SignedIdentifier"`
+				}{Items: identifiers})
+				_, _ = w.Write(b)
+			}))
+
+			var reqs []string
+			var access string
+			var got []azblob.SignedIdentifier
+			target := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reqs = append(reqs, r.Method+" "+r.URL.Path+"?comp="+r.URL.Query().Get("comp"))
+				access = r.Header.Get(headerBlobPublicAccess)
+				b, _ := ioutil.ReadAll(r.Body)
+				body := struct {
+					Items []azblob.SignedIdentifier `xml:"SignedIdentifier"`
+				}{}
+				if err := xml.Unmarshal(b, &body); err != nil {
+					t.Errorf("xml.Unmarshal(...): %v", err)
+				}
+				got = body.Items
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			err := target.CopyAccessPolicyFrom(context.Background(), source)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nCopyAccessPolicyFrom(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if access != tc.want.access {
+				t.Errorf("\n%s\nCopyAccessPolicyFrom(...): want public access %q, got %q", tc.reason, tc.want.access, access)
+			}
+			if diff := cmp.Diff(tc.want.identifiers, got); diff != "" {
+				t.Errorf("\n%s\nCopyAccessPolicyFrom(...): -want signed identifiers, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reqs, reqs); diff != "" {
+				t.Errorf("\n%s\nCopyAccessPolicyFrom(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
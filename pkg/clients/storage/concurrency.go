@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+)
+
+// A semaphore admits a bounded number of concurrent requests. A nil
+// semaphore admits any number.
+type semaphore chan struct{}
+
+// acquire blocks until the semaphore admits a request, or until the supplied
+// context is done.
+func (s semaphore) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a request admitted by acquire.
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// accountSemaphores limit the concurrent requests made against each storage
+// account, across every handle of it.
+var accountSemaphores = &semaphores{}
+
+type semaphores struct {
+	mu sync.Mutex
+	s  map[string]semaphore
+}
+
+// get returns the semaphore that limits the named storage account to the
+// supplied number of concurrent requests, or nil if the limit is not
+// positive. A semaphore with a different limit is replaced, so that a
+// changed limit takes effect; requests it admitted still release it.
+func (s *semaphores) get(account string, limit int) semaphore {
+	if limit <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sem, ok := s.s[account]; ok && cap(sem) == limit {
+		return sem
+	}
+	if s.s == nil {
+		s.s = map[string]semaphore{}
+	}
+	s.s[account] = make(semaphore, limit)
+	return s.s[account]
+}
+
+// newConcurrencyPolicyFactory returns a factory of policies that hold a slot
+// of the supplied semaphore while each try is on the wire.
+func newConcurrencyPolicyFactory(s semaphore) pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, _ *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, req pipeline.Request) (pipeline.Response, error) {
+			if err := s.acquire(ctx); err != nil {
+				return nil, err
+			}
+			defer s.release()
+			return next.Do(ctx, req)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// newLimitedTestContainerHandle returns a ContainerHandle of the named
+// storage account whose requests are limited to the supplied concurrency and
+// served by the supplied server rather than Azure.
+func newLimitedTestContainerHandle(t *testing.T, srv *httptest.Server, account string, limit int) *ContainerHandle {
+	t.Helper()
+	c, err := azblob.NewSharedKeyCredential(account, testKey)
+	if err != nil {
+		t.Fatalf("azblob.NewSharedKeyCredential(...): %v", err)
+	}
+	p := newPipeline(c, azblob.PipelineOptions{Retry: azblob.RetryOptions{MaxTries: 1}}, accountSemaphores.get(account, limit), "")
+	u, _ := url.Parse(srv.URL + "/" + testContainer)
+	return &ContainerHandle{ContainerURL: azblob.NewContainerURL(*u, p), pipeline: p}
+}
+
+// gauge tracks how many requests a fake blob endpoint is serving.
+type gauge struct {
+	mu       sync.Mutex
+	inflight int
+	max      int
+}
+
+func (g *gauge) add(d int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.inflight += d
+	if g.inflight > g.max {
+		g.max = g.inflight
+	}
+}
+
+func (g *gauge) get() (inflight, max int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.inflight, g.max
+}
+
+func TestAccountConcurrencyLimit(t *testing.T) {
+	const limit = 2
+
+	release := make(chan struct{})
+	busy := &gauge{}
+	srvA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		busy.add(1)
+		defer busy.add(-1)
+		<-release
+	}))
+	t.Cleanup(srvA.Close)
+	srvB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srvB.Close)
+
+	// Two handles of the same account share its limit.
+	handles := []*ContainerHandle{
+		newLimitedTestContainerHandle(t, srvA, "limiteda", limit),
+		newLimitedTestContainerHandle(t, srvA, "limiteda", limit),
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		for _, h := range handles {
+			wg.Add(1)
+			go func(h *ContainerHandle) {
+				defer wg.Done()
+				_, _ = h.GetProperties(context.Background(), azblob.LeaseAccessConditions{})
+			}(h)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for inflight, _ := busy.get(); inflight < limit; inflight, _ = busy.get() {
+		if time.Now().After(deadline) {
+			t.Fatalf("GetProperties(...): want %d concurrent requests, got %d", limit, inflight)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A request against the saturated account waits until its context is
+	// done.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := handles[0].GetProperties(ctx, azblob.LeaseAccessConditions{}); err == nil {
+		t.Errorf("GetProperties(...): want an error waiting for a saturated account, got nil")
+	}
+
+	// Another account proceeds independently.
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	other := newLimitedTestContainerHandle(t, srvB, "limitedb", limit)
+	if _, err := other.GetProperties(ctx, azblob.LeaseAccessConditions{}); err != nil {
+		t.Errorf("GetProperties(...): want requests against another account to proceed, got %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	if _, max := busy.get(); max != limit {
+		t.Errorf("GetProperties(...): want at most %d concurrent requests against the account, got %d", limit, max)
+	}
+}
+
+func TestAccountSemaphores(t *testing.T) {
+	s := &semaphores{}
+	if got := s.get(testAccount, 0); got != nil {
+		t.Errorf("semaphores.get(...): want no semaphore without a limit, got one of %d", cap(got))
+	}
+	a := s.get(testAccount, 2)
+	if again := s.get(testAccount, 2); again != a {
+		t.Errorf("semaphores.get(...): want the same semaphore for the same account and limit")
+	}
+	if changed := s.get(testAccount, 3); changed == a || cap(changed) != 3 {
+		t.Errorf("semaphores.get(...): want a new semaphore when the limit changes")
+	}
+}
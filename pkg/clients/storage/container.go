@@ -19,10 +19,18 @@ package storage
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/Azure/azure-pipeline-go/pipeline"
 	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	azure "github.com/crossplane-contrib/provider-azure/pkg/clients"
 )
@@ -31,57 +39,514 @@ import (
 type ContainerOperations interface {
 	Create(ctx context.Context, publicAccessType azblob.PublicAccessType, metadata azblob.Metadata) error
 	Update(ctx context.Context, publicAccessType azblob.PublicAccessType, metadata azblob.Metadata) error
+	UpdatePartial(ctx context.Context, publicAccessType *azblob.PublicAccessType, metadata *azblob.Metadata) UpdateResult
 	Get(ctx context.Context) (*azblob.PublicAccessType, azblob.Metadata, error)
+	GetContainerProperties(ctx context.Context) (*ContainerProperties, error)
+	Exists(ctx context.Context) (bool, error)
+	WaitUntilExists(ctx context.Context, timeout time.Duration) error
+	Import(ctx context.Context) (ContainerSnapshot, error)
+	ListSnapshots(ctx context.Context, prefix string) ([]Snapshot, error)
+	DeleteBlobsWithSnapshots(ctx context.Context, snapshots []Snapshot) error
+	ArchiveBlobs(ctx context.Context, archive string) error
+	GetStoredAccessPolicies(ctx context.Context) ([]azblob.SignedIdentifier, error)
+	SetStoredAccessPolicies(ctx context.Context, publicAccessType azblob.PublicAccessType, policies []azblob.SignedIdentifier) error
 	Delete(ctx context.Context) error
+	DeleteWithVersions(ctx context.Context) error
+}
+
+// ContainerProperties are the observed properties of a container.
+type ContainerProperties struct {
+	PublicAccessType azblob.PublicAccessType
+	Metadata         azblob.Metadata
+	ETag             azblob.ETag
+	LastModified     time.Time
+
+	// DefaultEncryptionScope is the encryption scope applied to blobs written
+	// to the container. It is empty when the service does not report it.
+	DefaultEncryptionScope string
+
+	// PreventEncryptionScopeOverride is true when blobs may not be written
+	// with an encryption scope other than the default one.
+	PreventEncryptionScopeOverride bool
+}
+
+// An UpdateResult reports which parts of a container an update applied, and
+// why it stopped short of applying the rest.
+type UpdateResult struct {
+	MetadataApplied     bool
+	AccessPolicyApplied bool
+	Err                 error
+}
+
+// Partial returns true if the update failed after applying some parts.
+func (r UpdateResult) Partial() bool {
+	return r.Err != nil && (r.MetadataApplied || r.AccessPolicyApplied)
 }
 
 // ContainerHandle implements ContainerOperations
 type ContainerHandle struct {
 	azblob.ContainerURL
 	PublicAccessType azblob.PublicAccessType
+
+	// DeleteSnapshots causes DeleteWithVersions to list blob snapshots, and
+	// delete them along with their blobs.
+	DeleteSnapshots bool
+
+	// DefaultEncryptionScope and PreventEncryptionScopeOverride are applied
+	// when the container is created. Neither can be changed through the blob
+	// service once the container exists.
+	DefaultEncryptionScope         string
+	PreventEncryptionScopeOverride bool
+
+	// StoredAccessPolicies are written along with the public access type.
+	// The blob service sets the two together, so writing the public access
+	// type replaces the container's stored access policies with these, and
+	// removes them all when there are none.
+	StoredAccessPolicies []azblob.SignedIdentifier
+
+	pipeline pipeline.Pipeline
+	retry    azblob.RetryOptions
+
+	// reader reads the container when it was created with a separate read
+	// credential. The handle itself is used to change the container.
+	reader *ContainerHandle
+
+	// secondary reads the container through the storage account's secondary
+	// endpoint, for the operations endpoints routes there.
+	secondary *ContainerHandle
+	endpoints EndpointPolicy
+
+	// fallback reads the container through the fallback endpoint when a
+	// read fails with a network error.
+	fallback *ContainerHandle
+}
+
+// ContainerHandleOptions configure a ContainerHandle.
+type ContainerHandleOptions struct {
+	// Retry configures how failed requests are retried. Zero values are
+	// replaced by the azblob SDK's defaults.
+	Retry azblob.RetryOptions
+
+	// MaxConcurrentRequests limits the number of requests that may be made
+	// concurrently against the storage account, by every handle of it that
+	// was created with the same limit. Requests wait, until their context is
+	// done, for earlier ones to finish. Requests are not limited when it is
+	// zero.
+	MaxConcurrentRequests int
+
+	// APIVersion pins the blob service API version requests are made with,
+	// for clouds that lag the versions the azblob SDK targets. Requests that
+	// need features of a newer version, like encryption scopes, are still
+	// made with that version when it is older. Requests are made with the
+	// azblob SDK's version, or the newer version those features need, when
+	// it is empty.
+	APIVersion string
+
+	// Endpoints routes the requests of operations that read the container to
+	// the storage account's primary or secondary endpoint, for example to
+	// list blobs from the secondary endpoint while getting the container
+	// from the primary one. Operations that write the container cannot be
+	// routed to the secondary endpoint. Every operation is made against the
+	// primary endpoint when it is empty.
+	Endpoints EndpointPolicy
+
+	// FallbackEndpoint is the URL of a blob service endpoint of the storage
+	// account, such as its secondary endpoint, that operations that read the
+	// container are made against again when they fail with a network error,
+	// for example because the primary endpoint does not resolve during a
+	// regional outage. Operations that write the container never fall back.
+	// Reads do not fall back when it is empty.
+	FallbackEndpoint string
+
+	// EndpointSuffix is the DNS suffix of the storage account's blob service
+	// endpoints, such as core.chinacloudapi.cn for accounts in a sovereign
+	// cloud. The public cloud's suffix is used when it is empty.
+	EndpointSuffix string
 }
 
 var _ ContainerOperations = &ContainerHandle{}
 
-const blobFormatString = `https://%s.blob.core.windows.net`
+const (
+	blobFormatString = `https://%s.blob.%s`
+
+	// DefaultEndpointSuffix is the DNS suffix of the blob service endpoints
+	// of storage accounts in the public cloud.
+	DefaultEndpointSuffix = "core.windows.net"
+
+	headerMetaPrefix = "x-ms-meta-"
+
+	// statusConcurrency bounds how many containers RefreshStatus gets at
+	// once.
+	statusConcurrency = 8
+
+	// createConcurrency bounds how many containers CreateMany creates at
+	// once.
+	createConcurrency = 8
+)
 
 // NewContainerHandle creates a new instance of ContainerHandle for given storage account and given container name
 func NewContainerHandle(accountName, accountKey, containerName string) (*ContainerHandle, error) {
+	return NewContainerHandleWithOptions(accountName, accountKey, containerName, ContainerHandleOptions{})
+}
+
+// NewContainerHandleWithOptions creates a new instance of ContainerHandle for
+// given storage account and given container name, configured by the supplied
+// options.
+func NewContainerHandleWithOptions(accountName, accountKey, containerName string, o ContainerHandleOptions) (*ContainerHandle, error) {
+	s, err := NewServiceHandle(accountName, accountKey, o)
+	if err != nil {
+		return nil, err
+	}
+	return s.Container(containerName), nil
+}
+
+// NewContainerHandleWithCredentials creates a new instance of ContainerHandle
+// for given storage account and given container name that reads the container
+// with the supplied read credential and creates, updates, and deletes it with
+// the supplied write credential, configured by the supplied options. Reads
+// such as Get and ListBlobs then keep working, and can do no harm, when the
+// read credential grants only read access.
+func NewContainerHandleWithCredentials(accountName, containerName string, read, write azblob.Credential, o ContainerHandleOptions) (*ContainerHandle, error) {
+	s, err := NewServiceHandleWithCredentials(accountName, read, write, o)
+	if err != nil {
+		return nil, err
+	}
+	return s.Container(containerName), nil
+}
+
+// A ServiceHandle vends ContainerHandles for the containers of a storage
+// account. Every ContainerHandle it vends shares its credential and pipeline,
+// and thus its connection pool.
+type ServiceHandle struct {
+	azblob.ServiceURL
+
+	pipeline pipeline.Pipeline
+	retry    azblob.RetryOptions
+
+	// reader vends the handles that read containers when the service was
+	// created with a separate read credential.
+	reader *ServiceHandle
+
+	// secondary vends the handles that read containers through the secondary
+	// endpoint when endpoints routes any operation there.
+	secondary *ServiceHandle
+	endpoints EndpointPolicy
+
+	// fallback vends the handles that read containers through the fallback
+	// endpoint, if there is one.
+	fallback *ServiceHandle
+}
+
+// NewServiceHandle creates a new instance of ServiceHandle for the given
+// storage account, configured by the supplied options.
+func NewServiceHandle(accountName, accountKey string, o ContainerHandleOptions) (*ServiceHandle, error) {
 	c, err := azblob.NewSharedKeyCredential(accountName, accountKey)
 	if err != nil {
 		return nil, err
 	}
+	return NewServiceHandleWithCredential(accountName, c, o)
+}
 
-	p := azblob.NewPipeline(c, azblob.PipelineOptions{
+// NewServiceHandleWithCredential creates a new instance of ServiceHandle for
+// the given storage account that authorizes requests with the supplied
+// credential, configured by the supplied options.
+func NewServiceHandleWithCredential(accountName string, c azblob.Credential, o ContainerHandleOptions) (*ServiceHandle, error) {
+	if o.APIVersion != "" {
+		if err := ValidateAPIVersion(o.APIVersion); err != nil {
+			return nil, err
+		}
+	}
+	if err := ValidateEndpointPolicy(o.Endpoints); err != nil {
+		return nil, err
+	}
+	var fu *url.URL
+	if o.FallbackEndpoint != "" {
+		u, err := url.Parse(o.FallbackEndpoint)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, errors.Errorf("invalid fallback endpoint %q: must be an http or https URL", o.FallbackEndpoint)
+		}
+		fu = u
+	}
+	p := newPipeline(c, azblob.PipelineOptions{
+		Retry:     o.Retry,
 		Telemetry: azblob.TelemetryOptions{Value: azure.UserAgent},
-	})
+	}, accountSemaphores.get(accountName, o.MaxConcurrentRequests), o.APIVersion)
 
-	u, _ := url.Parse(fmt.Sprintf(blobFormatString, accountName))
-	service := azblob.NewServiceURL(*u, p)
+	suffix := o.EndpointSuffix
+	if suffix == "" {
+		suffix = DefaultEndpointSuffix
+	}
+	u, _ := url.Parse(fmt.Sprintf(blobFormatString, accountName, suffix))
+	s := &ServiceHandle{
+		ServiceURL: azblob.NewServiceURL(*u, p),
+		pipeline:   p,
+		retry:      effectiveRetryOptions(o.Retry),
+		endpoints:  o.Endpoints,
+	}
+	if o.Endpoints.routesSecondary() {
+		su, _ := url.Parse(fmt.Sprintf(secondaryBlobFormatString, accountName, suffix))
+		s.secondary = &ServiceHandle{
+			ServiceURL: azblob.NewServiceURL(*su, p),
+			pipeline:   p,
+			retry:      s.retry,
+		}
+	}
+	if fu != nil {
+		s.fallback = &ServiceHandle{
+			ServiceURL: azblob.NewServiceURL(*fu, p),
+			pipeline:   p,
+			retry:      s.retry,
+		}
+	}
+	return s, nil
+}
 
-	return &ContainerHandle{
-		ContainerURL: service.NewContainerURL(containerName),
-	}, nil
+// NewServiceHandleWithSAS creates a new instance of ServiceHandle for the
+// given storage account that authorizes requests with the supplied shared
+// access signature, configured by the supplied options. The signature may
+// start with a '?', as the Azure portal shows it.
+func NewServiceHandleWithSAS(accountName, sas string, o ContainerHandleOptions) (*ServiceHandle, error) {
+	q, err := url.ParseQuery(strings.TrimPrefix(sas, "?"))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse shared access signature")
+	}
+	if q.Get("sig") == "" {
+		return nil, errors.New("shared access signature is not signed")
+	}
+	s, err := NewServiceHandleWithCredential(accountName, azblob.NewAnonymousCredential(), o)
+	if err != nil {
+		return nil, err
+	}
+	s.ServiceURL = s.withQuery(q)
+	if s.secondary != nil {
+		s.secondary.ServiceURL = s.secondary.withQuery(q)
+	}
+	if s.fallback != nil {
+		s.fallback.ServiceURL = s.fallback.withQuery(q)
+	}
+	return s, nil
+}
+
+// withQuery returns the URL of the service with the supplied query, which
+// every container and blob URL derived from it keeps.
+func (s *ServiceHandle) withQuery(q url.Values) azblob.ServiceURL {
+	u := s.URL()
+	u.RawQuery = q.Encode()
+	return azblob.NewServiceURL(u, s.pipeline)
+}
+
+// NewServiceHandleWithCredentials creates a new instance of ServiceHandle
+// for the given storage account whose ContainerHandles read containers with
+// the supplied read credential and change them with the supplied write
+// credential, configured by the supplied options.
+func NewServiceHandleWithCredentials(accountName string, read, write azblob.Credential, o ContainerHandleOptions) (*ServiceHandle, error) {
+	r, err := NewServiceHandleWithCredential(accountName, read, o)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewServiceHandleWithCredential(accountName, write, o)
+	if err != nil {
+		return nil, err
+	}
+	s.reader = r
+	return s, nil
+}
+
+// Container returns a ContainerHandle for the named container of the storage
+// account.
+func (s *ServiceHandle) Container(name string) *ContainerHandle {
+	h := &ContainerHandle{
+		ContainerURL: s.NewContainerURL(name),
+		pipeline:     s.pipeline,
+		retry:        s.retry,
+	}
+	if s.reader != nil {
+		h.reader = s.reader.Container(name)
+	}
+	if s.secondary != nil {
+		h.secondary = s.secondary.Container(name)
+		h.endpoints = s.endpoints
+	}
+	if s.fallback != nil {
+		h.fallback = s.fallback.Container(name)
+	}
+	return h
+}
+
+// reads returns the handle that makes the supplied read operation, which is
+// the handle itself unless it was created with a separate read credential or
+// its endpoint policy routes the operation to the secondary endpoint.
+func (a *ContainerHandle) reads(op Operation) *ContainerHandle {
+	r := a
+	if a.reader != nil {
+		r = a.reader
+	}
+	if r.secondary != nil && r.endpoints[op] == EndpointSecondary {
+		return r.secondary
+	}
+	return r
+}
+
+// read makes the supplied read operation with the handle that makes it, and
+// again with the handle that reads through the fallback endpoint, if any, if
+// that fails with a network error.
+func (a *ContainerHandle) read(op Operation, fn func(r *ContainerHandle) error) error {
+	err := fn(a.reads(op))
+	fb := a.fallback
+	if a.reader != nil {
+		fb = a.reader.fallback
+	}
+	if err == nil || fb == nil || ClassifyStorageError(err) != ErrorClassNetwork {
+		return err
+	}
+	return fn(fb)
+}
+
+// RetryOptions returns the retry options in effect for requests made by the
+// handle, including any defaults applied by the azblob SDK.
+func (a *ContainerHandle) RetryOptions() azblob.RetryOptions {
+	return a.retry
+}
+
+// effectiveRetryOptions returns the supplied retry options with zero values
+// replaced by the defaults the azblob SDK applies when building its retry
+// policy. The SDK does not export them, so they are mirrored here.
+func effectiveRetryOptions(o azblob.RetryOptions) azblob.RetryOptions {
+	if o.MaxTries == 0 {
+		o.MaxTries = 4
+	}
+	if o.TryTimeout == 0 {
+		o.TryTimeout = 1 * time.Minute
+	}
+	if o.RetryDelay == 0 {
+		o.RetryDelay = 4 * time.Second
+		if o.Policy == azblob.RetryPolicyFixed {
+			o.RetryDelay = 30 * time.Second
+		}
+	}
+	if o.MaxRetryDelay == 0 {
+		o.MaxRetryDelay = 120 * time.Second
+	}
+	return o
 }
 
 // Create container resource
 func (a *ContainerHandle) Create(ctx context.Context, publicAccessType azblob.PublicAccessType, metadata azblob.Metadata) error {
-	_, err := a.ContainerURL.Create(ctx, azblob.Metadata{}, publicAccessType)
+	if a.DefaultEncryptionScope == "" {
+		if metadata == nil {
+			metadata = azblob.Metadata{}
+		}
+		_, err := a.ContainerURL.Create(ctx, metadata, publicAccessType)
+		return err
+	}
+
+	u := a.URL()
+	q := u.Query()
+	q.Set("restype", "container")
+	u.RawQuery = q.Encode()
+
+	h := http.Header{}
+	h.Set(headerDefaultEncryptionScope, a.DefaultEncryptionScope)
+	h.Set(headerDenyEncryptionScopeOverride, strconv.FormatBool(a.PreventEncryptionScopeOverride))
+	if publicAccessType != azblob.PublicAccessNone {
+		h.Set(headerBlobPublicAccess, string(publicAccessType))
+	}
+	for k, v := range metadata {
+		h.Set(headerMetaPrefix+k, v)
+	}
+	_, _, err := a.send(ctx, http.MethodPut, u, h, http.StatusCreated)
 	return err
 }
 
 // Update container resource
 func (a *ContainerHandle) Update(ctx context.Context, publicAccessType azblob.PublicAccessType, metadata azblob.Metadata) error {
-	if _, err := a.ContainerURL.SetMetadata(ctx, metadata, azblob.ContainerAccessConditions{}); err != nil {
+	return a.UpdatePartial(ctx, &publicAccessType, &metadata).Err
+}
+
+// UpdatePartial updates the container's metadata and then its public access
+// policy, skipping either that is nil, and reports which were applied. The
+// two are separate writes, so the metadata may be applied even though the
+// access policy is not; only the access policy then needs to be retried. The
+// handle's stored access policies are written with the public access type.
+func (a *ContainerHandle) UpdatePartial(ctx context.Context, publicAccessType *azblob.PublicAccessType, metadata *azblob.Metadata) UpdateResult {
+	r := UpdateResult{}
+	if metadata != nil {
+		if _, r.Err = a.ContainerURL.SetMetadata(ctx, *metadata, azblob.ContainerAccessConditions{}); r.Err != nil {
+			return r
+		}
+		r.MetadataApplied = true
+	}
+	if publicAccessType != nil {
+		if r.Err = a.setAccessPolicy(ctx, *publicAccessType, r.MetadataApplied); r.Err != nil {
+			return r
+		}
+		r.AccessPolicyApplied = true
+	}
+	return r
+}
+
+// accessPolicySettle is how long an access policy write that conflicts with a
+// metadata write made just before it waits to be retried. The wait doubles
+// with each of accessPolicyRetries retries.
+var (
+	accessPolicySettle  = 200 * time.Millisecond
+	accessPolicyRetries = 2
+)
+
+// setAccessPolicy sets the container's public access policy, along with the
+// handle's stored access policies. The blob service sometimes rejects the
+// write with a transient conflict when the container's metadata was written
+// immediately before it, so in that case a conflicting write is retried after
+// letting the metadata write settle. Waiting stops when the supplied context
+// is done.
+func (a *ContainerHandle) setAccessPolicy(ctx context.Context, t azblob.PublicAccessType, afterMetadata bool) error {
+	settle := accessPolicySettle
+	for i := 0; ; i++ {
+		_, err := a.ContainerURL.SetAccessPolicy(ctx, t, a.StoredAccessPolicies, azblob.ContainerAccessConditions{})
+		if err == nil || !afterMetadata || i == accessPolicyRetries || StatusCode(err) != http.StatusConflict {
+			return err
+		}
+		tm := time.NewTimer(settle)
+		select {
+		case <-tm.C:
+		case <-ctx.Done():
+			tm.Stop()
+			return errors.Wrap(ctx.Err(), "cannot wait for metadata update to settle")
+		}
+		settle *= 2
+	}
+}
+
+// MergeMetadata merges the supplied metadata into the container's existing
+// metadata, rather than replacing it as Update does, and removes the named
+// keys. Keys are compared case-insensitively, and a key that is both supplied
+// and removed is removed. The blob service cannot set container metadata
+// conditionally, so metadata written by others between reading and writing it
+// is overwritten.
+func (a *ContainerHandle) MergeMetadata(ctx context.Context, metadata azblob.Metadata, remove []string) error {
+	rs, err := a.ContainerURL.GetProperties(ctx, azblob.LeaseAccessConditions{})
+	if err != nil {
 		return err
 	}
-	_, err := a.ContainerURL.SetAccessPolicy(ctx, publicAccessType, nil, azblob.ContainerAccessConditions{})
+	md := rs.NewMetadata()
+	for k, v := range metadata {
+		md[strings.ToLower(k)] = v
+	}
+	for _, k := range remove {
+		delete(md, strings.ToLower(k))
+	}
+	_, err = a.ContainerURL.SetMetadata(ctx, md, azblob.ContainerAccessConditions{})
 	return err
 }
 
 // Get resource information
 func (a *ContainerHandle) Get(ctx context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
-	rs, err := a.ContainerURL.GetProperties(ctx, azblob.LeaseAccessConditions{})
+	var rs *azblob.ContainerGetPropertiesResponse
+	err := a.read(OperationGet, func(r *ContainerHandle) error {
+		var err error
+		rs, err = r.ContainerURL.GetProperties(ctx, azblob.LeaseAccessConditions{})
+		return err
+	})
 	if err != nil {
 		return nil, nil, err
 	}
@@ -89,6 +554,166 @@ func (a *ContainerHandle) Get(ctx context.Context) (*azblob.PublicAccessType, az
 	return &publicAccess, emtpyMetaToNil(rs.NewMetadata()), nil
 }
 
+// A StatusResult is the result of getting a container's status.
+type StatusResult struct {
+	PublicAccessType *azblob.PublicAccessType
+	Metadata         azblob.Metadata
+	Err              error
+}
+
+// RefreshStatus gets each supplied container, a few containers at a time. It
+// returns the result of getting each container at the same index as its
+// handle. A container that does not exist has a not found error. Containers
+// that were not yet got when the supplied context was cancelled have the
+// context's error.
+func RefreshStatus(ctx context.Context, handles []*ContainerHandle) []StatusResult {
+	results := make([]StatusResult, len(handles))
+
+	wg := &sync.WaitGroup{}
+	sem := make(semaphore, statusConcurrency)
+	for i, h := range handles {
+		if ctx.Err() != nil {
+			results[i].Err = ctx.Err()
+			continue
+		}
+		if err := sem.acquire(ctx); err != nil {
+			results[i].Err = err
+			continue
+		}
+		wg.Add(1)
+		go func(i int, h *ContainerHandle) {
+			defer func() { sem.release(); wg.Done() }()
+			r := &results[i]
+			r.PublicAccessType, r.Metadata, r.Err = h.Get(ctx)
+		}(i, h)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// A ContainerSpec specifies a container for CreateMany to create.
+type ContainerSpec struct {
+	Name             string
+	PublicAccessType azblob.PublicAccessType
+	Metadata         azblob.Metadata
+}
+
+// CreateMany creates each supplied container of the storage account, a few
+// containers at a time. Containers that already exist are left as they are,
+// and count as created. It returns the error creating each container that
+// could not be created, keyed by its name, and so returns an empty map when
+// every container was created. Containers that were not yet created when the
+// supplied context was cancelled have the context's error. Requests are
+// further bounded by the handle's MaxConcurrentRequests option, if any.
+func (s *ServiceHandle) CreateMany(ctx context.Context, specs []ContainerSpec) map[string]error {
+	errs := map[string]error{}
+	mu := &sync.Mutex{}
+	fail := func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs[name] = err
+	}
+
+	wg := &sync.WaitGroup{}
+	sem := make(semaphore, createConcurrency)
+	for _, spec := range specs {
+		if ctx.Err() != nil {
+			fail(spec.Name, ctx.Err())
+			continue
+		}
+		if err := sem.acquire(ctx); err != nil {
+			fail(spec.Name, err)
+			continue
+		}
+		wg.Add(1)
+		go func(spec ContainerSpec) {
+			defer func() { sem.release(); wg.Done() }()
+			_, err := s.NewContainerURL(spec.Name).Create(ctx, spec.Metadata, spec.PublicAccessType)
+			if err != nil && !IsContainerAlreadyExists(err) {
+				fail(spec.Name, errors.Wrapf(err, "cannot create container %s", spec.Name))
+			}
+		}(spec)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// GetContainerProperties returns the container's properties, including the
+// ones the azblob SDK version we use does not expose.
+func (a *ContainerHandle) GetContainerProperties(ctx context.Context) (*ContainerProperties, error) {
+	var h http.Header
+	err := a.read(OperationGetContainerProperties, func(r *ContainerHandle) error {
+		u := r.URL()
+		q := u.Query()
+		q.Set("restype", "container")
+		u.RawQuery = q.Encode()
+
+		var err error
+		h, _, err = r.send(ctx, http.MethodGet, u, nil, http.StatusOK)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	md := azblob.Metadata{}
+	for k, v := range h {
+		if len(k) > len(headerMetaPrefix) && strings.EqualFold(k[:len(headerMetaPrefix)], headerMetaPrefix) {
+			md[strings.ToLower(k[len(headerMetaPrefix):])] = v[0]
+		}
+	}
+	lm, _ := time.Parse(time.RFC1123, h.Get("Last-Modified"))
+
+	return &ContainerProperties{
+		PublicAccessType:               azblob.PublicAccessType(h.Get(headerBlobPublicAccess)),
+		Metadata:                       emtpyMetaToNil(md),
+		ETag:                           azblob.ETag(h.Get("ETag")),
+		LastModified:                   lm,
+		DefaultEncryptionScope:         h.Get(headerDefaultEncryptionScope),
+		PreventEncryptionScopeOverride: strings.EqualFold(h.Get(headerDenyEncryptionScopeOverride), "true"),
+	}, nil
+}
+
+// Exists returns true if the container exists.
+func (a *ContainerHandle) Exists(ctx context.Context) (bool, error) {
+	err := a.read(OperationExists, func(r *ContainerHandle) error {
+		_, err := r.ContainerURL.GetProperties(ctx, azblob.LeaseAccessConditions{})
+		return err
+	})
+	if IsNotFoundError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// existsBackoff paces WaitUntilExists. Its steps are bounded by the timeout
+// rather than counted.
+var existsBackoff = wait.Backoff{Duration: 100 * time.Millisecond, Factor: 2, Cap: 2 * time.Second, Steps: math.MaxInt32}
+
+// WaitUntilExists polls until the container exists, which may be a short time
+// after it was created. It returns an error if the container does not exist
+// within the supplied timeout, or the supplied context is cancelled.
+func (a *ContainerHandle) WaitUntilExists(ctx context.Context, timeout time.Duration) error {
+	// The timeout bounds polling, not each poll. The azblob pipeline cannot
+	// send requests with less than a second until their context's deadline.
+	wctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := wait.ExponentialBackoffWithContext(wctx, existsBackoff, func() (bool, error) {
+		return a.Exists(ctx)
+	})
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case wctx.Err() != nil, errors.Is(err, wait.ErrWaitTimeout):
+		return errors.Errorf("container does not exist after %s", timeout)
+	}
+	return err
+}
+
 // Delete deletes the named container.
 func (a *ContainerHandle) Delete(ctx context.Context) error {
 	_, err := a.ContainerURL.Delete(ctx, azblob.ContainerAccessConditions{})
@@ -104,10 +729,6 @@ func emtpyMetaToNil(m azblob.Metadata) azblob.Metadata {
 
 // IsNotFoundError tests for azblob not found error
 func IsNotFoundError(err error) bool {
-	storageErr, ok := err.(azblob.StorageError)
-	if !ok {
-		return false
-	}
-
-	return storageErr.Response().StatusCode == http.StatusNotFound // nolint: bodyclose
+	_, ok := NotFoundKind(err)
+	return ok
 }
@@ -0,0 +1,940 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func TestNewContainerHandleWithOptions(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		o      ContainerHandleOptions
+		want   azblob.RetryOptions
+	}{
+		"Defaults": {
+			reason: "Unconfigured retry options should reflect the azblob SDK's defaults.",
+			want: azblob.RetryOptions{
+				Policy:        azblob.RetryPolicyExponential,
+				MaxTries:      4,
+				TryTimeout:    time.Minute,
+				RetryDelay:    4 * time.Second,
+				MaxRetryDelay: 120 * time.Second,
+			},
+		},
+		"FixedDefaults": {
+			reason: "The fixed retry policy should default to a longer retry delay.",
+			o:      ContainerHandleOptions{Retry: azblob.RetryOptions{Policy: azblob.RetryPolicyFixed}},
+			want: azblob.RetryOptions{
+				Policy:        azblob.RetryPolicyFixed,
+				MaxTries:      4,
+				TryTimeout:    time.Minute,
+				RetryDelay:    30 * time.Second,
+				MaxRetryDelay: 120 * time.Second,
+			},
+		},
+		"Configured": {
+			reason: "Configured retry options should be reflected as is.",
+			o: ContainerHandleOptions{Retry: azblob.RetryOptions{
+				Policy:        azblob.RetryPolicyFixed,
+				MaxTries:      2,
+				TryTimeout:    10 * time.Second,
+				RetryDelay:    time.Second,
+				MaxRetryDelay: 5 * time.Second,
+			}},
+			want: azblob.RetryOptions{
+				Policy:        azblob.RetryPolicyFixed,
+				MaxTries:      2,
+				TryTimeout:    10 * time.Second,
+				RetryDelay:    time.Second,
+				MaxRetryDelay: 5 * time.Second,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h, err := NewContainerHandleWithOptions(testAccount, testKey, testContainer, tc.o)
+			if err != nil {
+				t.Fatalf("\n%s\nNewContainerHandleWithOptions(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, h.RetryOptions()); diff != "" {
+				t.Errorf("\n%s\nRetryOptions(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExists(t *testing.T) {
+	type want struct {
+		exists bool
+		err    bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		want   want
+	}{
+		"Exists": {
+			reason: "A container whose properties can be read exists.",
+			status: http.StatusOK,
+			want:   want{exists: true},
+		},
+		"NotFound": {
+			reason: "A container that is not found does not exist.",
+			status: http.StatusNotFound,
+			want:   want{exists: false},
+		},
+		"Error": {
+			reason: "Other errors should be returned.",
+			status: http.StatusForbidden,
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			exists, err := h.Exists(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nExists(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if exists != tc.want.exists {
+				t.Errorf("\n%s\nExists(...): want %t, got %t", tc.reason, tc.want.exists, exists)
+			}
+		})
+	}
+}
+
+func TestWaitUntilExists(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		visible  int
+		timeout  time.Duration
+		cancel   bool
+		wantErr  bool
+		minPolls int
+	}{
+		"EventuallyVisible": {
+			reason:   "Polling should continue until a newly created container is visible.",
+			visible:  3,
+			timeout:  5 * time.Second,
+			minPolls: 3,
+		},
+		"Timeout": {
+			reason:  "An error should be returned if the container is not visible before the timeout.",
+			visible: -1,
+			timeout: 300 * time.Millisecond,
+			wantErr: true,
+		},
+		"Cancelled": {
+			reason:  "An error should be returned if the context is cancelled.",
+			visible: -1,
+			timeout: 5 * time.Second,
+			cancel:  true,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			polls := 0
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				polls++
+				if tc.visible < 0 || polls < tc.visible {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancel {
+				cancel()
+			}
+
+			err := h.WaitUntilExists(ctx, tc.timeout)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\nWaitUntilExists(...): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+			if polls < tc.minPolls {
+				t.Errorf("\n%s\nWaitUntilExists(...): want at least %d polls, got %d", tc.reason, tc.minPolls, polls)
+			}
+		})
+	}
+}
+
+func TestIsAlreadyExists(t *testing.T) {
+	err := errors.Wrap(&AlreadyExistsError{Container: testContainer}, "boom")
+	if !IsAlreadyExists(err) {
+		t.Errorf("IsAlreadyExists(%v): want true", err)
+	}
+	if IsExternalChange(err) {
+		t.Errorf("IsExternalChange(%v): want false", err)
+	}
+}
+
+func TestIsContainerAlreadyExists(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		status int
+		code   azblob.ServiceCodeType
+		want   bool
+	}{
+		"AlreadyExists": {
+			reason: "Creating a container that already exists should be detected.",
+			status: http.StatusConflict,
+			code:   azblob.ServiceCodeContainerAlreadyExists,
+			want:   true,
+		},
+		"BeingDeleted": {
+			reason: "Creating a container that is being deleted is a conflict for another reason.",
+			status: http.StatusConflict,
+			code:   azblob.ServiceCodeContainerBeingDeleted,
+			want:   false,
+		},
+		"NoServiceCode": {
+			reason: "A conflict that does not say why should not be mistaken for an existing container.",
+			status: http.StatusConflict,
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.code != "" {
+					w.Header().Set("x-ms-error-code", string(tc.code))
+				}
+				w.WriteHeader(tc.status)
+			}))
+			err := errors.Wrap(h.Create(context.Background(), azblob.PublicAccessNone, nil), "boom")
+			if got := IsContainerAlreadyExists(err); got != tc.want {
+				t.Errorf("\n%s\nIsContainerAlreadyExists(%v): want %t, got %t", tc.reason, err, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNotFoundKind(t *testing.T) {
+	type want struct {
+		kind Kind
+		ok   bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		code   string
+		wrap   bool
+		want   want
+	}{
+		"ContainerNotFound": {
+			reason: "A missing container should be reported as such.",
+			status: http.StatusNotFound,
+			code:   string(azblob.ServiceCodeContainerNotFound),
+			want:   want{kind: KindContainer, ok: true},
+		},
+		"BlobNotFound": {
+			reason: "A missing blob should be reported as such.",
+			status: http.StatusNotFound,
+			code:   string(azblob.ServiceCodeBlobNotFound),
+			want:   want{kind: KindBlob, ok: true},
+		},
+		"ResourceNotFound": {
+			reason: "A not found error that does not identify the missing resource should be of an unknown kind.",
+			status: http.StatusNotFound,
+			code:   string(azblob.ServiceCodeResourceNotFound),
+			want:   want{kind: KindUnknown, ok: true},
+		},
+		"NoServiceCode": {
+			reason: "A not found error without a service code should be of an unknown kind.",
+			status: http.StatusNotFound,
+			want:   want{kind: KindUnknown, ok: true},
+		},
+		"Wrapped": {
+			reason: "Wrapped not found errors should be detected.",
+			status: http.StatusNotFound,
+			code:   string(azblob.ServiceCodeContainerNotFound),
+			wrap:   true,
+			want:   want{kind: KindContainer, ok: true},
+		},
+		"OtherError": {
+			reason: "Errors other than not found should not be reported as not found.",
+			status: http.StatusForbidden,
+			code:   string(azblob.ServiceCodeContainerNotFound),
+			want:   want{ok: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.code != "" {
+					w.Header().Set("x-ms-error-code", tc.code)
+				}
+				w.WriteHeader(tc.status)
+			}))
+			_, err := h.GetProperties(context.Background(), azblob.LeaseAccessConditions{})
+			if tc.wrap {
+				err = errors.Wrap(err, "boom")
+			}
+			kind, ok := NotFoundKind(err)
+			if diff := cmp.Diff(tc.want, want{kind: kind, ok: ok}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nNotFoundKind(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if IsNotFoundError(err) != tc.want.ok {
+				t.Errorf("\n%s\nIsNotFoundError(...): want %t", tc.reason, tc.want.ok)
+			}
+		})
+	}
+
+	if _, ok := NotFoundKind(errors.New("boom")); ok {
+		t.Errorf("NotFoundKind(...): want false for an error that is not a storage error")
+	}
+}
+
+func TestServiceHandleContainer(t *testing.T) {
+	s, err := NewServiceHandle(testAccount, testKey, ContainerHandleOptions{})
+	if err != nil {
+		t.Fatalf("NewServiceHandle(...): %v", err)
+	}
+	a, b := s.Container("a"), s.Container("b")
+	if a.pipeline != s.pipeline || b.pipeline != s.pipeline {
+		t.Errorf("Container(...): want containers to share the service's pipeline")
+	}
+	for name, h := range map[string]*ContainerHandle{"a": a, "b": b} {
+		u := h.URL()
+		if got, want := u.String(), "https://"+testAccount+".blob.core.windows.net/"+name; got != want {
+			t.Errorf("Container(%q): want URL %s, got %s", name, want, got)
+		}
+	}
+	if diff := cmp.Diff(s.retry, a.RetryOptions()); diff != "" {
+		t.Errorf("Container(...): -want retry options, +got retry options:\n%s", diff)
+	}
+}
+
+func TestNewServiceHandleWithSAS(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		sas    string
+		want   string
+		err    bool
+	}{
+		"SAS": {
+			reason: "Containers should be addressed with the shared access signature.",
+			sas:    "sv=2020-08-04&sig=c2ln",
+			want:   "https://" + testAccount + ".blob.core.windows.net/c?sig=c2ln&sv=2020-08-04",
+		},
+		"LeadingQuestionMark": {
+			reason: "Shared access signatures copied from the Azure portal should be accepted.",
+			sas:    "?sv=2020-08-04&sig=c2ln",
+			want:   "https://" + testAccount + ".blob.core.windows.net/c?sig=c2ln&sv=2020-08-04",
+		},
+		"Unsigned": {
+			reason: "Shared access signatures without a signature should be rejected.",
+			sas:    "sv=2020-08-04",
+			err:    true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewServiceHandleWithSAS(testAccount, tc.sas, ContainerHandleOptions{})
+			if (err != nil) != tc.err {
+				t.Fatalf("\n%s\nNewServiceHandleWithSAS(...): want error %t, got %v", tc.reason, tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			u := s.Container("c").URL()
+			if got := u.String(); got != tc.want {
+				t.Errorf("\n%s\nContainer(...): want URL %s, got %s", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+// metadataServer serves a container's metadata, replacing it when it is set.
+func metadataServer(md map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Query().Get("comp") == "metadata" {
+			for k := range md {
+				delete(md, k)
+			}
+			for k, v := range r.Header {
+				if strings.HasPrefix(strings.ToLower(k), headerMetaPrefix) {
+					md[strings.ToLower(k[len(headerMetaPrefix):])] = v[0]
+				}
+			}
+			return
+		}
+		for k, v := range md {
+			w.Header().Set(headerMetaPrefix+k, v)
+		}
+	})
+}
+
+func TestMergeMetadata(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		existing map[string]string
+		metadata azblob.Metadata
+		remove   []string
+		want     azblob.Metadata
+	}{
+		"Merge": {
+			reason:   "Supplied metadata should be merged into the existing metadata.",
+			existing: map[string]string{"owner": "crossplane", "team": "a"},
+			metadata: azblob.Metadata{"team": "b", "env": "prod"},
+			want:     azblob.Metadata{"owner": "crossplane", "team": "b", "env": "prod"},
+		},
+		"Remove": {
+			reason:   "Removed keys should be deleted while the others are preserved.",
+			existing: map[string]string{"owner": "crossplane", "stale": "x"},
+			metadata: azblob.Metadata{"env": "prod"},
+			remove:   []string{"Stale"},
+			want:     azblob.Metadata{"owner": "crossplane", "env": "prod"},
+		},
+		"RemoveSupplied": {
+			reason:   "A key that is both supplied and removed should be removed.",
+			existing: map[string]string{"owner": "crossplane"},
+			metadata: azblob.Metadata{"env": "prod"},
+			remove:   []string{"env", "missing"},
+			want:     azblob.Metadata{"owner": "crossplane"},
+		},
+		"RemoveAll": {
+			reason:   "Removing every key should leave the container without metadata.",
+			existing: map[string]string{"owner": "crossplane"},
+			remove:   []string{"owner"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestContainerHandle(t, metadataServer(tc.existing))
+			if err := h.MergeMetadata(context.Background(), tc.metadata, tc.remove); err != nil {
+				t.Fatalf("\n%s\nMergeMetadata(...): %v", tc.reason, err)
+			}
+			_, got, err := h.Get(context.Background())
+			if err != nil {
+				t.Fatalf("\n%s\nGet(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nMergeMetadata(...): -want metadata, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRefreshStatus(t *testing.T) {
+	mu := &sync.Mutex{}
+	current, peak := 0, 0
+	serve := func(status int, headers map[string]string) *ContainerHandle {
+		return newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			current++
+			if current > peak {
+				peak = current
+			}
+			mu.Unlock()
+			defer func() { mu.Lock(); current--; mu.Unlock() }()
+			time.Sleep(10 * time.Millisecond)
+
+			for k, v := range headers {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(status)
+		}))
+	}
+
+	found := map[string]string{headerBlobPublicAccess: "blob", headerMetaPrefix + "owner": "crossplane"}
+	missing := map[string]string{"x-ms-error-code": string(azblob.ServiceCodeContainerNotFound)}
+	handles := []*ContainerHandle{serve(http.StatusOK, found), serve(http.StatusNotFound, missing)}
+	for i := 0; i < 2*statusConcurrency; i++ {
+		handles = append(handles, serve(http.StatusOK, found))
+	}
+
+	results := RefreshStatus(context.Background(), handles)
+
+	if len(results) != len(handles) {
+		t.Fatalf("RefreshStatus(...): want %d results, got %d", len(handles), len(results))
+	}
+	pat := azblob.PublicAccessBlob
+	want := StatusResult{PublicAccessType: &pat, Metadata: azblob.Metadata{"owner": "crossplane"}}
+	if diff := cmp.Diff(want, results[0]); diff != "" {
+		t.Errorf("RefreshStatus(...): -want result 0, +got:\n%s", diff)
+	}
+	if kind, ok := NotFoundKind(results[1].Err); !ok || kind != KindContainer {
+		t.Errorf("RefreshStatus(...): want container not found error for result 1, got %v", results[1].Err)
+	}
+	for i, r := range results[2:] {
+		if r.Err != nil {
+			t.Errorf("RefreshStatus(...): result %d: unexpected error: %v", i+2, r.Err)
+		}
+	}
+	if peak > statusConcurrency {
+		t.Errorf("RefreshStatus(...): want at most %d concurrent requests, got %d", statusConcurrency, peak)
+	}
+}
+
+func TestRefreshStatusCancelled(t *testing.T) {
+	requests := 0
+	h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for i, r := range RefreshStatus(ctx, []*ContainerHandle{h, h}) {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("RefreshStatus(...): want result %d cancelled, got %v", i, r.Err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("RefreshStatus(...): want no requests, got %d", requests)
+	}
+}
+
+// newTestServiceHandle returns a ServiceHandle whose requests are served by
+// the supplied handler rather than Azure.
+func newTestServiceHandle(t *testing.T, h http.Handler) *ServiceHandle {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	c, err := azblob.NewSharedKeyCredential(testAccount, testKey)
+	if err != nil {
+		t.Fatalf("azblob.NewSharedKeyCredential(...): %v", err)
+	}
+	p := azblob.NewPipeline(c, azblob.PipelineOptions{Retry: azblob.RetryOptions{MaxTries: 1}})
+	u, _ := url.Parse(srv.URL)
+	return &ServiceHandle{ServiceURL: azblob.NewServiceURL(*u, p), pipeline: p}
+}
+
+func TestCreateMany(t *testing.T) {
+	mu := &sync.Mutex{}
+	current, peak := 0, 0
+	created := map[string]azblob.Metadata{}
+	s := newTestServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		current++
+		if current > peak {
+			peak = current
+		}
+		mu.Unlock()
+		defer func() { mu.Lock(); current--; mu.Unlock() }()
+		time.Sleep(10 * time.Millisecond)
+
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		switch {
+		case name == "existing":
+			w.Header().Set("x-ms-error-code", string(azblob.ServiceCodeContainerAlreadyExists))
+			w.WriteHeader(http.StatusConflict)
+		case name == "failing":
+			w.Header().Set("x-ms-error-code", string(azblob.ServiceCodeInvalidResourceName))
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			mu.Lock()
+			created[name] = azblob.Metadata{"owner": r.Header.Get(headerMetaPrefix + "owner")}
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+
+	specs := []ContainerSpec{
+		{Name: "existing"},
+		{Name: "failing"},
+	}
+	for i := 0; i < 2*createConcurrency; i++ {
+		specs = append(specs, ContainerSpec{Name: fmt.Sprintf("new-%d", i), Metadata: azblob.Metadata{"owner": "crossplane"}})
+	}
+
+	errs := s.CreateMany(context.Background(), specs)
+
+	if len(errs) != 1 || errs["failing"] == nil {
+		t.Fatalf("CreateMany(...): want only the failing container to have an error, got %v", errs)
+	}
+	if got := ClassifyStorageError(errs["failing"]); got != ErrorClassInvalid {
+		t.Errorf("CreateMany(...): want the failing container's error classified %s, got %s", ErrorClassInvalid, got)
+	}
+	if len(created) != 2*createConcurrency {
+		t.Errorf("CreateMany(...): want %d new containers created, got %d", 2*createConcurrency, len(created))
+	}
+	for name, md := range created {
+		if md["owner"] != "crossplane" {
+			t.Errorf("CreateMany(...): want container %s created with its metadata, got %v", name, md)
+		}
+	}
+	if peak > createConcurrency {
+		t.Errorf("CreateMany(...): want at most %d concurrent requests, got %d", createConcurrency, peak)
+	}
+}
+
+func TestCreateManyCancelled(t *testing.T) {
+	requests := 0
+	s := newTestServiceHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	errs := s.CreateMany(ctx, []ContainerSpec{{Name: "a"}, {Name: "b"}})
+	for _, name := range []string{"a", "b"} {
+		if !errors.Is(errs[name], context.Canceled) {
+			t.Errorf("CreateMany(...): want container %s cancelled, got %v", name, errs[name])
+		}
+	}
+	if requests != 0 {
+		t.Errorf("CreateMany(...): want no requests, got %d", requests)
+	}
+}
+
+func TestUpdatePartial(t *testing.T) {
+	errBoom := errors.New("boom")
+	blob := azblob.PublicAccessBlob
+	md := azblob.Metadata{"owner": "crossplane"}
+
+	type want struct {
+		result UpdateResult
+		reqs   []string
+	}
+	cases := map[string]struct {
+		reason string
+		access *azblob.PublicAccessType
+		md     *azblob.Metadata
+		fail   string
+		want   want
+	}{
+		"Applied": {
+			reason: "Both parts should be applied, metadata first.",
+			access: &blob,
+			md:     &md,
+			want: want{
+				result: UpdateResult{MetadataApplied: true, AccessPolicyApplied: true},
+				reqs:   []string{"metadata", "acl"},
+			},
+		},
+		"MetadataFailed": {
+			reason: "A failure to apply the metadata should stop the update before the access policy.",
+			access: &blob,
+			md:     &md,
+			fail:   "metadata",
+			want: want{
+				result: UpdateResult{Err: errBoom},
+				reqs:   []string{"metadata"},
+			},
+		},
+		"AccessPolicyFailed": {
+			reason: "A failure to apply the access policy should report the metadata as applied.",
+			access: &blob,
+			md:     &md,
+			fail:   "acl",
+			want: want{
+				result: UpdateResult{MetadataApplied: true, Err: errBoom},
+				reqs:   []string{"metadata", "acl"},
+			},
+		},
+		"AccessPolicyOnly": {
+			reason: "Metadata that is nil should not be written.",
+			access: &blob,
+			want: want{
+				result: UpdateResult{AccessPolicyApplied: true},
+				reqs:   []string{"acl"},
+			},
+		},
+		"MetadataOnlyFailed": {
+			reason: "An access policy that is nil should not be written, even if the metadata fails.",
+			md:     &md,
+			fail:   "metadata",
+			want: want{
+				result: UpdateResult{Err: errBoom},
+				reqs:   []string{"metadata"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var reqs []string
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				comp := r.URL.Query().Get("comp")
+				reqs = append(reqs, comp)
+				if comp == tc.fail {
+					w.WriteHeader(http.StatusForbidden)
+				}
+			}))
+
+			got := h.UpdatePartial(context.Background(), tc.access, tc.md)
+			if diff := cmp.Diff(tc.want.result, got, cmp.Comparer(func(a, b error) bool { return (a == nil) == (b == nil) })); diff != "" {
+				t.Errorf("\n%s\nUpdatePartial(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reqs, reqs); diff != "" {
+				t.Errorf("\n%s\nUpdatePartial(...): -want requests, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdatePartialStoredAccessPolicies(t *testing.T) {
+	blob := azblob.PublicAccessBlob
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var body string
+	h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("comp") == "acl" {
+			b, _ := io.ReadAll(r.Body)
+			body = string(b)
+		}
+	}))
+	h.StoredAccessPolicies = []azblob.SignedIdentifier{{ID: "readers", AccessPolicy: azblob.AccessPolicy{Start: start, Expiry: start.Add(time.Hour), Permission: "r"}}}
+
+	if r := h.UpdatePartial(context.Background(), &blob, nil); r.Err != nil {
+		t.Fatalf("UpdatePartial(...): %v", r.Err)
+	}
+	if !strings.Contains(body, "<Id>readers</Id>") {
+		t.Errorf("UpdatePartial(...): want the stored access policies written with the public access type, got body %q", body)
+	}
+}
+
+func TestUpdatePartialAccessPolicyConflict(t *testing.T) {
+	blob := azblob.PublicAccessBlob
+	md := azblob.Metadata{"owner": "crossplane"}
+
+	settle := accessPolicySettle
+	accessPolicySettle = time.Millisecond
+	t.Cleanup(func() { accessPolicySettle = settle })
+
+	type want struct {
+		result UpdateResult
+		acls   int
+	}
+	cases := map[string]struct {
+		reason    string
+		md        *azblob.Metadata
+		conflicts int
+		want      want
+	}{
+		"SettledAfterConflict": {
+			reason:    "An access policy write that conflicts with the metadata write before it should be retried.",
+			md:        &md,
+			conflicts: 1,
+			want: want{
+				result: UpdateResult{MetadataApplied: true, AccessPolicyApplied: true},
+				acls:   2,
+			},
+		},
+		"ConflictPersists": {
+			reason:    "An access policy write that keeps conflicting should fail after a couple of retries.",
+			md:        &md,
+			conflicts: 10,
+			want: want{
+				result: UpdateResult{MetadataApplied: true, Err: errors.New("conflict")},
+				acls:   1 + accessPolicyRetries,
+			},
+		},
+		"NoMetadataWrite": {
+			reason:    "An access policy write that conflicts without a metadata write before it should not be retried.",
+			conflicts: 1,
+			want: want{
+				result: UpdateResult{Err: errors.New("conflict")},
+				acls:   1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			acls := 0
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("comp") != "acl" {
+					return
+				}
+				acls++
+				if acls <= tc.conflicts {
+					w.WriteHeader(http.StatusConflict)
+				}
+			}))
+
+			got := h.UpdatePartial(context.Background(), &blob, tc.md)
+			if diff := cmp.Diff(tc.want.result, got, cmp.Comparer(func(a, b error) bool { return (a == nil) == (b == nil) })); diff != "" {
+				t.Errorf("\n%s\nUpdatePartial(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if acls != tc.want.acls {
+				t.Errorf("\n%s\nUpdatePartial(...): want %d access policy writes, got %d", tc.reason, tc.want.acls, acls)
+			}
+		})
+	}
+}
+
+func TestUpdatePartialAccessPolicyCancelled(t *testing.T) {
+	blob := azblob.PublicAccessBlob
+	md := azblob.Metadata{"owner": "crossplane"}
+
+	settle := accessPolicySettle
+	accessPolicySettle = time.Hour
+	t.Cleanup(func() { accessPolicySettle = settle })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The context is cancelled shortly after the conflicting write is
+	// answered, while the update waits to retry it.
+	acls := 0
+	h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("comp") == "acl" {
+			acls++
+			time.AfterFunc(20*time.Millisecond, cancel)
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+
+	got := h.UpdatePartial(ctx, &blob, &md)
+	if !errors.Is(got.Err, context.Canceled) {
+		t.Errorf("UpdatePartial(...): want error %v while waiting to retry, got %v", context.Canceled, got.Err)
+	}
+	if !got.MetadataApplied || got.AccessPolicyApplied {
+		t.Errorf("UpdatePartial(...): want only metadata applied, got %+v", got)
+	}
+	if acls != 1 {
+		t.Errorf("UpdatePartial(...): want 1 access policy write, got %d", acls)
+	}
+}
+
+func TestNewContainerHandleWithCredentials(t *testing.T) {
+	read, _ := azblob.NewSharedKeyCredential(testAccount, testKey)
+	write, _ := azblob.NewSharedKeyCredential(testAccount, testKey)
+	h, err := NewContainerHandleWithCredentials(testAccount, testContainer, read, write, ContainerHandleOptions{})
+	if err != nil {
+		t.Fatalf("NewContainerHandleWithCredentials(...): %v", err)
+	}
+	if h.reader == nil || h.reader.pipeline == h.pipeline {
+		t.Fatalf("NewContainerHandleWithCredentials(...): want reads to use a separate pipeline")
+	}
+	if got, want := h.reader.URL(), h.URL(); got != want {
+		t.Errorf("NewContainerHandleWithCredentials(...): want reads of %s, got %s", want.String(), got.String())
+	}
+}
+
+func TestContainerHandleCredentials(t *testing.T) {
+	const (
+		reader = "reader"
+		writer = "writer"
+	)
+	cases := map[string]struct {
+		reason string
+		op     func(context.Context, *ContainerHandle) error
+		want   string
+	}{
+		"Get": {
+			reason: "Getting the container should use the read credential.",
+			op: func(ctx context.Context, h *ContainerHandle) error {
+				_, _, err := h.Get(ctx)
+				return err
+			},
+			want: reader,
+		},
+		"GetContainerProperties": {
+			reason: "Getting the container's properties should use the read credential.",
+			op: func(ctx context.Context, h *ContainerHandle) error {
+				_, err := h.GetContainerProperties(ctx)
+				return err
+			},
+			want: reader,
+		},
+		"Exists": {
+			reason: "Checking whether the container exists should use the read credential.",
+			op: func(ctx context.Context, h *ContainerHandle) error {
+				_, err := h.Exists(ctx)
+				return err
+			},
+			want: reader,
+		},
+		"ListBlobs": {
+			reason: "Listing the container's blobs should use the read credential.",
+			op: func(ctx context.Context, h *ContainerHandle) error {
+				_, err := h.ListBlobs(ctx, "")
+				return err
+			},
+			want: reader,
+		},
+		"Create": {
+			reason: "Creating the container should use the write credential.",
+			op: func(ctx context.Context, h *ContainerHandle) error {
+				return h.Create(ctx, azblob.PublicAccessNone, nil)
+			},
+			want: writer,
+		},
+		"Update": {
+			reason: "Updating the container should use the write credential.",
+			op: func(ctx context.Context, h *ContainerHandle) error {
+				return h.Update(ctx, azblob.PublicAccessNone, azblob.Metadata{"owner": "crossplane"})
+			},
+			want: writer,
+		},
+		"Delete": {
+			reason: "Deleting the container should use the write credential.",
+			op: func(ctx context.Context, h *ContainerHandle) error {
+				return h.Delete(ctx)
+			},
+			want: writer,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mu := sync.Mutex{}
+			got := map[string]bool{}
+			srv := func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				got[strings.TrimPrefix(strings.SplitN(r.Header.Get("Authorization"), ":", 2)[0], "SharedKey ")] = true
+				mu.Unlock()
+				switch {
+				case r.URL.Query().Get("comp") == "list":
+					_, _ = w.Write([]byte(blobListing("")))
+				case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "":
+					w.WriteHeader(http.StatusCreated)
+				case r.Method == http.MethodDelete:
+					w.WriteHeader(http.StatusAccepted)
+				}
+			}
+			h := newTestContainerHandleWithCredentials(t, http.HandlerFunc(srv), reader, writer)
+
+			if err := tc.op(context.Background(), h); err != nil {
+				t.Fatalf("\n%s\n%s(...): %v", tc.reason, name, err)
+			}
+			if diff := cmp.Diff(map[string]bool{tc.want: true}, got); diff != "" {
+				t.Errorf("\n%s\n%s(...): -want credentials, +got credentials:\n%s", tc.reason, name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+// Metadata keys under which a container's default content settings are kept.
+// The blob service has no container level content settings, so tools that
+// write blobs to the container must read them from its metadata.
+const (
+	MetadataKeyCacheControl       = "defaultcachecontrol"
+	MetadataKeyContentDisposition = "defaultcontentdisposition"
+)
+
+const errInvalidContentSetting = "invalid default %s: %s"
+
+// ContentSettings are the default content headers of a container's blobs.
+// Empty settings are unset.
+type ContentSettings struct {
+	CacheControl       string
+	ContentDisposition string
+}
+
+// Validate returns an error if any of the settings is set to a value that is
+// not a valid, non-empty header value.
+func (s ContentSettings) Validate() error {
+	for _, h := range []struct{ name, value string }{
+		{name: "Cache-Control", value: s.CacheControl},
+		{name: "Content-Disposition", value: s.ContentDisposition},
+	} {
+		if h.value == "" {
+			continue
+		}
+		if strings.TrimSpace(h.value) == "" {
+			return errors.Errorf(errInvalidContentSetting, h.name, "value must not be blank")
+		}
+		for _, r := range h.value {
+			// Metadata values are sent as headers, so must be printable ASCII.
+			if r < ' ' || r > '~' {
+				return errors.Errorf(errInvalidContentSetting, h.name, "value must contain only printable ASCII characters")
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyTo returns a copy of the supplied metadata in which the settings are
+// kept. Unset settings are removed from it.
+func (s ContentSettings) ApplyTo(md azblob.Metadata) azblob.Metadata {
+	out := azblob.Metadata{}
+	for k, v := range md {
+		switch strings.ToLower(k) {
+		case MetadataKeyCacheControl, MetadataKeyContentDisposition:
+		default:
+			out[k] = v
+		}
+	}
+	if s.CacheControl != "" {
+		out[MetadataKeyCacheControl] = s.CacheControl
+	}
+	if s.ContentDisposition != "" {
+		out[MetadataKeyContentDisposition] = s.ContentDisposition
+	}
+	return out
+}
+
+// ContentSettingsFromMetadata returns the settings kept in the supplied
+// metadata.
+func ContentSettingsFromMetadata(md azblob.Metadata) ContentSettings {
+	s := ContentSettings{}
+	for k, v := range md {
+		switch strings.ToLower(k) {
+		case MetadataKeyCacheControl:
+			s.CacheControl = v
+		case MetadataKeyContentDisposition:
+			s.ContentDisposition = v
+		}
+	}
+	return s
+}
+
+// GetDefaultContentSettings returns the container's default content settings.
+func (a *ContainerHandle) GetDefaultContentSettings(ctx context.Context) (ContentSettings, error) {
+	_, md, err := a.Get(ctx)
+	if err != nil {
+		return ContentSettings{}, err
+	}
+	return ContentSettingsFromMetadata(md), nil
+}
+
+// SetDefaultContentSettings sets the container's default content settings,
+// leaving the rest of its metadata as it is.
+func (a *ContainerHandle) SetDefaultContentSettings(ctx context.Context, s ContentSettings) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	_, md, err := a.Get(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = a.ContainerURL.SetMetadata(ctx, s.ApplyTo(md), azblob.ContainerAccessConditions{})
+	return err
+}
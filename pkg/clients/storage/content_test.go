@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestContentSettingsValidate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		s      ContentSettings
+		want   bool
+	}{
+		"Unset": {
+			reason: "Unset settings should be valid.",
+			want:   true,
+		},
+		"Valid": {
+			reason: "Printable header values should be valid.",
+			s:      ContentSettings{CacheControl: "public, max-age=3600", ContentDisposition: `attachment; filename="a.txt"`},
+			want:   true,
+		},
+		"Blank": {
+			reason: "Blank header values should be invalid.",
+			s:      ContentSettings{ContentDisposition: "  "},
+		},
+		"ControlCharacter": {
+			reason: "Header values containing control characters should be invalid.",
+			s:      ContentSettings{CacheControl: "no-cache\r\nX-Injected: 1"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.s.Validate()
+			if (err == nil) != tc.want {
+				t.Errorf("\n%s\nValidate(): want valid %t, got %v", tc.reason, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestContentSettingsMetadata(t *testing.T) {
+	s := ContentSettings{CacheControl: "no-cache"}
+	md := azblob.Metadata{"owner": "someone", "DefaultContentDisposition": "inline"}
+
+	got := s.ApplyTo(md)
+	want := azblob.Metadata{"owner": "someone", MetadataKeyCacheControl: "no-cache"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ApplyTo(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(s, ContentSettingsFromMetadata(got)); diff != "" {
+		t.Errorf("ContentSettingsFromMetadata(...): -want, +got:\n%s", diff)
+	}
+	if _, ok := md[MetadataKeyCacheControl]; ok {
+		t.Errorf("ApplyTo(...): want the supplied metadata unchanged, got %v", md)
+	}
+}
+
+func TestDefaultContentSettings(t *testing.T) {
+	// The fake container stores the metadata it was last set with.
+	md := map[string]string{"x-ms-meta-owner": "someone", "x-ms-meta-defaultcachecontrol": "max-age=60"}
+	var sets int
+	h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			sets++
+			md = map[string]string{}
+			for k := range r.Header {
+				if k = strings.ToLower(k); strings.HasPrefix(k, "x-ms-meta-") {
+					md[k] = r.Header.Get(k)
+				}
+			}
+			return
+		}
+		for k, v := range md {
+			w.Header().Set(k, v)
+		}
+	}))
+
+	got, err := h.GetDefaultContentSettings(context.Background())
+	if err != nil {
+		t.Fatalf("GetDefaultContentSettings(...): %v", err)
+	}
+	if diff := cmp.Diff(ContentSettings{CacheControl: "max-age=60"}, got); diff != "" {
+		t.Errorf("GetDefaultContentSettings(...): -want, +got:\n%s", diff)
+	}
+
+	want := ContentSettings{ContentDisposition: "inline"}
+	if err := h.SetDefaultContentSettings(context.Background(), want); err != nil {
+		t.Fatalf("SetDefaultContentSettings(...): %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"x-ms-meta-owner": "someone", "x-ms-meta-defaultcontentdisposition": "inline"}, md); diff != "" {
+		t.Errorf("SetDefaultContentSettings(...): want other metadata unchanged: -want, +got:\n%s", diff)
+	}
+	got, err = h.GetDefaultContentSettings(context.Background())
+	if err != nil {
+		t.Fatalf("GetDefaultContentSettings(...): %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetDefaultContentSettings(...): -want, +got:\n%s", diff)
+	}
+
+	if err := h.SetDefaultContentSettings(context.Background(), ContentSettings{CacheControl: " "}); err == nil {
+		t.Errorf("SetDefaultContentSettings(...): want error setting a blank value, got nil")
+	}
+	if sets != 1 {
+		t.Errorf("SetDefaultContentSettings(...): want 1 metadata update, got %d", sets)
+	}
+}
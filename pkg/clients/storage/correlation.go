@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+)
+
+// headerClientRequestID is the header of the client request ID, which Azure
+// records with each request in its diagnostic logs.
+const headerClientRequestID = "x-ms-client-request-id"
+
+type correlationKey struct{}
+
+// WithCorrelationID returns a context whose blob service requests are all
+// made with the supplied client request ID, so that the requests made by one
+// unit of work, such as a reconcile, can be found together in Azure
+// diagnostics. Requests are made with a unique client request ID otherwise.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of the supplied context, or an
+// empty string if it has none.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// newCorrelationPolicyFactory returns a factory of policies that make every
+// request with the correlation ID of its context, if any, as its client
+// request ID. Requests that already have a client request ID keep it.
+func newCorrelationPolicyFactory() pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, _ *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, req pipeline.Request) (pipeline.Response, error) {
+			if id := CorrelationID(ctx); id != "" && req.Header.Get(headerClientRequestID) == "" {
+				req.Header.Set(headerClientRequestID, id)
+			}
+			return next.Do(ctx, req)
+		}
+	})
+}
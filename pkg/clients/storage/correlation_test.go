@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCorrelationID(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		ctx    context.Context
+		shared bool
+	}{
+		"Correlated": {
+			reason: "Every request made with a correlation ID should share it as its client request ID.",
+			ctx:    WithCorrelationID(context.Background(), "uid-7"),
+			shared: true,
+		},
+		"Uncorrelated": {
+			reason: "Requests made without a correlation ID should each have a unique client request ID.",
+			ctx:    context.Background(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mu := &sync.Mutex{}
+			var got []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				got = append(got, r.Header.Get(headerClientRequestID))
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := azblob.NewSharedKeyCredential(testAccount, testKey)
+			if err != nil {
+				t.Fatalf("azblob.NewSharedKeyCredential(...): %v", err)
+			}
+			p := newPipeline(c, azblob.PipelineOptions{Retry: azblob.RetryOptions{MaxTries: 1}}, nil, "")
+			u, _ := url.Parse(srv.URL + "/" + testContainer)
+			h := &ContainerHandle{ContainerURL: azblob.NewContainerURL(*u, p), pipeline: p}
+
+			if _, _, err := h.Get(tc.ctx); err != nil {
+				t.Fatalf("\n%s\nGet(...): %v", tc.reason, err)
+			}
+			if _, err := h.GetContainerProperties(tc.ctx); err != nil {
+				t.Fatalf("\n%s\nGetContainerProperties(...): %v", tc.reason, err)
+			}
+			if tc.shared {
+				if diff := cmp.Diff([]string{"uid-7", "uid-7"}, got); diff != "" {
+					t.Errorf("\n%s\n%s header: -want, +got:\n%s", tc.reason, headerClientRequestID, diff)
+				}
+				return
+			}
+			if len(got) != 2 || got[0] == "" || got[0] == got[1] {
+				t.Errorf("\n%s\n%s header: want two unique IDs, got %q", tc.reason, headerClientRequestID, got)
+			}
+		})
+	}
+}
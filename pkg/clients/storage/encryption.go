@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AccountEncryptionScope is the name of the implicit encryption scope that
+// encrypts blobs with the storage account's own encryption settings.
+const AccountEncryptionScope = "$account-encryption-key"
+
+// An EncryptionKeyType indicates who manages the key used to encrypt a
+// container's blobs.
+type EncryptionKeyType string
+
+// Encryption key types.
+const (
+	EncryptionKeyMicrosoftManaged EncryptionKeyType = "MicrosoftManaged"
+	EncryptionKeyCustomerManaged  EncryptionKeyType = "CustomerManaged"
+)
+
+// keyVaultSource is the key source, in either account or encryption scope
+// terms, of keys stored in a customer's Key Vault. The two management APIs
+// disagree on its capitalisation.
+const keyVaultSource = "microsoft.keyvault"
+
+// IsAccountEncryptionScope returns true if blobs written with the supplied
+// encryption scope are encrypted using the storage account's own encryption
+// settings. An empty scope, such as one the service did not report, is treated
+// as the account's scope.
+func IsAccountEncryptionScope(scope string) bool {
+	return scope == "" || scope == AccountEncryptionScope
+}
+
+// AccountEncryptionKeyType returns the type of key used by a storage account
+// with the supplied encryption key source.
+func AccountEncryptionKeyType(keySource string) EncryptionKeyType {
+	if strings.EqualFold(keySource, keyVaultSource) {
+		return EncryptionKeyCustomerManaged
+	}
+	return EncryptionKeyMicrosoftManaged
+}
+
+// GetEncryptionScopeKeyType returns the type of key used by the named
+// encryption scope.
+func GetEncryptionScopeKeyType(ctx context.Context, m ManagementOperations, scope string) (EncryptionKeyType, error) {
+	s, err := m.GetEncryptionScope(ctx, scope)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot get encryption scope %s", scope)
+	}
+	if s.EncryptionScopeProperties == nil {
+		return EncryptionKeyMicrosoftManaged, nil
+	}
+	return AccountEncryptionKeyType(string(s.EncryptionScopeProperties.Source)), nil
+}
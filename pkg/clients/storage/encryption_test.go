@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+type mockManagementOperations struct {
+	scope *storage.EncryptionScope
+	err   error
+}
+
+func (m *mockManagementOperations) GetAccount(_ context.Context) (*storage.Account, error) {
+	return nil, m.err
+}
+
+func (m *mockManagementOperations) GetEncryptionScope(_ context.Context, _ string) (*storage.EncryptionScope, error) {
+	return m.scope, m.err
+}
+
+func (m *mockManagementOperations) SetContainerEncryptionScope(_ context.Context, _, _ string, _ bool) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) EnsureEncryptionScope(_ context.Context, _, _, _, _ string) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) InitiateFailover(_ context.Context, _, _ string) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) GetFailoverState(_ context.Context, _, _ string) (FailoverState, error) {
+	return FailoverState{}, m.err
+}
+
+func (m *mockManagementOperations) GetDefaultToOAuth(_ context.Context) (bool, error) {
+	return false, m.err
+}
+
+func (m *mockManagementOperations) SetDefaultToOAuth(_ context.Context, _ bool) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) GetAllowSharedKeyAccess(_ context.Context) (bool, error) {
+	return true, m.err
+}
+
+func (m *mockManagementOperations) SetAllowSharedKeyAccess(_ context.Context, _ bool) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) GetAllowBlobPublicAccess(_ context.Context) (bool, error) {
+	return true, m.err
+}
+
+func (m *mockManagementOperations) GetMinimumTLSVersion(_ context.Context) (string, error) {
+	return "", m.err
+}
+
+func (m *mockManagementOperations) SetMinimumTLSVersion(_ context.Context, _ string) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) GetEnableHTTPSTrafficOnly(_ context.Context) (bool, error) {
+	return true, m.err
+}
+
+func (m *mockManagementOperations) SetEnableHTTPSTrafficOnly(_ context.Context, _ bool) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) GetDeletedAccount(_ context.Context, _ string) (*storage.DeletedAccount, error) {
+	return nil, m.err
+}
+
+func (m *mockManagementOperations) RestoreDeletedAccount(_ context.Context, _ string) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) GetContainerImmutability(_ context.Context, _ string) (ContainerImmutability, error) {
+	return ContainerImmutability{}, m.err
+}
+
+func (m *mockManagementOperations) SetContainerImmutabilityPolicy(_ context.Context, _ string, _ ContainerImmutabilityPolicy) (string, error) {
+	return "", m.err
+}
+
+func (m *mockManagementOperations) ExtendContainerImmutabilityPolicy(_ context.Context, _ string, _ ContainerImmutabilityPolicy) (string, error) {
+	return "", m.err
+}
+
+func (m *mockManagementOperations) LockContainerImmutabilityPolicy(_ context.Context, _, _ string) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) SetContainerLegalHold(_ context.Context, _ string, _ []string) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) ClearContainerLegalHold(_ context.Context, _ string, _ []string) error {
+	return m.err
+}
+
+func (m *mockManagementOperations) GetBlobVersioning(_ context.Context) (bool, error) {
+	return false, m.err
+}
+
+func TestAccountEncryptionKeyType(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		keySource string
+		want      EncryptionKeyType
+	}{
+		"Unset": {
+			reason: "Accounts without a key source use Microsoft-managed keys.",
+			want:   EncryptionKeyMicrosoftManaged,
+		},
+		"Storage": {
+			reason:    "Accounts whose keys are managed by the storage service use Microsoft-managed keys.",
+			keySource: "Microsoft.Storage",
+			want:      EncryptionKeyMicrosoftManaged,
+		},
+		"KeyVault": {
+			reason:    "Accounts whose keys are stored in Key Vault use customer-managed keys, regardless of capitalisation.",
+			keySource: "Microsoft.Keyvault",
+			want:      EncryptionKeyCustomerManaged,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, AccountEncryptionKeyType(tc.keySource)); diff != "" {
+				t.Errorf("\n%s\nAccountEncryptionKeyType(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetEncryptionScopeKeyType(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		kt  EncryptionKeyType
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		m      ManagementOperations
+		want   want
+	}{
+		"KeyVault": {
+			reason: "Encryption scopes backed by Key Vault use customer-managed keys.",
+			m: &mockManagementOperations{scope: &storage.EncryptionScope{
+				EncryptionScopeProperties: &storage.EncryptionScopeProperties{
+					Source: storage.EncryptionScopeSourceMicrosoftKeyVault,
+				},
+			}},
+			want: want{kt: EncryptionKeyCustomerManaged},
+		},
+		"NoProperties": {
+			reason: "Encryption scopes without properties use Microsoft-managed keys.",
+			m:      &mockManagementOperations{scope: &storage.EncryptionScope{}},
+			want:   want{kt: EncryptionKeyMicrosoftManaged},
+		},
+		"GetFailed": {
+			reason: "Errors getting the encryption scope should be returned.",
+			m:      &mockManagementOperations{err: errBoom},
+			want:   want{err: errors.Wrapf(errBoom, "cannot get encryption scope %s", "scope")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kt, err := GetEncryptionScopeKeyType(context.Background(), tc.m, "scope")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGetEncryptionScopeKeyType(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.kt, kt); diff != "" {
+				t.Errorf("\n%s\nGetEncryptionScopeKeyType(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
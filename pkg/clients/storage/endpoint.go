@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// An Endpoint is one of the blob service endpoints of a storage account.
+type Endpoint string
+
+// Endpoints of a storage account.
+const (
+	// EndpointPrimary is the endpoint in the account's primary location.
+	EndpointPrimary Endpoint = "Primary"
+
+	// EndpointSecondary is the read-only endpoint in the account's secondary
+	// location. Only read-access geo-redundant accounts have one, and it may
+	// lag the primary endpoint by the account's replication delay.
+	EndpointSecondary Endpoint = "Secondary"
+)
+
+// secondaryBlobFormatString is the blob service endpoint in a storage
+// account's secondary location.
+const secondaryBlobFormatString = `https://%s-secondary.blob.%s`
+
+// An Operation is a ContainerHandle method whose requests can be routed to an
+// endpoint. Operations are named after their method.
+type Operation string
+
+// Operations that only read the container, and so can be routed to the
+// secondary endpoint. Every other operation writes the container, or reads it
+// to write it, and is always made against the primary endpoint.
+const (
+	OperationGet                    Operation = "Get"
+	OperationGetContainerProperties Operation = "GetContainerProperties"
+	OperationExists                 Operation = "Exists"
+	OperationListBlobs              Operation = "ListBlobs"
+)
+
+var readOperations = map[Operation]bool{
+	OperationGet:                    true,
+	OperationGetContainerProperties: true,
+	OperationExists:                 true,
+	OperationListBlobs:              true,
+}
+
+// An EndpointPolicy routes the requests of each operation to an endpoint.
+// Operations it omits are made against the primary endpoint.
+type EndpointPolicy map[Operation]Endpoint
+
+// ValidateEndpointPolicy returns an error unless the supplied policy routes
+// every operation to a known endpoint, and routes only operations that read
+// the container to the secondary endpoint, which cannot be written.
+func ValidateEndpointPolicy(p EndpointPolicy) error {
+	ops := make([]string, 0, len(p))
+	for op := range p {
+		ops = append(ops, string(op))
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		switch e := p[Operation(op)]; e {
+		case EndpointPrimary:
+		case EndpointSecondary:
+			if !readOperations[Operation(op)] {
+				return errors.Errorf("operation %s writes the container and cannot be routed to the secondary endpoint", op)
+			}
+		default:
+			return errors.Errorf("operation %s is routed to unknown endpoint %q", op, e)
+		}
+	}
+	return nil
+}
+
+// routesSecondary returns true if the supplied policy routes any operation to the
+// secondary endpoint.
+func (p EndpointPolicy) routesSecondary() bool {
+	for _, e := range p {
+		if e == EndpointSecondary {
+			return true
+		}
+	}
+	return false
+}
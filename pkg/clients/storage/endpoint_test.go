@@ -0,0 +1,334 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestValidateEndpointPolicy(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		p      EndpointPolicy
+		want   error
+	}{
+		"Empty": {
+			reason: "An empty policy should be valid.",
+		},
+		"ReadsToSecondary": {
+			reason: "Routing reads to the secondary endpoint should be valid.",
+			p:      EndpointPolicy{OperationListBlobs: EndpointSecondary, OperationGet: EndpointPrimary},
+		},
+		"WriteToSecondary": {
+			reason: "Routing a write to the secondary endpoint should be invalid.",
+			p:      EndpointPolicy{OperationListBlobs: EndpointSecondary, "Create": EndpointSecondary},
+			want:   errors.New("operation Create writes the container and cannot be routed to the secondary endpoint"),
+		},
+		"WriteToPrimary": {
+			reason: "Routing a write to the primary endpoint should be valid.",
+			p:      EndpointPolicy{"Delete": EndpointPrimary},
+		},
+		"UnknownEndpoint": {
+			reason: "Routing an operation to an unknown endpoint should be invalid.",
+			p:      EndpointPolicy{OperationGet: "Tertiary"},
+			want:   errors.New(`operation Get is routed to unknown endpoint "Tertiary"`),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateEndpointPolicy(tc.p)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateEndpointPolicy(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNewContainerHandleWithEndpoints(t *testing.T) {
+	h, err := NewContainerHandleWithOptions(testAccount, testKey, testContainer, ContainerHandleOptions{
+		Endpoints: EndpointPolicy{OperationListBlobs: EndpointSecondary},
+	})
+	if err != nil {
+		t.Fatalf("NewContainerHandleWithOptions(...): %v", err)
+	}
+	if h.secondary == nil {
+		t.Fatalf("NewContainerHandleWithOptions(...): want a secondary endpoint")
+	}
+	su := h.secondary.URL()
+	if got, want := su.String(), "https://"+testAccount+"-secondary.blob.core.windows.net/"+testContainer; got != want {
+		t.Errorf("NewContainerHandleWithOptions(...): want secondary endpoint %s, got %s", want, got)
+	}
+
+	_, err = NewContainerHandleWithOptions(testAccount, testKey, testContainer, ContainerHandleOptions{
+		Endpoints: EndpointPolicy{"Update": EndpointSecondary},
+	})
+	if err == nil {
+		t.Errorf("NewContainerHandleWithOptions(...): want an error when a write is routed to the secondary endpoint")
+	}
+}
+
+func TestNewContainerHandleWithEndpointSuffix(t *testing.T) {
+	h, err := NewContainerHandleWithOptions(testAccount, testKey, testContainer, ContainerHandleOptions{
+		Endpoints:      EndpointPolicy{OperationListBlobs: EndpointSecondary},
+		EndpointSuffix: "core.chinacloudapi.cn",
+	})
+	if err != nil {
+		t.Fatalf("NewContainerHandleWithOptions(...): %v", err)
+	}
+	u, su := h.URL(), h.secondary.URL()
+	if got, want := u.String(), "https://"+testAccount+".blob.core.chinacloudapi.cn/"+testContainer; got != want {
+		t.Errorf("NewContainerHandleWithOptions(...): want primary endpoint %s, got %s", want, got)
+	}
+	if got, want := su.String(), "https://"+testAccount+"-secondary.blob.core.chinacloudapi.cn/"+testContainer; got != want {
+		t.Errorf("NewContainerHandleWithOptions(...): want secondary endpoint %s, got %s", want, got)
+	}
+}
+
+func TestContainerHandleEndpoints(t *testing.T) {
+	const (
+		primary   = "primary"
+		secondary = "secondary"
+	)
+	ops := map[string]func(context.Context, *ContainerHandle) error{
+		"Get": func(ctx context.Context, h *ContainerHandle) error {
+			_, _, err := h.Get(ctx)
+			return err
+		},
+		"GetContainerProperties": func(ctx context.Context, h *ContainerHandle) error {
+			_, err := h.GetContainerProperties(ctx)
+			return err
+		},
+		"Exists": func(ctx context.Context, h *ContainerHandle) error {
+			_, err := h.Exists(ctx)
+			return err
+		},
+		"ListBlobs": func(ctx context.Context, h *ContainerHandle) error {
+			_, err := h.ListBlobs(ctx, "")
+			return err
+		},
+		"Create": func(ctx context.Context, h *ContainerHandle) error {
+			return h.Create(ctx, azblob.PublicAccessNone, nil)
+		},
+		"Update": func(ctx context.Context, h *ContainerHandle) error {
+			return h.Update(ctx, azblob.PublicAccessNone, azblob.Metadata{"owner": "crossplane"})
+		},
+		"Delete": func(ctx context.Context, h *ContainerHandle) error {
+			return h.Delete(ctx)
+		},
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      EndpointPolicy
+		want   map[string]string
+	}{
+		"NoPolicy": {
+			reason: "Every operation should be made against the primary endpoint when there is no policy.",
+			want: map[string]string{
+				"Get": primary, "GetContainerProperties": primary, "Exists": primary, "ListBlobs": primary,
+				"Create": primary, "Update": primary, "Delete": primary,
+			},
+		},
+		"ListBlobsOnSecondary": {
+			reason: "Only ListBlobs should be made against the secondary endpoint when only it is routed there.",
+			p:      EndpointPolicy{OperationListBlobs: EndpointSecondary, OperationGet: EndpointPrimary},
+			want: map[string]string{
+				"Get": primary, "GetContainerProperties": primary, "Exists": primary, "ListBlobs": secondary,
+				"Create": primary, "Update": primary, "Delete": primary,
+			},
+		},
+		"ReadsOnSecondary": {
+			reason: "Every routed read should be made against the secondary endpoint, and writes against the primary one.",
+			p: EndpointPolicy{
+				OperationGet:                    EndpointSecondary,
+				OperationGetContainerProperties: EndpointSecondary,
+				OperationExists:                 EndpointSecondary,
+				OperationListBlobs:              EndpointSecondary,
+			},
+			want: map[string]string{
+				"Get": secondary, "GetContainerProperties": secondary, "Exists": secondary, "ListBlobs": secondary,
+				"Create": primary, "Update": primary, "Delete": primary,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mu := sync.Mutex{}
+			var got []string
+			endpoint := func(e string) *url.URL {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					mu.Lock()
+					got = append(got, e)
+					mu.Unlock()
+					switch {
+					case r.URL.Query().Get("comp") == "list":
+						_, _ = w.Write([]byte(blobListing("")))
+					case r.Method == http.MethodPut && r.URL.Query().Get("comp") == "":
+						w.WriteHeader(http.StatusCreated)
+					case r.Method == http.MethodDelete:
+						w.WriteHeader(http.StatusAccepted)
+					}
+				}))
+				t.Cleanup(srv.Close)
+				u, _ := url.Parse(srv.URL + "/" + testContainer)
+				return u
+			}
+
+			c, _ := azblob.NewSharedKeyCredential(testAccount, testKey)
+			p := azblob.NewPipeline(c, azblob.PipelineOptions{Retry: azblob.RetryOptions{MaxTries: 1}})
+			h := &ContainerHandle{ContainerURL: azblob.NewContainerURL(*endpoint(primary), p), pipeline: p, endpoints: tc.p}
+			if tc.p.routesSecondary() {
+				h.secondary = &ContainerHandle{ContainerURL: azblob.NewContainerURL(*endpoint(secondary), p), pipeline: p}
+			}
+
+			for op, want := range tc.want {
+				got = nil
+				if err := ops[op](context.Background(), h); err != nil {
+					t.Fatalf("\n%s\n%s(...): %v", tc.reason, op, err)
+				}
+				if len(got) == 0 {
+					t.Errorf("\n%s\n%s(...): want requests to the %s endpoint, got none", tc.reason, op, want)
+				}
+				for _, e := range got {
+					if e != want {
+						t.Errorf("\n%s\n%s(...): want requests to the %s endpoint, got a request to the %s endpoint", tc.reason, op, want, e)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestContainerHandleFallback(t *testing.T) {
+	ops := map[string]func(context.Context, *ContainerHandle) error{
+		"Get": func(ctx context.Context, h *ContainerHandle) error {
+			_, _, err := h.Get(ctx)
+			return err
+		},
+		"GetContainerProperties": func(ctx context.Context, h *ContainerHandle) error {
+			_, err := h.GetContainerProperties(ctx)
+			return err
+		},
+		"Exists": func(ctx context.Context, h *ContainerHandle) error {
+			_, err := h.Exists(ctx)
+			return err
+		},
+		"ListBlobs": func(ctx context.Context, h *ContainerHandle) error {
+			_, err := h.ListBlobs(ctx, "")
+			return err
+		},
+		"StreamBlobs": func(ctx context.Context, h *ContainerHandle) error {
+			return h.StreamBlobs(ctx, "", func(azblob.BlobItem) error { return nil })
+		},
+		"Create": func(ctx context.Context, h *ContainerHandle) error {
+			return h.Create(ctx, azblob.PublicAccessNone, nil)
+		},
+		"Update": func(ctx context.Context, h *ContainerHandle) error {
+			return h.Update(ctx, azblob.PublicAccessNone, azblob.Metadata{"owner": "crossplane"})
+		},
+		"Delete": func(ctx context.Context, h *ContainerHandle) error {
+			return h.Delete(ctx)
+		},
+	}
+	reads := map[string]bool{"Get": true, "GetContainerProperties": true, "Exists": true, "ListBlobs": true, "StreamBlobs": true}
+
+	// The primary endpoint refuses connections, as if it did not resolve.
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	cases := map[string]struct {
+		reason   string
+		primary  http.Handler
+		fallback bool
+	}{
+		"PrimaryUnreachable": {
+			reason:   "Reads should fall back when the primary endpoint is unreachable, and writes should not.",
+			fallback: true,
+		},
+		"PrimaryFailed": {
+			reason:  "Reads should not fall back when the primary endpoint returns an error response.",
+			primary: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusInternalServerError) }),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fallbacks := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fallbacks++
+				if r.URL.Query().Get("comp") == "list" {
+					_, _ = w.Write([]byte(blobListing("")))
+				}
+			}))
+			t.Cleanup(srv.Close)
+
+			primary := down.URL
+			if tc.primary != nil {
+				p := httptest.NewServer(tc.primary)
+				t.Cleanup(p.Close)
+				primary = p.URL
+			}
+
+			s, err := NewServiceHandle(testAccount, testKey, ContainerHandleOptions{
+				Retry:            azblob.RetryOptions{MaxTries: 1},
+				FallbackEndpoint: srv.URL,
+			})
+			if err != nil {
+				t.Fatalf("\n%s\nNewServiceHandle(...): %v", tc.reason, err)
+			}
+			u, _ := url.Parse(primary)
+			s.ServiceURL = azblob.NewServiceURL(*u, s.pipeline)
+			h := s.Container(testContainer)
+
+			for op, fn := range ops {
+				fallbacks = 0
+				err := fn(context.Background(), h)
+				want := tc.fallback && reads[op]
+				if want && err != nil {
+					t.Errorf("\n%s\n%s(...): want the fallback read to succeed, got %v", tc.reason, op, err)
+				}
+				if !want && err == nil {
+					t.Errorf("\n%s\n%s(...): want an error, got none", tc.reason, op)
+				}
+				if got := fallbacks > 0; got != want {
+					t.Errorf("\n%s\n%s(...): want fallback %t, got %t", tc.reason, op, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewServiceHandleInvalidFallbackEndpoint(t *testing.T) {
+	for _, e := range []string{"secondary.example.org", "ftp://secondary.example.org", "https://"} {
+		if _, err := NewServiceHandle(testAccount, testKey, ContainerHandleOptions{FallbackEndpoint: e}); err == nil {
+			t.Errorf("NewServiceHandle(...): want error for fallback endpoint %q, got nil", e)
+		}
+	}
+}
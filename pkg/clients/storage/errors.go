@@ -0,0 +1,506 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+)
+
+// An AlreadyExistsError indicates that a container Crossplane did not create
+// already exists.
+type AlreadyExistsError struct {
+	Container string
+}
+
+func (e *AlreadyExistsError) Error() string {
+	return fmt.Sprintf("container %s already exists and was not created by Crossplane", e.Container)
+}
+
+// IsAlreadyExists returns true if the supplied error is, or wraps, an
+// AlreadyExistsError.
+func IsAlreadyExists(err error) bool {
+	e := &AlreadyExistsError{}
+	return errors.As(err, &e)
+}
+
+// IsContainerAlreadyExists returns true if the supplied error is, or wraps, a
+// blob service conflict caused by creating a container that already exists.
+// Conflicts for other reasons, such as the container being deleted, are not.
+func IsContainerAlreadyExists(err error) bool {
+	var se azblob.StorageError
+	return ClassifyStorageError(err) == ErrorClassConflict && errors.As(err, &se) && se.ServiceCode() == azblob.ServiceCodeContainerAlreadyExists
+}
+
+// IsContainerBeingDeleted returns true if the supplied error is, or wraps, a
+// blob service conflict caused by a container that is being deleted.
+func IsContainerBeingDeleted(err error) bool {
+	var se azblob.StorageError
+	return ClassifyStorageError(err) == ErrorClassConflict && errors.As(err, &se) && se.ServiceCode() == azblob.ServiceCodeContainerBeingDeleted
+}
+
+// An ExternalChangeError indicates that a container was changed outside of
+// Crossplane.
+type ExternalChangeError struct {
+	Container string
+	Changes   []string
+}
+
+func (e *ExternalChangeError) Error() string {
+	return fmt.Sprintf("container %s was changed outside of Crossplane: %s", e.Container, strings.Join(e.Changes, "; "))
+}
+
+// IsExternalChange returns true if the supplied error is, or wraps, an
+// ExternalChangeError.
+func IsExternalChange(err error) bool {
+	e := &ExternalChangeError{}
+	return errors.As(err, &e)
+}
+
+// A ForeignOwnerError indicates that a container's ownership metadata claims
+// it for a system other than Crossplane.
+type ForeignOwnerError struct {
+	Container string
+	Key       string
+	Owner     string
+}
+
+func (e *ForeignOwnerError) Error() string {
+	return fmt.Sprintf("container %s is owned by %q according to its %s metadata; refusing to change it", e.Container, e.Owner, e.Key)
+}
+
+// IsForeignOwner returns true if the supplied error is, or wraps, a
+// ForeignOwnerError.
+func IsForeignOwner(err error) bool {
+	e := &ForeignOwnerError{}
+	return errors.As(err, &e)
+}
+
+// A PublicAccessDisallowedError indicates that a container asks for public
+// access that its storage account does not allow.
+type PublicAccessDisallowedError struct {
+	Account string
+	Access  azblob.PublicAccessType
+}
+
+func (e *PublicAccessDisallowedError) Error() string {
+	return fmt.Sprintf("storage account %s disallows blob public access, so its containers cannot have public access type %s", e.Account, e.Access)
+}
+
+// IsPublicAccessDisallowed returns true if the supplied error is, or wraps, a
+// PublicAccessDisallowedError.
+func IsPublicAccessDisallowed(err error) bool {
+	e := &PublicAccessDisallowedError{}
+	return errors.As(err, &e)
+}
+
+// A PartialUpdateError indicates that an update of a container applied some
+// of its parts, but not all of them.
+type PartialUpdateError struct {
+	Result UpdateResult
+}
+
+func (e *PartialUpdateError) Error() string {
+	applied, failed := []string{}, []string{}
+	for _, p := range []struct {
+		name    string
+		applied bool
+	}{
+		{name: "metadata", applied: e.Result.MetadataApplied},
+		{name: "public access policy", applied: e.Result.AccessPolicyApplied},
+	} {
+		if p.applied {
+			applied = append(applied, p.name)
+			continue
+		}
+		failed = append(failed, p.name)
+	}
+	return fmt.Sprintf("container was partially updated: applied %s, but not %s: %v", strings.Join(applied, " and "), strings.Join(failed, " and "), e.Result.Err)
+}
+
+// Unwrap returns the error that stopped the update.
+func (e *PartialUpdateError) Unwrap() error {
+	return e.Result.Err
+}
+
+// IsPartialUpdate returns true if the supplied error is, or wraps, a
+// PartialUpdateError.
+func IsPartialUpdate(err error) bool {
+	e := &PartialUpdateError{}
+	return errors.As(err, &e)
+}
+
+// A SoftDeleteDisabledError indicates that an operation requires blob soft
+// delete, which is disabled on the storage account.
+type SoftDeleteDisabledError struct {
+	Account string
+}
+
+func (e *SoftDeleteDisabledError) Error() string {
+	return fmt.Sprintf("blob soft delete is disabled on storage account %s; enable it by setting spec.blobService.softDelete.enabled to true on the Account", e.Account)
+}
+
+// IsSoftDeleteDisabled returns true if the supplied error is, or wraps, a
+// SoftDeleteDisabledError.
+func IsSoftDeleteDisabled(err error) bool {
+	e := &SoftDeleteDisabledError{}
+	return errors.As(err, &e)
+}
+
+// An AccountSoftDeletedError indicates that the storage account of a container
+// was soft-deleted, so none of its containers can be reconciled until the
+// account is restored.
+type AccountSoftDeletedError struct {
+	Account string
+
+	// Restoring is true if a restore of the account was requested.
+	Restoring bool
+}
+
+func (e *AccountSoftDeletedError) Error() string {
+	if e.Restoring {
+		return fmt.Sprintf("storage account %s is soft-deleted; restore requested", e.Account)
+	}
+	return fmt.Sprintf("storage account %s is soft-deleted; restore required: recover the account before its containers can be reconciled", e.Account)
+}
+
+// IsAccountSoftDeleted returns true if the supplied error is, or wraps, an
+// AccountSoftDeletedError.
+func IsAccountSoftDeleted(err error) bool {
+	e := &AccountSoftDeletedError{}
+	return errors.As(err, &e)
+}
+
+// managementCodesAccountDisabled are the codes of management plane errors
+// caused by a storage account, or the subscription that holds it, that is
+// disabled.
+var managementCodesAccountDisabled = map[string]bool{
+	"AccountIsDisabled":            true,
+	"ReadOnlyDisabledSubscription": true,
+	"SubscriptionDisabled":         true,
+}
+
+// IsAccountDisabled returns true if the supplied error is, or wraps, a blob
+// service or management plane error caused by a storage account that is
+// disabled, for example because its subscription is disabled for billing.
+func IsAccountDisabled(err error) bool {
+	var se azblob.StorageError
+	if errors.As(err, &se) {
+		return se.ServiceCode() == azblob.ServiceCodeAccountIsDisabled
+	}
+	re := &azure.RequestError{}
+	return errors.As(err, &re) && re.ServiceError != nil && managementCodesAccountDisabled[re.ServiceError.Code]
+}
+
+// IsAccountUnresolvable returns true if the supplied error is, or wraps, a
+// failure to resolve the storage account's blob endpoint. The endpoint stops
+// resolving once the account is deleted, including when it is soft-deleted.
+func IsAccountUnresolvable(err error) bool {
+	var de *net.DNSError
+	return errors.As(err, &de) && de.IsNotFound
+}
+
+// IsNetworkError returns true if the supplied error is, or wraps, a failure to
+// reach the blob service, such as an endpoint that does not resolve or a
+// connection that was refused, rather than an error the service returned.
+// Errors the azblob pipeline wraps are unwrapped by their cause.
+func IsNetworkError(err error) bool {
+	for err != nil {
+		switch err.(type) {
+		case *net.DNSError, *net.OpError:
+			return true
+		}
+		if u := errors.Unwrap(err); u != nil {
+			err = u
+			continue
+		}
+		c, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+		err = c.Cause()
+	}
+	return false
+}
+
+// A SnapshotsBlockDeletionError indicates that a container could not be
+// deleted because some of its blobs have snapshots.
+type SnapshotsBlockDeletionError struct {
+	Container string
+	Snapshots []Snapshot
+}
+
+func (e *SnapshotsBlockDeletionError) Error() string {
+	blobs := snapshotBlobs(e.Snapshots)
+	return fmt.Sprintf("container %s cannot be deleted because %d blob(s) have snapshots: %s; delete the snapshots, or set spec.deleteSnapshots to true to delete them with the container", e.Container, len(blobs), strings.Join(blobs, ", "))
+}
+
+// IsSnapshotsBlockDeletion returns true if the supplied error is, or wraps, a
+// SnapshotsBlockDeletionError.
+func IsSnapshotsBlockDeletion(err error) bool {
+	e := &SnapshotsBlockDeletionError{}
+	return errors.As(err, &e)
+}
+
+// A MetadataRejectedError indicates that the blob service rejected the
+// metadata of a container as invalid, and that it is not written again until
+// it changes.
+type MetadataRejectedError struct {
+	Container string
+}
+
+func (e *MetadataRejectedError) Error() string {
+	return fmt.Sprintf("metadata of container %s was rejected and is not written again until the container's spec changes", e.Container)
+}
+
+// IsInvalidMetadata returns true if the supplied error is, or wraps, a blob
+// service error rejecting metadata as invalid, or a MetadataRejectedError.
+func IsInvalidMetadata(err error) bool {
+	var se azblob.StorageError
+	if errors.As(err, &se) && se.ServiceCode() == azblob.ServiceCodeInvalidMetadata {
+		return true
+	}
+	e := &MetadataRejectedError{}
+	return errors.As(err, &e)
+}
+
+// A Kind of blob service resource.
+type Kind string
+
+// Kinds of blob service resource.
+const (
+	KindContainer Kind = "Container"
+	KindBlob      Kind = "Blob"
+
+	// KindUnknown resources were not found, but the blob service did not say
+	// what kind of resource they were.
+	KindUnknown Kind = "Unknown"
+)
+
+// NotFoundKind returns the kind of resource the blob service did not find, and
+// true, if the supplied error is, or wraps, a not found storage error. Not
+// found errors whose service code does not identify a kind of resource are of
+// KindUnknown.
+func NotFoundKind(err error) (Kind, bool) {
+	var se azblob.StorageError
+	if !errors.As(err, &se) || se.Response() == nil || se.Response().StatusCode != http.StatusNotFound { // nolint: bodyclose
+		return "", false
+	}
+	switch se.ServiceCode() {
+	case azblob.ServiceCodeContainerNotFound:
+		return KindContainer, true
+	case azblob.ServiceCodeBlobNotFound:
+		return KindBlob, true
+	default:
+		return KindUnknown, true
+	}
+}
+
+// StatusCode returns the HTTP status code of the Azure response that caused
+// the supplied error, or 0 if the error was not caused by an Azure response.
+func StatusCode(err error) int {
+	var se azblob.StorageError
+	if errors.As(err, &se) && se.Response() != nil { // nolint: bodyclose
+		return se.Response().StatusCode // nolint: bodyclose
+	}
+	var de autorest.DetailedError
+	if errors.As(err, &de) {
+		if c, ok := de.StatusCode.(int); ok {
+			return c
+		}
+	}
+	return 0
+}
+
+// headerRequestID is the header in which Azure returns the ID of a request.
+const headerRequestID = "x-ms-request-id"
+
+// RequestID returns the Azure request ID of the request that caused the
+// supplied error, or an empty string if it has none.
+func RequestID(err error) string {
+	var se azblob.StorageError
+	if errors.As(err, &se) && se.Response() != nil { // nolint: bodyclose
+		return se.Response().Header.Get(headerRequestID) // nolint: bodyclose
+	}
+	var de autorest.DetailedError
+	if errors.As(err, &de) && de.Response != nil {
+		return de.Response.Header.Get(headerRequestID)
+	}
+	return ""
+}
+
+// RetryAfter returns how long Azure asked for the request that caused the
+// supplied error to be retried after, or zero if it did not say.
+func RetryAfter(err error) time.Duration {
+	var se azblob.StorageError
+	if errors.As(err, &se) && se.Response() != nil { // nolint: bodyclose
+		return retryAfter(se.Response(), time.Now()) // nolint: bodyclose
+	}
+	var de autorest.DetailedError
+	if errors.As(err, &de) && de.Response != nil {
+		return retryAfter(de.Response, time.Now())
+	}
+	return 0
+}
+
+// serviceCodeAuthorizationPermissionMismatch is the service code of requests
+// authorized by Azure AD whose principal lacks the RBAC data action they need.
+// The azblob SDK version we use predates it.
+const serviceCodeAuthorizationPermissionMismatch azblob.ServiceCodeType = "AuthorizationPermissionMismatch"
+
+// An ErrorClass is a broad class of storage error that an operator may want to
+// be alerted to differently.
+type ErrorClass string
+
+// Classes of storage error.
+const (
+	// ErrorClassAuthentication errors were caused by credentials that Azure
+	// did not accept.
+	ErrorClassAuthentication ErrorClass = "Authentication"
+
+	// ErrorClassAuthorization errors were caused by authenticated credentials
+	// that do not permit the operation.
+	ErrorClassAuthorization ErrorClass = "Authorization"
+
+	// ErrorClassInsufficientPermissions errors were caused by an Azure AD
+	// principal that lacks an RBAC role permitting the operation's data
+	// action. Unlike other authorization errors they are fixed by assigning
+	// the principal a role, not by changing a key or SAS.
+	ErrorClassInsufficientPermissions ErrorClass = "InsufficientPermissions"
+
+	// ErrorClassNotFound errors were caused by a resource that does not exist.
+	ErrorClassNotFound ErrorClass = "NotFound"
+
+	// ErrorClassConflict errors were caused by a resource whose state does not
+	// permit the operation, for example because it is being deleted.
+	ErrorClassConflict ErrorClass = "Conflict"
+
+	// ErrorClassInvalid errors were caused by a request Azure rejected as
+	// invalid.
+	ErrorClassInvalid ErrorClass = "Invalid"
+
+	// ErrorClassInvalidMetadata errors were caused by metadata Azure
+	// rejected as invalid, typically because of a key that is not a valid
+	// C# identifier.
+	ErrorClassInvalidMetadata ErrorClass = "InvalidMetadata"
+
+	// ErrorClassThrottled errors were caused by Azure limiting the rate of
+	// requests.
+	ErrorClassThrottled ErrorClass = "Throttled"
+
+	// ErrorClassTimeout errors were caused by an operation running out of
+	// time, or of the requests it was budgeted.
+	ErrorClassTimeout ErrorClass = "Timeout"
+
+	// ErrorClassServer errors were caused by Azure failing to serve an
+	// otherwise valid request.
+	ErrorClassServer ErrorClass = "Server"
+
+	// ErrorClassAccountSoftDeleted errors were caused by a storage account
+	// that was soft-deleted and must be restored.
+	ErrorClassAccountSoftDeleted ErrorClass = "AccountSoftDeleted"
+
+	// ErrorClassAccountDisabled errors were caused by a storage account, or
+	// its subscription, that is disabled. They are fixed by re-enabling it,
+	// typically by resolving a billing issue, not by changing configuration.
+	ErrorClassAccountDisabled ErrorClass = "AccountDisabled"
+
+	// ErrorClassNetwork errors were caused by a blob service endpoint that
+	// could not be reached, for example because it did not resolve.
+	ErrorClassNetwork ErrorClass = "Network"
+
+	// ErrorClassUnknown errors could not be classified.
+	ErrorClassUnknown ErrorClass = "Unknown"
+)
+
+// ClassifyStorageError returns the class of the supplied error, which may be
+// a blob service or a management plane error, or wrap one.
+func ClassifyStorageError(err error) ErrorClass {
+	if errors.Is(err, ErrBudgetExceeded) || errors.Is(err, ErrOperationBudgetExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	if IsAccountSoftDeleted(err) {
+		return ErrorClassAccountSoftDeleted
+	}
+	if IsAccountDisabled(err) {
+		return ErrorClassAccountDisabled
+	}
+	if IsInvalidMetadata(err) {
+		return ErrorClassInvalidMetadata
+	}
+	if IsForeignOwner(err) {
+		return ErrorClassConflict
+	}
+	if IsPublicAccessDisallowed(err) {
+		return ErrorClassInvalid
+	}
+	if IsNetworkError(err) {
+		return ErrorClassNetwork
+	}
+
+	var se azblob.StorageError
+	var tre adal.TokenRefreshError
+	switch {
+	case errors.As(err, &se):
+		switch se.ServiceCode() {
+		case azblob.ServiceCodeAuthenticationFailed:
+			return ErrorClassAuthentication
+		case azblob.ServiceCodeServerBusy:
+			return ErrorClassThrottled
+		case serviceCodeAuthorizationPermissionMismatch:
+			return ErrorClassInsufficientPermissions
+		}
+	case errors.As(err, &tre):
+		// The management plane failed to get a token before it sent a
+		// request. Blob service errors satisfy this interface too, so they
+		// must be ruled out first.
+		return ErrorClassAuthentication
+	}
+
+	c := StatusCode(err)
+	switch {
+	case c == http.StatusUnauthorized:
+		return ErrorClassAuthentication
+	case c == http.StatusForbidden:
+		return ErrorClassAuthorization
+	case c == http.StatusNotFound:
+		return ErrorClassNotFound
+	case c == http.StatusConflict, c == http.StatusPreconditionFailed:
+		return ErrorClassConflict
+	case c == http.StatusTooManyRequests, c == http.StatusServiceUnavailable:
+		// Azure Storage answers with 503 Service Unavailable when it
+		// throttles requests, whatever the error code it gives.
+		return ErrorClassThrottled
+	case c == http.StatusRequestTimeout, c == http.StatusGatewayTimeout:
+		return ErrorClassTimeout
+	case c >= http.StatusInternalServerError:
+		return ErrorClassServer
+	case c >= http.StatusBadRequest:
+		return ErrorClassInvalid
+	}
+	return ErrorClassUnknown
+}
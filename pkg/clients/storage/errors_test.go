@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// newTestStorageError returns a blob service error with the supplied status
+// code and error code.
+func newTestStorageError(status int, code azblob.ServiceCodeType) error {
+	h := http.Header{}
+	if code != "" {
+		h.Set("x-ms-error-code", string(code))
+	}
+	req := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "example.blob.core.windows.net"}, Header: http.Header{}}
+	return azblob.NewResponseError(nil, &http.Response{StatusCode: status, Header: h, Request: req}, "")
+}
+
+func TestClassifyStorageError(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   ErrorClass
+	}{
+		"ServerBusy": {
+			reason: "Azure telling us it is busy should be classified as throttling.",
+			err:    newTestStorageError(http.StatusServiceUnavailable, azblob.ServiceCodeServerBusy),
+			want:   ErrorClassThrottled,
+		},
+		"ServiceUnavailable": {
+			reason: "A service unavailable response should be classified as throttling whatever its error code.",
+			err:    newTestStorageError(http.StatusServiceUnavailable, "IngressOverLimit"),
+			want:   ErrorClassThrottled,
+		},
+		"TooManyRequests": {
+			reason: "A too many requests response should be classified as throttling.",
+			err:    newTestStorageError(http.StatusTooManyRequests, ""),
+			want:   ErrorClassThrottled,
+		},
+		"InternalError": {
+			reason: "Other server errors should be classified as server errors.",
+			err:    newTestStorageError(http.StatusInternalServerError, azblob.ServiceCodeInternalError),
+			want:   ErrorClassServer,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := ClassifyStorageError(tc.err); got != tc.want {
+				t.Errorf("\n%s\nClassifyStorageError(...): want %s, got %s", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
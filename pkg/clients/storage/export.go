@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// A ContainerConfig is the exported configuration of a container. Its fields
+// use the names of the Container resource's spec, so that an export can be
+// re-applied to recreate the container.
+type ContainerConfig struct {
+	PublicAccessType               azblob.PublicAccessType `json:"publicAccessType,omitempty"`
+	Metadata                       azblob.Metadata         `json:"metadata,omitempty"`
+	DefaultEncryptionScope         string                  `json:"defaultEncryptionScope,omitempty"`
+	PreventEncryptionScopeOverride bool                    `json:"preventEncryptionScopeOverride,omitempty"`
+	AccessPolicies                 []ContainerAccessPolicy `json:"accessPolicies,omitempty"`
+}
+
+// A ContainerAccessPolicy is an exported stored access policy.
+type ContainerAccessPolicy struct {
+	ID         string    `json:"id"`
+	Start      time.Time `json:"start"`
+	Expiry     time.Time `json:"expiry"`
+	Permission string    `json:"permission"`
+}
+
+// ExportConfig returns the container's live configuration as a YAML document.
+// Map keys are sorted and access policies are ordered by ID, so exports of an
+// unchanged container are identical and may be diffed.
+func (a *ContainerHandle) ExportConfig(ctx context.Context) ([]byte, error) {
+	s, err := a.Import(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read container configuration")
+	}
+	b, err := yaml.Marshal(NewContainerConfig(s))
+	return b, errors.Wrap(err, "cannot marshal container configuration")
+}
+
+// NewContainerConfig returns the exportable configuration of the supplied
+// container snapshot.
+func NewContainerConfig(s ContainerSnapshot) ContainerConfig {
+	cfg := ContainerConfig{
+		PublicAccessType:               s.PublicAccessType,
+		Metadata:                       s.Metadata,
+		PreventEncryptionScopeOverride: s.PreventEncryptionScopeOverride,
+	}
+	if !IsAccountEncryptionScope(s.DefaultEncryptionScope) {
+		cfg.DefaultEncryptionScope = s.DefaultEncryptionScope
+	}
+	for _, si := range s.SignedIdentifiers {
+		cfg.AccessPolicies = append(cfg.AccessPolicies, ContainerAccessPolicy{
+			ID:         si.ID,
+			Start:      si.AccessPolicy.Start.UTC(),
+			Expiry:     si.AccessPolicy.Expiry.UTC(),
+			Permission: si.AccessPolicy.Permission,
+		})
+	}
+	sort.Slice(cfg.AccessPolicies, func(i, j int) bool { return cfg.AccessPolicies[i].ID < cfg.AccessPolicies[j].ID })
+	return cfg
+}
+
+// ParseContainerConfig parses a container configuration exported by
+// ExportConfig.
+func ParseContainerConfig(b []byte) (ContainerConfig, error) {
+	cfg := ContainerConfig{}
+	err := yaml.UnmarshalStrict(b, &cfg)
+	return cfg, errors.Wrap(err, "cannot parse container configuration")
+}
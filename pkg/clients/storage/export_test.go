@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExportConfig(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	acl := `<?xml version="1.0" encoding="utf-8"?><SignedIdentifiers>` +
+		`<SignedIdentifier><Id>write</Id><AccessPolicy><Start>2022-06-01T00:00:00.0000000Z</Start><Permission>w</Permission></AccessPolicy></SignedIdentifier>` +
+		`<SignedIdentifier><Id>read</Id><AccessPolicy><Start>2022-06-01T00:00:00.0000000Z</Start><Permission>r</Permission></AccessPolicy></SignedIdentifier>` +
+		`</SignedIdentifiers>`
+
+	type want struct {
+		cfg ContainerConfig
+		err bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		scope  string
+		want   want
+	}{
+		"Exported": {
+			reason: "An exported configuration should parse back to the container's live configuration.",
+			status: http.StatusOK,
+			scope:  "cmk",
+			want: want{
+				cfg: ContainerConfig{
+					PublicAccessType:               azblob.PublicAccessBlob,
+					Metadata:                       azblob.Metadata{"owner": "someone", "team": "storage"},
+					DefaultEncryptionScope:         "cmk",
+					PreventEncryptionScopeOverride: true,
+					AccessPolicies: []ContainerAccessPolicy{
+						{ID: "read", Start: start, Permission: "r"},
+						{ID: "write", Start: start, Permission: "w"},
+					},
+				},
+			},
+		},
+		"AccountEncryptionScope": {
+			reason: "The account's implicit encryption scope should not be exported.",
+			status: http.StatusOK,
+			scope:  AccountEncryptionScope,
+			want: want{
+				cfg: ContainerConfig{
+					PublicAccessType:               azblob.PublicAccessBlob,
+					Metadata:                       azblob.Metadata{"owner": "someone", "team": "storage"},
+					PreventEncryptionScopeOverride: true,
+					AccessPolicies: []ContainerAccessPolicy{
+						{ID: "read", Start: start, Permission: "r"},
+						{ID: "write", Start: start, Permission: "w"},
+					},
+				},
+			},
+		},
+		"NotFound": {
+			reason: "Errors reading the container should be returned.",
+			status: http.StatusNotFound,
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.status != http.StatusOK {
+					w.WriteHeader(tc.status)
+					return
+				}
+				w.Header().Set(headerBlobPublicAccess, "blob")
+				if r.URL.Query().Get("comp") == "acl" {
+					fmt.Fprint(w, acl)
+					return
+				}
+				w.Header().Set("x-ms-meta-Team", "storage")
+				w.Header().Set("x-ms-meta-Owner", "someone")
+				w.Header().Set(headerDefaultEncryptionScope, tc.scope)
+				w.Header().Set(headerDenyEncryptionScopeOverride, "true")
+			}))
+
+			b, err := h.ExportConfig(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Fatalf("\n%s\nExportConfig(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if tc.want.err {
+				return
+			}
+			got, err := ParseContainerConfig(b)
+			if err != nil {
+				t.Fatalf("\n%s\nParseContainerConfig(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.cfg, got); diff != "" {
+				t.Errorf("\n%s\nExportConfig(...): -want, +got:\n%s", tc.reason, diff)
+			}
+
+			again, err := h.ExportConfig(context.Background())
+			if err != nil {
+				t.Fatalf("\n%s\nExportConfig(...): %v", tc.reason, err)
+			}
+			if !bytes.Equal(b, again) {
+				t.Errorf("\n%s\nExportConfig(...): want identical exports, got:\n%s\n%s", tc.reason, b, again)
+			}
+		})
+	}
+}
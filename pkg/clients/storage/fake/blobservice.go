@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+
+	azurestorage "github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+// MockBlobServiceOperations mock implementation of BlobServiceOperations
+type MockBlobServiceOperations struct {
+	MockGetMetricsConfig  func(ctx context.Context) (hour, minute azurestorage.MetricsProperties, err error)
+	MockSetMetricsConfig  func(ctx context.Context, hour, minute azurestorage.MetricsProperties) error
+	MockGetLoggingConfig  func(ctx context.Context) (azurestorage.LoggingProperties, error)
+	MockSetLoggingConfig  func(ctx context.Context, p azurestorage.LoggingProperties) error
+	MockGetBlobSoftDelete func(ctx context.Context) (bool, int32, error)
+	MockSetBlobSoftDelete func(ctx context.Context, enabled bool, days int32) error
+
+	MockGetMetadataForContainers func(ctx context.Context, names []string) (map[string]azblob.Metadata, map[string]error)
+}
+
+var _ azurestorage.BlobServiceOperations = &MockBlobServiceOperations{}
+
+// GetMetricsConfig mock get metrics config function
+func (m *MockBlobServiceOperations) GetMetricsConfig(ctx context.Context) (hour, minute azurestorage.MetricsProperties, err error) {
+	return m.MockGetMetricsConfig(ctx)
+}
+
+// SetMetricsConfig mock set metrics config function
+func (m *MockBlobServiceOperations) SetMetricsConfig(ctx context.Context, hour, minute azurestorage.MetricsProperties) error {
+	return m.MockSetMetricsConfig(ctx, hour, minute)
+}
+
+// GetLoggingConfig mock get logging config function
+func (m *MockBlobServiceOperations) GetLoggingConfig(ctx context.Context) (azurestorage.LoggingProperties, error) {
+	return m.MockGetLoggingConfig(ctx)
+}
+
+// SetLoggingConfig mock set logging config function
+func (m *MockBlobServiceOperations) SetLoggingConfig(ctx context.Context, p azurestorage.LoggingProperties) error {
+	return m.MockSetLoggingConfig(ctx, p)
+}
+
+// GetBlobSoftDelete mock get blob soft delete function
+func (m *MockBlobServiceOperations) GetBlobSoftDelete(ctx context.Context) (bool, int32, error) {
+	return m.MockGetBlobSoftDelete(ctx)
+}
+
+// SetBlobSoftDelete mock set blob soft delete function
+func (m *MockBlobServiceOperations) SetBlobSoftDelete(ctx context.Context, enabled bool, days int32) error {
+	return m.MockSetBlobSoftDelete(ctx, enabled, days)
+}
+
+// GetMetadataForContainers mock get metadata for containers function
+func (m *MockBlobServiceOperations) GetMetadataForContainers(ctx context.Context, names []string) (map[string]azblob.Metadata, map[string]error) {
+	return m.MockGetMetadataForContainers(ctx, names)
+}
@@ -18,6 +18,7 @@ package fake
 
 import (
 	"context"
+	"time"
 
 	"github.com/Azure/azure-storage-blob-go/azblob"
 
@@ -30,6 +31,24 @@ type MockContainerOperations struct {
 	MockUpdate func(context.Context, azblob.PublicAccessType, azblob.Metadata) error
 	MockGet    func(ctx context.Context) (*azblob.PublicAccessType, azblob.Metadata, error)
 	MockDelete func(ctx context.Context) error
+
+	MockDeleteWithVersions func(ctx context.Context) error
+
+	// MockUpdatePartial is called by UpdatePartial. UpdatePartial calls
+	// MockUpdate with the parts to update instead when it is nil, treating
+	// parts that are not updated as empty.
+	MockUpdatePartial func(context.Context, *azblob.PublicAccessType, *azblob.Metadata) azurestorage.UpdateResult
+
+	MockGetContainerProperties func(ctx context.Context) (*azurestorage.ContainerProperties, error)
+	MockExists                 func(ctx context.Context) (bool, error)
+	MockWaitUntilExists        func(ctx context.Context, timeout time.Duration) error
+	MockImport                 func(ctx context.Context) (azurestorage.ContainerSnapshot, error)
+
+	MockListSnapshots            func(ctx context.Context, prefix string) ([]azurestorage.Snapshot, error)
+	MockDeleteBlobsWithSnapshots func(ctx context.Context, snapshots []azurestorage.Snapshot) error
+	MockArchiveBlobs             func(ctx context.Context, archive string) error
+	MockGetStoredAccessPolicies  func(ctx context.Context) ([]azblob.SignedIdentifier, error)
+	MockSetStoredAccessPolicies  func(ctx context.Context, pat azblob.PublicAccessType, policies []azblob.SignedIdentifier) error
 }
 
 var _ azurestorage.ContainerOperations = &MockContainerOperations{}
@@ -49,6 +68,36 @@ func NewMockContainerOperations() *MockContainerOperations {
 		MockDelete: func(ctx context.Context) error {
 			return nil
 		},
+		MockDeleteWithVersions: func(ctx context.Context) error {
+			return nil
+		},
+		MockGetContainerProperties: func(ctx context.Context) (*azurestorage.ContainerProperties, error) {
+			return &azurestorage.ContainerProperties{}, nil
+		},
+		MockExists: func(ctx context.Context) (bool, error) {
+			return false, nil
+		},
+		MockWaitUntilExists: func(ctx context.Context, timeout time.Duration) error {
+			return nil
+		},
+		MockImport: func(ctx context.Context) (azurestorage.ContainerSnapshot, error) {
+			return azurestorage.ContainerSnapshot{}, nil
+		},
+		MockListSnapshots: func(ctx context.Context, prefix string) ([]azurestorage.Snapshot, error) {
+			return nil, nil
+		},
+		MockDeleteBlobsWithSnapshots: func(ctx context.Context, snapshots []azurestorage.Snapshot) error {
+			return nil
+		},
+		MockArchiveBlobs: func(ctx context.Context, archive string) error {
+			return nil
+		},
+		MockGetStoredAccessPolicies: func(ctx context.Context) ([]azblob.SignedIdentifier, error) {
+			return nil, nil
+		},
+		MockSetStoredAccessPolicies: func(ctx context.Context, pat azblob.PublicAccessType, policies []azblob.SignedIdentifier) error {
+			return nil
+		},
 	}
 }
 
@@ -62,16 +111,85 @@ func (m *MockContainerOperations) Update(ctx context.Context, pat azblob.PublicA
 	return m.MockUpdate(ctx, pat, meta)
 }
 
+// UpdatePartial mock partial update function
+func (m *MockContainerOperations) UpdatePartial(ctx context.Context, pat *azblob.PublicAccessType, meta *azblob.Metadata) azurestorage.UpdateResult {
+	if m.MockUpdatePartial != nil {
+		return m.MockUpdatePartial(ctx, pat, meta)
+	}
+	var a azblob.PublicAccessType
+	if pat != nil {
+		a = *pat
+	}
+	var md azblob.Metadata
+	if meta != nil {
+		md = *meta
+	}
+	if err := m.MockUpdate(ctx, a, md); err != nil {
+		return azurestorage.UpdateResult{Err: err}
+	}
+	return azurestorage.UpdateResult{MetadataApplied: meta != nil, AccessPolicyApplied: pat != nil}
+}
+
 // Get mock get function
 func (m *MockContainerOperations) Get(ctx context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
 	return m.MockGet(ctx)
 }
 
+// GetContainerProperties mock get container properties function
+func (m *MockContainerOperations) GetContainerProperties(ctx context.Context) (*azurestorage.ContainerProperties, error) {
+	return m.MockGetContainerProperties(ctx)
+}
+
+// Exists mock exists function
+func (m *MockContainerOperations) Exists(ctx context.Context) (bool, error) {
+	return m.MockExists(ctx)
+}
+
+// WaitUntilExists mock wait until exists function
+func (m *MockContainerOperations) WaitUntilExists(ctx context.Context, timeout time.Duration) error {
+	return m.MockWaitUntilExists(ctx, timeout)
+}
+
+// Import mock import function
+func (m *MockContainerOperations) Import(ctx context.Context) (azurestorage.ContainerSnapshot, error) {
+	return m.MockImport(ctx)
+}
+
+// ListSnapshots mock list snapshots function
+func (m *MockContainerOperations) ListSnapshots(ctx context.Context, prefix string) ([]azurestorage.Snapshot, error) {
+	return m.MockListSnapshots(ctx, prefix)
+}
+
+// DeleteBlobsWithSnapshots mock delete blobs with snapshots function
+func (m *MockContainerOperations) DeleteBlobsWithSnapshots(ctx context.Context, snapshots []azurestorage.Snapshot) error {
+	return m.MockDeleteBlobsWithSnapshots(ctx, snapshots)
+}
+
+// ArchiveBlobs mock archive blobs function
+func (m *MockContainerOperations) ArchiveBlobs(ctx context.Context, archive string) error {
+	return m.MockArchiveBlobs(ctx, archive)
+}
+
+// GetStoredAccessPolicies mock get stored access policies function
+func (m *MockContainerOperations) GetStoredAccessPolicies(ctx context.Context) ([]azblob.SignedIdentifier, error) {
+	return m.MockGetStoredAccessPolicies(ctx)
+}
+
+// SetStoredAccessPolicies mock set stored access policies function
+func (m *MockContainerOperations) SetStoredAccessPolicies(ctx context.Context, pat azblob.PublicAccessType, policies []azblob.SignedIdentifier) error {
+	return m.MockSetStoredAccessPolicies(ctx, pat, policies)
+}
+
 // Delete mock delete function
 func (m *MockContainerOperations) Delete(ctx context.Context) error {
 	return m.MockDelete(ctx)
 }
 
+// DeleteWithVersions mock delete with versions function
+func (m *MockContainerOperations) DeleteWithVersions(ctx context.Context) error {
+	return m.MockDeleteWithVersions(ctx)
+}
+
 // PublicAccessTypePtr returns pointer of the PublicAccessType value
 func PublicAccessTypePtr(pab azblob.PublicAccessType) *azblob.PublicAccessType {
 	return &pab
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+
+	azurestorage "github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+// MockManagementOperations mock implementation of ManagementOperations
+type MockManagementOperations struct {
+	MockGetAccount                  func(ctx context.Context) (*storage.Account, error)
+	MockGetEncryptionScope          func(ctx context.Context, name string) (*storage.EncryptionScope, error)
+	MockSetContainerEncryptionScope func(ctx context.Context, container, scope string, preventOverride bool) error
+	MockEnsureEncryptionScope       func(ctx context.Context, resourceGroup, accountName, scopeName, keyVaultKeyURI string) error
+	MockInitiateFailover            func(ctx context.Context, resourceGroup, accountName string) error
+	MockGetFailoverState            func(ctx context.Context, resourceGroup, accountName string) (azurestorage.FailoverState, error)
+	MockGetDefaultToOAuth           func(ctx context.Context) (bool, error)
+	MockSetDefaultToOAuth           func(ctx context.Context, enabled bool) error
+	MockGetAllowSharedKeyAccess     func(ctx context.Context) (bool, error)
+	MockSetAllowSharedKeyAccess     func(ctx context.Context, allowed bool) error
+	MockGetAllowBlobPublicAccess    func(ctx context.Context) (bool, error)
+	MockGetMinimumTLSVersion        func(ctx context.Context) (string, error)
+	MockSetMinimumTLSVersion        func(ctx context.Context, version string) error
+	MockGetEnableHTTPSTrafficOnly   func(ctx context.Context) (bool, error)
+	MockSetEnableHTTPSTrafficOnly   func(ctx context.Context, enabled bool) error
+	MockGetDeletedAccount           func(ctx context.Context, location string) (*storage.DeletedAccount, error)
+	MockRestoreDeletedAccount       func(ctx context.Context, location string) error
+
+	MockGetContainerImmutability          func(ctx context.Context, container string) (azurestorage.ContainerImmutability, error)
+	MockSetContainerImmutabilityPolicy    func(ctx context.Context, container string, p azurestorage.ContainerImmutabilityPolicy) (string, error)
+	MockExtendContainerImmutabilityPolicy func(ctx context.Context, container string, p azurestorage.ContainerImmutabilityPolicy) (string, error)
+	MockLockContainerImmutabilityPolicy   func(ctx context.Context, container, etag string) error
+	MockSetContainerLegalHold             func(ctx context.Context, container string, tags []string) error
+	MockClearContainerLegalHold           func(ctx context.Context, container string, tags []string) error
+	MockGetBlobVersioning                 func(ctx context.Context) (bool, error)
+}
+
+var _ azurestorage.ManagementOperations = &MockManagementOperations{}
+
+// GetAccount mock get account
+func (m *MockManagementOperations) GetAccount(ctx context.Context) (*storage.Account, error) {
+	return m.MockGetAccount(ctx)
+}
+
+// GetEncryptionScope mock get encryption scope
+func (m *MockManagementOperations) GetEncryptionScope(ctx context.Context, name string) (*storage.EncryptionScope, error) {
+	return m.MockGetEncryptionScope(ctx, name)
+}
+
+// SetContainerEncryptionScope mock set container encryption scope
+func (m *MockManagementOperations) SetContainerEncryptionScope(ctx context.Context, container, scope string, preventOverride bool) error {
+	return m.MockSetContainerEncryptionScope(ctx, container, scope, preventOverride)
+}
+
+// EnsureEncryptionScope mock ensure encryption scope
+func (m *MockManagementOperations) EnsureEncryptionScope(ctx context.Context, resourceGroup, accountName, scopeName, keyVaultKeyURI string) error {
+	return m.MockEnsureEncryptionScope(ctx, resourceGroup, accountName, scopeName, keyVaultKeyURI)
+}
+
+// InitiateFailover mock initiate failover
+func (m *MockManagementOperations) InitiateFailover(ctx context.Context, resourceGroup, accountName string) error {
+	return m.MockInitiateFailover(ctx, resourceGroup, accountName)
+}
+
+// GetFailoverState mock get failover state
+func (m *MockManagementOperations) GetFailoverState(ctx context.Context, resourceGroup, accountName string) (azurestorage.FailoverState, error) {
+	return m.MockGetFailoverState(ctx, resourceGroup, accountName)
+}
+
+// GetDefaultToOAuth mock get default to OAuth
+func (m *MockManagementOperations) GetDefaultToOAuth(ctx context.Context) (bool, error) {
+	return m.MockGetDefaultToOAuth(ctx)
+}
+
+// SetDefaultToOAuth mock set default to OAuth
+func (m *MockManagementOperations) SetDefaultToOAuth(ctx context.Context, enabled bool) error {
+	return m.MockSetDefaultToOAuth(ctx, enabled)
+}
+
+// GetAllowSharedKeyAccess mock get allow shared key access
+func (m *MockManagementOperations) GetAllowSharedKeyAccess(ctx context.Context) (bool, error) {
+	return m.MockGetAllowSharedKeyAccess(ctx)
+}
+
+// SetAllowSharedKeyAccess mock set allow shared key access
+func (m *MockManagementOperations) SetAllowSharedKeyAccess(ctx context.Context, allowed bool) error {
+	return m.MockSetAllowSharedKeyAccess(ctx, allowed)
+}
+
+// GetAllowBlobPublicAccess mock get allow blob public access
+func (m *MockManagementOperations) GetAllowBlobPublicAccess(ctx context.Context) (bool, error) {
+	return m.MockGetAllowBlobPublicAccess(ctx)
+}
+
+// GetMinimumTLSVersion mock get minimum TLS version
+func (m *MockManagementOperations) GetMinimumTLSVersion(ctx context.Context) (string, error) {
+	return m.MockGetMinimumTLSVersion(ctx)
+}
+
+// SetMinimumTLSVersion mock set minimum TLS version
+func (m *MockManagementOperations) SetMinimumTLSVersion(ctx context.Context, version string) error {
+	return m.MockSetMinimumTLSVersion(ctx, version)
+}
+
+// GetEnableHTTPSTrafficOnly mock get enable HTTPS traffic only
+func (m *MockManagementOperations) GetEnableHTTPSTrafficOnly(ctx context.Context) (bool, error) {
+	return m.MockGetEnableHTTPSTrafficOnly(ctx)
+}
+
+// SetEnableHTTPSTrafficOnly mock set enable HTTPS traffic only
+func (m *MockManagementOperations) SetEnableHTTPSTrafficOnly(ctx context.Context, enabled bool) error {
+	return m.MockSetEnableHTTPSTrafficOnly(ctx, enabled)
+}
+
+// GetDeletedAccount mock get deleted account
+func (m *MockManagementOperations) GetDeletedAccount(ctx context.Context, location string) (*storage.DeletedAccount, error) {
+	return m.MockGetDeletedAccount(ctx, location)
+}
+
+// RestoreDeletedAccount mock restore deleted account
+func (m *MockManagementOperations) RestoreDeletedAccount(ctx context.Context, location string) error {
+	return m.MockRestoreDeletedAccount(ctx, location)
+}
+
+// GetContainerImmutability mock get container immutability
+func (m *MockManagementOperations) GetContainerImmutability(ctx context.Context, container string) (azurestorage.ContainerImmutability, error) {
+	return m.MockGetContainerImmutability(ctx, container)
+}
+
+// SetContainerImmutabilityPolicy mock set container immutability policy
+func (m *MockManagementOperations) SetContainerImmutabilityPolicy(ctx context.Context, container string, p azurestorage.ContainerImmutabilityPolicy) (string, error) {
+	return m.MockSetContainerImmutabilityPolicy(ctx, container, p)
+}
+
+// ExtendContainerImmutabilityPolicy mock extend container immutability policy
+func (m *MockManagementOperations) ExtendContainerImmutabilityPolicy(ctx context.Context, container string, p azurestorage.ContainerImmutabilityPolicy) (string, error) {
+	return m.MockExtendContainerImmutabilityPolicy(ctx, container, p)
+}
+
+// LockContainerImmutabilityPolicy mock lock container immutability policy
+func (m *MockManagementOperations) LockContainerImmutabilityPolicy(ctx context.Context, container, etag string) error {
+	return m.MockLockContainerImmutabilityPolicy(ctx, container, etag)
+}
+
+// SetContainerLegalHold mock set container legal hold
+func (m *MockManagementOperations) SetContainerLegalHold(ctx context.Context, container string, tags []string) error {
+	return m.MockSetContainerLegalHold(ctx, container, tags)
+}
+
+// ClearContainerLegalHold mock clear container legal hold
+func (m *MockManagementOperations) ClearContainerLegalHold(ctx context.Context, container string, tags []string) error {
+	return m.MockClearContainerLegalHold(ctx, container, tags)
+}
+
+// GetBlobVersioning mock get blob versioning
+func (m *MockManagementOperations) GetBlobVersioning(ctx context.Context) (bool, error) {
+	return m.MockGetBlobVersioning(ctx)
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage/storageapi"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+var _ storageapi.ManagementPoliciesClientAPI = &MockManagementPoliciesClient{}
+
+// MockManagementPoliciesClient is a fake implementation of
+// storage.ManagementPoliciesClient.
+type MockManagementPoliciesClient struct {
+	storageapi.ManagementPoliciesClientAPI
+
+	MockCreateOrUpdate func(ctx context.Context, resourceGroupName string, accountName string, properties storage.ManagementPolicy) (result storage.ManagementPolicy, err error)
+	MockDelete         func(ctx context.Context, resourceGroupName string, accountName string) (result autorest.Response, err error)
+	MockGet            func(ctx context.Context, resourceGroupName string, accountName string) (result storage.ManagementPolicy, err error)
+}
+
+// CreateOrUpdate calls the MockManagementPoliciesClient's MockCreateOrUpdate method.
+func (c *MockManagementPoliciesClient) CreateOrUpdate(ctx context.Context, resourceGroupName string, accountName string, properties storage.ManagementPolicy) (result storage.ManagementPolicy, err error) {
+	return c.MockCreateOrUpdate(ctx, resourceGroupName, accountName, properties)
+}
+
+// Delete calls the MockManagementPoliciesClient's MockDelete method.
+func (c *MockManagementPoliciesClient) Delete(ctx context.Context, resourceGroupName string, accountName string) (result autorest.Response, err error) {
+	return c.MockDelete(ctx, resourceGroupName, accountName)
+}
+
+// Get calls the MockManagementPoliciesClient's MockGet method.
+func (c *MockManagementPoliciesClient) Get(ctx context.Context, resourceGroupName string, accountName string) (result storage.ManagementPolicy, err error) {
+	return c.MockGet(ctx, resourceGroupName, accountName)
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+// A ContainerSnapshot is the full observed configuration of a container.
+type ContainerSnapshot struct {
+	ContainerProperties
+
+	// SignedIdentifiers are the container's stored access policies.
+	SignedIdentifiers []azblob.SignedIdentifier
+}
+
+// Import returns a snapshot of the container's configuration, from which a
+// container that was created outside of Crossplane may be adopted. It only
+// reads the container.
+func (a *ContainerHandle) Import(ctx context.Context) (ContainerSnapshot, error) {
+	p, err := a.GetContainerProperties(ctx)
+	if err != nil {
+		return ContainerSnapshot{}, errors.Wrap(err, "cannot get container properties")
+	}
+	ap, err := a.GetAccessPolicy(ctx, azblob.LeaseAccessConditions{})
+	if err != nil {
+		return ContainerSnapshot{}, errors.Wrap(err, "cannot get container access policy")
+	}
+	return ContainerSnapshot{ContainerProperties: *p, SignedIdentifiers: ap.Items}, nil
+}
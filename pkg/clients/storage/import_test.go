@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestImport(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	acl := `<?xml version="1.0" encoding="utf-8"?><SignedIdentifiers><SignedIdentifier><Id>read</Id>` +
+		`<AccessPolicy><Start>2022-06-01T00:00:00.0000000Z</Start><Permission>r</Permission></AccessPolicy>` +
+		`</SignedIdentifier></SignedIdentifiers>`
+
+	type want struct {
+		snap ContainerSnapshot
+		err  bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		want   want
+	}{
+		"Imported": {
+			reason: "Every observable field of the container should be imported.",
+			status: http.StatusOK,
+			want: want{
+				snap: ContainerSnapshot{
+					ContainerProperties: ContainerProperties{
+						PublicAccessType:               azblob.PublicAccessBlob,
+						Metadata:                       azblob.Metadata{"owner": "someone"},
+						ETag:                           azblob.ETag(`"0x1"`),
+						LastModified:                   start,
+						DefaultEncryptionScope:         "cmk",
+						PreventEncryptionScopeOverride: true,
+					},
+					SignedIdentifiers: []azblob.SignedIdentifier{{
+						ID:           "read",
+						AccessPolicy: azblob.AccessPolicy{Start: start, Permission: "r"},
+					}},
+				},
+			},
+		},
+		"NotFound": {
+			reason: "Errors reading the container should be returned.",
+			status: http.StatusNotFound,
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := &recorder{}
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rec.record(r)
+				if tc.status != http.StatusOK {
+					w.WriteHeader(tc.status)
+					return
+				}
+				w.Header().Set(headerBlobPublicAccess, "blob")
+				w.Header().Set("ETag", `"0x1"`)
+				w.Header().Set("Last-Modified", start.Format(time.RFC1123))
+				if r.URL.Query().Get("comp") == "acl" {
+					fmt.Fprint(w, acl)
+					return
+				}
+				w.Header().Set("x-ms-meta-Owner", "someone")
+				w.Header().Set(headerDefaultEncryptionScope, "cmk")
+				w.Header().Set(headerDenyEncryptionScopeOverride, "true")
+			}))
+
+			got, err := h.Import(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nImport(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.snap, got); diff != "" {
+				t.Errorf("\n%s\nImport(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			for _, req := range rec.requests() {
+				if !strings.HasPrefix(req, http.MethodGet+" ") {
+					t.Errorf("\n%s\nImport(...): want only reads, got %s", tc.reason, req)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2017-06-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+)
+
+// DefaultAccountKeyTTL is how long account keys are cached by default.
+const DefaultAccountKeyTTL = 5 * time.Minute
+
+// keyFetchTimeout bounds how long a shared fetch of an account's key may take.
+const keyFetchTimeout = 1 * time.Minute
+
+// An AccountKeyFetcher fetches the key of a storage account from the
+// management plane.
+type AccountKeyFetcher func(ctx context.Context) (string, error)
+
+// PrimaryAccountKey returns an AccountKeyFetcher that fetches the first key
+// of the supplied storage account.
+func PrimaryAccountKey(ao AccountOperations) AccountKeyFetcher {
+	return func(ctx context.Context) (string, error) {
+		keys, err := ao.ListKeys(ctx)
+		if err != nil {
+			return "", err
+		}
+		if len(keys) == 0 {
+			return "", errors.New("account keys are empty")
+		}
+		return to.String(keys[0].Value), nil
+	}
+}
+
+// An AccountKeyProvider caches the key of each storage account for a time to
+// live, so that the management plane, which rate limits listing keys, is not
+// asked for them every time they are used. Concurrent lookups of the same
+// account's key are coalesced into one fetch. A nil provider caches nothing.
+type AccountKeyProvider struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	keys     map[string]cachedKey
+	inflight map[string]*keyLookup
+}
+
+type cachedKey struct {
+	key     string
+	expires time.Time
+}
+
+// A keyLookup is a fetch of an account's key that concurrent lookups wait on.
+type keyLookup struct {
+	done chan struct{}
+	key  string
+	err  error
+}
+
+// NewAccountKeyProvider returns an AccountKeyProvider that caches keys for the
+// supplied time to live. Keys are not cached if it is not positive.
+func NewAccountKeyProvider(ttl time.Duration) *AccountKeyProvider {
+	return &AccountKeyProvider{ttl: ttl, now: time.Now}
+}
+
+// Key returns the key of the named storage account. A cached key is returned
+// until it expires, after which the key is fetched again using the supplied
+// fetcher. Lookups that find a fetch of the account's key in progress wait
+// for its result rather than fetching it again. Failed fetches are not cached.
+func (p *AccountKeyProvider) Key(ctx context.Context, account string, fetch AccountKeyFetcher) (string, error) {
+	if p == nil || p.ttl <= 0 {
+		return fetch(ctx)
+	}
+
+	p.mu.Lock()
+	if k, ok := p.keys[account]; ok && p.now().Before(k.expires) {
+		p.mu.Unlock()
+		return k.key, nil
+	}
+	l, ok := p.inflight[account]
+	if !ok {
+		l = &keyLookup{done: make(chan struct{})}
+		if p.inflight == nil {
+			p.inflight = map[string]*keyLookup{}
+		}
+		p.inflight[account] = l
+		go p.fetch(account, l, fetch)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-l.done:
+		return l.key, l.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// fetch fetches the key of the named account for the supplied lookup. Every
+// lookup waiting for the key shares the fetch, so it is made with a context
+// of its own rather than that of the lookup that started it, which could be
+// cancelled while the others still wait.
+func (p *AccountKeyProvider) fetch(account string, l *keyLookup, fetch AccountKeyFetcher) {
+	ctx, cancel := context.WithTimeout(context.Background(), keyFetchTimeout)
+	defer cancel()
+	l.key, l.err = fetch(ctx)
+
+	p.mu.Lock()
+	delete(p.inflight, account)
+	if l.err == nil {
+		if p.keys == nil {
+			p.keys = map[string]cachedKey{}
+		}
+		p.keys[account] = cachedKey{key: l.key, expires: p.now().Add(p.ttl)}
+	}
+	p.mu.Unlock()
+	close(l.done)
+}
+
+// Invalidate forgets the cached key of the named storage account if the
+// supplied error was caused by Azure not accepting the account's credentials,
+// for example because its keys were rotated. It returns true if the key was
+// forgotten, in which case the next lookup fetches it again.
+func (p *AccountKeyProvider) Invalidate(account string, err error) bool {
+	if p == nil || ClassifyStorageError(err) != ErrorClassAuthentication {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.keys[account]; !ok {
+		return false
+	}
+	delete(p.keys, account)
+	return true
+}
+
+// KeyCachingAccountOperations wraps AccountOperations, caching the primary key
+// of the storage account in an AccountKeyProvider.
+type KeyCachingAccountOperations struct {
+	AccountOperations
+	keys    *AccountKeyProvider
+	account string
+}
+
+var _ AccountOperations = &KeyCachingAccountOperations{}
+
+// NewKeyCachingAccountOperations returns AccountOperations that cache the
+// primary key of the named storage account in the supplied provider.
+func NewKeyCachingAccountOperations(ao AccountOperations, keys *AccountKeyProvider, account string) *KeyCachingAccountOperations {
+	return &KeyCachingAccountOperations{AccountOperations: ao, keys: keys, account: account}
+}
+
+// ListKeys returns the primary key of the storage account, which may have
+// been cached. Only the primary key is returned.
+func (o *KeyCachingAccountOperations) ListKeys(ctx context.Context) ([]storage.AccountKey, error) {
+	k, err := o.keys.Key(ctx, o.account, PrimaryAccountKey(o.AccountOperations))
+	if err != nil {
+		return nil, err
+	}
+	return []storage.AccountKey{{Value: to.StringPtr(k)}}, nil
+}
+
+// InvalidateKey forgets the cached key of the storage account if the supplied
+// error was caused by Azure not accepting it. It returns true if the key was
+// forgotten.
+func (o *KeyCachingAccountOperations) InvalidateKey(err error) bool {
+	return o.keys.Invalidate(o.account, err)
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+// countingFetcher returns an AccountKeyFetcher that returns the supplied key
+// and counts how many times it was called.
+func countingFetcher(key string, calls *int32) AccountKeyFetcher {
+	return func(ctx context.Context) (string, error) {
+		atomic.AddInt32(calls, 1)
+		return key, nil
+	}
+}
+
+func newAuthenticationError() error {
+	h := http.Header{}
+	h.Set("x-ms-error-code", string(azblob.ServiceCodeAuthenticationFailed))
+	r := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "example.blob.core.windows.net"}, Header: http.Header{}}
+	return azblob.NewResponseError(nil, &http.Response{StatusCode: http.StatusForbidden, Header: h, Request: r}, "")
+}
+
+func TestAccountKeyProviderCacheHit(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		ttl    time.Duration
+		want   int32
+	}{
+		"Cached": {
+			reason: "A key should be fetched once and then served from the cache.",
+			ttl:    time.Minute,
+			want:   1,
+		},
+		"CachingDisabled": {
+			reason: "A key should be fetched every time it is looked up when the time to live is not positive.",
+			want:   3,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := NewAccountKeyProvider(tc.ttl)
+			var calls int32
+			for i := 0; i < 3; i++ {
+				got, err := p.Key(context.Background(), testAccount, countingFetcher(testKey, &calls))
+				if err != nil {
+					t.Fatalf("\n%s\nKey(...): %v", tc.reason, err)
+				}
+				if got != testKey {
+					t.Errorf("\n%s\nKey(...): want %q, got %q", tc.reason, testKey, got)
+				}
+			}
+			if calls != tc.want {
+				t.Errorf("\n%s\nKey(...): want %d fetches, got %d", tc.reason, tc.want, calls)
+			}
+		})
+	}
+}
+
+func TestAccountKeyProviderTTLExpiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	p := NewAccountKeyProvider(time.Minute)
+	p.now = func() time.Time { return now }
+
+	var calls int32
+	lookup := func() {
+		t.Helper()
+		if _, err := p.Key(context.Background(), testAccount, countingFetcher(testKey, &calls)); err != nil {
+			t.Fatalf("Key(...): %v", err)
+		}
+	}
+
+	lookup()
+	now = now.Add(59 * time.Second)
+	lookup()
+	if calls != 1 {
+		t.Errorf("Key(...): want 1 fetch before the key expires, got %d", calls)
+	}
+	now = now.Add(time.Second)
+	lookup()
+	if calls != 2 {
+		t.Errorf("Key(...): want 2 fetches after the key expires, got %d", calls)
+	}
+}
+
+func TestAccountKeyProviderFetchFailed(t *testing.T) {
+	p := NewAccountKeyProvider(time.Minute)
+	errBoom := errors.New("boom")
+
+	if _, err := p.Key(context.Background(), testAccount, func(ctx context.Context) (string, error) { return "", errBoom }); !errors.Is(err, errBoom) {
+		t.Errorf("Key(...): want error %v, got %v", errBoom, err)
+	}
+	var calls int32
+	if _, err := p.Key(context.Background(), testAccount, countingFetcher(testKey, &calls)); err != nil {
+		t.Fatalf("Key(...): %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Key(...): want a failed fetch not to be cached, got %d fetches", calls)
+	}
+}
+
+func TestAccountKeyProviderInvalidate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   bool
+	}{
+		"AuthenticationFailed": {
+			reason: "A key the blob service does not accept should be forgotten.",
+			err:    errors.Wrap(newAuthenticationError(), "cannot get blob service metrics"),
+			want:   true,
+		},
+		"OtherError": {
+			reason: "A key should be kept when the error was not an authentication failure.",
+			err:    errors.New("boom"),
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := NewAccountKeyProvider(time.Minute)
+			var calls int32
+			if _, err := p.Key(context.Background(), testAccount, countingFetcher(testKey, &calls)); err != nil {
+				t.Fatalf("\n%s\nKey(...): %v", tc.reason, err)
+			}
+
+			if got := p.Invalidate(testAccount, tc.err); got != tc.want {
+				t.Errorf("\n%s\nInvalidate(...): want %t, got %t", tc.reason, tc.want, got)
+			}
+
+			if _, err := p.Key(context.Background(), testAccount, countingFetcher(testKey, &calls)); err != nil {
+				t.Fatalf("\n%s\nKey(...): %v", tc.reason, err)
+			}
+			want := int32(1)
+			if tc.want {
+				want = 2
+			}
+			if calls != want {
+				t.Errorf("\n%s\nKey(...): want %d fetches, got %d", tc.reason, want, calls)
+			}
+		})
+	}
+}
+
+func TestAccountKeyProviderCoalesce(t *testing.T) {
+	p := NewAccountKeyProvider(time.Minute)
+
+	var calls int32
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return testKey, nil
+	}
+
+	const lookups = 10
+	wg := sync.WaitGroup{}
+	got := make([]string, lookups)
+	for i := 0; i < lookups; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i], _ = p.Key(context.Background(), testAccount, fetch)
+		}(i)
+	}
+
+	// Wait for the first lookup to start fetching, and give the others a
+	// chance to find it in progress, before letting it finish.
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("Key(...): want concurrent lookups to share 1 fetch, got %d", calls)
+	}
+	for i, k := range got {
+		if k != testKey {
+			t.Errorf("Key(...): lookup %d: want %q, got %q", i, testKey, k)
+		}
+	}
+}
+
+func TestAccountKeyProviderCancelledLookup(t *testing.T) {
+	p := NewAccountKeyProvider(time.Minute)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	fetch := func(ctx context.Context) (string, error) {
+		close(started)
+		select {
+		case <-release:
+			return testKey, nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := p.Key(ctx, testAccount, fetch)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan string)
+	go func() {
+		k, _ := p.Key(context.Background(), testAccount, fetch)
+		second <- k
+	}()
+
+	// Cancelling the lookup that started the fetch should not fail the
+	// fetch that other lookups wait on.
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("Key(...): want cancelled lookup to return %v, got %v", context.Canceled, err)
+	}
+	close(release)
+	if k := <-second; k != testKey {
+		t.Errorf("Key(...): want waiting lookup to get %q, got %q", testKey, k)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+// A Blob is a blob in a container. Blobs are current versions unless their
+// snapshots, versions, or deleted blobs were listed too.
+type Blob struct {
+	Name string
+	Type azblob.BlobType
+
+	// Snapshot is the time the blob was snapshotted, if it is a snapshot.
+	Snapshot string
+
+	// VersionID identifies the version of the blob, if versions were listed
+	// and the account has blob versioning enabled.
+	VersionID string
+
+	// Previous is true if the blob is a version other than the current one.
+	// The blob service marks only the current version of a blob as such.
+	Previous bool
+
+	// Deleted is true if the blob was soft-deleted.
+	Deleted bool
+
+	// Metadata of the blob, if it was listed.
+	Metadata azblob.Metadata
+}
+
+// ListOptions configure which blobs ListBlobsWithOptions lists.
+type ListOptions struct {
+	// Prefix that the names of listed blobs start with. It is applied by the
+	// blob service.
+	Prefix string
+
+	// Types of blob that are listed. Every type is listed when it is empty.
+	Types []azblob.BlobType
+
+	// Snapshots, Versions, and Deleted list the snapshots, previous
+	// versions, and soft-deleted blobs of the container too. Metadata lists
+	// each blob's metadata. Listing less is cheaper, so each is false by
+	// default.
+	Snapshots bool
+	Versions  bool
+	Deleted   bool
+	Metadata  bool
+}
+
+// include returns the value of the include query parameter that lists what
+// the options ask for, in the alphabetical order the blob service signs it
+// in.
+func (o ListOptions) include() string {
+	var include []string
+	if o.Deleted {
+		include = append(include, "deleted")
+	}
+	if o.Metadata {
+		include = append(include, "metadata")
+	}
+	if o.Snapshots {
+		include = append(include, "snapshots")
+	}
+	if o.Versions {
+		include = append(include, "versions")
+	}
+	return strings.Join(include, ",")
+}
+
+type blobList struct {
+	XMLName    xml.Name   `xml:"EnumerationResults"`
+	Blobs      []blobItem `xml:"Blobs>Blob"`
+	NextMarker string     `xml:"NextMarker"`
+}
+
+type blobItem struct {
+	Name             string `xml:"Name"`
+	Snapshot         string `xml:"Snapshot"`
+	VersionID        string `xml:"VersionId"`
+	IsCurrentVersion *bool  `xml:"IsCurrentVersion"`
+	Deleted          bool   `xml:"Deleted"`
+	Properties       struct {
+		BlobType azblob.BlobType `xml:"BlobType"`
+	} `xml:"Properties"`
+	Metadata *struct {
+		Items []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"Metadata"`
+}
+
+func (b blobItem) blob() Blob {
+	out := Blob{
+		Name:      b.Name,
+		Type:      b.Properties.BlobType,
+		Snapshot:  b.Snapshot,
+		VersionID: b.VersionID,
+		Previous:  b.VersionID != "" && (b.IsCurrentVersion == nil || !*b.IsCurrentVersion),
+		Deleted:   b.Deleted,
+	}
+	if b.Metadata != nil {
+		out.Metadata = azblob.Metadata{}
+		for _, i := range b.Metadata.Items {
+			out.Metadata[strings.ToLower(i.XMLName.Local)] = i.Value
+		}
+	}
+	return out
+}
+
+// ListBlobs lists the current version of every blob in the container whose
+// name starts with the supplied prefix and, if any types are supplied, whose
+// type is one of them. The prefix is applied by the blob service. The blob
+// service cannot filter listings by blob type, so types are applied as each
+// page is listed.
+func (a *ContainerHandle) ListBlobs(ctx context.Context, prefix string, types ...azblob.BlobType) ([]Blob, error) {
+	return a.ListBlobsWithOptions(ctx, ListOptions{Prefix: prefix, Types: types})
+}
+
+// ListBlobsWithOptions lists the blobs in the container that the supplied
+// options select, and optionally their snapshots, versions, soft-deleted
+// blobs, and metadata. Types are applied as each page is listed, as they are
+// by ListBlobs.
+func (a *ContainerHandle) ListBlobsWithOptions(ctx context.Context, o ListOptions) ([]Blob, error) {
+	allowed := map[azblob.BlobType]bool{}
+	for _, t := range o.Types {
+		allowed[t] = true
+	}
+	include := o.include()
+
+	var blobs []Blob
+	for marker := ""; ; {
+		var body []byte
+		err := a.read(OperationListBlobs, func(r *ContainerHandle) error {
+			u := r.URL()
+			q := u.Query()
+			q.Set("restype", "container")
+			q.Set("comp", "list")
+			if o.Prefix != "" {
+				q.Set("prefix", o.Prefix)
+			}
+			if include != "" {
+				q.Set("include", include)
+			}
+			if marker != "" {
+				q.Set("marker", marker)
+			}
+			u.RawQuery = q.Encode()
+
+			var err error
+			_, body, err = r.send(ctx, http.MethodGet, u, nil, http.StatusOK)
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot list blobs")
+		}
+		page := blobList{}
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return nil, errors.Wrap(err, "cannot parse blob listing")
+		}
+		for _, b := range page.Blobs {
+			if len(allowed) > 0 && !allowed[b.Properties.BlobType] {
+				continue
+			}
+			blobs = append(blobs, b.blob())
+		}
+		if page.NextMarker == "" {
+			return blobs, nil
+		}
+		marker = page.NextMarker
+	}
+}
+
+// StreamBlobs calls the supplied function with the current version of every
+// blob in the container whose name starts with the supplied prefix, as each
+// page of the listing arrives, so that huge containers can be processed
+// without listing every blob first. Iteration stops at the first error the
+// function returns, which is returned as is. The context is checked between
+// pages.
+func (a *ContainerHandle) StreamBlobs(ctx context.Context, prefix string, fn func(azblob.BlobItem) error) error {
+	o := azblob.ListBlobsSegmentOptions{Prefix: prefix}
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var page *azblob.ListBlobsFlatSegmentResponse
+		err := a.read(OperationListBlobs, func(r *ContainerHandle) error {
+			var err error
+			page, err = r.ListBlobsFlatSegment(ctx, marker, o)
+			return err
+		})
+		if err != nil {
+			return errors.Wrap(err, "cannot list blobs")
+		}
+		for _, b := range page.Segment.BlobItems {
+			if err := fn(b); err != nil {
+				return err
+			}
+		}
+		marker = page.NextMarker
+	}
+	return nil
+}
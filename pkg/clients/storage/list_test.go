@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func typedBlobListing(next string, blobs ...Blob) string {
+	b := &strings.Builder{}
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>`)
+	for _, v := range blobs {
+		fmt.Fprintf(b, "<Blob><Name>%s</Name><Properties><BlobType>%s</BlobType></Properties></Blob>", v.Name, v.Type)
+	}
+	fmt.Fprintf(b, "</Blobs><NextMarker>%s</NextMarker></EnumerationResults>", next)
+	return b.String()
+}
+
+func TestListBlobs(t *testing.T) {
+	block := Blob{Name: "data/a", Type: azblob.BlobBlockBlob}
+	page := Blob{Name: "data/b", Type: azblob.BlobPageBlob}
+	appendBlob := Blob{Name: "data/c.log", Type: azblob.BlobAppendBlob}
+	pages := map[string]string{
+		"":   typedBlobListing("m1", block, appendBlob),
+		"m1": typedBlobListing("", page),
+	}
+
+	cases := map[string]struct {
+		reason string
+		types  []azblob.BlobType
+		want   []Blob
+	}{
+		"AllTypes": {
+			reason: "Every blob should be listed when no types are requested.",
+			want:   []Blob{block, appendBlob, page},
+		},
+		"BlockOnly": {
+			reason: "Only block blobs should be listed when they are requested.",
+			types:  []azblob.BlobType{azblob.BlobBlockBlob},
+			want:   []Blob{block},
+		},
+		"BlockAndPage": {
+			reason: "Blobs of every requested type should be listed, across pages.",
+			types:  []azblob.BlobType{azblob.BlobBlockBlob, azblob.BlobPageBlob},
+			want:   []Blob{block, page},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var prefixes []string
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				prefixes = append(prefixes, r.URL.Query().Get("prefix"))
+				fmt.Fprint(w, pages[r.URL.Query().Get("marker")])
+			}))
+
+			got, err := h.ListBlobs(context.Background(), "data/", tc.types...)
+			if err != nil {
+				t.Fatalf("\n%s\nListBlobs(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nListBlobs(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff([]string{"data/", "data/"}, prefixes); diff != "" {
+				t.Errorf("\n%s\nListBlobs(...): -want prefixes, +got prefixes:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestListBlobsWithOptions(t *testing.T) {
+	listing := `<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>` +
+		`<Blob><Name>a</Name><Snapshot>2022-01-01T00:00:00.0000000Z</Snapshot><Properties><BlobType>BlockBlob</BlobType></Properties></Blob>` +
+		`<Blob><Name>a</Name><VersionId>v1</VersionId><Properties><BlobType>BlockBlob</BlobType></Properties></Blob>` +
+		`<Blob><Name>a</Name><VersionId>v2</VersionId><IsCurrentVersion>true</IsCurrentVersion><Properties><BlobType>BlockBlob</BlobType></Properties><Metadata><Owner>team</Owner></Metadata></Blob>` +
+		`<Blob><Name>b</Name><Deleted>true</Deleted><Properties><BlobType>PageBlob</BlobType></Properties></Blob>` +
+		`</Blobs><NextMarker></NextMarker></EnumerationResults>`
+
+	cases := map[string]struct {
+		reason  string
+		o       ListOptions
+		include string
+		want    []Blob
+	}{
+		"None": {
+			reason: "Nothing should be included in listings by default.",
+			want: []Blob{
+				{Name: "a", Type: azblob.BlobBlockBlob, Snapshot: "2022-01-01T00:00:00.0000000Z"},
+				{Name: "a", Type: azblob.BlobBlockBlob, VersionID: "v1", Previous: true},
+				{Name: "a", Type: azblob.BlobBlockBlob, VersionID: "v2", Metadata: azblob.Metadata{"owner": "team"}},
+				{Name: "b", Type: azblob.BlobPageBlob, Deleted: true},
+			},
+		},
+		"Everything": {
+			reason:  "Every requested inclusion should be passed to the blob service in alphabetical order.",
+			o:       ListOptions{Snapshots: true, Versions: true, Metadata: true, Deleted: true},
+			include: "deleted,metadata,snapshots,versions",
+		},
+		"SnapshotsAndVersions": {
+			reason:  "Only the requested inclusions should be passed to the blob service.",
+			o:       ListOptions{Versions: true, Snapshots: true},
+			include: "snapshots,versions",
+		},
+		"Types": {
+			reason: "Included blobs should still be filtered by type.",
+			o:      ListOptions{Deleted: true, Types: []azblob.BlobType{azblob.BlobPageBlob}},
+			want: []Blob{
+				{Name: "b", Type: azblob.BlobPageBlob, Deleted: true},
+			},
+			include: "deleted",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var include []string
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				include = append(include, r.URL.Query().Get("include"))
+				fmt.Fprint(w, listing)
+			}))
+
+			got, err := h.ListBlobsWithOptions(context.Background(), tc.o)
+			if err != nil {
+				t.Fatalf("\n%s\nListBlobsWithOptions(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff([]string{tc.include}, include); diff != "" {
+				t.Errorf("\n%s\nListBlobsWithOptions(...): -want include, +got include:\n%s", tc.reason, diff)
+			}
+			if tc.want != nil {
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("\n%s\nListBlobsWithOptions(...): -want, +got:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamBlobs(t *testing.T) {
+	errStop := errors.New("stop")
+	pages := map[string]string{
+		"":   typedBlobListing("m1", Blob{Name: "data/a", Type: azblob.BlobBlockBlob}, Blob{Name: "data/b", Type: azblob.BlobBlockBlob}),
+		"m1": typedBlobListing("m2", Blob{Name: "data/c", Type: azblob.BlobPageBlob}),
+		"m2": typedBlobListing("", Blob{Name: "data/d", Type: azblob.BlobAppendBlob}),
+	}
+
+	type want struct {
+		names []string
+		pages int
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		fn     func(ctx context.Context, cancel context.CancelFunc, b azblob.BlobItem) error
+		want   want
+	}{
+		"EveryBlob": {
+			reason: "The function should be called with every blob, across pages.",
+			fn:     func(context.Context, context.CancelFunc, azblob.BlobItem) error { return nil },
+			want:   want{names: []string{"data/a", "data/b", "data/c", "data/d"}, pages: 3},
+		},
+		"StopOnError": {
+			reason: "Iteration should stop at the first error the function returns, without listing more pages.",
+			fn: func(_ context.Context, _ context.CancelFunc, b azblob.BlobItem) error {
+				if b.Name == "data/b" {
+					return errStop
+				}
+				return nil
+			},
+			want: want{names: []string{"data/a", "data/b"}, pages: 1, err: errStop},
+		},
+		"Cancelled": {
+			reason: "Iteration should stop between pages once the context is cancelled.",
+			fn: func(_ context.Context, cancel context.CancelFunc, b azblob.BlobItem) error {
+				if b.Name == "data/c" {
+					cancel()
+				}
+				return nil
+			},
+			want: want{names: []string{"data/a", "data/b", "data/c"}, pages: 2, err: context.Canceled},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			listed := 0
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				listed++
+				if got := r.URL.Query().Get("prefix"); got != "data/" {
+					t.Errorf("\n%s\nStreamBlobs(...): want prefix data/, got %q", tc.reason, got)
+				}
+				w.Header().Set("Content-Type", "application/xml")
+				fmt.Fprint(w, pages[r.URL.Query().Get("marker")])
+			}))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var names []string
+			err := h.StreamBlobs(ctx, "data/", func(b azblob.BlobItem) error {
+				names = append(names, b.Name)
+				return tc.fn(ctx, cancel, b)
+			})
+			if !errors.Is(err, tc.want.err) {
+				t.Errorf("\n%s\nStreamBlobs(...): want error %v, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.names, names); diff != "" {
+				t.Errorf("\n%s\nStreamBlobs(...): -want blobs, +got blobs:\n%s", tc.reason, diff)
+			}
+			if listed != tc.want.pages {
+				t.Errorf("\n%s\nStreamBlobs(...): want %d page(s) listed, got %d", tc.reason, tc.want.pages, listed)
+			}
+		})
+	}
+}
@@ -0,0 +1,689 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/Azure/go-autorest/autorest"
+	autorestazure "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+
+	azure "github.com/crossplane-contrib/provider-azure/pkg/clients"
+)
+
+// ManagementOperations are storage account management plane operations that
+// the Account resource's API version does not support.
+type ManagementOperations interface {
+	GetAccount(ctx context.Context) (*storage.Account, error)
+	GetEncryptionScope(ctx context.Context, name string) (*storage.EncryptionScope, error)
+	SetContainerEncryptionScope(ctx context.Context, container, scope string, preventOverride bool) error
+	EnsureEncryptionScope(ctx context.Context, resourceGroup, accountName, scopeName, keyVaultKeyURI string) error
+	InitiateFailover(ctx context.Context, resourceGroup, accountName string) error
+	GetFailoverState(ctx context.Context, resourceGroup, accountName string) (FailoverState, error)
+	GetDefaultToOAuth(ctx context.Context) (bool, error)
+	SetDefaultToOAuth(ctx context.Context, enabled bool) error
+	GetAllowSharedKeyAccess(ctx context.Context) (bool, error)
+	GetAllowBlobPublicAccess(ctx context.Context) (bool, error)
+	SetAllowSharedKeyAccess(ctx context.Context, allowed bool) error
+	GetMinimumTLSVersion(ctx context.Context) (string, error)
+	SetMinimumTLSVersion(ctx context.Context, version string) error
+	GetEnableHTTPSTrafficOnly(ctx context.Context) (bool, error)
+	SetEnableHTTPSTrafficOnly(ctx context.Context, enabled bool) error
+	GetDeletedAccount(ctx context.Context, location string) (*storage.DeletedAccount, error)
+	RestoreDeletedAccount(ctx context.Context, location string) error
+	GetContainerImmutability(ctx context.Context, container string) (ContainerImmutability, error)
+	SetContainerImmutabilityPolicy(ctx context.Context, container string, p ContainerImmutabilityPolicy) (string, error)
+	ExtendContainerImmutabilityPolicy(ctx context.Context, container string, p ContainerImmutabilityPolicy) (string, error)
+	LockContainerImmutabilityPolicy(ctx context.Context, container, etag string) error
+	SetContainerLegalHold(ctx context.Context, container string, tags []string) error
+	ClearContainerLegalHold(ctx context.Context, container string, tags []string) error
+	GetBlobVersioning(ctx context.Context) (bool, error)
+}
+
+const (
+	errGetSharedKeyAccess   = "cannot get shared key access of storage account %s"
+	errGetBlobPublicAccess  = "cannot get blob public access of storage account %s"
+	errGetMinimumTLSVersion = "cannot get minimum TLS version of storage account %s"
+	errGetHTTPSTrafficOnly  = "cannot get HTTPS-only traffic of storage account %s"
+	errGetBlobVersioning    = "cannot get blob versioning of storage account %s"
+)
+
+const (
+	// oauthAPIVersion is the first management API version that knows about
+	// the defaultToOAuthAuthentication account property. The SDK we use pins
+	// an older version, so requests that need it are built by hand.
+	oauthAPIVersion = "2021-08-01"
+
+	accountPath = "/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Storage/storageAccounts/{accountName}"
+)
+
+// oauthAccount is the subset of a storage account that the
+// defaultToOAuthAuthentication requests read and write.
+type oauthAccount struct {
+	Properties oauthAccountProperties `json:"properties"`
+}
+
+type oauthAccountProperties struct {
+	DefaultToOAuthAuthentication *bool `json:"defaultToOAuthAuthentication,omitempty"`
+}
+
+// restoreAccount is the storage account a request to recover a soft-deleted
+// account puts. The SDK's create parameters cannot carry a restore reference.
+type restoreAccount struct {
+	Location   string                   `json:"location"`
+	Properties restoreAccountProperties `json:"properties"`
+}
+
+type restoreAccountProperties struct {
+	RestoreReference string `json:"restoreReference"`
+}
+
+// ErrFailoverInProgress is returned when a failover is initiated while one is
+// already in progress.
+var ErrFailoverInProgress = errors.New("a failover is already in progress")
+
+// ErrFailoverUnsupported is returned when a failover is initiated for a
+// storage account that cannot currently fail over, for example because it is
+// not geo-redundant or its secondary location is unavailable.
+var ErrFailoverUnsupported = errors.New("the storage account cannot currently fail over")
+
+// FailoverState is the observed failover state of a storage account.
+type FailoverState struct {
+	// InProgress is true while a failover is in progress.
+	InProgress bool
+
+	// CanFailover is true if the account may currently fail over.
+	CanFailover bool
+
+	// PrimaryLocation and SecondaryLocation are the account's current
+	// locations. They swap when a failover completes.
+	PrimaryLocation   string
+	SecondaryLocation string
+
+	// LastSyncTime is the time before which every write to the primary
+	// location may be read from the secondary one. It is nil when unknown.
+	LastSyncTime *time.Time
+}
+
+// ContainerImmutability is the observed immutability of a container.
+type ContainerImmutability struct {
+	// Policy is the container's time-based retention policy. It is nil when
+	// the container has none.
+	Policy *ContainerImmutabilityPolicy
+
+	// LegalHoldTags are the tags of the container's legal hold, which Azure
+	// stores in lower case. The container is not on legal hold when there
+	// are none.
+	LegalHoldTags []string
+}
+
+// A ContainerImmutabilityPolicy is a time-based retention policy of a
+// container.
+type ContainerImmutabilityPolicy struct {
+	PeriodSinceCreationInDays  int32
+	AllowProtectedAppendWrites bool
+	Locked                     bool
+
+	// ETag of the policy. Changes to an existing policy must supply the
+	// ETag it was last observed with.
+	ETag string
+}
+
+// ManagementHandle implements ManagementOperations for a storage account.
+type ManagementHandle struct {
+	accounts    storage.AccountsClient
+	scopes      storage.EncryptionScopesClient
+	containers  storage.BlobContainersClient
+	deleted     storage.DeletedAccountsClient
+	services    storage.BlobServicesClient
+	groupName   string
+	accountName string
+}
+
+var _ ManagementOperations = &ManagementHandle{}
+
+// NewManagementHandle returns a ManagementHandle for the named storage account.
+func NewManagementHandle(subscriptionID string, auth autorest.Authorizer, groupName, accountName string) *ManagementHandle {
+	return NewManagementHandleWithBaseURI(storage.DefaultBaseURI, subscriptionID, auth, groupName, accountName)
+}
+
+// NewManagementHandleWithBaseURI returns a ManagementHandle for the named
+// storage account that manages it through the supplied Azure Resource Manager
+// endpoint, such as that of a sovereign cloud.
+func NewManagementHandleWithBaseURI(baseURI, subscriptionID string, auth autorest.Authorizer, groupName, accountName string) *ManagementHandle {
+	accounts := storage.NewAccountsClientWithBaseURI(baseURI, subscriptionID)
+	accounts.Authorizer = auth
+	_ = accounts.AddToUserAgent(azure.UserAgent)
+
+	scopes := storage.NewEncryptionScopesClientWithBaseURI(baseURI, subscriptionID)
+	scopes.Authorizer = auth
+	_ = scopes.AddToUserAgent(azure.UserAgent)
+
+	containers := storage.NewBlobContainersClientWithBaseURI(baseURI, subscriptionID)
+	containers.Authorizer = auth
+	_ = containers.AddToUserAgent(azure.UserAgent)
+
+	deleted := storage.NewDeletedAccountsClientWithBaseURI(baseURI, subscriptionID)
+	deleted.Authorizer = auth
+	_ = deleted.AddToUserAgent(azure.UserAgent)
+
+	services := storage.NewBlobServicesClientWithBaseURI(baseURI, subscriptionID)
+	services.Authorizer = auth
+	_ = services.AddToUserAgent(azure.UserAgent)
+
+	return &ManagementHandle{
+		accounts:    accounts,
+		scopes:      scopes,
+		containers:  containers,
+		deleted:     deleted,
+		services:    services,
+		groupName:   groupName,
+		accountName: accountName,
+	}
+}
+
+// GetAccount returns the storage account.
+func (m *ManagementHandle) GetAccount(ctx context.Context) (*storage.Account, error) {
+	a, err := m.accounts.GetProperties(ctx, m.groupName, m.accountName, "")
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetEncryptionScope returns the named encryption scope of the storage account.
+func (m *ManagementHandle) GetEncryptionScope(ctx context.Context, name string) (*storage.EncryptionScope, error) {
+	s, err := m.scopes.Get(ctx, m.groupName, m.accountName, name)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SetContainerEncryptionScope sets the default encryption scope of the named
+// container, and whether blobs may override it.
+func (m *ManagementHandle) SetContainerEncryptionScope(ctx context.Context, container, scope string, preventOverride bool) error {
+	_, err := m.containers.Update(ctx, m.groupName, m.accountName, container, storage.BlobContainer{
+		ContainerProperties: &storage.ContainerProperties{
+			DefaultEncryptionScope:      to.StringPtr(scope),
+			DenyEncryptionScopeOverride: to.BoolPtr(preventOverride),
+		},
+	})
+	return err
+}
+
+// EnsureEncryptionScope creates the named encryption scope in the supplied
+// storage account unless it already exists. The scope encrypts blobs with the
+// supplied Key Vault key, or with a Microsoft managed key if no key URI is
+// supplied. An existing scope is left as it is, even if it uses another key.
+func (m *ManagementHandle) EnsureEncryptionScope(ctx context.Context, resourceGroup, accountName, scopeName, keyVaultKeyURI string) error {
+	_, err := m.scopes.Get(ctx, resourceGroup, accountName, scopeName)
+	if err == nil {
+		return nil
+	}
+	if !azure.IsNotFound(err) {
+		return err
+	}
+
+	p := &storage.EncryptionScopeProperties{Source: storage.EncryptionScopeSourceMicrosoftStorage}
+	if keyVaultKeyURI != "" {
+		p.Source = storage.EncryptionScopeSourceMicrosoftKeyVault
+		p.KeyVaultProperties = &storage.EncryptionScopeKeyVaultProperties{KeyURI: to.StringPtr(keyVaultKeyURI)}
+	}
+	_, err = m.scopes.Put(ctx, resourceGroup, accountName, scopeName, storage.EncryptionScope{EncryptionScopeProperties: p})
+	return err
+}
+
+// InitiateFailover starts a customer initiated failover of the supplied
+// storage account to its secondary location. It does not wait for the
+// failover, which may take an hour or more, to complete; use GetFailoverState
+// to observe it. It returns ErrFailoverInProgress if a failover is already in
+// progress, and ErrFailoverUnsupported if the account cannot fail over.
+func (m *ManagementHandle) InitiateFailover(ctx context.Context, resourceGroup, accountName string) error {
+	s, err := m.GetFailoverState(ctx, resourceGroup, accountName)
+	if err != nil {
+		return err
+	}
+	if s.InProgress {
+		return errors.Wrapf(ErrFailoverInProgress, "cannot initiate failover of storage account %s", accountName)
+	}
+	if !s.CanFailover {
+		return errors.Wrapf(ErrFailoverUnsupported, "cannot initiate failover of storage account %s", accountName)
+	}
+	_, err = m.accounts.Failover(ctx, resourceGroup, accountName)
+	return errors.Wrapf(err, "cannot initiate failover of storage account %s", accountName)
+}
+
+// GetFailoverState returns the failover state of the supplied storage account.
+func (m *ManagementHandle) GetFailoverState(ctx context.Context, resourceGroup, accountName string) (FailoverState, error) {
+	a, err := m.accounts.GetProperties(ctx, resourceGroup, accountName, storage.AccountExpandGeoReplicationStats)
+	if err != nil {
+		return FailoverState{}, errors.Wrapf(err, "cannot get failover state of storage account %s", accountName)
+	}
+	s := FailoverState{}
+	p := a.AccountProperties
+	if p == nil {
+		return s, nil
+	}
+	s.InProgress = to.Bool(p.FailoverInProgress)
+	s.PrimaryLocation = to.String(p.PrimaryLocation)
+	s.SecondaryLocation = to.String(p.SecondaryLocation)
+	if g := p.GeoReplicationStats; g != nil {
+		s.CanFailover = to.Bool(g.CanFailover)
+		if g.LastSyncTime != nil {
+			t := g.LastSyncTime.ToTime()
+			s.LastSyncTime = &t
+		}
+	}
+	return s, nil
+}
+
+// GetDefaultToOAuth returns true if the storage account defaults to OAuth
+// rather than shared key authentication when clients such as the Azure portal
+// do not ask for either. Accounts that never set it do not.
+func (m *ManagementHandle) GetDefaultToOAuth(ctx context.Context) (bool, error) {
+	a := oauthAccount{}
+	if err := m.sendAccount(ctx, autorest.AsGet(), &a); err != nil {
+		return false, errors.Wrapf(err, "cannot get default to OAuth authentication of storage account %s", m.accountName)
+	}
+	return to.Bool(a.Properties.DefaultToOAuthAuthentication), nil
+}
+
+// SetDefaultToOAuth sets whether the storage account defaults to OAuth rather
+// than shared key authentication.
+func (m *ManagementHandle) SetDefaultToOAuth(ctx context.Context, enabled bool) error {
+	a := oauthAccount{Properties: oauthAccountProperties{DefaultToOAuthAuthentication: to.BoolPtr(enabled)}}
+	err := m.sendAccount(ctx, autorest.AsPatch(), nil, autorest.WithJSON(a))
+	return errors.Wrapf(err, "cannot set default to OAuth authentication of storage account %s", m.accountName)
+}
+
+// sendAccount issues a request for the storage account at oauthAPIVersion
+// and unmarshals the response into v, unless it is nil.
+func (m *ManagementHandle) sendAccount(ctx context.Context, method autorest.PrepareDecorator, v interface{}, d ...autorest.PrepareDecorator) error {
+	path := map[string]interface{}{
+		"subscriptionId":    autorest.Encode("path", m.accounts.SubscriptionID),
+		"resourceGroupName": autorest.Encode("path", m.groupName),
+		"accountName":       autorest.Encode("path", m.accountName),
+	}
+	d = append([]autorest.PrepareDecorator{
+		method,
+		autorest.AsContentType("application/json; charset=utf-8"),
+		autorest.WithBaseURL(m.accounts.BaseURI),
+		autorest.WithPathParameters(accountPath, path),
+		autorest.WithQueryParameters(map[string]interface{}{"api-version": oauthAPIVersion}),
+	}, d...)
+	req, err := autorest.Prepare((&http.Request{}).WithContext(ctx), d...)
+	if err != nil {
+		return err
+	}
+	resp, err := m.accounts.Send(req, autorestazure.DoRetryWithRegistration(m.accounts.Client))
+	if err != nil {
+		return err
+	}
+	respond := []autorest.RespondDecorator{autorestazure.WithErrorUnlessStatusCode(http.StatusOK, http.StatusAccepted)}
+	if v != nil {
+		respond = append(respond, autorest.ByUnmarshallingJSON(v))
+	}
+	return autorest.Respond(resp, append(respond, autorest.ByClosing())...)
+}
+
+// GetAllowSharedKeyAccess returns true if the storage account permits requests
+// authorized with its account keys, including shared access signatures.
+// Accounts that never set it do.
+func (m *ManagementHandle) GetAllowSharedKeyAccess(ctx context.Context) (bool, error) {
+	a, err := m.GetAccount(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, errGetSharedKeyAccess, m.accountName)
+	}
+	return allowSharedKeyAccess(a), nil
+}
+
+func allowSharedKeyAccess(a *storage.Account) bool {
+	if a.AccountProperties == nil || a.AccountProperties.AllowSharedKeyAccess == nil {
+		return true
+	}
+	return *a.AccountProperties.AllowSharedKeyAccess
+}
+
+// GetAllowBlobPublicAccess returns whether the storage account permits its
+// containers to allow anonymous public access. Azure permits it when the
+// account does not say.
+func (m *ManagementHandle) GetAllowBlobPublicAccess(ctx context.Context) (bool, error) {
+	a, err := m.GetAccount(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, errGetBlobPublicAccess, m.accountName)
+	}
+	return allowBlobPublicAccess(a), nil
+}
+
+func allowBlobPublicAccess(a *storage.Account) bool {
+	if a.AccountProperties == nil || a.AccountProperties.AllowBlobPublicAccess == nil {
+		return true
+	}
+	return *a.AccountProperties.AllowBlobPublicAccess
+}
+
+// SetAllowSharedKeyAccess sets whether the storage account permits requests
+// authorized with its account keys. Once it is disallowed every request must
+// be authorized with Azure AD.
+func (m *ManagementHandle) SetAllowSharedKeyAccess(ctx context.Context, allowed bool) error {
+	_, err := m.accounts.Update(ctx, m.groupName, m.accountName, storage.AccountUpdateParameters{
+		AccountPropertiesUpdateParameters: &storage.AccountPropertiesUpdateParameters{AllowSharedKeyAccess: to.BoolPtr(allowed)},
+	})
+	return errors.Wrapf(err, "cannot set shared key access of storage account %s", m.accountName)
+}
+
+// ValidateMinimumTLSVersion returns an error unless the supplied version is a
+// minimum TLS version that storage accounts accept: TLS1_0, TLS1_1 or TLS1_2.
+func ValidateMinimumTLSVersion(version string) error {
+	for _, v := range storage.PossibleMinimumTLSVersionValues() {
+		if version == string(v) {
+			return nil
+		}
+	}
+	return errors.Errorf("invalid minimum TLS version %q: must be one of TLS1_0, TLS1_1 or TLS1_2", version)
+}
+
+// GetMinimumTLSVersion returns the minimum TLS version that the storage
+// account permits requests to use. Accounts that never set it permit TLS 1.0.
+func (m *ManagementHandle) GetMinimumTLSVersion(ctx context.Context) (string, error) {
+	a, err := m.GetAccount(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, errGetMinimumTLSVersion, m.accountName)
+	}
+	return minimumTLSVersion(a), nil
+}
+
+func minimumTLSVersion(a *storage.Account) string {
+	if a.AccountProperties == nil || a.AccountProperties.MinimumTLSVersion == "" {
+		return string(storage.MinimumTLSVersionTLS10)
+	}
+	return string(a.AccountProperties.MinimumTLSVersion)
+}
+
+// SetMinimumTLSVersion sets the minimum TLS version that the storage account
+// permits requests to use. Versions other than TLS1_0, TLS1_1 and TLS1_2 are
+// rejected without updating the account.
+func (m *ManagementHandle) SetMinimumTLSVersion(ctx context.Context, version string) error {
+	if err := ValidateMinimumTLSVersion(version); err != nil {
+		return err
+	}
+	_, err := m.accounts.Update(ctx, m.groupName, m.accountName, storage.AccountUpdateParameters{
+		AccountPropertiesUpdateParameters: &storage.AccountPropertiesUpdateParameters{MinimumTLSVersion: storage.MinimumTLSVersion(version)},
+	})
+	return errors.Wrapf(err, "cannot set minimum TLS version of storage account %s", m.accountName)
+}
+
+// GetEnableHTTPSTrafficOnly returns true if the storage account only permits
+// requests made over HTTPS. Accounts that never set it do.
+func (m *ManagementHandle) GetEnableHTTPSTrafficOnly(ctx context.Context) (bool, error) {
+	a, err := m.GetAccount(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, errGetHTTPSTrafficOnly, m.accountName)
+	}
+	return enableHTTPSTrafficOnly(a), nil
+}
+
+func enableHTTPSTrafficOnly(a *storage.Account) bool {
+	if a.AccountProperties == nil || a.AccountProperties.EnableHTTPSTrafficOnly == nil {
+		return true
+	}
+	return *a.AccountProperties.EnableHTTPSTrafficOnly
+}
+
+// SetEnableHTTPSTrafficOnly sets whether the storage account only permits
+// requests made over HTTPS. Only that property of the account is updated.
+func (m *ManagementHandle) SetEnableHTTPSTrafficOnly(ctx context.Context, enabled bool) error {
+	_, err := m.accounts.Update(ctx, m.groupName, m.accountName, storage.AccountUpdateParameters{
+		AccountPropertiesUpdateParameters: &storage.AccountPropertiesUpdateParameters{EnableHTTPSTrafficOnly: to.BoolPtr(enabled)},
+	})
+	return errors.Wrapf(err, "cannot set HTTPS-only traffic of storage account %s", m.accountName)
+}
+
+// GetDeletedAccount returns the storage account, in the supplied location, if
+// it was soft-deleted. It returns nil if the account is not soft-deleted.
+func (m *ManagementHandle) GetDeletedAccount(ctx context.Context, location string) (*storage.DeletedAccount, error) {
+	a, err := m.deleted.Get(ctx, m.accountName, location)
+	if StatusCode(err) == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get deleted storage account %s", m.accountName)
+	}
+	return &a, nil
+}
+
+// RestoreDeletedAccount requests that the soft-deleted storage account, in the
+// supplied location, is recovered by putting the account with the restore
+// reference Azure reported for it. Recovery completes asynchronously.
+func (m *ManagementHandle) RestoreDeletedAccount(ctx context.Context, location string) error {
+	d, err := m.GetDeletedAccount(ctx, location)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("storage account %s is not soft-deleted", m.accountName)
+	}
+	if d.DeletedAccountProperties == nil || to.String(d.DeletedAccountProperties.RestoreReference) == "" {
+		return errors.Errorf("soft-deleted storage account %s has no restore reference", m.accountName)
+	}
+	a := restoreAccount{Location: location, Properties: restoreAccountProperties{RestoreReference: *d.DeletedAccountProperties.RestoreReference}}
+	err = m.sendAccount(ctx, autorest.AsPut(), nil, autorest.WithJSON(a))
+	return errors.Wrapf(err, "cannot restore soft-deleted storage account %s", m.accountName)
+}
+
+// GetBlobVersioning returns true if the storage account keeps previous versions
+// of its blobs when they are overwritten or deleted. Accounts that never
+// enabled it do not.
+func (m *ManagementHandle) GetBlobVersioning(ctx context.Context) (bool, error) {
+	p, err := m.services.GetServiceProperties(ctx, m.groupName, m.accountName)
+	if err != nil {
+		return false, errors.Wrapf(err, errGetBlobVersioning, m.accountName)
+	}
+	if p.BlobServicePropertiesProperties == nil {
+		return false, nil
+	}
+	return to.Bool(p.BlobServicePropertiesProperties.IsVersioningEnabled), nil
+}
+
+// GetContainerImmutability returns the time-based retention policy and legal
+// hold of the named container.
+func (m *ManagementHandle) GetContainerImmutability(ctx context.Context, container string) (ContainerImmutability, error) {
+	c, err := m.containers.Get(ctx, m.groupName, m.accountName, container)
+	if err != nil {
+		return ContainerImmutability{}, errors.Wrapf(err, "cannot get immutability of container %s", container)
+	}
+	out := ContainerImmutability{}
+	p := c.ContainerProperties
+	if p == nil {
+		return out, nil
+	}
+	if ip := p.ImmutabilityPolicy; to.Bool(p.HasImmutabilityPolicy) && ip != nil && ip.ImmutabilityPolicyProperty != nil {
+		out.Policy = &ContainerImmutabilityPolicy{
+			PeriodSinceCreationInDays:  to.Int32(ip.ImmutabilityPeriodSinceCreationInDays),
+			AllowProtectedAppendWrites: to.Bool(ip.AllowProtectedAppendWrites),
+			Locked:                     ip.State == storage.ImmutabilityPolicyStateLocked,
+			ETag:                       to.String(ip.Etag),
+		}
+	}
+	if lh := p.LegalHold; lh != nil && lh.Tags != nil {
+		for _, t := range *lh.Tags {
+			out.LegalHoldTags = append(out.LegalHoldTags, to.String(t.Tag))
+		}
+	}
+	return out, nil
+}
+
+// SetContainerImmutabilityPolicy creates the time-based retention policy of
+// the named container, or changes its unlocked policy if the supplied policy
+// has the ETag it was observed with. It returns the policy's new ETag.
+// Whether the policy is locked is ignored; use
+// LockContainerImmutabilityPolicy to lock it.
+func (m *ManagementHandle) SetContainerImmutabilityPolicy(ctx context.Context, container string, p ContainerImmutabilityPolicy) (string, error) {
+	ip, err := m.containers.CreateOrUpdateImmutabilityPolicy(ctx, m.groupName, m.accountName, container, immutabilityPolicy(p), p.ETag)
+	return to.String(ip.Etag), errors.Wrapf(err, "cannot set immutability policy of container %s", container)
+}
+
+// ExtendContainerImmutabilityPolicy extends the retention period of the
+// locked time-based retention policy of the named container, which must have
+// the ETag of the supplied policy. It returns the policy's new ETag. Locked
+// policies cannot have their retention period shortened, nor whether they
+// allow protected append writes changed.
+func (m *ManagementHandle) ExtendContainerImmutabilityPolicy(ctx context.Context, container string, p ContainerImmutabilityPolicy) (string, error) {
+	ip, err := m.containers.ExtendImmutabilityPolicy(ctx, m.groupName, m.accountName, container, p.ETag, immutabilityPolicy(p))
+	return to.String(ip.Etag), errors.Wrapf(err, "cannot extend immutability policy of container %s", container)
+}
+
+// LockContainerImmutabilityPolicy locks the time-based retention policy of
+// the named container, which must have the supplied ETag. Locked policies
+// cannot be unlocked or removed.
+func (m *ManagementHandle) LockContainerImmutabilityPolicy(ctx context.Context, container, etag string) error {
+	_, err := m.containers.LockImmutabilityPolicy(ctx, m.groupName, m.accountName, container, etag)
+	return errors.Wrapf(err, "cannot lock immutability policy of container %s", container)
+}
+
+func immutabilityPolicy(p ContainerImmutabilityPolicy) *storage.ImmutabilityPolicy {
+	return &storage.ImmutabilityPolicy{ImmutabilityPolicyProperty: &storage.ImmutabilityPolicyProperty{
+		ImmutabilityPeriodSinceCreationInDays: to.Int32Ptr(p.PeriodSinceCreationInDays),
+		AllowProtectedAppendWrites:            to.BoolPtr(p.AllowProtectedAppendWrites),
+	}}
+}
+
+// SetContainerLegalHold adds the supplied tags to the legal hold of the named
+// container. Its other tags are kept.
+func (m *ManagementHandle) SetContainerLegalHold(ctx context.Context, container string, tags []string) error {
+	_, err := m.containers.SetLegalHold(ctx, m.groupName, m.accountName, container, storage.LegalHold{Tags: &tags})
+	return errors.Wrapf(err, "cannot set legal hold of container %s", container)
+}
+
+// ClearContainerLegalHold removes the supplied tags from the legal hold of
+// the named container. The hold is released once it has no tags.
+func (m *ManagementHandle) ClearContainerLegalHold(ctx context.Context, container string, tags []string) error {
+	_, err := m.containers.ClearLegalHold(ctx, m.groupName, m.accountName, container, storage.LegalHold{Tags: &tags})
+	return errors.Wrapf(err, "cannot clear legal hold of container %s", container)
+}
+
+// AccountCachingManagementOperations wraps ManagementOperations, reading the
+// storage account at most once and answering the getters of its settings from
+// that read. Writes to the account's settings forget it. It is not safe for
+// concurrent use, and is meant to live for no longer than one reconcile.
+type AccountCachingManagementOperations struct {
+	ManagementOperations
+	accountName string
+	account     *storage.Account
+}
+
+var _ ManagementOperations = &AccountCachingManagementOperations{}
+
+// NewAccountCachingManagementOperations returns ManagementOperations that
+// cache the named storage account once they have read it.
+func NewAccountCachingManagementOperations(m ManagementOperations, accountName string) *AccountCachingManagementOperations {
+	return &AccountCachingManagementOperations{ManagementOperations: m, accountName: accountName}
+}
+
+// GetAccount returns the storage account, which may have been cached.
+func (m *AccountCachingManagementOperations) GetAccount(ctx context.Context) (*storage.Account, error) {
+	if m.account != nil {
+		return m.account, nil
+	}
+	a, err := m.ManagementOperations.GetAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m.account = a
+	return a, nil
+}
+
+// GetAllowSharedKeyAccess returns true if the storage account permits
+// requests authorized with its account keys.
+func (m *AccountCachingManagementOperations) GetAllowSharedKeyAccess(ctx context.Context) (bool, error) {
+	a, err := m.GetAccount(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, errGetSharedKeyAccess, m.accountName)
+	}
+	return allowSharedKeyAccess(a), nil
+}
+
+// GetAllowBlobPublicAccess returns whether the storage account permits its
+// containers to allow anonymous public access.
+func (m *AccountCachingManagementOperations) GetAllowBlobPublicAccess(ctx context.Context) (bool, error) {
+	a, err := m.GetAccount(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, errGetBlobPublicAccess, m.accountName)
+	}
+	return allowBlobPublicAccess(a), nil
+}
+
+// GetMinimumTLSVersion returns the minimum TLS version that the storage
+// account permits requests to use.
+func (m *AccountCachingManagementOperations) GetMinimumTLSVersion(ctx context.Context) (string, error) {
+	a, err := m.GetAccount(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, errGetMinimumTLSVersion, m.accountName)
+	}
+	return minimumTLSVersion(a), nil
+}
+
+// GetEnableHTTPSTrafficOnly returns true if the storage account only permits
+// requests made over HTTPS.
+func (m *AccountCachingManagementOperations) GetEnableHTTPSTrafficOnly(ctx context.Context) (bool, error) {
+	a, err := m.GetAccount(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, errGetHTTPSTrafficOnly, m.accountName)
+	}
+	return enableHTTPSTrafficOnly(a), nil
+}
+
+// SetAllowSharedKeyAccess sets whether the storage account permits requests
+// authorized with its account keys, and forgets the cached account.
+func (m *AccountCachingManagementOperations) SetAllowSharedKeyAccess(ctx context.Context, allowed bool) error {
+	m.account = nil
+	return m.ManagementOperations.SetAllowSharedKeyAccess(ctx, allowed)
+}
+
+// SetMinimumTLSVersion sets the minimum TLS version that the storage account
+// permits requests to use, and forgets the cached account.
+func (m *AccountCachingManagementOperations) SetMinimumTLSVersion(ctx context.Context, version string) error {
+	m.account = nil
+	return m.ManagementOperations.SetMinimumTLSVersion(ctx, version)
+}
+
+// SetEnableHTTPSTrafficOnly sets whether the storage account only permits
+// requests made over HTTPS, and forgets the cached account.
+func (m *AccountCachingManagementOperations) SetEnableHTTPSTrafficOnly(ctx context.Context, enabled bool) error {
+	m.account = nil
+	return m.ManagementOperations.SetEnableHTTPSTrafficOnly(ctx, enabled)
+}
+
+// InitiateFailover starts a failover of the storage account, which swaps its
+// locations, and forgets the cached account.
+func (m *AccountCachingManagementOperations) InitiateFailover(ctx context.Context, resourceGroup, accountName string) error {
+	m.account = nil
+	return m.ManagementOperations.InitiateFailover(ctx, resourceGroup, accountName)
+}
+
+// RestoreDeletedAccount requests that the soft-deleted storage account be
+// recovered, and forgets the cached account.
+func (m *AccountCachingManagementOperations) RestoreDeletedAccount(ctx context.Context, location string) error {
+	m.account = nil
+	return m.ManagementOperations.RestoreDeletedAccount(ctx, location)
+}
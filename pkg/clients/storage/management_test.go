@@ -0,0 +1,969 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// newTestManagementHandle returns a ManagementHandle whose requests are served
+// by the supplied handler rather than Azure.
+func newTestManagementHandle(t *testing.T, h http.Handler) *ManagementHandle {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	scopes := storage.NewEncryptionScopesClientWithBaseURI(srv.URL, "sub")
+	scopes.Authorizer = autorest.NullAuthorizer{}
+	scopes.RetryAttempts = 1
+	accounts := storage.NewAccountsClientWithBaseURI(srv.URL, "sub")
+	accounts.Authorizer = autorest.NullAuthorizer{}
+	accounts.RetryAttempts = 1
+	deleted := storage.NewDeletedAccountsClientWithBaseURI(srv.URL, "sub")
+	deleted.Authorizer = autorest.NullAuthorizer{}
+	deleted.RetryAttempts = 1
+	containers := storage.NewBlobContainersClientWithBaseURI(srv.URL, "sub")
+	containers.Authorizer = autorest.NullAuthorizer{}
+	containers.RetryAttempts = 1
+	services := storage.NewBlobServicesClientWithBaseURI(srv.URL, "sub")
+	services.Authorizer = autorest.NullAuthorizer{}
+	services.RetryAttempts = 1
+	return &ManagementHandle{accounts: accounts, scopes: scopes, containers: containers, deleted: deleted, services: services, groupName: "group", accountName: testAccount}
+}
+
+func TestEnsureEncryptionScope(t *testing.T) {
+	type want struct {
+		err     bool
+		methods []string
+		put     *storage.EncryptionScopeProperties
+	}
+	cases := map[string]struct {
+		reason    string
+		keyURI    string
+		getStatus int
+		want      want
+	}{
+		"AlreadyExists": {
+			reason:    "An existing encryption scope should be left as it is.",
+			getStatus: http.StatusOK,
+			want:      want{methods: []string{http.MethodGet}},
+		},
+		"CreateMicrosoftManaged": {
+			reason:    "A missing encryption scope without a key URI should be created with a Microsoft managed key.",
+			getStatus: http.StatusNotFound,
+			want: want{
+				methods: []string{http.MethodGet, http.MethodPut},
+				put:     &storage.EncryptionScopeProperties{Source: storage.EncryptionScopeSourceMicrosoftStorage},
+			},
+		},
+		"CreateCustomerManaged": {
+			reason:    "A missing encryption scope with a key URI should be created with that Key Vault key.",
+			keyURI:    "https://vault/keys/k",
+			getStatus: http.StatusNotFound,
+			want: want{
+				methods: []string{http.MethodGet, http.MethodPut},
+				put: &storage.EncryptionScopeProperties{
+					Source:             storage.EncryptionScopeSourceMicrosoftKeyVault,
+					KeyVaultProperties: &storage.EncryptionScopeKeyVaultProperties{KeyURI: func() *string { s := "https://vault/keys/k"; return &s }()},
+				},
+			},
+		},
+		"GetFailed": {
+			reason:    "Errors other than not found should be returned without creating the scope.",
+			getStatus: http.StatusForbidden,
+			want:      want{err: true, methods: []string{http.MethodGet}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var methods []string
+			var put *storage.EncryptionScopeProperties
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				methods = append(methods, r.Method)
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodPut {
+					s := storage.EncryptionScope{}
+					if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+						t.Errorf("cannot decode encryption scope: %v", err)
+					}
+					put = s.EncryptionScopeProperties
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{}`))
+					return
+				}
+				w.WriteHeader(tc.getStatus)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+
+			err := h.EnsureEncryptionScope(context.Background(), "group", testAccount, "cmk", tc.keyURI)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nEnsureEncryptionScope(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.methods, methods); diff != "" {
+				t.Errorf("\n%s\nEnsureEncryptionScope(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.put, put); diff != "" {
+				t.Errorf("\n%s\nEnsureEncryptionScope(...): -want created scope, +got created scope:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestInitiateFailover(t *testing.T) {
+	type want struct {
+		err      error
+		failover bool
+	}
+	cases := map[string]struct {
+		reason  string
+		account string
+		want    want
+	}{
+		"Initiated": {
+			reason:  "A failover should be initiated for an account that can fail over.",
+			account: `{"properties":{"geoReplicationStats":{"canFailover":true}}}`,
+			want:    want{failover: true},
+		},
+		"InProgress": {
+			reason:  "A failover should not be initiated while one is in progress.",
+			account: `{"properties":{"failoverInProgress":true,"geoReplicationStats":{"canFailover":true}}}`,
+			want:    want{err: ErrFailoverInProgress},
+		},
+		"Unsupported": {
+			reason:  "A failover should not be initiated for an account that cannot fail over.",
+			account: `{"properties":{"geoReplicationStats":{"canFailover":false}}}`,
+			want:    want{err: ErrFailoverUnsupported},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			failover := false
+			var srvURL string
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/failover") {
+					failover = true
+					w.Header().Set("Location", srvURL+"/operations/failover")
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				if r.URL.Query().Get("$expand") != string(storage.AccountExpandGeoReplicationStats) {
+					t.Errorf("\n%s\nInitiateFailover(...): want geo-replication stats expanded, got query %s", tc.reason, r.URL.RawQuery)
+				}
+				_, _ = w.Write([]byte(tc.account))
+			}))
+			srvURL = h.accounts.BaseURI
+
+			err := h.InitiateFailover(context.Background(), "group", testAccount)
+			if !errorIs(err, tc.want.err) {
+				t.Errorf("\n%s\nInitiateFailover(...): want error %v, got %v", tc.reason, tc.want.err, err)
+			}
+			if failover != tc.want.failover {
+				t.Errorf("\n%s\nInitiateFailover(...): want failover requested %t, got %t", tc.reason, tc.want.failover, failover)
+			}
+		})
+	}
+}
+
+func TestGetFailoverState(t *testing.T) {
+	synced := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"properties":{"failoverInProgress":true,"primaryLocation":"westus","secondaryLocation":"eastus",` +
+			`"geoReplicationStats":{"status":"Live","canFailover":true,"lastSyncTime":"2022-06-01T12:00:00Z"}}}`))
+	}))
+
+	got, err := h.GetFailoverState(context.Background(), "group", testAccount)
+	if err != nil {
+		t.Fatalf("GetFailoverState(...): %v", err)
+	}
+	want := FailoverState{
+		InProgress:        true,
+		CanFailover:       true,
+		PrimaryLocation:   "westus",
+		SecondaryLocation: "eastus",
+		LastSyncTime:      &synced,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetFailoverState(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestGetDefaultToOAuth(t *testing.T) {
+	type want struct {
+		enabled bool
+		err     bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		body   string
+		want   want
+	}{
+		"Enabled": {
+			reason: "An account that defaults to OAuth authentication should be reported as such.",
+			status: http.StatusOK,
+			body:   `{"properties":{"defaultToOAuthAuthentication":true}}`,
+			want:   want{enabled: true},
+		},
+		"Unset": {
+			reason: "An account that never set the property should not default to OAuth authentication.",
+			status: http.StatusOK,
+			body:   `{"properties":{}}`,
+			want:   want{enabled: false},
+		},
+		"GetFailed": {
+			reason: "Errors getting the account should be returned.",
+			status: http.StatusForbidden,
+			body:   `{}`,
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.URL.Query().Get("api-version"); got != oauthAPIVersion {
+					t.Errorf("GetDefaultToOAuth(...): want api-version %s, got %s", oauthAPIVersion, got)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			got, err := h.GetDefaultToOAuth(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nGetDefaultToOAuth(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if got != tc.want.enabled {
+				t.Errorf("\n%s\nGetDefaultToOAuth(...): want %t, got %t", tc.reason, tc.want.enabled, got)
+			}
+		})
+	}
+}
+
+func TestSetDefaultToOAuth(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		enabled bool
+		want    string
+	}{
+		"Enable": {
+			reason:  "Enabling should patch the account to default to OAuth authentication.",
+			enabled: true,
+			want:    `{"properties":{"defaultToOAuthAuthentication":true}}`,
+		},
+		"Disable": {
+			reason:  "Disabling should explicitly patch the property to false rather than omit it.",
+			enabled: false,
+			want:    `{"properties":{"defaultToOAuthAuthentication":false}}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var method, path, body string
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, path = r.Method, r.URL.Path
+				b := &strings.Builder{}
+				_, _ = io.Copy(b, r.Body)
+				body = b.String()
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{}`))
+			}))
+
+			if err := h.SetDefaultToOAuth(context.Background(), tc.enabled); err != nil {
+				t.Fatalf("\n%s\nSetDefaultToOAuth(...): %v", tc.reason, err)
+			}
+			if method != http.MethodPatch {
+				t.Errorf("\n%s\nSetDefaultToOAuth(...): want method %s, got %s", tc.reason, http.MethodPatch, method)
+			}
+			if want := "/subscriptions/sub/resourceGroups/group/providers/Microsoft.Storage/storageAccounts/" + testAccount; path != want {
+				t.Errorf("\n%s\nSetDefaultToOAuth(...): want path %s, got %s", tc.reason, want, path)
+			}
+			if diff := cmp.Diff(tc.want, body); diff != "" {
+				t.Errorf("\n%s\nSetDefaultToOAuth(...): -want body, +got body:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetAllowSharedKeyAccess(t *testing.T) {
+	type want struct {
+		allowed bool
+		err     bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		body   string
+		want   want
+	}{
+		"Disallowed": {
+			reason: "An account that disallows shared key access should be reported as such.",
+			status: http.StatusOK,
+			body:   `{"properties":{"allowSharedKeyAccess":false}}`,
+			want:   want{allowed: false},
+		},
+		"Unset": {
+			reason: "An account that never set the property should allow shared key access.",
+			status: http.StatusOK,
+			body:   `{"properties":{}}`,
+			want:   want{allowed: true},
+		},
+		"GetFailed": {
+			reason: "Errors getting the account should be returned.",
+			status: http.StatusForbidden,
+			body:   `{}`,
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			got, err := h.GetAllowSharedKeyAccess(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nGetAllowSharedKeyAccess(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if got != tc.want.allowed {
+				t.Errorf("\n%s\nGetAllowSharedKeyAccess(...): want %t, got %t", tc.reason, tc.want.allowed, got)
+			}
+		})
+	}
+}
+
+func TestGetBlobVersioning(t *testing.T) {
+	type want struct {
+		enabled bool
+		err     bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		body   string
+		want   want
+	}{
+		"Enabled": {
+			reason: "An account that keeps blob versions should be reported as such.",
+			status: http.StatusOK,
+			body:   `{"properties":{"isVersioningEnabled":true}}`,
+			want:   want{enabled: true},
+		},
+		"Unset": {
+			reason: "An account that never enabled versioning should not keep blob versions.",
+			status: http.StatusOK,
+			body:   `{"properties":{}}`,
+			want:   want{enabled: false},
+		},
+		"GetFailed": {
+			reason: "Errors getting the blob service properties should be returned.",
+			status: http.StatusForbidden,
+			body:   `{}`,
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			got, err := h.GetBlobVersioning(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nGetBlobVersioning(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if got != tc.want.enabled {
+				t.Errorf("\n%s\nGetBlobVersioning(...): want %t, got %t", tc.reason, tc.want.enabled, got)
+			}
+		})
+	}
+}
+
+func TestGetAllowBlobPublicAccess(t *testing.T) {
+	type want struct {
+		allowed bool
+		err     bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		body   string
+		want   want
+	}{
+		"Disallowed": {
+			reason: "An account that disallows blob public access should be reported as such.",
+			status: http.StatusOK,
+			body:   `{"properties":{"allowBlobPublicAccess":false}}`,
+			want:   want{allowed: false},
+		},
+		"Unset": {
+			reason: "An account that never set the property should allow blob public access.",
+			status: http.StatusOK,
+			body:   `{"properties":{}}`,
+			want:   want{allowed: true},
+		},
+		"GetFailed": {
+			reason: "Errors getting the account should be returned.",
+			status: http.StatusForbidden,
+			body:   `{}`,
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			got, err := h.GetAllowBlobPublicAccess(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nGetAllowBlobPublicAccess(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if got != tc.want.allowed {
+				t.Errorf("\n%s\nGetAllowBlobPublicAccess(...): want %t, got %t", tc.reason, tc.want.allowed, got)
+			}
+		})
+	}
+}
+
+func TestSetAllowSharedKeyAccess(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		allowed bool
+	}{
+		"Allow": {
+			reason:  "Allowing should patch the account to permit shared key access.",
+			allowed: true,
+		},
+		"Disallow": {
+			reason:  "Disallowing should explicitly patch the property to false rather than omit it.",
+			allowed: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var method string
+			var got *bool
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method = r.Method
+				a := storage.AccountUpdateParameters{}
+				if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+					t.Errorf("cannot decode account update: %v", err)
+				}
+				if a.AccountPropertiesUpdateParameters != nil {
+					got = a.AccountPropertiesUpdateParameters.AllowSharedKeyAccess
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{}`))
+			}))
+
+			if err := h.SetAllowSharedKeyAccess(context.Background(), tc.allowed); err != nil {
+				t.Fatalf("\n%s\nSetAllowSharedKeyAccess(...): %v", tc.reason, err)
+			}
+			if method != http.MethodPatch {
+				t.Errorf("\n%s\nSetAllowSharedKeyAccess(...): want method %s, got %s", tc.reason, http.MethodPatch, method)
+			}
+			if got == nil || *got != tc.allowed {
+				t.Errorf("\n%s\nSetAllowSharedKeyAccess(...): want allowSharedKeyAccess %t, got %v", tc.reason, tc.allowed, got)
+			}
+		})
+	}
+}
+
+func TestGetMinimumTLSVersion(t *testing.T) {
+	type want struct {
+		version string
+		err     bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		body   string
+		want   want
+	}{
+		"Set": {
+			reason: "The minimum TLS version of the account should be returned.",
+			status: http.StatusOK,
+			body:   `{"properties":{"minimumTlsVersion":"TLS1_2"}}`,
+			want:   want{version: "TLS1_2"},
+		},
+		"Unset": {
+			reason: "An account that never set the property should permit TLS 1.0.",
+			status: http.StatusOK,
+			body:   `{"properties":{}}`,
+			want:   want{version: "TLS1_0"},
+		},
+		"GetFailed": {
+			reason: "Errors getting the account should be returned.",
+			status: http.StatusForbidden,
+			body:   `{}`,
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			got, err := h.GetMinimumTLSVersion(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nGetMinimumTLSVersion(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if got != tc.want.version {
+				t.Errorf("\n%s\nGetMinimumTLSVersion(...): want %q, got %q", tc.reason, tc.want.version, got)
+			}
+		})
+	}
+}
+
+func TestSetMinimumTLSVersion(t *testing.T) {
+	type want struct {
+		version  string
+		requests int
+		err      bool
+	}
+	cases := map[string]struct {
+		reason  string
+		version string
+		want    want
+	}{
+		"Set": {
+			reason:  "Setting should patch the account's minimum TLS version.",
+			version: "TLS1_2",
+			want:    want{version: "TLS1_2", requests: 1},
+		},
+		"Invalid": {
+			reason:  "An invalid version should be rejected without updating the account.",
+			version: "TLS1_3",
+			want:    want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			var got storage.MinimumTLSVersion
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				a := storage.AccountUpdateParameters{}
+				if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+					t.Errorf("cannot decode account update: %v", err)
+				}
+				if r.Method == http.MethodPatch && a.AccountPropertiesUpdateParameters != nil {
+					got = a.AccountPropertiesUpdateParameters.MinimumTLSVersion
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{}`))
+			}))
+
+			err := h.SetMinimumTLSVersion(context.Background(), tc.version)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nSetMinimumTLSVersion(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if string(got) != tc.want.version {
+				t.Errorf("\n%s\nSetMinimumTLSVersion(...): want minimumTlsVersion %q, got %q", tc.reason, tc.want.version, got)
+			}
+			if requests != tc.want.requests {
+				t.Errorf("\n%s\nSetMinimumTLSVersion(...): want %d requests, got %d", tc.reason, tc.want.requests, requests)
+			}
+		})
+	}
+}
+
+func TestGetEnableHTTPSTrafficOnly(t *testing.T) {
+	type want struct {
+		enabled bool
+		err     bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		body   string
+		want   want
+	}{
+		"Disabled": {
+			reason: "An account that permits HTTP should be reported as such.",
+			status: http.StatusOK,
+			body:   `{"properties":{"supportsHttpsTrafficOnly":false}}`,
+			want:   want{enabled: false},
+		},
+		"Unset": {
+			reason: "An account that never set the property should only permit HTTPS.",
+			status: http.StatusOK,
+			body:   `{"properties":{}}`,
+			want:   want{enabled: true},
+		},
+		"GetFailed": {
+			reason: "Errors getting the account should be returned.",
+			status: http.StatusForbidden,
+			body:   `{}`,
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			got, err := h.GetEnableHTTPSTrafficOnly(context.Background())
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nGetEnableHTTPSTrafficOnly(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if got != tc.want.enabled {
+				t.Errorf("\n%s\nGetEnableHTTPSTrafficOnly(...): want %t, got %t", tc.reason, tc.want.enabled, got)
+			}
+		})
+	}
+}
+
+func TestSetEnableHTTPSTrafficOnly(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		enabled bool
+	}{
+		"Enable": {
+			reason:  "Enabling should patch the account to only permit HTTPS.",
+			enabled: true,
+		},
+		"Disable": {
+			reason:  "Disabling should explicitly patch the property to false rather than omit it.",
+			enabled: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var method string
+			var got *storage.AccountPropertiesUpdateParameters
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method = r.Method
+				a := storage.AccountUpdateParameters{}
+				if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+					t.Errorf("cannot decode account update: %v", err)
+				}
+				got = a.AccountPropertiesUpdateParameters
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{}`))
+			}))
+
+			if err := h.SetEnableHTTPSTrafficOnly(context.Background(), tc.enabled); err != nil {
+				t.Fatalf("\n%s\nSetEnableHTTPSTrafficOnly(...): %v", tc.reason, err)
+			}
+			if method != http.MethodPatch {
+				t.Errorf("\n%s\nSetEnableHTTPSTrafficOnly(...): want method %s, got %s", tc.reason, http.MethodPatch, method)
+			}
+			want := &storage.AccountPropertiesUpdateParameters{EnableHTTPSTrafficOnly: to.BoolPtr(tc.enabled)}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("\n%s\nSetEnableHTTPSTrafficOnly(...): -want properties, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetDeletedAccount(t *testing.T) {
+	type want struct {
+		deleted *storage.DeletedAccount
+		err     bool
+	}
+	cases := map[string]struct {
+		reason string
+		status int
+		body   string
+		want   want
+	}{
+		"SoftDeleted": {
+			reason: "A soft-deleted account should be returned.",
+			status: http.StatusOK,
+			body:   `{"properties":{"restoreReference":"ref"}}`,
+			want: want{
+				deleted: &storage.DeletedAccount{DeletedAccountProperties: &storage.DeletedAccountProperties{RestoreReference: to.StringPtr("ref")}},
+			},
+		},
+		"NotSoftDeleted": {
+			reason: "An account that is not soft-deleted should be reported as nil rather than an error.",
+			status: http.StatusNotFound,
+			body:   `{"error":{"code":"ResourceNotFound"}}`,
+		},
+		"Failed": {
+			reason: "Errors getting the deleted account should be returned.",
+			status: http.StatusForbidden,
+			body:   `{}`,
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var path string
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			got, err := h.GetDeletedAccount(context.Background(), "westus")
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nGetDeletedAccount(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if want := "/subscriptions/sub/providers/Microsoft.Storage/locations/westus/deletedAccounts/" + testAccount; path != want {
+				t.Errorf("\n%s\nGetDeletedAccount(...): want path %s, got %s", tc.reason, want, path)
+			}
+			if diff := cmp.Diff(tc.want.deleted, got, cmpopts.IgnoreFields(storage.DeletedAccount{}, "Response")); diff != "" {
+				t.Errorf("\n%s\nGetDeletedAccount(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRestoreDeletedAccount(t *testing.T) {
+	type want struct {
+		err  bool
+		reqs []string
+		body string
+	}
+	cases := map[string]struct {
+		reason  string
+		deleted string
+		want    want
+	}{
+		"Restore": {
+			reason:  "A soft-deleted account should be put with its restore reference.",
+			deleted: `{"properties":{"restoreReference":"ref"}}`,
+			want: want{
+				reqs: []string{http.MethodGet, http.MethodPut},
+				body: `{"location":"westus","properties":{"restoreReference":"ref"}}`,
+			},
+		},
+		"NotSoftDeleted": {
+			reason: "An account that is not soft-deleted cannot be restored.",
+			want: want{
+				err:  true,
+				reqs: []string{http.MethodGet},
+			},
+		},
+		"NoRestoreReference": {
+			reason:  "A soft-deleted account without a restore reference cannot be restored.",
+			deleted: `{"properties":{}}`,
+			want: want{
+				err:  true,
+				reqs: []string{http.MethodGet},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var reqs []string
+			var body string
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reqs = append(reqs, r.Method)
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodPut {
+					b := &strings.Builder{}
+					_, _ = io.Copy(b, r.Body)
+					body = b.String()
+					w.WriteHeader(http.StatusAccepted)
+					return
+				}
+				if tc.deleted == "" {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{}`))
+					return
+				}
+				_, _ = w.Write([]byte(tc.deleted))
+			}))
+
+			err := h.RestoreDeletedAccount(context.Background(), "westus")
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nRestoreDeletedAccount(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.reqs, reqs); diff != "" {
+				t.Errorf("\n%s\nRestoreDeletedAccount(...): -want requests, +got requests:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.body, body); diff != "" {
+				t.Errorf("\n%s\nRestoreDeletedAccount(...): -want body, +got body:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGetContainerImmutability(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		body   string
+		want   ContainerImmutability
+	}{
+		"None": {
+			reason: "A container without an immutability policy or legal hold should have neither.",
+			body:   `{"properties":{"hasImmutabilityPolicy":false,"immutabilityPolicy":{"properties":{"immutabilityPeriodSinceCreationInDays":0}},"legalHold":{"hasLegalHold":false,"tags":[]}}}`,
+			want:   ContainerImmutability{},
+		},
+		"Locked": {
+			reason: "A container's locked immutability policy and legal hold tags should be returned.",
+			body: `{"properties":{"hasImmutabilityPolicy":true,"immutabilityPolicy":{"etag":"\"8d5\"","properties":{"immutabilityPeriodSinceCreationInDays":7,"state":"Locked","allowProtectedAppendWrites":true}},` +
+				`"legalHold":{"hasLegalHold":true,"tags":[{"tag":"case1"},{"tag":"case2"}]}}}`,
+			want: ContainerImmutability{
+				Policy:        &ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, AllowProtectedAppendWrites: true, Locked: true, ETag: `"8d5"`},
+				LegalHoldTags: []string{"case1", "case2"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tc.body))
+			}))
+
+			got, err := h.GetContainerImmutability(context.Background(), "cool")
+			if err != nil {
+				t.Fatalf("\n%s\nGetContainerImmutability(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nGetContainerImmutability(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSetContainerImmutabilityPolicy(t *testing.T) {
+	var ifMatch string
+	var put storage.ImmutabilityPolicy
+	h := newTestManagementHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifMatch = r.Header.Get("If-Match")
+		if err := json.NewDecoder(r.Body).Decode(&put); err != nil {
+			t.Errorf("cannot decode immutability policy: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"etag":"\"new\"","properties":{"immutabilityPeriodSinceCreationInDays":30,"state":"Unlocked"}}`))
+	}))
+
+	etag, err := h.SetContainerImmutabilityPolicy(context.Background(), "cool", ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 30, ETag: `"old"`})
+	if err != nil {
+		t.Fatalf("SetContainerImmutabilityPolicy(...): %v", err)
+	}
+	if etag != `"new"` {
+		t.Errorf("SetContainerImmutabilityPolicy(...): want ETag %q, got %q", `"new"`, etag)
+	}
+	if ifMatch != `"old"` {
+		t.Errorf("SetContainerImmutabilityPolicy(...): want If-Match %q, got %q", `"old"`, ifMatch)
+	}
+	want := &storage.ImmutabilityPolicyProperty{ImmutabilityPeriodSinceCreationInDays: to.Int32Ptr(30), AllowProtectedAppendWrites: to.BoolPtr(false)}
+	if diff := cmp.Diff(want, put.ImmutabilityPolicyProperty); diff != "" {
+		t.Errorf("SetContainerImmutabilityPolicy(...): -want policy, +got policy:\n%s", diff)
+	}
+}
+
+// countingManagementOperations counts the reads of a storage account that
+// allows shared key access until a write disallows it.
+type countingManagementOperations struct {
+	ManagementOperations
+	reads   int
+	allowed bool
+}
+
+func (m *countingManagementOperations) GetAccount(context.Context) (*storage.Account, error) {
+	m.reads++
+	return &storage.Account{AccountProperties: &storage.AccountProperties{
+		AllowSharedKeyAccess: to.BoolPtr(m.allowed),
+		MinimumTLSVersion:    storage.MinimumTLSVersionTLS12,
+	}}, nil
+}
+
+func (m *countingManagementOperations) SetAllowSharedKeyAccess(_ context.Context, allowed bool) error {
+	m.allowed = allowed
+	return nil
+}
+
+func TestAccountCachingManagementOperations(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingManagementOperations{allowed: true}
+	m := NewAccountCachingManagementOperations(inner, testAccount)
+
+	if _, err := m.GetAccount(ctx); err != nil {
+		t.Fatalf("GetAccount(...): %v", err)
+	}
+	allowed, err := m.GetAllowSharedKeyAccess(ctx)
+	if err != nil {
+		t.Fatalf("GetAllowSharedKeyAccess(...): %v", err)
+	}
+	if !allowed {
+		t.Errorf("GetAllowSharedKeyAccess(...): want true, got false")
+	}
+	version, err := m.GetMinimumTLSVersion(ctx)
+	if err != nil {
+		t.Fatalf("GetMinimumTLSVersion(...): %v", err)
+	}
+	if version != string(storage.MinimumTLSVersionTLS12) {
+		t.Errorf("GetMinimumTLSVersion(...): want %s, got %s", storage.MinimumTLSVersionTLS12, version)
+	}
+	if inner.reads != 1 {
+		t.Errorf("reads before a write: want 1, got %d", inner.reads)
+	}
+
+	if err := m.SetAllowSharedKeyAccess(ctx, false); err != nil {
+		t.Fatalf("SetAllowSharedKeyAccess(...): %v", err)
+	}
+	allowed, err = m.GetAllowSharedKeyAccess(ctx)
+	if err != nil {
+		t.Fatalf("GetAllowSharedKeyAccess(...): %v", err)
+	}
+	if allowed {
+		t.Errorf("GetAllowSharedKeyAccess(...) after a write: want false, got true")
+	}
+	if inner.reads != 2 {
+		t.Errorf("reads after a write: want 2, got %d", inner.reads)
+	}
+}
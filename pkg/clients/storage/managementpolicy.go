@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"sort"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	azure "github.com/crossplane-contrib/provider-azure/pkg/clients"
+)
+
+// ManagementPolicyRuleTypeLifecycle is the only type of rule a management
+// policy supports.
+const ManagementPolicyRuleTypeLifecycle = "Lifecycle"
+
+const errRuleWithoutActions = "rule %s must set at least one action"
+
+// NewManagementPolicy returns the Azure management policy described by the
+// supplied parameters, with unset rule fields defaulted the way Azure does.
+// An error is returned if a rule takes no action, which Azure rejects.
+func NewManagementPolicy(p v1alpha3.ManagementPolicyParameters) (storage.ManagementPolicy, error) {
+	for _, r := range p.Rules {
+		if !hasManagementPolicyActions(r.Actions) {
+			return storage.ManagementPolicy{}, errors.Errorf(errRuleWithoutActions, r.Name)
+		}
+	}
+	return newManagementPolicy(p), nil
+}
+
+func newManagementPolicy(p v1alpha3.ManagementPolicyParameters) storage.ManagementPolicy {
+	rules := make([]storage.ManagementPolicyRule, len(p.Rules))
+	for i, r := range p.Rules {
+		enabled := true
+		if r.Enabled != nil {
+			enabled = *r.Enabled
+		}
+		rules[i] = storage.ManagementPolicyRule{
+			Name:    azure.ToStringPtr(r.Name),
+			Enabled: &enabled,
+			Type:    azure.ToStringPtr(ManagementPolicyRuleTypeLifecycle),
+			Definition: &storage.ManagementPolicyDefinition{
+				Actions: newManagementPolicyAction(r.Actions),
+				Filters: newManagementPolicyFilter(r.Filters),
+			},
+		}
+	}
+	return storage.ManagementPolicy{
+		ManagementPolicyProperties: &storage.ManagementPolicyProperties{
+			Policy: &storage.ManagementPolicySchema{Rules: &rules},
+		},
+	}
+}
+
+// hasManagementPolicyActions returns true if the supplied actions act on blobs
+// at some age.
+func hasManagementPolicyActions(a v1alpha3.ManagementPolicyActions) bool {
+	if b := a.BaseBlob; b != nil && (b.TierToCoolAfterDays != nil || b.TierToArchiveAfterDays != nil || b.DeleteAfterDays != nil) {
+		return true
+	}
+	for _, v := range []*v1alpha3.ManagementPolicyVersionActions{a.Snapshot, a.Version} {
+		if v != nil && (v.TierToCoolAfterDays != nil || v.TierToArchiveAfterDays != nil || v.DeleteAfterDays != nil) {
+			return true
+		}
+	}
+	return false
+}
+
+func newManagementPolicyFilter(f *v1alpha3.ManagementPolicyFilters) *storage.ManagementPolicyFilter {
+	types := []string{string(v1alpha3.BlobTypeBlockBlob)}
+	var prefixes *[]string
+	if f != nil {
+		if len(f.BlobTypes) > 0 {
+			types = make([]string, len(f.BlobTypes))
+			for i, t := range f.BlobTypes {
+				types[i] = string(t)
+			}
+		}
+		if len(f.PrefixMatch) > 0 {
+			prefixes = azure.ToStringArrayPtr(f.PrefixMatch)
+		}
+	}
+	return &storage.ManagementPolicyFilter{BlobTypes: &types, PrefixMatch: prefixes}
+}
+
+func newManagementPolicyAction(a v1alpha3.ManagementPolicyActions) *storage.ManagementPolicyAction {
+	out := &storage.ManagementPolicyAction{}
+	if b := a.BaseBlob; b != nil {
+		out.BaseBlob = &storage.ManagementPolicyBaseBlob{
+			TierToCool:    afterModification(b.TierToCoolAfterDays),
+			TierToArchive: afterModification(b.TierToArchiveAfterDays),
+			Delete:        afterModification(b.DeleteAfterDays),
+		}
+	}
+	if s := a.Snapshot; s != nil {
+		out.Snapshot = &storage.ManagementPolicySnapShot{
+			TierToCool:    afterCreation(s.TierToCoolAfterDays),
+			TierToArchive: afterCreation(s.TierToArchiveAfterDays),
+			Delete:        afterCreation(s.DeleteAfterDays),
+		}
+	}
+	if v := a.Version; v != nil {
+		out.Version = &storage.ManagementPolicyVersion{
+			TierToCool:    afterCreation(v.TierToCoolAfterDays),
+			TierToArchive: afterCreation(v.TierToArchiveAfterDays),
+			Delete:        afterCreation(v.DeleteAfterDays),
+		}
+	}
+	return out
+}
+
+func afterModification(days *int32) *storage.DateAfterModification {
+	if days == nil {
+		return nil
+	}
+	d := float64(*days)
+	return &storage.DateAfterModification{DaysAfterModificationGreaterThan: &d}
+}
+
+func afterCreation(days *int32) *storage.DateAfterCreation {
+	if days == nil {
+		return nil
+	}
+	d := float64(*days)
+	return &storage.DateAfterCreation{DaysAfterCreationGreaterThan: &d}
+}
+
+// GenerateManagementPolicyObservation produces a ManagementPolicyObservation
+// from the supplied Azure management policy.
+func GenerateManagementPolicyObservation(az storage.ManagementPolicy) v1alpha3.ManagementPolicyObservation {
+	o := v1alpha3.ManagementPolicyObservation{ID: azure.ToString(az.ID)}
+	if p := az.ManagementPolicyProperties; p != nil && p.LastModifiedTime != nil {
+		t := metav1.NewTime(p.LastModifiedTime.Time)
+		o.LastModifiedTime = &t
+	}
+	return o
+}
+
+// ManagementPolicyIsUpToDate returns true if the rules of the supplied Azure
+// management policy are those the supplied parameters describe, regardless
+// of their order.
+func ManagementPolicyIsUpToDate(p v1alpha3.ManagementPolicyParameters, az storage.ManagementPolicy) bool {
+	want := newManagementPolicy(p)
+	return cmp.Equal(sortedManagementPolicyRules(want), sortedManagementPolicyRules(az), cmpopts.EquateEmpty())
+}
+
+func sortedManagementPolicyRules(mp storage.ManagementPolicy) []storage.ManagementPolicyRule {
+	if mp.ManagementPolicyProperties == nil || mp.Policy == nil || mp.Policy.Rules == nil {
+		return nil
+	}
+	rules := append([]storage.ManagementPolicyRule(nil), *mp.Policy.Rules...)
+	sort.Slice(rules, func(i, j int) bool {
+		return azure.ToString(rules[i].Name) < azure.ToString(rules[j].Name)
+	})
+	return rules
+}
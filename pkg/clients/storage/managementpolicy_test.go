@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/Azure/go-autorest/autorest/date"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+)
+
+func TestNewManagementPolicy(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		rules  []v1alpha3.ManagementPolicyRule
+		want   []storage.ManagementPolicyRule
+		err    error
+	}{
+		"Defaults": {
+			reason: "Rules should be enabled and apply to block blobs unless stated otherwise.",
+			rules: []v1alpha3.ManagementPolicyRule{{
+				Name:    "expire",
+				Actions: v1alpha3.ManagementPolicyActions{BaseBlob: &v1alpha3.ManagementPolicyBaseBlobActions{DeleteAfterDays: to.Int32Ptr(365)}},
+			}},
+			want: []storage.ManagementPolicyRule{{
+				Name:    to.StringPtr("expire"),
+				Enabled: to.BoolPtr(true),
+				Type:    to.StringPtr(ManagementPolicyRuleTypeLifecycle),
+				Definition: &storage.ManagementPolicyDefinition{
+					Actions: &storage.ManagementPolicyAction{
+						BaseBlob: &storage.ManagementPolicyBaseBlob{
+							Delete: &storage.DateAfterModification{DaysAfterModificationGreaterThan: to.Float64Ptr(365)},
+						},
+					},
+					Filters: &storage.ManagementPolicyFilter{BlobTypes: &[]string{"blockBlob"}},
+				},
+			}},
+		},
+		"Full": {
+			reason: "Every declared action and filter should be converted.",
+			rules: []v1alpha3.ManagementPolicyRule{{
+				Name:    "tiering",
+				Enabled: to.BoolPtr(false),
+				Filters: &v1alpha3.ManagementPolicyFilters{
+					PrefixMatch: []string{"logs/"},
+					BlobTypes:   []v1alpha3.BlobType{v1alpha3.BlobTypeBlockBlob, v1alpha3.BlobTypeAppendBlob},
+				},
+				Actions: v1alpha3.ManagementPolicyActions{
+					BaseBlob: &v1alpha3.ManagementPolicyBaseBlobActions{TierToCoolAfterDays: to.Int32Ptr(30), TierToArchiveAfterDays: to.Int32Ptr(90)},
+					Snapshot: &v1alpha3.ManagementPolicyVersionActions{DeleteAfterDays: to.Int32Ptr(7)},
+					Version:  &v1alpha3.ManagementPolicyVersionActions{TierToCoolAfterDays: to.Int32Ptr(1)},
+				},
+			}},
+			want: []storage.ManagementPolicyRule{{
+				Name:    to.StringPtr("tiering"),
+				Enabled: to.BoolPtr(false),
+				Type:    to.StringPtr(ManagementPolicyRuleTypeLifecycle),
+				Definition: &storage.ManagementPolicyDefinition{
+					Actions: &storage.ManagementPolicyAction{
+						BaseBlob: &storage.ManagementPolicyBaseBlob{
+							TierToCool:    &storage.DateAfterModification{DaysAfterModificationGreaterThan: to.Float64Ptr(30)},
+							TierToArchive: &storage.DateAfterModification{DaysAfterModificationGreaterThan: to.Float64Ptr(90)},
+						},
+						Snapshot: &storage.ManagementPolicySnapShot{
+							Delete: &storage.DateAfterCreation{DaysAfterCreationGreaterThan: to.Float64Ptr(7)},
+						},
+						Version: &storage.ManagementPolicyVersion{
+							TierToCool: &storage.DateAfterCreation{DaysAfterCreationGreaterThan: to.Float64Ptr(1)},
+						},
+					},
+					Filters: &storage.ManagementPolicyFilter{
+						PrefixMatch: &[]string{"logs/"},
+						BlobTypes:   &[]string{"blockBlob", "appendBlob"},
+					},
+				},
+			}},
+		},
+		"NoActions": {
+			reason: "Rules that take no action should be rejected.",
+			rules: []v1alpha3.ManagementPolicyRule{
+				{Name: "expire", Actions: v1alpha3.ManagementPolicyActions{Version: &v1alpha3.ManagementPolicyVersionActions{DeleteAfterDays: to.Int32Ptr(7)}}},
+				{Name: "noop", Actions: v1alpha3.ManagementPolicyActions{BaseBlob: &v1alpha3.ManagementPolicyBaseBlobActions{}}},
+			},
+			err: errors.Errorf(errRuleWithoutActions, "noop"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewManagementPolicy(v1alpha3.ManagementPolicyParameters{Rules: tc.rules})
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nNewManagementPolicy(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want, *got.Policy.Rules); diff != "" {
+				t.Errorf("\n%s\nNewManagementPolicy(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestManagementPolicyIsUpToDate(t *testing.T) {
+	rule := func(name string, days float64) storage.ManagementPolicyRule {
+		return storage.ManagementPolicyRule{
+			Name:    to.StringPtr(name),
+			Enabled: to.BoolPtr(true),
+			Type:    to.StringPtr(ManagementPolicyRuleTypeLifecycle),
+			Definition: &storage.ManagementPolicyDefinition{
+				Actions: &storage.ManagementPolicyAction{
+					BaseBlob: &storage.ManagementPolicyBaseBlob{
+						Delete: &storage.DateAfterModification{DaysAfterModificationGreaterThan: to.Float64Ptr(days)},
+					},
+				},
+				Filters: &storage.ManagementPolicyFilter{BlobTypes: &[]string{"blockBlob"}},
+			},
+		}
+	}
+	policy := func(rules ...storage.ManagementPolicyRule) storage.ManagementPolicy {
+		return storage.ManagementPolicy{ManagementPolicyProperties: &storage.ManagementPolicyProperties{
+			Policy: &storage.ManagementPolicySchema{Rules: &rules},
+		}}
+	}
+	params := v1alpha3.ManagementPolicyParameters{Rules: []v1alpha3.ManagementPolicyRule{
+		{Name: "a", Actions: v1alpha3.ManagementPolicyActions{BaseBlob: &v1alpha3.ManagementPolicyBaseBlobActions{DeleteAfterDays: to.Int32Ptr(30)}}},
+		{Name: "b", Actions: v1alpha3.ManagementPolicyActions{BaseBlob: &v1alpha3.ManagementPolicyBaseBlobActions{DeleteAfterDays: to.Int32Ptr(60)}}},
+	}}
+
+	cases := map[string]struct {
+		reason string
+		az     storage.ManagementPolicy
+		want   bool
+	}{
+		"UpToDate": {
+			reason: "Policies whose rules match, in any order, should be up to date.",
+			az:     policy(rule("b", 60), rule("a", 30)),
+			want:   true,
+		},
+		"Changed": {
+			reason: "Policies whose rules act at a different age should not be up to date.",
+			az:     policy(rule("a", 30), rule("b", 90)),
+			want:   false,
+		},
+		"Unwanted": {
+			reason: "Policies with rules that are not declared should not be up to date.",
+			az:     policy(rule("a", 30), rule("b", 60), rule("c", 1)),
+			want:   false,
+		},
+		"Empty": {
+			reason: "Policies without rules should not be up to date.",
+			az:     storage.ManagementPolicy{},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ManagementPolicyIsUpToDate(params, tc.az)
+			if got != tc.want {
+				t.Errorf("\n%s\nManagementPolicyIsUpToDate(...): want %t, got %t", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGenerateManagementPolicyObservation(t *testing.T) {
+	modified := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	az := storage.ManagementPolicy{
+		ID:                         to.StringPtr("/subscriptions/x/managementPolicies/default"),
+		ManagementPolicyProperties: &storage.ManagementPolicyProperties{LastModifiedTime: &date.Time{Time: modified}},
+	}
+	want := v1alpha3.ManagementPolicyObservation{ID: "/subscriptions/x/managementPolicies/default", LastModifiedTime: &metav1.Time{Time: modified}}
+	if diff := cmp.Diff(want, GenerateManagementPolicyObservation(az)); diff != "" {
+		t.Errorf("GenerateManagementPolicyObservation(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// Container name limits.
+const (
+	minContainerNameLength = 3
+	maxContainerNameLength = 63
+)
+
+// containerNameRE matches container names of lowercase letters, numbers and
+// single hyphens that start and end with a letter or number.
+var containerNameRE = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reservedContainerNames are the names of containers that Azure reserves.
+// They are valid despite not matching containerNameRE.
+var reservedContainerNames = map[string]bool{
+	"$root": true,
+	"$web":  true,
+	"$logs": true,
+}
+
+// ValidateContainerName returns an error unless the supplied name is a valid
+// Azure blob container name: 3 to 63 lowercase letters, numbers and hyphens
+// that start and end with a letter or number, with no consecutive hyphens.
+// The names Azure reserves for special containers, such as $web, are valid.
+func ValidateContainerName(name string) error {
+	if reservedContainerNames[name] {
+		return nil
+	}
+	if l := len(name); l < minContainerNameLength || l > maxContainerNameLength {
+		return errors.Errorf("invalid container name %q: must be between %d and %d characters long", name, minContainerNameLength, maxContainerNameLength)
+	}
+	if !containerNameRE.MatchString(name) {
+		return errors.Errorf("invalid container name %q: must consist of lowercase letters, numbers and single hyphens, and start and end with a letter or number", name)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateContainerName(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		name   string
+		valid  bool
+	}{
+		"Valid":              {reason: "Lowercase letters, numbers and hyphens should be valid.", name: "my-container-1", valid: true},
+		"MinimumLength":      {reason: "Three character names should be valid.", name: "abc", valid: true},
+		"MaximumLength":      {reason: "63 character names should be valid.", name: strings.Repeat("a", 63), valid: true},
+		"Reserved":           {reason: "Names Azure reserves for special containers should be valid.", name: "$web", valid: true},
+		"TooShort":           {reason: "Names shorter than three characters should be invalid.", name: "ab"},
+		"TooLong":            {reason: "Names longer than 63 characters should be invalid.", name: strings.Repeat("a", 64)},
+		"Uppercase":          {reason: "Names with uppercase letters should be invalid.", name: "MyContainer"},
+		"LeadingHyphen":      {reason: "Names that start with a hyphen should be invalid.", name: "-container"},
+		"TrailingHyphen":     {reason: "Names that end with a hyphen should be invalid.", name: "container-"},
+		"ConsecutiveHyphens": {reason: "Names with consecutive hyphens should be invalid.", name: "my--container"},
+		"Period":             {reason: "Names with periods, which Kubernetes permits, should be invalid.", name: "my.container"},
+		"UnknownReserved":    {reason: "Names starting with $ that Azure does not reserve should be invalid.", name: "$data"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateContainerName(tc.name)
+			if (err == nil) != tc.valid {
+				t.Errorf("\n%s\nValidateContainerName(%q): want valid %t, got error %v", tc.reason, tc.name, tc.valid, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the OpenTelemetry tracer that storage operations
+// are traced with.
+const TracerName = "github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+
+// NewOpenTelemetryTracer returns a Tracer that starts spans with the supplied
+// OpenTelemetry tracer.
+func NewOpenTelemetryTracer(t trace.Tracer) Tracer {
+	return &otelTracer{tracer: t}
+}
+
+// NewGlobalTracer returns a Tracer that starts spans with the global
+// OpenTelemetry tracer provider. Spans are not recorded unless a provider has
+// been registered, including one registered after the Tracer is returned.
+func NewGlobalTracer() Tracer {
+	return NewOpenTelemetryTracer(otel.Tracer(TracerName))
+}
+
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+func (t *otelTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	ctx, s := t.tracer.Start(ctx, name, trace.WithAttributes(otelAttributes(attrs)...))
+	return ctx, &otelSpan{span: s}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) SetAttributes(attrs ...Attribute) { s.span.SetAttributes(otelAttributes(attrs)...) }
+func (s *otelSpan) RecordError(err error)            { s.span.RecordError(err) }
+func (s *otelSpan) End()                             { s.span.End() }
+
+func (s *otelSpan) SetStatus(st SpanStatus, description string) {
+	if st == SpanStatusError {
+		s.span.SetStatus(codes.Error, description)
+		return
+	}
+	s.span.SetStatus(codes.Ok, "")
+}
+
+func otelAttributes(attrs []Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		case fmt.Stringer:
+			kvs = append(kvs, attribute.String(a.Key, v.String()))
+		default:
+			kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestOpenTelemetryTracer(t *testing.T) {
+	errForbidden := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})).Delete(context.Background())
+
+	type want struct {
+		attrs  map[attribute.Key]attribute.Value
+		status codes.Code
+		events int
+	}
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   want
+	}{
+		"Success": {
+			reason: "An operation that succeeds should end an OpenTelemetry span with an OK status.",
+			want: want{
+				attrs: map[attribute.Key]attribute.Value{
+					AttributeOperation: attribute.StringValue("Delete"),
+					AttributeAccount:   attribute.StringValue(testAccount),
+					AttributeContainer: attribute.StringValue(testContainer),
+				},
+				status: codes.Ok,
+			},
+		},
+		"Error": {
+			reason: "An operation that fails should record its error, class and status code on an OpenTelemetry span with an error status.",
+			err:    errForbidden,
+			want: want{
+				attrs: map[attribute.Key]attribute.Value{
+					AttributeOperation:  attribute.StringValue("Delete"),
+					AttributeAccount:    attribute.StringValue(testAccount),
+					AttributeContainer:  attribute.StringValue(testContainer),
+					AttributeErrorClass: attribute.StringValue(string(ErrorClassAuthorization)),
+					AttributeStatusCode: attribute.IntValue(http.StatusForbidden),
+				},
+				status: codes.Error,
+				events: 1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+			o := NewTracingContainerOperations(stubContainerOperations{err: tc.err}, NewOpenTelemetryTracer(tp.Tracer(TracerName)), testAccount, testContainer)
+
+			_ = o.Delete(context.Background())
+
+			spans := sr.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("\n%s\nTracingContainerOperations.Delete(...): want 1 span, got %d", tc.reason, len(spans))
+			}
+			s := spans[0]
+			got := map[attribute.Key]attribute.Value{}
+			for _, kv := range s.Attributes() {
+				got[kv.Key] = kv.Value
+			}
+			if diff := cmp.Diff(tc.want.attrs, got, cmp.AllowUnexported(attribute.Value{})); diff != "" {
+				t.Errorf("\n%s\nTracingContainerOperations.Delete(...): -want attributes, +got attributes:\n%s", tc.reason, diff)
+			}
+			if s.Status().Code != tc.want.status {
+				t.Errorf("\n%s\nTracingContainerOperations.Delete(...): want status %s, got %s", tc.reason, tc.want.status, s.Status().Code)
+			}
+			if len(s.Events()) != tc.want.events {
+				t.Errorf("\n%s\nTracingContainerOperations.Delete(...): want %d error events, got %d", tc.reason, tc.want.events, len(s.Events()))
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"path"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+// EnforcePrivate removes anonymous public access from each of the supplied
+// containers that permits it, keeping their stored access policies. It is a
+// sweep that runs independently of reconciling the containers, so containers
+// that no longer exist are skipped. Containers that cannot be checked or
+// corrected do not stop the sweep; an error is returned for each of them.
+func EnforcePrivate(ctx context.Context, handles []*ContainerHandle) []error {
+	var errs []error
+	for _, h := range handles {
+		if err := h.enforcePrivate(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (a *ContainerHandle) enforcePrivate(ctx context.Context) error {
+	name := path.Base(a.URL().Path)
+	p, err := a.GetAccessPolicy(ctx, azblob.LeaseAccessConditions{})
+	if IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot get access policy of container %s", name)
+	}
+	if p.BlobPublicAccess() == azblob.PublicAccessNone {
+		return nil
+	}
+	_, err = a.SetAccessPolicy(ctx, azblob.PublicAccessNone, p.Items, azblob.ContainerAccessConditions{})
+	return errors.Wrapf(err, "cannot remove public access from container %s", name)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEnforcePrivate(t *testing.T) {
+	identifiers := []azblob.SignedIdentifier{{
+		ID:           "read-only",
+		AccessPolicy: azblob.AccessPolicy{Start: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Expiry: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), Permission: "r"},
+	}}
+
+	// A container is served with the supplied public access type, or fails
+	// with the supplied status when getting or setting its access policy.
+	type container struct {
+		access    string
+		getStatus int
+		setStatus int
+	}
+	type want struct {
+		errs      int
+		corrected []bool
+	}
+	cases := map[string]struct {
+		reason     string
+		containers []container
+		want       want
+	}{
+		"OnlyPublicCorrected": {
+			reason:     "Only containers that permit public access should be corrected.",
+			containers: []container{{}, {access: "blob"}, {access: "container"}},
+			want:       want{corrected: []bool{false, true, true}},
+		},
+		"ErrorsAggregated": {
+			reason: "Containers that cannot be checked or corrected should not stop the sweep.",
+			containers: []container{
+				{getStatus: http.StatusForbidden},
+				{access: "blob", setStatus: http.StatusForbidden},
+				{access: "container"},
+			},
+			want: want{errs: 2, corrected: []bool{false, true, true}},
+		},
+		"NotFoundSkipped": {
+			reason:     "Containers that no longer exist should be skipped.",
+			containers: []container{{getStatus: http.StatusNotFound}, {access: "blob"}},
+			want:       want{corrected: []bool{false, true}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			handles := make([]*ContainerHandle, len(tc.containers))
+			corrected := make([]bool, len(tc.containers))
+			for i, c := range tc.containers {
+				i, c := i, c
+				handles[i] = newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.Method {
+					case http.MethodGet:
+						if c.getStatus != 0 {
+							w.WriteHeader(c.getStatus)
+							return
+						}
+						if c.access != "" {
+							w.Header().Set(headerBlobPublicAccess, c.access)
+						}
+						b, _ := xml.Marshal(struct {
+							XMLName xml.Name                  `xml:"SignedIdentifiers"`
+							Items   []azblob.SignedIdentifier `xml:"SignedIdentifier"`
+						}{Items: identifiers})
+						_, _ = w.Write(b)
+					case http.MethodPut:
+						corrected[i] = true
+						if a := r.Header.Get(headerBlobPublicAccess); a != "" {
+							t.Errorf("\n%s\nEnforcePrivate(...): want no public access, got %q", tc.reason, a)
+						}
+						b, _ := ioutil.ReadAll(r.Body)
+						body := struct {
+							Items []azblob.SignedIdentifier `xml:"SignedIdentifier"`
+						}{}
+						_ = xml.Unmarshal(b, &body)
+						if diff := cmp.Diff(identifiers, body.Items); diff != "" {
+							t.Errorf("\n%s\nEnforcePrivate(...): -want signed identifiers, +got:\n%s", tc.reason, diff)
+						}
+						if c.setStatus != 0 {
+							w.WriteHeader(c.setStatus)
+						}
+					}
+				}))
+			}
+
+			errs := EnforcePrivate(context.Background(), handles)
+			if len(errs) != tc.want.errs {
+				t.Errorf("\n%s\nEnforcePrivate(...): want %d errors, got %v", tc.reason, tc.want.errs, errs)
+			}
+			if diff := cmp.Diff(tc.want.corrected, corrected); diff != "" {
+				t.Errorf("\n%s\nEnforcePrivate(...): -want corrected, +got corrected:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+const (
+	headerRetryAfter = "Retry-After"
+
+	// maxRetryAfter caps how long we honor a Retry-After header for, so that
+	// a misbehaving or heavily throttled service can't stall a reconcile.
+	maxRetryAfter = 30 * time.Second
+)
+
+// newPipeline returns a pipeline like the one azblob.NewPipeline returns, but
+// that waits for as long as a throttled response asks before retrying, that
+// enforces the time budget of an operation's context, that makes requests
+// with the correlation ID of an operation's context, that admits tries
+// through the supplied semaphore, and that makes requests with the supplied
+// blob service API version, if any.
+func newPipeline(c azblob.Credential, o azblob.PipelineOptions, s semaphore, version string) pipeline.Pipeline {
+	// Closest to the API goes first; closest to the wire goes last. The
+	// budget policy must come before the retry policy so that it sees the
+	// outcome of every try together. The try count policy must come before
+	// the retry policy so that it counts every try of an operation together.
+	// The Retry-After policy must come after the retry policy so that it sees
+	// the response of every try. So must the status code policy, so that an
+	// operation is traced with the status code of its last try. The
+	// concurrency policy comes after them, so that tries don't hold the
+	// semaphore while they wait to be retried. The correlation policy must
+	// come before the unique request ID policy, which only sets a client
+	// request ID if there is none. The version policy must come before the
+	// credential, which signs the version header.
+	f := []pipeline.Factory{
+		newBudgetPolicyFactory(),
+		azblob.NewTelemetryPolicyFactory(o.Telemetry),
+		newCorrelationPolicyFactory(),
+		azblob.NewUniqueRequestIDPolicyFactory(),
+		newTryCountPolicyFactory(),
+		azblob.NewRetryPolicyFactory(o.Retry),
+		newRetryAfterPolicyFactory(o.Retry, maxRetryAfter),
+		newStatusCodePolicyFactory(),
+	}
+	if s != nil {
+		f = append(f, newConcurrencyPolicyFactory(s))
+	}
+	f = append(f, newVersionPolicyFactory(version), c, azblob.NewRequestLogPolicyFactory(o.RequestLog), pipeline.MethodFactoryMarker())
+	return pipeline.NewPipeline(f, pipeline.Options{HTTPSender: o.HTTPSender, Log: o.Log})
+}
+
+// tryCountKey keys the number of tries an operation has made so far.
+type tryCountKey struct{}
+
+// newTryCountPolicyFactory returns a factory of policies that count the tries
+// of each operation, so that the Retry-After policy can tell which try is the
+// final one. It must come before the retry policy.
+func newTryCountPolicyFactory() pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, _ *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, req pipeline.Request) (pipeline.Response, error) {
+			return next.Do(context.WithValue(ctx, tryCountKey{}, new(int32)), req)
+		}
+	})
+}
+
+// newRetryAfterPolicyFactory returns a factory of policies that, when a try
+// is throttled with a Retry-After header, wait for that long, up to the
+// supplied maximum, before returning its response to the retry policy. The
+// supplied options must be those of the retry policy. The retry policy waits
+// before every retry too, so only the part of the Retry-After its shortest
+// delay does not cover is waited for. The final try is not waited for,
+// because nothing will retry it.
+func newRetryAfterPolicyFactory(o azblob.RetryOptions, max time.Duration) pipeline.Factory {
+	o = effectiveRetryOptions(o)
+	return pipeline.FactoryFunc(func(next pipeline.Policy, _ *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, req pipeline.Request) (pipeline.Response, error) {
+			resp, err := next.Do(ctx, req)
+
+			// Tries of an operation are made one after another, so the
+			// count needs no lock.
+			n, counted := ctx.Value(tryCountKey{}).(*int32)
+			if counted {
+				*n++
+				if *n >= o.MaxTries {
+					return resp, err
+				}
+			}
+
+			d := retryAfter(throttled(resp, err), time.Now())
+			if d > max {
+				d = max
+			}
+			if counted {
+				d -= minRetryDelay(o, *n+1)
+			}
+			if d <= 0 {
+				return resp, err
+			}
+
+			t := time.NewTimer(d)
+			defer t.Stop()
+			select {
+			case <-t.C:
+				return resp, err
+			case <-ctx.Done():
+				// The retry policy gives up once the operation's context is
+				// done, and retries the timeout if only this try's context is.
+				if resp != nil && resp.Response() != nil && resp.Response().Body != nil {
+					_ = resp.Response().Body.Close()
+				}
+				return nil, ctx.Err()
+			}
+		}
+	})
+}
+
+// minRetryDelay returns the shortest time the retry policy configured by the
+// supplied effective options waits before the supplied try. The SDK does not
+// export its delays, so this mirrors how it calculates them, including its
+// jitter of at least 80% of the delay. Tries of the secondary host, which we
+// don't use, are ignored.
+func minRetryDelay(o azblob.RetryOptions, try int32) time.Duration {
+	var d time.Duration
+	switch o.Policy {
+	case azblob.RetryPolicyExponential:
+		d = time.Duration(int64(1)<<(try-1)-1) * o.RetryDelay
+	case azblob.RetryPolicyFixed:
+		if try > 1 {
+			d = o.RetryDelay
+		}
+	}
+	d = d * 8 / 10
+	if d > o.MaxRetryDelay {
+		d = o.MaxRetryDelay
+	}
+	return d
+}
+
+// throttled returns the HTTP response that throttled a try, if any. The blob
+// service throttles requests with 503 Service Unavailable.
+func throttled(resp pipeline.Response, err error) *http.Response {
+	var r *http.Response
+	var se azblob.StorageError
+	switch {
+	case resp != nil && resp.Response() != nil:
+		r = resp.Response()
+	case errors.As(err, &se):
+		r = se.Response() // nolint: bodyclose
+	}
+	if r == nil || r.StatusCode != http.StatusServiceUnavailable {
+		return nil
+	}
+	return r
+}
+
+// retryAfter returns how long after the supplied time the supplied response
+// asks to be retried. It returns zero if the response has no valid
+// Retry-After header. The header may be either a number of seconds or an
+// HTTP date.
+func retryAfter(r *http.Response, now time.Time) time.Duration {
+	if r == nil {
+		return 0
+	}
+	v := r.Header.Get(headerRetryAfter)
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return t.Sub(now)
+	}
+	return 0
+}
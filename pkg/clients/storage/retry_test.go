@@ -0,0 +1,232 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+// newTestRetryAfterContainerURL returns a ContainerURL whose requests are
+// served by the supplied handler, retried once after a millisecond, and wait
+// for up to the supplied maximum when throttled.
+func newTestRetryAfterContainerURL(t *testing.T, h http.Handler, max time.Duration) azblob.ContainerURL {
+	return newTestRetryAfterContainerURLWithOptions(t, h, azblob.RetryOptions{MaxTries: 2, RetryDelay: time.Millisecond, MaxRetryDelay: time.Millisecond}, max)
+}
+
+// newTestRetryAfterContainerURLWithOptions returns a ContainerURL whose
+// requests are served by the supplied handler, retried according to the
+// supplied options, and wait for up to the supplied maximum when throttled.
+func newTestRetryAfterContainerURLWithOptions(t *testing.T, h http.Handler, o azblob.RetryOptions, max time.Duration) azblob.ContainerURL {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	c, err := azblob.NewSharedKeyCredential(testAccount, testKey)
+	if err != nil {
+		t.Fatalf("azblob.NewSharedKeyCredential(...): %v", err)
+	}
+	p := pipeline.NewPipeline([]pipeline.Factory{
+		newTryCountPolicyFactory(),
+		azblob.NewRetryPolicyFactory(o),
+		newRetryAfterPolicyFactory(o, max),
+		c,
+		pipeline.MethodFactoryMarker(),
+	}, pipeline.Options{})
+	u, _ := url.Parse(srv.URL + "/" + testContainer)
+	return azblob.NewContainerURL(*u, p)
+}
+
+func TestRetryAfterPolicy(t *testing.T) {
+	type want struct {
+		min time.Duration
+		max time.Duration
+	}
+	cases := map[string]struct {
+		reason     string
+		retryAfter string
+		max        time.Duration
+		want       want
+	}{
+		"Seconds": {
+			reason:     "The next try should wait for as long as a throttled response asks.",
+			retryAfter: "1",
+			max:        10 * time.Second,
+			want:       want{min: time.Second, max: 5 * time.Second},
+		},
+		"Capped": {
+			reason:     "The next try should wait for no longer than the maximum.",
+			retryAfter: "3600",
+			max:        100 * time.Millisecond,
+			want:       want{min: 100 * time.Millisecond, max: 5 * time.Second},
+		},
+		"NoRetryAfter": {
+			reason: "The next try should not wait when a throttled response does not ask it to.",
+			max:    10 * time.Second,
+			want:   want{max: 500 * time.Millisecond},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mu := &sync.Mutex{}
+			var tries []time.Time
+			u := newTestRetryAfterContainerURL(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				tries = append(tries, time.Now())
+				n := len(tries)
+				mu.Unlock()
+				if n == 1 {
+					if tc.retryAfter != "" {
+						w.Header().Set(headerRetryAfter, tc.retryAfter)
+					}
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}), tc.max)
+
+			if _, err := u.GetProperties(context.Background(), azblob.LeaseAccessConditions{}); err != nil {
+				t.Fatalf("\n%s\nGetProperties(...): %v", tc.reason, err)
+			}
+			if len(tries) != 2 {
+				t.Fatalf("\n%s\nGetProperties(...): want 2 tries, got %d", tc.reason, len(tries))
+			}
+			if d := tries[1].Sub(tries[0]); d < tc.want.min || d > tc.want.max {
+				t.Errorf("\n%s\nGetProperties(...): want retry after between %s and %s, got %s", tc.reason, tc.want.min, tc.want.max, d)
+			}
+		})
+	}
+}
+
+func TestRetryAfterPolicyRetryDelay(t *testing.T) {
+	mu := &sync.Mutex{}
+	var tries []time.Time
+	o := azblob.RetryOptions{MaxTries: 2, RetryDelay: time.Second, MaxRetryDelay: 2 * time.Second}
+	u := newTestRetryAfterContainerURLWithOptions(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tries = append(tries, time.Now())
+		n := len(tries)
+		mu.Unlock()
+		if n == 1 {
+			w.Header().Set(headerRetryAfter, "2")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}), o, time.Minute)
+
+	if _, err := u.GetProperties(context.Background(), azblob.LeaseAccessConditions{}); err != nil {
+		t.Fatalf("GetProperties(...): %v", err)
+	}
+	if len(tries) != 2 {
+		t.Fatalf("GetProperties(...): want 2 tries, got %d", len(tries))
+	}
+	// The retry policy waits between 0.8 and 1.3 seconds of its own, which
+	// should count towards the Retry-After rather than be added to it.
+	if d := tries[1].Sub(tries[0]); d < 2*time.Second || d > 2800*time.Millisecond {
+		t.Errorf("GetProperties(...): want retry after between 2s and 2.8s, got %s", d)
+	}
+}
+
+func TestRetryAfterPolicyFinalTry(t *testing.T) {
+	mu := &sync.Mutex{}
+	var last time.Time
+	u := newTestRetryAfterContainerURL(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		last = time.Now()
+		mu.Unlock()
+		w.Header().Set(headerRetryAfter, "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}), time.Minute)
+
+	if _, err := u.GetProperties(context.Background(), azblob.LeaseAccessConditions{}); err == nil {
+		t.Fatalf("GetProperties(...): want error from throttled final try, got nil")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if d := time.Since(last); d > 500*time.Millisecond {
+		t.Errorf("GetProperties(...): want no wait after the final try, waited %s", d)
+	}
+}
+
+func TestRetryAfterPolicyCancelled(t *testing.T) {
+	u := newTestRetryAfterContainerURL(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRetryAfter, "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}), time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := u.GetProperties(ctx, azblob.LeaseAccessConditions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("GetProperties(...): want %v, got %v", context.Canceled, err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("GetProperties(...): want cancellation to stop waiting, waited %s", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]struct {
+		reason string
+		header string
+		want   time.Duration
+	}{
+		"Seconds": {
+			reason: "A number of seconds should be parsed.",
+			header: "5",
+			want:   5 * time.Second,
+		},
+		"Date": {
+			reason: "An HTTP date should be parsed relative to now.",
+			header: now.Add(10 * time.Second).Format(http.TimeFormat),
+			want:   10 * time.Second,
+		},
+		"Invalid": {
+			reason: "An invalid header should be ignored.",
+			header: "soon",
+		},
+		"Missing": {
+			reason: "A missing header should be ignored.",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &http.Response{Header: http.Header{}}
+			if tc.header != "" {
+				r.Header.Set(headerRetryAfter, tc.header)
+			}
+			if got := retryAfter(r, now); got != tc.want {
+				t.Errorf("\n%s\nretryAfter(...): want %s, got %s", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
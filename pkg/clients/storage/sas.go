@@ -0,0 +1,268 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+)
+
+const (
+	errReservedSASName  = "shared access signature name %q is reserved"
+	errDuplicateSASName = "duplicate shared access signature name %q"
+	errIssueSAS         = "cannot issue shared access signature %q"
+)
+
+// Well known names of container SAS tokens.
+const (
+	SASRead  = "sas-read"
+	SASWrite = "sas-write"
+)
+
+// A SASSpec specifies a container SAS token.
+type SASSpec struct {
+	// Name of the token, for example SASRead.
+	Name string
+
+	// Permissions granted by the token, using the container SAS permission
+	// characters "racwdl".
+	Permissions string
+
+	// Expiry of the token.
+	Expiry time.Time
+
+	// IPRange restricts the token to requests from an IPv4 address, such as
+	// 203.0.113.7, or an inclusive range of them, such as
+	// 203.0.113.0-203.0.113.255. The token is not restricted when it is empty.
+	IPRange string
+
+	// AllowHTTP permits the token to be used over HTTP as well as HTTPS, for
+	// example with a storage emulator. Tokens may only be used over HTTPS
+	// unless it is set.
+	AllowHTTP bool
+}
+
+// ParseSASIPRange parses the supplied IPv4 address, or inclusive range of
+// them, into the IP range of a SAS token. The start of a range must not come
+// after its end.
+func ParseSASIPRange(r string) (azblob.IPRange, error) {
+	start, end, ranged := r, "", false
+	if i := strings.Index(r, "-"); i >= 0 {
+		start, end, ranged = r[:i], r[i+1:], true
+	}
+	ipr := azblob.IPRange{}
+	if ipr.Start = parseIPv4(start); ipr.Start == nil {
+		return azblob.IPRange{}, errors.Errorf("invalid IP range %q: %q is not an IPv4 address", r, start)
+	}
+	if !ranged {
+		return ipr, nil
+	}
+	if ipr.End = parseIPv4(end); ipr.End == nil {
+		return azblob.IPRange{}, errors.Errorf("invalid IP range %q: %q is not an IPv4 address", r, end)
+	}
+	if bytes.Compare(ipr.Start, ipr.End) > 0 {
+		return azblob.IPRange{}, errors.Errorf("invalid IP range %q: start %s comes after end %s", r, ipr.Start, ipr.End)
+	}
+	return ipr, nil
+}
+
+// parseIPv4 returns the supplied IPv4 address in its 4-byte form, or nil if it
+// is not one.
+func parseIPv4(s string) net.IP {
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return nil
+	}
+	return ip.To4()
+}
+
+// GenerateContainerSAS returns a container SAS token, signed with the supplied
+// account key, that grants the permissions of the supplied spec until it
+// expires.
+func GenerateContainerSAS(accountName, accountKey, containerName string, spec SASSpec) (string, error) {
+	perms := &azblob.ContainerSASPermissions{}
+	protocol, ipr, err := sasSignatureValues(spec, perms)
+	if err != nil {
+		return "", err
+	}
+	c, err := azblob.NewSharedKeyCredential(accountName, accountKey)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot create shared key credential")
+	}
+	q, err := azblob.BlobSASSignatureValues{
+		Protocol:      protocol,
+		ExpiryTime:    spec.Expiry.UTC(),
+		Permissions:   perms.String(),
+		ContainerName: containerName,
+		IPRange:       ipr,
+	}.NewSASQueryParameters(c)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot sign SAS token")
+	}
+	return q.Encode(), nil
+}
+
+// GenerateAccountSAS returns an account SAS token, signed with the supplied
+// account key, that grants the permissions of the supplied spec to the
+// account's blob service, its containers and their blobs until it expires.
+// Permissions use the account SAS permission characters "rwdlacup".
+func GenerateAccountSAS(accountName, accountKey string, spec SASSpec) (string, error) {
+	perms := &azblob.AccountSASPermissions{}
+	protocol, ipr, err := sasSignatureValues(spec, perms)
+	if err != nil {
+		return "", err
+	}
+	c, err := azblob.NewSharedKeyCredential(accountName, accountKey)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot create shared key credential")
+	}
+	q, err := azblob.AccountSASSignatureValues{
+		Protocol:      protocol,
+		ExpiryTime:    spec.Expiry.UTC(),
+		Permissions:   perms.String(),
+		IPRange:       ipr,
+		Services:      azblob.AccountSASServices{Blob: true}.String(),
+		ResourceTypes: azblob.AccountSASResourceTypes{Service: true, Container: true, Object: true}.String(),
+	}.NewSASQueryParameters(c)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot sign SAS token")
+	}
+	return q.Encode(), nil
+}
+
+// sasPermissions are the permissions of a kind of SAS token.
+type sasPermissions interface {
+	Parse(s string) error
+	String() string
+}
+
+// sasSignatureValues validates the supplied spec, parsing its permissions into
+// the supplied ones, and returns the protocol and IP range of its token.
+func sasSignatureValues(spec SASSpec, perms sasPermissions) (azblob.SASProtocol, azblob.IPRange, error) {
+	if err := perms.Parse(spec.Permissions); err != nil {
+		return "", azblob.IPRange{}, errors.Wrapf(err, "invalid permissions %q", spec.Permissions)
+	}
+	if perms.String() == "" {
+		return "", azblob.IPRange{}, errors.New("permissions must not be empty")
+	}
+	if spec.Expiry.IsZero() {
+		return "", azblob.IPRange{}, errors.New("expiry must be set")
+	}
+	ipr := azblob.IPRange{}
+	if spec.IPRange != "" {
+		var err error
+		if ipr, err = ParseSASIPRange(spec.IPRange); err != nil {
+			return "", azblob.IPRange{}, err
+		}
+	}
+	if spec.AllowHTTP {
+		return azblob.SASProtocolHTTPSandHTTP, ipr, nil
+	}
+	return azblob.SASProtocolHTTPS, ipr, nil
+}
+
+// GenerateContainerSASSet returns a container SAS token for each of the
+// supplied specs, keyed by spec name. No tokens are returned if any spec is
+// invalid.
+func GenerateContainerSASSet(accountName, accountKey, containerName string, specs []SASSpec) (map[string]string, error) {
+	tokens := make(map[string]string, len(specs))
+	for _, s := range specs {
+		if s.Name == "" {
+			return nil, errors.New("SAS token name must not be empty")
+		}
+		if _, ok := tokens[s.Name]; ok {
+			return nil, errors.Errorf("duplicate SAS token name %q", s.Name)
+		}
+		t, err := GenerateContainerSAS(accountName, accountKey, containerName, s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot generate SAS token %q", s.Name)
+		}
+		tokens[s.Name] = t
+	}
+	return tokens, nil
+}
+
+// SASExpiry returns when the supplied SAS token expires. It returns the zero
+// time if the token has no expiry.
+func SASExpiry(token string) time.Time {
+	p := azblob.NewBlobURLParts(url.URL{RawQuery: token})
+	return p.SAS.ExpiryTime()
+}
+
+// RenewSAS returns the supplied SAS token if it does not expire before the
+// supplied time, and the supplied generator would generate exactly the same
+// token for the supplied spec were it to expire when the token does. That is
+// not the case if the spec or the key that signs tokens has changed since the
+// token was generated. Otherwise RenewSAS returns a new token generated for
+// the supplied spec.
+func RenewSAS(token string, spec SASSpec, renewAt time.Time, generate func(SASSpec) (string, error)) (string, error) {
+	if e := SASExpiry(token); e.After(renewAt) {
+		s := spec
+		s.Expiry = e
+		if t, err := generate(s); err == nil && t == token {
+			return token, nil
+		}
+	}
+	return generate(spec)
+}
+
+// IssueSAS returns a token for each of the supplied shared access signatures,
+// keyed by name, generated by the supplied generator as of the supplied time.
+// Tokens among the supplied current ones are kept until they are due to be
+// reissued, as RenewSAS keeps them. Names must be distinct and must not be
+// among the supplied reserved names, such as those of other connection
+// details. It also returns when the first of the tokens is due to be
+// reissued, or the zero time if there are none.
+func IssueSAS(sigs []v1alpha3.SharedAccessSignature, current map[string][]byte, now time.Time, generate func(SASSpec) (string, error), reserved ...string) (map[string][]byte, time.Time, error) {
+	tokens := make(map[string][]byte, len(sigs))
+	due := time.Time{}
+	for _, s := range sigs {
+		for _, r := range reserved {
+			if s.Name == r {
+				return nil, time.Time{}, errors.Errorf(errReservedSASName, s.Name)
+			}
+		}
+		if _, ok := tokens[s.Name]; ok {
+			return nil, time.Time{}, errors.Errorf(errDuplicateSASName, s.Name)
+		}
+		validity, renewBefore := s.Lifetime()
+		spec := SASSpec{
+			Name:        s.Name,
+			Permissions: s.Permissions,
+			Expiry:      now.Add(validity),
+			IPRange:     s.IPRange,
+			AllowHTTP:   s.AllowHTTP,
+		}
+		t, err := RenewSAS(string(current[s.Name]), spec, now.Add(renewBefore), generate)
+		if err != nil {
+			return nil, time.Time{}, errors.Wrapf(err, errIssueSAS, s.Name)
+		}
+		tokens[s.Name] = []byte(t)
+		if d := SASExpiry(t).Add(-renewBefore); due.IsZero() || d.Before(due) {
+			due = d
+		}
+	}
+	return tokens, due, nil
+}
@@ -0,0 +1,450 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+)
+
+func TestGenerateContainerSASSet(t *testing.T) {
+	readExpiry := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	writeExpiry := time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	// token is the permissions and expiry carried by a SAS token.
+	type token struct {
+		sp string
+		se string
+	}
+	type want struct {
+		tokens map[string]token
+		err    bool
+	}
+	cases := map[string]struct {
+		reason string
+		specs  []SASSpec
+		want   want
+	}{
+		"ReadAndWrite": {
+			reason: "Each token should carry its own permissions and expiry.",
+			specs: []SASSpec{
+				{Name: SASRead, Permissions: "rl", Expiry: readExpiry},
+				{Name: SASWrite, Permissions: "wrlcd", Expiry: writeExpiry},
+			},
+			want: want{
+				tokens: map[string]token{
+					SASRead:  {sp: "rl", se: readExpiry.Format(azblob.SASTimeFormat)},
+					SASWrite: {sp: "rcwdl", se: writeExpiry.Format(azblob.SASTimeFormat)},
+				},
+			},
+		},
+		"InvalidPermissions": {
+			reason: "Permission strings containing characters other than racwdl should be rejected.",
+			specs: []SASSpec{
+				{Name: SASRead, Permissions: "rl", Expiry: readExpiry},
+				{Name: SASWrite, Permissions: "rx", Expiry: writeExpiry},
+			},
+			want: want{err: true},
+		},
+		"EmptyPermissions": {
+			reason: "Tokens that grant no permissions should be rejected.",
+			specs:  []SASSpec{{Name: SASRead, Expiry: readExpiry}},
+			want:   want{err: true},
+		},
+		"NoExpiry": {
+			reason: "Tokens that never expire should be rejected.",
+			specs:  []SASSpec{{Name: SASRead, Permissions: "r"}},
+			want:   want{err: true},
+		},
+		"DuplicateName": {
+			reason: "Specs with the same name should be rejected.",
+			specs: []SASSpec{
+				{Name: SASRead, Permissions: "r", Expiry: readExpiry},
+				{Name: SASRead, Permissions: "rl", Expiry: readExpiry},
+			},
+			want: want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := GenerateContainerSASSet(testAccount, testKey, testContainer, tc.specs)
+			if (err != nil) != tc.want.err {
+				t.Fatalf("\n%s\nGenerateContainerSASSet(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if tc.want.err {
+				if got != nil {
+					t.Errorf("\n%s\nGenerateContainerSASSet(...): want no tokens, got %v", tc.reason, got)
+				}
+				return
+			}
+
+			tokens := map[string]token{}
+			for n, tok := range got {
+				q, err := url.ParseQuery(tok)
+				if err != nil {
+					t.Fatalf("\n%s\nurl.ParseQuery(%q): %v", tc.reason, tok, err)
+				}
+				if q.Get("sig") == "" {
+					t.Errorf("\n%s\nGenerateContainerSASSet(...): token %q is not signed", tc.reason, n)
+				}
+				if q.Get("sr") != "c" {
+					t.Errorf("\n%s\nGenerateContainerSASSet(...): token %q is not a container SAS", tc.reason, n)
+				}
+				tokens[n] = token{sp: q.Get("sp"), se: q.Get("se")}
+			}
+			if diff := cmp.Diff(tc.want.tokens, tokens, cmp.AllowUnexported(token{})); diff != "" {
+				t.Errorf("\n%s\nGenerateContainerSASSet(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGenerateContainerSASIPRange(t *testing.T) {
+	expiry := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		sip string
+		err bool
+	}
+	cases := map[string]struct {
+		reason  string
+		ipRange string
+		want    want
+	}{
+		"Unrestricted": {
+			reason: "Tokens without an IP range should not carry the sip parameter.",
+		},
+		"SingleIP": {
+			reason:  "Tokens restricted to a single IP address should carry it in the sip parameter.",
+			ipRange: "203.0.113.7",
+			want:    want{sip: "203.0.113.7"},
+		},
+		"Range": {
+			reason:  "Tokens restricted to a range of IP addresses should carry it in the sip parameter.",
+			ipRange: "203.0.113.0-203.0.113.255",
+			want:    want{sip: "203.0.113.0-203.0.113.255"},
+		},
+		"InvalidIP": {
+			reason:  "IP ranges that are not IPv4 addresses should be rejected.",
+			ipRange: "203.0.113.300",
+			want:    want{err: true},
+		},
+		"InvalidRangeEnd": {
+			reason:  "IP ranges whose end is not an IPv4 address should be rejected.",
+			ipRange: "203.0.113.0-",
+			want:    want{err: true},
+		},
+		"IPv6": {
+			reason:  "IPv6 addresses should be rejected, since SAS tokens cannot be restricted to them.",
+			ipRange: "2001:db8::1",
+			want:    want{err: true},
+		},
+		"Reversed": {
+			reason:  "IP ranges whose start comes after their end should be rejected.",
+			ipRange: "203.0.113.255-203.0.113.0",
+			want:    want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := GenerateContainerSAS(testAccount, testKey, testContainer, SASSpec{Name: SASRead, Permissions: "r", Expiry: expiry, IPRange: tc.ipRange})
+			if (err != nil) != tc.want.err {
+				t.Fatalf("\n%s\nGenerateContainerSAS(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if tc.want.err {
+				return
+			}
+			q, err := url.ParseQuery(got)
+			if err != nil {
+				t.Fatalf("\n%s\nurl.ParseQuery(%q): %v", tc.reason, got, err)
+			}
+			if diff := cmp.Diff(tc.want.sip, q.Get("sip")); diff != "" {
+				t.Errorf("\n%s\nGenerateContainerSAS(...): -want sip, +got sip:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGenerateContainerSASProtocol(t *testing.T) {
+	expiry := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		reason    string
+		allowHTTP bool
+		want      string
+	}{
+		"HTTPSOnly": {
+			reason: "Tokens should only be usable over HTTPS by default.",
+			want:   "https",
+		},
+		"HTTPAndHTTPS": {
+			reason:    "Tokens should be usable over HTTP too when it is explicitly allowed.",
+			allowHTTP: true,
+			want:      "https,http",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := GenerateContainerSAS(testAccount, testKey, testContainer, SASSpec{Name: SASRead, Permissions: "r", Expiry: expiry, AllowHTTP: tc.allowHTTP})
+			if err != nil {
+				t.Fatalf("\n%s\nGenerateContainerSAS(...): %v", tc.reason, err)
+			}
+			q, err := url.ParseQuery(got)
+			if err != nil {
+				t.Fatalf("\n%s\nurl.ParseQuery(%q): %v", tc.reason, got, err)
+			}
+			if diff := cmp.Diff(tc.want, q.Get("spr")); diff != "" {
+				t.Errorf("\n%s\nGenerateContainerSAS(...): -want spr, +got spr:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGenerateAccountSAS(t *testing.T) {
+	expiry := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		sp  string
+		err bool
+	}
+	cases := map[string]struct {
+		reason string
+		spec   SASSpec
+		want   want
+	}{
+		"ReadWrite": {
+			reason: "Tokens should carry their permissions in the account SAS order.",
+			spec:   SASSpec{Permissions: "lwr", Expiry: expiry},
+			want:   want{sp: "rwl"},
+		},
+		"UpdateAndProcess": {
+			reason: "Account tokens may grant permissions container tokens cannot.",
+			spec:   SASSpec{Permissions: "up", Expiry: expiry},
+			want:   want{sp: "up"},
+		},
+		"InvalidPermissions": {
+			reason: "Permission strings containing characters other than rwdlacup should be rejected.",
+			spec:   SASSpec{Permissions: "rx", Expiry: expiry},
+			want:   want{err: true},
+		},
+		"NoExpiry": {
+			reason: "Tokens that never expire should be rejected.",
+			spec:   SASSpec{Permissions: "r"},
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := GenerateAccountSAS(testAccount, testKey, tc.spec)
+			if (err != nil) != tc.want.err {
+				t.Fatalf("\n%s\nGenerateAccountSAS(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if tc.want.err {
+				return
+			}
+			q, err := url.ParseQuery(got)
+			if err != nil {
+				t.Fatalf("\n%s\nurl.ParseQuery(%q): %v", tc.reason, got, err)
+			}
+			if q.Get("sig") == "" {
+				t.Errorf("\n%s\nGenerateAccountSAS(...): token is not signed", tc.reason)
+			}
+			if diff := cmp.Diff("b", q.Get("ss")); diff != "" {
+				t.Errorf("\n%s\nGenerateAccountSAS(...): -want ss, +got ss:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff("sco", q.Get("srt")); diff != "" {
+				t.Errorf("\n%s\nGenerateAccountSAS(...): -want srt, +got srt:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.sp, q.Get("sp")); diff != "" {
+				t.Errorf("\n%s\nGenerateAccountSAS(...): -want sp, +got sp:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRenewSAS(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	spec := SASSpec{Name: SASRead, Permissions: "rl", Expiry: now.Add(24 * time.Hour)}
+	generate := func(key string) func(SASSpec) (string, error) {
+		return func(s SASSpec) (string, error) {
+			return GenerateContainerSAS(testAccount, key, testContainer, s)
+		}
+	}
+	issued := func(s SASSpec, expiry time.Time) string {
+		s.Expiry = expiry
+		t, err := GenerateContainerSAS(testAccount, testKey, testContainer, s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	otherKey := "b3RoZXIta2V5Cg=="
+
+	cases := map[string]struct {
+		reason   string
+		token    string
+		spec     SASSpec
+		renewAt  time.Time
+		generate func(SASSpec) (string, error)
+		want     time.Time
+	}{
+		"Current": {
+			reason:   "Tokens that do not expire before they are due to be renewed should be kept.",
+			token:    issued(spec, now.Add(12*time.Hour)),
+			spec:     spec,
+			renewAt:  now.Add(8 * time.Hour),
+			generate: generate(testKey),
+			want:     now.Add(12 * time.Hour),
+		},
+		"Due": {
+			reason:   "Tokens that expire before they are due to be renewed should be reissued.",
+			token:    issued(spec, now.Add(4*time.Hour)),
+			spec:     spec,
+			renewAt:  now.Add(8 * time.Hour),
+			generate: generate(testKey),
+			want:     spec.Expiry,
+		},
+		"SpecChanged": {
+			reason:   "Tokens that grant different permissions than desired should be reissued.",
+			token:    issued(SASSpec{Permissions: "r"}, now.Add(12*time.Hour)),
+			spec:     spec,
+			renewAt:  now.Add(8 * time.Hour),
+			generate: generate(testKey),
+			want:     spec.Expiry,
+		},
+		"KeyChanged": {
+			reason:   "Tokens signed with a key other than the current one should be reissued.",
+			token:    issued(spec, now.Add(12*time.Hour)),
+			spec:     spec,
+			renewAt:  now.Add(8 * time.Hour),
+			generate: generate(otherKey),
+			want:     spec.Expiry,
+		},
+		"NoToken": {
+			reason:   "A token should be issued if there is none.",
+			spec:     spec,
+			renewAt:  now.Add(8 * time.Hour),
+			generate: generate(testKey),
+			want:     spec.Expiry,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := RenewSAS(tc.token, tc.spec, tc.renewAt, tc.generate)
+			if err != nil {
+				t.Fatalf("\n%s\nRenewSAS(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, SASExpiry(got)); diff != "" {
+				t.Errorf("\n%s\nRenewSAS(...): -want expiry, +got expiry:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIssueSAS(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	generate := func(s SASSpec) (string, error) {
+		return GenerateContainerSAS(testAccount, testKey, testContainer, s)
+	}
+	read := v1alpha3.SharedAccessSignature{Name: SASRead, Permissions: "rl"}
+	write := v1alpha3.SharedAccessSignature{Name: SASWrite, Permissions: "w", Validity: &metav1.Duration{Duration: 3 * time.Hour}}
+	current, err := generate(SASSpec{Permissions: "rl", Expiry: now.Add(20 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		expiry map[string]time.Time
+		due    time.Time
+		err    error
+	}
+	cases := map[string]struct {
+		reason  string
+		sigs    []v1alpha3.SharedAccessSignature
+		current map[string][]byte
+		want    want
+	}{
+		"Issue": {
+			reason: "Tokens should be issued for each signature, and be due a third of their validity before they expire.",
+			sigs:   []v1alpha3.SharedAccessSignature{read, write},
+			want: want{
+				expiry: map[string]time.Time{SASRead: now.Add(24 * time.Hour), SASWrite: now.Add(3 * time.Hour)},
+				due:    now.Add(2 * time.Hour),
+			},
+		},
+		"KeepCurrent": {
+			reason:  "Tokens that are not yet due to be reissued should be kept.",
+			sigs:    []v1alpha3.SharedAccessSignature{read},
+			current: map[string][]byte{SASRead: []byte(current)},
+			want: want{
+				expiry: map[string]time.Time{SASRead: now.Add(20 * time.Hour)},
+				due:    now.Add(12 * time.Hour),
+			},
+		},
+		"None": {
+			reason: "No tokens should be issued, or be due, without signatures.",
+			want:   want{expiry: map[string]time.Time{}},
+		},
+		"Reserved": {
+			reason: "Signatures may not use a reserved name.",
+			sigs:   []v1alpha3.SharedAccessSignature{{Name: "endpoint", Permissions: "r"}},
+			want:   want{err: errors.Errorf(errReservedSASName, "endpoint")},
+		},
+		"Duplicate": {
+			reason: "Signatures should have distinct names.",
+			sigs:   []v1alpha3.SharedAccessSignature{read, read},
+			want:   want{err: errors.Errorf(errDuplicateSASName, SASRead)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tokens, due, err := IssueSAS(tc.sigs, tc.current, now, generate, "endpoint")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nIssueSAS(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if tc.want.err != nil {
+				return
+			}
+			expiry := map[string]time.Time{}
+			for n, t := range tokens {
+				expiry[n] = SASExpiry(string(t))
+			}
+			if diff := cmp.Diff(tc.want.expiry, expiry); diff != "" {
+				t.Errorf("\n%s\nIssueSAS(...): -want expiry, +got expiry:\n%s", tc.reason, diff)
+			}
+			if !due.Equal(tc.want.due) {
+				t.Errorf("\n%s\nIssueSAS(...): want due %s, got %s", tc.reason, tc.want.due, due)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+)
+
+// A Snapshot is a read-only version of a blob taken at a point in time.
+type Snapshot struct {
+	// Blob is the name of the blob the snapshot was taken of.
+	Blob string
+
+	// Snapshot is the time the snapshot was taken, which identifies it.
+	Snapshot string
+}
+
+// ListSnapshots lists every blob snapshot in the container whose blob name
+// starts with the supplied prefix.
+func (a *ContainerHandle) ListSnapshots(ctx context.Context, prefix string) ([]Snapshot, error) {
+	o := azblob.ListBlobsSegmentOptions{Prefix: prefix, Details: azblob.BlobListingDetails{Snapshots: true}}
+
+	var snapshots []Snapshot
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		page, err := a.ListBlobsFlatSegment(ctx, marker, o)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot list blob snapshots")
+		}
+		for _, b := range page.Segment.BlobItems {
+			if b.Snapshot != "" {
+				snapshots = append(snapshots, Snapshot{Blob: b.Name, Snapshot: b.Snapshot})
+			}
+		}
+		marker = page.NextMarker
+	}
+	return snapshots, nil
+}
+
+// DeleteBlobsWithSnapshots deletes each blob that the supplied snapshots were
+// taken of, along with every snapshot of it. Errors deleting individual blobs
+// are aggregated and returned together.
+func (a *ContainerHandle) DeleteBlobsWithSnapshots(ctx context.Context, snapshots []Snapshot) error {
+	var failed []string
+	for _, name := range snapshotBlobs(snapshots) {
+		_, err := a.NewBlobURL(name).Delete(ctx, azblob.DeleteSnapshotsOptionInclude, azblob.BlobAccessConditions{})
+		if errors.Is(err, ErrOperationBudgetExceeded) {
+			return err
+		}
+		if err != nil && !IsNotFoundError(err) {
+			failed = append(failed, name+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("cannot delete %d blob(s) with snapshots: %s", len(failed), strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// snapshotBlobs returns the names of the blobs the supplied snapshots were
+// taken of, each once, in the order they were first listed.
+func snapshotBlobs(snapshots []Snapshot) []string {
+	seen := map[string]bool{}
+	blobs := []string{}
+	for _, s := range snapshots {
+		if !seen[s.Blob] {
+			seen[s.Blob] = true
+			blobs = append(blobs, s.Blob)
+		}
+	}
+	return blobs
+}
+
+// IsSnapshotsPresent returns true if the supplied error indicates that an
+// operation was not permitted because a blob has snapshots.
+func IsSnapshotsPresent(err error) bool {
+	var se azblob.StorageError
+	return errors.As(err, &se) && se.ServiceCode() == azblob.ServiceCodeSnapshotsPresent
+}
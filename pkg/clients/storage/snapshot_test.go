@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestListSnapshots(t *testing.T) {
+	pages := map[string]string{
+		"":   blobListing("m1", Blob{Name: "logs/a"}, Blob{Name: "logs/a", Snapshot: "s1"}),
+		"m1": blobListing("", Blob{Name: "logs/b", Snapshot: "s2"}, Blob{Name: "logs/c"}),
+	}
+	rec := &recorder{}
+	var prefixes []string
+	h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.record(r)
+		prefixes = append(prefixes, r.URL.Query().Get("prefix"))
+		fmt.Fprint(w, pages[r.URL.Query().Get("marker")])
+	}))
+
+	got, err := h.ListSnapshots(context.Background(), "logs/")
+	if err != nil {
+		t.Fatalf("ListSnapshots(...): %v", err)
+	}
+	want := []Snapshot{{Blob: "logs/a", Snapshot: "s1"}, {Blob: "logs/b", Snapshot: "s2"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ListSnapshots(...): -want, +got:\n%s", diff)
+	}
+	wantReqs := []string{
+		"GET /testcontainer?include=snapshots",
+		"GET /testcontainer?include=snapshots&marker=m1",
+	}
+	if diff := cmp.Diff(wantReqs, rec.requests()); diff != "" {
+		t.Errorf("ListSnapshots(...): -want requests, +got requests:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"logs/", "logs/"}, prefixes); diff != "" {
+		t.Errorf("ListSnapshots(...): -want prefixes, +got prefixes:\n%s", diff)
+	}
+}
+
+func TestDeleteBlobsWithSnapshots(t *testing.T) {
+	type want struct {
+		err     bool
+		deletes map[string]string
+	}
+	cases := map[string]struct {
+		reason    string
+		snapshots []Snapshot
+		failBlob  string
+		want      want
+	}{
+		"IncludeSnapshots": {
+			reason:    "Each snapshotted blob should be deleted once, including its snapshots.",
+			snapshots: []Snapshot{{Blob: "a", Snapshot: "s1"}, {Blob: "a", Snapshot: "s2"}, {Blob: "b", Snapshot: "s3"}},
+			want: want{
+				deletes: map[string]string{"/testcontainer/a": "include", "/testcontainer/b": "include"},
+			},
+		},
+		"DeleteFailed": {
+			reason:    "Errors deleting blobs should be aggregated and returned.",
+			snapshots: []Snapshot{{Blob: "a", Snapshot: "s1"}, {Blob: "b", Snapshot: "s2"}},
+			failBlob:  "/testcontainer/a",
+			want: want{
+				err:     true,
+				deletes: map[string]string{"/testcontainer/a": "include", "/testcontainer/b": "include"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mu := sync.Mutex{}
+			deletes := map[string]string{}
+			h := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				deletes[r.URL.Path] = r.Header.Get("x-ms-delete-snapshots")
+				mu.Unlock()
+				if r.URL.Path == tc.failBlob {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+
+			err := h.DeleteBlobsWithSnapshots(context.Background(), tc.snapshots)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nDeleteBlobsWithSnapshots(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.deletes, deletes); diff != "" {
+				t.Errorf("\n%s\nDeleteBlobsWithSnapshots(...): -want deletes, +got deletes:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/pkg/errors"
+)
+
+// TokenResource is the Azure AD resource of tokens that authorize requests to
+// the blob service.
+const TokenResource = "https://storage.azure.com/"
+
+// AccountTokenResource returns the Azure AD resource of tokens that authorize
+// requests to the blob service of the named storage account, whose endpoints
+// have the supplied DNS suffix. It is the TokenResource for accounts in the
+// public cloud, and the account's own blob service endpoint, which Azure AD
+// accepts in every cloud, otherwise.
+func AccountTokenResource(accountName, suffix string) string {
+	if suffix == "" || suffix == DefaultEndpointSuffix {
+		return TokenResource
+	}
+	return fmt.Sprintf(blobFormatString, accountName, suffix) + "/"
+}
+
+const (
+	// tokenRefreshMargin is how long before a token expires that it is
+	// refreshed. It must be within the window in which adal considers tokens
+	// due for refresh.
+	tokenRefreshMargin = 2 * time.Minute
+
+	// tokenRefreshRetry is how long to wait before trying again when a token
+	// could not be refreshed.
+	tokenRefreshRetry = 30 * time.Second
+)
+
+// NewTokenCredential returns a credential that authorizes blob service
+// requests with Azure AD tokens issued to the supplied service principal for
+// the TokenResource. Unlike shared key credentials it keeps working when
+// shared key access to the storage account is disabled. Tokens are refreshed
+// in the background shortly before they expire.
+func NewTokenCredential(spt *adal.ServicePrincipalToken) (azblob.TokenCredential, error) {
+	if err := spt.EnsureFresh(); err != nil {
+		return nil, errors.Wrap(err, "cannot get Azure AD token for the blob service")
+	}
+	return azblob.NewTokenCredential(spt.OAuthToken(), func(c azblob.TokenCredential) time.Duration {
+		if err := spt.EnsureFresh(); err != nil {
+			// Keep the current token, which may still be valid, and try
+			// again soon.
+			return tokenRefreshRetry
+		}
+		c.SetToken(spt.OAuthToken())
+		return tokenRefreshIn(spt.Token())
+	}), nil
+}
+
+// tokenRefreshIn returns how long to wait before refreshing the supplied token.
+func tokenRefreshIn(t adal.Token) time.Duration {
+	d := time.Until(t.Expires()) - tokenRefreshMargin
+	if d < tokenRefreshRetry {
+		return tokenRefreshRetry
+	}
+	return d
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+)
+
+type tokenSender struct {
+	tokens []string
+	status int
+}
+
+func (s *tokenSender) Do(r *http.Request) (*http.Response, error) {
+	body := `{"error":"invalid_client"}`
+	status := s.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status == http.StatusOK {
+		t := s.tokens[0]
+		s.tokens = s.tokens[1:]
+		body = fmt.Sprintf(`{"access_token":%q,"token_type":"Bearer","expires_in":"3600","expires_on":"%d","resource":%q}`,
+			t, time.Now().Add(time.Hour).Unix(), TokenResource)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}, nil
+}
+
+func newTestServicePrincipalToken(t *testing.T, s adal.Sender) *adal.ServicePrincipalToken {
+	t.Helper()
+	c, err := adal.NewOAuthConfig("https://login.example.org", "tenant")
+	if err != nil {
+		t.Fatalf("adal.NewOAuthConfig(...): %v", err)
+	}
+	spt, err := adal.NewServicePrincipalToken(*c, "client", "secret", TokenResource)
+	if err != nil {
+		t.Fatalf("adal.NewServicePrincipalToken(...): %v", err)
+	}
+	spt.SetSender(s)
+	return spt
+}
+
+func TestNewTokenCredential(t *testing.T) {
+	s := &tokenSender{tokens: []string{"t1"}}
+	c, err := NewTokenCredential(newTestServicePrincipalToken(t, s))
+	if err != nil {
+		t.Fatalf("NewTokenCredential(...): %v", err)
+	}
+	if got := c.Token(); got != "t1" {
+		t.Errorf("NewTokenCredential(...): want token %q, got %q", "t1", got)
+	}
+}
+
+func TestNewTokenCredentialFailed(t *testing.T) {
+	s := &tokenSender{status: http.StatusUnauthorized}
+	if _, err := NewTokenCredential(newTestServicePrincipalToken(t, s)); err == nil {
+		t.Errorf("NewTokenCredential(...): want error, got nil")
+	}
+}
+
+func TestTokenRefreshIn(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		expires time.Duration
+		want    time.Duration
+	}{
+		"LongLived": {
+			reason:  "Tokens should be refreshed shortly before they expire.",
+			expires: time.Hour,
+			want:    time.Hour - tokenRefreshMargin,
+		},
+		"AboutToExpire": {
+			reason:  "Tokens that are about to expire should not be refreshed in a tight loop.",
+			expires: time.Minute,
+			want:    tokenRefreshRetry,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tok := adal.Token{ExpiresOn: json.Number(strconv.FormatInt(time.Now().Add(tc.expires).Unix(), 10))}
+			got := tokenRefreshIn(tok)
+			if d := got - tc.want; d < -time.Second || d > time.Second {
+				t.Errorf("\n%s\ntokenRefreshIn(...): want %s, got %s", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAccountTokenResource(t *testing.T) {
+	cases := map[string]struct {
+		suffix string
+		want   string
+	}{
+		"Unset":  {suffix: "", want: TokenResource},
+		"Public": {suffix: DefaultEndpointSuffix, want: TokenResource},
+		"China":  {suffix: "core.chinacloudapi.cn", want: "https://account.blob.core.chinacloudapi.cn/"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := AccountTokenResource("account", tc.suffix); got != tc.want {
+				t.Errorf("AccountTokenResource(%q): want %s, got %s", tc.suffix, tc.want, got)
+			}
+		})
+	}
+}
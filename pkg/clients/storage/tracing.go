@@ -0,0 +1,266 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// Span attribute keys.
+const (
+	AttributeOperation  = "azure.storage.operation"
+	AttributeAccount    = "azure.storage.account"
+	AttributeContainer  = "azure.storage.container"
+	AttributeStatusCode = "http.status_code"
+	AttributeErrorClass = "azure.storage.error_class"
+)
+
+// A SpanStatus is the outcome of a traced operation.
+type SpanStatus int
+
+// Span statuses.
+const (
+	SpanStatusOK SpanStatus = iota
+	SpanStatusError
+)
+
+// An Attribute describes a traced operation.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// A Span traces a single operation. Its methods mirror those of an
+// OpenTelemetry span, so an OpenTelemetry tracer can be adapted to a Tracer
+// without buffering.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	SetStatus(s SpanStatus, description string)
+	End()
+}
+
+// A Tracer starts spans.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// TracingContainerOperations decorates ContainerOperations with a span per
+// operation.
+type TracingContainerOperations struct {
+	ContainerOperations
+
+	tracer    Tracer
+	account   string
+	container string
+}
+
+var _ ContainerOperations = &TracingContainerOperations{}
+
+// NewTracingContainerOperations returns ContainerOperations that trace each
+// operation of the supplied ContainerOperations. The supplied operations are
+// returned unchanged when no tracer is configured.
+func NewTracingContainerOperations(o ContainerOperations, t Tracer, account, container string) ContainerOperations {
+	if t == nil {
+		return o
+	}
+	return &TracingContainerOperations{ContainerOperations: o, tracer: t, account: account, container: container}
+}
+
+func (t *TracingContainerOperations) start(ctx context.Context, operation string) (context.Context, Span) {
+	ctx, s := t.tracer.Start(ctx, "storage.container."+operation,
+		Attribute{Key: AttributeOperation, Value: operation},
+		Attribute{Key: AttributeAccount, Value: t.account},
+		Attribute{Key: AttributeContainer, Value: t.container},
+	)
+	return context.WithValue(ctx, statusCodeKey{}, new(int)), s
+}
+
+// statusCodeKey keys the status code of the last response an operation
+// received.
+type statusCodeKey struct{}
+
+// newStatusCodePolicyFactory returns a factory of policies that record the
+// status code of every response in the context of its operation, if the
+// operation is traced, so that operations that succeed are traced with the
+// status code of their last response too.
+func newStatusCodePolicyFactory() pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, _ *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, req pipeline.Request) (pipeline.Response, error) {
+			resp, err := next.Do(ctx, req)
+			if c, ok := ctx.Value(statusCodeKey{}).(*int); ok && resp != nil && resp.Response() != nil {
+				*c = resp.Response().StatusCode
+			}
+			return resp, err
+		}
+	})
+}
+
+// end records the outcome of the supplied span's operation and ends it. The
+// class of an error decides the span's status. Containers are looked up
+// before they are created, so operations that fail because the container
+// does not exist record their error without failing their span. Operations
+// that succeed are traced with the status code of their last response, if
+// they made any.
+func end(ctx context.Context, s Span, err error) {
+	defer s.End()
+	if err == nil {
+		if c, ok := ctx.Value(statusCodeKey{}).(*int); ok && *c != 0 {
+			s.SetAttributes(Attribute{Key: AttributeStatusCode, Value: *c})
+		}
+		s.SetStatus(SpanStatusOK, "")
+		return
+	}
+	class := ClassifyStorageError(err)
+	s.SetAttributes(Attribute{Key: AttributeErrorClass, Value: string(class)})
+	if c := StatusCode(err); c != 0 {
+		s.SetAttributes(Attribute{Key: AttributeStatusCode, Value: c})
+	}
+	s.RecordError(err)
+	if class == ErrorClassNotFound {
+		s.SetStatus(SpanStatusOK, "")
+		return
+	}
+	s.SetStatus(SpanStatusError, string(class)+": "+err.Error())
+}
+
+// Create traces the creation of the container.
+func (t *TracingContainerOperations) Create(ctx context.Context, publicAccessType azblob.PublicAccessType, metadata azblob.Metadata) error {
+	ctx, s := t.start(ctx, "Create")
+	err := t.ContainerOperations.Create(ctx, publicAccessType, metadata)
+	end(ctx, s, err)
+	return err
+}
+
+// Update traces an update of the container.
+func (t *TracingContainerOperations) Update(ctx context.Context, publicAccessType azblob.PublicAccessType, metadata azblob.Metadata) error {
+	ctx, s := t.start(ctx, "Update")
+	err := t.ContainerOperations.Update(ctx, publicAccessType, metadata)
+	end(ctx, s, err)
+	return err
+}
+
+// UpdatePartial traces a partial update of the container.
+func (t *TracingContainerOperations) UpdatePartial(ctx context.Context, publicAccessType *azblob.PublicAccessType, metadata *azblob.Metadata) UpdateResult {
+	ctx, s := t.start(ctx, "UpdatePartial")
+	r := t.ContainerOperations.UpdatePartial(ctx, publicAccessType, metadata)
+	end(ctx, s, r.Err)
+	return r
+}
+
+// Get traces getting the container's access type and metadata.
+func (t *TracingContainerOperations) Get(ctx context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+	ctx, s := t.start(ctx, "Get")
+	access, md, err := t.ContainerOperations.Get(ctx)
+	end(ctx, s, err)
+	return access, md, err
+}
+
+// GetContainerProperties traces getting the container's properties.
+func (t *TracingContainerOperations) GetContainerProperties(ctx context.Context) (*ContainerProperties, error) {
+	ctx, s := t.start(ctx, "GetContainerProperties")
+	p, err := t.ContainerOperations.GetContainerProperties(ctx)
+	end(ctx, s, err)
+	return p, err
+}
+
+// Exists traces checking whether the container exists.
+func (t *TracingContainerOperations) Exists(ctx context.Context) (bool, error) {
+	ctx, s := t.start(ctx, "Exists")
+	ok, err := t.ContainerOperations.Exists(ctx)
+	end(ctx, s, err)
+	return ok, err
+}
+
+// WaitUntilExists traces waiting for the container to exist.
+func (t *TracingContainerOperations) WaitUntilExists(ctx context.Context, timeout time.Duration) error {
+	ctx, s := t.start(ctx, "WaitUntilExists")
+	err := t.ContainerOperations.WaitUntilExists(ctx, timeout)
+	end(ctx, s, err)
+	return err
+}
+
+// Import traces importing the container.
+func (t *TracingContainerOperations) Import(ctx context.Context) (ContainerSnapshot, error) {
+	ctx, s := t.start(ctx, "Import")
+	snap, err := t.ContainerOperations.Import(ctx)
+	end(ctx, s, err)
+	return snap, err
+}
+
+// ListSnapshots traces listing the container's blob snapshots.
+func (t *TracingContainerOperations) ListSnapshots(ctx context.Context, prefix string) ([]Snapshot, error) {
+	ctx, s := t.start(ctx, "ListSnapshots")
+	snaps, err := t.ContainerOperations.ListSnapshots(ctx, prefix)
+	end(ctx, s, err)
+	return snaps, err
+}
+
+// DeleteBlobsWithSnapshots traces deleting blobs with snapshots.
+func (t *TracingContainerOperations) DeleteBlobsWithSnapshots(ctx context.Context, snapshots []Snapshot) error {
+	ctx, s := t.start(ctx, "DeleteBlobsWithSnapshots")
+	err := t.ContainerOperations.DeleteBlobsWithSnapshots(ctx, snapshots)
+	end(ctx, s, err)
+	return err
+}
+
+// ArchiveBlobs traces archiving the container's blobs.
+func (t *TracingContainerOperations) ArchiveBlobs(ctx context.Context, archive string) error {
+	ctx, s := t.start(ctx, "ArchiveBlobs")
+	err := t.ContainerOperations.ArchiveBlobs(ctx, archive)
+	end(ctx, s, err)
+	return err
+}
+
+// GetStoredAccessPolicies traces getting the container's stored access
+// policies.
+func (t *TracingContainerOperations) GetStoredAccessPolicies(ctx context.Context) ([]azblob.SignedIdentifier, error) {
+	ctx, s := t.start(ctx, "GetStoredAccessPolicies")
+	p, err := t.ContainerOperations.GetStoredAccessPolicies(ctx)
+	end(ctx, s, err)
+	return p, err
+}
+
+// SetStoredAccessPolicies traces setting the container's stored access
+// policies.
+func (t *TracingContainerOperations) SetStoredAccessPolicies(ctx context.Context, publicAccessType azblob.PublicAccessType, policies []azblob.SignedIdentifier) error {
+	ctx, s := t.start(ctx, "SetStoredAccessPolicies")
+	err := t.ContainerOperations.SetStoredAccessPolicies(ctx, publicAccessType, policies)
+	end(ctx, s, err)
+	return err
+}
+
+// Delete traces the deletion of the container.
+func (t *TracingContainerOperations) Delete(ctx context.Context) error {
+	ctx, s := t.start(ctx, "Delete")
+	err := t.ContainerOperations.Delete(ctx)
+	end(ctx, s, err)
+	return err
+}
+
+// DeleteWithVersions traces the deletion of the container's blobs, their
+// versions, and then the container.
+func (t *TracingContainerOperations) DeleteWithVersions(ctx context.Context) error {
+	ctx, s := t.start(ctx, "DeleteWithVersions")
+	err := t.ContainerOperations.DeleteWithVersions(ctx)
+	end(ctx, s, err)
+	return err
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+type recordedSpan struct {
+	Name   string
+	Attrs  map[string]interface{}
+	Err    error
+	Status SpanStatus
+	Ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.Attrs[a.Key] = a.Value
+	}
+}
+func (s *recordedSpan) RecordError(err error)             { s.Err = err }
+func (s *recordedSpan) SetStatus(st SpanStatus, _ string) { s.Status = st }
+func (s *recordedSpan) End()                              { s.Ended = true }
+
+// recordingTracer records the spans it starts.
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &recordedSpan{Name: name, Attrs: map[string]interface{}{}}
+	s.SetAttributes(attrs...)
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+// stubContainerOperations returns the same error from every operation.
+type stubContainerOperations struct {
+	err error
+}
+
+func (s stubContainerOperations) Create(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+	return s.err
+}
+func (s stubContainerOperations) Update(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+	return s.err
+}
+func (s stubContainerOperations) UpdatePartial(context.Context, *azblob.PublicAccessType, *azblob.Metadata) UpdateResult {
+	return UpdateResult{Err: s.err}
+}
+func (s stubContainerOperations) Get(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+	return nil, nil, s.err
+}
+func (s stubContainerOperations) GetContainerProperties(context.Context) (*ContainerProperties, error) {
+	return nil, s.err
+}
+func (s stubContainerOperations) Exists(context.Context) (bool, error) { return false, s.err }
+func (s stubContainerOperations) WaitUntilExists(context.Context, time.Duration) error {
+	return s.err
+}
+func (s stubContainerOperations) Import(context.Context) (ContainerSnapshot, error) {
+	return ContainerSnapshot{}, s.err
+}
+func (s stubContainerOperations) ListSnapshots(context.Context, string) ([]Snapshot, error) {
+	return nil, s.err
+}
+func (s stubContainerOperations) DeleteBlobsWithSnapshots(context.Context, []Snapshot) error {
+	return s.err
+}
+func (s stubContainerOperations) ArchiveBlobs(context.Context, string) error { return s.err }
+func (s stubContainerOperations) GetStoredAccessPolicies(context.Context) ([]azblob.SignedIdentifier, error) {
+	return nil, s.err
+}
+func (s stubContainerOperations) SetStoredAccessPolicies(context.Context, azblob.PublicAccessType, []azblob.SignedIdentifier) error {
+	return s.err
+}
+func (s stubContainerOperations) Delete(context.Context) error { return s.err }
+func (s stubContainerOperations) DeleteWithVersions(context.Context) error {
+	return s.err
+}
+
+func TestNewTracingContainerOperations(t *testing.T) {
+	o := stubContainerOperations{}
+	if got := NewTracingContainerOperations(o, nil, testAccount, testContainer); got != ContainerOperations(o) {
+		t.Errorf("NewTracingContainerOperations(...): want the undecorated operations without a tracer, got %T", got)
+	}
+}
+
+func TestTracingContainerOperations(t *testing.T) {
+	errNotFound := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})).Delete(context.Background())
+	errForbidden := newTestContainerHandle(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})).Delete(context.Background())
+
+	attrs := func(operation string, extra ...Attribute) map[string]interface{} {
+		a := map[string]interface{}{
+			AttributeOperation: operation,
+			AttributeAccount:   testAccount,
+			AttributeContainer: testContainer,
+		}
+		for _, e := range extra {
+			a[e.Key] = e.Value
+		}
+		return a
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   []*recordedSpan
+	}{
+		"Success": {
+			reason: "Every operation should end a span with an OK status.",
+			want: []*recordedSpan{
+				{Name: "storage.container.Create", Attrs: attrs("Create"), Ended: true},
+				{Name: "storage.container.Update", Attrs: attrs("Update"), Ended: true},
+				{Name: "storage.container.Get", Attrs: attrs("Get"), Ended: true},
+				{Name: "storage.container.GetContainerProperties", Attrs: attrs("GetContainerProperties"), Ended: true},
+				{Name: "storage.container.Exists", Attrs: attrs("Exists"), Ended: true},
+				{Name: "storage.container.WaitUntilExists", Attrs: attrs("WaitUntilExists"), Ended: true},
+				{Name: "storage.container.Import", Attrs: attrs("Import"), Ended: true},
+				{Name: "storage.container.Delete", Attrs: attrs("Delete"), Ended: true},
+			},
+		},
+		"AzureError": {
+			reason: "Azure errors should be recorded along with their class and the status code of their response.",
+			err:    errForbidden,
+			want: func() []*recordedSpan {
+				spans := []*recordedSpan{}
+				for _, op := range []string{"Create", "Update", "Get", "GetContainerProperties", "Exists", "WaitUntilExists", "Import", "Delete"} {
+					spans = append(spans, &recordedSpan{
+						Name:   "storage.container." + op,
+						Attrs:  attrs(op, Attribute{Key: AttributeErrorClass, Value: string(ErrorClassAuthorization)}, Attribute{Key: AttributeStatusCode, Value: http.StatusForbidden}),
+						Err:    errForbidden,
+						Status: SpanStatusError,
+						Ended:  true,
+					})
+				}
+				return spans
+			}(),
+		},
+		"NotFound": {
+			reason: "Errors caused by a container that does not exist should be recorded without failing their spans.",
+			err:    errNotFound,
+			want: func() []*recordedSpan {
+				spans := []*recordedSpan{}
+				for _, op := range []string{"Create", "Update", "Get", "GetContainerProperties", "Exists", "WaitUntilExists", "Import", "Delete"} {
+					spans = append(spans, &recordedSpan{
+						Name:   "storage.container." + op,
+						Attrs:  attrs(op, Attribute{Key: AttributeErrorClass, Value: string(ErrorClassNotFound)}, Attribute{Key: AttributeStatusCode, Value: http.StatusNotFound}),
+						Err:    errNotFound,
+						Status: SpanStatusOK,
+						Ended:  true,
+					})
+				}
+				return spans
+			}(),
+		},
+		"OtherError": {
+			reason: "Errors not caused by an Azure response should be recorded without a status code.",
+			err:    errors.New("boom"),
+			want: func() []*recordedSpan {
+				spans := []*recordedSpan{}
+				for _, op := range []string{"Create", "Update", "Get", "GetContainerProperties", "Exists", "WaitUntilExists", "Import", "Delete"} {
+					spans = append(spans, &recordedSpan{
+						Name:   "storage.container." + op,
+						Attrs:  attrs(op, Attribute{Key: AttributeErrorClass, Value: string(ErrorClassUnknown)}),
+						Err:    errors.New("boom"),
+						Status: SpanStatusError,
+						Ended:  true,
+					})
+				}
+				return spans
+			}(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tr := &recordingTracer{}
+			o := NewTracingContainerOperations(stubContainerOperations{err: tc.err}, tr, testAccount, testContainer)
+			ctx := context.Background()
+
+			_ = o.Create(ctx, azblob.PublicAccessNone, nil)
+			_ = o.Update(ctx, azblob.PublicAccessNone, nil)
+			_, _, _ = o.Get(ctx)
+			_, _ = o.GetContainerProperties(ctx)
+			_, _ = o.Exists(ctx)
+			_ = o.WaitUntilExists(ctx, time.Second)
+			_, _ = o.Import(ctx)
+			_ = o.Delete(ctx)
+
+			if diff := cmp.Diff(tc.want, tr.spans, cmp.Comparer(func(a, b error) bool {
+				return (a == nil) == (b == nil) && (a == nil || a.Error() == b.Error())
+			})); diff != "" {
+				t.Errorf("\n%s\nTracingContainerOperations: -want spans, +got spans:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTracingStatusCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := azblob.NewSharedKeyCredential(testAccount, testKey)
+	if err != nil {
+		t.Fatalf("azblob.NewSharedKeyCredential(...): %v", err)
+	}
+	p := newPipeline(c, azblob.PipelineOptions{Retry: azblob.RetryOptions{MaxTries: 1}}, nil, "")
+	u, _ := url.Parse(srv.URL + "/" + testContainer)
+	h := &ContainerHandle{ContainerURL: azblob.NewContainerURL(*u, p), pipeline: p}
+
+	tr := &recordingTracer{}
+	o := NewTracingContainerOperations(h, tr, testAccount, testContainer)
+	if err := o.Create(context.Background(), azblob.PublicAccessNone, nil); err != nil {
+		t.Fatalf("Create(...): %v", err)
+	}
+
+	want := []*recordedSpan{{
+		Name: "storage.container.Create",
+		Attrs: map[string]interface{}{
+			AttributeOperation:  "Create",
+			AttributeAccount:    testAccount,
+			AttributeContainer:  testContainer,
+			AttributeStatusCode: http.StatusCreated,
+		},
+		Status: SpanStatusOK,
+		Ended:  true,
+	}}
+	if diff := cmp.Diff(want, tr.spans); diff != "" {
+		t.Errorf("Successful operations should be traced with the status code of their response: -want spans, +got spans:\n%s", diff)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// verifyBackoff paces UpdateVerifyPublicAccess. Its steps are bounded by the
+// timeout rather than counted. It is not capped, because a capped backoff
+// stops once it reaches its cap.
+var verifyBackoff = wait.Backoff{Duration: 100 * time.Millisecond, Factor: 2, Steps: math.MaxInt32}
+
+// UpdateVerifyPublicAccess updates the public access type of the container if
+// it differs from the supplied observed one, and its metadata unless it is
+// nil. A PartialUpdateError is returned if the metadata was updated but the
+// public access type was not. The blob service may take a while to report a
+// changed public access type, so the container's properties are then read
+// until they report it, for up to the supplied timeout. Metadata-only updates
+// are not verified, so nothing is read. The verified properties are returned;
+// they are nil when nothing was read.
+func UpdateVerifyPublicAccess(ctx context.Context, o ContainerOperations, observed, publicAccessType azblob.PublicAccessType, metadata *azblob.Metadata, timeout time.Duration) (*ContainerProperties, error) {
+	var access *azblob.PublicAccessType
+	if observed != publicAccessType {
+		access = &publicAccessType
+	}
+	r := o.UpdatePartial(ctx, access, metadata)
+	switch {
+	case r.Partial():
+		return nil, &PartialUpdateError{Result: r}
+	case r.Err != nil:
+		return nil, r.Err
+	case access == nil:
+		return nil, nil
+	}
+
+	// The timeout bounds polling, not each poll. The azblob pipeline cannot
+	// send requests with less than a second until their context's deadline.
+	wctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var p *ContainerProperties
+	err := wait.ExponentialBackoffWithContext(wctx, verifyBackoff, func() (bool, error) {
+		var err error
+		p, err = o.GetContainerProperties(ctx)
+		return err == nil && p.PublicAccessType == publicAccessType, err
+	})
+	switch {
+	case err == nil:
+		return p, nil
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case wctx.Err() != nil, errors.Is(err, wait.ErrWaitTimeout):
+		got := azblob.PublicAccessType("unknown")
+		if p != nil {
+			got = p.PublicAccessType
+		}
+		return nil, errors.Errorf("container public access type is %q rather than %q %s after it was updated", got, publicAccessType, timeout)
+	}
+	return nil, errors.Wrap(err, "cannot verify container public access type")
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+// laggingContainerOperations report an updated public access type only after
+// it has been read a number of times.
+type laggingContainerOperations struct {
+	stubContainerOperations
+	access  azblob.PublicAccessType
+	pending azblob.PublicAccessType
+	lag     int
+	reads   int
+}
+
+func (l *laggingContainerOperations) UpdatePartial(_ context.Context, access *azblob.PublicAccessType, _ *azblob.Metadata) UpdateResult {
+	if l.err != nil {
+		return UpdateResult{Err: l.err}
+	}
+	if access != nil {
+		l.pending = *access
+	}
+	return UpdateResult{AccessPolicyApplied: access != nil}
+}
+
+func (l *laggingContainerOperations) GetContainerProperties(context.Context) (*ContainerProperties, error) {
+	l.reads++
+	if l.reads > l.lag {
+		l.access = l.pending
+	}
+	return &ContainerProperties{PublicAccessType: l.access}, nil
+}
+
+func TestUpdateVerifyPublicAccess(t *testing.T) {
+	type args struct {
+		observed azblob.PublicAccessType
+		access   azblob.PublicAccessType
+		lag      int
+		err      error
+	}
+	type want struct {
+		props *ContainerProperties
+		err   bool
+
+		// reads is not checked when it is negative.
+		reads int
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"MetadataOnly": {
+			reason: "An update that does not change the public access type should not be verified.",
+			args:   args{observed: azblob.PublicAccessBlob, access: azblob.PublicAccessBlob},
+			want:   want{reads: 0},
+		},
+		"PublicAccessChanged": {
+			reason: "A changed public access type should be read until the blob service reports it.",
+			args:   args{observed: azblob.PublicAccessNone, access: azblob.PublicAccessBlob, lag: 2},
+			want:   want{props: &ContainerProperties{PublicAccessType: azblob.PublicAccessBlob}, reads: 3},
+		},
+		"NeverReported": {
+			reason: "A changed public access type that is never reported should return an error.",
+			args:   args{observed: azblob.PublicAccessNone, access: azblob.PublicAccessBlob, lag: 1000},
+			want:   want{err: true, reads: -1},
+		},
+		"UpdateFailed": {
+			reason: "A failed update should not be verified.",
+			args:   args{observed: azblob.PublicAccessNone, access: azblob.PublicAccessBlob, err: errors.New("boom")},
+			want:   want{err: true, reads: 0},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o := &laggingContainerOperations{
+				stubContainerOperations: stubContainerOperations{err: tc.args.err},
+				access:                  tc.args.observed,
+				lag:                     tc.args.lag,
+			}
+			got, err := UpdateVerifyPublicAccess(context.Background(), o, tc.args.observed, tc.args.access, nil, 500*time.Millisecond)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nUpdateVerifyPublicAccess(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if diff := cmp.Diff(tc.want.props, got); diff != "" {
+				t.Errorf("\n%s\nUpdateVerifyPublicAccess(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if tc.want.reads >= 0 && o.reads != tc.want.reads {
+				t.Errorf("\n%s\nUpdateVerifyPublicAccess(...): want %d reads, got %d", tc.reason, tc.want.reads, o.reads)
+			}
+		})
+	}
+}
+
+// partialContainerOperations return the same result from every update.
+type partialContainerOperations struct {
+	stubContainerOperations
+	result UpdateResult
+	access *azblob.PublicAccessType
+	md     *azblob.Metadata
+}
+
+func (p *partialContainerOperations) UpdatePartial(_ context.Context, access *azblob.PublicAccessType, md *azblob.Metadata) UpdateResult {
+	p.access, p.md = access, md
+	return p.result
+}
+
+func TestUpdateVerifyPublicAccessPartial(t *testing.T) {
+	errBoom := errors.New("boom")
+	md := azblob.Metadata{"owner": "crossplane"}
+
+	type want struct {
+		partial bool
+		err     bool
+		access  bool
+	}
+	cases := map[string]struct {
+		reason   string
+		observed azblob.PublicAccessType
+		result   UpdateResult
+		want     want
+	}{
+		"AccessPolicyFailed": {
+			reason:   "An update that applied the metadata but not the access policy should return a partial update error.",
+			observed: azblob.PublicAccessNone,
+			result:   UpdateResult{MetadataApplied: true, Err: errBoom},
+			want:     want{partial: true, err: true, access: true},
+		},
+		"MetadataFailed": {
+			reason:   "An update that applied nothing should return its error as is.",
+			observed: azblob.PublicAccessNone,
+			result:   UpdateResult{Err: errBoom},
+			want:     want{err: true, access: true},
+		},
+		"AccessPolicyUnchanged": {
+			reason:   "An unchanged access policy should not be written.",
+			observed: azblob.PublicAccessBlob,
+			result:   UpdateResult{MetadataApplied: true},
+			want:     want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o := &partialContainerOperations{result: tc.result}
+			_, err := UpdateVerifyPublicAccess(context.Background(), o, tc.observed, azblob.PublicAccessBlob, &md, time.Millisecond)
+			if (err != nil) != tc.want.err {
+				t.Errorf("\n%s\nUpdateVerifyPublicAccess(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if IsPartialUpdate(err) != tc.want.partial {
+				t.Errorf("\n%s\nUpdateVerifyPublicAccess(...): want partial update error %t, got %v", tc.reason, tc.want.partial, err)
+			}
+			if tc.want.err && !errors.Is(err, errBoom) {
+				t.Errorf("\n%s\nUpdateVerifyPublicAccess(...): want error wrapping %v, got %v", tc.reason, errBoom, err)
+			}
+			if (o.access != nil) != tc.want.access {
+				t.Errorf("\n%s\nUpdateVerifyPublicAccess(...): want access policy written %t, got %v", tc.reason, tc.want.access, o.access)
+			}
+		})
+	}
+}
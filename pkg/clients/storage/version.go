@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-pipeline-go/pipeline"
+	"github.com/pkg/errors"
+)
+
+// apiVersionLayout is the layout of blob service API versions, which are the
+// dates they were released.
+const apiVersionLayout = "2006-01-02"
+
+// ValidateAPIVersion returns an error unless the supplied blob service API
+// version is a date of the form YYYY-MM-DD, such as 2019-12-12.
+func ValidateAPIVersion(v string) error {
+	if _, err := time.Parse(apiVersionLayout, v); err != nil {
+		return errors.Errorf("invalid blob service API version %q: must be a date of the form YYYY-MM-DD", v)
+	}
+	return nil
+}
+
+// minVersionKey keys the earliest blob service API version a request made by
+// send can be made with.
+type minVersionKey struct{}
+
+// withMinVersion returns a context whose requests are never made with a blob
+// service API version older than the supplied one, even when an older one is
+// pinned.
+func withMinVersion(ctx context.Context, v string) context.Context {
+	return context.WithValue(ctx, minVersionKey{}, v)
+}
+
+// newVersionPolicyFactory returns a factory of policies that make every
+// request with the supplied blob service API version, overriding the one the
+// azblob SDK chose. Requests whose context demands a newer version, such as
+// those send builds by hand for features the pinned version lacks, are made
+// with that version instead. Requests are left unchanged when it is empty.
+func newVersionPolicyFactory(v string) pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, _ *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, req pipeline.Request) (pipeline.Response, error) {
+			if v == "" {
+				return next.Do(ctx, req)
+			}
+			version := v
+			// Versions are dates of the form YYYY-MM-DD, so they sort as
+			// strings.
+			if min, ok := ctx.Value(minVersionKey{}).(string); ok && version < min {
+				version = min
+			}
+			req.Header.Set(headerVersion, version)
+			return next.Do(ctx, req)
+		}
+	})
+}
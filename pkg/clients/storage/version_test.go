@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateAPIVersion(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		version string
+		wantErr bool
+	}{
+		"Valid": {
+			reason:  "A date should be a valid API version.",
+			version: "2019-02-02",
+		},
+		"NotADate": {
+			reason:  "A version that is not a date should be invalid.",
+			version: "v2019",
+			wantErr: true,
+		},
+		"InvalidDate": {
+			reason:  "A version that is not a real date should be invalid.",
+			version: "2019-13-45",
+			wantErr: true,
+		},
+		"Empty": {
+			reason:  "An empty version should be invalid.",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateAPIVersion(tc.version)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\nValidateAPIVersion(%q): want error %t, got %v", tc.reason, tc.version, tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAPIVersionPin(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		version string
+		want    []string
+	}{
+		"Pinned": {
+			reason:  "Requests should be made with the pinned version, except those that need a newer one.",
+			version: "2019-02-02",
+			want:    []string{"2019-02-02", extendedServiceVersion},
+		},
+		"PinnedNewer": {
+			reason:  "Every request should be made with a pinned version newer than the ones they choose.",
+			version: "2020-10-02",
+			want:    []string{"2020-10-02", "2020-10-02"},
+		},
+		"Default": {
+			reason: "Requests should be made with the versions they choose when no version is pinned.",
+			want:   []string{azblob.ServiceVersion, extendedServiceVersion},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mu := &sync.Mutex{}
+			var got []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				got = append(got, r.Header.Get(headerVersion))
+			}))
+			t.Cleanup(srv.Close)
+
+			c, err := azblob.NewSharedKeyCredential(testAccount, testKey)
+			if err != nil {
+				t.Fatalf("azblob.NewSharedKeyCredential(...): %v", err)
+			}
+			p := newPipeline(c, azblob.PipelineOptions{Retry: azblob.RetryOptions{MaxTries: 1}}, nil, tc.version)
+			u, _ := url.Parse(srv.URL + "/" + testContainer)
+			h := &ContainerHandle{ContainerURL: azblob.NewContainerURL(*u, p), pipeline: p}
+
+			if _, _, err := h.Get(context.Background()); err != nil {
+				t.Fatalf("\n%s\nGet(...): %v", tc.reason, err)
+			}
+			if _, err := h.GetContainerProperties(context.Background()); err != nil {
+				t.Fatalf("\n%s\nGetContainerProperties(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\n%s header: -want, +got:\n%s", tc.reason, headerVersion, diff)
+			}
+		})
+	}
+}
+
+func TestNewServiceHandleInvalidAPIVersion(t *testing.T) {
+	if _, err := NewServiceHandle(testAccount, testKey, ContainerHandleOptions{APIVersion: "latest"}); err == nil {
+		t.Errorf("NewServiceHandle(...): want error for invalid API version, got nil")
+	}
+}
@@ -42,10 +42,12 @@ import (
 	"github.com/crossplane-contrib/provider-azure/pkg/controller/resourcegroup"
 	"github.com/crossplane-contrib/provider-azure/pkg/controller/storage/account"
 	"github.com/crossplane-contrib/provider-azure/pkg/controller/storage/container"
+	"github.com/crossplane-contrib/provider-azure/pkg/controller/storage/managementpolicy"
 )
 
-// Setup Azure controllers.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
+// Setup Azure controllers. Storage accounts and containers are reconciled with
+// the supplied options.
+func Setup(mgr ctrl.Manager, o controller.Options, accounts account.Options, containers container.Options) error {
 	for _, setup := range []func(ctrl.Manager, controller.Options) error{
 		cache.SetupRedis,
 		compute.SetupAKSCluster,
@@ -62,8 +64,9 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		virtualnetwork.Setup,
 		subnet.Setup,
 		resourcegroup.Setup,
-		account.Setup,
-		container.Setup,
+		account.SetupWithOptions(accounts),
+		container.SetupWithOptions(containers),
+		managementpolicy.Setup,
 		secret.SetupSecret,
 		zone.Setup,
 		recordset.Setup,
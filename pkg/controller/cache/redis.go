@@ -85,7 +85,7 @@ func (c connector) Connect(ctx context.Context, mg resource.Managed) (managed.Ex
 	if err != nil {
 		return nil, errors.Wrap(err, errConnectFailed)
 	}
-	cl := redis.NewClient(creds[azure.CredentialsKeySubscriptionID])
+	cl := redis.NewClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
 	cl.Authorizer = auth
 	return &external{kube: c.kube, client: cl}, nil
 }
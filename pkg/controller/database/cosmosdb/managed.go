@@ -81,7 +81,7 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := documentdb.NewDatabaseAccountsClient(creds[azure.CredentialsKeySubscriptionID])
+	cl := documentdb.NewDatabaseAccountsClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
 	cl.Authorizer = auth
 	return &external{kube: c.kube, client: cl}, nil
 }
@@ -85,7 +85,7 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := mysql.NewServersClient(creds[azure.CredentialsKeySubscriptionID])
+	cl := mysql.NewServersClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
 	cl.Authorizer = auth
 	return &external{kube: c.client, client: database.NewMySQLServerClient(cl), newPasswordFn: password.Generate}, nil
 }
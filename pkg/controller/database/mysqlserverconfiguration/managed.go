@@ -86,7 +86,7 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := mysql.NewConfigurationsClient(creds[azure.CredentialsKeySubscriptionID])
+	cl := mysql.NewConfigurationsClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
 	cl.Authorizer = auth
 	return &external{
 		kube:           c.client,
@@ -82,7 +82,7 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := mysql.NewFirewallRulesClient(creds[azure.CredentialsKeySubscriptionID])
+	cl := mysql.NewFirewallRulesClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
 	cl.Authorizer = auth
 	return &external{client: cl}, nil
 }
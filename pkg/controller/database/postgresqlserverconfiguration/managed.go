@@ -85,7 +85,7 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := postgresql.NewConfigurationsClient(creds[azure.CredentialsKeySubscriptionID])
+	cl := postgresql.NewConfigurationsClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
 	cl.Authorizer = auth
 	return &external{
 		kube:           c.client,
@@ -80,7 +80,7 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := dnsapi.NewZonesClient(creds[azureclients.CredentialsKeySubscriptionID])
+	cl := dnsapi.NewZonesClientWithBaseURI(azureclients.ResourceManagerEndpoint(creds), creds[azureclients.CredentialsKeySubscriptionID])
 	cl.Authorizer = auth
 	return &external{
 		client: dns.NewZoneClient(cl),
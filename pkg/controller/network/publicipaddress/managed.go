@@ -83,7 +83,7 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := azurenetwork.NewPublicIPAddressesClient(creds[azureclients.CredentialsKeySubscriptionID])
+	cl := azurenetwork.NewPublicIPAddressesClientWithBaseURI(azureclients.ResourceManagerEndpoint(creds), creds[azureclients.CredentialsKeySubscriptionID])
 	cl.Authorizer = auth
 	return &external{kube: c.client, client: cl}, nil
 }
@@ -82,7 +82,7 @@ func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	if err != nil {
 		return nil, err
 	}
-	cl := azurenetwork.NewSubnetsClient(creds[azureclients.CredentialsKeySubscriptionID])
+	cl := azurenetwork.NewSubnetsClientWithBaseURI(azureclients.ResourceManagerEndpoint(creds), creds[azureclients.CredentialsKeySubscriptionID])
 	cl.Authorizer = auth
 	return &external{client: cl}, nil
 }
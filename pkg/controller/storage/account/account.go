@@ -19,13 +19,17 @@ package account
 import (
 	"context"
 	"reflect"
+	"strings"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2018-05-01/resources"
 	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2017-06-01/storage"
+	"github.com/Azure/azure-storage-blob-go/azblob"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,7 +44,9 @@ import (
 
 	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
 	azure "github.com/crossplane-contrib/provider-azure/pkg/clients"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/resourcegroup"
 	azurestorage "github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+	"github.com/crossplane-contrib/provider-azure/pkg/features"
 )
 
 const (
@@ -49,6 +55,10 @@ const (
 
 	reconcileTimeout   = 2 * time.Minute
 	requeueAfterOnWait = 30 * time.Second
+
+	errRemoveFailoverRequest = "cannot remove failover request annotation"
+	errEnsureResourceGroup   = "cannot ensure resource group of storage account"
+	errGetSecret             = "cannot get connection secret"
 )
 
 var (
@@ -68,18 +78,52 @@ type Reconciler struct {
 	log logging.Logger
 }
 
-// Setup adds a controller that reconciles Accounts.
+// Options configure the Account controller.
+type Options struct {
+	// KeyTTL is how long the key of each account is cached before it is
+	// fetched from the management plane again. Keys are fetched every time
+	// they are used if it is not positive. Connection secrets are written with
+	// the cached key too, so a rotated key reaches them once the cached key
+	// expires, or sooner if Azure rejects it while the blob service is synced.
+	KeyTTL time.Duration
+}
+
+// DefaultOptions returns the Account controller's default options.
+func DefaultOptions() Options {
+	return Options{
+		KeyTTL: azurestorage.DefaultAccountKeyTTL,
+	}
+}
+
+// Setup adds a controller that reconciles Accounts with the DefaultOptions.
 func Setup(mgr ctrl.Manager, o controller.Options) error {
+	return SetupWithOptions(DefaultOptions())(mgr, o)
+}
+
+// SetupWithOptions returns a function that adds a controller that reconciles
+// Accounts with the supplied options.
+func SetupWithOptions(opts Options) func(ctrl.Manager, controller.Options) error {
+	return func(mgr ctrl.Manager, o controller.Options) error {
+		return setup(mgr, o, opts)
+	}
+}
+
+func setup(mgr ctrl.Manager, o controller.Options, opts Options) error {
 	name := managed.ControllerName(v1alpha3.AccountGroupKind)
 
 	// NOTE(turkenh): We cannot add support for external secret stores to this
 	// resource since it does not use Crossplane Runtime Managed Reconciler.
 	r := &Reconciler{
-		Client:           mgr.GetClient(),
-		syncdeleterMaker: &accountSyncdeleterMaker{mgr.GetClient()},
-		Initializer:      managed.NewNameAsExternalName(mgr.GetClient()),
-		poll:             o.PollInterval,
-		log:              o.Logger.WithValues("controller", name),
+		Client: mgr.GetClient(),
+		syncdeleterMaker: &accountSyncdeleterMaker{
+			Client:      mgr.GetClient(),
+			ensureGroup: o.Features.Enabled(features.EnsureResourceGroups),
+			keys:        azurestorage.NewAccountKeyProvider(opts.KeyTTL),
+			log:         o.Logger.WithValues("controller", name),
+		},
+		Initializer: managed.NewNameAsExternalName(mgr.GetClient()),
+		poll:        o.PollInterval,
+		log:         o.Logger.WithValues("controller", name),
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
@@ -129,6 +173,16 @@ type syncdeleterMaker interface {
 
 type accountSyncdeleterMaker struct {
 	client.Client
+
+	// ensureGroup makes sure the resource group of an account exists before
+	// the account is created.
+	ensureGroup bool
+
+	// keys caches the keys of accounts, which are otherwise listed every
+	// time the account is reconciled.
+	keys *azurestorage.AccountKeyProvider
+
+	log logging.Logger
 }
 
 func (m *accountSyncdeleterMaker) newSyncdeleter(ctx context.Context, b *v1alpha3.Account, poll time.Duration) (syncdeleter, error) {
@@ -137,12 +191,42 @@ func (m *accountSyncdeleterMaker) newSyncdeleter(ctx context.Context, b *v1alpha
 		return nil, errors.Wrap(err, "cannot get auth information")
 	}
 
-	cl := storage.NewAccountsClient(creds[azure.CredentialsKeySubscriptionID])
+	cl := storage.NewAccountsClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
 	cl.Authorizer = auth
 
-	return newAccountSyncDeleter(
-		azurestorage.NewAccountHandle(&cl, b.Spec.ResourceGroupName, meta.GetExternalName(b)),
-		m.Client, b, poll), nil
+	var ao azurestorage.AccountOperations = azurestorage.NewAccountHandle(&cl, b.Spec.ResourceGroupName, meta.GetExternalName(b))
+	if m.keys != nil {
+		ao = azurestorage.NewKeyCachingAccountOperations(ao, m.keys, keyCacheKey(creds[azure.CredentialsKeySubscriptionID], b))
+	}
+	var groups groupEnsurer
+	if m.ensureGroup {
+		gc := resources.NewGroupsClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
+		gc.Authorizer = auth
+		_ = gc.AddToUserAgent(azure.UserAgent)
+		groups = resourcegroup.NewHandle(gc)
+	}
+	token := func(context.Context) (azblob.Credential, error) {
+		spt, err := azure.NewServicePrincipalToken(creds, azurestorage.AccountTokenResource(meta.GetExternalName(b), azure.StorageEndpointSuffix(creds)))
+		if err != nil {
+			return nil, err
+		}
+		return azurestorage.NewTokenCredential(spt)
+	}
+	sd := newAccountSyncDeleter(ao, m.Client, b, poll, groups, azure.StorageEndpointSuffix(creds), token)
+	mh := azurestorage.NewManagementHandleWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID], auth, b.Spec.ResourceGroupName, meta.GetExternalName(b))
+	sd.management = azurestorage.NewAccountCachingManagementOperations(mh, meta.GetExternalName(b))
+	if m.log != nil {
+		sd.log = m.log
+	}
+	return sd, nil
+}
+
+// keyCacheKey returns the key under which the key of the supplied account is
+// cached. Account names are globally unique, but the subscription and
+// resource group are included so that an account recreated elsewhere under
+// the same name is not served a stale key.
+func keyCacheKey(subscription string, b *v1alpha3.Account) string {
+	return strings.Join([]string{subscription, b.Spec.ResourceGroupName, meta.GetExternalName(b)}, "/")
 }
 
 type deleter interface {
@@ -166,7 +250,11 @@ type syncbacker interface {
 }
 
 type secretupdater interface {
-	updatesecret(ctx context.Context, acct *storage.Account) error
+	updatesecret(ctx context.Context, acct *storage.Account) (time.Duration, error)
+}
+
+type blobservicesyncer interface {
+	syncblobservice(ctx context.Context) error
 }
 
 type syncdeleter interface {
@@ -179,14 +267,27 @@ type accountSyncDeleter struct {
 	azurestorage.AccountOperations
 	kube client.Client
 	acct *v1alpha3.Account
+	poll time.Duration
+
+	// management operations of the account, which are used to fail it over
+	// and to reconcile the settings the storage SDK predates.
+	management azurestorage.ManagementOperations
+
+	log logging.Logger
 }
 
-func newAccountSyncDeleter(ao azurestorage.AccountOperations, kube client.Client, b *v1alpha3.Account, poll time.Duration) *accountSyncDeleter {
+// newAccountSyncDeleter returns an accountSyncDeleter that ensures resource
+// groups exist with the supplied ensurer, if any, and reaches blob services
+// under the supplied endpoint suffix, with the supplied token credential if
+// the account disallows shared key access.
+func newAccountSyncDeleter(ao azurestorage.AccountOperations, kube client.Client, b *v1alpha3.Account, poll time.Duration, groups groupEnsurer, endpointSuffix string, token tokenCredentialFn) *accountSyncDeleter {
 	return &accountSyncDeleter{
-		createupdater:     newAccountCreateUpdater(ao, kube, b, poll),
+		createupdater:     newAccountCreateUpdater(ao, kube, b, poll, groups, endpointSuffix, token),
 		AccountOperations: ao,
 		kube:              kube,
 		acct:              b,
+		poll:              poll,
+		log:               logging.NewNopLogger(),
 	}
 }
 
@@ -222,33 +323,134 @@ func (asd *accountSyncDeleter) sync(ctx context.Context) (reconcile.Result, erro
 		return asd.create(ctx)
 	}
 
+	if res, done, err := asd.failover(ctx); done {
+		return res, err
+	}
+
+	if account.AccountProperties != nil && account.ProvisioningState == storage.Succeeded {
+		drift, err := asd.syncsettings(ctx)
+		if err != nil {
+			asd.acct.Status.SetConditions(xpv1.ReconcileError(err))
+			return resultRequeue, asd.kube.Status().Update(ctx, asd.acct)
+		}
+		if len(drift) > 0 {
+			asd.log.Info("Corrected drift of storage account settings", "account", meta.GetExternalName(asd.acct), "drift", strings.Join(drift, "; "))
+		}
+	}
+
 	return asd.update(ctx, account)
 }
 
+// failover initiates a failover of the account if one was requested, and
+// observes it until it completes. The account is not updated while a failover
+// is in progress. It returns true if the reconcile is done.
+func (asd *accountSyncDeleter) failover(ctx context.Context) (reconcile.Result, bool, error) {
+	requested := asd.acct.GetAnnotations()[v1alpha3.AnnotationKeyInitiateFailover] == "true"
+	tracking := asd.acct.Status.Failover != nil && asd.acct.Status.Failover.InProgress
+	if !requested && !tracking {
+		return reconcile.Result{}, false, nil
+	}
+
+	group, name := asd.acct.Spec.ResourceGroupName, meta.GetExternalName(asd.acct)
+	var initiated *metav1.Time
+	var rejected error
+	if requested {
+		err := asd.management.InitiateFailover(ctx, group, name)
+		switch {
+		case err == nil:
+			now := metav1.Now()
+			initiated = &now
+		case errors.Is(err, azurestorage.ErrFailoverInProgress), errors.Is(err, azurestorage.ErrFailoverUnsupported):
+			rejected = err
+		default:
+			// The request is kept, so that it is retried.
+			asd.acct.Status.SetConditions(xpv1.ReconcileError(err))
+			return resultRequeue, true, asd.kube.Status().Update(ctx, asd.acct)
+		}
+
+		// Updating the account resets its status, so it's updated first.
+		meta.RemoveAnnotations(asd.acct, v1alpha3.AnnotationKeyInitiateFailover)
+		if err := asd.kube.Update(ctx, asd.acct); err != nil {
+			return resultRequeue, true, errors.Wrap(err, errRemoveFailoverRequest)
+		}
+	}
+
+	s, err := asd.management.GetFailoverState(ctx, group, name)
+	if err != nil {
+		asd.acct.Status.SetConditions(xpv1.ReconcileError(err))
+		return resultRequeue, true, asd.kube.Status().Update(ctx, asd.acct)
+	}
+
+	if asd.acct.Status.Failover == nil {
+		asd.acct.Status.Failover = &v1alpha3.FailoverStatus{}
+	}
+	fs := asd.acct.Status.Failover
+	if initiated != nil {
+		fs.InitiatedTime = initiated
+	}
+	// A newly initiated failover may not be reported as in progress yet.
+	fs.InProgress = s.InProgress || initiated != nil
+	fs.PrimaryLocation = s.PrimaryLocation
+	fs.SecondaryLocation = s.SecondaryLocation
+	fs.LastSyncTime = nil
+	if s.LastSyncTime != nil {
+		t := metav1.NewTime(*s.LastSyncTime)
+		fs.LastSyncTime = &t
+	}
+
+	switch {
+	case rejected != nil:
+		asd.acct.Status.SetConditions(xpv1.ReconcileError(rejected))
+	case fs.InProgress:
+		asd.acct.Status.SetConditions(xpv1.ReconcileSuccess())
+	default:
+		// The failover completed, so the account may be updated again. Its
+		// status is updated first, because updating the account resets it.
+		if err := asd.kube.Status().Update(ctx, asd.acct); err != nil {
+			return resultRequeue, true, err
+		}
+		return reconcile.Result{}, false, nil
+	}
+	return reconcile.Result{RequeueAfter: asd.poll}, true, asd.kube.Status().Update(ctx, asd.acct)
+}
+
 // createupdater interface defining create and update operations on/for storage account resource
 type createupdater interface {
 	creator
 	updater
 }
 
+// A groupEnsurer ensures that a resource group exists.
+type groupEnsurer interface {
+	EnsureResourceGroup(ctx context.Context, name, location string) error
+}
+
 // accountCreateUpdater implementation of createupdater interface
 type accountCreateUpdater struct {
 	syncbacker
+	blobservicesyncer
 	azurestorage.AccountOperations
 	kube      client.Client
 	acct      *v1alpha3.Account
 	poll      time.Duration
 	projectID string
+
+	// groups ensures the account's resource group exists before the account
+	// is created. Resource groups are assumed to exist when it is nil.
+	groups groupEnsurer
 }
 
 // newAccountCreateUpdater new instance of accountCreateUpdater
-func newAccountCreateUpdater(ao azurestorage.AccountOperations, kube client.Client, acct *v1alpha3.Account, poll time.Duration) *accountCreateUpdater {
+func newAccountCreateUpdater(ao azurestorage.AccountOperations, kube client.Client, acct *v1alpha3.Account, poll time.Duration, groups groupEnsurer, endpointSuffix string, token tokenCredentialFn) *accountCreateUpdater {
+	sb := newAccountSyncBacker(ao, kube, acct, poll, endpointSuffix, token)
 	return &accountCreateUpdater{
-		syncbacker:        newAccountSyncBacker(ao, kube, acct, poll),
+		syncbacker:        sb,
+		blobservicesyncer: sb.blobservicesyncer,
 		AccountOperations: ao,
 		kube:              kube,
 		acct:              acct,
 		poll:              poll,
+		groups:            groups,
 	}
 }
 
@@ -257,6 +459,13 @@ func (acu *accountCreateUpdater) create(ctx context.Context) (reconcile.Result,
 	acu.acct.Status.SetConditions(xpv1.Creating())
 	meta.AddFinalizer(acu.acct, finalizer)
 
+	if acu.groups != nil && acu.acct.Spec.StorageAccountSpec != nil {
+		if err := acu.groups.EnsureResourceGroup(ctx, acu.acct.Spec.ResourceGroupName, acu.acct.Spec.StorageAccountSpec.Location); err != nil {
+			acu.acct.Status.SetConditions(xpv1.ReconcileError(errors.Wrap(err, errEnsureResourceGroup)))
+			return resultRequeue, acu.kube.Status().Update(ctx, acu.acct)
+		}
+	}
+
 	accountSpec := v1alpha3.ToStorageAccountCreate(acu.acct.Spec.StorageAccountSpec)
 
 	a, err := acu.Create(ctx, accountSpec)
@@ -275,6 +484,19 @@ func (acu *accountCreateUpdater) update(ctx context.Context, account *storage.Ac
 
 		current := v1alpha3.NewStorageAccountSpec(account)
 		if reflect.DeepEqual(current, acu.acct.Spec.StorageAccountSpec) {
+			// Shared access signatures are reissued while their connection
+			// secret is updated, so it is updated even if nothing changed.
+			if len(acu.acct.Spec.SharedAccessSignatures) > 0 {
+				return acu.syncback(ctx, account)
+			}
+			// The blob service is configured apart from the account, so
+			// its properties may need updating even if the account's don't.
+			if acu.acct.Spec.BlobService != nil {
+				if err := acu.syncblobservice(ctx); err != nil {
+					acu.acct.Status.SetConditions(xpv1.ReconcileError(err))
+					return resultRequeue, acu.kube.Status().Update(ctx, acu.acct)
+				}
+			}
 			acu.acct.Status.SetConditions(xpv1.ReconcileSuccess())
 			return reconcile.Result{RequeueAfter: acu.poll}, acu.kube.Status().Update(ctx, acu.acct)
 		}
@@ -292,17 +514,19 @@ func (acu *accountCreateUpdater) update(ctx context.Context, account *storage.Ac
 
 type accountSyncbacker struct {
 	secretupdater
+	blobservicesyncer
 	acct *v1alpha3.Account
 	kube client.Client
 	poll time.Duration
 }
 
-func newAccountSyncBacker(ao azurestorage.AccountOperations, kube client.Client, acct *v1alpha3.Account, poll time.Duration) *accountSyncbacker {
+func newAccountSyncBacker(ao azurestorage.AccountOperations, kube client.Client, acct *v1alpha3.Account, poll time.Duration, endpointSuffix string, token tokenCredentialFn) *accountSyncbacker {
 	return &accountSyncbacker{
-		secretupdater: newAccountSecretUpdater(ao, kube, acct),
-		kube:          kube,
-		acct:          acct,
-		poll:          poll,
+		secretupdater:     newAccountSecretUpdater(ao, kube, acct),
+		blobservicesyncer: newAccountBlobServiceSyncer(ao, acct, endpointSuffix, token),
+		kube:              kube,
+		acct:              acct,
+		poll:              poll,
 	}
 }
 
@@ -319,13 +543,27 @@ func (asb *accountSyncbacker) syncback(ctx context.Context, acct *storage.Accoun
 		return requeueOnWait, asb.kube.Status().Update(ctx, asb.acct)
 	}
 
-	if err := asb.updatesecret(ctx, acct); err != nil {
+	due, err := asb.updatesecret(ctx, acct)
+	if err != nil {
 		asb.acct.Status.SetConditions(xpv1.ReconcileError(err))
 		return resultRequeue, asb.kube.Status().Update(ctx, asb.acct)
 	}
 
+	if asb.acct.Spec.BlobService != nil {
+		if err := asb.syncblobservice(ctx); err != nil {
+			asb.acct.Status.SetConditions(xpv1.ReconcileError(err))
+			return resultRequeue, asb.kube.Status().Update(ctx, asb.acct)
+		}
+	}
+
+	// Shared access signatures are reissued when the account is synced, so
+	// it is synced again before the first of them is due.
+	requeue := asb.poll
+	if due > 0 && due < requeue {
+		requeue = due
+	}
 	asb.acct.Status.SetConditions(xpv1.ReconcileSuccess())
-	return reconcile.Result{RequeueAfter: asb.poll}, asb.kube.Status().Update(ctx, asb.acct)
+	return reconcile.Result{RequeueAfter: requeue}, asb.kube.Status().Update(ctx, asb.acct)
 }
 
 type accountSecretUpdater struct {
@@ -342,7 +580,11 @@ func newAccountSecretUpdater(ao azurestorage.AccountOperations, kube client.Clie
 	}
 }
 
-func (asu *accountSecretUpdater) updatesecret(ctx context.Context, acct *storage.Account) error {
+// updatesecret writes the account's connection secret with the account's
+// key, which may be cached; see Options.KeyTTL. It returns how long it is
+// until the first of the account's shared access signatures is due to be
+// reissued, or zero if it has none.
+func (asu *accountSecretUpdater) updatesecret(ctx context.Context, acct *storage.Account) (time.Duration, error) {
 	secret := resource.ConnectionSecretFor(asu.acct, v1alpha3.AccountGroupVersionKind)
 	key := types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}
 
@@ -352,21 +594,212 @@ func (asu *accountSecretUpdater) updatesecret(ctx context.Context, acct *storage
 
 	keys, err := asu.ListKeys(ctx)
 	if err != nil {
-		return errors.Wrapf(err, "failed to list account keys")
+		return 0, errors.Wrapf(err, "failed to list account keys")
 	}
 	if len(keys) == 0 {
-		return errors.New("account keys are empty")
+		return 0, errors.New("account keys are empty")
 	}
 
 	secret.Data[xpv1.ResourceCredentialsSecretUserKey] = []byte(meta.GetExternalName(asu.acct))
 	secret.Data[xpv1.ResourceCredentialsSecretPasswordKey] = []byte(to.String(keys[0].Value))
 
+	due, err := asu.sharedAccessSignatures(ctx, secret, to.String(keys[0].Value))
+	if err != nil {
+		return 0, err
+	}
+
 	if err := asu.kube.Create(ctx, secret); err != nil {
 		if kerrors.IsAlreadyExists(err) {
-			return errors.Wrapf(asu.kube.Update(ctx, secret), "failed to update secret: %s", key)
+			return 0, errors.Wrapf(asu.kube.Update(ctx, secret), "failed to update secret: %s", key)
+		}
+		return 0, errors.Wrapf(err, "failed to create secret: %s", key)
+	}
+
+	if due.IsZero() {
+		return 0, nil
+	}
+	return time.Until(due), nil
+}
+
+// sharedAccessSignatures adds the account's shared access signatures to the
+// supplied connection secret. The tokens already in the secret are kept until
+// they are due to be reissued.
+func (asu *accountSecretUpdater) sharedAccessSignatures(ctx context.Context, secret *corev1.Secret, accountKey string) (time.Time, error) {
+	sigs := asu.acct.Spec.SharedAccessSignatures
+	if len(sigs) == 0 {
+		return time.Time{}, nil
+	}
+	current := &corev1.Secret{}
+	if err := asu.kube.Get(ctx, types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}, current); resource.IgnoreNotFound(err) != nil {
+		return time.Time{}, errors.Wrap(err, errGetSecret)
+	}
+	name := meta.GetExternalName(asu.acct)
+	generate := func(spec azurestorage.SASSpec) (string, error) {
+		return azurestorage.GenerateAccountSAS(name, accountKey, spec)
+	}
+	tokens, due, err := azurestorage.IssueSAS(sigs, current.Data, time.Now(), generate,
+		xpv1.ResourceCredentialsSecretEndpointKey, xpv1.ResourceCredentialsSecretUserKey, xpv1.ResourceCredentialsSecretPasswordKey)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for k, t := range tokens {
+		secret.Data[k] = t
+	}
+	return due, nil
+}
+
+// A tokenCredentialFn returns an Azure AD credential for the blob service of
+// a storage account.
+type tokenCredentialFn func(ctx context.Context) (azblob.Credential, error)
+
+type accountBlobServiceSyncer struct {
+	azurestorage.AccountOperations
+	acct           *v1alpha3.Account
+	newBlobService func(accountName, accountKey string) (azurestorage.BlobServiceOperations, error)
+
+	// tokenCredential returns the credential with which the blob service of
+	// an account that disallows shared key access is reached, since its
+	// account key stops working. newTokenBlobService creates a client of the
+	// blob service with it.
+	tokenCredential     tokenCredentialFn
+	newTokenBlobService func(accountName string, c azblob.Credential) (azurestorage.BlobServiceOperations, error)
+
+	// endpointSuffix is the DNS suffix of the blob service endpoints of the
+	// cloud the account is in. The public cloud's is used when it is empty.
+	endpointSuffix string
+}
+
+func newAccountBlobServiceSyncer(ao azurestorage.AccountOperations, acct *v1alpha3.Account, endpointSuffix string, token tokenCredentialFn) *accountBlobServiceSyncer {
+	bss := &accountBlobServiceSyncer{
+		AccountOperations: ao,
+		acct:              acct,
+		tokenCredential:   token,
+		endpointSuffix:    endpointSuffix,
+	}
+	bss.newBlobService = func(accountName, accountKey string) (azurestorage.BlobServiceOperations, error) {
+		return azurestorage.NewBlobServiceHandleWithEndpointSuffix(accountName, accountKey, bss.endpointSuffix)
+	}
+	bss.newTokenBlobService = func(accountName string, c azblob.Credential) (azurestorage.BlobServiceOperations, error) {
+		return azurestorage.NewBlobServiceHandleWithCredential(accountName, c, bss.endpointSuffix)
+	}
+	return bss
+}
+
+// sharedKeyDisallowed returns true if the supplied storage account disallows
+// shared key access.
+func sharedKeyDisallowed(acct *v1alpha3.Account) bool {
+	return acct.Spec.AllowSharedKeyAccess != nil && !*acct.Spec.AllowSharedKeyAccess
+}
+
+// A keyInvalidator forgets a cached account key that Azure did not accept.
+type keyInvalidator interface {
+	InvalidateKey(err error) bool
+}
+
+// blobService returns a client of the account's blob service. Accounts that
+// disallow shared key access are reached with a token credential, and all
+// others with their account key.
+func (bss *accountBlobServiceSyncer) blobService(ctx context.Context) (azurestorage.BlobServiceOperations, error) {
+	if sharedKeyDisallowed(bss.acct) {
+		if bss.tokenCredential == nil {
+			return nil, errors.New("account disallows shared key access, but no token credential is configured")
+		}
+		c, err := bss.tokenCredential(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot get token credential for blob service")
 		}
-		return errors.Wrapf(err, "failed to create secret: %s", key)
+		bs, err := bss.newTokenBlobService(meta.GetExternalName(bss.acct), c)
+		return bs, errors.Wrap(err, "cannot create blob service client")
+	}
+
+	keys, err := bss.ListKeys(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list account keys")
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("account keys are empty")
+	}
+	bs, err := bss.newBlobService(meta.GetExternalName(bss.acct), to.String(keys[0].Value))
+	return bs, errors.Wrap(err, "cannot create blob service client")
+}
+
+// syncblobservice updates the properties of the account's blob service that
+// differ from the desired ones. A cached account key is forgotten if the blob
+// service does not accept it, so that the next sync fetches it again.
+func (bss *accountBlobServiceSyncer) syncblobservice(ctx context.Context) (err error) {
+	if ki, ok := bss.AccountOperations.(keyInvalidator); ok {
+		defer func() {
+			if err != nil {
+				ki.InvalidateKey(err)
+			}
+		}()
+	}
+
+	bs, err := bss.blobService(ctx)
+	if err != nil {
+		return err
 	}
 
+	spec := bss.acct.Spec.BlobService
+	if spec.HourMetrics != nil || spec.MinuteMetrics != nil {
+		hour, minute, err := bs.GetMetricsConfig(ctx)
+		if err != nil {
+			return errors.Wrap(err, "cannot get blob service metrics")
+		}
+		wantHour, wantMinute := metricsProperties(spec.HourMetrics, hour), metricsProperties(spec.MinuteMetrics, minute)
+		if !reflect.DeepEqual(wantHour, hour) || !reflect.DeepEqual(wantMinute, minute) {
+			if err := bs.SetMetricsConfig(ctx, wantHour, wantMinute); err != nil {
+				return errors.Wrap(err, "cannot set blob service metrics")
+			}
+		}
+	}
+
+	if spec.Logging != nil {
+		logging, err := bs.GetLoggingConfig(ctx)
+		if err != nil {
+			return errors.Wrap(err, "cannot get blob service logging")
+		}
+		want := azurestorage.LoggingProperties{
+			Read:          spec.Logging.Read,
+			Write:         spec.Logging.Write,
+			Delete:        spec.Logging.Delete,
+			RetentionDays: spec.Logging.RetentionDays,
+		}
+		if !reflect.DeepEqual(want, logging) {
+			if err := bs.SetLoggingConfig(ctx, want); err != nil {
+				return errors.Wrap(err, "cannot set blob service logging")
+			}
+		}
+	}
+
+	if spec.SoftDelete != nil {
+		return syncSoftDelete(ctx, bs, spec.SoftDelete)
+	}
 	return nil
 }
+
+// syncSoftDelete updates the blob soft delete settings of the supplied blob
+// service if they differ from the desired ones.
+func syncSoftDelete(ctx context.Context, bs azurestorage.BlobServiceOperations, p *v1alpha3.SoftDeleteParameters) error {
+	enabled, days, err := bs.GetBlobSoftDelete(ctx)
+	if err != nil {
+		return errors.Wrap(err, "cannot get blob soft delete")
+	}
+	if enabled == p.Enabled && (!enabled || days == p.Days) {
+		return nil
+	}
+	return errors.Wrap(bs.SetBlobSoftDelete(ctx, p.Enabled, p.Days), "cannot set blob soft delete")
+}
+
+// metricsProperties returns the desired metrics properties, or the observed
+// ones if metrics are not managed.
+func metricsProperties(p *v1alpha3.MetricsParameters, observed azurestorage.MetricsProperties) azurestorage.MetricsProperties {
+	if p == nil {
+		return observed
+	}
+	return azurestorage.MetricsProperties{
+		Enabled:       p.Enabled,
+		IncludeAPIs:   p.Enabled && p.IncludeAPIs,
+		RetentionDays: p.RetentionDays,
+	}
+}
@@ -19,16 +19,20 @@ package account
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/crossplane-contrib/provider-azure/apis"
 
 	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2017-06-01/storage"
+	"github.com/Azure/azure-storage-blob-go/azblob"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -55,10 +59,10 @@ func init() {
 }
 
 type MockAccountSecretupdater struct {
-	MockUpdateSecret func(context.Context, *storage.Account) error
+	MockUpdateSecret func(context.Context, *storage.Account) (time.Duration, error)
 }
 
-func (m *MockAccountSecretupdater) updatesecret(ctx context.Context, a *storage.Account) error {
+func (m *MockAccountSecretupdater) updatesecret(ctx context.Context, a *storage.Account) (time.Duration, error) {
 	return m.MockUpdateSecret(ctx, a)
 }
 
@@ -74,6 +78,16 @@ func (m *MockAccountSyncbacker) syncback(ctx context.Context, a *storage.Account
 
 var _ syncbacker = &MockAccountSyncbacker{}
 
+type MockAccountBlobServiceSyncer struct {
+	MockSyncBlobService func(context.Context) error
+}
+
+func (m *MockAccountBlobServiceSyncer) syncblobservice(ctx context.Context) error {
+	return m.MockSyncBlobService(ctx)
+}
+
+var _ blobservicesyncer = &MockAccountBlobServiceSyncer{}
+
 type MockAccountCreateUpdater struct {
 	MockCreate func(context.Context) (reconcile.Result, error)
 	MockUpdate func(context.Context, *storage.Account) (reconcile.Result, error)
@@ -408,7 +422,7 @@ func Test_syncdeleter_delete(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			bh := newAccountSyncDeleter(tt.fields.ao, tt.fields.cc, tt.fields.acct, tt.fields.poll)
+			bh := newAccountSyncDeleter(tt.fields.ao, tt.fields.cc, tt.fields.acct, tt.fields.poll, nil, "", nil)
 			got, err := bh.delete(ctx)
 			if diff := cmp.Diff(tt.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("accountSyncDeleter.delete(): -want error, +got error: \n%s", diff)
@@ -423,6 +437,33 @@ func Test_syncdeleter_delete(t *testing.T) {
 	}
 }
 
+func Test_newAccountSyncDeleter(t *testing.T) {
+	groups := &MockGroupEnsurer{}
+	suffix := "core.chinacloudapi.cn"
+	tokens := 0
+	token := func(context.Context) (azblob.Credential, error) {
+		tokens++
+		return azblob.NewAnonymousCredential(), nil
+	}
+	sd := newAccountSyncDeleter(&azurestoragefake.MockAccountOperations{}, &test.MockClient{}, v1alpha3test.NewMockAccount(testAccountName).Account, time.Minute, groups, suffix, token)
+
+	acu := sd.createupdater.(*accountCreateUpdater)
+	if acu.groups != groups {
+		t.Errorf("newAccountSyncDeleter(...): want the supplied group ensurer to ensure resource groups")
+	}
+	bss := acu.syncbacker.(*accountSyncbacker).blobservicesyncer.(*accountBlobServiceSyncer)
+	if bss.endpointSuffix != suffix {
+		t.Errorf("newAccountSyncDeleter(...): want blob services reached under %q, got %q", suffix, bss.endpointSuffix)
+	}
+	if acu.blobservicesyncer != bss {
+		t.Errorf("newAccountSyncDeleter(...): want unchanged accounts to sync their blob service like changed ones")
+	}
+	_, _ = bss.tokenCredential(context.TODO())
+	if tokens != 1 {
+		t.Errorf("newAccountSyncDeleter(...): want blob services reached with the supplied token credential")
+	}
+}
+
 func Test_syncdeleter_sync(t *testing.T) {
 	ctx := context.TODO()
 	name := testAccountName
@@ -532,6 +573,15 @@ func Test_syncdeleter_sync(t *testing.T) {
 	}
 }
 
+// MockGroupEnsurer ensures resource groups by calling MockEnsureResourceGroup.
+type MockGroupEnsurer struct {
+	MockEnsureResourceGroup func(ctx context.Context, name, location string) error
+}
+
+func (m *MockGroupEnsurer) EnsureResourceGroup(ctx context.Context, name, location string) error {
+	return m.MockEnsureResourceGroup(ctx, name, location)
+}
+
 func Test_createupdater_create(t *testing.T) {
 	ctx := context.TODO()
 	name := testAccountName
@@ -543,6 +593,7 @@ func Test_createupdater_create(t *testing.T) {
 		kube      client.Client
 		acct      *v1alpha3.Account
 		projectID string
+		groups    groupEnsurer
 	}
 	type want struct {
 		err error
@@ -579,6 +630,71 @@ func Test_createupdater_create(t *testing.T) {
 					Account,
 			},
 		},
+		{
+			name: "EnsureResourceGroupFailed",
+			fields: fields{
+				ao: &azurestoragefake.MockAccountOperations{
+					MockCreate: func(ctx context.Context, params storage.AccountCreateParameters) (*storage.Account, error) {
+						t.Errorf("accountCreateUpdater.create(): unexpected create of an account whose resource group could not be ensured")
+						return nil, nil
+					},
+				},
+				kube: test.NewMockClient(),
+				acct: v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(&v1alpha3.StorageAccountSpec{Location: "westus"}).
+					Account,
+				groups: &MockGroupEnsurer{
+					MockEnsureResourceGroup: func(_ context.Context, _, _ string) error { return errBoom },
+				},
+			},
+			want: want{
+				res: resultRequeue,
+				obj: v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(&v1alpha3.StorageAccountSpec{Location: "westus"}).
+					WithStatusConditions(xpv1.Creating(), xpv1.ReconcileError(errors.Wrap(errBoom, errEnsureResourceGroup))).
+					WithFinalizer(finalizer).
+					Account,
+			},
+		},
+		{
+			name: "EnsureResourceGroupSuccessful",
+			fields: fields{
+				sb: &MockAccountSyncbacker{
+					MockSyncback: func(ctx context.Context, a *storage.Account) (result reconcile.Result, e error) {
+						return reconcile.Result{}, nil
+					},
+				},
+				ao:   azurestoragefake.NewMockAccountOperations(),
+				kube: test.NewMockClient(),
+				acct: func() *v1alpha3.Account {
+					a := v1alpha3test.NewMockAccount(name).
+						WithSpecStorageAccountSpec(&v1alpha3.StorageAccountSpec{Location: "westus"}).
+						Account
+					a.Spec.ResourceGroupName = "group"
+					return a
+				}(),
+				groups: &MockGroupEnsurer{
+					MockEnsureResourceGroup: func(_ context.Context, name, location string) error {
+						if name != "group" || location != "westus" {
+							t.Errorf("EnsureResourceGroup(...): want group in westus, got %s in %s", name, location)
+						}
+						return nil
+					},
+				},
+			},
+			want: want{
+				res: reconcile.Result{},
+				obj: func() *v1alpha3.Account {
+					a := v1alpha3test.NewMockAccount(name).
+						WithSpecStorageAccountSpec(&v1alpha3.StorageAccountSpec{Location: "westus"}).
+						WithFinalizer(finalizer).
+						WithStatusConditions(xpv1.Creating()).
+						Account
+					a.Spec.ResourceGroupName = "group"
+					return a
+				}(),
+			},
+		},
 		{
 			name: "CreateSuccessful",
 			fields: fields{
@@ -612,6 +728,7 @@ func Test_createupdater_create(t *testing.T) {
 				kube:              tt.fields.kube,
 				acct:              tt.fields.acct,
 				projectID:         tt.fields.projectID,
+				groups:            tt.fields.groups,
 			}
 			got, err := bh.create(ctx)
 			if diff := cmp.Diff(tt.want.err, err, test.EquateErrors()); diff != "" {
@@ -631,9 +748,14 @@ func Test_bucketCreateUpdater_update(t *testing.T) {
 	ctx := context.TODO()
 	name := testAccountName
 	errBoom := errors.New("boom")
+	withBlobService := func(a *v1alpha3.Account) *v1alpha3.Account {
+		a.Spec.BlobService = &v1alpha3.BlobServiceParameters{HourMetrics: &v1alpha3.MetricsParameters{Enabled: true}}
+		return a
+	}
 
 	type fields struct {
 		sb   syncbacker
+		bss  blobservicesyncer
 		ao   azurestorage.AccountOperations
 		kube client.Client
 		acct *v1alpha3.Account
@@ -687,6 +809,52 @@ func Test_bucketCreateUpdater_update(t *testing.T) {
 					Account,
 			},
 		},
+		{
+			name: "NoChangesBlobServiceSynced",
+			attrs: &storage.Account{
+				AccountProperties: &storage.AccountProperties{ProvisioningState: storage.Succeeded},
+			},
+			fields: fields{
+				bss: &MockAccountBlobServiceSyncer{
+					MockSyncBlobService: func(ctx context.Context) error { return nil },
+				},
+				acct: withBlobService(v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(newStoragAccountSpecWithProperties()).
+					Account),
+				kube: test.NewMockClient(),
+				poll: time.Minute,
+			},
+			want: want{
+				res: reconcile.Result{RequeueAfter: time.Minute},
+				acct: withBlobService(v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(newStoragAccountSpecWithProperties()).
+					WithStatusConditions(xpv1.Available(), xpv1.ReconcileSuccess()).
+					Account),
+			},
+		},
+		{
+			name: "NoChangesBlobServiceFailed",
+			attrs: &storage.Account{
+				AccountProperties: &storage.AccountProperties{ProvisioningState: storage.Succeeded},
+			},
+			fields: fields{
+				bss: &MockAccountBlobServiceSyncer{
+					MockSyncBlobService: func(ctx context.Context) error { return errBoom },
+				},
+				acct: withBlobService(v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(newStoragAccountSpecWithProperties()).
+					Account),
+				kube: test.NewMockClient(),
+				poll: time.Minute,
+			},
+			want: want{
+				res: resultRequeue,
+				acct: withBlobService(v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(newStoragAccountSpecWithProperties()).
+					WithStatusConditions(xpv1.Available(), xpv1.ReconcileError(errBoom)).
+					Account),
+			},
+		},
 		{
 			name: "UpdateFailed",
 			attrs: &storage.Account{
@@ -746,6 +914,7 @@ func Test_bucketCreateUpdater_update(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			bh := &accountCreateUpdater{
 				syncbacker:        tt.fields.sb,
+				blobservicesyncer: tt.fields.bss,
 				AccountOperations: tt.fields.ao,
 				kube:              tt.fields.kube,
 				acct:              tt.fields.acct,
@@ -829,8 +998,8 @@ func Test_accountSyncBacker_syncback(t *testing.T) {
 			name: "UpdateSecretFailed",
 			fields: fields{
 				secretupdater: &MockAccountSecretupdater{
-					MockUpdateSecret: func(ctx context.Context, a *storage.Account) error {
-						return errBoom
+					MockUpdateSecret: func(ctx context.Context, a *storage.Account) (time.Duration, error) {
+						return 0, errBoom
 					},
 				},
 				acct: v1alpha3test.NewMockAccount(name).Account,
@@ -848,7 +1017,7 @@ func Test_accountSyncBacker_syncback(t *testing.T) {
 			name: "Success",
 			fields: fields{
 				secretupdater: &MockAccountSecretupdater{
-					MockUpdateSecret: func(ctx context.Context, a *storage.Account) error { return nil },
+					MockUpdateSecret: func(ctx context.Context, a *storage.Account) (time.Duration, error) { return 0, nil },
 				},
 				acct: v1alpha3test.NewMockAccount(name).
 					WithSpecStorageAccountSpec(v1alpha3.NewStorageAccountSpec(&storage.Account{})).
@@ -865,6 +1034,27 @@ func Test_accountSyncBacker_syncback(t *testing.T) {
 					Account,
 			},
 		},
+		{
+			name: "SharedAccessSignatureDue",
+			fields: fields{
+				secretupdater: &MockAccountSecretupdater{
+					MockUpdateSecret: func(ctx context.Context, a *storage.Account) (time.Duration, error) { return 10 * time.Second, nil },
+				},
+				acct: v1alpha3test.NewMockAccount(name).
+					WithSpecStorageAccountSpec(v1alpha3.NewStorageAccountSpec(&storage.Account{})).
+					Account,
+				kube: test.NewMockClient(),
+				poll: time.Minute,
+			},
+			acct: &storage.Account{AccountProperties: &storage.AccountProperties{ProvisioningState: storage.Succeeded}},
+			want: want{
+				res: reconcile.Result{RequeueAfter: 10 * time.Second},
+				acct: v1alpha3test.NewMockAccount(name).
+					WithSpecStatusFromProperties(&storage.AccountProperties{ProvisioningState: storage.Succeeded}).
+					WithStatusConditions(xpv1.ReconcileSuccess()).
+					Account,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1062,10 +1252,619 @@ func Test_accountSecretUpdater_updatesecret(t *testing.T) {
 				acct:              tt.fields.acct,
 				kube:              tt.fields.kube,
 			}
-			err := asu.updatesecret(ctx, tt.acct)
+			_, err := asu.updatesecret(ctx, tt.acct)
 			if diff := cmp.Diff(tt.wantErr, err, test.EquateErrors()); diff != "" {
 				t.Errorf("accountSyncBackSecretUpdater.syncback() -want error, +got error:\n%s", diff)
 			}
 		})
 	}
 }
+
+func Test_accountSecretUpdater_sharedAccessSignatures(t *testing.T) {
+	ctx := context.TODO()
+	key := "dGVzdC1rZXkK"
+	sas := v1alpha3.SharedAccessSignature{Name: "sas", Permissions: "rl"}
+	current, err := azurestorage.GenerateAccountSAS(testAccountName, key, azurestorage.SASSpec{Permissions: "rl", Expiry: time.Now().Add(20 * time.Hour).Truncate(time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	due, err := azurestorage.GenerateAccountSAS(testAccountName, key, azurestorage.SASSpec{Permissions: "rl", Expiry: time.Now().Add(time.Hour).Truncate(time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		kept bool
+		err  error
+	}
+	cases := map[string]struct {
+		reason  string
+		sigs    []v1alpha3.SharedAccessSignature
+		current string
+		want    want
+	}{
+		"Issue": {
+			reason: "A token should be issued if the connection secret has none.",
+			sigs:   []v1alpha3.SharedAccessSignature{sas},
+		},
+		"KeepCurrent": {
+			reason:  "Tokens that are not yet due to be reissued should be kept.",
+			sigs:    []v1alpha3.SharedAccessSignature{sas},
+			current: current,
+			want:    want{kept: true},
+		},
+		"Reissue": {
+			reason:  "Tokens that are due to be reissued should be replaced.",
+			sigs:    []v1alpha3.SharedAccessSignature{sas},
+			current: due,
+		},
+		"ReservedName": {
+			reason: "Shared access signatures may not overwrite the account key.",
+			sigs:   []v1alpha3.SharedAccessSignature{{Name: xpv1.ResourceCredentialsSecretPasswordKey, Permissions: "r"}},
+			want:   want{err: errors.Errorf("shared access signature name %q is reserved", xpv1.ResourceCredentialsSecretPasswordKey)},
+		},
+		"DuplicateName": {
+			reason: "Shared access signatures should have distinct names.",
+			sigs:   []v1alpha3.SharedAccessSignature{sas, sas},
+			want:   want{err: errors.Errorf("duplicate shared access signature name %q", sas.Name)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			acct := v1alpha3test.NewMockAccount(testAccountName).WithSpecWriteConnectionSecretToReference(testNamespace, "connectionsecret").Account
+			acct.Spec.SharedAccessSignatures = tc.sigs
+			asu := &accountSecretUpdater{
+				acct: acct,
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+						obj.(*corev1.Secret).Data = map[string][]byte{"sas": []byte(tc.current)}
+						return nil
+					},
+				},
+			}
+			secret := &corev1.Secret{Data: map[string][]byte{xpv1.ResourceCredentialsSecretPasswordKey: []byte(key)}}
+			_, err := asu.sharedAccessSignatures(ctx, secret, key)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\naccountSecretUpdater.sharedAccessSignatures(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if tc.want.err != nil {
+				return
+			}
+			got := string(secret.Data["sas"])
+			if kept := got == tc.current; kept != tc.want.kept {
+				t.Errorf("\n%s\naccountSecretUpdater.sharedAccessSignatures(...): want token kept %t, got %t", tc.reason, tc.want.kept, kept)
+			}
+			if e := azurestorage.SASExpiry(got); e.Before(time.Now().Add(16 * time.Hour)) {
+				t.Errorf("\n%s\naccountSecretUpdater.sharedAccessSignatures(...): want token valid for at least 16h, got expiry %s", tc.reason, e)
+			}
+		})
+	}
+}
+
+func Test_accountBlobServiceSyncer_syncblobservice(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+
+	keys := func(ctx context.Context) ([]storage.AccountKey, error) {
+		return []storage.AccountKey{{Value: to.StringPtr("dGVzdC1rZXkK")}}, nil
+	}
+	observedMinute := azurestorage.MetricsProperties{Enabled: true}
+
+	type set struct {
+		hour   azurestorage.MetricsProperties
+		minute azurestorage.MetricsProperties
+	}
+	type softDelete struct {
+		enabled bool
+		days    int32
+	}
+	tests := []struct {
+		name     string
+		ops      azurestorage.AccountOperations
+		spec     *v1alpha3.BlobServiceParameters
+		observed azurestorage.MetricsProperties
+		logging  azurestorage.LoggingProperties
+		softDel  softDelete
+		wantSet  *set
+		wantLog  *azurestorage.LoggingProperties
+		wantDel  *softDelete
+		wantErr  error
+	}{
+		{
+			name: "NoChange",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				HourMetrics: &v1alpha3.MetricsParameters{Enabled: true, RetentionDays: to.Int32Ptr(7)},
+			},
+			observed: azurestorage.MetricsProperties{Enabled: true, RetentionDays: to.Int32Ptr(7)},
+		},
+		{
+			name: "EnableHourMetrics",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				HourMetrics: &v1alpha3.MetricsParameters{Enabled: true, IncludeAPIs: true},
+			},
+			wantSet: &set{
+				hour:   azurestorage.MetricsProperties{Enabled: true, IncludeAPIs: true},
+				minute: observedMinute,
+			},
+		},
+		{
+			name: "DisableMinuteMetrics",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				MinuteMetrics: &v1alpha3.MetricsParameters{Enabled: false, IncludeAPIs: true},
+			},
+			wantSet: &set{
+				minute: azurestorage.MetricsProperties{},
+			},
+		},
+		{
+			name: "LoggingNoChange",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				Logging: &v1alpha3.LoggingParameters{Read: true, RetentionDays: to.Int32Ptr(7)},
+			},
+			logging: azurestorage.LoggingProperties{Read: true, RetentionDays: to.Int32Ptr(7)},
+		},
+		{
+			name: "EnableAllLogging",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				Logging: &v1alpha3.LoggingParameters{Read: true, Write: true, Delete: true, RetentionDays: to.Int32Ptr(30)},
+			},
+			wantLog: &azurestorage.LoggingProperties{Read: true, Write: true, Delete: true, RetentionDays: to.Int32Ptr(30)},
+		},
+		{
+			name: "PartialLogging",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				Logging: &v1alpha3.LoggingParameters{Write: true},
+			},
+			logging: azurestorage.LoggingProperties{Read: true, Write: true, Delete: true},
+			wantLog: &azurestorage.LoggingProperties{Write: true},
+		},
+		{
+			name: "DisableLogging",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				Logging: &v1alpha3.LoggingParameters{},
+			},
+			logging: azurestorage.LoggingProperties{Read: true, Delete: true, RetentionDays: to.Int32Ptr(7)},
+			wantLog: &azurestorage.LoggingProperties{},
+		},
+		{
+			name: "EnableSoftDelete",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				SoftDelete: &v1alpha3.SoftDeleteParameters{Enabled: true, Days: 7},
+			},
+			wantDel: &softDelete{enabled: true, days: 7},
+		},
+		{
+			name: "SoftDeleteDaysChanged",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				SoftDelete: &v1alpha3.SoftDeleteParameters{Enabled: true, Days: 30},
+			},
+			softDel: softDelete{enabled: true, days: 7},
+			wantDel: &softDelete{enabled: true, days: 30},
+		},
+		{
+			name: "DisableSoftDelete",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				SoftDelete: &v1alpha3.SoftDeleteParameters{Days: 7},
+			},
+			softDel: softDelete{enabled: true, days: 7},
+			wantDel: &softDelete{days: 7},
+		},
+		{
+			name: "SoftDeleteDisabledNoChange",
+			ops:  &azurestoragefake.MockAccountOperations{MockListKeys: keys},
+			spec: &v1alpha3.BlobServiceParameters{
+				SoftDelete: &v1alpha3.SoftDeleteParameters{Days: 7},
+			},
+		},
+		{
+			name: "ListKeysFailed",
+			ops: &azurestoragefake.MockAccountOperations{
+				MockListKeys: func(ctx context.Context) ([]storage.AccountKey, error) {
+					return nil, errBoom
+				},
+			},
+			spec:    &v1alpha3.BlobServiceParameters{},
+			wantErr: errors.Wrapf(errBoom, "failed to list account keys"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *set
+			var gotLog *azurestorage.LoggingProperties
+			var gotDel *softDelete
+			acct := v1alpha3test.NewMockAccount(testAccountName).Account
+			acct.Spec.BlobService = tt.spec
+			bss := &accountBlobServiceSyncer{
+				AccountOperations: tt.ops,
+				acct:              acct,
+				newBlobService: func(accountName, accountKey string) (azurestorage.BlobServiceOperations, error) {
+					return &azurestoragefake.MockBlobServiceOperations{
+						MockGetMetricsConfig: func(ctx context.Context) (hour, minute azurestorage.MetricsProperties, err error) {
+							return tt.observed, observedMinute, nil
+						},
+						MockSetMetricsConfig: func(ctx context.Context, hour, minute azurestorage.MetricsProperties) error {
+							got = &set{hour: hour, minute: minute}
+							return nil
+						},
+						MockGetLoggingConfig: func(ctx context.Context) (azurestorage.LoggingProperties, error) {
+							return tt.logging, nil
+						},
+						MockSetLoggingConfig: func(ctx context.Context, p azurestorage.LoggingProperties) error {
+							gotLog = &p
+							return nil
+						},
+						MockGetBlobSoftDelete: func(ctx context.Context) (bool, int32, error) {
+							return tt.softDel.enabled, tt.softDel.days, nil
+						},
+						MockSetBlobSoftDelete: func(ctx context.Context, enabled bool, days int32) error {
+							gotDel = &softDelete{enabled: enabled, days: days}
+							return nil
+						},
+					}, nil
+				},
+			}
+			err := bss.syncblobservice(ctx)
+			if diff := cmp.Diff(tt.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("accountBlobServiceSyncer.syncblobservice(): -want error, +got error:\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantSet, got, cmp.AllowUnexported(set{})); diff != "" {
+				t.Errorf("accountBlobServiceSyncer.syncblobservice(): -want set, +got set:\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantLog, gotLog); diff != "" {
+				t.Errorf("accountBlobServiceSyncer.syncblobservice(): -want logging, +got logging:\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantDel, gotDel, cmp.AllowUnexported(softDelete{})); diff != "" {
+				t.Errorf("accountBlobServiceSyncer.syncblobservice(): -want soft delete, +got soft delete:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_accountBlobServiceSyncer_invalidateKey(t *testing.T) {
+	h := http.Header{}
+	h.Set("x-ms-error-code", string(azblob.ServiceCodeAuthenticationFailed))
+	req := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "example.blob.core.windows.net"}, Header: http.Header{}}
+	errAuth := azblob.NewResponseError(nil, &http.Response{StatusCode: http.StatusForbidden, Header: h, Request: req}, "")
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   int
+	}{
+		"AuthenticationFailed": {
+			reason: "A cached key the blob service does not accept should be listed again on the next sync.",
+			err:    errAuth,
+			want:   2,
+		},
+		"OtherError": {
+			reason: "A cached key should be reused when the blob service fails for another reason.",
+			err:    errors.New("boom"),
+			want:   1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			listed := 0
+			ao := &azurestoragefake.MockAccountOperations{
+				MockListKeys: func(ctx context.Context) ([]storage.AccountKey, error) {
+					listed++
+					return []storage.AccountKey{{Value: to.StringPtr("dGVzdC1rZXkK")}}, nil
+				},
+			}
+			acct := v1alpha3test.NewMockAccount(testAccountName).Account
+			acct.Spec.BlobService = &v1alpha3.BlobServiceParameters{HourMetrics: &v1alpha3.MetricsParameters{Enabled: true}}
+			bss := &accountBlobServiceSyncer{
+				AccountOperations: azurestorage.NewKeyCachingAccountOperations(ao, azurestorage.NewAccountKeyProvider(time.Minute), testAccountName),
+				acct:              acct,
+				newBlobService: func(accountName, accountKey string) (azurestorage.BlobServiceOperations, error) {
+					return &azurestoragefake.MockBlobServiceOperations{
+						MockGetMetricsConfig: func(ctx context.Context) (hour, minute azurestorage.MetricsProperties, err error) {
+							return hour, minute, tc.err
+						},
+					}, nil
+				},
+			}
+
+			for i := 0; i < 2; i++ {
+				if err := bss.syncblobservice(context.Background()); err == nil {
+					t.Errorf("\n%s\naccountBlobServiceSyncer.syncblobservice(): want error, got nil", tc.reason)
+				}
+			}
+			if listed != tc.want {
+				t.Errorf("\n%s\naccountBlobServiceSyncer.syncblobservice(): want keys listed %d times, got %d", tc.reason, tc.want, listed)
+			}
+		})
+	}
+}
+
+func Test_accountBlobServiceSyncer_credential(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+	key := "key"
+
+	type want struct {
+		credential string
+		err        error
+	}
+	cases := map[string]struct {
+		reason  string
+		allowed *bool
+		token   tokenCredentialFn
+		want    want
+	}{
+		"SharedKeyAllowed": {
+			reason:  "The blob service of an account that allows shared key access should be reached with its account key.",
+			allowed: to.BoolPtr(true),
+			want:    want{credential: "key"},
+		},
+		"SharedKeyUnset": {
+			reason: "The blob service of an account that does not ask for shared key access either way should be reached with its account key.",
+			want:   want{credential: "key"},
+		},
+		"SharedKeyDisallowed": {
+			reason:  "The blob service of an account that disallows shared key access should be reached with a token credential.",
+			allowed: to.BoolPtr(false),
+			token: func(context.Context) (azblob.Credential, error) {
+				return azblob.NewAnonymousCredential(), nil
+			},
+			want: want{credential: "token"},
+		},
+		"TokenUnavailable": {
+			reason:  "The blob service of an account that disallows shared key access should not be reached with its account key when no token credential can be obtained.",
+			allowed: to.BoolPtr(false),
+			token: func(context.Context) (azblob.Credential, error) {
+				return nil, errBoom
+			},
+			want: want{err: errors.Wrap(errBoom, "cannot get token credential for blob service")},
+		},
+		"NoTokenCredential": {
+			reason:  "The blob service of an account that disallows shared key access should not be reached with its account key when no token credential is configured.",
+			allowed: to.BoolPtr(false),
+			want:    want{err: errors.New("account disallows shared key access, but no token credential is configured")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			acct := v1alpha3test.NewMockAccount(testAccountName).Account
+			acct.Spec.AllowSharedKeyAccess = tc.allowed
+			acct.Spec.BlobService = &v1alpha3.BlobServiceParameters{}
+
+			credential := ""
+			bss := &accountBlobServiceSyncer{
+				AccountOperations: &azurestoragefake.MockAccountOperations{
+					MockListKeys: func(context.Context) ([]storage.AccountKey, error) {
+						return []storage.AccountKey{{Value: &key}}, nil
+					},
+				},
+				acct: acct,
+				newBlobService: func(_, accountKey string) (azurestorage.BlobServiceOperations, error) {
+					credential = accountKey
+					return &azurestoragefake.MockBlobServiceOperations{}, nil
+				},
+				tokenCredential: tc.token,
+				newTokenBlobService: func(string, azblob.Credential) (azurestorage.BlobServiceOperations, error) {
+					credential = "token"
+					return &azurestoragefake.MockBlobServiceOperations{}, nil
+				},
+			}
+			err := bss.syncblobservice(ctx)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\naccountBlobServiceSyncer.syncblobservice(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if credential != tc.want.credential {
+				t.Errorf("\n%s\naccountBlobServiceSyncer.syncblobservice(): want credential %q, got %q", tc.reason, tc.want.credential, credential)
+			}
+		})
+	}
+}
+
+func Test_accountSecretUpdater_cachedKey(t *testing.T) {
+	h := http.Header{}
+	h.Set("x-ms-error-code", string(azblob.ServiceCodeAuthenticationFailed))
+	req := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "example.blob.core.windows.net"}, Header: http.Header{}}
+	errAuth := azblob.NewResponseError(nil, &http.Response{StatusCode: http.StatusForbidden, Header: h, Request: req}, "")
+
+	key := "old"
+	ao := &azurestoragefake.MockAccountOperations{
+		MockListKeys: func(ctx context.Context) ([]storage.AccountKey, error) {
+			return []storage.AccountKey{{Value: to.StringPtr(key)}}, nil
+		},
+	}
+	ops := azurestorage.NewKeyCachingAccountOperations(ao, azurestorage.NewAccountKeyProvider(time.Minute), testAccountName)
+	if _, err := ops.ListKeys(context.Background()); err != nil {
+		t.Fatalf("ListKeys(...): %v", err)
+	}
+	key = "rotated"
+
+	var written []byte
+	asu := &accountSecretUpdater{
+		AccountOperations: ops,
+		acct:              v1alpha3test.NewMockAccount(testAccountName).WithSpecWriteConnectionSecretToReference(testNamespace, "connectionsecret").Account,
+		kube: &test.MockClient{
+			MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+				written = obj.(*corev1.Secret).Data[xpv1.ResourceCredentialsSecretPasswordKey]
+				return nil
+			},
+		},
+	}
+	if _, err := asu.updatesecret(context.Background(), &storage.Account{AccountProperties: &storage.AccountProperties{}}); err != nil {
+		t.Fatalf("accountSecretUpdater.updatesecret(): %v", err)
+	}
+	if string(written) != "old" {
+		t.Errorf("accountSecretUpdater.updatesecret(): want secret written with cached key %q, got %q", "old", written)
+	}
+
+	// Once Azure rejects the cached key the rotated key should be written.
+	ops.InvalidateKey(errAuth)
+	if _, err := asu.updatesecret(context.Background(), &storage.Account{AccountProperties: &storage.AccountProperties{}}); err != nil {
+		t.Fatalf("accountSecretUpdater.updatesecret(): %v", err)
+	}
+	if string(written) != key {
+		t.Errorf("accountSecretUpdater.updatesecret(): want secret written with rotated key %q once the cached key was rejected, got %q", key, written)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+	poll := time.Minute
+	errInProgress := errors.Wrap(azurestorage.ErrFailoverInProgress, "cannot initiate failover")
+
+	requested := func() *v1alpha3.Account {
+		a := v1alpha3test.NewMockAccount(testAccountName).Account
+		a.SetAnnotations(map[string]string{v1alpha3.AnnotationKeyInitiateFailover: "true"})
+		return a
+	}
+	tracking := func() *v1alpha3.Account {
+		a := v1alpha3test.NewMockAccount(testAccountName).Account
+		a.Status.Failover = &v1alpha3.FailoverStatus{InProgress: true, PrimaryLocation: "westus", SecondaryLocation: "eastus"}
+		return a
+	}
+
+	type want struct {
+		res           reconcile.Result
+		err           error
+		initiated     bool
+		initiatedTime bool
+		updated       bool
+		annotation    bool
+		failover      *v1alpha3.FailoverStatus
+		synced        xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason   string
+		acct     *v1alpha3.Account
+		initiate error
+		state    azurestorage.FailoverState
+		want     want
+	}{
+		"NotRequested": {
+			reason: "Accounts should be updated as usual when no failover was requested or is in progress.",
+			acct:   v1alpha3test.NewMockAccount(testAccountName).Account,
+			want: want{
+				res:     reconcile.Result{RequeueAfter: poll},
+				updated: true,
+				synced:  xpv1.Condition{Type: xpv1.TypeSynced, Status: corev1.ConditionUnknown},
+			},
+		},
+		"Initiated": {
+			reason: "A requested failover should be initiated, its request removed, and the account not updated while it is in progress.",
+			acct:   requested(),
+			state:  azurestorage.FailoverState{PrimaryLocation: "westus", SecondaryLocation: "eastus"},
+			want: want{
+				res:           reconcile.Result{RequeueAfter: poll},
+				initiated:     true,
+				initiatedTime: true,
+				failover:      &v1alpha3.FailoverStatus{InProgress: true, PrimaryLocation: "westus", SecondaryLocation: "eastus"},
+				synced:        xpv1.ReconcileSuccess(),
+			},
+		},
+		"AlreadyInProgress": {
+			reason:   "A failover requested while one is in progress should be rejected with a clear error, and its request removed.",
+			acct:     requested(),
+			initiate: errInProgress,
+			state:    azurestorage.FailoverState{InProgress: true, PrimaryLocation: "westus", SecondaryLocation: "eastus"},
+			want: want{
+				res:       reconcile.Result{RequeueAfter: poll},
+				initiated: true,
+				failover:  &v1alpha3.FailoverStatus{InProgress: true, PrimaryLocation: "westus", SecondaryLocation: "eastus"},
+				synced:    xpv1.ReconcileError(errInProgress),
+			},
+		},
+		"InitiateFailed": {
+			reason:   "A failover that could not be initiated should keep its request, so that it is retried.",
+			acct:     requested(),
+			initiate: errBoom,
+			want: want{
+				res:        resultRequeue,
+				initiated:  true,
+				annotation: true,
+				synced:     xpv1.ReconcileError(errBoom),
+			},
+		},
+		"Completed": {
+			reason: "A completed failover should be reflected in status, and the account updated as usual again.",
+			acct:   tracking(),
+			state:  azurestorage.FailoverState{PrimaryLocation: "eastus", SecondaryLocation: "westus"},
+			want: want{
+				res:      reconcile.Result{RequeueAfter: poll},
+				updated:  true,
+				failover: &v1alpha3.FailoverStatus{PrimaryLocation: "eastus", SecondaryLocation: "westus"},
+				synced:   xpv1.Condition{Type: xpv1.TypeSynced, Status: corev1.ConditionUnknown},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			initiated, updated := false, false
+			cu := newMockAccountCreateUpdater(poll)
+			cu.MockUpdate = func(context.Context, *storage.Account) (reconcile.Result, error) {
+				updated = true
+				return reconcile.Result{RequeueAfter: poll}, nil
+			}
+			asd := &accountSyncDeleter{
+				createupdater: cu,
+				AccountOperations: &azurestoragefake.MockAccountOperations{
+					MockGet: func(context.Context) (*storage.Account, error) { return &storage.Account{}, nil },
+				},
+				kube: &test.MockClient{
+					MockUpdate:       test.NewMockUpdateFn(nil),
+					MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+				},
+				acct: tc.acct,
+				poll: poll,
+				management: &azurestoragefake.MockManagementOperations{
+					MockInitiateFailover: func(_ context.Context, group, name string) error {
+						initiated = true
+						return tc.initiate
+					},
+					MockGetFailoverState: func(context.Context, string, string) (azurestorage.FailoverState, error) {
+						return tc.state, nil
+					},
+				},
+			}
+
+			res, err := asd.sync(ctx)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.res, res); diff != "" {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): -want result, +got result:\n%s", tc.reason, diff)
+			}
+			if initiated != tc.want.initiated {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): want failover initiated %t, got %t", tc.reason, tc.want.initiated, initiated)
+			}
+			if updated != tc.want.updated {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): want account updated %t, got %t", tc.reason, tc.want.updated, updated)
+			}
+			_, annotated := tc.acct.GetAnnotations()[v1alpha3.AnnotationKeyInitiateFailover]
+			if annotated != tc.want.annotation {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): want failover request annotation %t, got %t", tc.reason, tc.want.annotation, annotated)
+			}
+			got := tc.acct.Status.Failover
+			if recorded := got != nil && got.InitiatedTime != nil; recorded != tc.want.initiatedTime {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): want initiated time recorded %t, got %t", tc.reason, tc.want.initiatedTime, recorded)
+			}
+			if diff := cmp.Diff(tc.want.failover, got, cmpopts.IgnoreFields(v1alpha3.FailoverStatus{}, "InitiatedTime")); diff != "" {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): -want failover status, +got failover status:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.synced, tc.acct.Status.GetCondition(xpv1.TypeSynced), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): -want synced, +got synced:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
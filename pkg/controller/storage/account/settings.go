@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package account
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Azure/go-autorest/autorest/to"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	azurestorage "github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+// An accountSetting is a setting of a storage account that is reconciled
+// through the management plane, usually because the storage SDK that the
+// account is otherwise created and updated with predates it. Settings are
+// compared and written in their string form, so that drift in them can be
+// described.
+type accountSetting struct {
+	name    string
+	desired func(v1alpha3.AccountParameters) *string
+	get     func(azurestorage.ManagementOperations, context.Context) (string, error)
+	set     func(azurestorage.ManagementOperations, context.Context, string) error
+}
+
+// boolSetting returns an accountSetting of a boolean storage account setting.
+func boolSetting(name string, desired func(v1alpha3.AccountParameters) *bool, get func(azurestorage.ManagementOperations, context.Context) (bool, error), set func(azurestorage.ManagementOperations, context.Context, bool) error) accountSetting {
+	return accountSetting{
+		name: name,
+		desired: func(p v1alpha3.AccountParameters) *string {
+			if d := desired(p); d != nil {
+				return to.StringPtr(strconv.FormatBool(*d))
+			}
+			return nil
+		},
+		get: func(m azurestorage.ManagementOperations, ctx context.Context) (string, error) {
+			v, err := get(m, ctx)
+			return strconv.FormatBool(v), err
+		},
+		set: func(m azurestorage.ManagementOperations, ctx context.Context, v string) error {
+			return set(m, ctx, v == strconv.FormatBool(true))
+		},
+	}
+}
+
+var accountSettings = []accountSetting{
+	boolSetting("defaultToOAuthAuthentication",
+		func(p v1alpha3.AccountParameters) *bool { return p.DefaultToOAuthAuthentication },
+		azurestorage.ManagementOperations.GetDefaultToOAuth,
+		azurestorage.ManagementOperations.SetDefaultToOAuth),
+	boolSetting("allowSharedKeyAccess",
+		func(p v1alpha3.AccountParameters) *bool { return p.AllowSharedKeyAccess },
+		azurestorage.ManagementOperations.GetAllowSharedKeyAccess,
+		azurestorage.ManagementOperations.SetAllowSharedKeyAccess),
+	boolSetting("supportsHttpsTrafficOnly",
+		func(p v1alpha3.AccountParameters) *bool {
+			if p.StorageAccountSpec == nil || p.StorageAccountSpec.StorageAccountSpecProperties == nil {
+				return nil
+			}
+			return to.BoolPtr(p.StorageAccountSpec.EnableHTTPSTrafficOnly)
+		},
+		azurestorage.ManagementOperations.GetEnableHTTPSTrafficOnly,
+		azurestorage.ManagementOperations.SetEnableHTTPSTrafficOnly),
+	{
+		name:    "minimumTLSVersion",
+		desired: func(p v1alpha3.AccountParameters) *string { return p.MinimumTLSVersion },
+		get:     azurestorage.ManagementOperations.GetMinimumTLSVersion,
+		set:     azurestorage.ManagementOperations.SetMinimumTLSVersion,
+	},
+}
+
+// syncsettings changes the settings of the storage account that differ from
+// the ones its spec asks for, and describes how they differed. The account is
+// not consulted for settings its spec does not ask for.
+func (asd *accountSyncDeleter) syncsettings(ctx context.Context) ([]string, error) {
+	var drift []string
+	for _, s := range accountSettings {
+		want := s.desired(asd.acct.Spec.AccountParameters)
+		if want == nil {
+			continue
+		}
+		got, err := s.get(asd.management, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if *want == got {
+			continue
+		}
+		if err := s.set(asd.management, ctx, *want); err != nil {
+			return nil, err
+		}
+		drift = append(drift, fmt.Sprintf("%s: want %s, got %s", s.name, *want, got))
+	}
+	return drift, nil
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package account
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2017-06-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+	azurestoragefake "github.com/crossplane-contrib/provider-azure/pkg/clients/storage/fake"
+)
+
+func TestSyncSettings(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+
+	type observed struct {
+		oauth     bool
+		sharedKey bool
+		https     bool
+		tls       string
+		err       error
+	}
+	type want struct {
+		gets []string
+		set  map[string]string
+		res  reconcile.Result
+		err  bool
+	}
+	cases := map[string]struct {
+		reason      string
+		params      v1alpha3.AccountParameters
+		provisioned storage.ProvisioningState
+		observed    observed
+		want        want
+	}{
+		"Unset": {
+			reason:      "The account should not be consulted for settings its spec does not ask for.",
+			provisioned: storage.Succeeded,
+			want:        want{res: reconcile.Result{RequeueAfter: time.Minute}},
+		},
+		"EnableOAuth": {
+			reason:      "An account that does not default to OAuth authentication should be set to when its spec asks for it.",
+			params:      v1alpha3.AccountParameters{DefaultToOAuthAuthentication: to.BoolPtr(true)},
+			provisioned: storage.Succeeded,
+			observed:    observed{oauth: false},
+			want: want{
+				gets: []string{"defaultToOAuthAuthentication"},
+				set:  map[string]string{"defaultToOAuthAuthentication": "true"},
+				res:  reconcile.Result{RequeueAfter: time.Minute},
+			},
+		},
+		"DisableOAuth": {
+			reason:      "An account that defaults to OAuth authentication should be unset when its spec asks for shared key.",
+			params:      v1alpha3.AccountParameters{DefaultToOAuthAuthentication: to.BoolPtr(false)},
+			provisioned: storage.Succeeded,
+			observed:    observed{oauth: true},
+			want: want{
+				gets: []string{"defaultToOAuthAuthentication"},
+				set:  map[string]string{"defaultToOAuthAuthentication": "false"},
+				res:  reconcile.Result{RequeueAfter: time.Minute},
+			},
+		},
+		"DisallowSharedKey": {
+			reason:      "An account that allows shared key access should be changed to disallow it when its spec asks.",
+			params:      v1alpha3.AccountParameters{AllowSharedKeyAccess: to.BoolPtr(false)},
+			provisioned: storage.Succeeded,
+			observed:    observed{sharedKey: true},
+			want: want{
+				gets: []string{"allowSharedKeyAccess"},
+				set:  map[string]string{"allowSharedKeyAccess": "false"},
+				res:  reconcile.Result{RequeueAfter: time.Minute},
+			},
+		},
+		"HTTPSOnlyDisabledExternally": {
+			reason:      "An account that permits HTTP traffic outside of Crossplane should be restored to HTTPS only traffic when its spec asks for it.",
+			params:      v1alpha3.AccountParameters{StorageAccountSpec: &v1alpha3.StorageAccountSpec{StorageAccountSpecProperties: &v1alpha3.StorageAccountSpecProperties{EnableHTTPSTrafficOnly: true}}},
+			provisioned: storage.Succeeded,
+			observed:    observed{https: false},
+			want: want{
+				gets: []string{"supportsHttpsTrafficOnly"},
+				set:  map[string]string{"supportsHttpsTrafficOnly": "true"},
+				res:  reconcile.Result{RequeueAfter: time.Minute},
+			},
+		},
+		"NoProperties": {
+			reason:      "HTTPS only traffic should be left as it is when the spec has no account properties.",
+			params:      v1alpha3.AccountParameters{StorageAccountSpec: &v1alpha3.StorageAccountSpec{}},
+			provisioned: storage.Succeeded,
+			observed:    observed{https: true},
+			want:        want{res: reconcile.Result{RequeueAfter: time.Minute}},
+		},
+		"TLSLoweredExternally": {
+			reason:      "A minimum TLS version lowered outside of Crossplane should be restored.",
+			params:      v1alpha3.AccountParameters{MinimumTLSVersion: to.StringPtr("TLS1_2")},
+			provisioned: storage.Succeeded,
+			observed:    observed{tls: "TLS1_0"},
+			want: want{
+				gets: []string{"minimumTLSVersion"},
+				set:  map[string]string{"minimumTLSVersion": "TLS1_2"},
+				res:  reconcile.Result{RequeueAfter: time.Minute},
+			},
+		},
+		"InSync": {
+			reason:      "An account that already has the desired settings should not be changed.",
+			params:      v1alpha3.AccountParameters{DefaultToOAuthAuthentication: to.BoolPtr(true), AllowSharedKeyAccess: to.BoolPtr(false), MinimumTLSVersion: to.StringPtr("TLS1_2")},
+			provisioned: storage.Succeeded,
+			observed:    observed{oauth: true, sharedKey: false, tls: "TLS1_2"},
+			want: want{
+				gets: []string{"defaultToOAuthAuthentication", "allowSharedKeyAccess", "minimumTLSVersion"},
+				res:  reconcile.Result{RequeueAfter: time.Minute},
+			},
+		},
+		"NotProvisioned": {
+			reason:      "The settings of an account that is still being provisioned should not be consulted.",
+			params:      v1alpha3.AccountParameters{DefaultToOAuthAuthentication: to.BoolPtr(true)},
+			provisioned: storage.Creating,
+			want:        want{res: reconcile.Result{RequeueAfter: time.Minute}},
+		},
+		"GetFailed": {
+			reason:      "Errors getting the account's settings should be reported in the Synced condition.",
+			params:      v1alpha3.AccountParameters{DefaultToOAuthAuthentication: to.BoolPtr(true)},
+			provisioned: storage.Succeeded,
+			observed:    observed{err: errBoom},
+			want: want{
+				gets: []string{"defaultToOAuthAuthentication"},
+				res:  resultRequeue,
+				err:  true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			acct := v1alpha3test.NewMockAccount(testAccountName).Account
+			acct.Spec.AccountParameters = tc.params
+
+			var gets []string
+			var set map[string]string
+			record := func(setting, v string) {
+				if set == nil {
+					set = map[string]string{}
+				}
+				set[setting] = v
+			}
+			m := &azurestoragefake.MockManagementOperations{
+				MockGetDefaultToOAuth: func(context.Context) (bool, error) {
+					gets = append(gets, "defaultToOAuthAuthentication")
+					return tc.observed.oauth, tc.observed.err
+				},
+				MockSetDefaultToOAuth: func(_ context.Context, enabled bool) error {
+					record("defaultToOAuthAuthentication", strconv.FormatBool(enabled))
+					return nil
+				},
+				MockGetAllowSharedKeyAccess: func(context.Context) (bool, error) {
+					gets = append(gets, "allowSharedKeyAccess")
+					return tc.observed.sharedKey, tc.observed.err
+				},
+				MockSetAllowSharedKeyAccess: func(_ context.Context, allowed bool) error {
+					record("allowSharedKeyAccess", strconv.FormatBool(allowed))
+					return nil
+				},
+				MockGetEnableHTTPSTrafficOnly: func(context.Context) (bool, error) {
+					gets = append(gets, "supportsHttpsTrafficOnly")
+					return tc.observed.https, tc.observed.err
+				},
+				MockSetEnableHTTPSTrafficOnly: func(_ context.Context, enabled bool) error {
+					record("supportsHttpsTrafficOnly", strconv.FormatBool(enabled))
+					return nil
+				},
+				MockGetMinimumTLSVersion: func(context.Context) (string, error) {
+					gets = append(gets, "minimumTLSVersion")
+					return tc.observed.tls, tc.observed.err
+				},
+				MockSetMinimumTLSVersion: func(_ context.Context, version string) error {
+					record("minimumTLSVersion", version)
+					return nil
+				},
+			}
+			asd := &accountSyncDeleter{
+				createupdater: newMockAccountCreateUpdater(time.Minute),
+				AccountOperations: &azurestoragefake.MockAccountOperations{
+					MockGet: func(context.Context) (*storage.Account, error) {
+						return &storage.Account{AccountProperties: &storage.AccountProperties{ProvisioningState: tc.provisioned}}, nil
+					},
+				},
+				kube:       test.NewMockClient(),
+				acct:       acct,
+				management: m,
+				log:        logging.NewNopLogger(),
+			}
+
+			res, err := asd.sync(ctx)
+			if err != nil {
+				t.Fatalf("\n%s\naccountSyncDeleter.sync(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.res, res); diff != "" {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.gets, gets); diff != "" {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): -want lookups, +got lookups:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.set, set); diff != "" {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): -want set, +got set:\n%s", tc.reason, diff)
+			}
+			failed := acct.Status.GetCondition(xpv1.TypeSynced).Reason == xpv1.ReasonReconcileError
+			if failed != tc.want.err {
+				t.Errorf("\n%s\naccountSyncDeleter.sync(): want reconcile error %t, got %t", tc.reason, tc.want.err, failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+)
+
+const (
+	errGetAccessPolicies = "cannot get stored access policies"
+	errSetAccessPolicies = "cannot set stored access policies"
+)
+
+// storedAccessPolicies returns the stored access policies of the supplied
+// desired state in the form the blob service takes them. It returns nil if
+// the desired state does not manage stored access policies.
+func storedAccessPolicies(spec v1alpha3.ContainerParameters) []azblob.SignedIdentifier {
+	if spec.AccessPolicies == nil {
+		return nil
+	}
+	out := make([]azblob.SignedIdentifier, len(*spec.AccessPolicies))
+	for i, p := range *spec.AccessPolicies {
+		out[i] = azblob.SignedIdentifier{
+			ID: p.ID,
+			AccessPolicy: azblob.AccessPolicy{
+				Start:      p.Start.UTC(),
+				Expiry:     p.Expiry.UTC(),
+				Permission: p.Permission,
+			},
+		}
+	}
+	return out
+}
+
+// accessPolicyDrift returns how the container's stored access policies differ
+// from the desired ones. Nothing is read when the desired state does not
+// manage stored access policies.
+func (ccu *containerCreateUpdater) accessPolicyDrift(ctx context.Context, spec v1alpha3.ContainerParameters) ([]string, error) {
+	if spec.AccessPolicies == nil {
+		return nil, nil
+	}
+	got, err := ccu.GetStoredAccessPolicies(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetAccessPolicies)
+	}
+	return storedAccessPolicyDrift(storedAccessPolicies(spec), got), nil
+}
+
+// setAccessPolicies replaces the container's stored access policies with the
+// desired ones, if the desired state manages them. The blob service writes the
+// public access type along with them, so the desired one is written too.
+func (ccu *containerCreateUpdater) setAccessPolicies(ctx context.Context, spec v1alpha3.ContainerParameters) error {
+	if spec.AccessPolicies == nil {
+		return nil
+	}
+	access, _ := canonicalize(spec.PublicAccessType, nil)
+	return errors.Wrap(ccu.SetStoredAccessPolicies(ctx, access, storedAccessPolicies(spec)), errSetAccessPolicies)
+}
+
+// storedAccessPolicyDrift describes how the supplied observed stored access
+// policies differ from the supplied desired ones. Policies are matched by ID.
+func storedAccessPolicyDrift(want, got []azblob.SignedIdentifier) []string {
+	observed := make(map[string]azblob.AccessPolicy, len(got))
+	for _, p := range got {
+		observed[p.ID] = p.AccessPolicy
+	}
+	drift := []string{}
+	for _, p := range want {
+		o, ok := observed[p.ID]
+		delete(observed, p.ID)
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("accessPolicy %s: want %s, got none", p.ID, describeAccessPolicy(p.AccessPolicy)))
+		case !sameAccessPolicy(p.AccessPolicy, o):
+			drift = append(drift, fmt.Sprintf("accessPolicy %s: want %s, got %s", p.ID, describeAccessPolicy(p.AccessPolicy), describeAccessPolicy(o)))
+		}
+	}
+	unwanted := make([]string, 0, len(observed))
+	for id := range observed {
+		unwanted = append(unwanted, id)
+	}
+	sort.Strings(unwanted)
+	for _, id := range unwanted {
+		drift = append(drift, fmt.Sprintf("accessPolicy %s: want none, got %s", id, describeAccessPolicy(observed[id])))
+	}
+	return drift
+}
+
+// sameAccessPolicy returns true if the supplied access policies grant the same
+// permissions for the same period. The blob service stores times to the
+// second, and may return permissions in a different order.
+func sameAccessPolicy(a, b azblob.AccessPolicy) bool {
+	return a.Start.Truncate(time.Second).Equal(b.Start.Truncate(time.Second)) &&
+		a.Expiry.Truncate(time.Second).Equal(b.Expiry.Truncate(time.Second)) &&
+		canonicalPermission(a.Permission) == canonicalPermission(b.Permission)
+}
+
+// canonicalPermission returns the supplied access policy permissions in the
+// blob service's order. Permissions it does not know are returned as is.
+func canonicalPermission(p string) string {
+	pp := azblob.AccessPolicyPermission{}
+	if err := pp.Parse(p); err != nil {
+		return p
+	}
+	return pp.String()
+}
+
+func describeAccessPolicy(p azblob.AccessPolicy) string {
+	return fmt.Sprintf("%q from %s until %s", canonicalPermission(p.Permission), p.Start.UTC().Format(time.RFC3339), p.Expiry.UTC().Format(time.RFC3339))
+}
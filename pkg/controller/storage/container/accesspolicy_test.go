@@ -0,0 +1,238 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+	azurestoragefake "github.com/crossplane-contrib/provider-azure/pkg/clients/storage/fake"
+)
+
+func TestStoredAccessPolicyDrift(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiry := start.Add(24 * time.Hour)
+	policy := func(id, perm string, start time.Time) azblob.SignedIdentifier {
+		return azblob.SignedIdentifier{ID: id, AccessPolicy: azblob.AccessPolicy{Start: start, Expiry: expiry, Permission: perm}}
+	}
+
+	cases := map[string]struct {
+		reason string
+		want   []azblob.SignedIdentifier
+		got    []azblob.SignedIdentifier
+		drift  []string
+	}{
+		"Unchanged": {
+			reason: "Policies that grant the same permissions for the same period should not drift.",
+			want:   []azblob.SignedIdentifier{policy("read", "rl", start)},
+			got:    []azblob.SignedIdentifier{policy("read", "rl", start)},
+			drift:  []string{},
+		},
+		"PermissionOrder": {
+			reason: "Policies whose permissions differ only in order should not drift.",
+			want:   []azblob.SignedIdentifier{policy("read", "lr", start)},
+			got:    []azblob.SignedIdentifier{policy("read", "rl", start)},
+			drift:  []string{},
+		},
+		"SubSecond": {
+			reason: "Policies whose times differ by less than the second the blob service stores should not drift.",
+			want:   []azblob.SignedIdentifier{policy("read", "r", start.Add(500*time.Millisecond))},
+			got:    []azblob.SignedIdentifier{policy("read", "r", start)},
+			drift:  []string{},
+		},
+		"Missing": {
+			reason: "Desired policies the container does not have should drift.",
+			want:   []azblob.SignedIdentifier{policy("read", "r", start)},
+			drift:  []string{`accessPolicy read: want "r" from 2022-01-01T00:00:00Z until 2022-01-02T00:00:00Z, got none`},
+		},
+		"Changed": {
+			reason: "Desired policies whose permissions differ should drift.",
+			want:   []azblob.SignedIdentifier{policy("read", "r", start)},
+			got:    []azblob.SignedIdentifier{policy("read", "rw", start)},
+			drift:  []string{`accessPolicy read: want "r" from 2022-01-01T00:00:00Z until 2022-01-02T00:00:00Z, got "rw" from 2022-01-01T00:00:00Z until 2022-01-02T00:00:00Z`},
+		},
+		"Unwanted": {
+			reason: "Policies the desired state does not declare should drift, in order of their IDs.",
+			want:   []azblob.SignedIdentifier{policy("read", "r", start)},
+			got:    []azblob.SignedIdentifier{policy("write", "w", start), policy("read", "r", start), policy("delete", "d", start)},
+			drift: []string{
+				`accessPolicy delete: want none, got "d" from 2022-01-01T00:00:00Z until 2022-01-02T00:00:00Z`,
+				`accessPolicy write: want none, got "w" from 2022-01-01T00:00:00Z until 2022-01-02T00:00:00Z`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := storedAccessPolicyDrift(tc.want, tc.got)
+			if diff := cmp.Diff(tc.drift, got); diff != "" {
+				t.Errorf("\n%s\nstoredAccessPolicyDrift(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateAccessPolicies(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiry := start.Add(24 * time.Hour)
+	declared := &[]v1alpha3.StoredAccessPolicy{{
+		ID:         "read",
+		Start:      metav1.NewTime(start),
+		Expiry:     metav1.NewTime(expiry),
+		Permission: "rl",
+	}}
+	applied := []azblob.SignedIdentifier{{
+		ID:           "read",
+		AccessPolicy: azblob.AccessPolicy{Start: start, Expiry: expiry, Permission: "rl"},
+	}}
+
+	type want struct {
+		set      []azblob.SignedIdentifier
+		reason   xpv1.ConditionReason
+		policies bool
+	}
+	cases := map[string]struct {
+		reason   string
+		declared *[]v1alpha3.StoredAccessPolicy
+		access   azblob.PublicAccessType
+		got      []azblob.SignedIdentifier
+		getErr   error
+		setErr   error
+		want     want
+	}{
+		"Unchanged": {
+			reason:   "Containers whose stored access policies have not drifted should not be written.",
+			declared: declared,
+			got:      applied,
+			want:     want{reason: xpv1.ReconcileSuccess().Reason},
+		},
+		"Drifted": {
+			reason:   "Containers whose stored access policies have drifted should have the desired policies written.",
+			declared: declared,
+			want:     want{set: applied, reason: xpv1.ReconcileSuccess().Reason, policies: true},
+		},
+		"PublicAccessDrifted": {
+			reason:   "Stored access policies are written along with the public access type, so they should not be written again.",
+			declared: declared,
+			access:   azblob.PublicAccessBlob,
+			got:      applied,
+			want:     want{reason: xpv1.ReconcileSuccess().Reason},
+		},
+		"PublicAccessAndPoliciesDrifted": {
+			reason:   "Drifted stored access policies are corrected by writing the public access type, so they should not be written separately.",
+			declared: declared,
+			access:   azblob.PublicAccessBlob,
+			want:     want{reason: xpv1.ReconcileSuccess().Reason},
+		},
+		"ManagedEmpty": {
+			reason:   "A container whose desired state declares no stored access policies, rather than leaving them unset, should have its policies removed.",
+			declared: &[]v1alpha3.StoredAccessPolicy{},
+			got:      applied,
+			want:     want{set: []azblob.SignedIdentifier{}, reason: xpv1.ReconcileSuccess().Reason, policies: true},
+		},
+		"NotManaged": {
+			reason: "Stored access policies should be neither read nor written if the desired state leaves them unset.",
+			got:    applied,
+			getErr: errBoom,
+			want:   want{reason: xpv1.ReconcileSuccess().Reason},
+		},
+		"GetFailed": {
+			reason:   "Errors getting stored access policies should be reported.",
+			declared: declared,
+			getErr:   errBoom,
+			want:     want{reason: xpv1.ReconcileError(errBoom).Reason},
+		},
+		"SetFailed": {
+			reason:   "Errors setting stored access policies should be reported.",
+			declared: declared,
+			setErr:   errBoom,
+			want:     want{set: applied, reason: xpv1.ReconcileError(errBoom).Reason, policies: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var set []azblob.SignedIdentifier
+			written := false
+			access := tc.access
+			if access == "" {
+				access = azblob.PublicAccessNone
+			}
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockUpdatePartial = func(context.Context, *azblob.PublicAccessType, *azblob.Metadata) storage.UpdateResult {
+				return storage.UpdateResult{MetadataApplied: true, AccessPolicyApplied: true}
+			}
+			ops.MockGetContainerProperties = func(context.Context) (*storage.ContainerProperties, error) {
+				return &storage.ContainerProperties{PublicAccessType: access, Metadata: azblob.Metadata{"team": "a"}}, nil
+			}
+			ops.MockGetStoredAccessPolicies = func(context.Context) ([]azblob.SignedIdentifier, error) {
+				return tc.got, tc.getErr
+			}
+			ops.MockSetStoredAccessPolicies = func(_ context.Context, _ azblob.PublicAccessType, policies []azblob.SignedIdentifier) error {
+				set, written = policies, true
+				return tc.setErr
+			}
+			m := &azurestoragefake.MockManagementOperations{
+				MockGetAllowBlobPublicAccess: func(context.Context) (bool, error) { return true, nil },
+			}
+			acct := &v1alpha3.Account{}
+			meta.SetExternalName(acct, testAccountName)
+			c := v1alpha3test.NewMockContainer(testContainerName).WithSpecPAC(access).WithSpecMetadata(azblob.Metadata{"team": "a"}).Container
+			c.Spec.AccessPolicies = tc.declared
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				account:             acct,
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return m, nil
+				},
+				accountAccess: storage.NewAccountPublicAccessCache(time.Hour),
+				conditions:    DefaultErrorConditions(),
+			}
+
+			none := azblob.PublicAccessNone
+			if _, err := ccu.update(ctx, &none, azblob.Metadata{"team": "a"}); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if written != tc.want.policies {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want policies written %t, got %t", tc.reason, tc.want.policies, written)
+			}
+			if diff := cmp.Diff(tc.want.set, set); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): -want policies, +got:\n%s", tc.reason, diff)
+			}
+			if got := c.Status.GetCondition(xpv1.TypeSynced).Reason; got != tc.want.reason {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want Synced reason %s, got %s", tc.reason, tc.want.reason, got)
+			}
+		})
+	}
+}
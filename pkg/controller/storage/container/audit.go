@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+)
+
+// ReasonAnonymousAccess is the reason of audit events recorded when a
+// container is observed to permit anonymous public access.
+const ReasonAnonymousAccess event.Reason = "AnonymousAccessObserved"
+
+// A publicAccessAuditor reports containers that permit anonymous public
+// access. A nil auditor reports nothing.
+type publicAccessAuditor struct {
+	record event.Recorder
+	log    logging.Logger
+}
+
+// audit reports the supplied container of the supplied storage account if the
+// supplied observed public access permits anonymous access. Each access level is reported once, until
+// the container stops permitting anonymous access; the last reported level is
+// recorded in the container's status, which the caller must persist.
+func (a *publicAccessAuditor) audit(c *v1alpha3.Container, acct *v1alpha3.Account, observed azblob.PublicAccessType) {
+	if a == nil {
+		return
+	}
+	access, _ := canonicalize(observed, nil)
+	if access == azblob.PublicAccessNone {
+		c.Status.AtProvider.AuditedPublicAccess = ""
+		return
+	}
+	if string(access) == c.Status.AtProvider.AuditedPublicAccess {
+		return
+	}
+
+	account, name := "", externalName(c)
+	if acct != nil {
+		account = meta.GetExternalName(acct)
+	}
+	a.record.Event(c, event.Normal(ReasonAnonymousAccess,
+		fmt.Sprintf("Container %s in storage account %s permits anonymous access: public access type is %s", name, account, publicAccessName(access)),
+		"account", account, "container", name, "access", string(access)))
+	a.log.Info("Container permits anonymous access", "audit", true, "account", account, "container", name, "access", string(access))
+	c.Status.AtProvider.AuditedPublicAccess = string(access)
+}
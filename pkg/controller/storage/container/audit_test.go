@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+)
+
+// eventRecorder records the events it is asked to record.
+type eventRecorder struct {
+	events []event.Event
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, e event.Event) { r.events = append(r.events, e) }
+
+func (r *eventRecorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestPublicAccessAuditor(t *testing.T) {
+	acct := &v1alpha3.Account{}
+	meta.SetExternalName(acct, testAccountName)
+
+	anonymous := func(access string) event.Event {
+		return event.Event{
+			Type:    event.TypeNormal,
+			Reason:  ReasonAnonymousAccess,
+			Message: "Container " + testContainerName + " in storage account " + testAccountName + " permits anonymous access: public access type is \"" + access + "\"",
+			Annotations: map[string]string{
+				"account":   testAccountName,
+				"container": testContainerName,
+				"access":    access,
+			},
+		}
+	}
+
+	type want struct {
+		events  []event.Event
+		audited string
+	}
+	cases := map[string]struct {
+		reason   string
+		observed []azblob.PublicAccessType
+		want     want
+	}{
+		"Private": {
+			reason:   "Containers that do not permit anonymous access should not be audited.",
+			observed: []azblob.PublicAccessType{azblob.PublicAccessNone, azblob.PublicAccessNone},
+		},
+		"Unchanged": {
+			reason:   "Anonymous access should be audited once while it is unchanged.",
+			observed: []azblob.PublicAccessType{azblob.PublicAccessBlob, azblob.PublicAccessBlob, "Blob"},
+			want: want{
+				events:  []event.Event{anonymous("blob")},
+				audited: "blob",
+			},
+		},
+		"Changed": {
+			reason:   "A change of anonymous access level should be audited again.",
+			observed: []azblob.PublicAccessType{azblob.PublicAccessBlob, azblob.PublicAccessContainer},
+			want: want{
+				events:  []event.Event{anonymous("blob"), anonymous("container")},
+				audited: "container",
+			},
+		},
+		"Reopened": {
+			reason:   "Anonymous access that is removed and then restored should be audited again.",
+			observed: []azblob.PublicAccessType{azblob.PublicAccessBlob, azblob.PublicAccessNone, azblob.PublicAccessBlob},
+			want: want{
+				events:  []event.Event{anonymous("blob"), anonymous("blob")},
+				audited: "blob",
+			},
+		},
+		"Closed": {
+			reason:   "The audited access level should be cleared once anonymous access is removed.",
+			observed: []azblob.PublicAccessType{azblob.PublicAccessContainer, azblob.PublicAccessNone},
+			want: want{
+				events: []event.Event{anonymous("container")},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := &eventRecorder{}
+			a := &publicAccessAuditor{record: rec, log: logging.NewNopLogger()}
+			c := v1alpha3test.NewMockContainer(testContainerName).Container
+			for _, o := range tc.observed {
+				a.audit(c, acct, o)
+			}
+			if diff := cmp.Diff(tc.want.events, rec.events); diff != "" {
+				t.Errorf("\n%s\naudit(...): -want events, +got events:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.audited, c.Status.AtProvider.AuditedPublicAccess); diff != "" {
+				t.Errorf("\n%s\naudit(...): -want audited access, +got audited access:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPublicAccessAuditorNil(t *testing.T) {
+	c := v1alpha3test.NewMockContainer(testContainerName).Container
+	var a *publicAccessAuditor
+	a.audit(c, nil, azblob.PublicAccessContainer)
+	if c.Status.AtProvider.AuditedPublicAccess != "" {
+		t.Errorf("audit(...): want a nil auditor to audit nothing, got %q", c.Status.AtProvider.AuditedPublicAccess)
+	}
+}
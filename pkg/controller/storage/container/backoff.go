@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// baseRequeueBackoff is how long a container waits to be retried after
+// reconciling it fails for the first time.
+const baseRequeueBackoff = 1 * time.Second
+
+// A requeueBackoff counts how many times in a row reconciling each container
+// has failed, and bounds how long the container waits to be retried. A nil
+// backoff leaves retries to the workqueue's rate limiting.
+type requeueBackoff struct {
+	mu       sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+// bound returns the supplied result of reconciling the named container. If
+// the result asks for the container to be retried because reconciling it
+// failed, it instead asks for it to be retried after a delay that grows
+// exponentially with each consecutive failure, up to the supplied ceiling.
+// Results are returned unchanged when the ceiling is not positive.
+func (b *requeueBackoff) bound(nn types.NamespacedName, res reconcile.Result, ceiling time.Duration) reconcile.Result {
+	if b == nil || ceiling <= 0 {
+		return res
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !res.Requeue || res.RequeueAfter > 0 {
+		delete(b.failures, nn)
+		return res
+	}
+	if b.failures == nil {
+		b.failures = map[types.NamespacedName]int{}
+	}
+	b.failures[nn]++
+	return reconcile.Result{RequeueAfter: backoffDelay(b.failures[nn], ceiling)}
+}
+
+// forget stops counting failures of the named container.
+func (b *requeueBackoff) forget(nn types.NamespacedName) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, nn)
+}
+
+// backoffDelay returns how long to wait before retrying a container that has
+// failed to reconcile the supplied number of times in a row. It doubles with
+// each failure, starting from baseRequeueBackoff, but never exceeds ceiling.
+func backoffDelay(failures int, ceiling time.Duration) time.Duration {
+	d := baseRequeueBackoff
+	for i := 1; i < failures; i++ {
+		if d >= ceiling/2 {
+			return ceiling
+		}
+		d *= 2
+	}
+	if d > ceiling {
+		return ceiling
+	}
+	return d
+}
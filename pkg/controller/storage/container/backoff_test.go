@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		failures int
+		ceiling  time.Duration
+		want     time.Duration
+	}{
+		"FirstFailure": {
+			reason:   "The first failure should be retried after the base backoff.",
+			failures: 1,
+			ceiling:  time.Minute,
+			want:     baseRequeueBackoff,
+		},
+		"Doubling": {
+			reason:   "The backoff should double with each consecutive failure.",
+			failures: 4,
+			ceiling:  time.Minute,
+			want:     8 * baseRequeueBackoff,
+		},
+		"Capped": {
+			reason:   "The backoff should not exceed the ceiling.",
+			failures: 10,
+			ceiling:  time.Minute,
+			want:     time.Minute,
+		},
+		"CeilingBelowBase": {
+			reason:   "A ceiling below the base backoff should bound even the first retry.",
+			failures: 1,
+			ceiling:  100 * time.Millisecond,
+			want:     100 * time.Millisecond,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := backoffDelay(tc.failures, tc.ceiling); got != tc.want {
+				t.Errorf("\n%s\nbackoffDelay(%d, %s): want %s, got %s", tc.reason, tc.failures, tc.ceiling, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestBackoffDelayNeverExceedsCeiling(t *testing.T) {
+	for _, ceiling := range []time.Duration{time.Millisecond, 3 * time.Second, 5 * time.Minute, time.Duration(1<<62 + 1)} {
+		prev := time.Duration(0)
+		for failures := 1; failures <= 1000; failures++ {
+			got := backoffDelay(failures, ceiling)
+			if got <= 0 || got > ceiling {
+				t.Fatalf("backoffDelay(%d, %s): want a delay in (0, %s], got %s", failures, ceiling, ceiling, got)
+			}
+			if got < prev {
+				t.Fatalf("backoffDelay(%d, %s): want a delay of at least %s, got %s", failures, ceiling, prev, got)
+			}
+			prev = got
+		}
+	}
+}
+
+func TestRequeueBackoffBound(t *testing.T) {
+	nn := types.NamespacedName{Name: testContainerName}
+	ceiling := 4 * baseRequeueBackoff
+
+	cases := map[string]struct {
+		reason  string
+		b       *requeueBackoff
+		ceiling time.Duration
+		results []reconcile.Result
+		want    []reconcile.Result
+	}{
+		"Unbounded": {
+			reason:  "Results should be unchanged when no ceiling is configured.",
+			b:       &requeueBackoff{},
+			results: []reconcile.Result{resultRequeue, resultRequeue},
+			want:    []reconcile.Result{resultRequeue, resultRequeue},
+		},
+		"NilBackoff": {
+			reason:  "A nil backoff should leave results unchanged.",
+			ceiling: ceiling,
+			results: []reconcile.Result{resultRequeue},
+			want:    []reconcile.Result{resultRequeue},
+		},
+		"ConsecutiveFailures": {
+			reason:  "Consecutive failures should back off exponentially up to the ceiling.",
+			b:       &requeueBackoff{},
+			ceiling: ceiling,
+			results: []reconcile.Result{resultRequeue, resultRequeue, resultRequeue, resultRequeue, resultRequeue},
+			want: []reconcile.Result{
+				{RequeueAfter: baseRequeueBackoff},
+				{RequeueAfter: 2 * baseRequeueBackoff},
+				{RequeueAfter: 4 * baseRequeueBackoff},
+				{RequeueAfter: ceiling},
+				{RequeueAfter: ceiling},
+			},
+		},
+		"SuccessResets": {
+			reason:  "A successful reconcile should reset the backoff, and be returned unchanged.",
+			b:       &requeueBackoff{},
+			ceiling: ceiling,
+			results: []reconcile.Result{resultRequeue, resultRequeue, {RequeueAfter: time.Hour}, resultRequeue},
+			want: []reconcile.Result{
+				{RequeueAfter: baseRequeueBackoff},
+				{RequeueAfter: 2 * baseRequeueBackoff},
+				{RequeueAfter: time.Hour},
+				{RequeueAfter: baseRequeueBackoff},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := make([]reconcile.Result, len(tc.results))
+			for i, res := range tc.results {
+				got[i] = tc.b.bound(nn, res, tc.ceiling)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nbound(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
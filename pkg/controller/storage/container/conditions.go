@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+// Reasons a container failed to reconcile, by the class of storage error that
+// caused it to.
+const (
+	ReasonAuthenticationFailed xpv1.ConditionReason = "AuthenticationFailed"
+	ReasonAuthorizationFailed  xpv1.ConditionReason = "AuthorizationFailed"
+	ReasonNotFound             xpv1.ConditionReason = "NotFound"
+	ReasonConflict             xpv1.ConditionReason = "Conflict"
+	ReasonInvalidRequest       xpv1.ConditionReason = "InvalidRequest"
+	ReasonInvalidMetadata      xpv1.ConditionReason = "InvalidMetadata"
+	ReasonThrottled            xpv1.ConditionReason = "Throttled"
+	ReasonTimedOut             xpv1.ConditionReason = "TimedOut"
+	ReasonAzureServerError     xpv1.ConditionReason = "AzureServerError"
+	ReasonAccountSoftDeleted   xpv1.ConditionReason = "AccountSoftDeleted"
+
+	ReasonInsufficientPermissions xpv1.ConditionReason = "InsufficientRBACPermissions"
+	ReasonAccountDisabled         xpv1.ConditionReason = "AccountDisabled"
+)
+
+// TypeThrottled containers were last reconciled while Azure was throttling
+// requests to their storage account.
+const TypeThrottled xpv1.ConditionType = "Throttled"
+
+// ReasonNotThrottled is the reason of the Throttled condition of containers
+// that were reconciled successfully since they were last throttled.
+const ReasonNotThrottled xpv1.ConditionReason = "NotThrottled"
+
+// An ErrorCondition describes the Synced condition that reports a class of
+// storage error.
+type ErrorCondition struct {
+	// Reason of the condition.
+	Reason xpv1.ConditionReason
+
+	// Hint is prefixed to the error in the condition's message, if set.
+	Hint string
+}
+
+// ErrorConditions map classes of storage error to the Synced conditions that
+// report them. Errors of classes that are not mapped, including every error
+// when the map is nil, are reported as generic reconcile errors. Add to or
+// replace the DefaultErrorConditions to alert on other classes of error.
+type ErrorConditions map[storage.ErrorClass]ErrorCondition
+
+// DefaultErrorConditions returns the Synced conditions that report each class
+// of storage error by default.
+func DefaultErrorConditions() ErrorConditions {
+	return ErrorConditions{
+		storage.ErrorClassAuthentication: {Reason: ReasonAuthenticationFailed, Hint: "Azure did not accept the storage account's credentials"},
+		storage.ErrorClassAuthorization:  {Reason: ReasonAuthorizationFailed, Hint: "the storage account's credentials do not permit the operation"},
+		storage.ErrorClassNotFound:       {Reason: ReasonNotFound},
+		storage.ErrorClassConflict:       {Reason: ReasonConflict},
+		storage.ErrorClassInvalid:        {Reason: ReasonInvalidRequest},
+		storage.ErrorClassThrottled:      {Reason: ReasonThrottled, Hint: "Azure is throttling requests to the storage account"},
+		storage.ErrorClassTimeout:        {Reason: ReasonTimedOut},
+		storage.ErrorClassServer:         {Reason: ReasonAzureServerError},
+
+		storage.ErrorClassAccountSoftDeleted: {Reason: ReasonAccountSoftDeleted},
+		storage.ErrorClassInvalidMetadata:    {Reason: ReasonInvalidMetadata, Hint: "Azure rejected the container's metadata, likely because a key is not a valid C# identifier"},
+
+		storage.ErrorClassInsufficientPermissions: {
+			Reason: ReasonInsufficientPermissions,
+			Hint:   "The Azure AD identity lacks an RBAC data action the operation needs, likely because it was not assigned the Storage Blob Data Contributor role on the storage account",
+		},
+		storage.ErrorClassAccountDisabled: {
+			Reason: ReasonAccountDisabled,
+			Hint:   "The storage account or its subscription is disabled, typically because of its billing or subscription state rather than the container's configuration",
+		},
+	}
+}
+
+// reconcileError returns a Synced condition that reports the supplied error
+// with the condition its class maps to.
+func (m ErrorConditions) reconcileError(err error) xpv1.Condition {
+	c := xpv1.ReconcileError(err)
+	ec, ok := m[storage.ClassifyStorageError(err)]
+	if !ok {
+		return c
+	}
+	if ec.Reason != "" {
+		c.Reason = ec.Reason
+	}
+	if ec.Hint != "" {
+		c.Message = ec.Hint + ": " + c.Message
+	}
+	return c
+}
+
+// setReconcileError reports the supplied error in the Synced condition of the
+// supplied container. Errors caused by throttling are reported in its
+// Throttled condition too.
+func (m ErrorConditions) setReconcileError(c *v1alpha3.Container, err error) {
+	c.Status.SetConditions(m.reconcileError(err))
+	if storage.ClassifyStorageError(err) == storage.ErrorClassThrottled {
+		c.Status.SetConditions(throttled(err))
+	}
+}
+
+// awaitsOperator returns true if the supplied container last failed to
+// reconcile for a reason only an operator can fix, such as its Azure AD
+// identity lacking an RBAC role or its storage account being disabled.
+// Retrying cannot succeed until then, so such containers wait for the poll
+// interval rather than backing off.
+func awaitsOperator(c *v1alpha3.Container) bool {
+	s := c.Status.GetCondition(xpv1.TypeSynced)
+	return s.Status == corev1.ConditionFalse && (s.Reason == ReasonInsufficientPermissions || s.Reason == ReasonAccountDisabled)
+}
+
+// clearThrottled reports that the supplied container is no longer throttled,
+// if it was.
+func clearThrottled(c *v1alpha3.Container) {
+	if c.Status.GetCondition(TypeThrottled).Status == corev1.ConditionTrue {
+		c.Status.SetConditions(notThrottled())
+	}
+}
+
+// throttled returns a condition indicating that Azure throttled a request
+// to reconcile a container, and how long it asked to be retried after.
+func throttled(err error) xpv1.Condition {
+	msg := "Azure is throttling requests to the storage account"
+	if d := storage.RetryAfter(err); d > 0 {
+		msg = fmt.Sprintf("%s; retry after %s", msg, d)
+	}
+	return xpv1.Condition{
+		Type:               TypeThrottled,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonThrottled,
+		Message:            msg,
+	}
+}
+
+// notThrottled returns a condition indicating that a container was reconciled
+// successfully since it was last throttled.
+func notThrottled() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeThrottled,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNotThrottled,
+	}
+}
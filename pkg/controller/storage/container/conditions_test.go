@@ -0,0 +1,311 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+	azurestoragefake "github.com/crossplane-contrib/provider-azure/pkg/clients/storage/fake"
+)
+
+func newStorageError(status int, code azblob.ServiceCodeType) error {
+	h := http.Header{}
+	if code != "" {
+		h.Set("x-ms-error-code", string(code))
+	}
+	r := &http.Request{Method: http.MethodGet, URL: &url.URL{Scheme: "https", Host: "example.blob.core.windows.net"}, Header: http.Header{}}
+	return azblob.NewResponseError(nil, &http.Response{StatusCode: status, Header: h, Request: r}, "")
+}
+
+// tokenRefreshError is a management plane authentication failure.
+type tokenRefreshError struct{}
+
+func (tokenRefreshError) Error() string            { return "token refresh failed" }
+func (tokenRefreshError) Response() *http.Response { return nil }
+
+func TestErrorConditions(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason     string
+		conditions ErrorConditions
+		err        error
+		want       xpv1.ConditionReason
+	}{
+		"AuthenticationFailed": {
+			reason:     "Credentials the blob service did not accept should be reported as an authentication failure.",
+			conditions: DefaultErrorConditions(),
+			err:        newStorageError(http.StatusForbidden, azblob.ServiceCodeAuthenticationFailed),
+			want:       ReasonAuthenticationFailed,
+		},
+		"TokenRefreshFailed": {
+			reason:     "Credentials the management plane did not accept should be reported as an authentication failure.",
+			conditions: DefaultErrorConditions(),
+			err:        autorest.NewErrorWithError(tokenRefreshError{}, "storage.EncryptionScopesClient", "Get", nil, "Failure preparing request"),
+			want:       ReasonAuthenticationFailed,
+		},
+		"AuthorizationFailed": {
+			reason:     "Credentials that do not permit an operation should be reported as an authorization failure.",
+			conditions: DefaultErrorConditions(),
+			err:        errors.Wrap(newStorageError(http.StatusForbidden, azblob.ServiceCodeInsufficientAccountPermissions), "cannot get properties"),
+			want:       ReasonAuthorizationFailed,
+		},
+		"NotFound": {
+			reason:     "Resources that do not exist should be reported as not found.",
+			conditions: DefaultErrorConditions(),
+			err:        autorest.DetailedError{StatusCode: http.StatusNotFound},
+			want:       ReasonNotFound,
+		},
+		"Conflict": {
+			reason:     "Conflicting resources should be reported as a conflict.",
+			conditions: DefaultErrorConditions(),
+			err:        newStorageError(http.StatusConflict, azblob.ServiceCodeContainerBeingDeleted),
+			want:       ReasonConflict,
+		},
+		"InvalidRequest": {
+			reason:     "Requests Azure rejects as invalid should be reported as such.",
+			conditions: DefaultErrorConditions(),
+			err:        newStorageError(http.StatusBadRequest, azblob.ServiceCodeInvalidHeaderValue),
+			want:       ReasonInvalidRequest,
+		},
+		"InvalidMetadata": {
+			reason:     "Metadata Azure rejects as invalid should be reported as such, so that it can be corrected.",
+			conditions: DefaultErrorConditions(),
+			err:        newStorageError(http.StatusBadRequest, azblob.ServiceCodeInvalidMetadata),
+			want:       ReasonInvalidMetadata,
+		},
+		"MetadataRejected": {
+			reason:     "Metadata that is not written again because Azure rejected it should be reported as invalid metadata.",
+			conditions: DefaultErrorConditions(),
+			err:        &storage.MetadataRejectedError{Container: "test"},
+			want:       ReasonInvalidMetadata,
+		},
+		"Throttled": {
+			reason:     "Throttled requests should be reported as throttled.",
+			conditions: DefaultErrorConditions(),
+			err:        newStorageError(http.StatusServiceUnavailable, azblob.ServiceCodeServerBusy),
+			want:       ReasonThrottled,
+		},
+		"TimedOut": {
+			reason:     "Operations that exceed their time budget should be reported as timed out.",
+			conditions: DefaultErrorConditions(),
+			err:        errors.Wrap(storage.ErrBudgetExceeded, "cannot update container"),
+			want:       ReasonTimedOut,
+		},
+		"AzureServerError": {
+			reason:     "Azure failing to serve a request should be reported as a server error.",
+			conditions: DefaultErrorConditions(),
+			err:        newStorageError(http.StatusInternalServerError, azblob.ServiceCodeInternalError),
+			want:       ReasonAzureServerError,
+		},
+		"AccountSoftDeleted": {
+			reason:     "Containers of a soft-deleted account should be reported as such, so that the account can be restored.",
+			conditions: DefaultErrorConditions(),
+			err:        errors.Wrap(&storage.AccountSoftDeletedError{Account: "testaccount"}, "cannot get container"),
+			want:       ReasonAccountSoftDeleted,
+		},
+		"Unclassified": {
+			reason:     "Errors that cannot be classified should be reported as generic reconcile errors.",
+			conditions: DefaultErrorConditions(),
+			err:        errBoom,
+			want:       xpv1.ReconcileError(errBoom).Reason,
+		},
+		"InsufficientPermissions": {
+			reason:     "Requests whose Azure AD identity lacks an RBAC role should be reported as insufficient permissions, not as an authorization failure.",
+			conditions: DefaultErrorConditions(),
+			err:        newStorageError(http.StatusForbidden, "AuthorizationPermissionMismatch"),
+			want:       ReasonInsufficientPermissions,
+		},
+		"AccountDisabled": {
+			reason:     "Containers of a disabled account should be reported as such, so that operators know it is not a configuration problem.",
+			conditions: DefaultErrorConditions(),
+			err:        newStorageError(http.StatusForbidden, azblob.ServiceCodeAccountIsDisabled),
+			want:       ReasonAccountDisabled,
+		},
+		"SubscriptionDisabled": {
+			reason:     "Management plane errors caused by a disabled subscription should be reported as a disabled account.",
+			conditions: DefaultErrorConditions(),
+			err: autorest.DetailedError{
+				StatusCode: http.StatusConflict,
+				Original:   &azure.RequestError{ServiceError: &azure.ServiceError{Code: "ReadOnlyDisabledSubscription"}},
+			},
+			want: ReasonAccountDisabled,
+		},
+		"NoConditions": {
+			reason: "Every error should be reported as a generic reconcile error when no conditions are mapped.",
+			err:    newStorageError(http.StatusForbidden, azblob.ServiceCodeAuthenticationFailed),
+			want:   xpv1.ReconcileError(errBoom).Reason,
+		},
+		"CustomCondition": {
+			reason:     "Additional classes of error should be reported with the condition they are mapped to.",
+			conditions: ErrorConditions{storage.ErrorClassNotFound: {Reason: "AccountMissing"}},
+			err:        autorest.DetailedError{StatusCode: http.StatusNotFound},
+			want:       "AccountMissing",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := tc.conditions.reconcileError(tc.err)
+			if got.Type != xpv1.TypeSynced || got.Status != xpv1.ReconcileError(tc.err).Status {
+				t.Errorf("\n%s\nreconcileError(...): want a failed Synced condition, got %s %s", tc.reason, got.Type, got.Status)
+			}
+			if diff := cmp.Diff(tc.want, got.Reason); diff != "" {
+				t.Errorf("\n%s\nreconcileError(...): -want reason, +got reason:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestErrorConditionMessage(t *testing.T) {
+	err := newStorageError(http.StatusForbidden, azblob.ServiceCodeAuthenticationFailed)
+	got := DefaultErrorConditions().reconcileError(err)
+	want := "Azure did not accept the storage account's credentials: " + err.Error()
+	if diff := cmp.Diff(want, got.Message); diff != "" {
+		t.Errorf("reconcileError(...): -want message, +got message:\n%s", diff)
+	}
+}
+
+func TestInsufficientPermissionsMessage(t *testing.T) {
+	err := newStorageError(http.StatusForbidden, "AuthorizationPermissionMismatch")
+	got := DefaultErrorConditions().reconcileError(err)
+	if !strings.Contains(got.Message, "Storage Blob Data Contributor") {
+		t.Errorf("reconcileError(...): want message naming the Storage Blob Data Contributor role, got %q", got.Message)
+	}
+}
+
+func TestUpdateErrorCondition(t *testing.T) {
+	c := v1alpha3test.NewMockContainer(testContainerName).Container
+	ops := azurestoragefake.NewMockContainerOperations()
+	ops.MockGetContainerProperties = func(context.Context) (*storage.ContainerProperties, error) {
+		return nil, newStorageError(http.StatusForbidden, azblob.ServiceCodeInsufficientAccountPermissions)
+	}
+	ccu := &containerCreateUpdater{
+		ContainerOperations: ops,
+		kube:                test.NewMockClient(),
+		container:           c,
+		conditions:          DefaultErrorConditions(),
+	}
+
+	access := azblob.PublicAccessNone
+	if _, err := ccu.update(context.TODO(), &access, nil); err != nil {
+		t.Fatalf("containerCreateUpdater.update(): unexpected error: %v", err)
+	}
+	if got := c.Status.GetCondition(xpv1.TypeSynced).Reason; got != ReasonAuthorizationFailed {
+		t.Errorf("containerCreateUpdater.update(): want Synced reason %s, got %s", ReasonAuthorizationFailed, got)
+	}
+}
+
+func TestThrottledCondition(t *testing.T) {
+	throttledFor := func(retryAfter string) error {
+		err := newStorageError(http.StatusServiceUnavailable, azblob.ServiceCodeServerBusy)
+		var se azblob.StorageError
+		if errors.As(err, &se) && retryAfter != "" {
+			se.Response().Header.Set("Retry-After", retryAfter) // nolint: bodyclose
+		}
+		return err
+	}
+
+	cases := map[string]struct {
+		reason string
+		before []xpv1.Condition
+		err    error
+		want   xpv1.Condition
+	}{
+		"ThrottledWithRetryAfter": {
+			reason: "Throttled requests should set the Throttled condition, including how long Azure asked to be retried after.",
+			err:    throttledFor("30"),
+			want: xpv1.Condition{
+				Type:    TypeThrottled,
+				Status:  corev1.ConditionTrue,
+				Reason:  ReasonThrottled,
+				Message: "Azure is throttling requests to the storage account; retry after 30s",
+			},
+		},
+		"ThrottledWithoutRetryAfter": {
+			reason: "Throttled requests without a Retry-After header should set the Throttled condition without a hint.",
+			err:    newStorageError(http.StatusTooManyRequests, ""),
+			want: xpv1.Condition{
+				Type:    TypeThrottled,
+				Status:  corev1.ConditionTrue,
+				Reason:  ReasonThrottled,
+				Message: "Azure is throttling requests to the storage account",
+			},
+		},
+		"OtherError": {
+			reason: "Errors not caused by throttling should not set the Throttled condition.",
+			err:    newStorageError(http.StatusForbidden, azblob.ServiceCodeInsufficientAccountPermissions),
+			want:   xpv1.Condition{Type: TypeThrottled, Status: corev1.ConditionUnknown},
+		},
+		"ClearedOnSuccess": {
+			reason: "The Throttled condition should be cleared once the container is reconciled successfully.",
+			before: []xpv1.Condition{throttled(throttledFor("30"))},
+			want: xpv1.Condition{
+				Type:   TypeThrottled,
+				Status: corev1.ConditionFalse,
+				Reason: ReasonNotThrottled,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).Container
+			c.Status.SetConditions(tc.before...)
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockGetContainerProperties = func(context.Context) (*storage.ContainerProperties, error) {
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return &storage.ContainerProperties{}, nil
+			}
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return newProvisionedManagementOperations(), nil
+				},
+				conditions: DefaultErrorConditions(),
+			}
+
+			access := azblob.PublicAccessNone
+			if _, err := ccu.update(context.TODO(), &access, nil); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, c.Status.GetCondition(TypeThrottled), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -18,45 +18,124 @@ package container
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	mgmtstorage "github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
 	"github.com/Azure/azure-storage-blob-go/azblob"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane-contrib/provider-azure/apis/v1beta1"
 	azure "github.com/crossplane-contrib/provider-azure/pkg/clients"
 	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+	"github.com/crossplane-contrib/provider-azure/pkg/features"
 )
 
 const (
 	controllerName = "container.storage.azure.crossplane.io"
 	finalizer      = "finalizer." + controllerName
 
+	// reconcileTimeout is the time budget of a reconcile, which is shared by
+	// all of its container operations rather than granted to each.
 	reconcileTimeout = 2 * time.Minute
+
+	// visibleTimeout bounds how long we wait for a created container to be
+	// visible to the blob service.
+	visibleTimeout = 30 * time.Second
+
+	// verifyTimeout bounds how long we wait for the blob service to report a
+	// container's updated public access type.
+	verifyTimeout = 30 * time.Second
+
+	// DefaultReconcileJitter is the fraction of the poll interval by which
+	// requeues are randomized when no other jitter is configured.
+	DefaultReconcileJitter = 0.1
 )
 
 // Error strings
 const (
-	errAcctSecretNil = "account does not have a connection secret"
+	errAcctSecretNil  = "account does not have a connection secret"
+	errGetAuthInfo    = "cannot get auth information"
+	errGetMetadata    = "cannot get metadata ConfigMap %s"
+	errGetProperties  = "cannot get container properties"
+	errGetAccount     = "cannot get storage account"
+	errVerifyDeletion = "cannot verify that container was deleted"
+	errGetPC          = "cannot get provider config %s of storage account"
+	errAwaitVisible   = "created container is not yet visible"
+	errSetScope       = "cannot set default encryption scope %s"
+	errEnsureScope    = "cannot create default encryption scope %s"
+	errImport         = "cannot import existing container"
+	errGetSecret      = "cannot get connection secret"
+	errDeleteSecret   = "cannot delete connection secret"
+	errUpdateImported = "cannot update spec of imported container"
+	errUpdateAdopted  = "cannot update spec of adopted container"
+	errReconcileNow   = "cannot remove reconcile-now request"
+	errRestoreAccount = "cannot restore soft-deleted storage account"
+	errArchive        = "cannot archive blobs to container %s"
+	errRemoveScope    = "cannot remove default encryption scope %s; Azure may reject removal while blobs encrypted with it exist"
+
+	errInvalidJitter = "reconcile jitter must be at least 0 and less than 1, got %v"
+	errAddSweeper    = "cannot add orphaned secret sweeper"
+	errIndexMetadata = "cannot index containers by their metadata ConfigMap"
+
+	errDeletionProtected     = "deletion protected: container %s cannot be deleted while spec.deletionProtection is true; set it to false to delete it"
+	errAccountNotProvisioned = "storage account is not yet provisioned: provisioning state is %q"
+	errPublicAccessDenied    = "admission denied: public access type %s is not permitted for containers in the %s environment; at most %s is permitted"
+	errMetadataValueTooLong  = "metadata value of key %q is %d bytes long, longer than the limit of %d bytes"
+	errLocationDenied        = "admission denied: storage account location %s is not permitted by the provider config; permitted locations are %s"
+	errMetadataKeyDenied     = "admission denied: metadata key %q is not permitted by the provider config; permitted keys are %s"
+	errScopeRemovalBlocked   = "cannot remove default encryption scope %s while preventEncryptionScopeOverride is set; set preventEncryptionScopeOverride to false first, or recreate the container to change its encryption scope"
+)
+
+// ReasonDriftDetected indicates that an observe-only container differs from
+// its desired state.
+const ReasonDriftDetected xpv1.ConditionReason = "DriftDetected"
+
+// ReasonAdoptionPending indicates that a container is not yet adopted because
+// it was modified too recently.
+const ReasonAdoptionPending xpv1.ConditionReason = "AdoptionPending"
+
+// TypeExternallyModified containers were changed outside of Crossplane since
+// they were last observed.
+const TypeExternallyModified xpv1.ConditionType = "ExternallyModified"
+
+// Reasons a container was or was not externally modified.
+const (
+	ReasonExternalModification   xpv1.ConditionReason = "ExternalModificationDetected"
+	ReasonNoExternalModification xpv1.ConditionReason = "NoExternalModification"
 )
 
 var (
 	resultRequeue = reconcile.Result{Requeue: true}
+
+	// deletionBackoff bounds how long we wait for a deleted container to stop
+	// existing before we requeue rather than remove our finalizer.
+	deletionBackoff = wait.Backoff{Duration: 1 * time.Second, Factor: 2, Steps: 6, Cap: 16 * time.Second}
 )
 
 // Reconciler reconciles an Azure storage container
@@ -68,56 +147,418 @@ type Reconciler struct {
 
 	poll time.Duration
 
+	// operations limits the number of blob service requests each reconcile
+	// may send. Requests are not limited when it is zero.
+	operations int
+
+	// conditions map classes of storage error to the Synced conditions that
+	// report them.
+	conditions ErrorConditions
+
+	// backoff bounds how long failing containers wait to be retried.
+	backoff *requeueBackoff
+
+	// correlate makes every blob service request of a reconcile with the
+	// same client request ID, which is recorded in the container's status.
+	correlate bool
+
 	log logging.Logger
 }
 
-// Setup adds a controller that reconciles Containers.
+// Options configure the Container controller.
+type Options struct {
+	// Jitter is the fraction of the poll interval by which requeues are
+	// randomized either way.
+	Jitter float64
+
+	// AuditPublicAccess enables audit events and logs for containers that
+	// are observed to permit anonymous public access.
+	AuditPublicAccess bool
+
+	// ErrorConditions map classes of storage error to the Synced conditions
+	// that report them. Errors of unmapped classes are reported as generic
+	// reconcile errors.
+	ErrorConditions ErrorConditions
+
+	// MetadataCipher encrypts the values of metadata keys that start with
+	// the SensitiveMetadataPrefix before they are written to Azure, and
+	// decrypts them when they are read. Metadata is stored in plaintext when
+	// it is nil.
+	MetadataCipher storage.MetadataCipher
+
+	// SensitiveMetadataPrefix is the prefix of the metadata keys whose values
+	// the MetadataCipher encrypts. It is matched ignoring case.
+	SensitiveMetadataPrefix string
+
+	// RestoreSoftDeletedAccounts requests that storage accounts found to be
+	// soft-deleted are restored. Their containers are otherwise reported as
+	// requiring a restore until the account is recovered.
+	RestoreSoftDeletedAccounts bool
+
+	// MaxMetadataValueLength is the longest, in bytes, that the value of
+	// each metadata key of a Container may be. Containers with a longer value
+	// are neither created nor updated. Values are not limited when it is
+	// zero.
+	MaxMetadataValueLength int
+
+	// PublicAccessRemediationDelay is how long the public access type of a
+	// Container may drift from its desired state before it is corrected.
+	// A warning event is recorded when the drift is first observed, and the
+	// Container is requeued until the delay has passed. Drift of anything
+	// else is corrected meanwhile. Drift is corrected immediately when it is
+	// zero.
+	PublicAccessRemediationDelay time.Duration
+
+	// SnapshotBeforeDelete records the public access type and metadata of
+	// each Container in an event before it is deleted, so that accidentally
+	// deleted Containers can be recreated. The values of sensitive metadata
+	// keys are redacted.
+	SnapshotBeforeDelete bool
+
+	// MaxOperationsPerReconcile limits the number of requests that each
+	// reconcile of a Container may send to the blob service, so that
+	// containers with many blobs or snapshots do not make a single reconcile
+	// unboundedly slow or costly. A reconcile that would exceed it fails and
+	// is requeued, and deletions of blobs resume where they left off.
+	// Requests are not limited when it is zero.
+	MaxOperationsPerReconcile int
+
+	// OrphanedSecretSweepInterval is how often connection secrets controlled
+	// by Containers that no longer exist are deleted. Orphaned secrets are
+	// not swept when it is zero.
+	OrphanedSecretSweepInterval time.Duration
+
+	// SanitizeMetadataKeys retries writing metadata that Azure rejected as
+	// invalid once, with its keys made valid C# identifiers by replacing
+	// their invalid characters with underscores. Rejected metadata is
+	// otherwise not written again until the Container's spec changes.
+	SanitizeMetadataKeys bool
+
+	// ManagedMetadataKeys are metadata keys that the controller or platform
+	// manages, such as keys that every Container is given through its
+	// ProviderConfig's default metadata. Their drift is still corrected, but
+	// is reported as platform drift in a Container's status rather than as
+	// drift from its spec. Keys are compared case-insensitively.
+	ManagedMetadataKeys []string
+
+	// OwnerMetadataKey is a metadata key that other tools set to claim a
+	// container, such as owner. Containers whose value of it is neither
+	// empty nor OwnerIdentity are never changed, and report a conflict.
+	// Ownership is not checked when it is empty.
+	OwnerMetadataKey string
+
+	// OwnerIdentity is the value of OwnerMetadataKey that identifies this
+	// provider as a container's owner.
+	OwnerIdentity string
+
+	// CredentialPreference is the order in which kinds of credential are
+	// tried when managing a Container, most preferred first. The first kind
+	// that is available is used. When it is empty Containers whose Account
+	// disallows shared key access are managed with a token credential if one
+	// can be obtained, and all others with the account key.
+	CredentialPreference []CredentialKind
+
+	// SuppressUnchangedStatus skips writing the status of a Container when
+	// a reconcile left it unchanged, such as when it had not drifted and
+	// its conditions and recorded operations are the same. Status is
+	// written every reconcile when it is false.
+	SuppressUnchangedStatus bool
+
+	// CheckAccountPublicAccess fails Containers that ask for public access
+	// before they are created or updated if their storage account disallows
+	// blob public access, rather than leaving Azure to reject the write.
+	CheckAccountPublicAccess bool
+
+	// AccountPublicAccessTTL is how long whether a storage account allows
+	// blob public access is cached. It is asked before every write when it
+	// is not positive.
+	AccountPublicAccessTTL time.Duration
+
+	// RecordTransitionEvents records an event each time a Container is
+	// created, updated, or deleted, and when its drift is detected or
+	// corrected. Reconciles that change nothing record nothing.
+	RecordTransitionEvents bool
+
+	// CorrelateRequests makes every blob service request of a reconcile of
+	// a Container with the same client request ID, derived from the
+	// Container's UID and generation, and records it in the Container's
+	// status so that the requests can be found in Azure diagnostic logs.
+	// Reconciles of the same generation share an ID, so that recording it
+	// does not change the status of a Container that is up to date.
+	CorrelateRequests bool
+
+	// Tracer traces each blob service operation of a Container with a span.
+	// Operations are traced with the global OpenTelemetry tracer provider
+	// when it is nil, which records nothing unless a provider is registered.
+	Tracer storage.Tracer
+}
+
+// DefaultSensitiveMetadataPrefix is the default prefix of the metadata keys
+// whose values are encrypted when a MetadataCipher is configured.
+const DefaultSensitiveMetadataPrefix = "secret-"
+
+// DefaultMaxMetadataValueLength is the default limit on the length, in bytes,
+// of each metadata value. It is half of the 8 KiB that Azure permits all of a
+// container's metadata to take up.
+const DefaultMaxMetadataValueLength = 4096
+
+// DefaultOptions returns the Container controller's default options.
+func DefaultOptions() Options {
+	return Options{
+		Jitter:                  DefaultReconcileJitter,
+		AuditPublicAccess:       true,
+		ErrorConditions:         DefaultErrorConditions(),
+		SensitiveMetadataPrefix: DefaultSensitiveMetadataPrefix,
+		MaxMetadataValueLength:  DefaultMaxMetadataValueLength,
+	}
+}
+
+// Setup adds a controller that reconciles Containers with the DefaultOptions.
 func Setup(mgr ctrl.Manager, o controller.Options) error {
+	return SetupWithOptions(DefaultOptions())(mgr, o)
+}
+
+// SetupWithJitter returns a function that adds a controller that reconciles
+// Containers. Containers are requeued after the poll interval, randomized by
+// up to the supplied fraction of it either way, so that containers created
+// together do not keep hitting the blob service together.
+func SetupWithJitter(jitter float64) func(ctrl.Manager, controller.Options) error {
+	opts := DefaultOptions()
+	opts.Jitter = jitter
+	return SetupWithOptions(opts)
+}
+
+// SetupWithOptions returns a function that adds a controller that reconciles
+// Containers with the supplied options.
+func SetupWithOptions(opts Options) func(ctrl.Manager, controller.Options) error {
+	return func(mgr ctrl.Manager, o controller.Options) error {
+		if opts.Jitter < 0 || opts.Jitter >= 1 {
+			return errors.Errorf(errInvalidJitter, opts.Jitter)
+		}
+		if err := validateCredentialPreference(opts.CredentialPreference); err != nil {
+			return err
+		}
+		return setup(mgr, o, opts)
+	}
+}
+
+func setup(mgr ctrl.Manager, o controller.Options, opts Options) error {
 	name := managed.ControllerName(v1alpha3.ContainerGroupKind)
+	r := newReconciler(mgr, o, opts)
+
+	if opts.OrphanedSecretSweepInterval > 0 {
+		s := &orphanedSecretSweeper{
+			kube:     mgr.GetClient(),
+			interval: opts.OrphanedSecretSweepInterval,
+			log:      o.Logger.WithValues("controller", name),
+		}
+		if err := mgr.Add(s); err != nil {
+			return errors.Wrap(err, errAddSweeper)
+		}
+	}
 
-	r := &Reconciler{
-		Client:           mgr.GetClient(),
-		syncdeleterMaker: &containerSyncdeleterMaker{mgr.GetClient()},
-		Initializer:      managed.NewNameAsExternalName(mgr.GetClient()),
-		poll:             o.PollInterval,
-		log:              o.Logger.WithValues("controller", name),
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha3.Container{}, metadataFromIndex, indexMetadataFrom); err != nil {
+		return errors.Wrap(err, errIndexMetadata)
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o.ForControllerRuntime()).
 		For(&v1alpha3.Container{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(containersForConfigMap(mgr.GetClient()))).
 		Complete(r)
 }
 
+// newReconciler returns a Reconciler of Containers that is configured by the
+// supplied options.
+func newReconciler(mgr ctrl.Manager, o controller.Options, opts Options) *Reconciler {
+	name := managed.ControllerName(v1alpha3.ContainerGroupKind)
+
+	var audit *publicAccessAuditor
+	if opts.AuditPublicAccess {
+		audit = &publicAccessAuditor{
+			record: event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+			log:    o.Logger.WithValues("controller", name),
+		}
+	}
+
+	credentials := &credentialWarner{
+		record: event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		log:    o.Logger.WithValues("controller", name),
+	}
+
+	var snapshots *configSnapshotter
+	if opts.SnapshotBeforeDelete {
+		snapshots = &configSnapshotter{
+			record:          event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+			sensitivePrefix: opts.SensitiveMetadataPrefix,
+		}
+	}
+
+	var transitions *transitionRecorder
+	if opts.RecordTransitionEvents {
+		transitions = &transitionRecorder{record: event.NewAPIRecorder(mgr.GetEventRecorderFor(name))}
+	}
+
+	var accountAccess *storage.AccountPublicAccessCache
+	if opts.CheckAccountPublicAccess {
+		accountAccess = storage.NewAccountPublicAccessCache(opts.AccountPublicAccessTTL)
+	}
+
+	tracer := opts.Tracer
+	if tracer == nil {
+		tracer = storage.NewGlobalTracer()
+	}
+
+	var kube client.Client = mgr.GetClient()
+	if opts.SuppressUnchangedStatus {
+		kube = newStatusDebouncer(kube)
+	}
+
+	return &Reconciler{
+		Client: kube,
+		syncdeleterMaker: &containerSyncdeleterMaker{
+			Client:          kube,
+			observeOnly:     o.Features.Enabled(features.ObserveOnly),
+			jitter:          opts.Jitter,
+			services:        &serviceCache{},
+			audit:           audit,
+			conditions:      opts.ErrorConditions,
+			cipher:          opts.MetadataCipher,
+			sensitivePrefix: opts.SensitiveMetadataPrefix,
+			tokenCredential: newTokenCredentialFn(mgr.GetClient()),
+			credentials:     credentials,
+			preference:      opts.CredentialPreference,
+			restoreDeleted:  opts.RestoreSoftDeletedAccounts,
+			maxValueLength:  opts.MaxMetadataValueLength,
+			snapshots:       snapshots,
+			sanitizeKeys:    opts.SanitizeMetadataKeys,
+			managedKeys:     opts.ManagedMetadataKeys,
+			ownerKey:        opts.OwnerMetadataKey,
+			owner:           opts.OwnerIdentity,
+			accountAccess:   accountAccess,
+			transitions:     transitions,
+			tracer:          tracer,
+			remediation: &publicAccessRemediator{
+				delay:  opts.PublicAccessRemediationDelay,
+				record: event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+			},
+		},
+		Initializer: managed.NewNameAsExternalName(kube),
+		poll:        o.PollInterval,
+		operations:  opts.MaxOperationsPerReconcile,
+		conditions:  opts.ErrorConditions,
+		backoff:     &requeueBackoff{},
+		correlate:   opts.CorrelateRequests,
+		log:         o.Logger.WithValues("controller", name),
+	}
+}
+
+// metadataFromIndex indexes Containers by the namespace and name of the
+// ConfigMap they take their metadata from.
+const metadataFromIndex = "spec.metadataFrom"
+
+// indexMetadataFrom returns the metadataFromIndex key of the supplied
+// Container. Containers that don't take their metadata from a ConfigMap are
+// not indexed.
+func indexMetadataFrom(o client.Object) []string {
+	c, ok := o.(*v1alpha3.Container)
+	if !ok || c.Spec.MetadataFrom == nil {
+		return nil
+	}
+	return []string{types.NamespacedName{Namespace: c.Spec.MetadataFrom.Namespace, Name: c.Spec.MetadataFrom.Name}.String()}
+}
+
+// containersForConfigMap returns a function that maps a ConfigMap to requests
+// for each Container that takes its metadata from it.
+func containersForConfigMap(kube client.Reader) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		l := &v1alpha3.ContainerList{}
+		key := types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}.String()
+		if err := kube.List(context.TODO(), l, client.MatchingFields{metadataFromIndex: key}); err != nil {
+			return nil
+		}
+		reqs := []reconcile.Request{}
+		for _, c := range l.Items {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: c.GetName()}})
+		}
+		return reqs
+	}
+}
+
 // Reconcile reads that state of the cluster for a Provider acct and makes changes based on the state read
 // and what is in the Provider.Spec
 func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	r.log.Debug("Reconciling", "request", request)
 
-	ctx, cancel := context.WithTimeout(ctx, reconcileTimeout)
+	ctx, cancel := storage.WithBudget(ctx, reconcileTimeout)
 	defer cancel()
+	if r.operations > 0 {
+		ctx = storage.WithOperationBudget(ctx, r.operations)
+	}
 
 	c := &v1alpha3.Container{}
 	if err := r.Get(ctx, request.NamespacedName, c); err != nil {
+		r.backoff.forget(request.NamespacedName)
 		return reconcile.Result{}, resource.Ignore(kerrors.IsNotFound, err)
 	}
 	if err := r.Initialize(ctx, c); err != nil {
 		return reconcile.Result{}, err
 	}
 
+	if err := r.reconcileNow(ctx, request.NamespacedName, c); err != nil {
+		return reconcile.Result{}, err
+	}
+	if r.correlate {
+		ctx = correlate(ctx, c)
+	}
+
 	sd, err := r.newSyncdeleter(ctx, c, r.poll)
 	if err != nil {
-		c.Status.SetConditions(xpv1.ReconcileError(err))
+		r.conditions.setReconcileError(c, err)
 		return resultRequeue, r.Status().Update(ctx, c)
 	}
 
+	var res reconcile.Result
 	// Check for deletion
 	if c.DeletionTimestamp != nil {
-		return sd.delete(ctx)
+		res, err = sd.delete(ctx)
+	} else {
+		res, err = sd.sync(ctx)
+	}
+	if res.Requeue && r.poll > 0 && awaitsOperator(c) {
+		res = reconcile.Result{RequeueAfter: r.poll}
 	}
+	return r.backoff.bound(request.NamespacedName, res, sd.requeueCeiling()), err
+}
+
+// correlate returns a context whose blob service requests are all made with a
+// correlation ID derived from the supplied container's UID and generation.
+// The ID is recorded in the container's status, which the caller must
+// persist. It only changes with the container's spec, so that recording it
+// doesn't trigger another reconcile of a container that is up to date.
+func correlate(ctx context.Context, c *v1alpha3.Container) context.Context {
+	c.Status.AtProvider.CorrelationID = fmt.Sprintf("%s-%d", c.GetUID(), c.GetGeneration())
+	return storage.WithCorrelationID(ctx, c.Status.AtProvider.CorrelationID)
+}
 
-	return sd.sync(ctx)
+// reconcileNow removes a request to reconcile the supplied container
+// immediately, and forgets its backoff so that it isn't delayed by earlier
+// failures. The container is then reconciled as usual. The request is removed
+// before reconciling so that the update the removal causes finds no request,
+// rather than triggering another reconcile; if the removal fails the request
+// is kept, and retried.
+func (r *Reconciler) reconcileNow(ctx context.Context, nn types.NamespacedName, c *v1alpha3.Container) error {
+	if _, ok := c.GetAnnotations()[v1alpha3.AnnotationKeyReconcileNow]; !ok {
+		return nil
+	}
+	meta.RemoveAnnotations(c, v1alpha3.AnnotationKeyReconcileNow)
+	if err := r.Update(ctx, c); err != nil {
+		return errors.Wrap(err, errReconcileNow)
+	}
+	r.backoff.forget(nn)
+	r.log.Debug("Reconciling on request", "request", nn)
+	return nil
 }
 
 type syncdeleterMaker interface {
@@ -126,6 +567,160 @@ type syncdeleterMaker interface {
 
 type containerSyncdeleterMaker struct {
 	client.Client
+	observeOnly bool
+
+	// jitter is the fraction of the poll interval by which requeues are
+	// randomized.
+	jitter float64
+
+	// tracer traces container operations. Operations are not traced when it
+	// is nil.
+	tracer storage.Tracer
+
+	// services caches the blob service of each storage account, so that
+	// containers in the same account share its connection pool.
+	services *serviceCache
+
+	// audit reports containers that permit anonymous public access.
+	audit *publicAccessAuditor
+
+	// conditions map classes of storage error to the Synced conditions that
+	// report them.
+	conditions ErrorConditions
+
+	// cipher encrypts the values of metadata keys that start with
+	// sensitivePrefix. Metadata is not encrypted when it is nil.
+	cipher          storage.MetadataCipher
+	sensitivePrefix string
+
+	// tokenCredential returns the credential used to manage containers whose
+	// storage account disallows shared key access. Such containers are
+	// managed with the account key, and warned about, when it is nil.
+	tokenCredential tokenCredentialFn
+	credentials     *credentialWarner
+
+	// preference is the order in which kinds of credential are tried. The
+	// default choice of credential is made when it is empty.
+	preference []CredentialKind
+
+	// restoreDeleted requests that soft-deleted storage accounts are
+	// restored.
+	restoreDeleted bool
+
+	// maxValueLength limits the length of each metadata value. Values are
+	// not limited when it is zero.
+	maxValueLength int
+
+	// remediation delays correcting drift of containers' public access
+	// types.
+	remediation *publicAccessRemediator
+
+	// snapshots records the configuration of containers before they are
+	// deleted.
+	snapshots *configSnapshotter
+
+	// sanitizeKeys retries writing rejected metadata with sanitized keys.
+	sanitizeKeys bool
+
+	// managedKeys are the metadata keys whose drift is platform drift.
+	managedKeys []string
+
+	// ownerKey and owner identify the containers that may be changed.
+	ownerKey string
+	owner    string
+
+	// accountAccess caches whether storage accounts allow blob public
+	// access. It is not checked when it is nil.
+	accountAccess *storage.AccountPublicAccessCache
+
+	// transitions records the state transitions of containers.
+	transitions *transitionRecorder
+}
+
+// A serviceCache caches a ServiceHandle per storage account. A nil cache
+// caches nothing.
+type serviceCache struct {
+	mu       sync.Mutex
+	services map[string]cachedService
+}
+
+type cachedService struct {
+	key     string
+	options storage.ContainerHandleOptions
+	handle  *storage.ServiceHandle
+}
+
+// tokenIdentityPrefix prefixes the identities of token credentials in a
+// serviceCache, so they cannot be mistaken for account keys.
+const tokenIdentityPrefix = "token/"
+
+// get returns a ServiceHandle for the supplied storage account. A cached
+// handle is only returned if it was created with the supplied account key and
+// options, so that rotated keys and changed options take effect.
+func (c *serviceCache) get(account, key string, o storage.ContainerHandleOptions) (*storage.ServiceHandle, error) {
+	if c == nil {
+		return storage.NewServiceHandle(account, key, o)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.services[account]; ok && s.key == key && reflect.DeepEqual(s.options, o) {
+		return s.handle, nil
+	}
+	h, err := storage.NewServiceHandle(account, key, o)
+	if err != nil {
+		return nil, err
+	}
+	c.put(account, key, o, h)
+	return h, nil
+}
+
+// getWithSAS returns a ServiceHandle for the supplied storage account that
+// authorizes requests with the supplied shared access signature. A cached
+// handle is only returned if it was created with the same signature and with
+// the supplied options.
+func (c *serviceCache) getWithSAS(account, sas string, o storage.ContainerHandleOptions) (*storage.ServiceHandle, error) {
+	if c == nil {
+		return storage.NewServiceHandleWithSAS(account, sas, o)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.services[account]; ok && s.key == sasIdentityPrefix+sas && reflect.DeepEqual(s.options, o) {
+		return s.handle, nil
+	}
+	h, err := storage.NewServiceHandleWithSAS(account, sas, o)
+	if err != nil {
+		return nil, err
+	}
+	c.put(account, sasIdentityPrefix+sas, o, h)
+	return h, nil
+}
+
+// getWithCredential returns a ServiceHandle for the supplied storage account
+// that authorizes requests with the supplied credential. A cached handle is
+// only returned if it was created with a credential of the same identity and
+// with the supplied options.
+func (c *serviceCache) getWithCredential(account, id string, o storage.ContainerHandleOptions, cred azblob.Credential) (*storage.ServiceHandle, error) {
+	if c == nil {
+		return storage.NewServiceHandleWithCredential(account, cred, o)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.services[account]; ok && s.key == id && reflect.DeepEqual(s.options, o) {
+		return s.handle, nil
+	}
+	h, err := storage.NewServiceHandleWithCredential(account, cred, o)
+	if err != nil {
+		return nil, err
+	}
+	c.put(account, id, o, h)
+	return h, nil
+}
+
+func (c *serviceCache) put(account, key string, o storage.ContainerHandleOptions, h *storage.ServiceHandle) {
+	if c.services == nil {
+		c.services = map[string]cachedService{}
+	}
+	c.services[account] = cachedService{key: key, options: o, handle: h}
 }
 
 func (m *containerSyncdeleterMaker) newSyncdeleter(ctx context.Context, c *v1alpha3.Container, poll time.Duration) (syncdeleter, error) { // nolint:gocyclo
@@ -138,6 +733,9 @@ func (m *containerSyncdeleterMaker) newSyncdeleter(ctx context.Context, c *v1alp
 	default:
 		return nil, errors.New("neither providerConfigRef nor providerRef is given")
 	}
+	if err := storage.ValidateContainerName(externalName(c)); err != nil {
+		return nil, err
+	}
 	// Storage containers use a storage account as their 'provider', not a
 	// typical Azure provider.
 	acct := &v1alpha3.Account{}
@@ -145,9 +743,8 @@ func (m *containerSyncdeleterMaker) newSyncdeleter(ctx context.Context, c *v1alp
 		// For storage account not found errors - check if we are on deletion path
 		// if so - remove finalizer from this container object
 		if kerrors.IsNotFound(err) && c.DeletionTimestamp != nil {
-			meta.RemoveFinalizer(c, finalizer)
-			if err := m.Client.Update(ctx, c); err != nil {
-				return nil, errors.Wrapf(err, "failed to update after removing finalizer")
+			if err := m.removeFinalizer(ctx, c); err != nil {
+				return nil, err
 			}
 		}
 		return nil, errors.Wrapf(err, "failed to retrieve storage account: %s", nn.Name)
@@ -164,17 +761,48 @@ func (m *containerSyncdeleterMaker) newSyncdeleter(ctx context.Context, c *v1alp
 		Name:      acct.Spec.WriteConnectionSecretToReference.Name,
 	}
 	if err := m.Get(ctx, n, s); err != nil {
+		// The secret of a storage account that is being deleted may be
+		// deleted before the account, in which case so are its containers.
+		if kerrors.IsNotFound(err) && c.DeletionTimestamp != nil && meta.WasDeleted(acct) {
+			if err := m.removeFinalizer(ctx, c); err != nil {
+				return nil, err
+			}
+		}
 		return nil, errors.Wrapf(err, "failed to retrieve storage account secret: %s", n)
 	}
 
 	accountName := string(s.Data[xpv1.ResourceCredentialsSecretUserKey])
 	accountPassword := string(s.Data[xpv1.ResourceCredentialsSecretPasswordKey])
-	containerName := meta.GetExternalName(c)
+	accountSAS := string(s.Data[AccountSASKey])
+	containerName := externalName(c)
+
+	pc, err := providerConfig(ctx, m.Client, acct)
+	if err != nil {
+		return nil, err
+	}
+	cloud, err := azure.CloudEnvironment(pc.Cloud)
+	if err != nil {
+		return nil, err
+	}
 
-	ch, err := storage.NewContainerHandle(accountName, accountPassword, containerName)
+	sh, err := m.serviceHandle(ctx, c, acct, accountName, accountPassword, accountSAS, storage.ContainerHandleOptions{
+		MaxConcurrentRequests: pc.MaxConcurrentStorageRequests,
+		APIVersion:            pc.BlobServiceAPIVersion,
+		Retry:                 storageRetryOptions(pc),
+		FallbackEndpoint:      strings.ReplaceAll(pc.BlobFallbackEndpoint, "%s", accountName),
+		EndpointSuffix:        cloud.StorageEndpointSuffix,
+	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to create client handle: %s, storage account: %s", containerName, accountName)
 	}
+	ch := sh.Container(containerName)
+
+	ch.DefaultEncryptionScope = c.Spec.DefaultEncryptionScope
+	ch.PreventEncryptionScopeOverride = c.Spec.PreventEncryptionScopeOverride
+	ch.StoredAccessPolicies = storedAccessPolicies(c.Spec.ContainerParameters)
+	ch.DeleteSnapshots = c.Spec.DeleteSnapshots
+	ops := storage.NewMetadataEncryptingContainerOperations(ch, m.cipher, m.sensitivePrefix)
+	ops = storage.NewTracingContainerOperations(ops, m.tracer, accountName, containerName)
 
 	// set owner reference on the container to storage account, thus
 	// if the account is delete - container is garbage collected as well
@@ -182,19 +810,74 @@ func (m *containerSyncdeleterMaker) newSyncdeleter(ctx context.Context, c *v1alp
 	or.BlockOwnerDeletion = to.BoolPtr(true)
 	meta.AddOwnerReference(c, or)
 
+	management := newManagementConnector(m.Client, acct)
 	return &containerSyncdeleter{
 		createupdater: &containerCreateUpdater{
-			ContainerOperations: ch,
+			ContainerOperations: ops,
 			kube:                m.Client,
 			container:           c,
+			account:             acct,
+			management:          management,
 			poll:                poll,
+			jitter:              m.jitter,
+			observeOnly:         m.observeOnly,
+			audit:               m.audit,
+			defaultMetadata:     pc.ContainerDefaultMetadata,
+			allowedMetadataKeys: pc.ContainerAllowedMetadataKeys,
+			allowedLocations:    pc.ContainerAllowedLocations,
+			maxValueLength:      m.maxValueLength,
+			remediation:         m.remediation,
+			sanitizeKeys:        m.sanitizeKeys,
+			managedKeys:         m.managedKeys,
+			ownerKey:            m.ownerKey,
+			owner:               m.owner,
+			accountAccess:       m.accountAccess,
+			transitions:         m.transitions,
+			conditions:          m.conditions,
+			sas:                 newSASIssuer(accountName, accountPassword, ch.URL()),
 		},
-		ContainerOperations: ch,
+		ContainerOperations: ops,
 		kube:                m.Client,
 		container:           c,
+		observeOnly:         m.observeOnly,
+		conditions:          m.conditions,
+		deletion:            deletionBackoff,
+		environment:         pc.Environment,
+		maxBackoff:          maxReconcileBackoff(pc),
+		account:             acct,
+		management:          management,
+		restoreDeleted:      m.restoreDeleted,
+		ownerKey:            m.ownerKey,
+		owner:               m.owner,
+		snapshots:           m.snapshots,
+		transitions:         m.transitions,
+		poll:                poll,
+		jitter:              m.jitter,
 	}, nil
 }
 
+// newManagementConnector returns a function that connects to the management
+// plane of the supplied storage account. Connecting requires the account's
+// Azure credentials, so it is deferred until management operations are needed.
+// The function connects at most once and the account is read at most once
+// through the returned operations, so they should be built for each reconcile.
+func newManagementConnector(kube client.Client, acct *v1alpha3.Account) func(context.Context) (storage.ManagementOperations, error) {
+	var m storage.ManagementOperations
+	return func(ctx context.Context) (storage.ManagementOperations, error) {
+		if m != nil {
+			return m, nil
+		}
+		creds, auth, err := azure.GetAuthInfo(ctx, kube, acct)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetAuthInfo)
+		}
+		name := meta.GetExternalName(acct)
+		h := storage.NewManagementHandleWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID], auth, acct.Spec.ResourceGroupName, name)
+		m = storage.NewAccountCachingManagementOperations(h, name)
+		return m, nil
+	}
+}
+
 type deleter interface {
 	delete(context.Context) (reconcile.Result, error)
 }
@@ -214,6 +897,10 @@ type updater interface {
 type syncdeleter interface {
 	deleter
 	syncer
+
+	// requeueCeiling is the longest the container may wait to be retried
+	// while reconciling it keeps failing. Zero leaves it unbounded.
+	requeueCeiling() time.Duration
 }
 
 type containerSyncdeleter struct {
@@ -221,15 +908,135 @@ type containerSyncdeleter struct {
 	storage.ContainerOperations
 	kube      client.Client
 	container *v1alpha3.Container
+
+	// observeOnly containers are never deleted from Azure, regardless of
+	// their deletion policy.
+	observeOnly bool
+
+	// deletion bounds how long we poll for a deleted container to stop
+	// existing.
+	deletion wait.Backoff
+
+	// conditions map classes of storage error to the Synced conditions that
+	// report them.
+	conditions ErrorConditions
+
+	// environment of the storage account's provider config, which limits the
+	// container's public access.
+	environment v1beta1.Environment
+
+	// maxBackoff is the longest the container waits to be retried while
+	// reconciling it keeps failing.
+	maxBackoff time.Duration
+
+	// account is the container's storage account, whose management plane is
+	// consulted when its blob endpoint stops resolving.
+	account    *v1alpha3.Account
+	management func(context.Context) (storage.ManagementOperations, error)
+
+	// restoreDeleted requests that the storage account is restored when it
+	// is found to be soft-deleted.
+	restoreDeleted bool
+
+	// ownerKey and owner identify the containers that may be deleted.
+	ownerKey string
+	owner    string
+
+	// snapshots records the container's configuration before it is
+	// deleted. Nothing is recorded when it is nil.
+	snapshots *configSnapshotter
+
+	// transitions records the container's deletion. Nothing is recorded
+	// when it is nil.
+	transitions *transitionRecorder
+
+	// poll is how long a container waits for its soft-deleted storage
+	// account to be restored, randomized by jitter.
+	poll   time.Duration
+	jitter float64
+}
+
+func (csd *containerSyncdeleter) requeueCeiling() time.Duration {
+	return csd.maxBackoff
 }
 
 func (csd *containerSyncdeleter) delete(ctx context.Context) (reconcile.Result, error) {
+	// Protected containers keep our finalizer, so they can't be deleted
+	// until the protection is removed.
+	csd.container.Status.AtProvider.DeletionProtected = csd.container.Spec.DeletionProtection
+	if csd.container.Spec.DeletionProtection {
+		err := errors.Errorf(errDeletionProtected, externalName(csd.container))
+		csd.conditions.setReconcileError(csd.container, err)
+		return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+	}
+
 	csd.container.Status.SetConditions(xpv1.Deleting())
-	if csd.container.Spec.DeletionPolicy == xpv1.DeletionDelete {
-		if err := csd.Delete(ctx); err != nil && !azure.IsNotFound(err) {
-			csd.container.Status.SetConditions(xpv1.ReconcileError(err))
+	if csd.container.Spec.DeletionPolicy == xpv1.DeletionDelete && !csd.observeOnly {
+		if err := csd.checkOwner(ctx); err != nil {
+			if csd.accountDeleted(ctx, err) {
+				return csd.deleted(ctx)
+			}
+			csd.conditions.setReconcileError(csd.container, err)
+			return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+		}
+		if err := csd.snapshots.snapshot(ctx, csd.container, csd.ContainerOperations); err != nil {
+			if csd.accountDeleted(ctx, err) {
+				return csd.deleted(ctx)
+			}
+			csd.conditions.setReconcileError(csd.container, err)
+			return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+		}
+		if err := csd.archive(ctx); err != nil {
+			if csd.accountDeleted(ctx, err) {
+				return csd.deleted(ctx)
+			}
+			csd.conditions.setReconcileError(csd.container, err)
+			return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+		}
+
+		err := csd.deleteContainer(ctx)
+		if storage.IsSnapshotsPresent(err) {
+			err = csd.deleteWithSnapshots(ctx)
+		}
+		if err != nil && !azure.IsNotFound(err) && csd.accountDeleted(ctx, err) {
+			recordOperation(csd.container, v1alpha3.ContainerOperationDelete, nil)
+			return csd.deleted(ctx)
+		}
+		recordOperation(csd.container, v1alpha3.ContainerOperationDelete, resource.Ignore(azure.IsNotFound, err))
+		if err != nil && !azure.IsNotFound(err) {
+			csd.conditions.setReconcileError(csd.container, err)
 			return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
 		}
+
+		// Azure may report a deleted container for a short while, so we
+		// don't remove our finalizer until it is gone. If it lingers beyond
+		// our deadline we requeue and delete it again.
+		if err == nil {
+			err = wait.ExponentialBackoffWithContext(ctx, csd.deletion, func() (bool, error) {
+				exists, err := csd.Exists(ctx)
+				return !exists, err
+			})
+			if errors.Is(err, wait.ErrWaitTimeout) {
+				return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+			}
+			if err != nil {
+				csd.conditions.setReconcileError(csd.container, errors.Wrap(err, errVerifyDeletion))
+				return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+			}
+		}
+		csd.transitions.deleted(csd.container, csd.account)
+	}
+
+	return csd.deleted(ctx)
+}
+
+// deleted completes the deletion of a container that no longer exists in
+// Azure, or that was kept there, by deleting its connection secret and
+// removing our finalizer.
+func (csd *containerSyncdeleter) deleted(ctx context.Context) (reconcile.Result, error) {
+	if err := csd.deleteConnectionSecret(ctx); err != nil {
+		csd.conditions.setReconcileError(csd.container, err)
+		return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
 	}
 
 	// NOTE(negz): We don't update the conditioned status here because assuming
@@ -239,65 +1046,1327 @@ func (csd *containerSyncdeleter) delete(ctx context.Context) (reconcile.Result,
 	return reconcile.Result{}, csd.kube.Update(ctx, csd.container)
 }
 
-func (csd *containerSyncdeleter) sync(ctx context.Context) (reconcile.Result, error) {
-	access, meta, err := csd.Get(ctx)
-	if err != nil && !storage.IsNotFoundError(err) {
-		csd.container.Status.SetConditions(xpv1.ReconcileError(err))
-		return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+// accountDeleted returns true if the supplied error, returned while deleting
+// the container, was caused by its storage account having been deleted, in
+// which case the container was deleted with it. Only the management plane can
+// tell that the account no longer exists; it is asked when the account is
+// being deleted, or when its blob endpoint no longer resolves, which may also
+// be a transient DNS failure.
+func (csd *containerSyncdeleter) accountDeleted(ctx context.Context, err error) bool {
+	if err == nil || csd.management == nil {
+		return false
 	}
-
-	if access == nil {
-		return csd.create(ctx)
+	if !storage.IsAccountUnresolvable(err) && (csd.account == nil || !meta.WasDeleted(csd.account)) {
+		return false
 	}
-
-	return csd.update(ctx, access, meta)
+	m, err := csd.management(ctx)
+	if err != nil {
+		return false
+	}
+	_, err = m.GetAccount(ctx)
+	return azure.IsNotFound(err)
 }
 
-type createupdater interface {
-	creator
-	updater
+// archive copies the container's blobs to its archive container, if it has
+// one, before it is deleted. Containers that are already gone have nothing to
+// archive.
+func (csd *containerSyncdeleter) archive(ctx context.Context) error {
+	a := csd.container.Spec.ArchiveContainer
+	if a == "" {
+		return nil
+	}
+	if err := csd.ArchiveBlobs(ctx, a); err != nil && !storage.IsNotFoundError(err) {
+		return errors.Wrapf(err, errArchive, a)
+	}
+	return nil
 }
 
-// containerCreateUpdater implementation of createupdater interface
-type containerCreateUpdater struct {
-	storage.ContainerOperations
-	kube      client.Client
-	container *v1alpha3.Container
-	poll      time.Duration
+// deleteConnectionSecret deletes the Secret the container writes its
+// connection details to, so that stale SAS tokens don't accumulate, unless the
+// container retains it. Secrets that are already gone, and Secrets controlled
+// by another resource, are left alone.
+func (csd *containerSyncdeleter) deleteConnectionSecret(ctx context.Context) error {
+	ref := csd.container.GetWriteConnectionSecretToReference()
+	if ref == nil || csd.container.Spec.RetainConnectionSecret {
+		return nil
+	}
+	s := &corev1.Secret{}
+	if err := csd.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return errors.Wrap(resource.IgnoreNotFound(err), errGetSecret)
+	}
+	if c := metav1.GetControllerOf(s); c != nil && c.UID != csd.container.GetUID() {
+		return nil
+	}
+	return errors.Wrap(resource.IgnoreNotFound(csd.kube.Delete(ctx, s)), errDeleteSecret)
 }
 
-var _ createupdater = &containerCreateUpdater{}
-
-func (ccu *containerCreateUpdater) create(ctx context.Context) (reconcile.Result, error) {
-	container := ccu.container
-	container.Status.SetConditions(xpv1.Creating())
-
-	meta.AddFinalizer(container, finalizer)
-	if err := ccu.kube.Update(ctx, container); err != nil {
-		return resultRequeue, errors.Wrapf(err, "failed to update container spec")
+// checkOwner returns an error if the container's metadata claims it for an
+// owner other than ours. Containers that no longer exist are not claimed.
+func (csd *containerSyncdeleter) checkOwner(ctx context.Context) error {
+	if csd.ownerKey == "" {
+		return nil
 	}
+	_, md, err := csd.Get(ctx)
+	if storage.IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return checkOwner(externalName(csd.container), md, csd.ownerKey, csd.owner)
+}
 
-	spec := container.Spec
-	if err := ccu.Create(ctx, spec.PublicAccessType, spec.Metadata); err != nil {
-		container.Status.SetConditions(xpv1.ReconcileError(err))
-		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+// deleteContainer deletes the container. Containers that ask for their blob
+// versions to be purged have their blobs and blob versions deleted first, but
+// only if the storage account has versioning enabled.
+func (csd *containerSyncdeleter) deleteContainer(ctx context.Context) error {
+	if !csd.container.Spec.PurgeVersionsOnDelete || csd.management == nil {
+		return csd.Delete(ctx)
+	}
+	m, err := csd.management(ctx)
+	if err != nil {
+		return err
+	}
+	versioned, err := m.GetBlobVersioning(ctx)
+	if err != nil {
+		return err
+	}
+	if !versioned {
+		return csd.Delete(ctx)
 	}
+	return csd.DeleteWithVersions(ctx)
+}
 
-	container.Status.SetConditions(xpv1.Available(), xpv1.ReconcileSuccess())
-	return reconcile.Result{}, ccu.kube.Status().Update(ctx, ccu.container)
+// deleteWithSnapshots deletes a container whose deletion was blocked by blob
+// snapshots. The snapshotted blobs are deleted first if the container permits
+// it; otherwise an error naming them is returned.
+func (csd *containerSyncdeleter) deleteWithSnapshots(ctx context.Context) error {
+	snaps, err := csd.ListSnapshots(ctx, "")
+	if err != nil {
+		return err
+	}
+	if !csd.container.Spec.DeleteSnapshots {
+		return &storage.SnapshotsBlockDeletionError{Container: externalName(csd.container), Snapshots: snaps}
+	}
+	if err := csd.DeleteBlobsWithSnapshots(ctx, snaps); err != nil {
+		return err
+	}
+	return csd.Delete(ctx)
 }
 
-func (ccu *containerCreateUpdater) update(ctx context.Context, accessType *azblob.PublicAccessType, meta azblob.Metadata) (reconcile.Result, error) {
-	container := ccu.container
-	spec := container.Spec
+func (csd *containerSyncdeleter) sync(ctx context.Context) (reconcile.Result, error) {
+	if res, done, err := csd.forceRecreate(ctx); done {
+		return res, err
+	}
 
-	if !reflect.DeepEqual(*accessType, spec.PublicAccessType) || !reflect.DeepEqual(meta, spec.Metadata) {
-		if err := ccu.Update(ctx, spec.PublicAccessType, spec.Metadata); err != nil {
-			container.Status.SetConditions(xpv1.ReconcileError(err))
-			return resultRequeue, ccu.kube.Status().Update(ctx, container)
-		}
+	access, meta, err := csd.Get(ctx)
+	if storage.IsAccountUnresolvable(err) {
+		return csd.accountGone(ctx, err)
+	}
+	if err != nil && !storage.IsNotFoundError(err) {
+		csd.conditions.setReconcileError(csd.container, err)
+		return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
 	}
 
-	container.Status.SetConditions(xpv1.Available(), xpv1.ReconcileSuccess())
-	return reconcile.Result{RequeueAfter: ccu.poll}, ccu.kube.Status().Update(ctx, ccu.container)
+	if err := checkPublicAccess(csd.container, csd.environment); err != nil {
+		csd.conditions.setReconcileError(csd.container, err)
+		return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+	}
+
+	if access == nil {
+		return csd.create(ctx)
+	}
+
+	if res, done, err := csd.awaitStable(ctx); done {
+		return res, err
+	}
+
+	if needsImport(csd.container) {
+		return csd.importExisting(ctx)
+	}
+
+	if err := checkAdoption(csd.container); err != nil {
+		csd.conditions.setReconcileError(csd.container, err)
+		return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+	}
+
+	return csd.update(ctx, access, meta)
+}
+
+// accountGone handles a storage account whose blob endpoint no longer
+// resolves. If the account was soft-deleted the container reports that the
+// account must be restored, and waits for the poll interval rather than
+// retrying a reconcile that cannot succeed. A restore of the account is
+// requested first if restoring soft-deleted accounts is enabled. Accounts that
+// are not soft-deleted report the supplied error as usual.
+func (csd *containerSyncdeleter) accountGone(ctx context.Context, cause error) (reconcile.Result, error) {
+	c := csd.container
+	m, location, deleted := csd.softDeleted(ctx)
+	if !deleted {
+		csd.conditions.setReconcileError(c, cause)
+		return resultRequeue, csd.kube.Status().Update(ctx, c)
+	}
+
+	sde := &storage.AccountSoftDeletedError{Account: meta.GetExternalName(csd.account)}
+	if csd.restoreDeleted {
+		if err := m.RestoreDeletedAccount(ctx, location); err != nil {
+			csd.conditions.setReconcileError(c, errors.Wrap(err, errRestoreAccount))
+			return resultRequeue, csd.kube.Status().Update(ctx, c)
+		}
+		sde.Restoring = true
+	}
+	c.Status.SetConditions(xpv1.Unavailable())
+	csd.conditions.setReconcileError(c, sde)
+	return reconcile.Result{RequeueAfter: jittered(csd.poll, csd.jitter)}, csd.kube.Status().Update(ctx, c)
+}
+
+// softDeleted returns true, along with the management operations and location
+// of the storage account, if the account is soft-deleted. Failures to find out
+// are treated as the account not being soft-deleted.
+func (csd *containerSyncdeleter) softDeleted(ctx context.Context) (storage.ManagementOperations, string, bool) {
+	if csd.management == nil || csd.account == nil || csd.account.Spec.StorageAccountSpec == nil {
+		return nil, "", false
+	}
+	location := csd.account.Spec.StorageAccountSpec.Location
+	m, err := csd.management(ctx)
+	if err != nil {
+		return nil, "", false
+	}
+	d, err := m.GetDeletedAccount(ctx, location)
+	if err != nil || d == nil {
+		return nil, "", false
+	}
+	return m, location, true
+}
+
+// awaitStable defers adopting or importing an existing container until it has
+// gone unmodified for the container's adoption grace period, so that we don't
+// race another controller that still manages it. Once it is stable an adopted
+// container is marked as managed by adding our finalizer; imported ones are
+// marked when they are imported. It returns true if the reconcile should end
+// with the returned result and error.
+func (csd *containerSyncdeleter) awaitStable(ctx context.Context) (reconcile.Result, bool, error) {
+	c := csd.container
+	grace := c.Spec.AdoptionGracePeriod
+	policy := adoptionPolicy(c)
+	if grace == nil || csd.observeOnly || meta.FinalizerExists(c, finalizer) || (policy != v1alpha3.AdoptIfExists && policy != v1alpha3.Import) {
+		return reconcile.Result{}, false, nil
+	}
+
+	p, err := csd.GetContainerProperties(ctx)
+	if err != nil {
+		csd.conditions.setReconcileError(c, errors.Wrap(err, errGetProperties))
+		return resultRequeue, true, csd.kube.Status().Update(ctx, c)
+	}
+	if wait := grace.Duration - time.Since(p.LastModified); wait > 0 {
+		c.Status.SetConditions(adoptionPending(p.LastModified, grace.Duration))
+		return reconcile.Result{RequeueAfter: wait}, true, csd.kube.Status().Update(ctx, c)
+	}
+
+	if policy == v1alpha3.AdoptIfExists {
+		meta.AddFinalizer(c, finalizer)
+		if err := csd.kube.Update(ctx, c); err != nil {
+			return resultRequeue, true, errors.Wrap(err, errUpdateAdopted)
+		}
+	}
+	return reconcile.Result{}, false, nil
+}
+
+// adoptionPending returns a condition that indicates adopting a container that
+// was last modified at the supplied time is deferred until it has gone
+// unmodified for the supplied grace period.
+func adoptionPending(modified time.Time, grace time.Duration) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeSynced,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAdoptionPending,
+		Message:            fmt.Sprintf("existing container was modified at %s; it will be adopted once it has not been modified for %s", modified.UTC().Format(time.RFC3339), grace),
+	}
+}
+
+// needsImport returns true if the supplied container imports an existing
+// container that it has not yet imported. Crossplane adds its finalizer when a
+// container is imported.
+func needsImport(c *v1alpha3.Container) bool {
+	return adoptionPolicy(c) == v1alpha3.Import && !meta.FinalizerExists(c, finalizer)
+}
+
+// importExisting backfills the container's spec from the existing container
+// and marks it as managed by adding our finalizer. It never changes the
+// existing container. Drift from the backfilled spec is corrected when the
+// container is next reconciled.
+func (csd *containerSyncdeleter) importExisting(ctx context.Context) (reconcile.Result, error) {
+	s, err := csd.Import(ctx)
+	if err != nil {
+		csd.conditions.setReconcileError(csd.container, errors.Wrap(err, errImport))
+		return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+	}
+
+	lateInitialize(&csd.container.Spec.ContainerParameters, s)
+	meta.AddFinalizer(csd.container, finalizer)
+	if err := csd.kube.Update(ctx, csd.container); err != nil {
+		return resultRequeue, errors.Wrap(err, errUpdateImported)
+	}
+
+	csd.container.Status.AtProvider.ETag = string(s.ETag)
+	clearThrottled(csd.container)
+	csd.container.Status.SetConditions(xpv1.Available(), xpv1.ReconcileSuccess())
+	return resultRequeue, csd.kube.Status().Update(ctx, csd.container)
+}
+
+// lateInitialize sets each unset field of the supplied parameters to the value
+// observed in the supplied snapshot. Metadata is only set when neither it nor
+// a metadata ConfigMap is specified. Imported metadata values are escaped so
+// that they are not mistaken for template variables.
+func lateInitialize(p *v1alpha3.ContainerParameters, s storage.ContainerSnapshot) {
+	if p.PublicAccessType == "" {
+		p.PublicAccessType = s.PublicAccessType
+	}
+	if p.Metadata == nil && p.MetadataFrom == nil && len(s.Metadata) > 0 {
+		p.Metadata = escapeMetadata(s.Metadata)
+	}
+	if p.DefaultEncryptionScope == "" && !storage.IsAccountEncryptionScope(s.DefaultEncryptionScope) {
+		p.DefaultEncryptionScope = s.DefaultEncryptionScope
+	}
+	if !p.PreventEncryptionScopeOverride {
+		p.PreventEncryptionScopeOverride = s.PreventEncryptionScopeOverride
+	}
+}
+
+// checkAdoption returns an AlreadyExistsError if the supplied container's
+// adoption policy forbids it from managing a container that exists in Azure
+// but was not created by Crossplane. Crossplane adds its finalizer before it
+// creates a container, so containers without it were created elsewhere.
+func checkAdoption(c *v1alpha3.Container) error {
+	if adoptionPolicy(c) == v1alpha3.AdoptIfExists || meta.FinalizerExists(c, finalizer) {
+		return nil
+	}
+	return &storage.AlreadyExistsError{Container: externalName(c)}
+}
+
+// environmentMaxPublicAccess is the most public access type permitted to
+// containers in each environment. Containers in other environments may use any
+// public access type.
+var environmentMaxPublicAccess = map[v1beta1.Environment]azblob.PublicAccessType{
+	v1beta1.EnvironmentProduction:  azblob.PublicAccessNone,
+	v1beta1.EnvironmentDevelopment: azblob.PublicAccessContainer,
+}
+
+// publicAccessRank orders public access types from least to most public.
+var publicAccessRank = map[azblob.PublicAccessType]int{
+	azblob.PublicAccessNone:      0,
+	azblob.PublicAccessBlob:      1,
+	azblob.PublicAccessContainer: 2,
+}
+
+// maxPublicAccess returns the most public access type permitted to containers
+// in the supplied environment.
+func maxPublicAccess(env v1beta1.Environment) azblob.PublicAccessType {
+	if max, ok := environmentMaxPublicAccess[env]; ok {
+		return max
+	}
+	return azblob.PublicAccessContainer
+}
+
+// checkPublicAccess returns an error if the supplied container's public access
+// type is more public than its environment permits.
+func checkPublicAccess(c *v1alpha3.Container, env v1beta1.Environment) error {
+	max := maxPublicAccess(env)
+	if max == azblob.PublicAccessContainer {
+		return nil
+	}
+	access, _ := canonicalize(c.Spec.PublicAccessType, nil)
+	if publicAccessRank[access] <= publicAccessRank[max] {
+		return nil
+	}
+	return errors.Errorf(errPublicAccessDenied, publicAccessName(access), env, publicAccessName(max))
+}
+
+// checkMetadataKeys returns an error naming the first, in sorted order, of the
+// supplied metadata keys that is not allowed. Keys are matched ignoring case.
+// Any key is allowed when there are no allowed keys.
+func checkMetadataKeys(md map[string]string, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	ok := make(map[string]bool, len(allowed))
+	for _, k := range allowed {
+		ok[strings.ToLower(k)] = true
+	}
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !ok[strings.ToLower(k)] {
+			return errors.Errorf(errMetadataKeyDenied, k, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// checkMetadataValueLengths returns an error naming the first, in sorted order,
+// of the supplied metadata keys whose value is longer than the supplied limit
+// in bytes. Values are not limited when it is zero.
+func checkMetadataValueLengths(md map[string]string, max int) error {
+	if max <= 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if l := len(md[k]); l > max {
+			return errors.Errorf(errMetadataValueTooLong, k, l, max)
+		}
+	}
+	return nil
+}
+
+// publicAccessName returns a printable name for the supplied public access
+// type, which is empty when there is no public access.
+func publicAccessName(t azblob.PublicAccessType) string {
+	if t == azblob.PublicAccessNone {
+		return "None"
+	}
+	return fmt.Sprintf("%q", t)
+}
+
+// providerConfig returns the spec of the supplied storage account's provider
+// config. Accounts that don't use a provider config have an empty one, and so
+// no environment.
+func providerConfig(ctx context.Context, kube client.Client, acct *v1alpha3.Account) (v1beta1.ProviderConfigSpec, error) {
+	ref := acct.GetProviderConfigReference()
+	if ref == nil || ref.Name == "" {
+		return v1beta1.ProviderConfigSpec{}, nil
+	}
+	pc := &v1beta1.ProviderConfig{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+		return v1beta1.ProviderConfigSpec{}, errors.Wrapf(err, errGetPC, ref.Name)
+	}
+	return pc.Spec, nil
+}
+
+// externalName returns the name of the supplied container in Azure, which is
+// its external name, or its metadata name if it has none. External names need
+// only satisfy Azure's naming rules, not Kubernetes's.
+func externalName(c *v1alpha3.Container) string {
+	if n := meta.GetExternalName(c); n != "" {
+		return n
+	}
+	return c.GetName()
+}
+
+// maxReconcileBackoff returns the longest that containers that use the
+// supplied provider config wait to be retried while reconciling them keeps
+// failing, or zero if the provider config does not bound it.
+func maxReconcileBackoff(pc v1beta1.ProviderConfigSpec) time.Duration {
+	if pc.MaxReconcileBackoff == nil {
+		return 0
+	}
+	return pc.MaxReconcileBackoff.Duration
+}
+
+// storageRetryOptions returns the options with which containers that use the
+// supplied provider config retry failed blob service requests. Unset options
+// are left zero, so that the azblob SDK's defaults apply.
+func storageRetryOptions(pc v1beta1.ProviderConfigSpec) azblob.RetryOptions {
+	r := pc.StorageRetry
+	if r == nil {
+		return azblob.RetryOptions{}
+	}
+	o := azblob.RetryOptions{Policy: azblob.RetryPolicyExponential, MaxTries: r.MaxTries}
+	if r.Policy == v1beta1.StorageRetryFixed {
+		o.Policy = azblob.RetryPolicyFixed
+	}
+	if r.TryTimeout != nil {
+		o.TryTimeout = r.TryTimeout.Duration
+	}
+	if r.RetryDelay != nil {
+		o.RetryDelay = r.RetryDelay.Duration
+	}
+	if r.MaxRetryDelay != nil {
+		o.MaxRetryDelay = r.MaxRetryDelay.Duration
+	}
+	return o
+}
+
+// adoptionPolicy returns the adoption policy of the supplied container.
+func adoptionPolicy(c *v1alpha3.Container) v1alpha3.AdoptionPolicy {
+	if c.Spec.AdoptionPolicy == "" {
+		return v1alpha3.AdoptIfExists
+	}
+	return c.Spec.AdoptionPolicy
+}
+
+// removeFinalizer removes our finalizer from the supplied container, whose
+// storage account was deleted with it.
+func (m *containerSyncdeleterMaker) removeFinalizer(ctx context.Context, c *v1alpha3.Container) error {
+	meta.RemoveFinalizer(c, finalizer)
+	return errors.Wrap(m.Client.Update(ctx, c), "failed to update after removing finalizer")
+}
+
+type createupdater interface {
+	creator
+	updater
+}
+
+// containerCreateUpdater implementation of createupdater interface
+type containerCreateUpdater struct {
+	storage.ContainerOperations
+	kube       client.Client
+	container  *v1alpha3.Container
+	account    *v1alpha3.Account
+	management func(context.Context) (storage.ManagementOperations, error)
+	poll       time.Duration
+
+	// jitter is the fraction of poll by which requeues are randomized.
+	jitter float64
+
+	// audit reports containers that permit anonymous public access.
+	audit *publicAccessAuditor
+
+	// defaultMetadata is merged into the metadata of the container, which
+	// takes precedence over it.
+	defaultMetadata map[string]string
+
+	// allowedMetadataKeys are the only metadata keys the container may set.
+	// Any key is allowed when it is empty.
+	allowedMetadataKeys []string
+
+	// allowedLocations are the only locations the container's storage
+	// account may be in. Any location is allowed when it is empty.
+	allowedLocations []string
+
+	// maxValueLength limits the length of each metadata value. Values are
+	// not limited when it is zero.
+	maxValueLength int
+
+	// remediation delays correcting drift of the container's public access
+	// type. Drift is corrected immediately when it is nil.
+	remediation *publicAccessRemediator
+
+	// sanitizeKeys retries writing metadata that Azure rejected with its
+	// keys sanitized.
+	sanitizeKeys bool
+
+	// managedKeys are metadata keys managed by the controller or platform,
+	// whose drift is reported separately from drift from the spec.
+	managedKeys []string
+
+	// ownerKey is the metadata key that claims a container for an owner,
+	// and owner the value of it that claims it for us. Containers claimed
+	// for other owners are not changed.
+	ownerKey string
+	owner    string
+
+	// accountAccess caches whether the storage account allows blob public
+	// access. Containers that ask for public access in accounts that
+	// disallow it are not written. It is not checked when it is nil.
+	accountAccess *storage.AccountPublicAccessCache
+
+	// transitions records the container's state transitions. Nothing is
+	// recorded when it is nil.
+	transitions *transitionRecorder
+
+	// conditions map classes of storage error to the Synced conditions that
+	// report them.
+	conditions ErrorConditions
+
+	// observeOnly containers are never created or updated. Any drift from
+	// their desired state is reported in their Synced condition instead.
+	observeOnly bool
+
+	// sas issues the shared access signatures written to the container's
+	// connection secret. None are issued when it is nil.
+	sas *sasIssuer
+}
+
+var _ createupdater = &containerCreateUpdater{}
+
+func (ccu *containerCreateUpdater) create(ctx context.Context) (reconcile.Result, error) {
+	container := ccu.container
+	if ccu.observeOnly {
+		drift := []string{"container does not exist"}
+		ccu.transitions.driftDetected(container, ccu.account, drift)
+		container.Status.SetConditions(xpv1.Unavailable(), driftDetected(drift))
+		return reconcile.Result{RequeueAfter: jittered(ccu.poll, ccu.jitter)}, ccu.kube.Status().Update(ctx, container)
+	}
+
+	// The container did not exist when we last looked, but make sure it was
+	// not created since if we must not adopt it.
+	if p := adoptionPolicy(container); p == v1alpha3.FailIfExists || p == v1alpha3.ManageExclusively {
+		exists, err := ccu.Exists(ctx)
+		if err == nil && exists {
+			err = &storage.AlreadyExistsError{Container: externalName(container)}
+		}
+		if err != nil {
+			ccu.conditions.setReconcileError(container, err)
+			return resultRequeue, ccu.kube.Status().Update(ctx, container)
+		}
+	}
+
+	container.Status.SetConditions(xpv1.Creating())
+
+	meta.AddFinalizer(container, finalizer)
+	if err := ccu.kube.Update(ctx, container); err != nil {
+		return resultRequeue, errors.Wrapf(err, "failed to update container spec")
+	}
+
+	spec, err := ccu.desired(ctx)
+	if err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	if err := ccu.checkLocation(ctx); err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	if err := ccu.checkAccountPublicAccess(ctx, spec); err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	if err := ccu.accountProvisioned(ctx); err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	if err := ccu.ensureEncryptionScope(ctx, spec); err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	err = ccu.createContainer(ctx, spec)
+	recordOperation(container, v1alpha3.ContainerOperationCreate, err)
+	if err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+
+	// Azure may briefly report that a newly created container does not exist,
+	// in which case an update made before it is visible would fail.
+	if err := ccu.WaitUntilExists(ctx, visibleTimeout); err != nil {
+		ccu.conditions.setReconcileError(container, errors.Wrap(err, errAwaitVisible))
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	if err := ccu.setAccessPolicies(ctx, spec); err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	_, immutability, m, err := ccu.immutability(ctx, spec)
+	if err == nil {
+		err = ccu.updateImmutability(ctx, m, spec, immutability)
+	}
+	if err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+
+	ccu.transitions.created(container, ccu.account)
+	container.Status.ObservedGeneration = container.Generation
+	clearThrottled(container)
+	container.Status.SetConditions(xpv1.Available(), xpv1.ReconcileSuccess())
+	return reconcile.Result{}, ccu.kube.Status().Update(ctx, ccu.container)
+}
+
+// createContainer creates the container in Azure. A container that was
+// created concurrently, for example by a racing reconcile, is updated to the
+// desired state rather than failing the create, unless the container's
+// adoption policy forbids managing containers that Crossplane did not create,
+// or its metadata claims it for another owner.
+func (ccu *containerCreateUpdater) createContainer(ctx context.Context, spec v1alpha3.ContainerParameters) error {
+	err := ccu.Create(ctx, spec.PublicAccessType, spec.Metadata)
+	if !storage.IsContainerAlreadyExists(err) {
+		return err
+	}
+	if p := adoptionPolicy(ccu.container); p == v1alpha3.FailIfExists || p == v1alpha3.ManageExclusively {
+		return &storage.AlreadyExistsError{Container: externalName(ccu.container)}
+	}
+	if ccu.ownerKey != "" {
+		_, md, err := ccu.Get(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkOwner(externalName(ccu.container), md, ccu.ownerKey, ccu.owner); err != nil {
+			return err
+		}
+	}
+	return ccu.Update(ctx, spec.PublicAccessType, spec.Metadata)
+}
+
+// accountProvisioned returns an error unless the storage account has finished
+// provisioning. Containers created before then fail with confusing errors.
+func (ccu *containerCreateUpdater) accountProvisioned(ctx context.Context) error {
+	m, err := ccu.management(ctx)
+	if err != nil {
+		return err
+	}
+	a, err := m.GetAccount(ctx)
+	if err != nil {
+		return errors.Wrap(err, errGetAccount)
+	}
+	state := mgmtstorage.ProvisioningState("")
+	if a.AccountProperties != nil {
+		state = a.AccountProperties.ProvisioningState
+	}
+	if state != mgmtstorage.ProvisioningStateSucceeded {
+		return errors.Errorf(errAccountNotProvisioned, state)
+	}
+	return nil
+}
+
+// checkLocation returns an error unless the storage account is in one of the
+// allowed locations. The account's location is only read from the management
+// plane when some locations are allowed, and that read is reused by the rest
+// of the reconcile, such as the check that the account is provisioned.
+func (ccu *containerCreateUpdater) checkLocation(ctx context.Context) error {
+	if len(ccu.allowedLocations) == 0 {
+		return nil
+	}
+	m, err := ccu.management(ctx)
+	if err != nil {
+		return err
+	}
+	a, err := m.GetAccount(ctx)
+	if err != nil {
+		return errors.Wrap(err, errGetAccount)
+	}
+	loc := to.String(a.Location)
+	for _, l := range ccu.allowedLocations {
+		if normalizeLocation(l) == normalizeLocation(loc) {
+			return nil
+		}
+	}
+	return errors.Errorf(errLocationDenied, loc, strings.Join(ccu.allowedLocations, ", "))
+}
+
+// checkAccountPublicAccess returns an error if the supplied desired state asks
+// for public access but the storage account disallows blob public access, in
+// which case Azure would reject writing it.
+func (ccu *containerCreateUpdater) checkAccountPublicAccess(ctx context.Context, spec v1alpha3.ContainerParameters) error {
+	if ccu.accountAccess == nil {
+		return nil
+	}
+	if access, _ := canonicalize(spec.PublicAccessType, nil); access == azblob.PublicAccessNone {
+		return nil
+	}
+	m, err := ccu.management(ctx)
+	if err != nil {
+		return err
+	}
+	name := meta.GetExternalName(ccu.account)
+	allowed, err := ccu.accountAccess.Allowed(ctx, name, m)
+	if err != nil {
+		return errors.Wrap(err, errGetAccount)
+	}
+	if !allowed {
+		return &storage.PublicAccessDisallowedError{Account: name, Access: spec.PublicAccessType}
+	}
+	return nil
+}
+
+// normalizeLocation returns the supplied Azure location in lower case without
+// spaces, so that display names such as "West Europe" match westeurope.
+func normalizeLocation(l string) string {
+	return strings.ToLower(strings.ReplaceAll(l, " ", ""))
+}
+
+func (ccu *containerCreateUpdater) update(ctx context.Context, accessType *azblob.PublicAccessType, md azblob.Metadata) (reconcile.Result, error) { // nolint:gocyclo
+	container := ccu.container
+	spec, err := ccu.desired(ctx)
+	if err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	// Metadata that Azure rejected is not written again until the spec
+	// changes, unless it was written with sanitized keys instead.
+	rejected := metadataRejected(container)
+	if rejected && container.Status.AtProvider.MetadataKeysSanitized {
+		spec.Metadata = sanitizeMetadataKeys(spec.Metadata)
+	}
+	if err := checkOwner(externalName(container), md, ccu.ownerKey, ccu.owner); err != nil && !ccu.observeOnly {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+
+	p, err := ccu.GetContainerProperties(ctx)
+	if err != nil {
+		ccu.conditions.setReconcileError(container, errors.Wrap(err, errGetProperties))
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+
+	checkETag(container, p.ETag)
+	ccu.audit.audit(container, ccu.account, *accessType)
+
+	container.Status.Drift = driftReport(spec, *accessType, md, ccu.managedKeys)
+	var held time.Duration
+	var heldDrift []string
+	if !ccu.observeOnly {
+		// Intentional, temporary changes to public access may be given time
+		// to be reverted before we correct them. Until then the public access
+		// type is left as it is, but any other drift is corrected.
+		if held = ccu.remediation.hold(container, spec.PublicAccessType, *accessType); held > 0 {
+			heldDrift = publicAccessDrift(spec.PublicAccessType, *accessType)
+			spec.PublicAccessType = *accessType
+		}
+	}
+	drift := containerDrift(spec, *accessType, md)
+	scopeDrift := encryptionScopeDrift(spec, p)
+	// A reused observation says nothing of the account's location, so it is
+	// checked even when nothing more is read from Azure.
+	if err := ccu.checkLocation(ctx); err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	if held == 0 && observationReusable(container, spec, p, drift, scopeDrift) {
+		return ccu.reuseObservation(ctx)
+	}
+
+	if !ccu.observeOnly {
+		if err := ccu.checkAccountPublicAccess(ctx, spec); err != nil {
+			ccu.conditions.setReconcileError(container, err)
+			return resultRequeue, ccu.kube.Status().Update(ctx, container)
+		}
+	}
+	policyDrift, err := ccu.accessPolicyDrift(ctx, spec)
+	if err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	immutabilityDrift, immutability, m, err := ccu.immutability(ctx, spec)
+	if err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	unchanged := len(drift) == 0 && len(scopeDrift) == 0 && len(policyDrift) == 0 && len(immutabilityDrift) == 0 && observedUnchanged(container, p)
+	withheld, accessWritten := false, false
+	if !ccu.observeOnly {
+		if len(drift) > 0 {
+			// Metadata is only written if it drifted, so that metadata that
+			// was applied by a partially failed update isn't written again.
+			var metadata *azblob.Metadata
+			if metadataDrifted(spec.Metadata, md) {
+				metadata = &spec.Metadata
+				if withheld = rejected && !container.Status.AtProvider.MetadataKeysSanitized; withheld {
+					metadata = nil
+				}
+			}
+			accessWritten = *accessType != spec.PublicAccessType
+			v, err := storage.UpdateVerifyPublicAccess(ctx, ccu.ContainerOperations, *accessType, spec.PublicAccessType, metadata, verifyTimeout)
+			if storage.IsInvalidMetadata(err) {
+				v, err = ccu.rejectMetadata(ctx, *accessType, spec, err)
+			}
+			recordOperation(container, v1alpha3.ContainerOperationUpdate, err)
+			if err != nil {
+				ccu.conditions.setReconcileError(container, err)
+				return resultRequeue, ccu.kube.Status().Update(ctx, container)
+			}
+			// Get the ETag of our own change, so it is not mistaken for an
+			// external one next time, unless verifying it already did.
+			if p = v; p == nil {
+				if p, err = ccu.GetContainerProperties(ctx); err != nil {
+					ccu.conditions.setReconcileError(container, errors.Wrap(err, errGetProperties))
+					return resultRequeue, ccu.kube.Status().Update(ctx, container)
+				}
+			}
+		}
+		// Our operations write the desired stored access policies along with
+		// the public access type, so they only need writing if it wasn't.
+		if len(policyDrift) > 0 && !accessWritten {
+			if err := ccu.setAccessPolicies(ctx, spec); err != nil {
+				ccu.conditions.setReconcileError(container, err)
+				return resultRequeue, ccu.kube.Status().Update(ctx, container)
+			}
+			if p, err = ccu.GetContainerProperties(ctx); err != nil {
+				ccu.conditions.setReconcileError(container, errors.Wrap(err, errGetProperties))
+				return resultRequeue, ccu.kube.Status().Update(ctx, container)
+			}
+		}
+		if len(scopeDrift) > 0 {
+			if p, err = ccu.updateEncryptionScope(ctx, spec, p); err != nil {
+				ccu.conditions.setReconcileError(container, err)
+				return resultRequeue, ccu.kube.Status().Update(ctx, container)
+			}
+		}
+		if len(immutabilityDrift) > 0 {
+			if err := ccu.updateImmutability(ctx, m, spec, immutability); err != nil {
+				ccu.conditions.setReconcileError(container, err)
+				return resultRequeue, ccu.kube.Status().Update(ctx, container)
+			}
+		}
+	}
+
+	if err := ccu.observe(ctx, p, unchanged); err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	due, err := ccu.publishSharedAccessSignatures(ctx)
+	if err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+
+	drift = concatDrift(drift, scopeDrift, policyDrift, immutabilityDrift)
+	if ccu.observeOnly {
+		synced := xpv1.ReconcileSuccess()
+		if len(drift) > 0 {
+			ccu.transitions.driftDetected(container, ccu.account, drift)
+			synced = driftDetected(drift)
+		}
+		clearThrottled(container)
+		container.Status.SetConditions(xpv1.Available(), synced)
+		return reconcile.Result{RequeueAfter: requeueAfter(jittered(ccu.poll, ccu.jitter), due)}, ccu.kube.Status().Update(ctx, ccu.container)
+	}
+
+	// Drift from a spec we have already applied can only have been caused by
+	// a change made outside of Crossplane. We've corrected it, but containers
+	// that are managed exclusively report it too.
+	external := len(drift) > 0 && container.Status.ObservedGeneration == container.Generation
+	if held == 0 {
+		container.Status.ObservedGeneration = container.Generation
+	}
+	switch {
+	case external:
+		ccu.transitions.driftCorrected(container, ccu.account, drift)
+	case len(drift) > 0:
+		ccu.transitions.updated(container, ccu.account, drift)
+	}
+	if external && adoptionPolicy(container) == v1alpha3.ManageExclusively {
+		err := &storage.ExternalChangeError{Container: externalName(container), Changes: drift}
+		container.Status.SetConditions(xpv1.Available())
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+
+	clearThrottled(container)
+	container.Status.SetConditions(xpv1.Available())
+	if held > 0 {
+		ccu.transitions.driftDetected(container, ccu.account, heldDrift)
+		container.Status.SetConditions(driftDetected(heldDrift))
+		return reconcile.Result{RequeueAfter: requeueAfter(held, due)}, ccu.kube.Status().Update(ctx, ccu.container)
+	}
+	if withheld {
+		ccu.conditions.setReconcileError(container, &storage.MetadataRejectedError{Container: externalName(container)})
+		return reconcile.Result{RequeueAfter: requeueAfter(jittered(ccu.poll, ccu.jitter), due)}, ccu.kube.Status().Update(ctx, ccu.container)
+	}
+	container.Status.SetConditions(xpv1.ReconcileSuccess())
+	return reconcile.Result{RequeueAfter: requeueAfter(jittered(ccu.poll, ccu.jitter), due)}, ccu.kube.Status().Update(ctx, ccu.container)
+}
+
+// reuseObservation completes a reconcile of a container whose observation
+// could be reused, so nothing more is read from Azure. Only its shared access
+// signatures, which expire regardless, are published.
+func (ccu *containerCreateUpdater) reuseObservation(ctx context.Context) (reconcile.Result, error) {
+	container := ccu.container
+	due, err := ccu.publishSharedAccessSignatures(ctx)
+	if err != nil {
+		ccu.conditions.setReconcileError(container, err)
+		return resultRequeue, ccu.kube.Status().Update(ctx, container)
+	}
+	clearThrottled(container)
+	container.Status.SetConditions(xpv1.Available(), xpv1.ReconcileSuccess())
+	return reconcile.Result{RequeueAfter: requeueAfter(jittered(ccu.poll, ccu.jitter), due)}, ccu.kube.Status().Update(ctx, container)
+}
+
+// concatDrift returns the supplied descriptions of drift in order, in a new
+// slice that shares no storage with any of them.
+func concatDrift(drift ...[]string) []string {
+	n := 0
+	for _, d := range drift {
+		n += len(d)
+	}
+	all := make([]string, 0, n)
+	for _, d := range drift {
+		all = append(all, d...)
+	}
+	return all
+}
+
+// metadataRejected returns true if Azure rejected the metadata of the
+// supplied container's current generation.
+func metadataRejected(c *v1alpha3.Container) bool {
+	g := c.Status.AtProvider.MetadataRejectedGeneration
+	return g != 0 && g == c.Generation
+}
+
+// rejectMetadata records that Azure rejected the supplied desired metadata
+// with the supplied error, so that it is not written again until the spec
+// changes. If keys may be sanitized the update is retried once with the
+// metadata's keys sanitized, and the result of the retry is returned.
+// Otherwise the supplied error is.
+func (ccu *containerCreateUpdater) rejectMetadata(ctx context.Context, observed azblob.PublicAccessType, spec v1alpha3.ContainerParameters, err error) (*storage.ContainerProperties, error) {
+	container := ccu.container
+	container.Status.AtProvider.MetadataRejectedGeneration = container.Generation
+	container.Status.AtProvider.MetadataKeysSanitized = false
+	if !ccu.sanitizeKeys {
+		return nil, err
+	}
+	md := azblob.Metadata(sanitizeMetadataKeys(spec.Metadata))
+	p, err := storage.UpdateVerifyPublicAccess(ctx, ccu.ContainerOperations, observed, spec.PublicAccessType, &md, verifyTimeout)
+	container.Status.AtProvider.MetadataKeysSanitized = err == nil
+	return p, err
+}
+
+// jittered returns d randomized uniformly within [d-d*fraction, d+d*fraction].
+func jittered(d time.Duration, fraction float64) time.Duration {
+	if d <= 0 || fraction <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 - fraction + 2*fraction*rand.Float64())) // nolint:gosec
+}
+
+// updateEncryptionScope changes the default encryption scope of the container,
+// and whether blobs may override it, through the management plane, which
+// unlike the blob service allows them to change after the container is
+// created. It returns the container's properties as they were after the
+// change.
+func (ccu *containerCreateUpdater) updateEncryptionScope(ctx context.Context, spec v1alpha3.ContainerParameters, p *storage.ContainerProperties) (*storage.ContainerProperties, error) {
+	scope := spec.DefaultEncryptionScope
+	removal := storage.IsAccountEncryptionScope(scope) && !storage.IsAccountEncryptionScope(p.DefaultEncryptionScope)
+	if removal && (spec.PreventEncryptionScopeOverride || p.PreventEncryptionScopeOverride) {
+		return nil, errors.Errorf(errScopeRemovalBlocked, p.DefaultEncryptionScope)
+	}
+	if scope == "" {
+		scope = storage.AccountEncryptionScope
+	}
+	if err := ccu.ensureEncryptionScope(ctx, spec); err != nil {
+		return nil, err
+	}
+
+	m, err := ccu.management(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.SetContainerEncryptionScope(ctx, externalName(ccu.container), scope, spec.PreventEncryptionScopeOverride); err != nil {
+		if removal {
+			return nil, errors.Wrapf(err, errRemoveScope, p.DefaultEncryptionScope)
+		}
+		return nil, errors.Wrapf(err, errSetScope, scope)
+	}
+
+	p, err = ccu.GetContainerProperties(ctx)
+	return p, errors.Wrap(err, errGetProperties)
+}
+
+// ensureEncryptionScope creates the container's default encryption scope in
+// its storage account if the container asks for it to be created and it does
+// not already exist.
+func (ccu *containerCreateUpdater) ensureEncryptionScope(ctx context.Context, spec v1alpha3.ContainerParameters) error {
+	if !spec.CreateEncryptionScope || storage.IsAccountEncryptionScope(spec.DefaultEncryptionScope) {
+		return nil
+	}
+	m, err := ccu.management(ctx)
+	if err != nil {
+		return err
+	}
+	err = m.EnsureEncryptionScope(ctx, ccu.account.Spec.ResourceGroupName, meta.GetExternalName(ccu.account), spec.DefaultEncryptionScope, spec.EncryptionScopeKeyURI)
+	return errors.Wrapf(err, errEnsureScope, spec.DefaultEncryptionScope)
+}
+
+// encryptionScopeDrift describes how the observed encryption settings of a
+// container differ from the supplied desired state.
+func encryptionScopeDrift(spec v1alpha3.ContainerParameters, p *storage.ContainerProperties) []string {
+	drift := []string{}
+	want, got := spec.DefaultEncryptionScope, p.DefaultEncryptionScope
+	if want != got && !(storage.IsAccountEncryptionScope(want) && storage.IsAccountEncryptionScope(got)) {
+		drift = append(drift, fmt.Sprintf("defaultEncryptionScope: want %q, got %q", want, got))
+	}
+	if spec.PreventEncryptionScopeOverride != p.PreventEncryptionScopeOverride {
+		drift = append(drift, fmt.Sprintf("preventEncryptionScopeOverride: want %t, got %t", spec.PreventEncryptionScopeOverride, p.PreventEncryptionScopeOverride))
+	}
+	return drift
+}
+
+// desired returns the desired state of the container, with the data of any
+// referenced metadata ConfigMap merged into its metadata, template variables
+// substituted into its metadata values, and its metadata keys in the case its
+// metadata key policy writes them in.
+func (ccu *containerCreateUpdater) desired(ctx context.Context) (v1alpha3.ContainerParameters, error) {
+	p := ccu.container.Spec.ContainerParameters
+	if p.MetadataFrom != nil {
+		cm := &corev1.ConfigMap{}
+		nn := types.NamespacedName{Namespace: p.MetadataFrom.Namespace, Name: p.MetadataFrom.Name}
+		if err := ccu.kube.Get(ctx, nn, cm); err != nil {
+			return p, errors.Wrapf(err, errGetMetadata, nn)
+		}
+		p.Metadata = mergeMetadata(cm.Data, p.Metadata)
+	}
+	if len(ccu.defaultMetadata) > 0 {
+		p.Metadata = mergeMetadata(ccu.defaultMetadata, p.Metadata)
+	}
+
+	if len(p.Metadata) > 0 {
+		md, err := renderMetadata(p.Metadata, templateVariables(ccu.container))
+		if err != nil {
+			return p, err
+		}
+		p.Metadata = md
+	}
+	if err := checkMetadataKeys(p.Metadata, ccu.allowedMetadataKeys); err != nil {
+		return p, err
+	}
+
+	// Default content settings are kept in reserved metadata keys, so drift
+	// from them is metadata drift. They are not rendered as templates.
+	if cs := p.DefaultContentSettings; cs != nil {
+		s := storage.ContentSettings{CacheControl: cs.CacheControl, ContentDisposition: cs.ContentDisposition}
+		if err := s.Validate(); err != nil {
+			return p, err
+		}
+		p.Metadata = s.ApplyTo(p.Metadata)
+	}
+	if len(p.Metadata) > 0 {
+		p.Metadata = applyMetadataKeyPolicy(p.Metadata, p.MetadataKeyPolicy)
+	}
+	return p, checkMetadataValueLengths(p.Metadata, ccu.maxValueLength)
+}
+
+// containerDrift describes each way in which the observed public access type
+// and metadata of a container differ from the supplied desired state. Both are
+// canonicalized before they are compared. It returns nothing when the
+// container is up to date.
+func containerDrift(spec v1alpha3.ContainerParameters, access azblob.PublicAccessType, meta azblob.Metadata) []string {
+	wantAccess, wantMeta := canonicalize(spec.PublicAccessType, spec.Metadata)
+	gotAccess, gotMeta := canonicalize(access, meta)
+
+	drift := publicAccessDrift(wantAccess, gotAccess)
+	for _, k := range metadataKeys(wantMeta, gotMeta) {
+		want, wok := wantMeta[k]
+		got, gok := gotMeta[k]
+		switch {
+		case !gok:
+			drift = append(drift, fmt.Sprintf("metadata[%s]: want %q, got none", k, want))
+		case !wok:
+			drift = append(drift, fmt.Sprintf("metadata[%s]: want none, got %q", k, got))
+		case want != got:
+			drift = append(drift, fmt.Sprintf("metadata[%s]: want %q, got %q", k, want, got))
+		}
+	}
+	return drift
+}
+
+// publicAccessDrift describes how the supplied observed public access type of
+// a container differs from the supplied desired one.
+func publicAccessDrift(want, got azblob.PublicAccessType) []string {
+	want, _ = canonicalize(want, nil)
+	got, _ = canonicalize(got, nil)
+	if want == got {
+		return []string{}
+	}
+	return []string{fmt.Sprintf("publicAccessType: want %q, got %q", want, got)}
+}
+
+// driftReport describes how the observed public access type and metadata of a
+// container differ from the supplied desired state, for its status. Both are
+// canonicalized before they are compared, as they are by containerDrift.
+// Drift of the supplied managed metadata keys is reported as platform drift.
+// It returns nil when the container is up to date.
+func driftReport(spec v1alpha3.ContainerParameters, access azblob.PublicAccessType, meta azblob.Metadata, managed []string) *v1alpha3.ContainerDrift {
+	wantAccess, wantMeta := canonicalize(spec.PublicAccessType, spec.Metadata)
+	gotAccess, gotMeta := canonicalize(access, meta)
+	platform := make(map[string]bool, len(managed))
+	for _, k := range managed {
+		platform[strings.ToLower(k)] = true
+	}
+
+	d := &v1alpha3.ContainerDrift{}
+	if gotAccess != wantAccess {
+		d.PublicAccessType = &v1alpha3.PublicAccessTypeDrift{Desired: accessTypeName(wantAccess), Observed: accessTypeName(gotAccess)}
+	}
+	for _, k := range metadataKeys(wantMeta, gotMeta) {
+		want, wok := wantMeta[k]
+		got, gok := gotMeta[k]
+		switch {
+		case wok && gok && want == got:
+		case platform[k]:
+			d.PlatformMetadata = append(d.PlatformMetadata, k)
+		case !gok:
+			d.MetadataRemoved = append(d.MetadataRemoved, k)
+		case !wok:
+			d.MetadataAdded = append(d.MetadataAdded, k)
+		case want != got:
+			d.MetadataChanged = append(d.MetadataChanged, k)
+		}
+	}
+	if d.PublicAccessType == nil && len(d.MetadataAdded)+len(d.MetadataRemoved)+len(d.MetadataChanged)+len(d.PlatformMetadata) == 0 {
+		return nil
+	}
+	return d
+}
+
+// accessTypeName returns the name of the supplied canonical public access
+// type, which is None when there is no public access.
+func accessTypeName(t azblob.PublicAccessType) string {
+	if t == azblob.PublicAccessNone {
+		return "None"
+	}
+	return string(t)
+}
+
+// metadataKeys returns the sorted keys of either of the supplied metadata.
+func metadataKeys(want, got azblob.Metadata) []string {
+	keys := make([]string, 0, len(want)+len(got))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// metadataDrifted returns true if the supplied observed metadata differs from
+// the supplied desired metadata once both are canonicalized.
+func metadataDrifted(want, got azblob.Metadata) bool {
+	_, cw := canonicalize("", want)
+	_, cg := canonicalize("", got)
+	if len(cw) != len(cg) {
+		return true
+	}
+	for k, v := range cw {
+		if g, ok := cg[k]; !ok || g != v {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalize returns the canonical form of the supplied public access type
+// and metadata, in which equivalent values are equal. A public access type of
+// "None" is the same as no public access, and public access types are not case
+// sensitive. Nil metadata is the same as empty metadata, and metadata keys are
+// not case sensitive; Azure reports them in lower case.
+func canonicalize(access azblob.PublicAccessType, md azblob.Metadata) (azblob.PublicAccessType, azblob.Metadata) {
+	access = azblob.PublicAccessType(strings.ToLower(string(access)))
+	if access == "none" {
+		access = azblob.PublicAccessNone
+	}
+
+	cmd := make(azblob.Metadata, len(md))
+	for k, v := range md {
+		cmd[strings.ToLower(k)] = v
+	}
+	return access, cmd
+}
+
+// driftDetected returns a condition that indicates an observe-only container
+// has drifted from its desired state in the supplied ways.
+func driftDetected(drift []string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeSynced,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDriftDetected,
+		Message:            "observe-only: " + strings.Join(drift, "; "),
+	}
+}
+
+// checkETag sets the ExternallyModified condition of the supplied container
+// according to whether its observed ETag differs from the one we recorded when
+// we last observed or changed it. Containers we have not yet observed have no
+// recorded ETag.
+func checkETag(c *v1alpha3.Container, observed azblob.ETag) {
+	last := c.Status.AtProvider.ETag
+	if last == "" || observed == "" {
+		return
+	}
+	if string(observed) != last {
+		c.Status.SetConditions(externallyModified(last, string(observed)))
+		return
+	}
+	if c.Status.GetCondition(TypeExternallyModified).Status == corev1.ConditionTrue {
+		c.Status.SetConditions(notExternallyModified())
+	}
+}
+
+// externallyModified returns a condition indicating that a container was
+// changed outside of Crossplane.
+func externallyModified(last, observed string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeExternallyModified,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonExternalModification,
+		Message:            fmt.Sprintf("external modification detected: ETag changed from %s to %s", last, observed),
+	}
+}
+
+// notExternallyModified returns a condition indicating that a container was
+// not changed outside of Crossplane since it was last observed.
+func notExternallyModified() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeExternallyModified,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNoExternalModification,
+	}
+}
+
+// observationReusable returns true if nothing the supplied container manages
+// can have changed since its last reconcile, given its supplied desired
+// state, observed properties, and drift. That is the case if its last
+// reconcile succeeded, it did not drift, and it is unchanged since it was last
+// observed. Writing its stored access policies changes its ETag too, but
+// changing its immutability does not, so containers that manage it are always
+// observed afresh.
+func observationReusable(c *v1alpha3.Container, spec v1alpha3.ContainerParameters, p *storage.ContainerProperties, drift, scopeDrift []string) bool {
+	return len(drift) == 0 && len(scopeDrift) == 0 &&
+		c.Status.GetCondition(xpv1.TypeSynced).Reason == xpv1.ReasonReconcileSuccess &&
+		observedUnchanged(c, p) &&
+		!managesImmutability(spec)
+}
+
+// observedUnchanged returns true if the spec generation of the supplied
+// container was already acted on, and the supplied properties are the ones
+// that were last recorded in its status.
+func observedUnchanged(c *v1alpha3.Container, p *storage.ContainerProperties) bool {
+	o := c.Status.AtProvider
+	return c.Status.ObservedGeneration == c.Generation &&
+		o.EncryptionKeyType != "" &&
+		o.ETag == string(p.ETag) &&
+		o.DefaultEncryptionScope == p.DefaultEncryptionScope
+}
+
+// observe records the supplied observed properties of the container in its
+// status. The key type of an encryption scope is looked up on the management
+// plane unless the container is unchanged since it was last observed, in
+// which case the recorded one is kept.
+func (ccu *containerCreateUpdater) observe(ctx context.Context, p *storage.ContainerProperties, unchanged bool) error {
+	kt := storage.AccountEncryptionKeyType(accountKeySource(ccu.account))
+	switch {
+	case storage.IsAccountEncryptionScope(p.DefaultEncryptionScope):
+		// Blobs are encrypted with the account's key.
+	case unchanged:
+		kt = storage.EncryptionKeyType(ccu.container.Status.AtProvider.EncryptionKeyType)
+	default:
+		m, err := ccu.management(ctx)
+		if err != nil {
+			return err
+		}
+		if kt, err = storage.GetEncryptionScopeKeyType(ctx, m, p.DefaultEncryptionScope); err != nil {
+			return err
+		}
+	}
+
+	ccu.container.Status.AtProvider.DefaultEncryptionScope = p.DefaultEncryptionScope
+	ccu.container.Status.AtProvider.EncryptionKeyType = string(kt)
+	ccu.container.Status.AtProvider.ETag = string(p.ETag)
+	ccu.container.Status.AtProvider.DeletionProtected = ccu.container.Spec.DeletionProtection
+	return nil
+}
+
+// accountKeySource returns the encryption key source of the supplied account
+// as last observed by the account controller.
+func accountKeySource(acct *v1alpha3.Account) string {
+	if acct == nil || acct.Spec.StorageAccountSpec == nil ||
+		acct.Spec.StorageAccountSpec.StorageAccountSpecProperties == nil ||
+		acct.Spec.StorageAccountSpec.Encryption == nil {
+		return ""
+	}
+	return string(acct.Spec.StorageAccountSpec.Encryption.KeySource)
 }
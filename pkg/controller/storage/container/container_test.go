@@ -18,6 +18,7 @@ package container
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"strings"
 	"testing"
@@ -25,8 +26,10 @@ import (
 
 	"github.com/crossplane-contrib/provider-azure/apis"
 
+	mgmtstorage "github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
 	"github.com/Azure/azure-storage-blob-go/azblob"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
@@ -35,18 +38,25 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
 	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+	azurev1alpha3 "github.com/crossplane-contrib/provider-azure/apis/v1alpha3"
+	"github.com/crossplane-contrib/provider-azure/apis/v1beta1"
 	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
 	azurestoragefake "github.com/crossplane-contrib/provider-azure/pkg/clients/storage/fake"
 )
@@ -84,6 +94,7 @@ var _ createupdater = &mockCreateUpdater{}
 type mockSyncdeleter struct {
 	mockDelete func(context.Context) (reconcile.Result, error)
 	mockSync   func(context.Context) (reconcile.Result, error)
+	ceiling    time.Duration
 }
 
 func (m *mockSyncdeleter) delete(ctx context.Context) (reconcile.Result, error) {
@@ -94,6 +105,10 @@ func (m *mockSyncdeleter) sync(ctx context.Context) (reconcile.Result, error) {
 	return m.mockSync(ctx)
 }
 
+func (m *mockSyncdeleter) requeueCeiling() time.Duration {
+	return m.ceiling
+}
+
 var _ syncdeleter = &mockSyncdeleter{}
 
 type mockSyncdeleteMaker struct {
@@ -137,7 +152,7 @@ func newStorageNotFoundError() error {
 
 const (
 	testNamespace     = "default"
-	testContainerName = "testContainer"
+	testContainerName = "test-container"
 	testAccountName   = "testAccount"
 )
 
@@ -248,6 +263,25 @@ func TestReconciler_Reconcile(t *testing.T) {
 				res: reconcile.Result{},
 			},
 		},
+		{
+			name: "SyncFailedWithBackoffCeiling",
+			fields: fields{
+				Client: fake.NewClientBuilder().WithObjects(v1alpha3test.NewMockContainer(testContainerName).Container).Build(),
+				syncdeleterMaker: &mockSyncdeleteMaker{
+					mockNewSyncdeleter: func(ctx context.Context, c *v1alpha3.Container, _ time.Duration) (syncdeleter, error) {
+						return &mockSyncdeleter{
+							mockSync: func(ctx context.Context) (reconcile.Result, error) {
+								return resultRequeue, nil
+							},
+							ceiling: time.Minute,
+						}, nil
+					},
+				},
+			},
+			want: want{
+				res: reconcile.Result{RequeueAfter: baseRequeueBackoff},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -255,6 +289,7 @@ func TestReconciler_Reconcile(t *testing.T) {
 				Client:           tt.fields.Client,
 				syncdeleterMaker: tt.fields.syncdeleterMaker,
 				Initializer:      managed.NewNameAsExternalName(tt.fields.Client),
+				backoff:          &requeueBackoff{},
 				log:              logging.NewNopLogger(),
 			}
 			got, err := r.Reconcile(context.Background(), req)
@@ -427,6 +462,41 @@ func Test_containerSyncdeleterMaker_newSyncdeleter(t *testing.T) {
 					"failed to create client handle: %s, storage account: %s", testContainerName, testAccountName),
 			},
 		},
+		{
+			name: "InvalidExternalName",
+			fields: fields{
+				Client: &test.MockClient{},
+			},
+			args: args{
+				ctx: ctx,
+				c:   newCont().WithSpecProviderRef(testAccountName).WithExternalName("Not_Valid").Container,
+			},
+			want: want{
+				err: storage.ValidateContainerName("Not_Valid"),
+			},
+		},
+		{
+			name: "ExternalNameOverride",
+			fields: fields{
+				Client: fake.NewClientBuilder().WithObjects(
+					newCont().WithSpecProviderRef(testAccountName).Container,
+					newSecret(testNamespace, testAccountName, map[string][]byte{
+						xpv1.ResourceCredentialsSecretUserKey:     []byte(testAccountName),
+						xpv1.ResourceCredentialsSecretPasswordKey: []byte("test-key"),
+					}),
+					v1alpha3test.NewMockAccount(testAccountName).
+						WithSpecWriteConnectionSecretToReference(testNamespace, testAccountName).
+						Account).Build(),
+			},
+			args: args{
+				ctx: ctx,
+				c:   newCont().WithSpecProviderRef(testAccountName).WithExternalName("azure-only-name").Container,
+			},
+			want: want{
+				err: errors.Wrapf(errors.New("illegal base64 data at input byte 4"),
+					"failed to create client handle: %s, storage account: %s", "azure-only-name", testAccountName),
+			},
+		},
 		{
 			name: "Success",
 			fields: fields{
@@ -477,6 +547,7 @@ func Test_containerSyncdeleterMaker_newSyncdeleter(t *testing.T) {
 				if diff := cmp.Diff(tt.want.syndel, got,
 					cmpopts.IgnoreUnexported(containerSyncdeleter{}),
 					cmpopts.IgnoreUnexported(azblob.ContainerURL{}),
+					cmpopts.IgnoreUnexported(storage.ContainerHandle{}),
 				); diff != "" {
 					t.Errorf("containerSyncdeleterMaker.newSyncdeleter(): -want, +got:\n%s", diff)
 				}
@@ -503,6 +574,7 @@ func Test_containerSyncdeleter_delete(t *testing.T) {
 		ContainerOperations storage.ContainerOperations
 		kube                client.Client
 		container           *v1alpha3.Container
+		deletion            wait.Backoff
 	}
 	type args struct {
 		ctx context.Context
@@ -557,6 +629,85 @@ func Test_containerSyncdeleter_delete(t *testing.T) {
 					WithSpecDeletionPolicy(xpv1.DeletionDelete).
 					WithFinalizers([]string{}).
 					WithStatusConditions(xpv1.Deleting()).
+					WithStatusOperations(operation(v1alpha3.ContainerOperationDelete, v1alpha3.OperationSucceeded)).
+					Container,
+			},
+		},
+		{
+			name: "DeleteEventuallyGone",
+			fields: fields{
+				kube: test.NewMockClient(),
+				ContainerOperations: func() storage.ContainerOperations {
+					polls := 0
+					return &azurestoragefake.MockContainerOperations{
+						MockDelete: func(ctx context.Context) error { return nil },
+						MockExists: func(ctx context.Context) (bool, error) {
+							polls++
+							return polls < 3, nil
+						},
+					}
+				}(),
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecDeletionPolicy(xpv1.DeletionDelete).
+					WithFinalizer(finalizer).Container,
+				deletion: wait.Backoff{Duration: time.Millisecond, Steps: 5},
+			},
+			args: args{ctx: ctx},
+			want: want{
+				res: reconcile.Result{},
+				cont: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecDeletionPolicy(xpv1.DeletionDelete).
+					WithFinalizers([]string{}).
+					WithStatusConditions(xpv1.Deleting()).
+					WithStatusOperations(operation(v1alpha3.ContainerOperationDelete, v1alpha3.OperationSucceeded)).
+					Container,
+			},
+		},
+		{
+			name: "DeleteLingersPastDeadline",
+			fields: fields{
+				kube: test.NewMockClient(),
+				ContainerOperations: &azurestoragefake.MockContainerOperations{
+					MockDelete: func(ctx context.Context) error { return nil },
+					MockExists: func(ctx context.Context) (bool, error) { return true, nil },
+				},
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecDeletionPolicy(xpv1.DeletionDelete).
+					WithFinalizer(finalizer).Container,
+				deletion: wait.Backoff{Duration: time.Millisecond, Steps: 3},
+			},
+			args: args{ctx: ctx},
+			want: want{
+				res: resultRequeue,
+				cont: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecDeletionPolicy(xpv1.DeletionDelete).
+					WithFinalizer(finalizer).
+					WithStatusConditions(xpv1.Deleting()).
+					WithStatusOperations(operation(v1alpha3.ContainerOperationDelete, v1alpha3.OperationSucceeded)).
+					Container,
+			},
+		},
+		{
+			name: "VerifyDeletionError",
+			fields: fields{
+				kube: test.NewMockClient(),
+				ContainerOperations: &azurestoragefake.MockContainerOperations{
+					MockDelete: func(ctx context.Context) error { return nil },
+					MockExists: func(ctx context.Context) (bool, error) { return false, errBoom },
+				},
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecDeletionPolicy(xpv1.DeletionDelete).
+					WithFinalizer(finalizer).Container,
+				deletion: wait.Backoff{Duration: time.Millisecond, Steps: 3},
+			},
+			args: args{ctx: ctx},
+			want: want{
+				res: resultRequeue,
+				cont: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecDeletionPolicy(xpv1.DeletionDelete).
+					WithFinalizer(finalizer).
+					WithStatusConditions(xpv1.Deleting(), xpv1.ReconcileError(errors.Wrap(errBoom, errVerifyDeletion))).
+					WithStatusOperations(operation(v1alpha3.ContainerOperationDelete, v1alpha3.OperationSucceeded)).
 					Container,
 			},
 		},
@@ -580,6 +731,7 @@ func Test_containerSyncdeleter_delete(t *testing.T) {
 					WithSpecDeletionPolicy(xpv1.DeletionDelete).
 					WithFinalizer(finalizer).
 					WithStatusConditions(xpv1.Deleting(), xpv1.ReconcileError(errBoom)).
+					WithStatusOperations(operation(v1alpha3.ContainerOperationDelete, v1alpha3.OperationFailed)).
 					Container,
 			},
 		},
@@ -591,6 +743,7 @@ func Test_containerSyncdeleter_delete(t *testing.T) {
 				ContainerOperations: tt.fields.ContainerOperations,
 				kube:                tt.fields.kube,
 				container:           tt.fields.container,
+				deletion:            tt.fields.deletion,
 			}
 			got, err := csd.delete(tt.args.ctx)
 			if diff := cmp.Diff(tt.want.err, err, test.EquateErrors()); diff != "" {
@@ -599,7 +752,7 @@ func Test_containerSyncdeleter_delete(t *testing.T) {
 			if diff := cmp.Diff(tt.want.res, got); diff != "" {
 				t.Errorf("containerSyncdeleter.delete(): -want, +got:\n%s", diff)
 			}
-			if diff := cmp.Diff(tt.want.cont, tt.fields.container, test.EquateConditions()); diff != "" {
+			if diff := cmp.Diff(tt.want.cont, tt.fields.container, test.EquateConditions(), equateOperations); diff != "" {
 				t.Errorf("containerSyncdeleter.delete() container: -want, +got:\n%s", diff)
 			}
 		})
@@ -640,9 +793,12 @@ func Test_containerSyncdeleter_sync(t *testing.T) {
 						return nil, nil, newStorageNotFoundError()
 					},
 				},
+				container: v1alpha3test.NewMockContainer(testContainerName).Container,
 			},
 			args: args{ctx: ctx},
-			want: want{},
+			want: want{
+				cont: v1alpha3test.NewMockContainer(testContainerName).Container,
+			},
 		},
 		{
 			name: "GetErrorOther",
@@ -723,6 +879,26 @@ func Test_containerSyncdeleter_sync(t *testing.T) {
 	}
 }
 
+// newProvisionedManagementOperations returns ManagementOperations for a storage
+// account that has finished provisioning.
+// equateOperations compares operation records ignoring when they happened.
+var equateOperations = cmpopts.IgnoreFields(v1alpha3.ContainerOperationRecord{}, "Time")
+
+// operation returns a record of an attempt at the supplied operation.
+func operation(op v1alpha3.ContainerOperation, outcome v1alpha3.OperationOutcome) v1alpha3.ContainerOperationRecord {
+	return v1alpha3.ContainerOperationRecord{Operation: op, Outcome: outcome}
+}
+
+func newProvisionedManagementOperations() *azurestoragefake.MockManagementOperations {
+	return &azurestoragefake.MockManagementOperations{
+		MockGetAccount: func(context.Context) (*mgmtstorage.Account, error) {
+			return &mgmtstorage.Account{AccountProperties: &mgmtstorage.AccountProperties{
+				ProvisioningState: mgmtstorage.ProvisioningStateSucceeded,
+			}}, nil
+		},
+	}
+}
+
 func Test_containerCreateUpdater_create(t *testing.T) {
 	ctx := context.TODO()
 	errBoom := errors.New("boom")
@@ -783,6 +959,34 @@ func Test_containerCreateUpdater_create(t *testing.T) {
 				cont: v1alpha3test.NewMockContainer(testContainerName).
 					WithFinalizer(finalizer).
 					WithStatusConditions(xpv1.Creating(), xpv1.ReconcileError(errBoom)).
+					WithStatusOperations(operation(v1alpha3.ContainerOperationCreate, v1alpha3.OperationFailed)).
+					Container,
+			},
+		},
+		{
+			name: "CreatedNotVisible",
+			fields: fields{
+				container: v1alpha3test.NewMockContainer(testContainerName).Container,
+				ContainerOperations: &azurestoragefake.MockContainerOperations{
+					MockCreate: func(ctx context.Context, pub azblob.PublicAccessType, meta azblob.Metadata) error {
+						return nil
+					},
+					MockWaitUntilExists: func(ctx context.Context, timeout time.Duration) error {
+						if timeout != visibleTimeout {
+							t.Errorf("WaitUntilExists(...): want timeout %s, got %s", visibleTimeout, timeout)
+						}
+						return errBoom
+					},
+				},
+				kube: test.NewMockClient(),
+			},
+			args: args{ctx: ctx},
+			want: want{
+				res: resultRequeue,
+				cont: v1alpha3test.NewMockContainer(testContainerName).
+					WithFinalizer(finalizer).
+					WithStatusConditions(xpv1.Creating(), xpv1.ReconcileError(errors.Wrap(errBoom, errAwaitVisible))).
+					WithStatusOperations(operation(v1alpha3.ContainerOperationCreate, v1alpha3.OperationSucceeded)).
 					Container,
 			},
 		},
@@ -799,6 +1003,7 @@ func Test_containerCreateUpdater_create(t *testing.T) {
 				cont: v1alpha3test.NewMockContainer(testContainerName).
 					WithFinalizer(finalizer).
 					WithStatusConditions(xpv1.Available(), xpv1.ReconcileSuccess()).
+					WithStatusOperations(operation(v1alpha3.ContainerOperationCreate, v1alpha3.OperationSucceeded)).
 					Container,
 			},
 		},
@@ -809,6 +1014,9 @@ func Test_containerCreateUpdater_create(t *testing.T) {
 				ContainerOperations: tt.fields.ContainerOperations,
 				kube:                tt.fields.kube,
 				container:           tt.fields.container,
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return newProvisionedManagementOperations(), nil
+				},
 			}
 			got, err := ccu.create(tt.args.ctx)
 			if diff := cmp.Diff(tt.want.err, err, test.EquateErrors()); diff != "" {
@@ -817,7 +1025,7 @@ func Test_containerCreateUpdater_create(t *testing.T) {
 			if diff := cmp.Diff(tt.want.res, got); diff != "" {
 				t.Errorf("containerCreateUpdater.create(): -want, +got:\n%s", diff)
 			}
-			if diff := cmp.Diff(tt.want.cont, tt.fields.container); diff != "" {
+			if diff := cmp.Diff(tt.want.cont, tt.fields.container, equateOperations); diff != "" {
 				t.Errorf("containerCreateUpdater.create() container: -want, +got:\n%s", diff)
 			}
 		})
@@ -828,10 +1036,14 @@ func Test_containerCreateUpdater_update(t *testing.T) {
 	ctx := context.TODO()
 	errBoom := errors.New("boom")
 
+	msManaged := v1alpha3.ContainerObservation{EncryptionKeyType: string(storage.EncryptionKeyMicrosoftManaged)}
+
 	type fields struct {
 		ContainerOperations storage.ContainerOperations
 		kube                client.Client
 		container           *v1alpha3.Container
+		account             *v1alpha3.Account
+		management          storage.ManagementOperations
 		poll                time.Duration
 	}
 	type args struct {
@@ -855,8 +1067,9 @@ func Test_containerCreateUpdater_update(t *testing.T) {
 			fields: fields{
 				container: v1alpha3test.NewMockContainer(testContainerName).
 					WithSpecPAC(azblob.PublicAccessContainer).Container,
-				kube: test.NewMockClient(),
-				poll: time.Minute,
+				ContainerOperations: azurestoragefake.NewMockContainerOperations(),
+				kube:                test.NewMockClient(),
+				poll:                time.Minute,
 			},
 			args: args{
 				ctx:        ctx,
@@ -867,6 +1080,7 @@ func Test_containerCreateUpdater_update(t *testing.T) {
 				cont: v1alpha3test.NewMockContainer(testContainerName).
 					WithSpecPAC(azblob.PublicAccessContainer).
 					WithStatusConditions(xpv1.Available(), xpv1.ReconcileSuccess()).
+					WithStatusAtProvider(msManaged).
 					Container,
 			},
 		},
@@ -881,6 +1095,9 @@ func Test_containerCreateUpdater_update(t *testing.T) {
 					MockUpdate: func(ctx context.Context, publicAccessType azblob.PublicAccessType, meta azblob.Metadata) error {
 						return errBoom
 					},
+					MockGetContainerProperties: func(ctx context.Context) (*storage.ContainerProperties, error) {
+						return &storage.ContainerProperties{}, nil
+					},
 				},
 				kube: test.NewMockClient(),
 			},
@@ -896,6 +1113,8 @@ func Test_containerCreateUpdater_update(t *testing.T) {
 				cont: v1alpha3test.NewMockContainer(testContainerName).
 					WithSpecPAC(azblob.PublicAccessContainer).
 					WithStatusConditions(xpv1.ReconcileError(errBoom)).
+					WithStatusDrift(&v1alpha3.ContainerDrift{MetadataAdded: []string{"foo"}}).
+					WithStatusOperations(operation(v1alpha3.ContainerOperationUpdate, v1alpha3.OperationFailed)).
 					Container,
 			},
 		},
@@ -921,6 +1140,108 @@ func Test_containerCreateUpdater_update(t *testing.T) {
 				cont: v1alpha3test.NewMockContainer(testContainerName).
 					WithSpecPAC(azblob.PublicAccessContainer).
 					WithStatusConditions(xpv1.Available(), xpv1.ReconcileSuccess()).
+					WithStatusAtProvider(msManaged).
+					WithStatusDrift(&v1alpha3.ContainerDrift{MetadataAdded: []string{"foo"}}).
+					WithStatusOperations(operation(v1alpha3.ContainerOperationUpdate, v1alpha3.OperationSucceeded)).
+					Container,
+			},
+		},
+		{
+			name: "ObserveAccountCustomerManagedKey",
+			fields: fields{
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).Container,
+				ContainerOperations: azurestoragefake.NewMockContainerOperations(),
+				account: v1alpha3test.NewMockAccount(testAccountName).
+					WithSpecStorageAccountSpec(&v1alpha3.StorageAccountSpec{
+						StorageAccountSpecProperties: &v1alpha3.StorageAccountSpecProperties{
+							Encryption: &v1alpha3.Encryption{KeySource: "Microsoft.Keyvault"},
+						},
+					}).Account,
+				kube: test.NewMockClient(),
+				poll: time.Minute,
+			},
+			args: args{
+				ctx:        ctx,
+				accessType: azurestoragefake.PublicAccessTypePtr(azblob.PublicAccessContainer),
+			},
+			want: want{
+				res: reconcile.Result{RequeueAfter: time.Minute},
+				cont: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).
+					WithStatusConditions(xpv1.Available(), xpv1.ReconcileSuccess()).
+					WithStatusAtProvider(v1alpha3.ContainerObservation{
+						EncryptionKeyType: string(storage.EncryptionKeyCustomerManaged),
+					}).
+					Container,
+			},
+		},
+		{
+			name: "ObserveEncryptionScope",
+			fields: fields{
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).
+					WithSpecEncryptionScope("cmk", false).Container,
+				ContainerOperations: &azurestoragefake.MockContainerOperations{
+					MockGetContainerProperties: func(ctx context.Context) (*storage.ContainerProperties, error) {
+						return &storage.ContainerProperties{DefaultEncryptionScope: "cmk"}, nil
+					},
+				},
+				management: &azurestoragefake.MockManagementOperations{
+					MockGetEncryptionScope: func(ctx context.Context, name string) (*mgmtstorage.EncryptionScope, error) {
+						return &mgmtstorage.EncryptionScope{EncryptionScopeProperties: &mgmtstorage.EncryptionScopeProperties{
+							Source: mgmtstorage.EncryptionScopeSourceMicrosoftKeyVault,
+						}}, nil
+					},
+				},
+				kube: test.NewMockClient(),
+				poll: time.Minute,
+			},
+			args: args{
+				ctx:        ctx,
+				accessType: azurestoragefake.PublicAccessTypePtr(azblob.PublicAccessContainer),
+			},
+			want: want{
+				res: reconcile.Result{RequeueAfter: time.Minute},
+				cont: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).
+					WithSpecEncryptionScope("cmk", false).
+					WithStatusConditions(xpv1.Available(), xpv1.ReconcileSuccess()).
+					WithStatusAtProvider(v1alpha3.ContainerObservation{
+						DefaultEncryptionScope: "cmk",
+						EncryptionKeyType:      string(storage.EncryptionKeyCustomerManaged),
+					}).
+					Container,
+			},
+		},
+		{
+			name: "ObserveEncryptionScopeFailed",
+			fields: fields{
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).
+					WithSpecEncryptionScope("cmk", false).Container,
+				ContainerOperations: &azurestoragefake.MockContainerOperations{
+					MockGetContainerProperties: func(ctx context.Context) (*storage.ContainerProperties, error) {
+						return &storage.ContainerProperties{DefaultEncryptionScope: "cmk"}, nil
+					},
+				},
+				management: &azurestoragefake.MockManagementOperations{
+					MockGetEncryptionScope: func(ctx context.Context, name string) (*mgmtstorage.EncryptionScope, error) {
+						return nil, errBoom
+					},
+				},
+				kube: test.NewMockClient(),
+			},
+			args: args{
+				ctx:        ctx,
+				accessType: azurestoragefake.PublicAccessTypePtr(azblob.PublicAccessContainer),
+			},
+			want: want{
+				res: resultRequeue,
+				cont: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).
+					WithSpecEncryptionScope("cmk", false).
+					WithStatusConditions(xpv1.ReconcileError(errors.Wrapf(errBoom, "cannot get encryption scope %s", "cmk"))).
 					Container,
 			},
 		},
@@ -931,7 +1252,11 @@ func Test_containerCreateUpdater_update(t *testing.T) {
 				ContainerOperations: tt.fields.ContainerOperations,
 				kube:                tt.fields.kube,
 				container:           tt.fields.container,
-				poll:                tt.fields.poll,
+				account:             tt.fields.account,
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return tt.fields.management, nil
+				},
+				poll: tt.fields.poll,
 			}
 			got, err := ccu.update(tt.args.ctx, tt.args.accessType, tt.args.meta)
 			if diff := cmp.Diff(tt.want.err, err, test.EquateErrors()); diff != "" {
@@ -940,9 +1265,3717 @@ func Test_containerCreateUpdater_update(t *testing.T) {
 			if diff := cmp.Diff(tt.want.res, got); diff != "" {
 				t.Errorf("containerCreateUpdater.update(): -want, +got:\n%s", diff)
 			}
-			if diff := cmp.Diff(tt.want.cont, tt.fields.container, test.EquateConditions()); diff != "" {
+			if diff := cmp.Diff(tt.want.cont, tt.fields.container, test.EquateConditions(), equateOperations); diff != "" {
 				t.Errorf("containerCreateUpdater.update() container: -want, +got:\n%s", diff)
 			}
 		})
 	}
 }
+
+// newObserveOnlyContainerOperations returns ContainerOperations that fail the
+// supplied test if any mutating operation is called.
+func newObserveOnlyContainerOperations(t *testing.T) *azurestoragefake.MockContainerOperations {
+	t.Helper()
+	o := azurestoragefake.NewMockContainerOperations()
+	o.MockCreate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+		t.Error("Create called in observe-only mode")
+		return nil
+	}
+	o.MockUpdate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+		t.Error("Update called in observe-only mode")
+		return nil
+	}
+	o.MockDelete = func(context.Context) error {
+		t.Error("Delete called in observe-only mode")
+		return nil
+	}
+	return o
+}
+
+func TestObserveOnly(t *testing.T) {
+	ctx := context.TODO()
+	msManaged := v1alpha3.ContainerObservation{EncryptionKeyType: string(storage.EncryptionKeyMicrosoftManaged)}
+
+	type args struct {
+		container *v1alpha3.Container
+		access    *azblob.PublicAccessType
+		meta      azblob.Metadata
+	}
+	type want struct {
+		res  reconcile.Result
+		cont *v1alpha3.Container
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotFound": {
+			reason: "A missing container should be reported rather than created.",
+			args: args{
+				container: v1alpha3test.NewMockContainer(testContainerName).Container,
+			},
+			want: want{
+				res: reconcile.Result{RequeueAfter: time.Minute},
+				cont: v1alpha3test.NewMockContainer(testContainerName).
+					WithStatusConditions(xpv1.Unavailable(), driftDetected([]string{"container does not exist"})).
+					Container,
+			},
+		},
+		"Drifted": {
+			reason: "Each field that differs from the desired state should be reported rather than updated.",
+			args: args{
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).
+					WithSpecMetadata(azblob.Metadata{"a": "1", "b": "2"}).
+					Container,
+				access: azurestoragefake.PublicAccessTypePtr(azblob.PublicAccessBlob),
+				meta:   azblob.Metadata{"b": "3", "c": "4"},
+			},
+			want: want{
+				res: reconcile.Result{RequeueAfter: time.Minute},
+				cont: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).
+					WithSpecMetadata(azblob.Metadata{"a": "1", "b": "2"}).
+					WithStatusConditions(xpv1.Available(), driftDetected([]string{
+						`publicAccessType: want "container", got "blob"`,
+						`metadata[a]: want "1", got none`,
+						`metadata[b]: want "2", got "3"`,
+						`metadata[c]: want none, got "4"`,
+					})).
+					WithStatusAtProvider(msManaged).
+					WithStatusDrift(&v1alpha3.ContainerDrift{
+						PublicAccessType: &v1alpha3.PublicAccessTypeDrift{Desired: "container", Observed: "blob"},
+						MetadataAdded:    []string{"c"},
+						MetadataRemoved:  []string{"a"},
+						MetadataChanged:  []string{"b"},
+					}).
+					Container,
+			},
+		},
+		"UpToDate": {
+			reason: "A container that matches its desired state should be reported as synced.",
+			args: args{
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).
+					Container,
+				access: azurestoragefake.PublicAccessTypePtr(azblob.PublicAccessContainer),
+			},
+			want: want{
+				res: reconcile.Result{RequeueAfter: time.Minute},
+				cont: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).
+					WithStatusConditions(xpv1.Available(), xpv1.ReconcileSuccess()).
+					WithStatusAtProvider(msManaged).
+					Container,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ops := newObserveOnlyContainerOperations(t)
+			ops.MockGet = func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+				return tc.args.access, tc.args.meta, nil
+			}
+			csd := &containerSyncdeleter{
+				createupdater: &containerCreateUpdater{
+					ContainerOperations: ops,
+					kube:                test.NewMockClient(),
+					container:           tc.args.container,
+					poll:                time.Minute,
+					observeOnly:         true,
+				},
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           tc.args.container,
+				observeOnly:         true,
+			}
+			got, err := csd.sync(ctx)
+			if err != nil {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.res, got); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cont, tc.args.container, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync() container: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+
+	t.Run("Delete", func(t *testing.T) {
+		c := v1alpha3test.NewMockContainer(testContainerName).
+			WithSpecDeletionPolicy(xpv1.DeletionDelete).
+			WithFinalizer(finalizer).
+			Container
+		csd := &containerSyncdeleter{
+			ContainerOperations: newObserveOnlyContainerOperations(t),
+			kube:                test.NewMockClient(),
+			container:           c,
+			observeOnly:         true,
+		}
+		if _, err := csd.delete(ctx); err != nil {
+			t.Errorf("containerSyncdeleter.delete(): unexpected error: %v", err)
+		}
+		want := v1alpha3test.NewMockContainer(testContainerName).
+			WithSpecDeletionPolicy(xpv1.DeletionDelete).
+			WithFinalizers([]string{}).
+			WithStatusConditions(xpv1.Deleting()).
+			Container
+		if diff := cmp.Diff(want, c, test.EquateConditions()); diff != "" {
+			t.Errorf("containerSyncdeleter.delete() container: -want, +got:\n%s", diff)
+		}
+	})
+}
+
+func TestDesired(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		p   v1alpha3.ContainerParameters
+		err error
+	}
+	cases := map[string]struct {
+		reason   string
+		kube     client.Client
+		defaults map[string]string
+		c        *v1alpha3.Container
+		want     want
+	}{
+		"NoMetadataFrom": {
+			reason: "The spec should be returned unchanged when no ConfigMap is referenced.",
+			c: v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecMetadata(azblob.Metadata{"a": "1"}).
+				Container,
+			want: want{
+				p: v1alpha3.ContainerParameters{Metadata: azblob.Metadata{"a": "1"}},
+			},
+		},
+		"InlineWins": {
+			reason: "ConfigMap data should be merged into the metadata, with inline keys taking precedence.",
+			kube: &test.MockClient{
+				MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+					if key != (client.ObjectKey{Namespace: testNamespace, Name: "shared"}) {
+						t.Errorf("unexpected ConfigMap %s", key)
+					}
+					obj.(*v1.ConfigMap).Data = map[string]string{"a": "cm", "b": "cm"}
+					return nil
+				},
+			},
+			c: v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecMetadata(azblob.Metadata{"a": "inline"}).
+				WithSpecMetadataFrom(testNamespace, "shared").
+				Container,
+			want: want{
+				p: v1alpha3.ContainerParameters{
+					Metadata:     azblob.Metadata{"a": "inline", "b": "cm"},
+					MetadataFrom: &v1alpha3.ConfigMapReference{Namespace: testNamespace, Name: "shared"},
+				},
+			},
+		},
+		"DefaultContentSettings": {
+			reason: "Default content settings should be kept in reserved metadata keys, and not rendered.",
+			c: v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecMetadata(azblob.Metadata{"a": "{{.Name}}", storage.MetadataKeyCacheControl: "stale"}).
+				WithSpecDefaultContentSettings("public, max-age=3600", `attachment; filename="{{.Name}}"`).
+				Container,
+			want: want{
+				p: v1alpha3.ContainerParameters{
+					Metadata: azblob.Metadata{
+						"a":                                   testContainerName,
+						storage.MetadataKeyCacheControl:       "public, max-age=3600",
+						storage.MetadataKeyContentDisposition: `attachment; filename="{{.Name}}"`,
+					},
+					DefaultContentSettings: &v1alpha3.ContentSettings{
+						CacheControl:       "public, max-age=3600",
+						ContentDisposition: `attachment; filename="{{.Name}}"`,
+					},
+				},
+			},
+		},
+		"BlankContentSetting": {
+			reason: "Default content settings that are set to blank values should be rejected.",
+			c: v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecDefaultContentSettings(" ", "").
+				Container,
+			want: want{
+				p: v1alpha3.ContainerParameters{
+					DefaultContentSettings: &v1alpha3.ContentSettings{CacheControl: " "},
+				},
+				err: errors.Errorf("invalid default %s: %s", "Cache-Control", "value must not be blank"),
+			},
+		},
+		"DefaultMetadata": {
+			reason: "Default metadata should be merged in and rendered, with the container's own keys taking precedence whatever their case.",
+			kube: &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					obj.(*v1.ConfigMap).Data = map[string]string{"team": "cm"}
+					return nil
+				},
+			},
+			defaults: map[string]string{"managedby": "crossplane", "Team": "platform", "stack": "{{.Name}}", "owner": "platform"},
+			c: v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecMetadata(azblob.Metadata{"Owner": "inline"}).
+				WithSpecMetadataFrom(testNamespace, "shared").
+				Container,
+			want: want{
+				p: v1alpha3.ContainerParameters{
+					Metadata:     azblob.Metadata{"managedby": "crossplane", "team": "cm", "stack": testContainerName, "Owner": "inline"},
+					MetadataFrom: &v1alpha3.ConfigMapReference{Namespace: testNamespace, Name: "shared"},
+				},
+			},
+		},
+		"MissingConfigMap": {
+			reason: "Errors getting the referenced ConfigMap should be returned.",
+			kube:   &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			c: v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecMetadataFrom(testNamespace, "shared").
+				Container,
+			want: want{
+				p: v1alpha3.ContainerParameters{
+					MetadataFrom: &v1alpha3.ConfigMapReference{Namespace: testNamespace, Name: "shared"},
+				},
+				err: errors.Wrapf(errBoom, errGetMetadata, types.NamespacedName{Namespace: testNamespace, Name: "shared"}),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ccu := &containerCreateUpdater{kube: tc.kube, container: tc.c, defaultMetadata: tc.defaults}
+			got, err := ccu.desired(context.TODO())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.desired(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.p, got); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.desired(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestContainersForConfigMap(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		want   []reconcile.Request
+	}{
+		"Referencing": {
+			reason: "Only the Containers indexed under the ConfigMap should be listed and requested.",
+			kube: &test.MockClient{
+				MockList: func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+					want := []client.ListOption{client.MatchingFields{metadataFromIndex: testNamespace + "/shared"}}
+					if diff := cmp.Diff(want, opts); diff != "" {
+						t.Errorf("List(...): -want options, +got options:\n%s", diff)
+					}
+					obj.(*v1alpha3.ContainerList).Items = []v1alpha3.Container{
+						*v1alpha3test.NewMockContainer("referencing").WithSpecMetadataFrom(testNamespace, "shared").Container,
+					}
+					return nil
+				},
+			},
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "referencing"}}},
+		},
+		"ListFailed": {
+			reason: "Nothing should be requested if the Containers cannot be listed.",
+			kube:   &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "shared"}}
+			if diff := cmp.Diff(tc.want, containersForConfigMap(tc.kube)(cm)); diff != "" {
+				t.Errorf("\n%s\ncontainersForConfigMap(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIndexMetadataFrom(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		o      client.Object
+		want   []string
+	}{
+		"Referencing": {
+			reason: "Containers should be indexed by the namespace and name of their metadata ConfigMap.",
+			o:      v1alpha3test.NewMockContainer("referencing").WithSpecMetadataFrom(testNamespace, "shared").Container,
+			want:   []string{testNamespace + "/shared"},
+		},
+		"Unreferencing": {
+			reason: "Containers that don't take their metadata from a ConfigMap should not be indexed.",
+			o:      v1alpha3test.NewMockContainer("unreferencing").Container,
+		},
+		"NotAContainer": {
+			reason: "Objects other than Containers should not be indexed.",
+			o:      &v1.ConfigMap{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, indexMetadataFrom(tc.o)); diff != "" {
+				t.Errorf("\n%s\nindexMetadataFrom(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateEncryptionScope(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type args struct {
+		spec   v1alpha3.ContainerParameters
+		p      *storage.ContainerProperties
+		set    error
+		ensure error
+	}
+	type want struct {
+		set     string
+		ensured string
+		p       *storage.ContainerProperties
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"AllowedRemoval": {
+			reason: "Removing the encryption scope should reset the container to the account's encryption scope.",
+			args: args{
+				p: &storage.ContainerProperties{DefaultEncryptionScope: "cmk"},
+			},
+			want: want{
+				set: storage.AccountEncryptionScope,
+				p:   &storage.ContainerProperties{DefaultEncryptionScope: storage.AccountEncryptionScope},
+			},
+		},
+		"BlockedRemoval": {
+			reason: "Removing the encryption scope should be blocked while encryption scope override is prevented.",
+			args: args{
+				p: &storage.ContainerProperties{DefaultEncryptionScope: "cmk", PreventEncryptionScopeOverride: true},
+			},
+			want: want{
+				err: errors.Errorf(errScopeRemovalBlocked, "cmk"),
+			},
+		},
+		"RemovalRejected": {
+			reason: "Errors removing the encryption scope should explain that it may be in use.",
+			args: args{
+				p:   &storage.ContainerProperties{DefaultEncryptionScope: "cmk"},
+				set: errBoom,
+			},
+			want: want{
+				set: storage.AccountEncryptionScope,
+				err: errors.Wrapf(errBoom, errRemoveScope, "cmk"),
+			},
+		},
+		"Change": {
+			reason: "Changing the encryption scope should set the desired scope.",
+			args: args{
+				spec: v1alpha3.ContainerParameters{DefaultEncryptionScope: "new"},
+				p:    &storage.ContainerProperties{DefaultEncryptionScope: "old"},
+			},
+			want: want{
+				set: "new",
+				p:   &storage.ContainerProperties{DefaultEncryptionScope: "new"},
+			},
+		},
+		"CreateScope": {
+			reason: "The desired scope should be created before it is set when the container asks for it.",
+			args: args{
+				spec: v1alpha3.ContainerParameters{DefaultEncryptionScope: "new", CreateEncryptionScope: true, EncryptionScopeKeyURI: "https://vault/keys/k"},
+				p:    &storage.ContainerProperties{DefaultEncryptionScope: "old"},
+			},
+			want: want{
+				set:     "new",
+				ensured: "new https://vault/keys/k",
+				p:       &storage.ContainerProperties{DefaultEncryptionScope: "new"},
+			},
+		},
+		"CreateScopeFailed": {
+			reason: "Errors creating the desired scope should be returned without setting it.",
+			args: args{
+				spec:   v1alpha3.ContainerParameters{DefaultEncryptionScope: "new", CreateEncryptionScope: true},
+				p:      &storage.ContainerProperties{DefaultEncryptionScope: "old"},
+				ensure: errBoom,
+			},
+			want: want{
+				ensured: "new ",
+				err:     errors.Wrapf(errBoom, errEnsureScope, "new"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			set, ensured := "", ""
+			ccu := &containerCreateUpdater{
+				ContainerOperations: &azurestoragefake.MockContainerOperations{
+					MockGetContainerProperties: func(ctx context.Context) (*storage.ContainerProperties, error) {
+						return &storage.ContainerProperties{DefaultEncryptionScope: set}, nil
+					},
+				},
+				container: v1alpha3test.NewMockContainer(testContainerName).Container,
+				account:   v1alpha3test.NewMockAccount(testAccountName).Account,
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return &azurestoragefake.MockManagementOperations{
+						MockSetContainerEncryptionScope: func(_ context.Context, _, scope string, _ bool) error {
+							set = scope
+							return tc.args.set
+						},
+						MockEnsureEncryptionScope: func(_ context.Context, _, _, scope, key string) error {
+							ensured = scope + " " + key
+							return tc.args.ensure
+						},
+					}, nil
+				},
+			}
+			got, err := ccu.updateEncryptionScope(context.TODO(), tc.args.spec, tc.args.p)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.updateEncryptionScope(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.set, set); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.updateEncryptionScope(): -want scope, +got scope:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ensured, ensured); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.updateEncryptionScope(): -want created scope, +got created scope:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.p, got); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.updateEncryptionScope(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateAwaitsAccountProvisioning(t *testing.T) {
+	ctx := context.TODO()
+
+	states := []mgmtstorage.ProvisioningState{mgmtstorage.ProvisioningStateCreating, mgmtstorage.ProvisioningStateSucceeded}
+	created := 0
+	c := v1alpha3test.NewMockContainer(testContainerName).Container
+	ccu := &containerCreateUpdater{
+		ContainerOperations: &azurestoragefake.MockContainerOperations{
+			MockCreate: func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+				created++
+				return nil
+			},
+			MockWaitUntilExists: func(context.Context, time.Duration) error { return nil },
+		},
+		kube:      test.NewMockClient(),
+		container: c,
+		management: func(context.Context) (storage.ManagementOperations, error) {
+			return &azurestoragefake.MockManagementOperations{
+				MockGetAccount: func(context.Context) (*mgmtstorage.Account, error) {
+					s := states[0]
+					states = states[1:]
+					return &mgmtstorage.Account{AccountProperties: &mgmtstorage.AccountProperties{ProvisioningState: s}}, nil
+				},
+			}, nil
+		},
+	}
+
+	// The account is still being provisioned, so we should requeue without
+	// attempting to create the container.
+	got, err := ccu.create(ctx)
+	if err != nil {
+		t.Fatalf("containerCreateUpdater.create(): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(resultRequeue, got); diff != "" {
+		t.Errorf("containerCreateUpdater.create(): -want, +got:\n%s", diff)
+	}
+	if created != 0 {
+		t.Errorf("containerCreateUpdater.create(): want no create while the account is provisioning, got %d", created)
+	}
+	want := xpv1.ReconcileError(errors.Errorf(errAccountNotProvisioned, mgmtstorage.ProvisioningStateCreating))
+	if diff := cmp.Diff(want, c.Status.GetCondition(xpv1.TypeSynced), test.EquateConditions()); diff != "" {
+		t.Errorf("containerCreateUpdater.create() condition: -want, +got:\n%s", diff)
+	}
+
+	// The account has since been provisioned, so the container should be
+	// created.
+	got, err = ccu.create(ctx)
+	if err != nil {
+		t.Fatalf("containerCreateUpdater.create(): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(reconcile.Result{}, got); diff != "" {
+		t.Errorf("containerCreateUpdater.create(): -want, +got:\n%s", diff)
+	}
+	if created != 1 {
+		t.Errorf("containerCreateUpdater.create(): want one create once the account is provisioned, got %d", created)
+	}
+}
+
+func TestManagementConnector(t *testing.T) {
+	ctx := context.TODO()
+	creds := `{
+		"subscriptionId": "sub",
+		"tenantId": "tenant",
+		"clientId": "client",
+		"clientSecret": "secret",
+		"activeDirectoryEndpointUrl": "https://login.example.com/",
+		"resourceManagerEndpointUrl": "https://management.example.com/"
+	}`
+
+	gets := 0
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			gets++
+			switch o := obj.(type) {
+			case *azurev1alpha3.Provider:
+				o.Spec.CredentialsSecretRef = xpv1.SecretKeySelector{Key: "creds"}
+			case *v1.Secret:
+				o.Data = map[string][]byte{"creds": []byte(creds)}
+			}
+			return nil
+		},
+	}
+	connect := newManagementConnector(kube, v1alpha3test.NewMockAccount(testAccountName).WithSpecProvider("provider").Account)
+
+	first, err := connect(ctx)
+	if err != nil {
+		t.Fatalf("newManagementConnector(...)(): %v", err)
+	}
+	second, err := connect(ctx)
+	if err != nil {
+		t.Fatalf("newManagementConnector(...)(): %v", err)
+	}
+	if first != second {
+		t.Errorf("newManagementConnector(...)(): want the same operations from every call")
+	}
+	if gets != 2 {
+		t.Errorf("newManagementConnector(...)(): want credentials read once, got %d reads", gets)
+	}
+}
+
+func TestAdoptionPolicy(t *testing.T) {
+	ctx := context.TODO()
+
+	type args struct {
+		container *v1alpha3.Container
+		exists    bool
+		access    azblob.PublicAccessType
+	}
+	type want struct {
+		updated bool
+		created bool
+		synced  xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"AdoptIfExists": {
+			reason: "An existing container should be adopted by default.",
+			args: args{
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecPAC(azblob.PublicAccessContainer).Container,
+				exists: true,
+				access: azblob.PublicAccessBlob,
+			},
+			want: want{updated: true, synced: xpv1.ReconcileSuccess()},
+		},
+		"FailIfExists": {
+			reason: "An existing container that Crossplane did not create should not be managed.",
+			args: args{
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecAdoptionPolicy(v1alpha3.FailIfExists).Container,
+				exists: true,
+			},
+			want: want{synced: xpv1.ReconcileError(&storage.AlreadyExistsError{Container: testContainerName})},
+		},
+		"FailIfExistsCreated": {
+			reason: "A container that Crossplane created should be managed.",
+			args: args{
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecAdoptionPolicy(v1alpha3.FailIfExists).
+					WithFinalizer(finalizer).Container,
+				exists: true,
+			},
+			want: want{synced: xpv1.ReconcileSuccess()},
+		},
+		"FailIfExistsCreatedConcurrently": {
+			reason: "A container that was created after it was found not to exist should not be managed.",
+			args: args{
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecAdoptionPolicy(v1alpha3.FailIfExists).Container,
+			},
+			want: want{synced: xpv1.ReconcileError(&storage.AlreadyExistsError{Container: testContainerName})},
+		},
+		"ManageExclusively": {
+			reason: "An existing container that Crossplane did not create should not be managed exclusively.",
+			args: args{
+				container: v1alpha3test.NewMockContainer(testContainerName).
+					WithSpecAdoptionPolicy(v1alpha3.ManageExclusively).Container,
+				exists: true,
+			},
+			want: want{synced: xpv1.ReconcileError(&storage.AlreadyExistsError{Container: testContainerName})},
+		},
+		"ManageExclusivelyExternalChange": {
+			reason: "Drift from an already applied spec should be corrected and reported as an external change.",
+			args: args{
+				container: func() *v1alpha3.Container {
+					c := v1alpha3test.NewMockContainer(testContainerName).
+						WithSpecAdoptionPolicy(v1alpha3.ManageExclusively).
+						WithSpecPAC(azblob.PublicAccessContainer).
+						WithFinalizer(finalizer).Container
+					c.Generation, c.Status.ObservedGeneration = 2, 2
+					return c
+				}(),
+				exists: true,
+				access: azblob.PublicAccessBlob,
+			},
+			want: want{
+				updated: true,
+				synced: xpv1.ReconcileError(&storage.ExternalChangeError{
+					Container: testContainerName,
+					Changes:   []string{`publicAccessType: want "container", got "blob"`},
+				}),
+			},
+		},
+		"ManageExclusivelySpecChange": {
+			reason: "Drift from a spec that has not yet been applied should not be reported as an external change.",
+			args: args{
+				container: func() *v1alpha3.Container {
+					c := v1alpha3test.NewMockContainer(testContainerName).
+						WithSpecAdoptionPolicy(v1alpha3.ManageExclusively).
+						WithSpecPAC(azblob.PublicAccessContainer).
+						WithFinalizer(finalizer).Container
+					c.Generation, c.Status.ObservedGeneration = 3, 2
+					return c
+				}(),
+				exists: true,
+				access: azblob.PublicAccessBlob,
+			},
+			want: want{updated: true, synced: xpv1.ReconcileSuccess()},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			updated, created := false, false
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockGet = func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+				if !tc.args.exists {
+					return nil, nil, nil
+				}
+				return azurestoragefake.PublicAccessTypePtr(tc.args.access), nil, nil
+			}
+			ops.MockExists = func(context.Context) (bool, error) { return true, nil }
+			ops.MockUpdate = func(_ context.Context, access azblob.PublicAccessType, _ azblob.Metadata) error {
+				updated = true
+				ops.MockGetContainerProperties = func(context.Context) (*storage.ContainerProperties, error) {
+					return &storage.ContainerProperties{PublicAccessType: access}, nil
+				}
+				return nil
+			}
+			ops.MockCreate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+				created = true
+				return nil
+			}
+			csd := &containerSyncdeleter{
+				createupdater: &containerCreateUpdater{
+					ContainerOperations: ops,
+					kube:                test.NewMockClient(),
+					container:           tc.args.container,
+					management: func(context.Context) (storage.ManagementOperations, error) {
+						return newProvisionedManagementOperations(), nil
+					},
+				},
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           tc.args.container,
+			}
+			if _, err := csd.sync(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.sync(): unexpected error: %v", tc.reason, err)
+			}
+			if updated != tc.want.updated {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want updated %t, got %t", tc.reason, tc.want.updated, updated)
+			}
+			if created != tc.want.created {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want created %t, got %t", tc.reason, tc.want.created, created)
+			}
+			got := tc.args.container.Status.GetCondition(xpv1.TypeSynced)
+			if diff := cmp.Diff(tc.want.synced, got, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPublicAccessPolicy(t *testing.T) {
+	ctx := context.TODO()
+
+	type args struct {
+		env    v1beta1.Environment
+		access azblob.PublicAccessType
+	}
+	type want struct {
+		created bool
+		synced  xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ProductionRejectsBlob": {
+			reason: "Production containers should not be permitted blob public access.",
+			args:   args{env: v1beta1.EnvironmentProduction, access: azblob.PublicAccessBlob},
+			want: want{synced: xpv1.ReconcileError(errors.Errorf(errPublicAccessDenied,
+				`"blob"`, v1beta1.EnvironmentProduction, "None"))},
+		},
+		"ProductionAllowsNone": {
+			reason: "Production containers without public access should be created.",
+			args:   args{env: v1beta1.EnvironmentProduction, access: azblob.PublicAccessNone},
+			want:   want{created: true, synced: xpv1.ReconcileSuccess()},
+		},
+		"DevelopmentAllowsBlob": {
+			reason: "Development containers should be permitted blob public access.",
+			args:   args{env: v1beta1.EnvironmentDevelopment, access: azblob.PublicAccessBlob},
+			want:   want{created: true, synced: xpv1.ReconcileSuccess()},
+		},
+		"NoEnvironment": {
+			reason: "Containers whose provider config has no environment should be permitted any public access.",
+			args:   args{access: azblob.PublicAccessContainer},
+			want:   want{created: true, synced: xpv1.ReconcileSuccess()},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			created := false
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockCreate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+				created = true
+				return nil
+			}
+			c := v1alpha3test.NewMockContainer(testContainerName).WithSpecPAC(tc.args.access).Container
+			csd := &containerSyncdeleter{
+				createupdater: &containerCreateUpdater{
+					ContainerOperations: ops,
+					kube:                test.NewMockClient(),
+					container:           c,
+					management: func(context.Context) (storage.ManagementOperations, error) {
+						return newProvisionedManagementOperations(), nil
+					},
+				},
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				environment:         tc.args.env,
+			}
+			if _, err := csd.sync(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.sync(): unexpected error: %v", tc.reason, err)
+			}
+			if created != tc.want.created {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want created %t, got %t", tc.reason, tc.want.created, created)
+			}
+			got := c.Status.GetCondition(xpv1.TypeSynced)
+			if diff := cmp.Diff(tc.want.synced, got, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestProviderConfig(t *testing.T) {
+	ctx := context.TODO()
+	spec := v1beta1.ProviderConfigSpec{
+		Environment:              v1beta1.EnvironmentProduction,
+		ContainerDefaultMetadata: map[string]string{"managedby": "crossplane"},
+	}
+	pc := &v1beta1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec:       spec,
+	}
+
+	type want struct {
+		spec v1beta1.ProviderConfigSpec
+		err  error
+	}
+	cases := map[string]struct {
+		reason string
+		ref    *xpv1.Reference
+		want   want
+	}{
+		"ProviderConfig": {
+			reason: "The spec of the account's provider config should be returned.",
+			ref:    &xpv1.Reference{Name: "prod"},
+			want:   want{spec: spec},
+		},
+		"NoProviderConfig": {
+			reason: "Accounts without a provider config should have an empty one, and so no environment.",
+		},
+		"MissingProviderConfig": {
+			reason: "Errors getting the account's provider config should be returned.",
+			ref:    &xpv1.Reference{Name: "missing"},
+			want: want{err: errors.Wrapf(kerrors.NewNotFound(schema.GroupResource{
+				Group:    v1beta1.Group,
+				Resource: "providerconfigs",
+			}, "missing"), errGetPC, "missing")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			acct := v1alpha3test.NewMockAccount(testAccountName).Account
+			acct.SetProviderConfigReference(tc.ref)
+			got, err := providerConfig(ctx, fake.NewClientBuilder().WithObjects(pc).Build(), acct)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nproviderConfig(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.spec, got); diff != "" {
+				t.Errorf("\n%s\nproviderConfig(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExternalModification(t *testing.T) {
+	ctx := context.TODO()
+
+	type args struct {
+		last     string
+		prior    *xpv1.Condition
+		etags    []azblob.ETag
+		access   azblob.PublicAccessType
+		observed azblob.PublicAccessType
+	}
+	type want struct {
+		etag      string
+		condition xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"FirstObservation": {
+			reason: "The ETag of a container that has not yet been observed should be recorded.",
+			args:   args{etags: []azblob.ETag{"0x1"}},
+			want:   want{etag: "0x1", condition: xpv1.Condition{Type: TypeExternallyModified, Status: v1.ConditionUnknown}},
+		},
+		"Unchanged": {
+			reason: "An unchanged ETag should not be reported as an external modification.",
+			args:   args{last: "0x1", etags: []azblob.ETag{"0x1"}},
+			want:   want{etag: "0x1", condition: xpv1.Condition{Type: TypeExternallyModified, Status: v1.ConditionUnknown}},
+		},
+		"ExternallyChanged": {
+			reason: "A changed ETag should be reported as an external modification.",
+			args:   args{last: "0x1", etags: []azblob.ETag{"0x2"}},
+			want:   want{etag: "0x2", condition: externallyModified("0x1", "0x2")},
+		},
+		"ChangedByUs": {
+			reason: "The ETag of our own change should be recorded and not reported as an external modification.",
+			args: args{
+				last:     "0x1",
+				etags:    []azblob.ETag{"0x1", "0x2"},
+				access:   azblob.PublicAccessBlob,
+				observed: azblob.PublicAccessNone,
+			},
+			want: want{etag: "0x2", condition: xpv1.Condition{Type: TypeExternallyModified, Status: v1.ConditionUnknown}},
+		},
+		"NoLongerChanged": {
+			reason: "A previously reported external modification should be cleared once the ETag is unchanged.",
+			args: args{
+				last:  "0x2",
+				prior: func() *xpv1.Condition { c := externallyModified("0x1", "0x2"); return &c }(),
+				etags: []azblob.ETag{"0x2"},
+			},
+			want: want{etag: "0x2", condition: notExternallyModified()},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).WithSpecPAC(tc.args.access).Container
+			c.Status.AtProvider.ETag = tc.args.last
+			if tc.args.prior != nil {
+				c.Status.SetConditions(*tc.args.prior)
+			}
+
+			etags := tc.args.etags
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockGetContainerProperties = func(context.Context) (*storage.ContainerProperties, error) {
+				e := etags[0]
+				if len(etags) > 1 {
+					etags = etags[1:]
+				}
+				return &storage.ContainerProperties{ETag: e, PublicAccessType: tc.args.access}, nil
+			}
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+			}
+			if _, err := ccu.update(ctx, &tc.args.observed, nil); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if c.Status.AtProvider.ETag != tc.want.etag {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want ETag %q, got %q", tc.reason, tc.want.etag, c.Status.AtProvider.ETag)
+			}
+			got := c.Status.GetCondition(TypeExternallyModified)
+			if diff := cmp.Diff(tc.want.condition, got, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestReconcileJitter(t *testing.T) {
+	ctx := context.TODO()
+	poll := time.Minute
+
+	cases := map[string]struct {
+		reason string
+		jitter float64
+	}{
+		"NoJitter": {
+			reason: "Containers should be requeued after exactly the poll interval when jitter is disabled.",
+		},
+		"DefaultJitter": {
+			reason: "Containers should be requeued within the default jitter of the poll interval.",
+			jitter: DefaultReconcileJitter,
+		},
+		"WideJitter": {
+			reason: "Containers should be requeued within a wide jitter of the poll interval.",
+			jitter: 0.5,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			lo := time.Duration(float64(poll) * (1 - tc.jitter))
+			hi := time.Duration(float64(poll) * (1 + tc.jitter))
+			for i := 0; i < 100; i++ {
+				ccu := &containerCreateUpdater{
+					ContainerOperations: azurestoragefake.NewMockContainerOperations(),
+					kube:                test.NewMockClient(),
+					container:           v1alpha3test.NewMockContainer(testContainerName).Container,
+					poll:                poll,
+					jitter:              tc.jitter,
+				}
+				none := azblob.PublicAccessNone
+				res, err := ccu.update(ctx, &none, nil)
+				if err != nil {
+					t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+				}
+				if res.RequeueAfter < lo || res.RequeueAfter > hi {
+					t.Fatalf("\n%s\ncontainerCreateUpdater.update(): want requeue within [%s, %s], got %s", tc.reason, lo, hi, res.RequeueAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestSetupWithJitter(t *testing.T) {
+	for _, j := range []float64{-0.1, 1, 1.5} {
+		if err := SetupWithJitter(j)(nil, controller.Options{}); err == nil {
+			t.Errorf("SetupWithJitter(%v): want error, got nil", j)
+		}
+	}
+}
+
+func TestContainerDrift(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		spec   v1alpha3.ContainerParameters
+		access azblob.PublicAccessType
+		md     azblob.Metadata
+		want   []string
+	}{
+		"NilVersusEmptyMetadata": {
+			reason: "Nil desired metadata should be equivalent to empty observed metadata.",
+			md:     azblob.Metadata{},
+			want:   []string{},
+		},
+		"EmptyVersusNilMetadata": {
+			reason: "Empty desired metadata should be equivalent to nil observed metadata.",
+			spec:   v1alpha3.ContainerParameters{Metadata: azblob.Metadata{}},
+			want:   []string{},
+		},
+		"NoneVersusEmptyAccess": {
+			reason: "A desired public access type of None should be equivalent to no observed public access.",
+			spec:   v1alpha3.ContainerParameters{PublicAccessType: "None"},
+			access: azblob.PublicAccessNone,
+			want:   []string{},
+		},
+		"CaseInsensitive": {
+			reason: "Public access types and metadata keys should be compared case insensitively.",
+			spec:   v1alpha3.ContainerParameters{PublicAccessType: "Blob", Metadata: azblob.Metadata{"Owner": "crossplane"}},
+			access: azblob.PublicAccessBlob,
+			md:     azblob.Metadata{"owner": "crossplane"},
+			want:   []string{},
+		},
+		"Drifted": {
+			reason: "Differences that remain after canonicalization should be reported.",
+			spec:   v1alpha3.ContainerParameters{PublicAccessType: "None", Metadata: azblob.Metadata{"a": "1"}},
+			access: azblob.PublicAccessContainer,
+			md:     azblob.Metadata{"a": "2", "b": "3"},
+			want: []string{
+				`publicAccessType: want "", got "container"`,
+				`metadata[a]: want "1", got "2"`,
+				`metadata[b]: want none, got "3"`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := containerDrift(tc.spec, tc.access, tc.md)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ncontainerDrift(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConcatDrift(t *testing.T) {
+	drift := make([]string, 1, 2)
+	drift[0] = "metadata"
+	all := concatDrift(drift, nil, []string{"defaultEncryptionScope"}, []string{"legalHold"})
+	if diff := cmp.Diff([]string{"metadata", "defaultEncryptionScope", "legalHold"}, all); diff != "" {
+		t.Errorf("concatDrift(...): -want, +got:\n%s", diff)
+	}
+	all[0] = "changed"
+	if drift[0] != "metadata" {
+		t.Errorf("concatDrift(...): want a new slice, got one that shares storage with its first argument")
+	}
+}
+
+func TestDriftReport(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		spec    v1alpha3.ContainerParameters
+		access  azblob.PublicAccessType
+		md      azblob.Metadata
+		managed []string
+		want    *v1alpha3.ContainerDrift
+	}{
+		"UpToDate": {
+			reason: "A container that matches its desired state once canonicalized should report no drift.",
+			spec:   v1alpha3.ContainerParameters{PublicAccessType: "None", Metadata: azblob.Metadata{"Owner": "crossplane"}},
+			access: azblob.PublicAccessNone,
+			md:     azblob.Metadata{"owner": "crossplane"},
+		},
+		"PublicAccessType": {
+			reason: "A drifted public access type should be reported with its desired and observed values.",
+			spec:   v1alpha3.ContainerParameters{PublicAccessType: "None"},
+			access: azblob.PublicAccessContainer,
+			want: &v1alpha3.ContainerDrift{
+				PublicAccessType: &v1alpha3.PublicAccessTypeDrift{Desired: "None", Observed: "container"},
+			},
+		},
+		"Metadata": {
+			reason: "Metadata keys that were added, removed, or changed should be reported by key, in order.",
+			spec:   v1alpha3.ContainerParameters{Metadata: azblob.Metadata{"a": "1", "b": "2", "c": "3"}},
+			md:     azblob.Metadata{"b": "2", "c": "4", "e": "5", "d": "6"},
+			want: &v1alpha3.ContainerDrift{
+				MetadataAdded:   []string{"d", "e"},
+				MetadataRemoved: []string{"a"},
+				MetadataChanged: []string{"c"},
+			},
+		},
+		"ManagedMetadata": {
+			reason:  "Drift of managed metadata keys should be reported as platform drift rather than as drift from the spec.",
+			spec:    v1alpha3.ContainerParameters{Metadata: azblob.Metadata{"owner": "team", "managed-by": "crossplane", "cost-center": "42"}},
+			md:      azblob.Metadata{"owner": "other", "Tier": "gold"},
+			managed: []string{"Managed-By", "cost-center", "tier"},
+			want: &v1alpha3.ContainerDrift{
+				MetadataChanged:  []string{"owner"},
+				PlatformMetadata: []string{"cost-center", "managed-by", "tier"},
+			},
+		},
+		"ManagedMetadataUpToDate": {
+			reason:  "Managed metadata keys that have not drifted should not be reported.",
+			spec:    v1alpha3.ContainerParameters{Metadata: azblob.Metadata{"managed-by": "crossplane"}},
+			md:      azblob.Metadata{"managed-by": "crossplane"},
+			managed: []string{"managed-by"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := driftReport(tc.spec, tc.access, tc.md, tc.managed)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ndriftReport(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDriftReportCleared(t *testing.T) {
+	c := v1alpha3test.NewMockContainer(testContainerName).
+		WithSpecPAC(azblob.PublicAccessContainer).
+		WithStatusDrift(&v1alpha3.ContainerDrift{MetadataAdded: []string{"foo"}}).
+		Container
+	ccu := &containerCreateUpdater{
+		ContainerOperations: azurestoragefake.NewMockContainerOperations(),
+		kube:                test.NewMockClient(),
+		container:           c,
+	}
+
+	access := azblob.PublicAccessContainer
+	if _, err := ccu.update(context.TODO(), &access, nil); err != nil {
+		t.Fatalf("containerCreateUpdater.update(): unexpected error: %v", err)
+	}
+	if c.Status.Drift != nil {
+		t.Errorf("containerCreateUpdater.update(): want drift cleared, got %+v", c.Status.Drift)
+	}
+}
+
+func TestContentSettingsDrift(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		md     azblob.Metadata
+		want   []string
+	}{
+		"InSync": {
+			reason: "Observed default content settings that match the spec should not drift.",
+			md:     azblob.Metadata{storage.MetadataKeyCacheControl: "no-cache"},
+			want:   []string{},
+		},
+		"Changed": {
+			reason: "Observed default content settings that differ from the spec should drift.",
+			md:     azblob.Metadata{storage.MetadataKeyCacheControl: "max-age=60"},
+			want:   []string{`metadata[defaultcachecontrol]: want "no-cache", got "max-age=60"`},
+		},
+		"Missing": {
+			reason: "Missing default content settings should drift.",
+			want:   []string{`metadata[defaultcachecontrol]: want "no-cache", got none`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ccu := &containerCreateUpdater{container: v1alpha3test.NewMockContainer(testContainerName).WithSpecDefaultContentSettings("no-cache", "").Container}
+			spec, err := ccu.desired(context.TODO())
+			if err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.desired(): %v", tc.reason, err)
+			}
+			got := containerDrift(spec, azblob.PublicAccessNone, tc.md)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ncontainerDrift(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDefaultMetadataDrift(t *testing.T) {
+	ctx := context.TODO()
+	defaults := map[string]string{"managedby": "crossplane"}
+
+	type want struct {
+		updated azblob.Metadata
+	}
+	cases := map[string]struct {
+		reason   string
+		spec     azblob.Metadata
+		observed azblob.Metadata
+		want     want
+	}{
+		"InSync": {
+			reason:   "A container that carries the default metadata should not be updated.",
+			spec:     azblob.Metadata{"owner": "someone"},
+			observed: azblob.Metadata{"owner": "someone", "managedby": "crossplane"},
+		},
+		"RemovedExternally": {
+			reason:   "Default metadata that was removed outside of Crossplane should be restored.",
+			spec:     azblob.Metadata{"owner": "someone"},
+			observed: azblob.Metadata{"owner": "someone"},
+			want:     want{updated: azblob.Metadata{"owner": "someone", "managedby": "crossplane"}},
+		},
+		"Overridden": {
+			reason:   "Default metadata overridden by the container should not be restored.",
+			spec:     azblob.Metadata{"ManagedBy": "someone"},
+			observed: azblob.Metadata{"managedby": "someone"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var updated azblob.Metadata
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockUpdate = func(_ context.Context, _ azblob.PublicAccessType, md azblob.Metadata) error {
+				updated = md
+				return nil
+			}
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           v1alpha3test.NewMockContainer(testContainerName).WithSpecMetadata(tc.spec).Container,
+				defaultMetadata:     defaults,
+			}
+			none := azblob.PublicAccessNone
+			if _, err := ccu.update(ctx, &none, tc.observed); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.updated, updated); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): -want updated metadata, +got updated metadata:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAllowedMetadataKeys(t *testing.T) {
+	ctx := context.TODO()
+	allowed := []string{"owner", "CostCenter"}
+
+	type want struct {
+		written bool
+		synced  xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason  string
+		allowed []string
+		spec    azblob.Metadata
+		want    want
+	}{
+		"Allowed": {
+			reason:  "Containers whose metadata keys are all allowed, ignoring case, should be written.",
+			allowed: allowed,
+			spec:    azblob.Metadata{"owner": "someone", "costcenter": "42"},
+			want:    want{written: true, synced: xpv1.ReconcileSuccess()},
+		},
+		"Disallowed": {
+			reason:  "Containers with a metadata key that is not allowed should be rejected with an error naming it.",
+			allowed: allowed,
+			spec:    azblob.Metadata{"owner": "someone", "team": "storage"},
+			want:    want{synced: xpv1.ReconcileError(errors.Errorf(errMetadataKeyDenied, "team", "owner, CostCenter"))},
+		},
+		"EmptyAllowlist": {
+			reason: "Containers should be permitted any metadata key when no keys are allowed explicitly.",
+			spec:   azblob.Metadata{"team": "storage"},
+			want:   want{written: true, synced: xpv1.ReconcileSuccess()},
+		},
+	}
+
+	for name, tc := range cases {
+		for _, op := range []string{"Create", "Update"} {
+			t.Run(name+op, func(t *testing.T) {
+				written := false
+				ops := azurestoragefake.NewMockContainerOperations()
+				ops.MockCreate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+					written = true
+					return nil
+				}
+				ops.MockUpdate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+					written = true
+					return nil
+				}
+				c := v1alpha3test.NewMockContainer(testContainerName).WithSpecMetadata(tc.spec).Container
+				ccu := &containerCreateUpdater{
+					ContainerOperations: ops,
+					kube:                test.NewMockClient(),
+					container:           c,
+					management: func(context.Context) (storage.ManagementOperations, error) {
+						return newProvisionedManagementOperations(), nil
+					},
+					allowedMetadataKeys: tc.allowed,
+				}
+				var err error
+				if op == "Create" {
+					_, err = ccu.create(ctx)
+				} else {
+					none := azblob.PublicAccessNone
+					_, err = ccu.update(ctx, &none, nil)
+				}
+				if err != nil {
+					t.Fatalf("\n%s\ncontainerCreateUpdater.%s(): unexpected error: %v", tc.reason, strings.ToLower(op), err)
+				}
+				if written != tc.want.written {
+					t.Errorf("\n%s\ncontainerCreateUpdater.%s(): want written %t, got %t", tc.reason, strings.ToLower(op), tc.want.written, written)
+				}
+				got := c.Status.GetCondition(xpv1.TypeSynced)
+				if diff := cmp.Diff(tc.want.synced, got, test.EquateConditions()); diff != "" {
+					t.Errorf("\n%s\ncontainerCreateUpdater.%s(): -want, +got:\n%s", tc.reason, strings.ToLower(op), diff)
+				}
+			})
+		}
+	}
+}
+
+func TestMaxMetadataValueLength(t *testing.T) {
+	ctx := context.TODO()
+	const max = 16
+
+	type want struct {
+		written bool
+		synced  xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason string
+		max    int
+		value  string
+		want   want
+	}{
+		"JustUnderLimit": {
+			reason: "Containers whose metadata values are shorter than the limit should be written.",
+			max:    max,
+			value:  strings.Repeat("a", max-1),
+			want:   want{written: true, synced: xpv1.ReconcileSuccess()},
+		},
+		"AtLimit": {
+			reason: "Containers whose metadata values are exactly as long as the limit should be written.",
+			max:    max,
+			value:  strings.Repeat("a", max),
+			want:   want{written: true, synced: xpv1.ReconcileSuccess()},
+		},
+		"JustOverLimit": {
+			reason: "Containers with a metadata value longer than the limit should be rejected with an error naming the key and its length.",
+			max:    max,
+			value:  strings.Repeat("a", max+1),
+			want:   want{synced: xpv1.ReconcileError(errors.Errorf(errMetadataValueTooLong, "owner", max+1, max))},
+		},
+		"NoLimit": {
+			reason: "Metadata values should not be limited when the limit is zero.",
+			value:  strings.Repeat("a", 8*1024),
+			want:   want{written: true, synced: xpv1.ReconcileSuccess()},
+		},
+	}
+
+	for name, tc := range cases {
+		for _, op := range []string{"Create", "Update"} {
+			t.Run(name+op, func(t *testing.T) {
+				written := false
+				ops := azurestoragefake.NewMockContainerOperations()
+				ops.MockCreate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+					written = true
+					return nil
+				}
+				ops.MockUpdate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+					written = true
+					return nil
+				}
+				c := v1alpha3test.NewMockContainer(testContainerName).WithSpecMetadata(azblob.Metadata{"owner": tc.value}).Container
+				ccu := &containerCreateUpdater{
+					ContainerOperations: ops,
+					kube:                test.NewMockClient(),
+					container:           c,
+					management: func(context.Context) (storage.ManagementOperations, error) {
+						return newProvisionedManagementOperations(), nil
+					},
+					maxValueLength: tc.max,
+				}
+				var err error
+				if op == "Create" {
+					_, err = ccu.create(ctx)
+				} else {
+					none := azblob.PublicAccessNone
+					_, err = ccu.update(ctx, &none, nil)
+				}
+				if err != nil {
+					t.Fatalf("\n%s\ncontainerCreateUpdater.%s(): unexpected error: %v", tc.reason, strings.ToLower(op), err)
+				}
+				if written != tc.want.written {
+					t.Errorf("\n%s\ncontainerCreateUpdater.%s(): want written %t, got %t", tc.reason, strings.ToLower(op), tc.want.written, written)
+				}
+				got := c.Status.GetCondition(xpv1.TypeSynced)
+				if diff := cmp.Diff(tc.want.synced, got, test.EquateConditions()); diff != "" {
+					t.Errorf("\n%s\ncontainerCreateUpdater.%s(): -want, +got:\n%s", tc.reason, strings.ToLower(op), diff)
+				}
+			})
+		}
+	}
+}
+
+func TestAllowedLocations(t *testing.T) {
+	ctx := context.TODO()
+	allowed := []string{"westeurope", "North Europe"}
+
+	type want struct {
+		written bool
+		synced  xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason   string
+		allowed  []string
+		location string
+		want     want
+	}{
+		"Allowed": {
+			reason:   "Containers in accounts in an allowed location, ignoring case and spaces, should be written.",
+			allowed:  allowed,
+			location: "northeurope",
+			want:     want{written: true, synced: xpv1.ReconcileSuccess()},
+		},
+		"Disallowed": {
+			reason:   "Containers in accounts outside the allowed locations should be rejected with an error naming the location.",
+			allowed:  allowed,
+			location: "eastus",
+			want:     want{synced: xpv1.ReconcileError(errors.Errorf(errLocationDenied, "eastus", "westeurope, North Europe"))},
+		},
+		"EmptyAllowlist": {
+			reason:   "Containers should be permitted in accounts in any location when no locations are allowed explicitly.",
+			location: "eastus",
+			want:     want{written: true, synced: xpv1.ReconcileSuccess()},
+		},
+	}
+
+	for name, tc := range cases {
+		for _, op := range []string{"Create", "Update"} {
+			t.Run(name+op, func(t *testing.T) {
+				written := false
+				ops := azurestoragefake.NewMockContainerOperations()
+				ops.MockCreate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+					written = true
+					return nil
+				}
+				ops.MockUpdate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+					written = true
+					return nil
+				}
+				reads := 0
+				m := newProvisionedManagementOperations()
+				m.MockGetAccount = func(context.Context) (*mgmtstorage.Account, error) {
+					reads++
+					return &mgmtstorage.Account{
+						Location:          to.StringPtr(tc.location),
+						AccountProperties: &mgmtstorage.AccountProperties{ProvisioningState: mgmtstorage.ProvisioningStateSucceeded},
+					}, nil
+				}
+				cached := storage.NewAccountCachingManagementOperations(m, testAccountName)
+				c := v1alpha3test.NewMockContainer(testContainerName).WithSpecMetadata(azblob.Metadata{"owner": "someone"}).Container
+				ccu := &containerCreateUpdater{
+					ContainerOperations: ops,
+					kube:                test.NewMockClient(),
+					container:           c,
+					management: func(context.Context) (storage.ManagementOperations, error) {
+						return cached, nil
+					},
+					allowedLocations: tc.allowed,
+				}
+				var err error
+				if op == "Create" {
+					_, err = ccu.create(ctx)
+				} else {
+					none := azblob.PublicAccessNone
+					_, err = ccu.update(ctx, &none, nil)
+				}
+				if err != nil {
+					t.Fatalf("\n%s\ncontainerCreateUpdater.%s(): unexpected error: %v", tc.reason, strings.ToLower(op), err)
+				}
+				if written != tc.want.written {
+					t.Errorf("\n%s\ncontainerCreateUpdater.%s(): want written %t, got %t", tc.reason, strings.ToLower(op), tc.want.written, written)
+				}
+				if reads > 1 {
+					t.Errorf("\n%s\ncontainerCreateUpdater.%s(): want the account read at most once, got %d reads", tc.reason, strings.ToLower(op), reads)
+				}
+				got := c.Status.GetCondition(xpv1.TypeSynced)
+				if diff := cmp.Diff(tc.want.synced, got, test.EquateConditions()); diff != "" {
+					t.Errorf("\n%s\ncontainerCreateUpdater.%s(): -want, +got:\n%s", tc.reason, strings.ToLower(op), diff)
+				}
+			})
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+
+	snap := storage.ContainerSnapshot{
+		ContainerProperties: storage.ContainerProperties{
+			PublicAccessType:               azblob.PublicAccessBlob,
+			Metadata:                       azblob.Metadata{"owner": "someone", "template": "{{.Name}}"},
+			ETag:                           "0x1",
+			DefaultEncryptionScope:         "cmk",
+			PreventEncryptionScopeOverride: true,
+		},
+	}
+
+	type want struct {
+		spec      v1alpha3.ContainerParameters
+		finalizer bool
+		etag      string
+		synced    xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason    string
+		container *v1alpha3.Container
+		importErr error
+		want      want
+	}{
+		"Imported": {
+			reason:    "Every observable field should be backfilled into an unset spec, and the container marked as managed.",
+			container: v1alpha3test.NewMockContainer(testContainerName).WithSpecAdoptionPolicy(v1alpha3.Import).Container,
+			want: want{
+				spec: v1alpha3.ContainerParameters{
+					AdoptionPolicy:                 v1alpha3.Import,
+					PublicAccessType:               azblob.PublicAccessBlob,
+					Metadata:                       azblob.Metadata{"owner": "someone", "template": `\{{.Name}}`},
+					DefaultEncryptionScope:         "cmk",
+					PreventEncryptionScopeOverride: true,
+				},
+				finalizer: true,
+				etag:      "0x1",
+				synced:    xpv1.ReconcileSuccess(),
+			},
+		},
+		"SpecWins": {
+			reason: "Fields that are already set in the spec should not be backfilled.",
+			container: v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecAdoptionPolicy(v1alpha3.Import).
+				WithSpecPAC(azblob.PublicAccessContainer).
+				WithSpecMetadata(azblob.Metadata{"owner": "crossplane"}).
+				WithSpecEncryptionScope("other", false).Container,
+			want: want{
+				spec: v1alpha3.ContainerParameters{
+					AdoptionPolicy:                 v1alpha3.Import,
+					PublicAccessType:               azblob.PublicAccessContainer,
+					Metadata:                       azblob.Metadata{"owner": "crossplane"},
+					DefaultEncryptionScope:         "other",
+					PreventEncryptionScopeOverride: true,
+				},
+				finalizer: true,
+				etag:      "0x1",
+				synced:    xpv1.ReconcileSuccess(),
+			},
+		},
+		"ImportFailed": {
+			reason:    "Errors importing the container should be reported without marking it as managed.",
+			container: v1alpha3test.NewMockContainer(testContainerName).WithSpecAdoptionPolicy(v1alpha3.Import).Container,
+			importErr: errBoom,
+			want: want{
+				spec:   v1alpha3.ContainerParameters{AdoptionPolicy: v1alpha3.Import},
+				synced: xpv1.ReconcileError(errors.Wrap(errBoom, errImport)),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mutate := func(op string) {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): unexpected mutating call %s", tc.reason, op)
+			}
+			ops := &azurestoragefake.MockContainerOperations{
+				MockGet: func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+					return azurestoragefake.PublicAccessTypePtr(snap.PublicAccessType), snap.Metadata, nil
+				},
+				MockImport: func(context.Context) (storage.ContainerSnapshot, error) { return snap, tc.importErr },
+				MockCreate: func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+					mutate("Create")
+					return nil
+				},
+				MockUpdate: func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+					mutate("Update")
+					return nil
+				},
+				MockDelete: func(context.Context) error {
+					mutate("Delete")
+					return nil
+				},
+			}
+			csd := &containerSyncdeleter{
+				createupdater: &containerCreateUpdater{
+					ContainerOperations: ops,
+					container:           tc.container,
+					management: func(context.Context) (storage.ManagementOperations, error) {
+						mutate("management")
+						return nil, errBoom
+					},
+				},
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           tc.container,
+			}
+			if _, err := csd.sync(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.sync(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.spec, tc.container.Spec.ContainerParameters); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): -want spec, +got spec:\n%s", tc.reason, diff)
+			}
+			if got := meta.FinalizerExists(tc.container, finalizer); got != tc.want.finalizer {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want finalizer %t, got %t", tc.reason, tc.want.finalizer, got)
+			}
+			if got := tc.container.Status.AtProvider.ETag; got != tc.want.etag {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want ETag %q, got %q", tc.reason, tc.want.etag, got)
+			}
+			got := tc.container.Status.GetCondition(xpv1.TypeSynced)
+			if diff := cmp.Diff(tc.want.synced, got, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestServiceCache(t *testing.T) {
+	c := &serviceCache{}
+	a, err := c.get(testAccountName, "dGVzdC1rZXkK", storage.ContainerHandleOptions{})
+	if err != nil {
+		t.Fatalf("serviceCache.get(...): %v", err)
+	}
+	if again, _ := c.get(testAccountName, "dGVzdC1rZXkK", storage.ContainerHandleOptions{}); again != a {
+		t.Errorf("serviceCache.get(...): want the cached service for the same account and key")
+	}
+	if limited, _ := c.get(testAccountName, "dGVzdC1rZXkK", storage.ContainerHandleOptions{MaxConcurrentRequests: 2}); limited == a {
+		t.Errorf("serviceCache.get(...): want a new service when the options change")
+	}
+	if rotated, _ := c.get(testAccountName, "cm90YXRlZAo=", storage.ContainerHandleOptions{}); rotated == a {
+		t.Errorf("serviceCache.get(...): want a new service when the account key changes")
+	}
+	if other, _ := c.get("other", "dGVzdC1rZXkK", storage.ContainerHandleOptions{}); other == a {
+		t.Errorf("serviceCache.get(...): want a different service for a different account")
+	}
+
+	var none *serviceCache
+	x, _ := none.get(testAccountName, "dGVzdC1rZXkK", storage.ContainerHandleOptions{})
+	if y, _ := none.get(testAccountName, "dGVzdC1rZXkK", storage.ContainerHandleOptions{}); x == y {
+		t.Errorf("serviceCache.get(...): want a nil cache to cache nothing")
+	}
+}
+
+func TestDeletionProtection(t *testing.T) {
+	ctx := context.TODO()
+
+	type want struct {
+		res       reconcile.Result
+		deleted   bool
+		finalizer bool
+		protected bool
+		synced    xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason    string
+		protected bool
+		want      want
+	}{
+		"Protected": {
+			reason:    "A protected container should not be deleted, and should keep its finalizer.",
+			protected: true,
+			want: want{
+				res:       resultRequeue,
+				finalizer: true,
+				protected: true,
+				synced:    xpv1.ReconcileError(errors.Errorf(errDeletionProtected, testContainerName)),
+			},
+		},
+		"Unprotected": {
+			reason: "An unprotected container should be deleted, and its finalizer removed.",
+			want: want{
+				res:     reconcile.Result{},
+				deleted: true,
+				synced:  xpv1.Condition{Type: xpv1.TypeSynced, Status: v1.ConditionUnknown},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecDeletionPolicy(xpv1.DeletionDelete).
+				WithFinalizer(finalizer).Container
+			c.Spec.DeletionProtection = tc.protected
+
+			deleted := false
+			csd := &containerSyncdeleter{
+				ContainerOperations: &azurestoragefake.MockContainerOperations{
+					MockDelete: func(context.Context) error {
+						deleted = true
+						return nil
+					},
+					MockExists: func(context.Context) (bool, error) { return false, nil },
+				},
+				kube:      test.NewMockClient(),
+				container: c,
+				deletion:  wait.Backoff{Duration: time.Millisecond, Steps: 1},
+			}
+			res, err := csd.delete(ctx)
+			if err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.delete(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.res, res); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): -want result, +got result:\n%s", tc.reason, diff)
+			}
+			if deleted != tc.want.deleted {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want deleted %t, got %t", tc.reason, tc.want.deleted, deleted)
+			}
+			if got := meta.FinalizerExists(c, finalizer); got != tc.want.finalizer {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want finalizer %t, got %t", tc.reason, tc.want.finalizer, got)
+			}
+			if got := c.Status.AtProvider.DeletionProtected; got != tc.want.protected {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want deletion protected %t, got %t", tc.reason, tc.want.protected, got)
+			}
+			if diff := cmp.Diff(tc.want.synced, c.Status.GetCondition(xpv1.TypeSynced), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObservedGenerationGate(t *testing.T) {
+	ctx := context.TODO()
+	reads := []string{"GetAccount", "GetStoredAccessPolicies"}
+
+	type args struct {
+		generation int64
+		etag       azblob.ETag
+		observed   azblob.Metadata
+		failed     bool
+		location   string
+	}
+	type want struct {
+		calls   []string
+		updated bool
+		denied  bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SameGeneration": {
+			reason: "An unchanged container of an already reconciled generation should not be read from Azure beyond its properties and its account's location.",
+			args:   args{generation: 2, etag: "0x1"},
+			want:   want{calls: []string{"GetAccount"}},
+		},
+		"SameGenerationLocationDenied": {
+			reason: "An unchanged container of an already reconciled generation should be rejected if its account is outside the allowed locations.",
+			args:   args{generation: 2, etag: "0x1", location: "eastus"},
+			want:   want{calls: []string{"GetAccount"}, denied: true},
+		},
+		"GenerationAdvanced": {
+			reason: "A container whose spec generation advanced should be observed afresh.",
+			args:   args{generation: 3, etag: "0x1"},
+			want:   want{calls: append(reads, "GetEncryptionScope")},
+		},
+		"ExternallyModified": {
+			reason: "A container whose ETag changed should be observed afresh.",
+			args:   args{generation: 2, etag: "0x2"},
+			want:   want{calls: append(reads, "GetEncryptionScope")},
+		},
+		"LastReconcileFailed": {
+			reason: "A container whose last reconcile failed should be observed afresh.",
+			args:   args{generation: 2, etag: "0x1", failed: true},
+			want:   want{calls: reads},
+		},
+		"Drifted": {
+			reason: "Drift should be corrected even if the spec generation has not advanced.",
+			args:   args{generation: 2, etag: "0x1", observed: azblob.Metadata{"owner": "someone-else"}},
+			want:   want{calls: append(reads, "GetEncryptionScope"), updated: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecPAC(azblob.PublicAccessContainer).
+				WithSpecEncryptionScope("cmk", false).
+				WithStatusAtProvider(v1alpha3.ContainerObservation{
+					ETag:                   "0x1",
+					DefaultEncryptionScope: "cmk",
+					EncryptionKeyType:      string(storage.EncryptionKeyCustomerManaged),
+				}).
+				Container
+			c.Generation, c.Status.ObservedGeneration = tc.args.generation, 2
+			c.Spec.AccessPolicies = &[]v1alpha3.StoredAccessPolicy{}
+			c.Status.SetConditions(xpv1.ReconcileSuccess())
+			if tc.args.failed {
+				c.Status.SetConditions(xpv1.ReconcileError(errors.New("boom")))
+			}
+
+			var calls []string
+			updated := false
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockGetContainerProperties = func(context.Context) (*storage.ContainerProperties, error) {
+				return &storage.ContainerProperties{ETag: tc.args.etag, DefaultEncryptionScope: "cmk"}, nil
+			}
+			ops.MockGetStoredAccessPolicies = func(context.Context) ([]azblob.SignedIdentifier, error) {
+				calls = append(calls, "GetStoredAccessPolicies")
+				return nil, nil
+			}
+			ops.MockUpdate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+				updated = true
+				return nil
+			}
+
+			m := &azurestoragefake.MockManagementOperations{
+				MockGetAccount: func(context.Context) (*mgmtstorage.Account, error) {
+					calls = append(calls, "GetAccount")
+					location := "westus"
+					if tc.args.location != "" {
+						location = tc.args.location
+					}
+					return &mgmtstorage.Account{Location: to.StringPtr(location)}, nil
+				},
+				MockGetEncryptionScope: func(ctx context.Context, name string) (*mgmtstorage.EncryptionScope, error) {
+					calls = append(calls, "GetEncryptionScope")
+					return &mgmtstorage.EncryptionScope{EncryptionScopeProperties: &mgmtstorage.EncryptionScopeProperties{
+						Source: mgmtstorage.EncryptionScopeSourceMicrosoftKeyVault,
+					}}, nil
+				},
+			}
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				allowedLocations:    []string{"westus"},
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return m, nil
+				},
+			}
+
+			access := azblob.PublicAccessContainer
+			if _, err := ccu.update(ctx, &access, tc.args.observed); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): -want Azure reads, +got Azure reads:\n%s", tc.reason, diff)
+			}
+			if updated != tc.want.updated {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want updated %t, got %t", tc.reason, tc.want.updated, updated)
+			}
+			if c.Status.ObservedGeneration != tc.args.generation {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want observed generation %d, got %d", tc.reason, tc.args.generation, c.Status.ObservedGeneration)
+			}
+			if got := c.Status.AtProvider.EncryptionKeyType; got != string(storage.EncryptionKeyCustomerManaged) {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want key type %s, got %s", tc.reason, storage.EncryptionKeyCustomerManaged, got)
+			}
+			synced := xpv1.ReconcileSuccess()
+			if tc.want.denied {
+				synced = xpv1.ReconcileError(errors.Errorf(errLocationDenied, "eastus", "westus"))
+			}
+			if diff := cmp.Diff(synced, c.Status.GetCondition(xpv1.TypeSynced), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): -want synced, +got synced:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDeleteSnapshots(t *testing.T) {
+	ctx := context.TODO()
+	snaps := []storage.Snapshot{{Blob: "a", Snapshot: "2022-01-01T00:00:00Z"}, {Blob: "a", Snapshot: "2022-01-02T00:00:00Z"}}
+
+	type want struct {
+		deleted   []storage.Snapshot
+		deletes   int
+		finalizer bool
+		blocked   bool
+	}
+	cases := map[string]struct {
+		reason          string
+		deleteSnapshots bool
+		want            want
+	}{
+		"Blocked": {
+			reason: "A container whose snapshots block its deletion should not be deleted unless it permits deleting them.",
+			want:   want{deletes: 1, finalizer: true, blocked: true},
+		},
+		"DeleteSnapshots": {
+			reason:          "The snapshotted blobs of a container that permits it should be deleted before the container.",
+			deleteSnapshots: true,
+			want:            want{deleted: snaps, deletes: 2},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecDeletionPolicy(xpv1.DeletionDelete).
+				WithFinalizer(finalizer).Container
+			c.Spec.DeleteSnapshots = tc.deleteSnapshots
+
+			deletes := 0
+			var deleted []storage.Snapshot
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockDelete = func(context.Context) error {
+				deletes++
+				if deleted == nil {
+					return newStorageError(http.StatusConflict, azblob.ServiceCodeSnapshotsPresent)
+				}
+				return nil
+			}
+			ops.MockListSnapshots = func(_ context.Context, prefix string) ([]storage.Snapshot, error) {
+				return snaps, nil
+			}
+			ops.MockDeleteBlobsWithSnapshots = func(_ context.Context, s []storage.Snapshot) error {
+				deleted = s
+				return nil
+			}
+			csd := &containerSyncdeleter{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				deletion:            wait.Backoff{Duration: time.Millisecond, Steps: 1},
+			}
+			if _, err := csd.delete(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.delete(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): -want deleted snapshots, +got:\n%s", tc.reason, diff)
+			}
+			if deletes != tc.want.deletes {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want %d container deletes, got %d", tc.reason, tc.want.deletes, deletes)
+			}
+			if got := meta.FinalizerExists(c, finalizer); got != tc.want.finalizer {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want finalizer %t, got %t", tc.reason, tc.want.finalizer, got)
+			}
+			want := xpv1.Deleting()
+			if tc.want.blocked {
+				want = xpv1.ReconcileError(&storage.SnapshotsBlockDeletionError{Container: testContainerName, Snapshots: snaps})
+			}
+			if diff := cmp.Diff(want, c.Status.GetCondition(want.Type), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPurgeVersionsOnDelete(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+
+	type want struct {
+		calls     []string
+		finalizer bool
+	}
+	cases := map[string]struct {
+		reason        string
+		purge         bool
+		versioned     bool
+		versioningErr error
+		want          want
+	}{
+		"NotRequested": {
+			reason:    "A container that does not ask for its blob versions to be purged should be deleted without looking up versioning.",
+			versioned: true,
+			want:      want{calls: []string{"Delete"}},
+		},
+		"Versioned": {
+			reason:    "A container that asks for its blob versions to be purged should be deleted with them if its account has versioning enabled.",
+			purge:     true,
+			versioned: true,
+			want:      want{calls: []string{"GetBlobVersioning", "DeleteWithVersions"}},
+		},
+		"NotVersioned": {
+			reason: "A container whose account does not have versioning enabled has no blob versions to purge.",
+			purge:  true,
+			want:   want{calls: []string{"GetBlobVersioning", "Delete"}},
+		},
+		"GetVersioningFailed": {
+			reason:        "A container should not be deleted if its account's versioning cannot be looked up.",
+			purge:         true,
+			versioningErr: errBoom,
+			want:          want{calls: []string{"GetBlobVersioning"}, finalizer: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecDeletionPolicy(xpv1.DeletionDelete).
+				WithFinalizer(finalizer).Container
+			c.Spec.PurgeVersionsOnDelete = tc.purge
+
+			var calls []string
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockDelete = func(context.Context) error {
+				calls = append(calls, "Delete")
+				return nil
+			}
+			ops.MockDeleteWithVersions = func(context.Context) error {
+				calls = append(calls, "DeleteWithVersions")
+				return nil
+			}
+			m := newProvisionedManagementOperations()
+			m.MockGetBlobVersioning = func(context.Context) (bool, error) {
+				calls = append(calls, "GetBlobVersioning")
+				return tc.versioned, tc.versioningErr
+			}
+			csd := &containerSyncdeleter{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				deletion:            wait.Backoff{Duration: time.Millisecond, Steps: 1},
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return m, nil
+				},
+				conditions: DefaultErrorConditions(),
+			}
+			if _, err := csd.delete(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.delete(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+			if got := meta.FinalizerExists(c, finalizer); got != tc.want.finalizer {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want finalizer %t, got %t", tc.reason, tc.want.finalizer, got)
+			}
+		})
+	}
+}
+
+func TestDeletionArchive(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+
+	type want struct {
+		calls     []string
+		finalizer bool
+		synced    xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason     string
+		policy     xpv1.DeletionPolicy
+		archive    string
+		archiveErr error
+		want       want
+	}{
+		"Delete": {
+			reason: "A container should be deleted without archiving it by default.",
+			policy: xpv1.DeletionDelete,
+			want:   want{calls: []string{"Delete"}},
+		},
+		"Orphan": {
+			reason:  "An orphaned container should be neither archived nor deleted.",
+			policy:  xpv1.DeletionOrphan,
+			archive: "archive",
+		},
+		"Archive": {
+			reason:  "A container's blobs should be archived before it is deleted.",
+			policy:  xpv1.DeletionDelete,
+			archive: "archive",
+			want:    want{calls: []string{"ArchiveBlobs archive", "Delete"}},
+		},
+		"ArchiveFailed": {
+			reason:     "A container whose blobs could not be archived should not be deleted.",
+			policy:     xpv1.DeletionDelete,
+			archive:    "archive",
+			archiveErr: errBoom,
+			want: want{
+				calls:     []string{"ArchiveBlobs archive"},
+				finalizer: true,
+				synced:    xpv1.ReconcileError(errors.Wrapf(errBoom, errArchive, "archive")),
+			},
+		},
+		"AlreadyGone": {
+			reason:     "A container that is already gone should be deleted without archiving it.",
+			policy:     xpv1.DeletionDelete,
+			archive:    "archive",
+			archiveErr: newStorageError(http.StatusNotFound, azblob.ServiceCodeContainerNotFound),
+			want:       want{calls: []string{"ArchiveBlobs archive", "Delete"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecDeletionPolicy(tc.policy).
+				WithFinalizer(finalizer).Container
+			c.Spec.ArchiveContainer = tc.archive
+
+			var calls []string
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockArchiveBlobs = func(_ context.Context, archive string) error {
+				calls = append(calls, "ArchiveBlobs "+archive)
+				return tc.archiveErr
+			}
+			ops.MockDelete = func(context.Context) error {
+				calls = append(calls, "Delete")
+				return nil
+			}
+			ops.MockExists = func(context.Context) (bool, error) { return false, nil }
+			csd := &containerSyncdeleter{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				deletion:            wait.Backoff{Duration: time.Millisecond, Steps: 1},
+			}
+			if _, err := csd.delete(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.delete(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+			if got := meta.FinalizerExists(c, finalizer); got != tc.want.finalizer {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want finalizer %t, got %t", tc.reason, tc.want.finalizer, got)
+			}
+			want := xpv1.Deleting()
+			if tc.want.synced.Type != "" {
+				want = tc.want.synced
+			}
+			if diff := cmp.Diff(want, c.Status.GetCondition(want.Type), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAdoptionGracePeriod(t *testing.T) {
+	ctx := context.TODO()
+	grace := &metav1.Duration{Duration: time.Hour}
+
+	type args struct {
+		policy   v1alpha3.AdoptionPolicy
+		grace    *metav1.Duration
+		modified time.Duration
+	}
+	type want struct {
+		requeue   bool
+		updated   bool
+		finalizer bool
+		reason    xpv1.ConditionReason
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RecentlyModified": {
+			reason: "A recently modified container should not be adopted until it is stable.",
+			args:   args{grace: grace, modified: 10 * time.Minute},
+			want:   want{requeue: true, reason: ReasonAdoptionPending},
+		},
+		"Stable": {
+			reason: "A container that is stable should be adopted and marked as managed.",
+			args:   args{grace: grace, modified: 2 * time.Hour},
+			want:   want{updated: true, finalizer: true},
+		},
+		"NoGracePeriod": {
+			reason: "A container should be adopted immediately when no grace period is configured.",
+			args:   args{modified: time.Minute},
+			want:   want{updated: true},
+		},
+		"RecentlyModifiedImport": {
+			reason: "A recently modified container should not be imported until it is stable.",
+			args:   args{policy: v1alpha3.Import, grace: grace, modified: 10 * time.Minute},
+			want:   want{requeue: true, reason: ReasonAdoptionPending},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).Container
+			c.Spec.AdoptionPolicy = tc.args.policy
+			c.Spec.AdoptionGracePeriod = tc.args.grace
+
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockGet = func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+				return azurestoragefake.PublicAccessTypePtr(azblob.PublicAccessNone), nil, nil
+			}
+			ops.MockGetContainerProperties = func(context.Context) (*storage.ContainerProperties, error) {
+				return &storage.ContainerProperties{LastModified: time.Now().Add(-tc.args.modified)}, nil
+			}
+			ops.MockImport = func(context.Context) (storage.ContainerSnapshot, error) {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): unexpected import", tc.reason)
+				return storage.ContainerSnapshot{}, nil
+			}
+
+			updated := false
+			cu := newMockCreateUpdater()
+			cu.mockUpdate = func(context.Context, *azblob.PublicAccessType, azblob.Metadata) (reconcile.Result, error) {
+				updated = true
+				return reconcile.Result{}, nil
+			}
+			csd := &containerSyncdeleter{
+				createupdater:       cu,
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+			}
+			res, err := csd.sync(ctx)
+			if err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.sync(): unexpected error: %v", tc.reason, err)
+			}
+			if requeue := res.RequeueAfter > 0 && res.RequeueAfter <= grace.Duration; requeue != tc.want.requeue {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want requeue within the grace period %t, got result %+v", tc.reason, tc.want.requeue, res)
+			}
+			if updated != tc.want.updated {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want updated %t, got %t", tc.reason, tc.want.updated, updated)
+			}
+			if got := meta.FinalizerExists(c, finalizer); got != tc.want.finalizer {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want finalizer %t, got %t", tc.reason, tc.want.finalizer, got)
+			}
+			if got := c.Status.GetCondition(xpv1.TypeSynced).Reason; got != tc.want.reason {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want Synced reason %q, got %q", tc.reason, tc.want.reason, got)
+			}
+		})
+	}
+}
+
+// reversingCipher "encrypts" metadata values by reversing them.
+type reversingCipher struct{}
+
+func (reversingCipher) Encrypt(_, plaintext string) (string, error) {
+	return "enc:" + reverse(plaintext), nil
+}
+func (reversingCipher) Decrypt(_, ciphertext string) (string, error) {
+	return reverse(strings.TrimPrefix(ciphertext, "enc:")), nil
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+func TestSensitiveMetadataDrift(t *testing.T) {
+	ctx := context.TODO()
+
+	type want struct {
+		updated azblob.Metadata
+	}
+	cases := map[string]struct {
+		reason string
+		stored azblob.Metadata
+		want   want
+	}{
+		"Unchanged": {
+			reason: "Encrypted metadata whose decrypted value matches the spec should not be reported as drift.",
+			stored: azblob.Metadata{"secret-token": "enc:2retnuh", "owner": "crossplane"},
+		},
+		"Drifted": {
+			reason: "Encrypted metadata whose decrypted value differs from the spec should be corrected, and written encrypted.",
+			stored: azblob.Metadata{"secret-token": "enc:dlo", "owner": "crossplane"},
+			want:   want{updated: azblob.Metadata{"secret-token": "enc:2retnuh", "owner": "crossplane"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecMetadata(azblob.Metadata{"secret-token": "hunter2", "owner": "crossplane"}).
+				WithFinalizer(finalizer).Container
+
+			var updated azblob.Metadata
+			fake := azurestoragefake.NewMockContainerOperations()
+			fake.MockGet = func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+				return azurestoragefake.PublicAccessTypePtr(azblob.PublicAccessNone), tc.stored, nil
+			}
+			fake.MockUpdate = func(_ context.Context, _ azblob.PublicAccessType, md azblob.Metadata) error {
+				updated = md
+				return nil
+			}
+			ops := storage.NewMetadataEncryptingContainerOperations(fake, reversingCipher{}, DefaultSensitiveMetadataPrefix)
+			csd := &containerSyncdeleter{
+				createupdater: &containerCreateUpdater{
+					ContainerOperations: ops,
+					kube:                test.NewMockClient(),
+					container:           c,
+				},
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+			}
+			if _, err := csd.sync(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.sync(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.updated, updated); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): -want updated metadata, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExternalName(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		c      *v1alpha3.Container
+		want   string
+	}{
+		"ExternalName": {
+			reason: "The external name should override the container's metadata name.",
+			c:      v1alpha3test.NewMockContainer(testContainerName).WithExternalName("azure-name").Container,
+			want:   "azure-name",
+		},
+		"Fallback": {
+			reason: "The metadata name should be used when the container has no external name.",
+			c:      v1alpha3test.NewMockContainer(testContainerName).WithExternalName("").Container,
+			want:   testContainerName,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := externalName(tc.c); got != tc.want {
+				t.Errorf("\n%s\nexternalName(...): want %q, got %q", tc.reason, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCreateAlreadyExists(t *testing.T) {
+	ctx := context.TODO()
+
+	type want struct {
+		updated bool
+		synced  xpv1.ConditionReason
+		err     bool
+	}
+	cases := map[string]struct {
+		reason  string
+		policy  v1alpha3.AdoptionPolicy
+		create  error
+		claimed azblob.Metadata
+		want    want
+	}{
+		"AlreadyExists": {
+			reason: "A container created concurrently should be treated as created and updated to the desired state.",
+			create: newStorageError(http.StatusConflict, azblob.ServiceCodeContainerAlreadyExists),
+			want:   want{updated: true, synced: xpv1.ReasonReconcileSuccess},
+		},
+		"AlreadyExistsNotAdoptable": {
+			reason: "A container created concurrently should not be managed if the adoption policy forbids it.",
+			policy: v1alpha3.FailIfExists,
+			create: newStorageError(http.StatusConflict, azblob.ServiceCodeContainerAlreadyExists),
+			want:   want{synced: xpv1.ReasonReconcileError, err: true},
+		},
+		"AlreadyExistsOwnedByOther": {
+			reason:  "A container created concurrently should not be updated if its metadata claims it for another owner.",
+			create:  newStorageError(http.StatusConflict, azblob.ServiceCodeContainerAlreadyExists),
+			claimed: azblob.Metadata{"owner": "terraform"},
+			want:    want{synced: xpv1.ReasonReconcileError, err: true},
+		},
+		"BeingDeleted": {
+			reason: "A conflict because the container is being deleted should fail the create.",
+			create: newStorageError(http.StatusConflict, azblob.ServiceCodeContainerBeingDeleted),
+			want:   want{synced: xpv1.ReasonReconcileError, err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).WithSpecAdoptionPolicy(tc.policy).Container
+
+			updated := false
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockCreate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+				return tc.create
+			}
+			ops.MockGet = func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+				none := azblob.PublicAccessNone
+				return &none, tc.claimed, nil
+			}
+			ops.MockUpdate = func(context.Context, azblob.PublicAccessType, azblob.Metadata) error {
+				updated = true
+				return nil
+			}
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				ownerKey:            "owner",
+				owner:               "crossplane",
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return newProvisionedManagementOperations(), nil
+				},
+			}
+
+			got, err := ccu.create(ctx)
+			if err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.create(): unexpected error: %v", tc.reason, err)
+			}
+			if updated != tc.want.updated {
+				t.Errorf("\n%s\ncontainerCreateUpdater.create(): want updated %t, got %t", tc.reason, tc.want.updated, updated)
+			}
+			if s := c.Status.GetCondition(xpv1.TypeSynced); s.Reason != tc.want.synced {
+				t.Errorf("\n%s\ncontainerCreateUpdater.create(): want Synced reason %s, got %s: %s", tc.reason, tc.want.synced, s.Reason, s.Message)
+			}
+			if requeued := got == resultRequeue; requeued != tc.want.err {
+				t.Errorf("\n%s\ncontainerCreateUpdater.create(): want requeue %t, got %+v", tc.reason, tc.want.err, got)
+			}
+		})
+	}
+}
+
+func TestDeleteConnectionSecret(t *testing.T) {
+	ctx := context.TODO()
+	uid := types.UID("container-uid")
+	ref := &xpv1.SecretReference{Name: "conn", Namespace: "default"}
+	secret := func(owner types.UID) *v1.Secret {
+		s := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: ref.Namespace}}
+		if owner != "" {
+			s.SetOwnerReferences([]metav1.OwnerReference{{UID: owner, Controller: to.BoolPtr(true)}})
+		}
+		return s
+	}
+
+	type want struct {
+		secret    bool
+		finalizer bool
+	}
+	cases := map[string]struct {
+		reason string
+		retain bool
+		ref    *xpv1.SecretReference
+		secret *v1.Secret
+		want   want
+	}{
+		"DeleteSecret": {
+			reason: "The connection secret of a deleted container should be deleted.",
+			ref:    ref,
+			secret: secret(uid),
+			want:   want{},
+		},
+		"RetainSecret": {
+			reason: "The connection secret of a container that retains it should be kept.",
+			retain: true,
+			ref:    ref,
+			secret: secret(uid),
+			want:   want{secret: true},
+		},
+		"SecretAlreadyGone": {
+			reason: "A connection secret that no longer exists should not block deletion.",
+			ref:    ref,
+			want:   want{},
+		},
+		"SecretControlledElsewhere": {
+			reason: "A connection secret controlled by another resource should be kept.",
+			ref:    ref,
+			secret: secret("other-uid"),
+			want:   want{secret: true},
+		},
+		"NoSecretReference": {
+			reason: "A container that writes no connection secret should be deleted.",
+			want:   want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecDeletionPolicy(xpv1.DeletionOrphan).
+				WithFinalizer(finalizer).Container
+			c.SetUID(uid)
+			c.Spec.WriteConnectionSecretToReference = tc.ref
+			c.Spec.RetainConnectionSecret = tc.retain
+
+			b := fake.NewClientBuilder().WithObjects(c)
+			if tc.secret != nil {
+				b = b.WithObjects(tc.secret)
+			}
+			kube := b.Build()
+			csd := &containerSyncdeleter{kube: kube, container: c}
+			if _, err := csd.delete(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.delete(): unexpected error: %v", tc.reason, err)
+			}
+
+			err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &v1.Secret{})
+			if got := err == nil; got != tc.want.secret {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want secret %t, got %t (%v)", tc.reason, tc.want.secret, got, err)
+			}
+			if got := meta.FinalizerExists(c, finalizer); got != tc.want.finalizer {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want finalizer %t, got %t", tc.reason, tc.want.finalizer, got)
+			}
+		})
+	}
+}
+
+func TestDeleteConnectionSecretError(t *testing.T) {
+	errBoom := errors.New("boom")
+	c := v1alpha3test.NewMockContainer(testContainerName).
+		WithSpecDeletionPolicy(xpv1.DeletionOrphan).
+		WithFinalizer(finalizer).Container
+	c.Spec.WriteConnectionSecretToReference = &xpv1.SecretReference{Name: "conn", Namespace: "default"}
+
+	csd := &containerSyncdeleter{
+		kube: &test.MockClient{
+			MockGet:          test.NewMockGetFn(nil),
+			MockDelete:       test.NewMockDeleteFn(errBoom),
+			MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+		},
+		container: c,
+	}
+	res, err := csd.delete(context.TODO())
+	if err != nil {
+		t.Fatalf("containerSyncdeleter.delete(): unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(resultRequeue, res); diff != "" {
+		t.Errorf("containerSyncdeleter.delete(): -want, +got:\n%s", diff)
+	}
+	if !meta.FinalizerExists(c, finalizer) {
+		t.Errorf("containerSyncdeleter.delete(): want finalizer retained when the connection secret cannot be deleted")
+	}
+	want := xpv1.ReconcileError(errors.Wrap(errBoom, errDeleteSecret))
+	if diff := cmp.Diff(want, c.Status.GetCondition(want.Type), test.EquateConditions()); diff != "" {
+		t.Errorf("containerSyncdeleter.delete(): -want, +got:\n%s", diff)
+	}
+}
+
+func TestReconcileNow(t *testing.T) {
+	key := types.NamespacedName{Name: testContainerName}
+	req := reconcile.Request{NamespacedName: key}
+	errBoom := errors.New("boom")
+
+	requested := func() *v1alpha3.Container {
+		c := v1alpha3test.NewMockContainer(testContainerName).Container
+		meta.AddAnnotations(c, map[string]string{v1alpha3.AnnotationKeyReconcileNow: ""})
+		return c
+	}
+
+	type want struct {
+		err       error
+		res       reconcile.Result
+		syncs     int
+		annotated bool
+	}
+	cases := map[string]struct {
+		reason string
+		kube   func(client.Client) client.Client
+		c      *v1alpha3.Container
+		want   want
+	}{
+		"Requested": {
+			reason: "A requested reconcile should remove the request, forget earlier failures, and sync the container.",
+			c:      requested(),
+			want:   want{res: reconcile.Result{RequeueAfter: baseRequeueBackoff}, syncs: 1},
+		},
+		"NotRequested": {
+			reason: "A container without a request should sync with its earlier failures counted.",
+			c:      v1alpha3test.NewMockContainer(testContainerName).Container,
+			want:   want{res: reconcile.Result{RequeueAfter: 8 * baseRequeueBackoff}, syncs: 1},
+		},
+		"RemoveFailed": {
+			reason: "A request that cannot be removed should be kept, and the container should not be synced.",
+			kube: func(c client.Client) client.Client {
+				return &test.MockClient{
+					MockGet:    c.Get,
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				}
+			},
+			c:    requested(),
+			want: want{err: errors.Wrap(errBoom, errReconcileNow), annotated: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var kube client.Client = fake.NewClientBuilder().WithObjects(tc.c).Build()
+			if tc.kube != nil {
+				kube = tc.kube(kube)
+			}
+			syncs := 0
+			r := &Reconciler{
+				Client: kube,
+				syncdeleterMaker: &mockSyncdeleteMaker{
+					mockNewSyncdeleter: func(ctx context.Context, c *v1alpha3.Container, _ time.Duration) (syncdeleter, error) {
+						return &mockSyncdeleter{
+							mockSync: func(ctx context.Context) (reconcile.Result, error) {
+								syncs++
+								return resultRequeue, nil
+							},
+							ceiling: time.Minute,
+						}, nil
+					},
+				},
+				Initializer: managed.NewNameAsExternalName(kube),
+				backoff:     &requeueBackoff{failures: map[types.NamespacedName]int{key: 3}},
+				log:         logging.NewNopLogger(),
+			}
+			got, err := r.Reconcile(context.Background(), req)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReconciler.Reconcile(): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.res, got); diff != "" {
+				t.Errorf("\n%s\nReconciler.Reconcile(): -want, +got:\n%s", tc.reason, diff)
+			}
+			if syncs != tc.want.syncs {
+				t.Errorf("\n%s\nReconciler.Reconcile(): want %d syncs, got %d", tc.reason, tc.want.syncs, syncs)
+			}
+			c := &v1alpha3.Container{}
+			if err := kube.Get(context.Background(), key, c); err != nil {
+				t.Fatalf("\n%s\nGet(...): %v", tc.reason, err)
+			}
+			if _, got := c.GetAnnotations()[v1alpha3.AnnotationKeyReconcileNow]; got != tc.want.annotated {
+				t.Errorf("\n%s\nReconciler.Reconcile(): want annotated %t, got %t", tc.reason, tc.want.annotated, got)
+			}
+		})
+	}
+}
+
+func TestPartialUpdate(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+	spec := azblob.Metadata{"owner": "crossplane"}
+
+	type want struct {
+		access   bool
+		metadata bool
+		synced   xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason   string
+		observed azblob.Metadata
+		result   storage.UpdateResult
+		want     want
+	}{
+		"AccessPolicyFailed": {
+			reason:   "An update that applied the metadata but not the access policy should report which part applied.",
+			observed: azblob.Metadata{"owner": "someone"},
+			result:   storage.UpdateResult{MetadataApplied: true, Err: errBoom},
+			want: want{
+				access:   true,
+				metadata: true,
+				synced:   xpv1.ReconcileError(&storage.PartialUpdateError{Result: storage.UpdateResult{MetadataApplied: true, Err: errBoom}}),
+			},
+		},
+		"RetryAccessPolicy": {
+			reason:   "Retrying a partially failed update should only write the part that was not applied.",
+			observed: spec,
+			result:   storage.UpdateResult{AccessPolicyApplied: true},
+			want: want{
+				access: true,
+				synced: xpv1.ReconcileSuccess(),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var access *azblob.PublicAccessType
+			var metadata *azblob.Metadata
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockUpdatePartial = func(_ context.Context, pat *azblob.PublicAccessType, md *azblob.Metadata) storage.UpdateResult {
+				access, metadata = pat, md
+				return tc.result
+			}
+			ops.MockGetContainerProperties = func(context.Context) (*storage.ContainerProperties, error) {
+				return &storage.ContainerProperties{PublicAccessType: azblob.PublicAccessBlob}, nil
+			}
+			c := v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecPAC(azblob.PublicAccessBlob).
+				WithSpecMetadata(spec).Container
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+			}
+			none := azblob.PublicAccessNone
+			if _, err := ccu.update(ctx, &none, tc.observed); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if (access != nil) != tc.want.access {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want access policy written %t, got %v", tc.reason, tc.want.access, access)
+			}
+			if (metadata != nil) != tc.want.metadata {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want metadata written %t, got %v", tc.reason, tc.want.metadata, metadata)
+			}
+			if diff := cmp.Diff(tc.want.synced, c.Status.GetCondition(xpv1.TypeSynced), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAccountSoftDeleted(t *testing.T) {
+	errBoom := errors.New("boom")
+	unresolvable := errors.Wrap(&net.DNSError{Err: "no such host", Name: "testaccount.blob.core.windows.net", IsNotFound: true}, "cannot get container")
+
+	type args struct {
+		deleted    *mgmtstorage.DeletedAccount
+		restore    bool
+		restoreErr error
+	}
+	type want struct {
+		reason   xpv1.ConditionReason
+		message  string
+		requeue  bool
+		restored bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NotSoftDeleted": {
+			reason: "An unresolvable account that is not soft-deleted should be reported as a generic reconcile error and retried.",
+			want:   want{reason: xpv1.ReconcileError(errBoom).Reason, requeue: true},
+		},
+		"SoftDeleted": {
+			reason: "A soft-deleted account should be reported as requiring a restore, and not retried before the poll interval.",
+			args:   args{deleted: &mgmtstorage.DeletedAccount{}},
+			want:   want{reason: ReasonAccountSoftDeleted, message: "restore required"},
+		},
+		"Restore": {
+			reason: "A soft-deleted account should be restored when restoring is enabled.",
+			args:   args{deleted: &mgmtstorage.DeletedAccount{}, restore: true},
+			want:   want{reason: ReasonAccountSoftDeleted, message: "restore requested", restored: true},
+		},
+		"RestoreFailed": {
+			reason: "Failures to restore a soft-deleted account should be reported and retried.",
+			args:   args{deleted: &mgmtstorage.DeletedAccount{}, restore: true, restoreErr: errBoom},
+			want:   want{reason: xpv1.ReconcileError(errBoom).Reason, message: errRestoreAccount, requeue: true, restored: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).Container
+			acct := &v1alpha3.Account{Spec: v1alpha3.AccountSpec{AccountParameters: v1alpha3.AccountParameters{
+				StorageAccountSpec: &v1alpha3.StorageAccountSpec{Location: "westus"},
+			}}}
+			meta.SetExternalName(acct, "testaccount")
+
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockGet = func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+				return nil, nil, unresolvable
+			}
+			restored := false
+			m := &azurestoragefake.MockManagementOperations{
+				MockGetDeletedAccount: func(_ context.Context, location string) (*mgmtstorage.DeletedAccount, error) {
+					if location != "westus" {
+						t.Errorf("\n%s\nGetDeletedAccount(...): want location westus, got %s", tc.reason, location)
+					}
+					return tc.args.deleted, nil
+				},
+				MockRestoreDeletedAccount: func(context.Context, string) error {
+					restored = true
+					return tc.args.restoreErr
+				},
+			}
+			csd := &containerSyncdeleter{
+				createupdater:       newMockCreateUpdater(),
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				conditions:          DefaultErrorConditions(),
+				account:             acct,
+				management:          func(context.Context) (storage.ManagementOperations, error) { return m, nil },
+				restoreDeleted:      tc.args.restore,
+				poll:                time.Minute,
+			}
+
+			res, err := csd.sync(context.Background())
+			if err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.sync(): unexpected error: %v", tc.reason, err)
+			}
+			if res.Requeue != tc.want.requeue {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want requeue %t, got result %+v", tc.reason, tc.want.requeue, res)
+			}
+			if !tc.want.requeue && res.RequeueAfter != time.Minute {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want requeue after the poll interval, got result %+v", tc.reason, res)
+			}
+			if restored != tc.want.restored {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want restored %t, got %t", tc.reason, tc.want.restored, restored)
+			}
+			got := c.Status.GetCondition(xpv1.TypeSynced)
+			if got.Reason != tc.want.reason {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want Synced reason %q, got %q", tc.reason, tc.want.reason, got.Reason)
+			}
+			if !strings.Contains(got.Message, tc.want.message) {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want Synced message containing %q, got %q", tc.reason, tc.want.message, got.Message)
+			}
+		})
+	}
+}
+
+func TestPublicAccessRemediationDelay(t *testing.T) {
+	ctx := context.TODO()
+	const delay = time.Hour
+	ago := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(time.Now().Add(-d))
+		return &t
+	}
+
+	type want struct {
+		written  bool
+		metadata bool
+		events   int
+		since    bool
+		wait     bool
+	}
+	cases := map[string]struct {
+		reason   string
+		delay    time.Duration
+		observed azblob.PublicAccessType
+		metadata azblob.Metadata
+		since    *metav1.Time
+		want     want
+	}{
+		"NoDelay": {
+			reason:   "Drift should be corrected immediately when there is no remediation delay.",
+			observed: azblob.PublicAccessContainer,
+			want:     want{written: true},
+		},
+		"DriftFirstObserved": {
+			reason:   "Newly observed drift should be reported and not corrected until the delay has passed.",
+			delay:    delay,
+			observed: azblob.PublicAccessContainer,
+			want:     want{events: 1, since: true, wait: true},
+		},
+		"DriftWithinDelay": {
+			reason:   "Drift that has not yet persisted past the delay should not be corrected or reported again.",
+			delay:    delay,
+			observed: azblob.PublicAccessContainer,
+			since:    ago(delay / 2),
+			want:     want{since: true, wait: true},
+		},
+		"OtherDriftWithinDelay": {
+			reason:   "Drift of anything but the public access type should be corrected while correcting the public access type is delayed.",
+			delay:    delay,
+			observed: azblob.PublicAccessContainer,
+			metadata: azblob.Metadata{"owner": "someone"},
+			since:    ago(delay / 2),
+			want:     want{metadata: true, since: true, wait: true},
+		},
+		"DriftPersisted": {
+			reason:   "Drift that persisted past the delay should be corrected.",
+			delay:    delay,
+			observed: azblob.PublicAccessContainer,
+			since:    ago(2 * delay),
+			want:     want{written: true},
+		},
+		"DriftReverted": {
+			reason:   "When drift goes away before the delay has passed nothing should be corrected and it should no longer be tracked.",
+			delay:    delay,
+			observed: azblob.PublicAccessNone,
+			since:    ago(delay / 2),
+			want:     want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			written, metadata := false, false
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockUpdatePartial = func(_ context.Context, access *azblob.PublicAccessType, md *azblob.Metadata) storage.UpdateResult {
+				written = written || access != nil
+				metadata = metadata || md != nil
+				return storage.UpdateResult{}
+			}
+			c := v1alpha3test.NewMockContainer(testContainerName).WithSpecMetadata(tc.metadata).Container
+			c.Status.AtProvider.PublicAccessDriftSince = tc.since
+			rec := &eventRecorder{}
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return newProvisionedManagementOperations(), nil
+				},
+				remediation: &publicAccessRemediator{delay: tc.delay, record: rec},
+			}
+
+			got, err := ccu.update(ctx, &tc.observed, nil)
+			if err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if written != tc.want.written {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want written %t, got %t", tc.reason, tc.want.written, written)
+			}
+			if metadata != tc.want.metadata {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want metadata written %t, got %t", tc.reason, tc.want.metadata, metadata)
+			}
+			if len(rec.events) != tc.want.events {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want %d events, got %d", tc.reason, tc.want.events, len(rec.events))
+			}
+			if since := c.Status.AtProvider.PublicAccessDriftSince != nil; since != tc.want.since {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want drift tracked %t, got %t", tc.reason, tc.want.since, since)
+			}
+			if tc.want.wait && (got.RequeueAfter <= 0 || got.RequeueAfter > delay) {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want requeue within the delay, got %s", tc.reason, got.RequeueAfter)
+			}
+		})
+	}
+}
+
+func TestDeletionAccountDeleted(t *testing.T) {
+	ctx := context.TODO()
+	errForbidden := newStorageError(http.StatusForbidden, azblob.ServiceCodeAuthenticationFailed)
+	errUnresolvable := &net.DNSError{Err: "no such host", Name: testAccountName + ".blob.core.windows.net", IsNotFound: true}
+
+	type want struct {
+		lookups   int
+		finalizer bool
+	}
+	cases := map[string]struct {
+		reason    string
+		deleting  bool
+		deleteErr error
+		getErr    error
+		want      want
+	}{
+		"AccountUnresolvable": {
+			reason:    "A container whose storage account's blob endpoint no longer resolves, and that no longer exists, should be treated as deleted.",
+			deleteErr: errUnresolvable,
+			getErr:    autorest.DetailedError{StatusCode: http.StatusNotFound},
+			want:      want{lookups: 1},
+		},
+		"AccountUnresolvableButExists": {
+			reason:    "A container whose storage account's blob endpoint does not resolve, but that still exists, should be requeued rather than treated as deleted.",
+			deleteErr: errUnresolvable,
+			want:      want{lookups: 1, finalizer: true},
+		},
+		"AccountDeletedWhileDeleting": {
+			reason:    "A container whose storage account is being deleted and no longer exists should be treated as deleted.",
+			deleting:  true,
+			deleteErr: errForbidden,
+			getErr:    autorest.DetailedError{StatusCode: http.StatusNotFound},
+			want:      want{lookups: 1},
+		},
+		"AccountStillExists": {
+			reason:    "A container whose storage account is being deleted but still exists should report its deletion error.",
+			deleting:  true,
+			deleteErr: errForbidden,
+			want:      want{lookups: 1, finalizer: true},
+		},
+		"AccountNotDeleting": {
+			reason:    "The storage account of a container should not be looked up unless it is being deleted.",
+			deleteErr: errForbidden,
+			want:      want{finalizer: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecDeletionPolicy(xpv1.DeletionDelete).
+				WithFinalizer(finalizer).Container
+			acct := v1alpha3test.NewMockAccount(testAccountName)
+			if tc.deleting {
+				acct = acct.WithDeleteTimestamp(metav1.Now())
+			}
+
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockDelete = func(context.Context) error { return tc.deleteErr }
+			lookups := 0
+			m := newProvisionedManagementOperations()
+			m.MockGetAccount = func(context.Context) (*mgmtstorage.Account, error) {
+				lookups++
+				if tc.getErr != nil {
+					return nil, tc.getErr
+				}
+				return &mgmtstorage.Account{}, nil
+			}
+			csd := &containerSyncdeleter{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				account:             acct.Account,
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return m, nil
+				},
+				conditions: DefaultErrorConditions(),
+			}
+			if _, err := csd.delete(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.delete(): unexpected error: %v", tc.reason, err)
+			}
+			if lookups != tc.want.lookups {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want %d account lookups, got %d", tc.reason, tc.want.lookups, lookups)
+			}
+			if got := meta.FinalizerExists(c, finalizer); got != tc.want.finalizer {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want finalizer %t, got %t", tc.reason, tc.want.finalizer, got)
+			}
+		})
+	}
+}
+
+func TestNewSyncdeleterAccountSecretDeleted(t *testing.T) {
+	ctx := context.TODO()
+	acct := v1alpha3test.NewMockAccount(testAccountName).
+		WithSpecWriteConnectionSecretToReference(testNamespace, testAccountName).
+		WithFinalizer("test").
+		WithDeleteTimestamp(metav1.Now()).Account
+	c := v1alpha3test.NewMockContainer(testContainerName).WithSpecProviderRef(testAccountName).
+		WithFinalizer(finalizer).
+		WithResourceVersion("1").
+		WithDeleteTimestamp(time.Now()).Container
+	m := &containerSyncdeleterMaker{Client: &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			if a, ok := obj.(*v1alpha3.Account); ok {
+				acct.DeepCopyInto(a)
+				return nil
+			}
+			return kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+		},
+		MockUpdate: test.NewMockUpdateFn(nil),
+	}}
+
+	if _, err := m.newSyncdeleter(ctx, c, time.Minute); err == nil {
+		t.Fatalf("containerSyncdeleterMaker.newSyncdeleter(): want an error, got none")
+	}
+	if meta.FinalizerExists(c, finalizer) {
+		t.Errorf("containerSyncdeleterMaker.newSyncdeleter(): want the finalizer of a container whose storage account and secret are being deleted to be removed")
+	}
+}
+
+func TestMetadataKeyPolicy(t *testing.T) {
+	ctx := context.TODO()
+	spec := azblob.Metadata{"costCenter": "42"}
+
+	type want struct {
+		created azblob.Metadata
+		updated azblob.Metadata
+	}
+	cases := map[string]struct {
+		reason   string
+		policy   v1alpha3.MetadataKeyPolicy
+		observed azblob.Metadata
+		want     want
+	}{
+		"PreserveWrite": {
+			reason: "Keys should be written as they are specified by default.",
+			want:   want{created: spec, updated: spec},
+		},
+		"PreserveCompare": {
+			reason:   "Keys reported in another case should not be drift by default.",
+			observed: azblob.Metadata{"costcenter": "42"},
+			want:     want{created: spec},
+		},
+		"LowercaseWrite": {
+			reason: "Keys should be written in lower case.",
+			policy: v1alpha3.MetadataKeyLowercase,
+			want:   want{created: azblob.Metadata{"costcenter": "42"}, updated: azblob.Metadata{"costcenter": "42"}},
+		},
+		"LowercaseCompare": {
+			reason:   "Keys reported in another case should not be drift when keys are written in lower case.",
+			policy:   v1alpha3.MetadataKeyLowercase,
+			observed: azblob.Metadata{"Costcenter": "42"},
+			want:     want{created: azblob.Metadata{"costcenter": "42"}},
+		},
+		"AsReturnedWrite": {
+			reason: "Keys should be written in the form the blob service returns them in.",
+			policy: v1alpha3.MetadataKeyAsReturned,
+			want:   want{created: azblob.Metadata{"Costcenter": "42"}, updated: azblob.Metadata{"Costcenter": "42"}},
+		},
+		"AsReturnedCompare": {
+			reason:   "Keys reported in another case should not be drift when keys are written as they are returned.",
+			policy:   v1alpha3.MetadataKeyAsReturned,
+			observed: azblob.Metadata{"costcenter": "42"},
+			want:     want{created: azblob.Metadata{"Costcenter": "42"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var created, updated azblob.Metadata
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockCreate = func(_ context.Context, _ azblob.PublicAccessType, md azblob.Metadata) error {
+				created = md
+				return nil
+			}
+			ops.MockUpdatePartial = func(_ context.Context, _ *azblob.PublicAccessType, md *azblob.Metadata) storage.UpdateResult {
+				if md != nil {
+					updated = *md
+				}
+				return storage.UpdateResult{}
+			}
+			newCCU := func() *containerCreateUpdater {
+				c := v1alpha3test.NewMockContainer(testContainerName).WithSpecMetadata(spec).Container
+				c.Spec.MetadataKeyPolicy = tc.policy
+				return &containerCreateUpdater{
+					ContainerOperations: ops,
+					kube:                test.NewMockClient(),
+					container:           c,
+					management: func(context.Context) (storage.ManagementOperations, error) {
+						return newProvisionedManagementOperations(), nil
+					},
+				}
+			}
+
+			if _, err := newCCU().create(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.create(): unexpected error: %v", tc.reason, err)
+			}
+			none := azblob.PublicAccessNone
+			if _, err := newCCU().update(ctx, &none, tc.observed); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.created, created); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.create(): -want written metadata, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.updated, updated); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): -want written metadata, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestInvalidMetadata(t *testing.T) {
+	ctx := context.TODO()
+	const generation = 2
+	spec := azblob.Metadata{"cost-center": "42"}
+	sanitized := azblob.Metadata{"cost_center": "42"}
+	errInvalid := newStorageError(http.StatusBadRequest, azblob.ServiceCodeInvalidMetadata)
+
+	type want struct {
+		written   []azblob.Metadata
+		reason    xpv1.ConditionReason
+		rejected  int64
+		sanitized bool
+	}
+	cases := map[string]struct {
+		reason    string
+		sanitize  bool
+		rejected  int64
+		sanitized bool
+		observed  azblob.Metadata
+		reject    func(azblob.Metadata) bool
+		want      want
+	}{
+		"Rejected": {
+			reason: "Rejected metadata should be reported as invalid, and its generation recorded.",
+			reject: func(azblob.Metadata) bool { return true },
+			want:   want{written: []azblob.Metadata{spec}, reason: ReasonInvalidMetadata, rejected: generation},
+		},
+		"SanitizedAndRetried": {
+			reason:   "Rejected metadata should be written again once with sanitized keys when sanitizing is enabled.",
+			sanitize: true,
+			reject:   func(md azblob.Metadata) bool { return md["cost-center"] != "" },
+			want:     want{written: []azblob.Metadata{spec, sanitized}, reason: xpv1.ReconcileSuccess().Reason, rejected: generation, sanitized: true},
+		},
+		"SanitizedAndRejected": {
+			reason:   "Metadata that is rejected even with sanitized keys should be reported as invalid, and not retried again.",
+			sanitize: true,
+			reject:   func(azblob.Metadata) bool { return true },
+			want:     want{written: []azblob.Metadata{spec, sanitized}, reason: ReasonInvalidMetadata, rejected: generation},
+		},
+		"PreviouslyRejected": {
+			reason:   "Metadata that was rejected should not be written again while the spec is unchanged.",
+			rejected: generation,
+			want:     want{reason: ReasonInvalidMetadata, rejected: generation},
+		},
+		"PreviouslySanitized": {
+			reason:    "Metadata that was written with sanitized keys should not be drift.",
+			sanitize:  true,
+			rejected:  generation,
+			sanitized: true,
+			observed:  sanitized,
+			want:      want{reason: xpv1.ReconcileSuccess().Reason, rejected: generation, sanitized: true},
+		},
+		"SpecChanged": {
+			reason:   "Metadata should be written again once the spec changes after it was rejected.",
+			rejected: generation - 1,
+			want:     want{written: []azblob.Metadata{spec}, reason: xpv1.ReconcileSuccess().Reason, rejected: generation - 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written []azblob.Metadata
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockUpdatePartial = func(_ context.Context, _ *azblob.PublicAccessType, md *azblob.Metadata) storage.UpdateResult {
+				if md == nil {
+					return storage.UpdateResult{}
+				}
+				written = append(written, *md)
+				if tc.reject != nil && tc.reject(*md) {
+					return storage.UpdateResult{Err: errInvalid}
+				}
+				return storage.UpdateResult{MetadataApplied: true}
+			}
+			c := v1alpha3test.NewMockContainer(testContainerName).WithSpecMetadata(spec).Container
+			c.Generation = generation
+			c.Status.AtProvider.MetadataRejectedGeneration = tc.rejected
+			c.Status.AtProvider.MetadataKeysSanitized = tc.sanitized
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return newProvisionedManagementOperations(), nil
+				},
+				conditions:   DefaultErrorConditions(),
+				sanitizeKeys: tc.sanitize,
+			}
+
+			none := azblob.PublicAccessNone
+			if _, err := ccu.update(ctx, &none, tc.observed); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.written, written); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): -want written metadata, +got:\n%s", tc.reason, diff)
+			}
+			if got := c.Status.GetCondition(xpv1.TypeSynced).Reason; got != tc.want.reason {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want Synced reason %q, got %q", tc.reason, tc.want.reason, got)
+			}
+			if got := c.Status.AtProvider.MetadataRejectedGeneration; got != tc.want.rejected {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want rejected generation %d, got %d", tc.reason, tc.want.rejected, got)
+			}
+			if got := c.Status.AtProvider.MetadataKeysSanitized; got != tc.want.sanitized {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want keys sanitized %t, got %t", tc.reason, tc.want.sanitized, got)
+			}
+		})
+	}
+}
+
+func TestManagedMetadataKeys(t *testing.T) {
+	ctx := context.TODO()
+	spec := azblob.Metadata{"owner": "team"}
+	defaults := map[string]string{"managed-by": "crossplane"}
+
+	var written *azblob.Metadata
+	ops := azurestoragefake.NewMockContainerOperations()
+	ops.MockUpdatePartial = func(_ context.Context, _ *azblob.PublicAccessType, md *azblob.Metadata) storage.UpdateResult {
+		written = md
+		return storage.UpdateResult{MetadataApplied: true}
+	}
+	c := v1alpha3test.NewMockContainer(testContainerName).WithSpecMetadata(spec).Container
+	ccu := &containerCreateUpdater{
+		ContainerOperations: ops,
+		kube:                test.NewMockClient(),
+		container:           c,
+		management: func(context.Context) (storage.ManagementOperations, error) {
+			return newProvisionedManagementOperations(), nil
+		},
+		defaultMetadata: defaults,
+		managedKeys:     []string{"managed-by"},
+	}
+
+	none := azblob.PublicAccessNone
+	if _, err := ccu.update(ctx, &none, spec); err != nil {
+		t.Fatalf("containerCreateUpdater.update(): unexpected error: %v", err)
+	}
+	want := &v1alpha3.ContainerDrift{PlatformMetadata: []string{"managed-by"}}
+	if diff := cmp.Diff(want, c.Status.Drift); diff != "" {
+		t.Errorf("containerCreateUpdater.update(): managed keys should be reported as platform drift: -want, +got:\n%s", diff)
+	}
+	if written == nil || (*written)["managed-by"] != "crossplane" {
+		t.Errorf("containerCreateUpdater.update(): managed keys should still be written, got metadata %v", written)
+	}
+}
+
+func TestAwaitOperator(t *testing.T) {
+	key := types.NamespacedName{Name: testContainerName}
+	poll := 10 * time.Minute
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   reconcile.Result
+	}{
+		"InsufficientPermissions": {
+			reason: "Containers whose identity lacks an RBAC role should wait for the poll interval rather than back off.",
+			err:    newStorageError(http.StatusForbidden, "AuthorizationPermissionMismatch"),
+			want:   reconcile.Result{RequeueAfter: poll},
+		},
+		"AccountDisabled": {
+			reason: "Containers whose storage account is disabled should wait for the poll interval rather than back off.",
+			err:    newStorageError(http.StatusForbidden, azblob.ServiceCodeAccountIsDisabled),
+			want:   reconcile.Result{RequeueAfter: poll},
+		},
+		"AuthorizationFailed": {
+			reason: "Containers whose shared key is not authorized should back off as usual.",
+			err:    newStorageError(http.StatusForbidden, azblob.ServiceCodeInsufficientAccountPermissions),
+			want:   reconcile.Result{RequeueAfter: baseRequeueBackoff},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := fake.NewClientBuilder().WithObjects(v1alpha3test.NewMockContainer(testContainerName).Container).Build()
+			r := &Reconciler{
+				Client: kube,
+				syncdeleterMaker: &mockSyncdeleteMaker{
+					mockNewSyncdeleter: func(ctx context.Context, c *v1alpha3.Container, _ time.Duration) (syncdeleter, error) {
+						return &mockSyncdeleter{
+							mockSync: func(ctx context.Context) (reconcile.Result, error) {
+								DefaultErrorConditions().setReconcileError(c, tc.err)
+								return resultRequeue, nil
+							},
+							ceiling: time.Minute,
+						}, nil
+					},
+				},
+				Initializer: managed.NewNameAsExternalName(kube),
+				poll:        poll,
+				backoff:     &requeueBackoff{},
+				log:         logging.NewNopLogger(),
+			}
+			got, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: key})
+			if err != nil {
+				t.Fatalf("\n%s\nReconciler.Reconcile(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nReconciler.Reconcile(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCorrelateRequests(t *testing.T) {
+	key := types.NamespacedName{Name: testContainerName}
+
+	type want struct {
+		ids           []string
+		correlationID string
+	}
+	cases := map[string]struct {
+		reason    string
+		correlate bool
+		want      want
+	}{
+		"Correlated": {
+			reason:    "Every operation of a reconcile should share a correlation ID derived from the container's UID and generation, which is recorded in its status.",
+			correlate: true,
+			want: want{
+				ids:           []string{"uid-3", "uid-3", "uid-3", "uid-3"},
+				correlationID: "uid-3",
+			},
+		},
+		"Uncorrelated": {
+			reason: "Operations should not be correlated unless correlation is enabled.",
+			want:   want{ids: []string{"", "", "", ""}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := v1alpha3test.NewMockContainer(testContainerName).WithUID("uid").Container
+			cr.SetGeneration(3)
+			kube := fake.NewClientBuilder().WithObjects(cr).Build()
+			var ids []string
+			r := &Reconciler{
+				Client: kube,
+				syncdeleterMaker: &mockSyncdeleteMaker{
+					mockNewSyncdeleter: func(ctx context.Context, c *v1alpha3.Container, _ time.Duration) (syncdeleter, error) {
+						return &mockSyncdeleter{
+							mockSync: func(ctx context.Context) (reconcile.Result, error) {
+								// Each operation of the reconcile sees the
+								// same context.
+								ids = append(ids, storage.CorrelationID(ctx), storage.CorrelationID(ctx))
+								return reconcile.Result{}, kube.Status().Update(ctx, c)
+							},
+						}, nil
+					},
+				},
+				Initializer: managed.NewNameAsExternalName(kube),
+				backoff:     &requeueBackoff{},
+				correlate:   tc.correlate,
+				log:         logging.NewNopLogger(),
+			}
+
+			// The status recorded by each reconcile of a container that is up
+			// to date should be the same, so that writing it doesn't trigger
+			// yet another reconcile.
+			var status []v1alpha3.ContainerStatus
+			for i := 0; i < 2; i++ {
+				if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: key}); err != nil {
+					t.Fatalf("\n%s\nReconciler.Reconcile(): unexpected error: %v", tc.reason, err)
+				}
+				got := &v1alpha3.Container{}
+				if err := kube.Get(context.Background(), key, got); err != nil {
+					t.Fatalf("\n%s\nkube.Get(): %v", tc.reason, err)
+				}
+				status = append(status, got.Status)
+			}
+			if diff := cmp.Diff(tc.want.ids, ids); diff != "" {
+				t.Errorf("\n%s\nReconciler.Reconcile(): -want correlation IDs, +got correlation IDs:\n%s", tc.reason, diff)
+			}
+			if got := status[1].AtProvider.CorrelationID; got != tc.want.correlationID {
+				t.Errorf("\n%s\nReconciler.Reconcile(): want recorded correlation ID %q, got %q", tc.reason, tc.want.correlationID, got)
+			}
+			if diff := cmp.Diff(status[0], status[1]); diff != "" {
+				t.Errorf("\n%s\nReconciler.Reconcile(): -first status, +steady state status:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestStorageRetryOptions(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		pc     v1beta1.ProviderConfigSpec
+		want   azblob.RetryOptions
+	}{
+		"Unset": {
+			reason: "Provider configs that do not configure retries should leave the azblob SDK's defaults in effect.",
+			want:   azblob.RetryOptions{},
+		},
+		"Exponential": {
+			reason: "Retries should be exponential unless the fixed policy is selected.",
+			pc:     v1beta1.ProviderConfigSpec{StorageRetry: &v1beta1.StorageRetryOptions{MaxTries: 3}},
+			want:   azblob.RetryOptions{Policy: azblob.RetryPolicyExponential, MaxTries: 3},
+		},
+		"Fixed": {
+			reason: "The fixed policy and every configured parameter should be passed to the azblob SDK.",
+			pc: v1beta1.ProviderConfigSpec{StorageRetry: &v1beta1.StorageRetryOptions{
+				Policy:        v1beta1.StorageRetryFixed,
+				MaxTries:      5,
+				TryTimeout:    &metav1.Duration{Duration: 10 * time.Second},
+				RetryDelay:    &metav1.Duration{Duration: 2 * time.Second},
+				MaxRetryDelay: &metav1.Duration{Duration: 20 * time.Second},
+			}},
+			want: azblob.RetryOptions{
+				Policy:        azblob.RetryPolicyFixed,
+				MaxTries:      5,
+				TryTimeout:    10 * time.Second,
+				RetryDelay:    2 * time.Second,
+				MaxRetryDelay: 20 * time.Second,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := storageRetryOptions(tc.pc)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nstorageRetryOptions(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestOwnerMetadataKey(t *testing.T) {
+	ctx := context.TODO()
+
+	type want struct {
+		updated bool
+		reason  xpv1.ConditionReason
+	}
+	cases := map[string]struct {
+		reason   string
+		observed azblob.Metadata
+		want     want
+	}{
+		"OwnedByUs": {
+			reason:   "Containers claimed for this provider should be updated.",
+			observed: azblob.Metadata{"owner": "crossplane"},
+			want:     want{updated: true, reason: xpv1.ReconcileSuccess().Reason},
+		},
+		"Unclaimed": {
+			reason: "Containers that nothing claims should be updated.",
+			want:   want{updated: true, reason: xpv1.ReconcileSuccess().Reason},
+		},
+		"OwnedByOther": {
+			reason:   "Containers claimed for another owner should not be changed, and should report a conflict.",
+			observed: azblob.Metadata{"Owner": "terraform"},
+			want:     want{reason: ReasonConflict},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			updated := false
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockUpdatePartial = func(context.Context, *azblob.PublicAccessType, *azblob.Metadata) storage.UpdateResult {
+				updated = true
+				return storage.UpdateResult{MetadataApplied: true, AccessPolicyApplied: true}
+			}
+			c := v1alpha3test.NewMockContainer(testContainerName).WithSpecMetadata(azblob.Metadata{"team": "a"}).Container
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				ownerKey:            "owner",
+				owner:               "crossplane",
+				conditions:          DefaultErrorConditions(),
+			}
+
+			none := azblob.PublicAccessNone
+			if _, err := ccu.update(ctx, &none, tc.observed); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if updated != tc.want.updated {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want updated %t, got %t", tc.reason, tc.want.updated, updated)
+			}
+			if got := c.Status.GetCondition(xpv1.TypeSynced).Reason; got != tc.want.reason {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want Synced reason %s, got %s", tc.reason, tc.want.reason, got)
+			}
+		})
+	}
+}
+
+func TestOwnerMetadataKeyDelete(t *testing.T) {
+	ctx := context.TODO()
+
+	type want struct {
+		deleted   bool
+		finalizer bool
+		reason    xpv1.ConditionReason
+	}
+	cases := map[string]struct {
+		reason   string
+		observed azblob.Metadata
+		getErr   error
+		want     want
+	}{
+		"OwnedByUs": {
+			reason:   "Containers claimed for this provider should be deleted.",
+			observed: azblob.Metadata{"owner": "crossplane"},
+			want:     want{deleted: true},
+		},
+		"Unclaimed": {
+			reason: "Containers that nothing claims should be deleted.",
+			want:   want{deleted: true},
+		},
+		"OwnedByOther": {
+			reason:   "Containers claimed for another owner should not be deleted, and should report a conflict.",
+			observed: azblob.Metadata{"Owner": "terraform"},
+			want:     want{finalizer: true, reason: ReasonConflict},
+		},
+		"AlreadyDeleted": {
+			reason: "Containers that no longer exist are not claimed by anyone.",
+			getErr: newStorageError(http.StatusNotFound, azblob.ServiceCodeContainerNotFound),
+			want:   want{deleted: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			deleted := false
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockGet = func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+				none := azblob.PublicAccessNone
+				return &none, tc.observed, tc.getErr
+			}
+			ops.MockDelete = func(context.Context) error {
+				deleted = true
+				return nil
+			}
+			ops.MockExists = func(context.Context) (bool, error) { return false, nil }
+			c := v1alpha3test.NewMockContainer(testContainerName).WithFinalizer(finalizer).Container
+			c.Spec.DeletionPolicy = xpv1.DeletionDelete
+			csd := &containerSyncdeleter{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				ownerKey:            "owner",
+				owner:               "crossplane",
+				conditions:          DefaultErrorConditions(),
+				deletion:            wait.Backoff{Duration: time.Millisecond, Steps: 1},
+			}
+
+			if _, err := csd.delete(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.delete(): unexpected error: %v", tc.reason, err)
+			}
+			if deleted != tc.want.deleted {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want deleted %t, got %t", tc.reason, tc.want.deleted, deleted)
+			}
+			if got := meta.FinalizerExists(c, finalizer); got != tc.want.finalizer {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want finalizer %t, got %t", tc.reason, tc.want.finalizer, got)
+			}
+			if tc.want.reason != "" {
+				if got := c.Status.GetCondition(xpv1.TypeSynced).Reason; got != tc.want.reason {
+					t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want Synced reason %s, got %s", tc.reason, tc.want.reason, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckAccountPublicAccess(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+
+	type want struct {
+		updated bool
+		reason  xpv1.ConditionReason
+		asked   int
+	}
+	cases := map[string]struct {
+		reason  string
+		access  azblob.PublicAccessType
+		allowed bool
+		err     error
+		want    want
+	}{
+		"Allowed": {
+			reason:  "Containers that ask for public access should be updated if their account allows it.",
+			access:  azblob.PublicAccessBlob,
+			allowed: true,
+			want:    want{updated: true, reason: xpv1.ReconcileSuccess().Reason, asked: 1},
+		},
+		"Disallowed": {
+			reason: "Containers that ask for public access should not be written if their account disallows it, and should report an invalid request.",
+			access: azblob.PublicAccessContainer,
+			want:   want{reason: ReasonInvalidRequest, asked: 1},
+		},
+		"NoPublicAccess": {
+			reason: "Containers that do not ask for public access should be updated without asking whether their account allows it.",
+			access: azblob.PublicAccessType("None"),
+			want:   want{updated: true, reason: xpv1.ReconcileSuccess().Reason},
+		},
+		"GetAccountFailed": {
+			reason: "Containers should not be written if whether their account allows public access cannot be determined.",
+			access: azblob.PublicAccessBlob,
+			err:    errBoom,
+			want:   want{reason: xpv1.ReconcileError(errBoom).Reason, asked: 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			updated, asked := false, 0
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockUpdatePartial = func(context.Context, *azblob.PublicAccessType, *azblob.Metadata) storage.UpdateResult {
+				updated = true
+				return storage.UpdateResult{MetadataApplied: true, AccessPolicyApplied: true}
+			}
+			ops.MockGetContainerProperties = func(context.Context) (*storage.ContainerProperties, error) {
+				return &storage.ContainerProperties{PublicAccessType: tc.access, Metadata: azblob.Metadata{"team": "a"}}, nil
+			}
+			m := &azurestoragefake.MockManagementOperations{
+				MockGetAllowBlobPublicAccess: func(context.Context) (bool, error) {
+					asked++
+					return tc.allowed, tc.err
+				},
+			}
+			acct := &v1alpha3.Account{}
+			meta.SetExternalName(acct, testAccountName)
+			c := v1alpha3test.NewMockContainer(testContainerName).WithSpecPAC(tc.access).WithSpecMetadata(azblob.Metadata{"team": "a"}).Container
+			ccu := &containerCreateUpdater{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				account:             acct,
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return m, nil
+				},
+				accountAccess: storage.NewAccountPublicAccessCache(time.Hour),
+				conditions:    DefaultErrorConditions(),
+			}
+
+			none := azblob.PublicAccessNone
+			if _, err := ccu.update(ctx, &none, nil); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			if updated != tc.want.updated {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want updated %t, got %t", tc.reason, tc.want.updated, updated)
+			}
+			if got := c.Status.GetCondition(xpv1.TypeSynced).Reason; got != tc.want.reason {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want Synced reason %s, got %s", tc.reason, tc.want.reason, got)
+			}
+
+			// Whether the account allows public access is cached, so
+			// updating again does not ask unless asking failed.
+			if _, err := ccu.update(ctx, &none, nil); err != nil {
+				t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+			}
+			want := tc.want.asked
+			if tc.err != nil {
+				want *= 2
+			}
+			if asked != want {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): want account asked %d time(s), got %d", tc.reason, want, asked)
+			}
+		})
+	}
+}
+
+func TestNewReconcilerTracer(t *testing.T) {
+	tr := storage.NewOpenTelemetryTracer(nil)
+	cases := map[string]struct {
+		reason string
+		tracer storage.Tracer
+	}{
+		"Global": {
+			reason: "Container operations should be traced with the global OpenTelemetry tracer provider when no tracer is configured.",
+		},
+		"Configured": {
+			reason: "Container operations should be traced with the configured tracer.",
+			tracer: tr,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mgr := &setupManager{kube: test.NewMockClient(), recorder: record.NewFakeRecorder(1)}
+			opts := DefaultOptions()
+			opts.Tracer = tc.tracer
+			r := newReconciler(mgr, controller.Options{Logger: logging.NewNopLogger(), Features: &feature.Flags{}}, opts)
+			got := r.syncdeleterMaker.(*containerSyncdeleterMaker).tracer
+			if got == nil || (tc.tracer != nil && got != tc.tracer) {
+				t.Errorf("\n%s\nnewReconciler(...): want tracer %v, got %v", tc.reason, tc.tracer, got)
+			}
+		})
+	}
+}
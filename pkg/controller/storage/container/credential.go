@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	azure "github.com/crossplane-contrib/provider-azure/pkg/clients"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+// ReasonSharedKeyCredential is the reason of warning events recorded when a
+// container whose storage account disallows shared key access must
+// nonetheless be managed with the account key.
+const ReasonSharedKeyCredential event.Reason = "SharedKeyCredentialInUse"
+
+// A CredentialKind is a kind of credential with which containers may be
+// managed.
+type CredentialKind string
+
+// Kinds of credential.
+const (
+	// CredentialToken is an Azure AD token issued to the service principal
+	// of the storage account's provider credentials.
+	CredentialToken CredentialKind = "token"
+
+	// CredentialSAS is a shared access signature stored under AccountSASKey
+	// in the storage account's connection secret.
+	CredentialSAS CredentialKind = "sas"
+
+	// CredentialSharedKey is the storage account key stored in the storage
+	// account's connection secret.
+	CredentialSharedKey CredentialKind = "sharedKey"
+)
+
+// AccountSASKey is the key of the storage account connection secret that may
+// hold a shared access signature for the account.
+const AccountSASKey = "sas"
+
+const (
+	errNoTokenCredential   = "no token credential is configured"
+	errNoSAS               = "the storage account secret has no shared access signature"
+	errNoAccountKey        = "the storage account secret has no account key"
+	errNoCredential        = "no preferred credential is available: %s"
+	errUnknownCredential   = "unknown credential kind %q; want one of token, sas or sharedKey"
+	errDuplicateCredential = "credential kind %q is preferred more than once"
+)
+
+// sasIdentityPrefix prefixes shared access signatures in a serviceCache, so
+// they cannot be mistaken for account keys.
+const sasIdentityPrefix = "sas/"
+
+// validateCredentialPreference returns an error if the supplied preference
+// order names an unknown kind of credential, or a kind more than once.
+func validateCredentialPreference(pref []CredentialKind) error {
+	seen := map[CredentialKind]bool{}
+	for _, k := range pref {
+		switch k {
+		case CredentialToken, CredentialSAS, CredentialSharedKey:
+		default:
+			return errors.Errorf(errUnknownCredential, k)
+		}
+		if seen[k] {
+			return errors.Errorf(errDuplicateCredential, k)
+		}
+		seen[k] = true
+	}
+	return nil
+}
+
+// A tokenCredentialFn returns an Azure AD credential for the blob service of
+// the supplied storage account, and an identity that changes whenever the
+// credential would.
+type tokenCredentialFn func(ctx context.Context, acct *v1alpha3.Account) (string, azblob.Credential, error)
+
+// newTokenCredentialFn returns a tokenCredentialFn that issues tokens to the
+// service principal of the storage account's provider credentials.
+func newTokenCredentialFn(kube client.Client) tokenCredentialFn {
+	return func(ctx context.Context, acct *v1alpha3.Account) (string, azblob.Credential, error) {
+		creds, _, err := azure.GetAuthInfo(ctx, kube, acct)
+		if err != nil {
+			return "", nil, errors.Wrap(err, errGetAuthInfo)
+		}
+		spt, err := azure.NewServicePrincipalToken(creds, storage.AccountTokenResource(meta.GetExternalName(acct), azure.StorageEndpointSuffix(creds)))
+		if err != nil {
+			return "", nil, err
+		}
+		c, err := storage.NewTokenCredential(spt)
+		return creds[azure.CredentialsKeyTenantID] + "/" + creds[azure.CredentialsKeyClientID], c, err
+	}
+}
+
+// sharedKeyDisallowed returns true if the supplied storage account disallows
+// shared key access.
+func sharedKeyDisallowed(acct *v1alpha3.Account) bool {
+	return acct != nil && acct.Spec.AllowSharedKeyAccess != nil && !*acct.Spec.AllowSharedKeyAccess
+}
+
+// A credentialWarner reports containers whose storage account disallows shared
+// key access but that are managed with the account key, which will stop
+// working once shared key access is disallowed. A nil warner reports nothing.
+type credentialWarner struct {
+	record event.Recorder
+	log    logging.Logger
+}
+
+func (w *credentialWarner) warn(c *v1alpha3.Container, account string, err error) {
+	if w == nil {
+		return
+	}
+	name := externalName(c)
+	w.record.Event(c, event.Warning(ReasonSharedKeyCredential, errors.Wrap(err, fmt.Sprintf(
+		"Storage account %s of container %s disallows shared key access, but the container is managed with the account key because no token credential could be obtained", account, name))))
+	w.log.Info("Storage account disallows shared key access but container is managed with the account key", "account", account, "container", name, "error", err.Error())
+}
+
+// chose logs the kind of credential the supplied container is managed with,
+// and why any more preferred kinds were skipped.
+func (w *credentialWarner) chose(c *v1alpha3.Container, account string, k CredentialKind, skipped []string) {
+	if w == nil {
+		return
+	}
+	w.log.Debug("Chose storage credential", "account", account, "container", externalName(c), "credential", string(k), "skipped", strings.Join(skipped, "; "))
+}
+
+// serviceHandle returns a handle to the blob service of the supplied storage
+// account. When a credential preference order is configured the first kind of
+// credential in it that is available is used. Otherwise containers of accounts
+// that disallow shared key access use a token credential, since the account
+// key stops working once shared key access is disallowed. If no token
+// credential can be obtained the account key is used instead, and the
+// container is warned about.
+func (m *containerSyncdeleterMaker) serviceHandle(ctx context.Context, c *v1alpha3.Container, acct *v1alpha3.Account, account, key, sas string, o storage.ContainerHandleOptions) (*storage.ServiceHandle, error) {
+	if len(m.preference) > 0 {
+		return m.preferredServiceHandle(ctx, c, acct, account, key, sas, o)
+	}
+	if !sharedKeyDisallowed(acct) {
+		return m.services.get(account, key, o)
+	}
+	err := errors.New(errNoTokenCredential)
+	if m.tokenCredential != nil {
+		var id string
+		var tc azblob.Credential
+		if id, tc, err = m.tokenCredential(ctx, acct); err == nil {
+			return m.services.getWithCredential(account, tokenIdentityPrefix+id, o, tc)
+		}
+	}
+	m.credentials.warn(c, account, err)
+	return m.services.get(account, key, o)
+}
+
+// preferredServiceHandle returns a handle to the blob service of the supplied
+// storage account that uses the first preferred kind of credential that is
+// available.
+func (m *containerSyncdeleterMaker) preferredServiceHandle(ctx context.Context, c *v1alpha3.Container, acct *v1alpha3.Account, account, key, sas string, o storage.ContainerHandleOptions) (*storage.ServiceHandle, error) {
+	skipped := make([]string, 0, len(m.preference))
+	for _, k := range m.preference {
+		h, err := m.serviceHandleWith(ctx, k, acct, account, key, sas, o)
+		if err != nil {
+			skipped = append(skipped, string(k)+": "+err.Error())
+			continue
+		}
+		m.credentials.chose(c, account, k, skipped)
+		return h, nil
+	}
+	return nil, errors.Errorf(errNoCredential, strings.Join(skipped, "; "))
+}
+
+// serviceHandleWith returns a handle to the blob service of the supplied
+// storage account that uses the supplied kind of credential, or an error if
+// no such credential is available.
+func (m *containerSyncdeleterMaker) serviceHandleWith(ctx context.Context, k CredentialKind, acct *v1alpha3.Account, account, key, sas string, o storage.ContainerHandleOptions) (*storage.ServiceHandle, error) {
+	switch k {
+	case CredentialToken:
+		if m.tokenCredential == nil {
+			return nil, errors.New(errNoTokenCredential)
+		}
+		id, tc, err := m.tokenCredential(ctx, acct)
+		if err != nil {
+			return nil, err
+		}
+		return m.services.getWithCredential(account, tokenIdentityPrefix+id, o, tc)
+	case CredentialSAS:
+		if sas == "" {
+			return nil, errors.New(errNoSAS)
+		}
+		return m.services.getWithSAS(account, sas, o)
+	case CredentialSharedKey:
+		if key == "" {
+			return nil, errors.New(errNoAccountKey)
+		}
+		return m.services.get(account, key, o)
+	}
+	return nil, errors.Errorf(errUnknownCredential, k)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+func TestServiceHandleCredential(t *testing.T) {
+	const testKey = "dGVzdC1rZXkK"
+	errBoom := errors.New("boom")
+
+	type args struct {
+		allowSharedKey *bool
+		token          tokenCredentialFn
+	}
+	type want struct {
+		cacheKey string
+		warned   bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SharedKeyAllowed": {
+			reason: "Containers of accounts that do not disallow shared key access should use the account key.",
+			args:   args{allowSharedKey: to.BoolPtr(true)},
+			want:   want{cacheKey: testKey},
+		},
+		"SharedKeyUnset": {
+			reason: "Containers of accounts that do not ask for shared key access either way should use the account key.",
+			want:   want{cacheKey: testKey},
+		},
+		"PreferToken": {
+			reason: "Containers of accounts that disallow shared key access should use a token credential.",
+			args: args{
+				allowSharedKey: to.BoolPtr(false),
+				token: func(context.Context, *v1alpha3.Account) (string, azblob.Credential, error) {
+					return "tenant/client", azblob.NewTokenCredential("t", nil), nil
+				},
+			},
+			want: want{cacheKey: tokenIdentityPrefix + "tenant/client"},
+		},
+		"TokenUnavailable": {
+			reason: "Containers of accounts that disallow shared key access should fall back to the account key, with a warning, when no token credential can be obtained.",
+			args: args{
+				allowSharedKey: to.BoolPtr(false),
+				token: func(context.Context, *v1alpha3.Account) (string, azblob.Credential, error) {
+					return "", nil, errBoom
+				},
+			},
+			want: want{cacheKey: testKey, warned: true},
+		},
+		"NoTokenCredential": {
+			reason: "Containers of accounts that disallow shared key access should be warned about when no token credential is configured.",
+			args:   args{allowSharedKey: to.BoolPtr(false)},
+			want:   want{cacheKey: testKey, warned: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).Container
+			acct := v1alpha3test.NewMockAccount(testAccountName).Account
+			acct.Spec.AllowSharedKeyAccess = tc.args.allowSharedKey
+
+			rec := &eventRecorder{}
+			m := &containerSyncdeleterMaker{
+				services:        &serviceCache{},
+				tokenCredential: tc.args.token,
+				credentials:     &credentialWarner{record: rec, log: logging.NewNopLogger()},
+			}
+			if _, err := m.serviceHandle(context.TODO(), c, acct, testAccountName, testKey, "", storage.ContainerHandleOptions{}); err != nil {
+				t.Fatalf("\n%s\nserviceHandle(...): %v", tc.reason, err)
+			}
+			if got := m.services.services[testAccountName].key; got != tc.want.cacheKey {
+				t.Errorf("\n%s\nserviceHandle(...): want credential %q, got %q", tc.reason, tc.want.cacheKey, got)
+			}
+			warned := len(rec.events) == 1 && rec.events[0].Type == event.TypeWarning && rec.events[0].Reason == ReasonSharedKeyCredential
+			if warned != tc.want.warned {
+				t.Errorf("\n%s\nserviceHandle(...): want warning %t, got events %v", tc.reason, tc.want.warned, rec.events)
+			}
+			if warned && !strings.Contains(rec.events[0].Message, "disallows shared key access") {
+				t.Errorf("\n%s\nserviceHandle(...): unexpected warning message %q", tc.reason, rec.events[0].Message)
+			}
+		})
+	}
+}
+
+func TestCredentialPreference(t *testing.T) {
+	const (
+		testKey = "dGVzdC1rZXkK"
+		testSAS = "sv=2020-08-04&sig=c2ln"
+	)
+	token := func(context.Context, *v1alpha3.Account) (string, azblob.Credential, error) {
+		return "tenant/client", azblob.NewTokenCredential("t", nil), nil
+	}
+
+	type args struct {
+		preference []CredentialKind
+		token      tokenCredentialFn
+		key        string
+		sas        string
+	}
+	type want struct {
+		cacheKey string
+		err      bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"TokenFirst": {
+			reason: "A token credential should be used when it is preferred and can be obtained.",
+			args:   args{preference: []CredentialKind{CredentialToken, CredentialSAS, CredentialSharedKey}, token: token, key: testKey, sas: testSAS},
+			want:   want{cacheKey: tokenIdentityPrefix + "tenant/client"},
+		},
+		"TokenUnavailable": {
+			reason: "The next preferred credential should be used when no token credential is configured.",
+			args:   args{preference: []CredentialKind{CredentialToken, CredentialSAS, CredentialSharedKey}, key: testKey, sas: testSAS},
+			want:   want{cacheKey: sasIdentityPrefix + testSAS},
+		},
+		"SASFirst": {
+			reason: "A shared access signature should be used over the account key when it is preferred.",
+			args:   args{preference: []CredentialKind{CredentialSAS, CredentialSharedKey}, key: testKey, sas: testSAS},
+			want:   want{cacheKey: sasIdentityPrefix + testSAS},
+		},
+		"NoSAS": {
+			reason: "The account key should be used when the account secret has no shared access signature.",
+			args:   args{preference: []CredentialKind{CredentialSAS, CredentialSharedKey}, key: testKey},
+			want:   want{cacheKey: testKey},
+		},
+		"SharedKeyFirst": {
+			reason: "The account key should be used over a shared access signature when it is preferred.",
+			args:   args{preference: []CredentialKind{CredentialSharedKey, CredentialSAS}, key: testKey, sas: testSAS},
+			want:   want{cacheKey: testKey},
+		},
+		"NoneAvailable": {
+			reason: "An error should be returned when no preferred credential is available.",
+			args:   args{preference: []CredentialKind{CredentialToken, CredentialSAS}, key: testKey},
+			want:   want{err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := &containerSyncdeleterMaker{
+				services:        &serviceCache{},
+				tokenCredential: tc.args.token,
+				credentials:     &credentialWarner{record: &eventRecorder{}, log: logging.NewNopLogger()},
+				preference:      tc.args.preference,
+			}
+			c := v1alpha3test.NewMockContainer(testContainerName).Container
+			_, err := m.serviceHandle(context.TODO(), c, nil, testAccountName, tc.args.key, tc.args.sas, storage.ContainerHandleOptions{})
+			if (err != nil) != tc.want.err {
+				t.Fatalf("\n%s\nserviceHandle(...): want error %t, got %v", tc.reason, tc.want.err, err)
+			}
+			if got := m.services.services[testAccountName].key; got != tc.want.cacheKey {
+				t.Errorf("\n%s\nserviceHandle(...): want credential %q, got %q", tc.reason, tc.want.cacheKey, got)
+			}
+		})
+	}
+}
+
+func TestValidateCredentialPreference(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		pref   []CredentialKind
+		want   error
+	}{
+		"Valid": {
+			reason: "Every known kind of credential may be preferred once.",
+			pref:   []CredentialKind{CredentialToken, CredentialSAS, CredentialSharedKey},
+		},
+		"Unknown": {
+			reason: "Unknown kinds of credential should be rejected.",
+			pref:   []CredentialKind{"certificate"},
+			want:   errors.Errorf(errUnknownCredential, "certificate"),
+		},
+		"Duplicate": {
+			reason: "Kinds of credential preferred more than once should be rejected.",
+			pref:   []CredentialKind{CredentialSAS, CredentialSAS},
+			want:   errors.Errorf(errDuplicateCredential, CredentialSAS),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateCredentialPreference(tc.pref)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateCredentialPreference(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
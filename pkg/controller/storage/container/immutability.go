@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+const (
+	errUnlockPolicy        = "cannot unlock the locked immutability policy of container %s; locked policies can only be extended"
+	errShortenLockedPolicy = "cannot shorten the retention period of the locked immutability policy of container %s from %d to %d days"
+	errLockedAppendWrites  = "cannot change allowProtectedAppendWrites of the locked immutability policy of container %s"
+)
+
+// managesImmutability returns true if the supplied desired state manages the
+// container's immutability policy or legal hold.
+func managesImmutability(spec v1alpha3.ContainerParameters) bool {
+	return spec.ImmutabilityPolicy != nil || spec.LegalHold != nil
+}
+
+// immutability returns how the container's immutability policy and legal hold
+// differ from the desired ones, along with their observed state and the
+// management operations they were read with. Nothing is read when the desired
+// state manages neither.
+func (ccu *containerCreateUpdater) immutability(ctx context.Context, spec v1alpha3.ContainerParameters) ([]string, storage.ContainerImmutability, storage.ManagementOperations, error) {
+	if !managesImmutability(spec) {
+		return nil, storage.ContainerImmutability{}, nil, nil
+	}
+	m, err := ccu.management(ctx)
+	if err != nil {
+		return nil, storage.ContainerImmutability{}, nil, err
+	}
+	got, err := m.GetContainerImmutability(ctx, externalName(ccu.container))
+	if err != nil {
+		return nil, storage.ContainerImmutability{}, nil, err
+	}
+	return containerImmutabilityDrift(spec, got), got, m, nil
+}
+
+// updateImmutability changes the container's immutability policy and legal
+// hold from the supplied observed state to the desired one, using the
+// supplied management operations. Locked policies can only have their
+// retention period extended, so an error is returned if the desired state
+// would otherwise change them.
+func (ccu *containerCreateUpdater) updateImmutability(ctx context.Context, m storage.ManagementOperations, spec v1alpha3.ContainerParameters, got storage.ContainerImmutability) error {
+	if !managesImmutability(spec) {
+		return nil
+	}
+	name := externalName(ccu.container)
+	if want := spec.ImmutabilityPolicy; want != nil && !sameImmutabilityPolicy(*want, got.Policy) {
+		if err := updateImmutabilityPolicy(ctx, m, name, *want, got.Policy); err != nil {
+			return err
+		}
+	}
+	if spec.LegalHold == nil {
+		return nil
+	}
+	add, remove := legalHoldChanges(legalHoldTags(spec.LegalHold), got.LegalHoldTags)
+	if len(add) > 0 {
+		if err := m.SetContainerLegalHold(ctx, name, add); err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		return m.ClearContainerLegalHold(ctx, name, remove)
+	}
+	return nil
+}
+
+// updateImmutabilityPolicy changes the named container's immutability policy
+// from the supplied observed one, which is nil if it has none, to the desired
+// one.
+func updateImmutabilityPolicy(ctx context.Context, m storage.ManagementOperations, container string, want v1alpha3.ContainerImmutabilityPolicy, got *storage.ContainerImmutabilityPolicy) error {
+	p := storage.ContainerImmutabilityPolicy{
+		PeriodSinceCreationInDays:  want.PeriodSinceCreationInDays,
+		AllowProtectedAppendWrites: want.AllowProtectedAppendWrites,
+	}
+	if got != nil && got.Locked {
+		switch {
+		case !want.Locked:
+			return errors.Errorf(errUnlockPolicy, container)
+		case want.PeriodSinceCreationInDays < got.PeriodSinceCreationInDays:
+			return errors.Errorf(errShortenLockedPolicy, container, got.PeriodSinceCreationInDays, want.PeriodSinceCreationInDays)
+		case want.AllowProtectedAppendWrites != got.AllowProtectedAppendWrites:
+			return errors.Errorf(errLockedAppendWrites, container)
+		}
+		p.ETag = got.ETag
+		_, err := m.ExtendContainerImmutabilityPolicy(ctx, container, p)
+		return err
+	}
+
+	etag := ""
+	if got != nil {
+		etag = got.ETag
+	}
+	if got == nil || got.PeriodSinceCreationInDays != p.PeriodSinceCreationInDays || got.AllowProtectedAppendWrites != p.AllowProtectedAppendWrites {
+		p.ETag = etag
+		var err error
+		if etag, err = m.SetContainerImmutabilityPolicy(ctx, container, p); err != nil {
+			return err
+		}
+	}
+	if !want.Locked {
+		return nil
+	}
+	return m.LockContainerImmutabilityPolicy(ctx, container, etag)
+}
+
+// containerImmutabilityDrift describes how the supplied observed immutability
+// of a container differs from the supplied desired state.
+func containerImmutabilityDrift(spec v1alpha3.ContainerParameters, got storage.ContainerImmutability) []string {
+	drift := []string{}
+	if want := spec.ImmutabilityPolicy; want != nil && !sameImmutabilityPolicy(*want, got.Policy) {
+		drift = append(drift, fmt.Sprintf("immutabilityPolicy: want %s, got %s", describeImmutabilityPolicy(want.PeriodSinceCreationInDays, want.AllowProtectedAppendWrites, want.Locked), describeObservedImmutabilityPolicy(got.Policy)))
+	}
+	if spec.LegalHold != nil {
+		want, observed := legalHoldTags(spec.LegalHold), normalizeLegalHoldTags(got.LegalHoldTags)
+		if strings.Join(want, ",") != strings.Join(observed, ",") {
+			drift = append(drift, fmt.Sprintf("legalHold: want %s, got %s", describeLegalHold(want), describeLegalHold(observed)))
+		}
+	}
+	return drift
+}
+
+func sameImmutabilityPolicy(want v1alpha3.ContainerImmutabilityPolicy, got *storage.ContainerImmutabilityPolicy) bool {
+	return got != nil &&
+		want.PeriodSinceCreationInDays == got.PeriodSinceCreationInDays &&
+		want.AllowProtectedAppendWrites == got.AllowProtectedAppendWrites &&
+		want.Locked == got.Locked
+}
+
+func describeImmutabilityPolicy(days int32, appendWrites, locked bool) string {
+	d := fmt.Sprintf("%d days", days)
+	if appendWrites {
+		d += " allowing protected append writes"
+	}
+	if locked {
+		d += ", locked"
+	}
+	return d
+}
+
+func describeObservedImmutabilityPolicy(p *storage.ContainerImmutabilityPolicy) string {
+	if p == nil {
+		return "none"
+	}
+	return describeImmutabilityPolicy(p.PeriodSinceCreationInDays, p.AllowProtectedAppendWrites, p.Locked)
+}
+
+func describeLegalHold(tags []string) string {
+	if len(tags) == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("tags %s", strings.Join(tags, ", "))
+}
+
+// legalHoldTags returns the tags of the supplied desired legal hold in the
+// form Azure stores them.
+func legalHoldTags(h *v1alpha3.ContainerLegalHold) []string {
+	tags := make([]string, len(h.Tags))
+	for i, t := range h.Tags {
+		tags[i] = string(t)
+	}
+	return normalizeLegalHoldTags(tags)
+}
+
+// normalizeLegalHoldTags returns the supplied legal hold tags in lower case,
+// sorted and without duplicates.
+func normalizeLegalHoldTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(t)
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// legalHoldChanges returns the tags that must be added and removed to change
+// a legal hold from the supplied observed tags to the desired ones.
+func legalHoldChanges(want, got []string) (add, remove []string) {
+	observed := make(map[string]bool, len(got))
+	for _, t := range normalizeLegalHoldTags(got) {
+		observed[t] = true
+	}
+	for _, t := range want {
+		if !observed[t] {
+			add = append(add, t)
+		}
+		delete(observed, t)
+	}
+	for t := range observed {
+		remove = append(remove, t)
+	}
+	sort.Strings(remove)
+	return add, remove
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+	azurestoragefake "github.com/crossplane-contrib/provider-azure/pkg/clients/storage/fake"
+)
+
+func TestContainerImmutabilityDrift(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		spec   v1alpha3.ContainerParameters
+		got    storage.ContainerImmutability
+		drift  []string
+	}{
+		"NotManaged": {
+			reason: "Immutability should not drift if the desired state manages none of it.",
+			got:    storage.ContainerImmutability{Policy: &storage.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7}, LegalHoldTags: []string{"case1"}},
+			drift:  []string{},
+		},
+		"Unchanged": {
+			reason: "Immutability that matches the desired state should not drift, regardless of the case and order of tags.",
+			spec: v1alpha3.ContainerParameters{
+				ImmutabilityPolicy: &v1alpha3.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, Locked: true},
+				LegalHold:          &v1alpha3.ContainerLegalHold{Tags: []v1alpha3.LegalHoldTag{"Case2", "case1"}},
+			},
+			got:   storage.ContainerImmutability{Policy: &storage.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, Locked: true}, LegalHoldTags: []string{"case1", "case2"}},
+			drift: []string{},
+		},
+		"MissingPolicy": {
+			reason: "A desired immutability policy the container does not have should drift.",
+			spec:   v1alpha3.ContainerParameters{ImmutabilityPolicy: &v1alpha3.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, AllowProtectedAppendWrites: true}},
+			drift:  []string{"immutabilityPolicy: want 7 days allowing protected append writes, got none"},
+		},
+		"UnlockedPolicy": {
+			reason: "A desired locked immutability policy that is unlocked should drift.",
+			spec:   v1alpha3.ContainerParameters{ImmutabilityPolicy: &v1alpha3.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, Locked: true}},
+			got:    storage.ContainerImmutability{Policy: &storage.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7}},
+			drift:  []string{"immutabilityPolicy: want 7 days, locked, got 7 days"},
+		},
+		"LegalHoldReleased": {
+			reason: "A legal hold without tags should drift if the container has any.",
+			spec:   v1alpha3.ContainerParameters{LegalHold: &v1alpha3.ContainerLegalHold{}},
+			got:    storage.ContainerImmutability{LegalHoldTags: []string{"case1"}},
+			drift:  []string{"legalHold: want none, got tags case1"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := containerImmutabilityDrift(tc.spec, tc.got)
+			if diff := cmp.Diff(tc.drift, got); diff != "" {
+				t.Errorf("\n%s\ncontainerImmutabilityDrift(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateImmutability(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+
+	type want struct {
+		calls []string
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		spec   v1alpha3.ContainerParameters
+		got    storage.ContainerImmutability
+		setErr error
+		want   want
+	}{
+		"NotManaged": {
+			reason: "Nothing should be written if the desired state manages no immutability.",
+			got:    storage.ContainerImmutability{LegalHoldTags: []string{"case1"}},
+			want:   want{},
+		},
+		"CreateAndLock": {
+			reason: "A missing policy should be created and then locked with the ETag it was created with.",
+			spec:   v1alpha3.ContainerParameters{ImmutabilityPolicy: &v1alpha3.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, Locked: true}},
+			want:   want{calls: []string{`set 7 days false ""`, `lock "new"`}},
+		},
+		"ChangeUnlocked": {
+			reason: "An unlocked policy should be changed with the ETag it was observed with.",
+			spec:   v1alpha3.ContainerParameters{ImmutabilityPolicy: &v1alpha3.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 30, AllowProtectedAppendWrites: true}},
+			got:    storage.ContainerImmutability{Policy: &storage.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, ETag: "old"}},
+			want:   want{calls: []string{`set 30 days true "old"`}},
+		},
+		"LockUnchanged": {
+			reason: "An unlocked policy whose retention is unchanged should only be locked.",
+			spec:   v1alpha3.ContainerParameters{ImmutabilityPolicy: &v1alpha3.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, Locked: true}},
+			got:    storage.ContainerImmutability{Policy: &storage.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, ETag: "old"}},
+			want:   want{calls: []string{`lock "old"`}},
+		},
+		"ExtendLocked": {
+			reason: "A locked policy should be extended to a longer retention period.",
+			spec:   v1alpha3.ContainerParameters{ImmutabilityPolicy: &v1alpha3.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 30, Locked: true}},
+			got:    storage.ContainerImmutability{Policy: &storage.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, Locked: true, ETag: "old"}},
+			want:   want{calls: []string{`extend 30 days "old"`}},
+		},
+		"ShortenLocked": {
+			reason: "A locked policy cannot have its retention period shortened.",
+			spec:   v1alpha3.ContainerParameters{ImmutabilityPolicy: &v1alpha3.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 1, Locked: true}},
+			got:    storage.ContainerImmutability{Policy: &storage.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, Locked: true}},
+			want:   want{err: errors.Errorf(errShortenLockedPolicy, testContainerName, 7, 1)},
+		},
+		"UnlockLocked": {
+			reason: "A locked policy cannot be unlocked.",
+			spec:   v1alpha3.ContainerParameters{ImmutabilityPolicy: &v1alpha3.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7}},
+			got:    storage.ContainerImmutability{Policy: &storage.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, Locked: true}},
+			want:   want{err: errors.Errorf(errUnlockPolicy, testContainerName)},
+		},
+		"LegalHold": {
+			reason: "Missing legal hold tags should be set and unwanted ones cleared.",
+			spec:   v1alpha3.ContainerParameters{LegalHold: &v1alpha3.ContainerLegalHold{Tags: []v1alpha3.LegalHoldTag{"Case1", "case2"}}},
+			got:    storage.ContainerImmutability{LegalHoldTags: []string{"case2", "case3"}},
+			want:   want{calls: []string{"setLegalHold [case1]", "clearLegalHold [case3]"}},
+		},
+		"SetFailed": {
+			reason: "Errors writing the policy should be returned without locking it.",
+			spec:   v1alpha3.ContainerParameters{ImmutabilityPolicy: &v1alpha3.ContainerImmutabilityPolicy{PeriodSinceCreationInDays: 7, Locked: true}},
+			setErr: errBoom,
+			want:   want{calls: []string{`set 7 days false ""`}, err: errBoom},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var calls []string
+			m := &azurestoragefake.MockManagementOperations{
+				MockSetContainerImmutabilityPolicy: func(_ context.Context, _ string, p storage.ContainerImmutabilityPolicy) (string, error) {
+					calls = append(calls, fmt.Sprintf("set %d days %t %q", p.PeriodSinceCreationInDays, p.AllowProtectedAppendWrites, p.ETag))
+					return "new", tc.setErr
+				},
+				MockExtendContainerImmutabilityPolicy: func(_ context.Context, _ string, p storage.ContainerImmutabilityPolicy) (string, error) {
+					calls = append(calls, fmt.Sprintf("extend %d days %q", p.PeriodSinceCreationInDays, p.ETag))
+					return "new", nil
+				},
+				MockLockContainerImmutabilityPolicy: func(_ context.Context, _, etag string) error {
+					calls = append(calls, fmt.Sprintf("lock %q", etag))
+					return nil
+				},
+				MockSetContainerLegalHold: func(_ context.Context, _ string, tags []string) error {
+					calls = append(calls, fmt.Sprintf("setLegalHold %v", tags))
+					return nil
+				},
+				MockClearContainerLegalHold: func(_ context.Context, _ string, tags []string) error {
+					calls = append(calls, fmt.Sprintf("clearLegalHold %v", tags))
+					return nil
+				},
+			}
+			ccu := &containerCreateUpdater{container: v1alpha3test.NewMockContainer(testContainerName).Container}
+
+			err := ccu.updateImmutability(ctx, m, tc.spec, tc.got)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.updateImmutability(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.updateImmutability(...): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
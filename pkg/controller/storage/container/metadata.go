@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+	"github.com/crossplane-contrib/provider-azure/pkg/version"
+)
+
+const (
+	templateOpen  = "{{"
+	templateClose = "}}"
+
+	errUnterminatedVariable = "metadata %s: unterminated template variable; write literal braces as \\{{"
+	errUnknownVariable      = "metadata %s: unknown template variable %q; supported variables are %s"
+)
+
+// templateVariables returns the variables that may be substituted into the
+// metadata values of the supplied container. Timestamp is the container's
+// creation time, so that it does not change between reconciles.
+func templateVariables(c *v1alpha3.Container) map[string]string {
+	return map[string]string{
+		"ControllerName":    controllerName,
+		"ControllerVersion": version.Version,
+		"Name":              c.GetName(),
+		"Namespace":         c.GetNamespace(),
+		"Timestamp":         c.GetCreationTimestamp().UTC().Format(time.RFC3339),
+	}
+}
+
+// renderMetadata substitutes template variables such as {{.Name}} into the
+// supplied metadata values. A literal {{ may be written as \{{.
+func renderMetadata(md map[string]string, vars map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(md))
+	for k, v := range md {
+		r, err := render(k, v, vars)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = r
+	}
+	return out, nil
+}
+
+// render substitutes template variables into the value of the supplied
+// metadata key.
+func render(key, v string, vars map[string]string) (string, error) {
+	b := &strings.Builder{}
+	for {
+		i := strings.Index(v, templateOpen)
+		if i < 0 {
+			b.WriteString(v)
+			return b.String(), nil
+		}
+		if i > 0 && v[i-1] == '\\' {
+			b.WriteString(v[:i-1] + templateOpen)
+			v = v[i+len(templateOpen):]
+			continue
+		}
+		b.WriteString(v[:i])
+		v = v[i+len(templateOpen):]
+
+		j := strings.Index(v, templateClose)
+		if j < 0 {
+			return "", errors.Errorf(errUnterminatedVariable, key)
+		}
+		name := strings.TrimSpace(v[:j])
+		val, ok := vars[strings.TrimPrefix(name, ".")]
+		if !ok || !strings.HasPrefix(name, ".") {
+			return "", errors.Errorf(errUnknownVariable, key, name, supportedVariables(vars))
+		}
+		b.WriteString(val)
+		v = v[j+len(templateClose):]
+	}
+}
+
+// mergeMetadata returns the supplied metadata merged together. Keys of each
+// are compared case insensitively, like the blob service does, and take
+// precedence over the same keys of the ones before it.
+func mergeMetadata(mds ...map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, md := range mds {
+		for k, v := range md {
+			for existing := range out {
+				if strings.EqualFold(existing, k) {
+					delete(out, existing)
+				}
+			}
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// escapeMetadata escapes the supplied metadata values so that rendering them
+// returns them unchanged.
+func escapeMetadata(md map[string]string) map[string]string {
+	out := make(map[string]string, len(md))
+	for k, v := range md {
+		out[k] = strings.ReplaceAll(v, templateOpen, `\`+templateOpen)
+	}
+	return out
+}
+
+// supportedVariables returns the sorted, comma separated names of the
+// supplied template variables.
+func supportedVariables(vars map[string]string) string {
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, "."+k)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// applyMetadataKeyPolicy returns the supplied metadata with its keys in the
+// case the supplied policy writes them in. Keys that the policy makes equal
+// are written once, with the value of the last of them in sorted order.
+func applyMetadataKeyPolicy(md map[string]string, p v1alpha3.MetadataKeyPolicy) map[string]string {
+	var key func(string) string
+	switch p {
+	case v1alpha3.MetadataKeyLowercase:
+		key = strings.ToLower
+	case v1alpha3.MetadataKeyAsReturned:
+		key = returnedMetadataKey
+	default:
+		return md
+	}
+	return rekeyMetadata(md, key)
+}
+
+// rekeyMetadata returns the supplied metadata with each key replaced by the
+// supplied function's result. Keys that it makes equal are written once, with
+// the value of the last of them in sorted order.
+func rekeyMetadata(md map[string]string, key func(string) string) map[string]string {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make(map[string]string, len(md))
+	for _, k := range keys {
+		out[key(k)] = md[k]
+	}
+	return out
+}
+
+// returnedMetadataKey returns the supplied metadata key in the form the blob
+// service's HTTP response headers return it in.
+func returnedMetadataKey(k string) string {
+	const prefix = "x-ms-meta-"
+	return textproto.CanonicalMIMEHeaderKey(prefix + k)[len(prefix):]
+}
+
+// sanitizeMetadataKeys returns the supplied metadata with each key made a
+// valid C# identifier, as Azure requires metadata keys to be.
+func sanitizeMetadataKeys(md map[string]string) map[string]string {
+	return rekeyMetadata(md, sanitizeMetadataKey)
+}
+
+// sanitizeMetadataKey returns the supplied metadata key with each character
+// that is not an ASCII letter, digit, or underscore replaced by an underscore,
+// and prefixed with an underscore if it would otherwise be empty or start
+// with a digit.
+func sanitizeMetadataKey(k string) string {
+	b := &strings.Builder{}
+	for _, r := range k {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if b.Len() == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// checkOwner returns an error if the supplied observed metadata claims the
+// container for an owner other than the supplied one under the supplied key.
+// Containers whose metadata does not claim an owner may be changed. Keys are
+// matched ignoring case, but owners are not. Ownership is not checked when
+// the key is empty.
+func checkOwner(name string, md map[string]string, key, owner string) error {
+	if key == "" {
+		return nil
+	}
+	for k, v := range md {
+		if strings.EqualFold(k, key) && v != "" && v != owner {
+			return &storage.ForeignOwnerError{Container: name, Key: key, Owner: v}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+)
+
+func TestRenderMetadata(t *testing.T) {
+	vars := map[string]string{
+		"ControllerName": controllerName,
+		"Name":           testContainerName,
+	}
+
+	type want struct {
+		md  map[string]string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		md     map[string]string
+		want   want
+	}{
+		"Substitution": {
+			reason: "Supported variables should be substituted, with or without surrounding spaces.",
+			md: map[string]string{
+				"created-by": "{{.ControllerName}}",
+				"container":  "name={{ .Name }}",
+				"plain":      "value",
+			},
+			want: want{md: map[string]string{
+				"created-by": controllerName,
+				"container":  "name=" + testContainerName,
+				"plain":      "value",
+			}},
+		},
+		"UnknownVariable": {
+			reason: "Unknown variables should be an error rather than left as literal braces.",
+			md:     map[string]string{"owner": "{{.Owner}}"},
+			want: want{err: errors.Errorf(errUnknownVariable, "owner", ".Owner",
+				".ControllerName, .Name")},
+		},
+		"NotAVariable": {
+			reason: "Template actions other than variables should be an error.",
+			md:     map[string]string{"owner": `{{printf "x"}}`},
+			want: want{err: errors.Errorf(errUnknownVariable, "owner", `printf "x"`,
+				".ControllerName, .Name")},
+		},
+		"Unterminated": {
+			reason: "An unterminated variable should be an error.",
+			md:     map[string]string{"owner": "{{.Name"},
+			want:   want{err: errors.Errorf(errUnterminatedVariable, "owner")},
+		},
+		"Escaped": {
+			reason: "Escaped braces should be written literally.",
+			md:     map[string]string{"literal": `\{{.Name}} is {{.Name}}`},
+			want:   want{md: map[string]string{"literal": "{{.Name}} is " + testContainerName}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := renderMetadata(tc.md, vars)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nrenderMetadata(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.md, got); diff != "" {
+				t.Errorf("\n%s\nrenderMetadata(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTemplateVariables(t *testing.T) {
+	c := v1alpha3test.NewMockContainer(testContainerName).Container
+	c.CreationTimestamp = metav1.NewTime(time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC))
+
+	got := templateVariables(c)
+	want := map[string]string{
+		"ControllerName":    controllerName,
+		"ControllerVersion": "",
+		"Name":              testContainerName,
+		"Namespace":         "",
+		"Timestamp":         "2022-03-04T05:06:07Z",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("templateVariables(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestSanitizeMetadataKeys(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		md     map[string]string
+		want   map[string]string
+	}{
+		"Valid": {
+			reason: "Keys that are valid C# identifiers should be unchanged.",
+			md:     map[string]string{"costCenter": "42", "_owner2": "team"},
+			want:   map[string]string{"costCenter": "42", "_owner2": "team"},
+		},
+		"InvalidCharacters": {
+			reason: "Characters that may not appear in C# identifiers should be replaced with underscores.",
+			md:     map[string]string{"cost-center": "42", "owner.team": "a", "naïve": "b"},
+			want:   map[string]string{"cost_center": "42", "owner_team": "a", "na_ve": "b"},
+		},
+		"LeadingDigit": {
+			reason: "Keys starting with a digit should be prefixed with an underscore.",
+			md:     map[string]string{"2fa": "on"},
+			want:   map[string]string{"_2fa": "on"},
+		},
+		"Collision": {
+			reason: "Keys that sanitize to the same key should be written once, with the value of the last in sorted order.",
+			md:     map[string]string{"cost-center": "a", "cost.center": "b"},
+			want:   map[string]string{"cost_center": "b"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := sanitizeMetadataKeys(tc.md)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nsanitizeMetadataKeys(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
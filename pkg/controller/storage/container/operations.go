@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+// maxOperationRecords is how many operations are recorded in the status of a
+// Container. Older records are dropped, so that the status does not grow
+// without bound.
+const maxOperationRecords = 10
+
+// recordOperation records an attempt at the supplied operation, which failed
+// with the supplied error unless it is nil, in the status of the supplied
+// container. The oldest records are dropped once there are more than
+// maxOperationRecords. A failure of the same operation as the most recently
+// recorded failure is not recorded again, so that retrying an operation that
+// keeps failing doesn't change the container's status, which would trigger
+// another reconcile immediately rather than after its backoff.
+func recordOperation(c *v1alpha3.Container, op v1alpha3.ContainerOperation, err error) {
+	r := v1alpha3.ContainerOperationRecord{Operation: op, Outcome: v1alpha3.OperationSucceeded, Time: metav1.Now()}
+	if err != nil {
+		r.Outcome = v1alpha3.OperationFailed
+		r.RequestID = storage.RequestID(err)
+	}
+	if n := len(c.Status.Operations); n > 0 && r.Outcome == v1alpha3.OperationFailed {
+		last := c.Status.Operations[n-1]
+		if last.Operation == op && last.Outcome == v1alpha3.OperationFailed {
+			return
+		}
+	}
+	ops := append(c.Status.Operations, r)
+	if n := len(ops) - maxOperationRecords; n > 0 {
+		ops = append([]v1alpha3.ContainerOperationRecord{}, ops[n:]...)
+	}
+	c.Status.Operations = ops
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+)
+
+func TestRecordOperation(t *testing.T) {
+	h := http.Header{}
+	h.Set("x-ms-request-id", "req-1")
+	r := &http.Request{Method: http.MethodPut, URL: &url.URL{Scheme: "https", Host: "example.blob.core.windows.net"}, Header: http.Header{}}
+	errAzure := azblob.NewResponseError(nil, &http.Response{StatusCode: http.StatusForbidden, Header: h, Request: r}, "")
+
+	type args struct {
+		existing int
+		previous *v1alpha3.ContainerOperationRecord
+		op       v1alpha3.ContainerOperation
+		err      error
+	}
+	type want struct {
+		len  int
+		last v1alpha3.ContainerOperationRecord
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Succeeded": {
+			reason: "A successful operation should be recorded as succeeded.",
+			args:   args{op: v1alpha3.ContainerOperationCreate},
+			want:   want{len: 1, last: operation(v1alpha3.ContainerOperationCreate, v1alpha3.OperationSucceeded)},
+		},
+		"FailedInAzure": {
+			reason: "An operation that Azure failed should be recorded as failed, with the ID of the request.",
+			args:   args{op: v1alpha3.ContainerOperationUpdate, err: errors.Wrap(errAzure, "cannot update")},
+			want: want{len: 1, last: v1alpha3.ContainerOperationRecord{
+				Operation: v1alpha3.ContainerOperationUpdate,
+				Outcome:   v1alpha3.OperationFailed,
+				RequestID: "req-1",
+			}},
+		},
+		"FailedWithoutRequest": {
+			reason: "An operation that failed before reaching Azure should be recorded without a request ID.",
+			args:   args{op: v1alpha3.ContainerOperationDelete, err: errors.New("boom")},
+			want:   want{len: 1, last: operation(v1alpha3.ContainerOperationDelete, v1alpha3.OperationFailed)},
+		},
+		"Appended": {
+			reason: "An operation should be recorded after the existing records.",
+			args:   args{existing: 3, op: v1alpha3.ContainerOperationDelete},
+			want:   want{len: 4, last: operation(v1alpha3.ContainerOperationDelete, v1alpha3.OperationSucceeded)},
+		},
+		"RepeatedFailure": {
+			reason: "A failure of the same operation as the most recently recorded failure should not be recorded again.",
+			args: args{
+				previous: &v1alpha3.ContainerOperationRecord{Operation: v1alpha3.ContainerOperationUpdate, Outcome: v1alpha3.OperationFailed, RequestID: "req-0", Time: metav1.Now()},
+				op:       v1alpha3.ContainerOperationUpdate,
+				err:      errors.Wrap(errAzure, "cannot update"),
+			},
+			want: want{len: 1, last: v1alpha3.ContainerOperationRecord{
+				Operation: v1alpha3.ContainerOperationUpdate,
+				Outcome:   v1alpha3.OperationFailed,
+				RequestID: "req-0",
+			}},
+		},
+		"FailureOfAnotherOperation": {
+			reason: "A failure of another operation than the most recently recorded failure should be recorded.",
+			args: args{
+				previous: &v1alpha3.ContainerOperationRecord{Operation: v1alpha3.ContainerOperationCreate, Outcome: v1alpha3.OperationFailed, Time: metav1.Now()},
+				op:       v1alpha3.ContainerOperationUpdate,
+				err:      errors.New("boom"),
+			},
+			want: want{len: 2, last: operation(v1alpha3.ContainerOperationUpdate, v1alpha3.OperationFailed)},
+		},
+		"FailureAfterSuccess": {
+			reason: "A failure of an operation that last succeeded should be recorded.",
+			args: args{
+				previous: &v1alpha3.ContainerOperationRecord{Operation: v1alpha3.ContainerOperationUpdate, Outcome: v1alpha3.OperationSucceeded, Time: metav1.Now()},
+				op:       v1alpha3.ContainerOperationUpdate,
+				err:      errors.New("boom"),
+			},
+			want: want{len: 2, last: operation(v1alpha3.ContainerOperationUpdate, v1alpha3.OperationFailed)},
+		},
+		"Capped": {
+			reason: "The oldest records should be dropped once there are more than the maximum.",
+			args:   args{existing: maxOperationRecords, op: v1alpha3.ContainerOperationDelete},
+			want:   want{len: maxOperationRecords, last: operation(v1alpha3.ContainerOperationDelete, v1alpha3.OperationSucceeded)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			existing := make([]v1alpha3.ContainerOperationRecord, tc.args.existing)
+			for i := range existing {
+				existing[i] = operation(v1alpha3.ContainerOperationUpdate, v1alpha3.OperationSucceeded)
+			}
+			if len(existing) > 0 {
+				existing[0] = operation(v1alpha3.ContainerOperationCreate, v1alpha3.OperationSucceeded)
+			}
+			if tc.args.previous != nil {
+				existing = append(existing, *tc.args.previous)
+			}
+			c := v1alpha3test.NewMockContainer(testContainerName).WithStatusOperations(existing...).Container
+
+			recordOperation(c, tc.args.op, tc.args.err)
+
+			got := c.Status.Operations
+			if len(got) != tc.want.len {
+				t.Fatalf("\n%s\nrecordOperation(...): want %d records, got %d", tc.reason, tc.want.len, len(got))
+			}
+			if diff := cmp.Diff(tc.want.last, got[len(got)-1], equateOperations); diff != "" {
+				t.Errorf("\n%s\nrecordOperation(...): -want last record, +got:\n%s", tc.reason, diff)
+			}
+			if got[len(got)-1].Time.IsZero() {
+				t.Errorf("\n%s\nrecordOperation(...): want the time of the operation recorded", tc.reason)
+			}
+			dropped := tc.args.existing + 1 - tc.want.len
+			if tc.args.existing > 0 && dropped == 0 && got[0].Operation != v1alpha3.ContainerOperationCreate {
+				t.Errorf("\n%s\nrecordOperation(...): want the oldest record kept, got %+v", tc.reason, got[0])
+			}
+			if dropped > 0 && got[0].Operation == v1alpha3.ContainerOperationCreate {
+				t.Errorf("\n%s\nrecordOperation(...): want the oldest record dropped, got %+v", tc.reason, got[0])
+			}
+		})
+	}
+}
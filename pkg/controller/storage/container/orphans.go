@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+)
+
+const (
+	errListSecrets        = "cannot list secrets"
+	errGetSecretOwner     = "cannot get container %s that controls secret %s"
+	errDeleteOrphanSecret = "cannot delete orphaned secret %s"
+)
+
+// An orphanedSecretSweeper periodically deletes the connection secrets of
+// Containers that no longer exist, which are left behind when a Container is
+// deleted without its secret being deleted, for example because the provider
+// crashed. Only secrets that a Container controls are considered.
+type orphanedSecretSweeper struct {
+	kube     client.Client
+	interval time.Duration
+	log      logging.Logger
+}
+
+// Start sweeps orphaned secrets every interval until the supplied context is
+// done.
+func (s *orphanedSecretSweeper) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		deleted, err := s.sweep(ctx)
+		for _, nn := range deleted {
+			s.log.Info("Deleted orphaned connection secret", "secret", nn.String())
+		}
+		if err != nil {
+			s.log.Info("Cannot sweep orphaned connection secrets", "error", err.Error())
+		}
+	}, s.interval)
+	return nil
+}
+
+// sweep deletes every secret that is controlled by a Container that no longer
+// exists, and returns the secrets it deleted. Secrets that cannot be checked
+// or deleted do not stop the sweep; their errors are returned together.
+func (s *orphanedSecretSweeper) sweep(ctx context.Context) ([]types.NamespacedName, error) {
+	l := &corev1.SecretList{}
+	if err := s.kube.List(ctx, l); err != nil {
+		return nil, errors.Wrap(err, errListSecrets)
+	}
+
+	var deleted []types.NamespacedName
+	var failed []string
+	for i := range l.Items {
+		sec := &l.Items[i]
+		nn := types.NamespacedName{Namespace: sec.GetNamespace(), Name: sec.GetName()}
+		owner := metav1.GetControllerOf(sec)
+		if owner == nil || !isContainer(owner) {
+			continue
+		}
+		orphaned, err := s.orphaned(ctx, owner)
+		if err != nil {
+			failed = append(failed, errors.Wrapf(err, errGetSecretOwner, owner.Name, nn).Error())
+			continue
+		}
+		if !orphaned {
+			continue
+		}
+		if err := s.kube.Delete(ctx, sec); resource.IgnoreNotFound(err) != nil {
+			failed = append(failed, errors.Wrapf(err, errDeleteOrphanSecret, nn).Error())
+			continue
+		}
+		deleted = append(deleted, nn)
+	}
+	if len(failed) > 0 {
+		return deleted, errors.New(strings.Join(failed, "; "))
+	}
+	return deleted, nil
+}
+
+// orphaned returns true if the Container the supplied owner reference refers
+// to no longer exists. A Container with the same name but a different UID is
+// a new Container, so the secret of the old one is orphaned.
+func (s *orphanedSecretSweeper) orphaned(ctx context.Context, owner *metav1.OwnerReference) (bool, error) {
+	c := &v1alpha3.Container{}
+	err := s.kube.Get(ctx, types.NamespacedName{Name: owner.Name}, c)
+	if kerrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return c.GetUID() != owner.UID, nil
+}
+
+// isContainer returns true if the supplied owner reference refers to a
+// Container.
+func isContainer(owner *metav1.OwnerReference) bool {
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	return err == nil && gv.Group == v1alpha3.Group && owner.Kind == v1alpha3.ContainerKind
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+)
+
+func TestOrphanedSecretSweep(t *testing.T) {
+	container := func(name string, uid types.UID) *v1alpha3.Container {
+		return &v1alpha3.Container{ObjectMeta: metav1.ObjectMeta{Name: name, UID: uid}}
+	}
+	secret := func(name string, owner *metav1.OwnerReference) *corev1.Secret {
+		s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: name}}
+		if owner != nil {
+			s.SetOwnerReferences([]metav1.OwnerReference{*owner})
+		}
+		return s
+	}
+	controlledBy := func(apiVersion, kind, name string, uid types.UID) *metav1.OwnerReference {
+		ctrl := true
+		return &metav1.OwnerReference{APIVersion: apiVersion, Kind: kind, Name: name, UID: uid, Controller: &ctrl}
+	}
+	owned := func(name string, uid types.UID) *metav1.OwnerReference {
+		return controlledBy(v1alpha3.SchemeGroupVersion.String(), v1alpha3.ContainerKind, name, uid)
+	}
+	nn := func(name string) types.NamespacedName { return types.NamespacedName{Namespace: "test-ns", Name: name} }
+
+	cases := map[string]struct {
+		reason  string
+		objects []client.Object
+		deleted []types.NamespacedName
+		kept    []types.NamespacedName
+	}{
+		"ContainerDeleted": {
+			reason:  "A secret controlled by a Container that no longer exists should be deleted.",
+			objects: []client.Object{secret("orphan", owned("gone", "uid-gone"))},
+			deleted: []types.NamespacedName{nn("orphan")},
+		},
+		"ContainerExists": {
+			reason:  "A secret controlled by a Container that exists should be kept.",
+			objects: []client.Object{container("live", "uid-live"), secret("valid", owned("live", "uid-live"))},
+			kept:    []types.NamespacedName{nn("valid")},
+		},
+		"ContainerRecreated": {
+			reason:  "A secret controlled by an earlier Container with the same name as an existing one should be deleted.",
+			objects: []client.Object{container("live", "uid-new"), secret("stale", owned("live", "uid-old"))},
+			deleted: []types.NamespacedName{nn("stale")},
+		},
+		"UnrelatedSecrets": {
+			reason: "Secrets that are not controlled by a Container should be kept.",
+			objects: []client.Object{
+				secret("unowned", nil),
+				secret("other-kind", controlledBy(v1alpha3.SchemeGroupVersion.String(), v1alpha3.AccountKind, "gone", "uid-gone")),
+				secret("other-group", controlledBy("example.org/v1", v1alpha3.ContainerKind, "gone", "uid-gone")),
+			},
+			kept: []types.NamespacedName{nn("unowned"), nn("other-kind"), nn("other-group")},
+		},
+		"Mixed": {
+			reason: "Only orphaned secrets should be deleted from a mix of orphaned and valid secrets.",
+			objects: []client.Object{
+				container("live", "uid-live"),
+				secret("a-orphan", owned("gone", "uid-gone")),
+				secret("b-valid", owned("live", "uid-live")),
+				secret("c-unowned", nil),
+			},
+			deleted: []types.NamespacedName{nn("a-orphan")},
+			kept:    []types.NamespacedName{nn("b-valid"), nn("c-unowned")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := fake.NewClientBuilder().WithObjects(tc.objects...).Build()
+			s := &orphanedSecretSweeper{kube: kube, log: logging.NewNopLogger()}
+
+			deleted, err := s.sweep(context.Background())
+			if err != nil {
+				t.Fatalf("\n%s\ns.sweep(...): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\ns.sweep(...): -want deleted, +got deleted:\n%s", tc.reason, diff)
+			}
+			for _, d := range tc.deleted {
+				if err := kube.Get(context.Background(), d, &corev1.Secret{}); !kerrors.IsNotFound(err) {
+					t.Errorf("\n%s\ns.sweep(...): want secret %s deleted, got error %v", tc.reason, d, err)
+				}
+			}
+			for _, k := range tc.kept {
+				if err := kube.Get(context.Background(), k, &corev1.Secret{}); err != nil {
+					t.Errorf("\n%s\ns.sweep(...): want secret %s kept, got error %v", tc.reason, k, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+// ReasonConfigSnapshot is the reason of events that record the configuration
+// of a container just before it is deleted, so that it can be recreated if it
+// was deleted by accident.
+const ReasonConfigSnapshot event.Reason = "ConfigSnapshot"
+
+const (
+	errSnapshotConfig = "cannot snapshot container configuration before deleting it"
+
+	// redacted replaces the values of sensitive metadata keys in snapshots.
+	redacted = "REDACTED"
+)
+
+// A configSnapshotter records the public access type and metadata of
+// containers in an event before they are deleted. The values of metadata keys
+// that start with its sensitive prefix are redacted, since events may be read
+// more widely than the containers themselves. A nil snapshotter records
+// nothing.
+type configSnapshotter struct {
+	record          event.Recorder
+	sensitivePrefix string
+}
+
+// snapshot records the observed configuration of the supplied container.
+// Containers that are already gone have nothing to record.
+func (s *configSnapshotter) snapshot(ctx context.Context, c *v1alpha3.Container, o storage.ContainerOperations) error {
+	if s == nil {
+		return nil
+	}
+	access, md, err := o.Get(ctx)
+	if storage.IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errSnapshotConfig)
+	}
+
+	cfg := storage.ContainerConfig{PublicAccessType: *access}
+	for k, v := range md {
+		if s.sensitivePrefix != "" && strings.HasPrefix(strings.ToLower(k), strings.ToLower(s.sensitivePrefix)) {
+			v = redacted
+		}
+		if cfg.Metadata == nil {
+			cfg.Metadata = map[string]string{}
+		}
+		cfg.Metadata[k] = v
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return errors.Wrap(err, errSnapshotConfig)
+	}
+	name := externalName(c)
+	s.record.Event(c, event.Normal(ReasonConfigSnapshot,
+		fmt.Sprintf("Container %s was configured as follows before it was deleted: %s", name, b),
+		"container", name))
+	return nil
+}
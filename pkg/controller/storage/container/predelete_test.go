@@ -0,0 +1,204 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+	azurestoragefake "github.com/crossplane-contrib/provider-azure/pkg/clients/storage/fake"
+)
+
+func TestDeletionConfigSnapshot(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+	blob := azblob.PublicAccessBlob
+
+	snapshot := func(cfg string) event.Event {
+		return event.Event{
+			Type:        event.TypeNormal,
+			Reason:      ReasonConfigSnapshot,
+			Message:     "Container " + testContainerName + " was configured as follows before it was deleted: " + cfg,
+			Annotations: map[string]string{"container": testContainerName},
+		}
+	}
+
+	type want struct {
+		calls     []string
+		events    []event.Event
+		finalizer bool
+		synced    xpv1.Condition
+	}
+	cases := map[string]struct {
+		reason   string
+		disabled bool
+		access   *azblob.PublicAccessType
+		metadata azblob.Metadata
+		getErr   error
+		want     want
+	}{
+		"Disabled": {
+			reason:   "A container should be deleted without a snapshot when snapshots are disabled.",
+			disabled: true,
+			want:     want{calls: []string{"Delete"}},
+		},
+		"Snapshot": {
+			reason:   "A container's public access type and metadata should be recorded before it is deleted.",
+			access:   &blob,
+			metadata: azblob.Metadata{"owner": "crossplane"},
+			want: want{
+				calls:  []string{"Get", "Delete after 1 events"},
+				events: []event.Event{snapshot(`{"publicAccessType":"blob","metadata":{"owner":"crossplane"}}`)},
+			},
+		},
+		"Redacted": {
+			reason:   "The values of sensitive metadata keys should be redacted from snapshots.",
+			access:   &blob,
+			metadata: azblob.Metadata{"secret-token": "hunter2"},
+			want: want{
+				calls:  []string{"Get", "Delete after 1 events"},
+				events: []event.Event{snapshot(`{"publicAccessType":"blob","metadata":{"secret-token":"REDACTED"}}`)},
+			},
+		},
+		"AlreadyGone": {
+			reason: "A container that is already gone should be deleted without a snapshot.",
+			getErr: newStorageError(http.StatusNotFound, azblob.ServiceCodeContainerNotFound),
+			want:   want{calls: []string{"Get", "Delete after 0 events"}},
+		},
+		"GetFailed": {
+			reason: "A container whose configuration could not be snapshotted should not be deleted.",
+			getErr: errBoom,
+			want: want{
+				calls:     []string{"Get"},
+				finalizer: true,
+				synced:    xpv1.ReconcileError(errors.Wrap(errBoom, errSnapshotConfig)),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := v1alpha3test.NewMockContainer(testContainerName).
+				WithSpecDeletionPolicy(xpv1.DeletionDelete).
+				WithFinalizer(finalizer).Container
+
+			rec := &eventRecorder{}
+			var snapshots *configSnapshotter
+			if !tc.disabled {
+				snapshots = &configSnapshotter{record: rec, sensitivePrefix: DefaultSensitiveMetadataPrefix}
+			}
+
+			var calls []string
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockGet = func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+				calls = append(calls, "Get")
+				return tc.access, tc.metadata, tc.getErr
+			}
+			ops.MockDelete = func(context.Context) error {
+				if snapshots == nil {
+					calls = append(calls, "Delete")
+					return nil
+				}
+				calls = append(calls, fmt.Sprintf("Delete after %d events", len(rec.events)))
+				return nil
+			}
+			ops.MockExists = func(context.Context) (bool, error) { return false, nil }
+			csd := &containerSyncdeleter{
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				deletion:            wait.Backoff{Duration: time.Millisecond, Steps: 1},
+				snapshots:           snapshots,
+			}
+			if _, err := csd.delete(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.delete(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, rec.events); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): -want events, +got events:\n%s", tc.reason, diff)
+			}
+			if got := meta.FinalizerExists(c, finalizer); got != tc.want.finalizer {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): want finalizer %t, got %t", tc.reason, tc.want.finalizer, got)
+			}
+			want := xpv1.Deleting()
+			if tc.want.synced.Type != "" {
+				want = tc.want.synced
+			}
+			if diff := cmp.Diff(want, c.Status.GetCondition(want.Type), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.delete(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// setupManager is a manager that supplies only what is needed to build a
+// Reconciler.
+type setupManager struct {
+	ctrl.Manager
+	kube     client.Client
+	recorder *record.FakeRecorder
+}
+
+func (m *setupManager) GetClient() client.Client { return m.kube }
+
+func (m *setupManager) GetEventRecorderFor(string) record.EventRecorder { return m.recorder }
+
+func TestSetupConfigSnapshotRedacted(t *testing.T) {
+	ctx := context.TODO()
+	blob := azblob.PublicAccessBlob
+
+	mgr := &setupManager{kube: test.NewMockClient(), recorder: record.NewFakeRecorder(1)}
+	opts := DefaultOptions()
+	opts.SnapshotBeforeDelete = true
+	r := newReconciler(mgr, controller.Options{Logger: logging.NewNopLogger(), Features: &feature.Flags{}}, opts)
+
+	ops := azurestoragefake.NewMockContainerOperations()
+	ops.MockGet = func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+		return &blob, azblob.Metadata{"secret-token": "hunter2"}, nil
+	}
+	c := v1alpha3test.NewMockContainer(testContainerName).Container
+	if err := r.syncdeleterMaker.(*containerSyncdeleterMaker).snapshots.snapshot(ctx, c, ops); err != nil {
+		t.Fatalf("configSnapshotter.snapshot(...): unexpected error: %v", err)
+	}
+	got := <-mgr.recorder.Events
+	if strings.Contains(got, "hunter2") || !strings.Contains(got, `"secret-token":"REDACTED"`) {
+		t.Errorf("\nThe values of sensitive metadata keys should be redacted from snapshots recorded by a controller set up with the default options.\nconfigSnapshotter.snapshot(...): got event %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+const (
+	errRecreateUnconfirmed = "force recreate of container %s is not confirmed: set the " + v1alpha3.AnnotationKeyForceRecreate + " annotation to the Container's UID to recreate it, or remove it"
+	errRecreateProtected   = "deletion protected: container %s cannot be recreated while spec.deletionProtection is true; set it to false to recreate it"
+	errRecreateObserveOnly = "container %s cannot be recreated because the controller only observes containers"
+	errRecreateDelete      = "cannot delete container to recreate it"
+)
+
+// ReasonRecreating indicates that a container was deleted to be recreated,
+// and that Azure has not yet finished deleting it.
+const ReasonRecreating xpv1.ConditionReason = "Recreating"
+
+// forceRecreate deletes the container if recreating it was requested and
+// confirmed, and creates it again once Azure reports that it no longer
+// exists. Unconfirmed requests are reported, and keep the container from
+// being reconciled until they are confirmed or removed, as do requests to
+// recreate a container claimed by another owner. It returns true if
+// the reconcile should end with the returned result and error.
+func (csd *containerSyncdeleter) forceRecreate(ctx context.Context) (reconcile.Result, bool, error) {
+	c := csd.container
+	token, ok := c.GetAnnotations()[v1alpha3.AnnotationKeyForceRecreate]
+	if !ok {
+		return reconcile.Result{}, false, nil
+	}
+
+	var err error
+	switch {
+	case token == "" || token != string(c.GetUID()):
+		err = errors.Errorf(errRecreateUnconfirmed, externalName(c))
+	case c.Spec.DeletionProtection:
+		err = errors.Errorf(errRecreateProtected, externalName(c))
+	case csd.observeOnly:
+		err = errors.Errorf(errRecreateObserveOnly, externalName(c))
+	default:
+		err = csd.checkOwner(ctx)
+	}
+	if err != nil {
+		csd.conditions.setReconcileError(c, err)
+		return reconcile.Result{RequeueAfter: jittered(csd.poll, csd.jitter)}, true, csd.kube.Status().Update(ctx, c)
+	}
+
+	// The container is deleted as it would be were its Container deleted,
+	// so its blobs are archived, and their versions purged, first. It may
+	// already be being deleted by an earlier attempt whose deletion we did
+	// not see complete.
+	err = csd.archive(ctx)
+	if err == nil {
+		err = csd.deleteContainer(ctx)
+	}
+	if storage.IsSnapshotsPresent(err) {
+		err = csd.deleteWithSnapshots(ctx)
+	}
+	if storage.IsContainerBeingDeleted(err) {
+		err = nil
+	}
+	if err != nil && !storage.IsNotFoundError(err) {
+		recordOperation(c, v1alpha3.ContainerOperationDelete, err)
+		csd.conditions.setReconcileError(c, errors.Wrap(err, errRecreateDelete))
+		return resultRequeue, true, csd.kube.Status().Update(ctx, c)
+	}
+	if err == nil {
+		recordOperation(c, v1alpha3.ContainerOperationDelete, nil)
+	}
+
+	// The container must be gone before it is created again, or creating it
+	// would fail, or find the container that was meant to be deleted.
+	err = wait.ExponentialBackoffWithContext(ctx, csd.deletion, func() (bool, error) {
+		exists, err := csd.Exists(ctx)
+		return !exists, err
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		c.Status.SetConditions(recreating())
+		return resultRequeue, true, csd.kube.Status().Update(ctx, c)
+	}
+	if err != nil {
+		csd.conditions.setReconcileError(c, errors.Wrap(err, errVerifyDeletion))
+		return resultRequeue, true, csd.kube.Status().Update(ctx, c)
+	}
+
+	// Creating the container updates it, which removes the request. Should
+	// creating fail before then the request is kept, and deleting the
+	// container that no longer exists is retried harmlessly.
+	meta.RemoveAnnotations(c, v1alpha3.AnnotationKeyForceRecreate)
+	res, err := csd.create(ctx)
+	return res, true, err
+}
+
+// recreating returns a condition indicating that a container was deleted to
+// be recreated, and is waiting for Azure to finish deleting it.
+func recreating() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeSynced,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonRecreating,
+		Message:            "container was deleted to be recreated; waiting for Azure to finish deleting it",
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+	azurestoragefake "github.com/crossplane-contrib/provider-azure/pkg/clients/storage/fake"
+)
+
+func TestForceRecreate(t *testing.T) {
+	ctx := context.TODO()
+	const uid = "test-uid"
+
+	type want struct {
+		calls      []string
+		annotation bool
+		synced     xpv1.ConditionReason
+	}
+	cases := map[string]struct {
+		reason      string
+		annotations map[string]string
+		protected   bool
+		observeOnly bool
+		claimed     azblob.Metadata
+		archive     string
+		purge       bool
+		deleteErr   error
+		exists      bool
+		want        want
+	}{
+		"NotRequested": {
+			reason: "A container that is not to be recreated should be reconciled as usual.",
+			exists: true,
+			want:   want{calls: []string{"get", "update"}},
+		},
+		"Unconfirmed": {
+			reason:      "A request to recreate a container that is not confirmed by its UID should be reported and not acted on.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: "true"},
+			exists:      true,
+			want:        want{annotation: true, synced: xpv1.ReasonReconcileError},
+		},
+		"EmptyToken": {
+			reason:      "A request to recreate a container without a confirmation token should be reported and not acted on.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: ""},
+			exists:      true,
+			want:        want{annotation: true, synced: xpv1.ReasonReconcileError},
+		},
+		"DeletionProtected": {
+			reason:      "A container that is protected from deletion should not be recreated.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: uid},
+			protected:   true,
+			exists:      true,
+			want:        want{annotation: true, synced: xpv1.ReasonReconcileError},
+		},
+		"ObserveOnly": {
+			reason:      "A container should not be recreated when the controller only observes containers.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: uid},
+			observeOnly: true,
+			exists:      true,
+			want:        want{annotation: true, synced: xpv1.ReasonReconcileError},
+		},
+		"OwnedByOther": {
+			reason:      "A container claimed for another owner should not be recreated, and should report a conflict.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: uid},
+			claimed:     azblob.Metadata{"owner": "terraform"},
+			exists:      true,
+			want:        want{calls: []string{"get"}, annotation: true, synced: ReasonConflict},
+		},
+		"OwnedByUs": {
+			reason:      "A container claimed for this provider should be recreated.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: uid},
+			claimed:     azblob.Metadata{"owner": "crossplane"},
+			want:        want{calls: []string{"get", "delete", "exists", "create"}},
+		},
+		"Recreated": {
+			reason:      "A confirmed request should delete the container, verify it is gone, and create it again.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: uid},
+			want:        want{calls: []string{"delete", "exists", "create"}},
+		},
+		"Archived": {
+			reason:      "A container with an archive container should have its blobs archived before it is deleted to be recreated.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: uid},
+			archive:     "archive",
+			want:        want{calls: []string{"archive", "delete", "exists", "create"}},
+		},
+		"VersionsPurged": {
+			reason:      "A container that asks for its blob versions to be purged should be deleted with them to be recreated.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: uid},
+			purge:       true,
+			want:        want{calls: []string{"deleteWithVersions", "exists", "create"}},
+		},
+		"AlreadyDeleted": {
+			reason:      "A container that no longer exists should be created again.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: uid},
+			deleteErr:   newStorageError(http.StatusNotFound, azblob.ServiceCodeContainerNotFound),
+			want:        want{calls: []string{"delete", "exists", "create"}},
+		},
+		"DeletionPending": {
+			reason:      "A container that Azure has not finished deleting should not be created again yet.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: uid},
+			deleteErr:   newStorageError(http.StatusConflict, azblob.ServiceCodeContainerBeingDeleted),
+			exists:      true,
+			want:        want{calls: []string{"delete", "exists"}, annotation: true, synced: ReasonRecreating},
+		},
+		"DeleteFailed": {
+			reason:      "A container that cannot be deleted should not be created again.",
+			annotations: map[string]string{v1alpha3.AnnotationKeyForceRecreate: uid},
+			deleteErr:   newStorageError(http.StatusForbidden, azblob.ServiceCodeInsufficientAccountPermissions),
+			exists:      true,
+			want:        want{calls: []string{"delete"}, annotation: true, synced: ReasonAuthorizationFailed},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var calls []string
+			ops := azurestoragefake.NewMockContainerOperations()
+			ops.MockGet = func(context.Context) (*azblob.PublicAccessType, azblob.Metadata, error) {
+				calls = append(calls, "get")
+				none := azblob.PublicAccessNone
+				return &none, tc.claimed, nil
+			}
+			ops.MockDelete = func(context.Context) error {
+				calls = append(calls, "delete")
+				return tc.deleteErr
+			}
+			ops.MockArchiveBlobs = func(context.Context, string) error {
+				calls = append(calls, "archive")
+				return nil
+			}
+			ops.MockDeleteWithVersions = func(context.Context) error {
+				calls = append(calls, "deleteWithVersions")
+				return tc.deleteErr
+			}
+			ops.MockExists = func(context.Context) (bool, error) {
+				calls = append(calls, "exists")
+				return tc.exists, nil
+			}
+			cu := newMockCreateUpdater()
+			cu.mockCreate = func(context.Context) (reconcile.Result, error) {
+				calls = append(calls, "create")
+				return reconcile.Result{}, nil
+			}
+			cu.mockUpdate = func(context.Context, *azblob.PublicAccessType, azblob.Metadata) (reconcile.Result, error) {
+				calls = append(calls, "update")
+				return reconcile.Result{}, nil
+			}
+
+			c := v1alpha3test.NewMockContainer(testContainerName).WithFinalizer(finalizer).Container
+			c.SetUID(uid)
+			c.SetAnnotations(tc.annotations)
+			c.Spec.DeletionProtection = tc.protected
+			c.Spec.ArchiveContainer = tc.archive
+			c.Spec.PurgeVersionsOnDelete = tc.purge
+			m := newProvisionedManagementOperations()
+			m.MockGetBlobVersioning = func(context.Context) (bool, error) { return true, nil }
+			csd := &containerSyncdeleter{
+				createupdater:       cu,
+				ContainerOperations: ops,
+				kube:                test.NewMockClient(),
+				container:           c,
+				observeOnly:         tc.observeOnly,
+				conditions:          DefaultErrorConditions(),
+				deletion:            wait.Backoff{Duration: time.Millisecond, Steps: 1},
+				management: func(context.Context) (storage.ManagementOperations, error) {
+					return m, nil
+				},
+			}
+			if tc.claimed != nil {
+				csd.ownerKey, csd.owner = "owner", "crossplane"
+			}
+
+			if _, err := csd.sync(ctx); err != nil {
+				t.Fatalf("\n%s\ncontainerSyncdeleter.sync(): unexpected error: %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): -want calls, +got calls:\n%s", tc.reason, diff)
+			}
+			_, annotated := c.GetAnnotations()[v1alpha3.AnnotationKeyForceRecreate]
+			if annotated != tc.want.annotation {
+				t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want force recreate annotation %t, got %t", tc.reason, tc.want.annotation, annotated)
+			}
+			if tc.want.synced != "" {
+				if got := c.Status.GetCondition(xpv1.TypeSynced).Reason; got != tc.want.synced {
+					t.Errorf("\n%s\ncontainerSyncdeleter.sync(): want Synced reason %q, got %q", tc.reason, tc.want.synced, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+)
+
+// ReasonPublicAccessDrift is the reason of warning events recorded when the
+// public access type of a container has drifted and correcting it is delayed.
+const ReasonPublicAccessDrift event.Reason = "PublicAccessDriftDetected"
+
+// A publicAccessRemediator delays correcting drift of the public access type
+// of containers, so that intentional, temporary changes are not reverted
+// straight away. A nil remediator, or one without a delay, corrects drift
+// immediately.
+type publicAccessRemediator struct {
+	delay  time.Duration
+	record event.Recorder
+}
+
+// hold returns how much longer correcting the public access type of the
+// supplied container should be delayed, given whether it drifted from the
+// supplied desired type. The drift is reported when it is first observed,
+// and when it was first observed is recorded in the container's status, which
+// the caller must persist. It returns zero once the drift should be
+// corrected.
+func (r *publicAccessRemediator) hold(c *v1alpha3.Container, want, got azblob.PublicAccessType) time.Duration {
+	want, _ = canonicalize(want, nil)
+	got, _ = canonicalize(got, nil)
+	if want == got || r == nil || r.delay <= 0 {
+		c.Status.AtProvider.PublicAccessDriftSince = nil
+		return 0
+	}
+	since := c.Status.AtProvider.PublicAccessDriftSince
+	if since == nil {
+		now := metav1.Now()
+		since = &now
+		c.Status.AtProvider.PublicAccessDriftSince = since
+		r.record.Event(c, event.Warning(ReasonPublicAccessDrift, errors.Errorf(
+			"Container %s has public access type %s instead of %s, which will be corrected if it persists for %s",
+			externalName(c), publicAccessName(got), publicAccessName(want), r.delay)))
+	}
+	if remaining := r.delay - time.Since(since.Time); remaining > 0 {
+		return remaining
+	}
+	c.Status.AtProvider.PublicAccessDriftSince = nil
+	return 0
+}
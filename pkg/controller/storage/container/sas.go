@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+const (
+	errNoConnectionSecret  = "shared access signatures require a connection secret"
+	errNoSASAccountKey     = "shared access signatures cannot be issued: " + errNoAccountKey
+	errSecretNotControlled = "connection secret %s is controlled by another resource"
+	errCreateSecret        = "cannot create connection secret"
+	errUpdateSecret        = "cannot update connection secret"
+)
+
+// A sasIssuer issues SAS tokens for a container, signed with the key of its
+// storage account.
+type sasIssuer struct {
+	account string
+	key     string
+
+	// url of the container, without any SAS token.
+	url url.URL
+
+	now func() time.Time
+}
+
+func newSASIssuer(account, key string, u url.URL) *sasIssuer {
+	u.RawQuery = ""
+	return &sasIssuer{account: account, key: key, url: u, now: time.Now}
+}
+
+// issue returns the connection details of the supplied container: its URL,
+// and the tokens of its shared access signatures keyed by name. Tokens among
+// the supplied current connection details are kept until they are due to be
+// reissued. It also returns when the first of the tokens is due.
+func (i *sasIssuer) issue(c *v1alpha3.Container, current map[string][]byte) (map[string][]byte, time.Time, error) {
+	if i.key == "" {
+		return nil, time.Time{}, errors.New(errNoSASAccountKey)
+	}
+	name := externalName(c)
+	generate := func(spec storage.SASSpec) (string, error) {
+		return storage.GenerateContainerSAS(i.account, i.key, name, spec)
+	}
+	data, due, err := storage.IssueSAS(c.Spec.SharedAccessSignatures, current, i.now(), generate, xpv1.ResourceCredentialsSecretEndpointKey)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data[xpv1.ResourceCredentialsSecretEndpointKey] = []byte(i.url.String())
+	return data, due, nil
+}
+
+// publishSharedAccessSignatures writes the container's URL and shared access
+// signatures to its connection secret, if it declares any. It returns how long
+// it is until the first of them is due to be reissued, or zero if none are.
+func (ccu *containerCreateUpdater) publishSharedAccessSignatures(ctx context.Context) (time.Duration, error) {
+	c := ccu.container
+	if ccu.sas == nil || len(c.Spec.SharedAccessSignatures) == 0 {
+		return 0, nil
+	}
+	ref := c.GetWriteConnectionSecretToReference()
+	if ref == nil {
+		return 0, errors.New(errNoConnectionSecret)
+	}
+	s := &corev1.Secret{}
+	err := ccu.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s)
+	if resource.IgnoreNotFound(err) != nil {
+		return 0, errors.Wrap(err, errGetSecret)
+	}
+	exists := err == nil
+	if o := metav1.GetControllerOf(s); exists && o != nil && o.UID != c.GetUID() {
+		return 0, errors.Errorf(errSecretNotControlled, ref.Namespace+"/"+ref.Name)
+	}
+
+	data, due, err := ccu.sas.issue(c, s.Data)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case !exists:
+		s = resource.ConnectionSecretFor(c, v1alpha3.ContainerGroupVersionKind)
+		s.Data = data
+		if err := ccu.kube.Create(ctx, s); err != nil {
+			return 0, errors.Wrap(err, errCreateSecret)
+		}
+	case !reflect.DeepEqual(s.Data, data):
+		s.Data = data
+		if err := ccu.kube.Update(ctx, s); err != nil {
+			return 0, errors.Wrap(err, errUpdateSecret)
+		}
+	}
+	return due.Sub(ccu.sas.now()), nil
+}
+
+// requeueAfter returns the supplied poll interval, or the supplied time until
+// a shared access signature is due to be reissued if that is sooner.
+func requeueAfter(poll, due time.Duration) time.Duration {
+	if due > 0 && due < poll {
+		return due
+	}
+	return poll
+}
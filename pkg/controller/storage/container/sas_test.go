@@ -0,0 +1,193 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+)
+
+func TestPublishSharedAccessSignatures(t *testing.T) {
+	ctx := context.TODO()
+	errBoom := errors.New("boom")
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := "dGVzdC1rZXkK"
+	u := url.URL{Scheme: "https", Host: testAccountName + ".blob.core.windows.net", Path: "/" + testContainerName, RawQuery: "sv=account-sas"}
+	endpoint := "https://" + testAccountName + ".blob.core.windows.net/" + testContainerName
+	read := v1alpha3.SharedAccessSignature{Name: storage.SASRead, Permissions: "rl"}
+
+	// issued is the token the container is issued for read at the supplied
+	// time.
+	issued := func(at time.Time) []byte {
+		t, err := storage.GenerateContainerSAS(testAccountName, key, testContainerName, storage.SASSpec{Permissions: "rl", Expiry: at.Add(v1alpha3.DefaultSASValidity)})
+		if err != nil {
+			panic(err)
+		}
+		return []byte(t)
+	}
+	current := map[string][]byte{xpv1.ResourceCredentialsSecretEndpointKey: []byte(endpoint), storage.SASRead: issued(now.Add(-time.Hour))}
+
+	type want struct {
+		data    map[string][]byte
+		written bool
+		due     time.Duration
+		err     error
+	}
+	cases := map[string]struct {
+		reason string
+		sigs   []v1alpha3.SharedAccessSignature
+		noRef  bool
+		key    string
+		secret *corev1.Secret
+		getErr error
+		want   want
+	}{
+		"NoneDeclared": {
+			reason: "Nothing should be written if the container declares no shared access signatures.",
+			want:   want{},
+		},
+		"CreateSecret": {
+			reason: "The container's URL and tokens should be written to a new connection secret.",
+			sigs:   []v1alpha3.SharedAccessSignature{read},
+			key:    key,
+			getErr: kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "cool"),
+			want: want{
+				data:    map[string][]byte{xpv1.ResourceCredentialsSecretEndpointKey: []byte(endpoint), storage.SASRead: issued(now)},
+				written: true,
+				due:     16 * time.Hour,
+			},
+		},
+		"KeepCurrent": {
+			reason: "Tokens that are not yet due to be reissued should be kept, and the secret not written.",
+			sigs:   []v1alpha3.SharedAccessSignature{read},
+			key:    key,
+			secret: &corev1.Secret{Data: current},
+			want:   want{due: 15 * time.Hour},
+		},
+		"Reissue": {
+			reason: "Tokens that are due to be reissued should be replaced.",
+			sigs:   []v1alpha3.SharedAccessSignature{read},
+			key:    key,
+			secret: &corev1.Secret{Data: map[string][]byte{storage.SASRead: issued(now.Add(-20 * time.Hour))}},
+			want: want{
+				data:    map[string][]byte{xpv1.ResourceCredentialsSecretEndpointKey: []byte(endpoint), storage.SASRead: issued(now)},
+				written: true,
+				due:     16 * time.Hour,
+			},
+		},
+		"NoConnectionSecret": {
+			reason: "Shared access signatures cannot be published without a connection secret.",
+			sigs:   []v1alpha3.SharedAccessSignature{read},
+			noRef:  true,
+			key:    key,
+			want:   want{err: errors.New(errNoConnectionSecret)},
+		},
+		"NoAccountKey": {
+			reason: "Shared access signatures cannot be issued without the account key.",
+			sigs:   []v1alpha3.SharedAccessSignature{read},
+			secret: &corev1.Secret{},
+			want:   want{err: errors.New(errNoSASAccountKey)},
+		},
+		"ReservedName": {
+			reason: "Shared access signatures may not overwrite the container's URL.",
+			sigs:   []v1alpha3.SharedAccessSignature{{Name: xpv1.ResourceCredentialsSecretEndpointKey, Permissions: "r"}},
+			key:    key,
+			secret: &corev1.Secret{},
+			want:   want{err: errors.Errorf("shared access signature name %q is reserved", xpv1.ResourceCredentialsSecretEndpointKey)},
+		},
+		"ControlledByAnother": {
+			reason: "Connection secrets controlled by another resource should not be written.",
+			sigs:   []v1alpha3.SharedAccessSignature{read},
+			key:    key,
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{UID: types.UID("other"), Controller: &[]bool{true}[0]}}}},
+			want:   want{err: errors.Errorf(errSecretNotControlled, "coolns/coolsecret")},
+		},
+		"GetFailed": {
+			reason: "Errors getting the connection secret should be returned.",
+			sigs:   []v1alpha3.SharedAccessSignature{read},
+			key:    key,
+			getErr: errBoom,
+			want:   want{err: errors.Wrap(errBoom, errGetSecret)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var data map[string][]byte
+			written := false
+			write := func(_ context.Context, obj client.Object) {
+				data, written = obj.(*corev1.Secret).Data, true
+			}
+			kube := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					if tc.secret != nil {
+						tc.secret.DeepCopyInto(obj.(*corev1.Secret))
+					}
+					return tc.getErr
+				},
+				MockCreate: func(ctx context.Context, obj client.Object, _ ...client.CreateOption) error {
+					write(ctx, obj)
+					return nil
+				},
+				MockUpdate: func(ctx context.Context, obj client.Object, _ ...client.UpdateOption) error {
+					write(ctx, obj)
+					return nil
+				},
+			}
+			c := v1alpha3test.NewMockContainer(testContainerName).Container
+			c.Spec.SharedAccessSignatures = tc.sigs
+			if !tc.noRef {
+				c.Spec.WriteConnectionSecretToReference = &xpv1.SecretReference{Namespace: "coolns", Name: "coolsecret"}
+			}
+			sas := newSASIssuer(testAccountName, tc.key, u)
+			sas.now = func() time.Time { return now }
+			ccu := &containerCreateUpdater{kube: kube, container: c, sas: sas}
+
+			due, err := ccu.publishSharedAccessSignatures(ctx)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.publishSharedAccessSignatures(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if due != tc.want.due {
+				t.Errorf("\n%s\ncontainerCreateUpdater.publishSharedAccessSignatures(...): want due in %s, got %s", tc.reason, tc.want.due, due)
+			}
+			if written != tc.want.written {
+				t.Errorf("\n%s\ncontainerCreateUpdater.publishSharedAccessSignatures(...): want secret written %t, got %t", tc.reason, tc.want.written, written)
+			}
+			if diff := cmp.Diff(tc.want.data, data); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.publishSharedAccessSignatures(...): -want data, +got data:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
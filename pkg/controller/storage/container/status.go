@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+)
+
+// A statusDebouncer is a client that does not write the status of a Container
+// when it is unchanged from the status last read or written, so that
+// reconciles that change nothing do not load the API server. Only Containers
+// are debounced; the status of every other kind of object is written as is.
+type statusDebouncer struct {
+	client.Client
+
+	mu   sync.Mutex
+	last map[types.NamespacedName]v1alpha3.ContainerStatus
+}
+
+func newStatusDebouncer(c client.Client) *statusDebouncer {
+	return &statusDebouncer{Client: c, last: map[types.NamespacedName]v1alpha3.ContainerStatus{}}
+}
+
+// Get gets the supplied object, and remembers its status if it is a Container.
+func (d *statusDebouncer) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	err := d.Client.Get(ctx, key, obj)
+	if c, ok := obj.(*v1alpha3.Container); ok {
+		if err != nil {
+			d.forget(key)
+			return err
+		}
+		d.remember(c)
+	}
+	return err
+}
+
+// Update updates the supplied object, and remembers the status it was
+// returned with if it is a Container.
+func (d *statusDebouncer) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := d.Client.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+	if c, ok := obj.(*v1alpha3.Container); ok {
+		d.remember(c)
+	}
+	return nil
+}
+
+// Status returns a writer that skips unchanged Container statuses.
+func (d *statusDebouncer) Status() client.StatusWriter {
+	return &debouncedStatusWriter{StatusWriter: d.Client.Status(), debouncer: d}
+}
+
+func (d *statusDebouncer) remember(c *v1alpha3.Container) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.last[types.NamespacedName{Namespace: c.GetNamespace(), Name: c.GetName()}] = *c.Status.DeepCopy()
+}
+
+func (d *statusDebouncer) forget(key client.ObjectKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.last, key)
+}
+
+// unchanged returns true if the status of the supplied Container is
+// semantically equal to the one last read or written.
+func (d *statusDebouncer) unchanged(c *v1alpha3.Container) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	last, ok := d.last[types.NamespacedName{Namespace: c.GetNamespace(), Name: c.GetName()}]
+	return ok && equality.Semantic.DeepEqual(last, c.Status)
+}
+
+// A debouncedStatusWriter writes the status of objects, unless they are
+// Containers whose status is unchanged.
+type debouncedStatusWriter struct {
+	client.StatusWriter
+	debouncer *statusDebouncer
+}
+
+// Update writes the status of the supplied object, unless it is a Container
+// whose status is unchanged.
+func (w *debouncedStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c, ok := obj.(*v1alpha3.Container)
+	if !ok {
+		return w.StatusWriter.Update(ctx, obj, opts...)
+	}
+	if w.debouncer.unchanged(c) {
+		return nil
+	}
+	if err := w.StatusWriter.Update(ctx, obj, opts...); err != nil {
+		// We don't know what status was written, if any.
+		w.debouncer.forget(client.ObjectKeyFromObject(c))
+		return err
+	}
+	w.debouncer.remember(c)
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+)
+
+func TestStatusDebouncer(t *testing.T) {
+	errBoom := errors.New("boom")
+	observed := func() *v1alpha3.Container {
+		c := v1alpha3test.NewMockContainer(testContainerName).Container
+		c.Status.SetConditions(xpv1.Available(), xpv1.ReconcileSuccess())
+		c.Status.AtProvider.ETag = "etag"
+		return c
+	}
+
+	cases := map[string]struct {
+		reason  string
+		read    bool
+		changes []func(*v1alpha3.Container)
+		failed  error
+		want    int
+	}{
+		"Unchanged": {
+			reason:  "The status of a Container that a reconcile left unchanged should not be written.",
+			read:    true,
+			changes: []func(*v1alpha3.Container){func(c *v1alpha3.Container) { c.Status.SetConditions(xpv1.ReconcileSuccess()) }},
+			want:    0,
+		},
+		"ConditionChanged": {
+			reason:  "A changed condition should be written.",
+			read:    true,
+			changes: []func(*v1alpha3.Container){func(c *v1alpha3.Container) { c.Status.SetConditions(xpv1.ReconcileError(errBoom)) }},
+			want:    1,
+		},
+		"DriftChanged": {
+			reason: "Changed drift should be written.",
+			read:   true,
+			changes: []func(*v1alpha3.Container){func(c *v1alpha3.Container) {
+				c.Status.Drift = &v1alpha3.ContainerDrift{MetadataAdded: []string{"team"}}
+			}},
+			want: 1,
+		},
+		"OperationRecorded": {
+			reason:  "A newly recorded operation, and its request ID, should be written.",
+			read:    true,
+			changes: []func(*v1alpha3.Container){func(c *v1alpha3.Container) { recordOperation(c, v1alpha3.ContainerOperationUpdate, nil) }},
+			want:    1,
+		},
+		"NotRead": {
+			reason:  "The status of a Container that was never read should be written.",
+			changes: []func(*v1alpha3.Container){func(*v1alpha3.Container) {}},
+			want:    1,
+		},
+		"Coalesced": {
+			reason: "Writing the status just written again should be skipped.",
+			read:   true,
+			changes: []func(*v1alpha3.Container){
+				func(c *v1alpha3.Container) { c.Status.AtProvider.ETag = "changed" },
+				func(*v1alpha3.Container) {},
+			},
+			want: 1,
+		},
+		"RetriedAfterFailure": {
+			reason: "A status that failed to be written should be written again.",
+			read:   true,
+			changes: []func(*v1alpha3.Container){
+				func(c *v1alpha3.Container) { c.Status.AtProvider.ETag = "changed" },
+				func(*v1alpha3.Container) {},
+			},
+			failed: errBoom,
+			want:   2,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			writes := 0
+			kube := &test.MockClient{
+				MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					observed().DeepCopyInto(obj.(*v1alpha3.Container))
+					return nil
+				},
+				MockStatusUpdate: func(context.Context, client.Object, ...client.UpdateOption) error {
+					writes++
+					if writes == 1 {
+						return tc.failed
+					}
+					return nil
+				},
+			}
+			d := newStatusDebouncer(kube)
+
+			c := observed()
+			if tc.read {
+				if err := d.Get(context.TODO(), client.ObjectKeyFromObject(c), c); err != nil {
+					t.Fatalf("\n%s\nGet(...): %v", tc.reason, err)
+				}
+			}
+			for _, change := range tc.changes {
+				change(c)
+				_ = d.Status().Update(context.TODO(), c)
+			}
+			if writes != tc.want {
+				t.Errorf("\n%s\nStatus().Update(...): want %d writes, got %d", tc.reason, tc.want, writes)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+)
+
+// Reasons of the events recorded when a container transitions between states.
+const (
+	ReasonContainerCreated        event.Reason = "CreatedContainer"
+	ReasonContainerUpdated        event.Reason = "UpdatedContainer"
+	ReasonContainerDeleted        event.Reason = "DeletedContainer"
+	ReasonContainerDriftDetected  event.Reason = "DriftDetected"
+	ReasonContainerDriftCorrected event.Reason = "DriftCorrected"
+)
+
+// A transitionRecorder records an event each time a container transitions
+// between states, such as when it is created or its drift is corrected.
+// Reconciles that change nothing record nothing. A nil recorder records
+// nothing.
+type transitionRecorder struct {
+	record event.Recorder
+}
+
+// created records that the supplied container was created in the supplied
+// storage account.
+func (r *transitionRecorder) created(c *v1alpha3.Container, acct *v1alpha3.Account) {
+	if r == nil {
+		return
+	}
+	account, name := accountName(acct), externalName(c)
+	r.record.Event(c, event.Normal(ReasonContainerCreated,
+		fmt.Sprintf("Created container %s in storage account %s", name, account),
+		"account", account, "container", name))
+}
+
+// updated records that the supplied container was updated to its desired
+// state, making the supplied changes.
+func (r *transitionRecorder) updated(c *v1alpha3.Container, acct *v1alpha3.Account, changes []string) {
+	if r == nil {
+		return
+	}
+	account, name := accountName(acct), externalName(c)
+	r.record.Event(c, event.Normal(ReasonContainerUpdated,
+		fmt.Sprintf("Updated container %s in storage account %s: %s", name, account, strings.Join(changes, "; ")),
+		"account", account, "container", name))
+}
+
+// deleted records that the supplied container was deleted from the supplied
+// storage account.
+func (r *transitionRecorder) deleted(c *v1alpha3.Container, acct *v1alpha3.Account) {
+	if r == nil {
+		return
+	}
+	account, name := accountName(acct), externalName(c)
+	r.record.Event(c, event.Normal(ReasonContainerDeleted,
+		fmt.Sprintf("Deleted container %s from storage account %s", name, account),
+		"account", account, "container", name))
+}
+
+// driftDetected records that the supplied container has drifted from its
+// desired state in the supplied ways, and is not being corrected. Drift is
+// recorded once, when the container's Synced condition starts reporting it,
+// so it must be called before the condition is set.
+func (r *transitionRecorder) driftDetected(c *v1alpha3.Container, acct *v1alpha3.Account, drift []string) {
+	if r == nil || c.Status.GetCondition(driftDetected(drift).Type).Equal(driftDetected(drift)) {
+		return
+	}
+	account, name := accountName(acct), externalName(c)
+	r.record.Event(c, event.Warning(ReasonContainerDriftDetected, errors.Errorf(
+		"Container %s in storage account %s has drifted from its desired state: %s", name, account, strings.Join(drift, "; ")),
+		"account", account, "container", name))
+}
+
+// driftCorrected records that drift of the supplied container from a desired
+// state that had already been applied, which can only have been caused by a
+// change made outside of Crossplane, was corrected.
+func (r *transitionRecorder) driftCorrected(c *v1alpha3.Container, acct *v1alpha3.Account, drift []string) {
+	if r == nil {
+		return
+	}
+	account, name := accountName(acct), externalName(c)
+	r.record.Event(c, event.Normal(ReasonContainerDriftCorrected,
+		fmt.Sprintf("Corrected drift of container %s in storage account %s: %s", name, account, strings.Join(drift, "; ")),
+		"account", account, "container", name))
+}
+
+// accountName returns the external name of the supplied storage account, or
+// an empty string if it is nil.
+func accountName(acct *v1alpha3.Account) string {
+	if acct == nil {
+		return ""
+	}
+	return meta.GetExternalName(acct)
+}
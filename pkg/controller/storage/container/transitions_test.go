@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	v1alpha3test "github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3/test"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+	azurestoragefake "github.com/crossplane-contrib/provider-azure/pkg/clients/storage/fake"
+)
+
+// reasons returns the reasons of the supplied events.
+func reasons(events []event.Event) []event.Reason {
+	var r []event.Reason
+	for _, e := range events {
+		r = append(r, e.Reason)
+	}
+	return r
+}
+
+func TestTransitionEventsUpdate(t *testing.T) {
+	ctx := context.TODO()
+
+	cases := map[string]struct {
+		reason      string
+		observed    azblob.Metadata
+		applied     int64 // The observed generation; the container is at generation 2.
+		observeOnly bool
+		reconciles  int
+		want        []event.Reason
+	}{
+		"SpecChanged": {
+			reason:     "Applying a changed spec should record that the container was updated.",
+			observed:   azblob.Metadata{"team": "b"},
+			applied:    1,
+			reconciles: 1,
+			want:       []event.Reason{ReasonContainerUpdated},
+		},
+		"ExternalChange": {
+			reason:     "Correcting a change made outside of Crossplane should record that drift was corrected.",
+			observed:   azblob.Metadata{"team": "b"},
+			applied:    2,
+			reconciles: 1,
+			want:       []event.Reason{ReasonContainerDriftCorrected},
+		},
+		"NoOp": {
+			reason:     "Reconciles that change nothing should record nothing.",
+			observed:   azblob.Metadata{"team": "a"},
+			applied:    2,
+			reconciles: 2,
+		},
+		"ObserveOnly": {
+			reason:      "Drift of observe-only containers should be recorded once while it is unchanged.",
+			observed:    azblob.Metadata{"team": "b"},
+			applied:     2,
+			observeOnly: true,
+			reconciles:  2,
+			want:        []event.Reason{ReasonContainerDriftDetected},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := &eventRecorder{}
+			c := v1alpha3test.NewMockContainer(testContainerName).WithSpecMetadata(azblob.Metadata{"team": "a"}).Container
+			c.Generation = 2
+			c.Status.ObservedGeneration = tc.applied
+			ccu := &containerCreateUpdater{
+				ContainerOperations: azurestoragefake.NewMockContainerOperations(),
+				kube:                test.NewMockClient(),
+				container:           c,
+				observeOnly:         tc.observeOnly,
+				transitions:         &transitionRecorder{record: rec},
+				conditions:          DefaultErrorConditions(),
+			}
+
+			none := azblob.PublicAccessNone
+			for i := 0; i < tc.reconciles; i++ {
+				if _, err := ccu.update(ctx, &none, tc.observed); err != nil {
+					t.Fatalf("\n%s\ncontainerCreateUpdater.update(): unexpected error: %v", tc.reason, err)
+				}
+			}
+			if diff := cmp.Diff(tc.want, reasons(rec.events)); diff != "" {
+				t.Errorf("\n%s\ncontainerCreateUpdater.update(): -want events, +got events:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestTransitionEventsCreateDelete(t *testing.T) {
+	ctx := context.TODO()
+	acct := &v1alpha3.Account{}
+	meta.SetExternalName(acct, testAccountName)
+
+	rec := &eventRecorder{}
+	c := v1alpha3test.NewMockContainer(testContainerName).WithSpecDeletionPolicy(xpv1.DeletionDelete).Container
+	ccu := &containerCreateUpdater{
+		ContainerOperations: azurestoragefake.NewMockContainerOperations(),
+		kube:                test.NewMockClient(),
+		container:           c,
+		account:             acct,
+		management: func(context.Context) (storage.ManagementOperations, error) {
+			return newProvisionedManagementOperations(), nil
+		},
+		transitions: &transitionRecorder{record: rec},
+		conditions:  DefaultErrorConditions(),
+	}
+	if _, err := ccu.create(ctx); err != nil {
+		t.Fatalf("containerCreateUpdater.create(): unexpected error: %v", err)
+	}
+
+	csd := &containerSyncdeleter{
+		ContainerOperations: azurestoragefake.NewMockContainerOperations(),
+		kube:                test.NewMockClient(),
+		container:           c,
+		account:             acct,
+		deletion:            wait.Backoff{Duration: time.Millisecond, Steps: 3},
+		transitions:         &transitionRecorder{record: rec},
+		conditions:          DefaultErrorConditions(),
+	}
+	if _, err := csd.delete(ctx); err != nil {
+		t.Fatalf("containerSyncdeleter.delete(): unexpected error: %v", err)
+	}
+
+	want := []event.Event{
+		{
+			Type:        event.TypeNormal,
+			Reason:      ReasonContainerCreated,
+			Message:     "Created container " + testContainerName + " in storage account " + testAccountName,
+			Annotations: map[string]string{"account": testAccountName, "container": testContainerName},
+		},
+		{
+			Type:        event.TypeNormal,
+			Reason:      ReasonContainerDeleted,
+			Message:     "Deleted container " + testContainerName + " from storage account " + testAccountName,
+			Annotations: map[string]string{"account": testAccountName, "container": testContainerName},
+		},
+	}
+	if diff := cmp.Diff(want, rec.events); diff != "" {
+		t.Errorf("create and delete: -want events, +got events:\n%s", diff)
+	}
+}
+
+func TestTransitionRecorderNil(t *testing.T) {
+	c := v1alpha3test.NewMockContainer(testContainerName).Container
+	var r *transitionRecorder
+	r.created(c, nil)
+	r.updated(c, nil, []string{"metadata[team]: want \"a\", got none"})
+	r.deleted(c, nil)
+	r.driftDetected(c, nil, []string{"container does not exist"})
+	r.driftCorrected(c, nil, []string{"metadata[team]: want \"a\", got none"})
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managementpolicy
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage/storageapi"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	"github.com/crossplane-contrib/provider-azure/apis/v1alpha1"
+	azure "github.com/crossplane-contrib/provider-azure/pkg/clients"
+	azurestorage "github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+	"github.com/crossplane-contrib/provider-azure/pkg/features"
+)
+
+// Error strings.
+const (
+	errNotManagementPolicy    = "managed resource is not a ManagementPolicy"
+	errCreateManagementPolicy = "cannot create ManagementPolicy"
+	errUpdateManagementPolicy = "cannot update ManagementPolicy"
+	errGetManagementPolicy    = "cannot get ManagementPolicy"
+	errDeleteManagementPolicy = "cannot delete ManagementPolicy"
+)
+
+// Setup adds a controller that reconciles ManagementPolicies.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := managed.ControllerName(v1alpha3.ManagementPolicyGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), v1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha3.ManagementPolicy{}).
+		Complete(managed.NewReconciler(mgr,
+			resource.ManagedKind(v1alpha3.ManagementPolicyGroupVersionKind),
+			managed.WithExternalConnecter(&connecter{client: mgr.GetClient()}),
+			managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+			managed.WithPollInterval(o.PollInterval),
+			managed.WithLogger(o.Logger.WithValues("controller", name)),
+			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+			managed.WithConnectionPublishers(cps...)))
+}
+
+type connecter struct {
+	client client.Client
+}
+
+func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	creds, auth, err := azure.GetAuthInfo(ctx, c.client, mg)
+	if err != nil {
+		return nil, err
+	}
+	cl := storage.NewManagementPoliciesClientWithBaseURI(azure.ResourceManagerEndpoint(creds), creds[azure.CredentialsKeySubscriptionID])
+	cl.Authorizer = auth
+	return &external{client: cl}, nil
+}
+
+// An external manages the management policy of a storage account. An account
+// has a single policy, so the resource's external name does not identify it.
+type external struct {
+	client storageapi.ManagementPoliciesClientAPI
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	p, ok := mg.(*v1alpha3.ManagementPolicy)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotManagementPolicy)
+	}
+
+	az, err := e.client.Get(ctx, p.Spec.ForProvider.ResourceGroupName, p.Spec.ForProvider.AccountName)
+	if azure.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetManagementPolicy)
+	}
+
+	p.Status.AtProvider = azurestorage.GenerateManagementPolicyObservation(az)
+	p.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: azurestorage.ManagementPolicyIsUpToDate(p.Spec.ForProvider, az),
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	p, ok := mg.(*v1alpha3.ManagementPolicy)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotManagementPolicy)
+	}
+
+	p.SetConditions(xpv1.Creating())
+	policy, err := azurestorage.NewManagementPolicy(p.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateManagementPolicy)
+	}
+	_, err = e.client.CreateOrUpdate(ctx, p.Spec.ForProvider.ResourceGroupName, p.Spec.ForProvider.AccountName, policy)
+	return managed.ExternalCreation{}, errors.Wrap(err, errCreateManagementPolicy)
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	p, ok := mg.(*v1alpha3.ManagementPolicy)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotManagementPolicy)
+	}
+
+	policy, err := azurestorage.NewManagementPolicy(p.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateManagementPolicy)
+	}
+	_, err = e.client.CreateOrUpdate(ctx, p.Spec.ForProvider.ResourceGroupName, p.Spec.ForProvider.AccountName, policy)
+	return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateManagementPolicy)
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	p, ok := mg.(*v1alpha3.ManagementPolicy)
+	if !ok {
+		return errors.New(errNotManagementPolicy)
+	}
+
+	p.SetConditions(xpv1.Deleting())
+	_, err := e.client.Delete(ctx, p.Spec.ForProvider.ResourceGroupName, p.Spec.ForProvider.AccountName)
+	return errors.Wrap(resource.Ignore(azure.IsNotFound, err), errDeleteManagementPolicy)
+}
@@ -0,0 +1,374 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managementpolicy
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-04-01/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-azure/apis/storage/v1alpha3"
+	azure "github.com/crossplane-contrib/provider-azure/pkg/clients"
+	azurestorage "github.com/crossplane-contrib/provider-azure/pkg/clients/storage"
+	"github.com/crossplane-contrib/provider-azure/pkg/clients/storage/fake"
+)
+
+const (
+	name              = "coolPolicy"
+	accountName       = "coolaccount"
+	resourceGroupName = "coolRG"
+	resourceID        = "a-very-cool-id"
+)
+
+type policyModifier func(*v1alpha3.ManagementPolicy)
+
+func withConditions(c ...xpv1.Condition) policyModifier {
+	return func(p *v1alpha3.ManagementPolicy) { p.Status.ConditionedStatus.Conditions = c }
+}
+
+func withID(s string) policyModifier {
+	return func(p *v1alpha3.ManagementPolicy) { p.Status.AtProvider.ID = s }
+}
+
+func withDeleteAfterDays(days int32) policyModifier {
+	return func(p *v1alpha3.ManagementPolicy) {
+		p.Spec.ForProvider.Rules[0].Actions.BaseBlob.DeleteAfterDays = &days
+	}
+}
+
+func withoutActions() policyModifier {
+	return func(p *v1alpha3.ManagementPolicy) {
+		p.Spec.ForProvider.Rules[0].Actions = v1alpha3.ManagementPolicyActions{}
+	}
+}
+
+func policy(m ...policyModifier) *v1alpha3.ManagementPolicy {
+	cool, archive, del := int32(30), int32(90), int32(365)
+	p := &v1alpha3.ManagementPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1alpha3.ManagementPolicySpec{
+			ForProvider: v1alpha3.ManagementPolicyParameters{
+				ResourceGroupName: resourceGroupName,
+				AccountName:       accountName,
+				Rules: []v1alpha3.ManagementPolicyRule{{
+					Name: "tiering",
+					Actions: v1alpha3.ManagementPolicyActions{
+						BaseBlob: &v1alpha3.ManagementPolicyBaseBlobActions{
+							TierToCoolAfterDays:    &cool,
+							TierToArchiveAfterDays: &archive,
+							DeleteAfterDays:        &del,
+						},
+					},
+				}},
+			},
+		},
+	}
+	for _, f := range m {
+		f(p)
+	}
+	return p
+}
+
+// desiredPolicy returns the management policy the supplied resource
+// describes.
+func desiredPolicy(p *v1alpha3.ManagementPolicy) storage.ManagementPolicy {
+	az, _ := azurestorage.NewManagementPolicy(p.Spec.ForProvider)
+	return az
+}
+
+// azurePolicy returns the Azure management policy of the supplied resource.
+func azurePolicy(p *v1alpha3.ManagementPolicy) storage.ManagementPolicy {
+	az := desiredPolicy(p)
+	az.ID = azure.ToStringPtr(resourceID)
+	return az
+}
+
+// Test that our Reconciler implementation satisfies the Reconciler interface.
+var _ managed.ExternalClient = &external{}
+var _ managed.ExternalConnecter = &connecter{}
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		mg  resource.Managed
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		client *fake.MockManagementPoliciesClient
+		mg     resource.Managed
+		want   want
+	}{
+		"NotManagementPolicy": {
+			reason: "An error should be returned if the managed resource is not a ManagementPolicy.",
+			client: &fake.MockManagementPoliciesClient{},
+			want:   want{err: errors.New(errNotManagementPolicy)},
+		},
+		"NotFound": {
+			reason: "A policy that does not exist should be reported as such.",
+			client: &fake.MockManagementPoliciesClient{
+				MockGet: func(context.Context, string, string) (storage.ManagementPolicy, error) {
+					return storage.ManagementPolicy{}, autorest.DetailedError{StatusCode: http.StatusNotFound}
+				},
+			},
+			mg:   policy(),
+			want: want{mg: policy()},
+		},
+		"UpToDate": {
+			reason: "A policy whose rules match the desired rules should be up to date.",
+			client: &fake.MockManagementPoliciesClient{
+				MockGet: func(context.Context, string, string) (storage.ManagementPolicy, error) {
+					return azurePolicy(policy()), nil
+				},
+			},
+			mg: policy(),
+			want: want{
+				mg: policy(withConditions(xpv1.Available()), withID(resourceID)),
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+			},
+		},
+		"NotUpToDate": {
+			reason: "A policy whose rules differ from the desired rules should not be up to date.",
+			client: &fake.MockManagementPoliciesClient{
+				MockGet: func(context.Context, string, string) (storage.ManagementPolicy, error) {
+					return azurePolicy(policy(withDeleteAfterDays(30))), nil
+				},
+			},
+			mg: policy(),
+			want: want{
+				mg: policy(withConditions(xpv1.Available()), withID(resourceID)),
+				o:  managed.ExternalObservation{ResourceExists: true},
+			},
+		},
+		"GetFailed": {
+			reason: "Errors getting the policy should be returned.",
+			client: &fake.MockManagementPoliciesClient{
+				MockGet: func(context.Context, string, string) (storage.ManagementPolicy, error) {
+					return storage.ManagementPolicy{}, errBoom
+				},
+			},
+			mg:   policy(),
+			want: want{mg: policy(), err: errors.Wrap(errBoom, errGetManagementPolicy)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{client: tc.client}
+			o, err := e.Observe(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, o); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.mg, tc.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want managed resource, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		mg     resource.Managed
+		policy storage.ManagementPolicy
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		mg     resource.Managed
+		want   want
+	}{
+		"NotManagementPolicy": {
+			reason: "An error should be returned if the managed resource is not a ManagementPolicy.",
+			want:   want{err: errors.New(errNotManagementPolicy)},
+		},
+		"Successful": {
+			reason: "The desired policy should be written.",
+			mg:     policy(),
+			want: want{
+				mg:     policy(withConditions(xpv1.Creating())),
+				policy: desiredPolicy(policy()),
+			},
+		},
+		"Failed": {
+			reason: "Errors writing the policy should be returned.",
+			err:    errBoom,
+			mg:     policy(),
+			want: want{
+				mg:     policy(withConditions(xpv1.Creating())),
+				policy: desiredPolicy(policy()),
+				err:    errors.Wrap(errBoom, errCreateManagementPolicy),
+			},
+		},
+		"NoActions": {
+			reason: "Rules without actions should be rejected before anything is written.",
+			mg:     policy(withoutActions()),
+			want: want{
+				mg:  policy(withoutActions(), withConditions(xpv1.Creating())),
+				err: errors.Wrap(errors.Errorf("rule %s must set at least one action", "tiering"), errCreateManagementPolicy),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var written storage.ManagementPolicy
+			e := &external{client: &fake.MockManagementPoliciesClient{
+				MockCreateOrUpdate: func(_ context.Context, rg, account string, p storage.ManagementPolicy) (storage.ManagementPolicy, error) {
+					if rg != resourceGroupName || account != accountName {
+						t.Errorf("\n%s\ne.Create(...): want policy of %s/%s, got %s/%s", tc.reason, resourceGroupName, accountName, rg, account)
+					}
+					written = p
+					return p, tc.err
+				},
+			}}
+			_, err := e.Create(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.policy, written); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want policy, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.mg, tc.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want managed resource, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		mg     resource.Managed
+		want   error
+	}{
+		"NotManagementPolicy": {
+			reason: "An error should be returned if the managed resource is not a ManagementPolicy.",
+			want:   errors.New(errNotManagementPolicy),
+		},
+		"Successful": {
+			reason: "The desired policy should be written.",
+			mg:     policy(),
+		},
+		"Failed": {
+			reason: "Errors writing the policy should be returned.",
+			err:    errBoom,
+			mg:     policy(),
+			want:   errors.Wrap(errBoom, errUpdateManagementPolicy),
+		},
+		"NoActions": {
+			reason: "Rules without actions should be rejected before anything is written.",
+			mg:     policy(withoutActions()),
+			want:   errors.Wrap(errors.Errorf("rule %s must set at least one action", "tiering"), errUpdateManagementPolicy),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{client: &fake.MockManagementPoliciesClient{
+				MockCreateOrUpdate: func(_ context.Context, _, _ string, p storage.ManagementPolicy) (storage.ManagementPolicy, error) {
+					if !azurestorage.ManagementPolicyIsUpToDate(policy().Spec.ForProvider, p) {
+						t.Errorf("\n%s\ne.Update(...): wrote a policy other than the desired one", tc.reason)
+					}
+					return p, tc.err
+				},
+			}}
+			_, err := e.Update(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		mg  resource.Managed
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		mg     resource.Managed
+		want   want
+	}{
+		"NotManagementPolicy": {
+			reason: "An error should be returned if the managed resource is not a ManagementPolicy.",
+			want:   want{err: errors.New(errNotManagementPolicy)},
+		},
+		"Successful": {
+			reason: "The policy should be deleted.",
+			mg:     policy(),
+			want:   want{mg: policy(withConditions(xpv1.Deleting()))},
+		},
+		"NotFound": {
+			reason: "Policies that do not exist should be considered deleted.",
+			err:    autorest.DetailedError{StatusCode: http.StatusNotFound},
+			mg:     policy(),
+			want:   want{mg: policy(withConditions(xpv1.Deleting()))},
+		},
+		"Failed": {
+			reason: "Errors deleting the policy should be returned.",
+			err:    errBoom,
+			mg:     policy(),
+			want:   want{mg: policy(withConditions(xpv1.Deleting())), err: errors.Wrap(errBoom, errDeleteManagementPolicy)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{client: &fake.MockManagementPoliciesClient{
+				MockDelete: func(context.Context, string, string) (autorest.Response, error) {
+					return autorest.Response{}, tc.err
+				},
+			}}
+			err := e.Delete(context.Background(), tc.mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.mg, tc.mg, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want managed resource, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -24,4 +24,14 @@ const (
 	// External Secret Stores. See the below design for more details.
 	// https://github.com/crossplane/crossplane/blob/390ddd/design/design-doc-external-secret-stores.md
 	EnableAlphaExternalSecretStores feature.Flag = "EnableAlphaExternalSecretStores"
+
+	// ObserveOnly makes the storage container controller observe containers
+	// and report drift from their desired state without ever creating,
+	// updating, or deleting them.
+	ObserveOnly feature.Flag = "ObserveOnly"
+
+	// EnsureResourceGroups makes the storage account controller create the
+	// resource group of an account, unless it already exists, before it
+	// creates the account.
+	EnsureResourceGroups feature.Flag = "EnsureResourceGroups"
 )